@@ -32,6 +32,16 @@ var (
 		Usage:  "Hardcoded tx.gasPrice, not setting it uses gas estimation",
 		EnvVar: "GAS_PRICE_ORACLE_TRANSACTION_GAS_PRICE",
 	}
+	RollupConfigFlag = cli.StringFlag{
+		Name:   "rollup-config",
+		Usage:  "JSON rollup config shared with the other services of the rollup, it fills in the options that are not set",
+		EnvVar: "GAS_PRICE_ORACLE_ROLLUP_CONFIG",
+	}
+	ProfilesFlag = cli.StringFlag{
+		Name:   "profiles",
+		Usage:  "JSON file of network profiles keyed by chain id, the profile of the chain fills in the options that are not set",
+		EnvVar: "GAS_PRICE_ORACLE_PROFILES",
+	}
 	LogLevelFlag = cli.IntFlag{
 		Name:   "loglevel",
 		Value:  3,
@@ -79,6 +89,11 @@ var (
 		Usage:  "wait for receipts when sending transactions",
 		EnvVar: "GAS_PRICE_ORACLE_WAIT_FOR_RECEIPT",
 	}
+	MockFlag = cli.BoolFlag{
+		Name:   "mock",
+		Usage:  "update the gas price from a simulated load instead of the gas used on L2, for local development",
+		EnvVar: "GAS_PRICE_ORACLE_MOCK",
+	}
 	MetricsEnabledFlag = cli.BoolFlag{
 		Name:   "metrics",
 		Usage:  "Enable metrics collection and reporting",
@@ -133,6 +148,8 @@ var Flags = []cli.Flag{
 	GasPriceOracleAddressFlag,
 	PrivateKeyFlag,
 	TransactionGasPriceFlag,
+	RollupConfigFlag,
+	ProfilesFlag,
 	LogLevelFlag,
 	FloorPriceFlag,
 	TargetGasPerSecondFlag,
@@ -141,6 +158,7 @@ var Flags = []cli.Flag{
 	EpochLengthSecondsFlag,
 	SignificanceFactorFlag,
 	WaitForReceiptFlag,
+	MockFlag,
 	MetricsEnabledFlag,
 	MetricsHTTPFlag,
 	MetricsPortFlag,
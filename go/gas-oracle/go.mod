@@ -3,7 +3,10 @@ module github.com/ethereum-optimism/optimism/go/gas-oracle
 go 1.16
 
 require (
+	github.com/ethereum-optimism/optimism/go/utils v0.0.0
 	github.com/ethereum/go-ethereum v1.10.4
 	github.com/urfave/cli v1.20.0
 	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2 // indirect
 )
+
+replace github.com/ethereum-optimism/optimism/go/utils => ../utils
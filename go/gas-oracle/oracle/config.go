@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/ethereum-optimism/optimism/go/gas-oracle/flags"
+	"github.com/ethereum-optimism/optimism/go/utils/rollupconfig"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
@@ -27,6 +28,7 @@ type Config struct {
 	averageBlockGasLimitPerEpoch float64
 	epochLengthSeconds           uint64
 	significanceFactor           float64
+	mock                         bool
 	// Metrics config
 	MetricsEnabled          bool
 	MetricsHTTP             string
@@ -68,6 +70,37 @@ func NewConfig(ctx *cli.Context) *Config {
 		cfg.chainID = new(big.Int).SetUint64(chainID)
 	}
 
+	if ctx.GlobalIsSet(flags.RollupConfigFlag.Name) {
+		rollupConfig, err := rollupconfig.Load(ctx.GlobalString(flags.RollupConfigFlag.Name))
+		if err != nil {
+			log.Crit(fmt.Sprintf("Option %q: %v", flags.RollupConfigFlag.Name, err))
+		}
+		if !ctx.GlobalIsSet(flags.ChainIDFlag.Name) {
+			cfg.chainID = new(big.Int).SetUint64(rollupConfig.L2ChainID)
+		}
+		if !ctx.GlobalIsSet(flags.GasPriceOracleAddressFlag.Name) {
+			cfg.gasPriceOracleAddress = rollupConfig.GasPriceOracle()
+		}
+		log.Info("Loaded rollup config", "chain-id", cfg.chainID,
+			"gas-price-oracle-address", cfg.gasPriceOracleAddress.Hex())
+	}
+
+	if ctx.GlobalIsSet(flags.ProfilesFlag.Name) {
+		if cfg.chainID == nil {
+			log.Crit("Network profiles require a chain id")
+		}
+		path := ctx.GlobalString(flags.ProfilesFlag.Name)
+		profile, err := loadNetworkProfile(path, cfg.chainID)
+		if err != nil {
+			log.Crit(fmt.Sprintf("Option %q: %v", flags.ProfilesFlag.Name, err))
+		}
+		if profile != nil {
+			cfg.applyProfile(ctx, profile)
+		} else {
+			log.Warn("No network profile for chain", "chain-id", cfg.chainID)
+		}
+	}
+
 	if ctx.GlobalIsSet(flags.TransactionGasPriceFlag.Name) {
 		gasPrice := ctx.GlobalUint64(flags.TransactionGasPriceFlag.Name)
 		cfg.gasPrice = new(big.Int).SetUint64(gasPrice)
@@ -77,6 +110,8 @@ func NewConfig(ctx *cli.Context) *Config {
 		cfg.waitForReceipt = true
 	}
 
+	cfg.mock = ctx.GlobalBool(flags.MockFlag.Name)
+
 	cfg.MetricsEnabled = ctx.GlobalBool(flags.MetricsEnabledFlag.Name)
 	cfg.MetricsHTTP = ctx.GlobalString(flags.MetricsHTTPFlag.Name)
 	cfg.MetricsPort = ctx.GlobalInt(flags.MetricsPortFlag.Name)
@@ -88,3 +123,32 @@ func NewConfig(ctx *cli.Context) *Config {
 
 	return &cfg
 }
+
+// applyProfile sets the options that are not set on the command line from
+// the network profile
+func (c *Config) applyProfile(ctx *cli.Context, profile *networkProfile) {
+	if profile.GasPriceOracleAddress != nil && !ctx.GlobalIsSet(flags.GasPriceOracleAddressFlag.Name) {
+		c.gasPriceOracleAddress = *profile.GasPriceOracleAddress
+	}
+	p := profile.GasOracle
+	if p.FloorPrice != nil && !ctx.GlobalIsSet(flags.FloorPriceFlag.Name) {
+		c.floorPrice = *p.FloorPrice
+	}
+	if p.TargetGasPerSecond != nil && !ctx.GlobalIsSet(flags.TargetGasPerSecondFlag.Name) {
+		c.targetGasPerSecond = *p.TargetGasPerSecond
+	}
+	if p.MaxPercentChangePerEpoch != nil && !ctx.GlobalIsSet(flags.MaxPercentChangePerEpochFlag.Name) {
+		c.maxPercentChangePerEpoch = *p.MaxPercentChangePerEpoch
+	}
+	if p.AverageBlockGasLimitPerEpoch != nil && !ctx.GlobalIsSet(flags.AverageBlockGasLimitPerEpochFlag.Name) {
+		c.averageBlockGasLimitPerEpoch = *p.AverageBlockGasLimitPerEpoch
+	}
+	if p.EpochLengthSeconds != nil && !ctx.GlobalIsSet(flags.EpochLengthSecondsFlag.Name) {
+		c.epochLengthSeconds = *p.EpochLengthSeconds
+	}
+	if p.SignificanceFactor != nil && !ctx.GlobalIsSet(flags.SignificanceFactorFlag.Name) {
+		c.significanceFactor = *p.SignificanceFactor
+	}
+	log.Info("Applied network profile", "chain-id", c.chainID,
+		"gas-price-oracle-address", c.gasPriceOracleAddress.Hex())
+}
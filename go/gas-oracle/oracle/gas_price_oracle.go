@@ -214,6 +214,10 @@ func NewGasPriceOracle(cfg *Config) (*GasPriceOracle, error) {
 	// getLatestBlockNumberFn is used by the GasPriceUpdater
 	// to get the latest block number
 	getLatestBlockNumberFn := wrapGetLatestBlockNumberFn(client)
+	if cfg.mock {
+		log.Warn("Running in mock mode, the gas price follows a simulated load")
+		getLatestBlockNumberFn = wrapMockGetLatestBlockNumberFn(epochStartBlockNumber, cfg)
+	}
 	// updateL2GasPriceFn is used by the GasPriceUpdater to
 	// update the gas price
 	updateL2GasPriceFn, err := wrapUpdateL2GasPriceFn(client, cfg)
@@ -0,0 +1,25 @@
+package oracle
+
+// mockEpochsPerPhase is the number of epochs that the simulated load of the
+// mock mode stays busy or idle for
+const mockEpochsPerPhase = 6
+
+// wrapMockGetLatestBlockNumberFn returns a getLatestBlockNumberFn for the
+// mock mode that simulates the load on L2 instead of reading the tip. Each
+// call completes an epoch. The epochs alternate between phases with enough
+// blocks for twice the target gas per second and phases without blocks, so
+// that the gas price rises and falls without any transactions on L2.
+func wrapMockGetLatestBlockNumberFn(start uint64, cfg *Config) func() (uint64, error) {
+	busy := uint64(2 * float64(cfg.targetGasPerSecond) * float64(cfg.epochLengthSeconds) / cfg.averageBlockGasLimitPerEpoch)
+	if busy == 0 {
+		busy = 1
+	}
+	number, epoch := start, uint64(0)
+	return func() (uint64, error) {
+		if (epoch/mockEpochsPerPhase)%2 == 0 {
+			number += busy
+		}
+		epoch++
+		return number, nil
+	}
+}
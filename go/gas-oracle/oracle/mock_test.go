@@ -0,0 +1,33 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/gas-oracle/gasprices"
+)
+
+func TestWrapMockGetLatestBlockNumberFn(t *testing.T) {
+	cfg := &Config{
+		targetGasPerSecond:           11_000_000,
+		averageBlockGasLimitPerEpoch: 11_000_000,
+		epochLengthSeconds:           10,
+	}
+	getLatest := wrapMockGetLatestBlockNumberFn(100, cfg)
+
+	start := uint64(100)
+	for epoch := 0; epoch < 4*mockEpochsPerPhase; epoch++ {
+		latest, err := getLatest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		gasPerSecond := gasprices.GetAverageGasPerSecond(start, latest, cfg.epochLengthSeconds, uint64(cfg.averageBlockGasLimitPerEpoch))
+		expect := float64(0)
+		if (epoch/mockEpochsPerPhase)%2 == 0 {
+			expect = 2 * float64(cfg.targetGasPerSecond)
+		}
+		if gasPerSecond != expect {
+			t.Fatalf("epoch %d: mismatched gas per second: got %f, expect %f", epoch, gasPerSecond, expect)
+		}
+		start = latest
+	}
+}
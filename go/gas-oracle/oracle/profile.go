@@ -0,0 +1,39 @@
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// networkProfile is the section of a network profile that is read by the
+// gas oracle. The profiles file is shared with l2geth and the batch
+// submitter, it is keyed by the decimal L2 chain id.
+type networkProfile struct {
+	GasPriceOracleAddress *common.Address `json:"gasPriceOracleAddress"`
+	GasOracle             struct {
+		FloorPrice                   *uint64  `json:"floorPrice"`
+		TargetGasPerSecond           *uint64  `json:"targetGasPerSecond"`
+		MaxPercentChangePerEpoch     *float64 `json:"maxPercentChangePerEpoch"`
+		AverageBlockGasLimitPerEpoch *float64 `json:"averageBlockGasLimitPerEpoch"`
+		EpochLengthSeconds           *uint64  `json:"epochLengthSeconds"`
+		SignificanceFactor           *float64 `json:"significanceFactor"`
+	} `json:"gasOracle"`
+}
+
+// loadNetworkProfile reads the profile of the chain from the profiles file,
+// it returns nil when the file has no profile for the chain
+func loadNetworkProfile(path string, chainID *big.Int) (*networkProfile, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read network profiles: %w", err)
+	}
+	var profiles map[string]*networkProfile
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, fmt.Errorf("cannot decode network profiles: %w", err)
+	}
+	return profiles[chainID.String()], nil
+}
@@ -0,0 +1,166 @@
+// Package rollupconfig loads the configuration of a rollup that is shared by
+// all of its services, so that they cannot be configured for different
+// chains. It mirrors the rollupconfig package of l2geth, which also checks
+// the config against the chain config of the L2 chain.
+package rollupconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidRollupConfig represents the error case of a rollup config that
+// cannot be used by any service
+var ErrInvalidRollupConfig = errors.New("invalid rollup config")
+
+// predeployGasPriceOracle is the address of the OVM_GasPriceOracle predeploy
+var predeployGasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+// RollupConfig is the configuration of a rollup. Services take the values
+// that are not set with their own flags from it.
+type RollupConfig struct {
+	L1ChainID uint64 `json:"l1ChainId"`
+	L2ChainID uint64 `json:"l2ChainId"`
+	// Address of the Lib_AddressManager on L1 that the contracts of the
+	// rollup are resolved with
+	AddressManager common.Address `json:"addressManager"`
+	// Address of the OVM_CanonicalTransactionChain that the transaction
+	// batches are appended to, and the L1 block that it was deployed at
+	BatchInbox             common.Address `json:"batchInbox"`
+	BatchInboxDeployHeight uint64         `json:"batchInboxDeployHeight"`
+	// Address of the OVM_StateCommitmentChain that the proposer appends the
+	// state roots to
+	StateCommitmentChain common.Address `json:"stateCommitmentChain"`
+	Predeploys           Predeploys     `json:"predeploys"`
+	// Forks of the formula of the L1 fee, which must match those of the
+	// chain config of the L2 chain
+	FeeForks *FeeForks `json:"feeForks,omitempty"`
+	// Gas schedule of calldata on the L1 chain, including its Istanbul
+	// block and later repricings
+	L1CalldataGas *L1CalldataGas `json:"l1CalldataGas,omitempty"`
+	Fees          Fees           `json:"fees"`
+}
+
+// Predeploys are the addresses of the L2 system contracts, those that are
+// not set are the predeploys of the OVM
+type Predeploys struct {
+	GasPriceOracle *common.Address `json:"gasPriceOracle,omitempty"`
+}
+
+// FeeForks are the L1 blocks that the formula of the L1 fee changes at
+type FeeForks struct {
+	L1CalldataGasBlock *big.Int `json:"l1CalldataGasBlock,omitempty"`
+	MinTxSizeBlock     *big.Int `json:"minTxSizeBlock,omitempty"`
+}
+
+// L1CalldataGas is the gas schedule of calldata on the L1 chain
+type L1CalldataGas struct {
+	ZeroGas    uint64 `json:"zeroGas"`
+	NonZeroGas uint64 `json:"nonZeroGas"`
+	MinTxSize  uint64 `json:"minTxSize"`
+	// L1 block that EIP 2028 activated at, calldata of earlier L1 blocks is
+	// priced at the Frontier costs
+	IstanbulBlock *big.Int `json:"istanbulBlock,omitempty"`
+	// Repricings of calldata on the L1 chain, in order of activation
+	Forks []L1CalldataGasFork `json:"forks,omitempty"`
+}
+
+// L1CalldataGasFork is a repricing of calldata on the L1 chain
+type L1CalldataGasFork struct {
+	Block      *big.Int `json:"block"`
+	ZeroGas    uint64   `json:"zeroGas"`
+	NonZeroGas uint64   `json:"nonZeroGas"`
+}
+
+// Fees are the fee thresholds of the sequencer, which are also used by the
+// services that estimate fees on its behalf
+type Fees struct {
+	ThresholdUp   *float64 `json:"thresholdUp,omitempty"`
+	ThresholdDown *float64 `json:"thresholdDown,omitempty"`
+}
+
+// Load reads a rollup config from a JSON file and validates it. Unknown
+// fields are rejected so that a misspelled field is not silently ignored.
+func Load(path string) (*RollupConfig, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rollup config: %w", err)
+	}
+	var config RollupConfig
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return nil, fmt.Errorf("cannot decode rollup config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate checks the values that every service relies on
+func (c *RollupConfig) Validate() error {
+	if c.L1ChainID == 0 || c.L2ChainID == 0 {
+		return fmt.Errorf("%w: missing chain id", ErrInvalidRollupConfig)
+	}
+	if c.AddressManager == (common.Address{}) {
+		return fmt.Errorf("%w: missing address manager", ErrInvalidRollupConfig)
+	}
+	if c.Predeploys.GasPriceOracle != nil && *c.Predeploys.GasPriceOracle == (common.Address{}) {
+		return fmt.Errorf("%w: gas price oracle at the zero address", ErrInvalidRollupConfig)
+	}
+	if c.Fees.ThresholdUp != nil && *c.Fees.ThresholdUp <= 1 {
+		return fmt.Errorf("%w: fee threshold up not larger than 1: %f", ErrInvalidRollupConfig, *c.Fees.ThresholdUp)
+	}
+	if c.Fees.ThresholdDown != nil && (*c.Fees.ThresholdDown <= 0 || *c.Fees.ThresholdDown >= 1) {
+		return fmt.Errorf("%w: fee threshold down not between 0 and 1: %f", ErrInvalidRollupConfig, *c.Fees.ThresholdDown)
+	}
+	if f := c.FeeForks; f != nil && f.MinTxSizeBlock != nil {
+		if f.L1CalldataGasBlock == nil || f.L1CalldataGasBlock.Cmp(f.MinTxSizeBlock) > 0 {
+			return fmt.Errorf("%w: min tx size fee fork before the l1 calldata gas fee fork", ErrInvalidRollupConfig)
+		}
+	}
+	if g := c.L1CalldataGas; g != nil {
+		last := g.IstanbulBlock
+		for i, fork := range g.Forks {
+			if fork.Block == nil || (last != nil && fork.Block.Cmp(last) <= 0) {
+				return fmt.Errorf("%w: l1 calldata gas fork %d out of order", ErrInvalidRollupConfig, i)
+			}
+			last = fork.Block
+		}
+	}
+	return nil
+}
+
+// GasPriceOracle returns the address of the OVM_GasPriceOracle
+func (c *RollupConfig) GasPriceOracle() common.Address {
+	if c.Predeploys.GasPriceOracle != nil {
+		return *c.Predeploys.GasPriceOracle
+	}
+	return predeployGasPriceOracle
+}
+
+// GasAt returns the gas costs of a zero and a non-zero byte of calldata on
+// the L1 chain at the L1 block number, the latest ones when it is nil.
+// Without a schedule calldata is priced as of EIP 2028.
+func (g *L1CalldataGas) GasAt(l1Block *big.Int) (uint64, uint64) {
+	if g == nil {
+		return 4, 16
+	}
+	if g.IstanbulBlock != nil && l1Block != nil && l1Block.Cmp(g.IstanbulBlock) < 0 {
+		return 4, 68
+	}
+	zero, nonZero := g.ZeroGas, g.NonZeroGas
+	for _, fork := range g.Forks {
+		if l1Block == nil || l1Block.Cmp(fork.Block) >= 0 {
+			zero, nonZero = fork.ZeroGas, fork.NonZeroGas
+		}
+	}
+	return zero, nonZero
+}
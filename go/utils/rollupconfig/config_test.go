@@ -0,0 +1,55 @@
+package rollupconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollupconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rollup.json")
+	content := `{
+		"l1ChainId": 1,
+		"l2ChainId": 10,
+		"addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F",
+		"feeForks": {"l1CalldataGasBlock": 200, "minTxSizeBlock": 100}
+	}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); !errors.Is(err, ErrInvalidRollupConfig) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrInvalidRollupConfig)
+	}
+
+	content = `{
+		"l1ChainId": 1,
+		"l2ChainId": 10,
+		"addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F",
+		"feeForks": {"l1CalldataGasBlock": 100, "minTxSizeBlock": 200},
+		"l1CalldataGas": {"zeroGas": 4, "nonZeroGas": 16, "minTxSize": 0, "istanbulBlock": 50}
+	}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.GasPriceOracle() != common.HexToAddress("0x420000000000000000000000000000000000000F") {
+		t.Fatalf("mismatched gas price oracle: got %s", config.GasPriceOracle().Hex())
+	}
+	if zero, nonZero := config.L1CalldataGas.GasAt(big.NewInt(49)); zero != 4 || nonZero != 68 {
+		t.Fatalf("mismatched calldata gas before istanbul: got %d/%d, expect 4/68", zero, nonZero)
+	}
+}
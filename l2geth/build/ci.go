@@ -81,6 +81,11 @@ var (
 		executablePath("rlpdump"),
 		executablePath("wnode"),
 		executablePath("clef"),
+		executablePath("feeestimator"),
+		executablePath("relayer"),
+		executablePath("feeexporter"),
+		executablePath("rpcproxy"),
+		executablePath("feesweeper"),
 	}
 
 	// A debian package is created for all executables listed here.
@@ -117,6 +122,26 @@ var (
 			BinaryName:  "clef",
 			Description: "Ethereum account management tool.",
 		},
+		{
+			BinaryName:  "feeestimator",
+			Description: "Developer utility that prints the fee breakdown of a rollup transaction.",
+		},
+		{
+			BinaryName:  "relayer",
+			Description: "Relayer that sponsors the fees of meta-transactions.",
+		},
+		{
+			BinaryName:  "feeexporter",
+			Description: "Exporter of the rollup fee and batch economics to Postgres.",
+		},
+		{
+			BinaryName:  "rpcproxy",
+			Description: "JSON-RPC proxy that adapts rollup receipts for legacy clients.",
+		},
+		{
+			BinaryName:  "feesweeper",
+			Description: "Service that sweeps the rollup fee vaults to L1.",
+		},
 	}
 
 	// A debian package is created for all executables listed here.
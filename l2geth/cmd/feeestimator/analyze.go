@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandAnalyze = cli.Command{
+	Name:      "analyze",
+	Usage:     "print the L1 byte cost of each field of a raw transaction",
+	ArgsUsage: "[<txfile>]",
+	Description: `
+Decode a raw transaction and report the zero and non-zero bytes and the L1
+gas of each of its RLP encoded fields, to find the parts of the payload that
+drive the L1 fee.`,
+	Flags: []cli.Flag{
+		txFlag,
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		tx, err := readTransaction(ctx)
+		if err != nil {
+			return err
+		}
+		a, err := analyze(tx)
+		if err != nil {
+			return err
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			out, err := json.MarshalIndent(a, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+		a.print(os.Stdout)
+		return nil
+	},
+}
+
+// fieldCost is the L1 byte cost of a single RLP encoded field
+type fieldCost struct {
+	Name      string `json:"name"`
+	Size      uint64 `json:"size"`
+	Zeroes    uint64 `json:"zeroes"`
+	NonZeroes uint64 `json:"nonZeroes"`
+	L1Gas     uint64 `json:"l1Gas"`
+}
+
+// analysis is the byte cost of a transaction broken down by field
+type analysis struct {
+	Fields []*fieldCost `json:"fields"`
+	Total  *fieldCost   `json:"total"`
+	// ChargedL1Gas is the L1 gas that the fee is computed with, which only
+	// counts the calldata and a fixed overhead for the rest of the
+	// transaction
+	ChargedL1Gas uint64 `json:"chargedL1Gas"`
+}
+
+// analyze computes the cost of each field of the RLP encoded transaction
+func analyze(tx *types.Transaction) (*analysis, error) {
+	v, r, s := tx.RawSignatureValues()
+	values := []struct {
+		name  string
+		value interface{}
+	}{
+		{"nonce", tx.Nonce()},
+		{"gasPrice", tx.GasPrice()},
+		{"gasLimit", tx.Gas()},
+		{"to", tx.To()},
+		{"value", tx.Value()},
+		{"data", tx.Data()},
+		{"v", v},
+		{"r", r},
+		{"s", s},
+	}
+	total := tx.EncodedCounts()
+	a := &analysis{
+		Total:        newFieldCost("total", total),
+		ChargedL1Gas: tx.L1GasUsed(),
+	}
+	// The fields are preceded by the header of the list that holds them,
+	// which is what is left of the transaction once they are counted
+	header := total
+	for _, field := range values {
+		counts, err := types.CountRLP(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot encode %s: %w", field.name, err)
+		}
+		header.Size -= counts.Size
+		header.Zeroes -= counts.Zeroes
+		header.NonZeroes -= counts.NonZeroes
+		a.Fields = append(a.Fields, newFieldCost(field.name, counts))
+	}
+	a.Fields = append([]*fieldCost{newFieldCost("header", header)}, a.Fields...)
+	return a, nil
+}
+
+func newFieldCost(name string, counts types.RLPCounts) *fieldCost {
+	cost := &fieldCost{Name: name, Size: counts.Size, Zeroes: counts.Zeroes, NonZeroes: counts.NonZeroes}
+	cost.L1Gas = cost.Zeroes*params.TxDataZeroGas + cost.NonZeroes*params.TxDataNonZeroGasEIP2028
+	return cost
+}
+
+// print writes the analysis in human-readable format, with the most
+// expensive fields first
+func (a *analysis) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	defer tw.Flush()
+
+	fields := make([]*fieldCost, len(a.Fields))
+	copy(fields, a.Fields)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].L1Gas > fields[j].L1Gas })
+
+	fmt.Fprintln(tw, "Field\tBytes\tZero\tNon-zero\tL1 gas\tShare\t")
+	for _, f := range append(fields, a.Total) {
+		share := 0.0
+		if a.Total.L1Gas > 0 {
+			share = float64(f.L1Gas) / float64(a.Total.L1Gas) * 100
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f%%\t\n", f.Name, f.Size, f.Zeroes, f.NonZeroes, f.L1Gas, share)
+	}
+	fmt.Fprintf(tw, "Charged L1 gas (calldata and overhead)\t\t\t\t%d\t\t\n", a.ChargedL1Gas)
+}
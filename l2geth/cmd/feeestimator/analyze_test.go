@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestAnalyze(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	data := make([]byte, 100)
+	for i := 0; i < 40; i++ {
+		data[i] = 0xff
+	}
+	tx := types.NewTransaction(1, common.HexToAddress("0x4200000000000000000000000000000000000006"), big.NewInt(0), 21000, big.NewInt(15_000_000), data)
+	tx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(10)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := analyze(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := rlp.EncodeToBytes(tx)
+	var size, l1Gas uint64
+	fields := make(map[string]*fieldCost)
+	for _, f := range a.Fields {
+		size += f.Size
+		l1Gas += f.L1Gas
+		fields[f.Name] = f
+	}
+	if size != uint64(len(encoded)) || a.Total.Size != size {
+		t.Fatalf("mismatched size: fields %d, total %d, encoded %d", size, a.Total.Size, len(encoded))
+	}
+	if l1Gas != a.Total.L1Gas {
+		t.Fatalf("mismatched L1 gas: fields %d, total %d", l1Gas, a.Total.L1Gas)
+	}
+	// The data is encoded with a two byte string header
+	calldata := fields["data"]
+	if calldata.Zeroes != 60 || calldata.NonZeroes != 42 {
+		t.Fatalf("unexpected data bytes: %d zero, %d non-zero", calldata.Zeroes, calldata.NonZeroes)
+	}
+	if calldata.L1Gas != 60*params.TxDataZeroGas+42*params.TxDataNonZeroGasEIP2028 {
+		t.Fatalf("unexpected data L1 gas: %d", calldata.L1Gas)
+	}
+	// The address has 19 zero bytes and a one byte string header
+	if to := fields["to"]; to.Zeroes != 18 || to.NonZeroes != 3 {
+		t.Fatalf("unexpected to bytes: %d zero, %d non-zero", to.Zeroes, to.NonZeroes)
+	}
+}
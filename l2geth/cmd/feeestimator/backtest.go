@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	fromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "first block of the range to backtest",
+	}
+	toFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "last block of the range to backtest",
+	}
+	scalarFlag = cli.Float64Flag{
+		Name:  "scalar",
+		Usage: "multiplier applied to the L1 fee by the alternative formula",
+		Value: 1,
+	}
+	overheadFlag = cli.Uint64Flag{
+		Name:  "overhead",
+		Usage: "fixed L1 gas overhead per transaction of the alternative formula",
+		Value: fees.Overhead,
+	}
+	compressionFlag = cli.BoolFlag{
+		Name:  "compression",
+		Usage: "estimate the L1 gas of the alternative formula from the compressed calldata",
+	}
+	formatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "output format: csv or json",
+		Value: "csv",
+	}
+)
+
+var commandBacktest = cli.Command{
+	Name:  "backtest",
+	Usage: "recompute the fees of a block range under alternative parameters",
+	Description: `
+Replay the transactions in a block range and compute the fee that each block
+would have paid under the current formula and under an alternative formula
+with a different L1 fee scalar, overhead or a calldata compression estimate.
+The revenue delta of each block is printed as CSV or JSON.
+
+The L2 gas price is read from the gas price oracle at the parent block. The
+L1 gas price is not stored in the L2 state, so a constant L1 gas price must
+be given with --l1gasprice.`,
+	Flags: []cli.Flag{
+		rpcFlag,
+		fromFlag,
+		toFlag,
+		l1GasPriceFlag,
+		scalarFlag,
+		overheadFlag,
+		compressionFlag,
+		formatFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.IsSet(rpcFlag.Name) {
+			return errors.New("Specify the node to read from with --rpc")
+		}
+		l1GasPrice, ok := new(big.Int).SetString(ctx.String(l1GasPriceFlag.Name), 10)
+		if !ok {
+			return errors.New("Specify a valid L1 gas price with --l1gasprice")
+		}
+		from, to := ctx.Uint64(fromFlag.Name), ctx.Uint64(toFlag.Name)
+		if from == 0 || to < from {
+			return errors.New("Specify a block range with --from and --to")
+		}
+		format := ctx.String(formatFlag.Name)
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("Unknown format: %s", format)
+		}
+		formula := &feeFormula{
+			scalar:      ctx.Float64(scalarFlag.Name),
+			overhead:    ctx.Uint64(overheadFlag.Name),
+			compression: ctx.Bool(compressionFlag.Name),
+		}
+		client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+		if err != nil {
+			return fmt.Errorf("Cannot connect to node: %w", err)
+		}
+		defer client.Close()
+
+		results := make([]*blockFees, 0, to-from+1)
+		for number := from; number <= to; number++ {
+			result, err := backtestBlock(client, number, l1GasPrice, formula)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		if format == "json" {
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+		return writeBacktestCSV(os.Stdout, results)
+	},
+}
+
+// feeFormula is an alternative to the fee formula used by the sequencer
+type feeFormula struct {
+	scalar      float64
+	overhead    uint64
+	compression bool
+}
+
+// l1GasUsed returns the L1 gas that the formula charges for the calldata
+func (f *feeFormula) l1GasUsed(data []byte) *big.Int {
+	if !f.compression {
+		zeroes, nonZeroes := countBytes(data)
+		return new(big.Int).SetUint64(zeroes*params.TxDataZeroGas + nonZeroes*params.TxDataNonZeroGasEIP2028 + f.overhead)
+	}
+	// Compressed data has few zero bytes, charge every byte as non-zero
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	w.Write(data)
+	w.Close()
+	return new(big.Int).SetUint64(uint64(buf.Len())*params.TxDataNonZeroGasEIP2028 + f.overhead)
+}
+
+// fee returns the fee in wei that the formula charges for a transaction
+func (f *feeFormula) fee(tx *types.Transaction, l1GasPrice, l2GasPrice *big.Int) *big.Int {
+	l1Fee := new(big.Float).SetInt(new(big.Int).Mul(f.l1GasUsed(tx.Data()), l1GasPrice))
+	l1Fee.Mul(l1Fee, big.NewFloat(f.scalar))
+	fee, _ := l1Fee.Int(nil)
+	l2GasLimit := fees.Ceilmod(fees.DecodeL2GasLimit(new(big.Int).SetUint64(tx.Gas())), fees.BigTenThousand)
+	return fee.Add(fee, new(big.Int).Mul(l2GasLimit, l2GasPrice))
+}
+
+func countBytes(data []byte) (uint64, uint64) {
+	var zeroes, nonZeroes uint64
+	for _, b := range data {
+		if b == 0 {
+			zeroes++
+		} else {
+			nonZeroes++
+		}
+	}
+	return zeroes, nonZeroes
+}
+
+// blockFees are the fees of the transactions in a block under the current
+// and the alternative formula
+type blockFees struct {
+	Number       uint64       `json:"number"`
+	Transactions int          `json:"transactions"`
+	L2GasPrice   *hexutil.Big `json:"l2GasPrice"`
+	Paid         *hexutil.Big `json:"paid"`
+	Current      *hexutil.Big `json:"current"`
+	Alternative  *hexutil.Big `json:"alternative"`
+	Delta        *hexutil.Big `json:"delta"`
+}
+
+// backtestFees computes the fees of the transactions of a block. Transactions
+// without a gas price, such as deposits from L1, do not pay fees and are
+// skipped.
+func backtestFees(number uint64, txs types.Transactions, l1GasPrice, l2GasPrice *big.Int, formula *feeFormula) *blockFees {
+	current := &feeFormula{scalar: 1, overhead: fees.Overhead}
+	result := &blockFees{
+		Number:      number,
+		L2GasPrice:  (*hexutil.Big)(l2GasPrice),
+		Paid:        (*hexutil.Big)(new(big.Int)),
+		Current:     (*hexutil.Big)(new(big.Int)),
+		Alternative: (*hexutil.Big)(new(big.Int)),
+	}
+	for _, tx := range txs {
+		if tx.GasPrice().Sign() == 0 {
+			continue
+		}
+		result.Transactions++
+		paid := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+		result.Paid.ToInt().Add(result.Paid.ToInt(), paid)
+		result.Current.ToInt().Add(result.Current.ToInt(), current.fee(tx, l1GasPrice, l2GasPrice))
+		result.Alternative.ToInt().Add(result.Alternative.ToInt(), formula.fee(tx, l1GasPrice, l2GasPrice))
+	}
+	result.Delta = (*hexutil.Big)(new(big.Int).Sub(result.Alternative.ToInt(), result.Current.ToInt()))
+	return result
+}
+
+// backtestBlock fetches a block and the L2 gas price it was sequenced with
+// and computes its fees
+func backtestBlock(client *ethclient.Client, number uint64, l1GasPrice *big.Int, formula *feeFormula) (*blockFees, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get block %d: %w", number, err)
+	}
+	parent := new(big.Int).SetUint64(number - 1)
+	reader, err := gaspriceoracle.NewCallGPOReader(client, parent)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get L2 gas price at block %d: %w", parent, err)
+	}
+	return backtestFees(number, block.Transactions(), l1GasPrice, slots.GasPrice, formula), nil
+}
+
+// writeBacktestCSV writes the fees of each block with the values in wei
+func writeBacktestCSV(w io.Writer, results []*blockFees) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"block", "transactions", "l2GasPrice", "paid", "current", "alternative", "delta"})
+	for _, r := range results {
+		out.Write([]string{
+			strconv.FormatUint(r.Number, 10),
+			strconv.Itoa(r.Transactions),
+			r.L2GasPrice.ToInt().String(),
+			r.Paid.ToInt().String(),
+			r.Current.ToInt().String(),
+			r.Alternative.ToInt().String(),
+			r.Delta.ToInt().String(),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
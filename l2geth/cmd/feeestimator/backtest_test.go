@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestBacktestFees(t *testing.T) {
+	l1GasPrice := big.NewInt(params.GWei)
+	l2GasPrice := big.NewInt(15_000_000)
+	data := make([]byte, 1000)
+	l2GasLimit := big.NewInt(100_000)
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+	txs := types.Transactions{
+		types.NewTransaction(0, common.Address{}, big.NewInt(0), gasLimit.Uint64(), fees.BigTxGasPrice, data),
+		// Deposits do not pay fees
+		types.NewTransaction(0, common.Address{}, big.NewInt(0), gasLimit.Uint64(), big.NewInt(0), data),
+	}
+
+	l1GasUsed := uint64(1000*params.TxDataZeroGas) + fees.Overhead
+	current := new(big.Int).SetUint64(l1GasUsed)
+	current.Mul(current, l1GasPrice)
+	current.Add(current, new(big.Int).Mul(l2GasLimit, l2GasPrice))
+
+	tests := map[string]struct {
+		formula *feeFormula
+		delta   *big.Int
+	}{
+		"unchanged": {
+			&feeFormula{scalar: 1, overhead: fees.Overhead},
+			big.NewInt(0),
+		},
+		"double-scalar": {
+			&feeFormula{scalar: 2, overhead: fees.Overhead},
+			new(big.Int).Mul(new(big.Int).SetUint64(l1GasUsed), l1GasPrice),
+		},
+		"no-overhead": {
+			&feeFormula{scalar: 1},
+			new(big.Int).Neg(new(big.Int).Mul(new(big.Int).SetUint64(fees.Overhead), l1GasPrice)),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := backtestFees(10, txs, l1GasPrice, l2GasPrice, tt.formula)
+			if result.Transactions != 1 {
+				t.Fatalf("unexpected transaction count: %d", result.Transactions)
+			}
+			if result.Current.ToInt().Cmp(current) != 0 {
+				t.Fatalf("mismatched current fee: got %s, expect %s", result.Current.ToInt(), current)
+			}
+			if result.Delta.ToInt().Cmp(tt.delta) != 0 {
+				t.Fatalf("mismatched delta: got %s, expect %s", result.Delta.ToInt(), tt.delta)
+			}
+		})
+	}
+}
+
+func TestBacktestCompression(t *testing.T) {
+	// Zero bytes compress well, so the compression estimate charges less
+	data := make([]byte, 10000)
+	plain := &feeFormula{scalar: 1, overhead: fees.Overhead}
+	compressed := &feeFormula{scalar: 1, overhead: fees.Overhead, compression: true}
+	if compressed.l1GasUsed(data).Cmp(plain.l1GasUsed(data)) >= 0 {
+		t.Fatalf("compressed estimate %s not lower than %s", compressed.l1GasUsed(data), plain.l1GasUsed(data))
+	}
+}
+
+func TestWriteBacktestCSV(t *testing.T) {
+	result := backtestFees(10, nil, big.NewInt(1), big.NewInt(1), &feeFormula{scalar: 1})
+	var out bytes.Buffer
+	if err := writeBacktestCSV(&out, []*blockFees{result}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1][0] != "10" || records[1][6] != "0" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+}
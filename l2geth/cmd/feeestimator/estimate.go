@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// estimateOpts are the gas price oracle parameters that the fee is
+// estimated with
+type estimateOpts struct {
+	l1GasPrice    *big.Int
+	l2GasPrice    *big.Int
+	chainID       *big.Int
+	thresholdUp   *big.Float
+	thresholdDown *big.Float
+}
+
+// breakdown is the fee that the sequencer expects for a transaction split
+// into its L1 and L2 components, compared to the fee that the transaction
+// pays
+type breakdown struct {
+	Hash               common.Hash     `json:"hash"`
+	From               *common.Address `json:"from,omitempty"`
+	Size               hexutil.Uint64  `json:"size"`
+	CalldataSize       hexutil.Uint64  `json:"calldataSize"`
+	L1GasPrice         *hexutil.Big    `json:"l1GasPrice"`
+	L1GasUsed          *hexutil.Big    `json:"l1GasUsed"`
+	L1Fee              *hexutil.Big    `json:"l1Fee"`
+	L2GasPrice         *hexutil.Big    `json:"l2GasPrice"`
+	L2GasLimit         *hexutil.Big    `json:"l2GasLimit"`
+	L2Fee              *hexutil.Big    `json:"l2Fee"`
+	TxGasPrice         *hexutil.Big    `json:"txGasPrice"`
+	TxGasLimit         hexutil.Uint64  `json:"txGasLimit"`
+	ExpectedTxGasLimit *hexutil.Big    `json:"expectedTxGasLimit"`
+	UserFee            *hexutil.Big    `json:"userFee"`
+	ExpectedFee        *hexutil.Big    `json:"expectedFee"`
+	PaysEnough         bool            `json:"paysEnough"`
+	Error              string          `json:"error,omitempty"`
+}
+
+// estimate computes the fee breakdown of a transaction the same way that the
+// sequencer verifies the fee of incoming transactions
+func estimate(tx *types.Transaction, opts *estimateOpts) (*breakdown, error) {
+	fee, err := fees.CalculateMsgFee(tx, opts.l1GasPrice, opts.l2GasPrice)
+	if err != nil {
+		return nil, err
+	}
+	l2GasLimit := fee.L2GasLimit
+	roundedL2GasLimit := fees.Ceilmod(l2GasLimit, fees.BigTenThousand)
+	l1GasUsed := new(big.Int).SetUint64(fee.L1GasUsed)
+	expectedTxGasLimit := fee.ExpectedTxGasLimit
+	userFee, expectedFee := fee.UserFee, fee.ExpectedFee
+
+	b := &breakdown{
+		Hash:               tx.Hash(),
+		Size:               hexutil.Uint64(tx.Size()),
+		CalldataSize:       hexutil.Uint64(len(tx.Data())),
+		L1GasPrice:         (*hexutil.Big)(opts.l1GasPrice),
+		L1GasUsed:          (*hexutil.Big)(l1GasUsed),
+		L1Fee:              (*hexutil.Big)(new(big.Int).Mul(l1GasUsed, opts.l1GasPrice)),
+		L2GasPrice:         (*hexutil.Big)(opts.l2GasPrice),
+		L2GasLimit:         (*hexutil.Big)(l2GasLimit),
+		L2Fee:              (*hexutil.Big)(new(big.Int).Mul(roundedL2GasLimit, opts.l2GasPrice)),
+		TxGasPrice:         (*hexutil.Big)(tx.GasPrice()),
+		TxGasLimit:         hexutil.Uint64(tx.Gas()),
+		ExpectedTxGasLimit: (*hexutil.Big)(expectedTxGasLimit),
+		UserFee:            (*hexutil.Big)(userFee),
+		ExpectedFee:        (*hexutil.Big)(expectedFee),
+	}
+	if opts.chainID != nil {
+		if from, err := types.Sender(types.NewEIP155Signer(opts.chainID), tx); err == nil {
+			b.From = &from
+		}
+	}
+	err = fees.PaysEnough(&fees.PaysEnoughOpts{
+		UserFee:       userFee,
+		ExpectedFee:   expectedFee,
+		ThresholdUp:   opts.thresholdUp,
+		ThresholdDown: opts.thresholdDown,
+	})
+	b.PaysEnough = err == nil
+	if err != nil {
+		b.Error = err.Error()
+	}
+	return b, nil
+}
+
+// print writes the breakdown in human-readable format
+func (b *breakdown) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "Hash:\t%s\n", b.Hash.Hex())
+	if b.From != nil {
+		fmt.Fprintf(tw, "From:\t%s\n", b.From.Hex())
+	}
+	fmt.Fprintf(tw, "Size:\t%d bytes (%d bytes calldata)\n", b.Size, b.CalldataSize)
+	fmt.Fprintf(tw, "L1 gas price:\t%s wei\n", b.L1GasPrice.ToInt())
+	fmt.Fprintf(tw, "L1 gas used:\t%s\n", b.L1GasUsed.ToInt())
+	fmt.Fprintf(tw, "L1 fee:\t%s wei\n", b.L1Fee.ToInt())
+	fmt.Fprintf(tw, "L2 gas price:\t%s wei\n", b.L2GasPrice.ToInt())
+	fmt.Fprintf(tw, "L2 gas limit:\t%s\n", b.L2GasLimit.ToInt())
+	fmt.Fprintf(tw, "L2 fee:\t%s wei\n", b.L2Fee.ToInt())
+	fmt.Fprintf(tw, "Tx gas price:\t%s\n", b.TxGasPrice.ToInt())
+	fmt.Fprintf(tw, "Tx gas limit:\t%d (expected %s)\n", b.TxGasLimit, b.ExpectedTxGasLimit.ToInt())
+	fmt.Fprintf(tw, "User fee:\t%s wei\n", b.UserFee.ToInt())
+	fmt.Fprintf(tw, "Expected fee:\t%s wei\n", b.ExpectedFee.ToInt())
+	if b.PaysEnough {
+		fmt.Fprintf(tw, "Result:\taccepted\n")
+	} else {
+		fmt.Fprintf(tw, "Result:\trejected: %s\n", b.Error)
+	}
+}
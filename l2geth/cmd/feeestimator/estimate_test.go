@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestEstimate(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(420)
+	signer := types.NewEIP155Signer(chainID)
+	l1GasPrice := big.NewInt(100_000_000_000)
+	l2GasPrice := big.NewInt(15_000_000)
+	data := []byte{0x00, 0x01, 0x02, 0x00}
+	l2GasLimit := big.NewInt(21000)
+
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+	tests := map[string]struct {
+		gasLimit   uint64
+		paysEnough bool
+	}{
+		"exact":    {gasLimit.Uint64(), true},
+		"too-low":  {gasLimit.Uint64() / 2, false},
+		"too-high": {gasLimit.Uint64() * 10, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), tt.gasLimit, fees.BigTxGasPrice, data)
+			tx, err := types.SignTx(tx, signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := estimate(tx, &estimateOpts{
+				l1GasPrice:  l1GasPrice,
+				l2GasPrice:  l2GasPrice,
+				chainID:     chainID,
+				thresholdUp: new(big.Float).SetFloat64(3),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.PaysEnough != tt.paysEnough {
+				t.Fatalf("mismatched result: got %t, expect %t (%s)", b.PaysEnough, tt.paysEnough, b.Error)
+			}
+			if b.From == nil || *b.From != crypto.PubkeyToAddress(key.PublicKey) {
+				t.Fatal("sender not recovered")
+			}
+			// The L2 gas limit is encoded in the lower digits of the gas
+			// limit, so it only round trips when the gas limit is unchanged
+			if tt.paysEnough && b.ExpectedTxGasLimit.ToInt().Cmp(gasLimit) != 0 {
+				t.Fatalf("mismatched expected gas limit: got %s, expect %s", b.ExpectedTxGasLimit.ToInt(), gasLimit)
+			}
+			l1GasUsed := fees.CalculateL1GasUsed(data)
+			if b.L1Fee.ToInt().Cmp(new(big.Int).Mul(l1GasUsed, l1GasPrice)) != 0 {
+				t.Fatalf("mismatched L1 fee: %s", b.L1Fee.ToInt())
+			}
+
+			var out bytes.Buffer
+			b.print(&out)
+			if !strings.Contains(out.String(), tx.Hash().Hex()) {
+				t.Fatalf("missing hash in output: %s", out.String())
+			}
+		})
+	}
+}
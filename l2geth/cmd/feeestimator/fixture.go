@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rollup/fixture"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandFixture = cli.Command{
+	Name:      "fixture",
+	Usage:     "capture the state of the gas price oracle into a test fixture",
+	ArgsUsage: "<file>",
+	Description: `
+Read the code, balance and storage slots of the OVM_GasPriceOracle from the
+node at --rpc and write them to a JSON fixture. The fixture can be loaded into
+a test state with the rollup/fixture package to reproduce the fees that the
+node charged. The L1 gas price is only captured at the latest block.`,
+	Flags: []cli.Flag{
+		rpcFlag,
+		blockFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.IsSet(rpcFlag.Name) {
+			return errors.New("Specify the node to read from with --rpc")
+		}
+		if ctx.NArg() != 1 {
+			return errors.New("Specify the file to write the fixture to")
+		}
+		client, err := rpc.Dial(ctx.String(rpcFlag.Name))
+		if err != nil {
+			return fmt.Errorf("Cannot connect to node: %w", err)
+		}
+		defer client.Close()
+		tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		f, err := fixture.Capture(tctx, client, blockNumber(ctx.Int64(blockFlag.Name)))
+		if err != nil {
+			return err
+		}
+		if err := f.Save(ctx.Args().First()); err != nil {
+			return err
+		}
+		fmt.Printf("Captured block %d of chain %s\n", f.Block, f.ChainID.ToInt())
+		return nil
+	},
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var errGPODiverged = errors.New("gas price oracle values differ")
+
+var (
+	compareRPCFlag = cli.StringFlag{
+		Name:  "rpc2",
+		Usage: "URL of a second L2 node to compare the values with, defaults to --rpc",
+	}
+	blockFlag = cli.Int64Flag{
+		Name:  "block",
+		Usage: "block number to read the values at, defaults to the latest block",
+		Value: -1,
+	}
+	compareBlockFlag = cli.Int64Flag{
+		Name:  "block2",
+		Usage: "block number to read the compared values at, defaults to --block",
+		Value: -1,
+	}
+)
+
+var commandGPO = cli.Command{
+	Name:  "gpo",
+	Usage: "print and compare the values of the gas price oracle",
+	Description: `
+Read the values of the OVM_GasPriceOracle from a node with eth_call and print
+them. When --rpc2 or --block2 is set, the values are also read from the
+second node or at the second block and the values that differ are marked,
+for example to compare a sequencer with a verifier. The command exits with
+an error when any of the values differ.`,
+	Flags: []cli.Flag{
+		rpcFlag,
+		compareRPCFlag,
+		blockFlag,
+		compareBlockFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.IsSet(rpcFlag.Name) {
+			return errors.New("Specify the node to read from with --rpc")
+		}
+		a := gpoSource{url: ctx.String(rpcFlag.Name), block: blockNumber(ctx.Int64(blockFlag.Name))}
+		values, err := a.read()
+		if err != nil {
+			return err
+		}
+		if !ctx.IsSet(compareRPCFlag.Name) && !ctx.IsSet(compareBlockFlag.Name) {
+			printGPOSlots(os.Stdout, a, values)
+			return nil
+		}
+		b := gpoSource{url: a.url, block: a.block}
+		if ctx.IsSet(compareRPCFlag.Name) {
+			b.url = ctx.String(compareRPCFlag.Name)
+		}
+		if ctx.IsSet(compareBlockFlag.Name) {
+			b.block = blockNumber(ctx.Int64(compareBlockFlag.Name))
+		}
+		compared, err := b.read()
+		if err != nil {
+			return err
+		}
+		if diffGPOSlots(os.Stdout, a, b, values, compared) {
+			return errGPODiverged
+		}
+		return nil
+	},
+}
+
+// blockNumber converts the value of a block flag, nil is the latest block
+func blockNumber(n int64) *big.Int {
+	if n < 0 {
+		return nil
+	}
+	return big.NewInt(n)
+}
+
+// gpoSource is a node and block to read the gas price oracle at
+type gpoSource struct {
+	url   string
+	block *big.Int
+}
+
+func (s gpoSource) String() string {
+	block := "latest"
+	if s.block != nil {
+		block = s.block.String()
+	}
+	return fmt.Sprintf("%s@%s", s.url, block)
+}
+
+// gpoFields are the names of the values of the gas price oracle in the order
+// that gpoValues formats them
+var gpoFields = []string{"owner", "gasPrice", "version"}
+
+// read returns the formatted values of the gas price oracle
+func (s gpoSource) read() ([]string, error) {
+	client, err := ethclient.Dial(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to %s: %w", s.url, err)
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reader, err := gaspriceoracle.NewCallGPOReader(client, s.block)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := reader.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read gas price oracle from %s: %w", s, err)
+	}
+	return gpoValues(slots), nil
+}
+
+// gpoValues formats the values of the gas price oracle
+func gpoValues(slots *rcfg.GPOStorageSlots) []string {
+	return []string{slots.Owner.Hex(), slots.GasPrice.String(), strconv.FormatUint(slots.Version, 10)}
+}
+
+// printGPOSlots writes the values read from a single source
+func printGPOSlots(w io.Writer, s gpoSource, values []string) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "Value\t%s\n", s)
+	for i, name := range gpoFields {
+		fmt.Fprintf(tw, "%s\t%s\n", name, values[i])
+	}
+}
+
+// diffGPOSlots writes the values read from two sources side by
+// side, marking the values that differ. It returns true if any value differs.
+func diffGPOSlots(w io.Writer, a, b gpoSource, values, compared []string) bool {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	diverged := false
+	fmt.Fprintf(tw, "\tValue\t%s\t%s\n", a, b)
+	for i, name := range gpoFields {
+		mark := ""
+		if values[i] != compared[i] {
+			mark = "*"
+			diverged = true
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", mark, name, values[i], compared[i])
+	}
+	return diverged
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle/contract"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testStorageAPI serves the gas price oracle slots and getters, the gas price
+// is the block number when reading at a specific block
+type testStorageAPI struct {
+	owner    common.Address
+	gasPrice *big.Int
+}
+
+// Call answers the calls of the getters of the gas price oracle
+func (api *testStorageAPI) Call(ctx context.Context, args struct {
+	To   *common.Address `json:"to"`
+	Data hexutil.Bytes   `json:"data"`
+}, block rpc.BlockNumber) (hexutil.Bytes, error) {
+	if args.To == nil || *args.To != rcfg.L2GasPriceOracleAddress {
+		return nil, nil
+	}
+	parsed, err := abi.JSON(strings.NewReader(contract.GasPriceOracleABI))
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(args.Data)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "owner":
+		return method.Outputs.Pack(api.owner)
+	case "gasPrice":
+		if block >= 0 {
+			return method.Outputs.Pack(big.NewInt(block.Int64()))
+		}
+		return method.Outputs.Pack(api.gasPrice)
+	}
+	return nil, fmt.Errorf("unexpected call of %s", method.Name)
+}
+
+func (api *testStorageAPI) GetStorageAt(ctx context.Context, address common.Address, key common.Hash, block rpc.BlockNumber) (hexutil.Bytes, error) {
+	if address != rcfg.L2GasPriceOracleAddress {
+		return common.Hash{}.Bytes(), nil
+	}
+	switch key {
+	case rcfg.L2GasPriceOracleOwnerSlot:
+		return common.BytesToHash(api.owner.Bytes()).Bytes(), nil
+	case rcfg.L2GasPriceSlot:
+		if block >= 0 {
+			return common.BigToHash(big.NewInt(block.Int64())).Bytes(), nil
+		}
+		return common.BigToHash(api.gasPrice).Bytes(), nil
+	}
+	return common.Hash{}.Bytes(), nil
+}
+
+func newTestStorageServer(t *testing.T, api *testStorageAPI) *httptest.Server {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(server)
+}
+
+func TestGPOSlots(t *testing.T) {
+	owner := common.HexToAddress("0x1234")
+	sequencer := newTestStorageServer(t, &testStorageAPI{owner: owner, gasPrice: big.NewInt(15_000_000)})
+	defer sequencer.Close()
+	verifier := newTestStorageServer(t, &testStorageAPI{owner: owner, gasPrice: big.NewInt(1_000_000)})
+	defer verifier.Close()
+
+	a := gpoSource{url: sequencer.URL}
+	values, err := a.read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	printGPOSlots(&out, a, values)
+	if !strings.Contains(out.String(), owner.Hex()) || !strings.Contains(out.String(), "15000000") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	tests := map[string]struct {
+		b        gpoSource
+		diverged bool
+	}{
+		"same-node":      {gpoSource{url: sequencer.URL}, false},
+		"diverged-node":  {gpoSource{url: verifier.URL}, true},
+		"diverged-block": {gpoSource{url: sequencer.URL, block: big.NewInt(10)}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			compared, err := tt.b.read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if diverged := diffGPOSlots(&out, a, tt.b, values, compared); diverged != tt.diverged {
+				t.Fatalf("mismatched result: got %t, expect %t\n%s", diverged, tt.diverged, out.String())
+			}
+			if tt.diverged && !strings.Contains(out.String(), "*") {
+				t.Fatalf("diverged slot not marked: %s", out.String())
+			}
+		})
+	}
+}
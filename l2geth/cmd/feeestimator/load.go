@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	keyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "hex encoded private key of the account that funds the load accounts",
+	}
+	countFlag = cli.IntFlag{
+		Name:  "count",
+		Usage: "number of transactions to send",
+		Value: 1000,
+	}
+	concurrencyFlag = cli.IntFlag{
+		Name:  "concurrency",
+		Usage: "number of accounts that send transactions concurrently",
+		Value: 8,
+	}
+	sizesFlag = cli.StringFlag{
+		Name:  "sizes",
+		Usage: "comma separated calldata sizes in bytes that the transactions are picked from",
+		Value: "0,128,1024",
+	}
+	underpayFlag = cli.Float64Flag{
+		Name:  "underpay",
+		Usage: "fraction of the transactions that pay a tenth of the expected fee",
+	}
+	overpayFlag = cli.Float64Flag{
+		Name:  "overpay",
+		Usage: "fraction of the transactions that pay ten times the expected fee",
+	}
+	badGasPriceFlag = cli.Float64Flag{
+		Name:  "badgasprice",
+		Usage: "fraction of the transactions that do not use the constant gas price",
+	}
+	fundFlag = cli.StringFlag{
+		Name:  "fund",
+		Usage: "wei that each load account is funded with before the run, 0 to skip funding",
+		Value: "1000000000000000000",
+	}
+	seedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "seed of the random transaction mix",
+		Value: 1,
+	}
+)
+
+var commandLoad = cli.Command{
+	Name:  "load",
+	Usage: "send a mix of transactions to a sequencer and report the admission results",
+	Description: `
+Send transactions to a local sequencer from a number of accounts concurrently
+and report the acceptance rate and the admission latency of each class of
+transaction. The transactions are self transfers with random calldata of the
+configured sizes. A fraction of them can be made to violate the fee policy by
+underpaying, overpaying or by using the wrong gas price, to measure the cost
+of fee enforcement for capacity planning.
+
+The load accounts are derived from --key, which funds them before the run.
+The gas prices are read once at the start of the run, so transactions that
+follow the fee policy may be rejected when the prices change during a run.`,
+	Flags: []cli.Flag{
+		rpcFlag,
+		keyFlag,
+		chainIDFlag,
+		l1GasPriceFlag,
+		l2GasPriceFlag,
+		countFlag,
+		concurrencyFlag,
+		sizesFlag,
+		underpayFlag,
+		overpayFlag,
+		badGasPriceFlag,
+		fundFlag,
+		seedFlag,
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.IsSet(rpcFlag.Name) {
+			return errors.New("Specify the sequencer to send to with --rpc")
+		}
+		if !ctx.IsSet(chainIDFlag.Name) {
+			return errors.New("Specify the chain id with --chainid")
+		}
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.String(keyFlag.Name), "0x"))
+		if err != nil {
+			return fmt.Errorf("Specify a valid private key with --key: %w", err)
+		}
+		if ctx.Int(countFlag.Name) <= 0 || ctx.Int(concurrencyFlag.Name) <= 0 {
+			return errors.New("The count and the concurrency must be positive")
+		}
+		sizes, err := parseSizes(ctx.String(sizesFlag.Name))
+		if err != nil {
+			return err
+		}
+		fund, ok := new(big.Int).SetString(ctx.String(fundFlag.Name), 10)
+		if !ok {
+			return fmt.Errorf("Invalid funding amount: %s", ctx.String(fundFlag.Name))
+		}
+		mix := &loadMix{
+			underpay:    ctx.Float64(underpayFlag.Name),
+			overpay:     ctx.Float64(overpayFlag.Name),
+			badGasPrice: ctx.Float64(badGasPriceFlag.Name),
+		}
+		if mix.underpay < 0 || mix.overpay < 0 || mix.badGasPrice < 0 || mix.underpay+mix.overpay+mix.badGasPrice > 1 {
+			return errors.New("The fractions of fee violating transactions must add up to at most 1")
+		}
+		l1GasPrice, l2GasPrice, err := gasPrices(ctx)
+		if err != nil {
+			return err
+		}
+		client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+		if err != nil {
+			return fmt.Errorf("Cannot connect to node: %w", err)
+		}
+		defer client.Close()
+
+		opts := &loadOpts{
+			signer:      types.NewEIP155Signer(new(big.Int).SetUint64(ctx.Uint64(chainIDFlag.Name))),
+			keys:        loadKeys(key, ctx.Int(concurrencyFlag.Name)),
+			count:       ctx.Int(countFlag.Name),
+			sizes:       sizes,
+			mix:         mix,
+			l1GasPrice:  l1GasPrice,
+			l2GasPrice:  l2GasPrice,
+			seed:        ctx.Int64(seedFlag.Name),
+			sendTimeout: 10 * time.Second,
+		}
+		if fund.Sign() > 0 {
+			if err := fundLoadKeys(client, key, opts, fund); err != nil {
+				return err
+			}
+		}
+		report, err := runLoad(client, opts)
+		if err != nil {
+			return err
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+		report.print(os.Stdout)
+		return nil
+	},
+}
+
+// txClass is the way that a load transaction follows or violates the fee
+// policy
+type txClass int
+
+const (
+	classValid txClass = iota
+	classUnderpay
+	classOverpay
+	classBadGasPrice
+)
+
+var txClassNames = []string{"valid", "underpay", "overpay", "badgasprice"}
+
+func (c txClass) String() string {
+	return txClassNames[c]
+}
+
+// loadMix are the fractions of the transactions that violate the fee policy,
+// the remaining transactions pay the expected fee
+type loadMix struct {
+	underpay    float64
+	overpay     float64
+	badGasPrice float64
+}
+
+// pick returns the class of the next transaction
+func (m *loadMix) pick(r *rand.Rand) txClass {
+	x := r.Float64()
+	switch {
+	case x < m.underpay:
+		return classUnderpay
+	case x < m.underpay+m.overpay:
+		return classOverpay
+	case x < m.underpay+m.overpay+m.badGasPrice:
+		return classBadGasPrice
+	}
+	return classValid
+}
+
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("Invalid calldata size: %s", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// loadKeys derives the keys of the load accounts from the funding key, so
+// that repeated runs reuse the same accounts
+func loadKeys(key *ecdsa.PrivateKey, n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		seed := crypto.Keccak256(crypto.FromECDSA(key), []byte(strconv.Itoa(i)))
+		keys[i], _ = crypto.ToECDSA(seed)
+	}
+	return keys
+}
+
+// loadClient is the part of the node API that the load generator uses
+type loadClient interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+type loadOpts struct {
+	signer      types.Signer
+	keys        []*ecdsa.PrivateKey
+	count       int
+	sizes       []int
+	mix         *loadMix
+	l1GasPrice  *big.Int
+	l2GasPrice  *big.Int
+	seed        int64
+	sendTimeout time.Duration
+}
+
+// newLoadTx creates an unsigned self transfer of the class with random
+// calldata of the given size
+func newLoadTx(nonce uint64, to common.Address, class txClass, size int, r *rand.Rand, opts *loadOpts) *types.Transaction {
+	data := make([]byte, size)
+	for i := range data {
+		if r.Intn(2) == 0 {
+			data[i] = byte(r.Intn(255) + 1)
+		}
+	}
+	zeroes, nonZeroes := countBytes(data)
+	l2GasLimit := new(big.Int).SetUint64(params.TxGas + zeroes*params.TxDataZeroGas + nonZeroes*params.TxDataNonZeroGasEIP2028)
+
+	// Scaling the gas prices keeps the L2 gas limit encoded in the gas limit
+	// while changing the fee
+	l1GasPrice, l2GasPrice := opts.l1GasPrice, opts.l2GasPrice
+	gasPrice := fees.BigTxGasPrice
+	switch class {
+	case classUnderpay:
+		l1GasPrice = new(big.Int).Div(l1GasPrice, big.NewInt(10))
+		l2GasPrice = new(big.Int).Div(l2GasPrice, big.NewInt(10))
+	case classOverpay:
+		l1GasPrice = new(big.Int).Mul(l1GasPrice, big.NewInt(10))
+		l2GasPrice = new(big.Int).Mul(l2GasPrice, big.NewInt(10))
+	case classBadGasPrice:
+		gasPrice = new(big.Int).Add(fees.BigTxGasPrice, common.Big1)
+	}
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+	return types.NewTransaction(nonce, to, new(big.Int), gasLimit.Uint64(), gasPrice, data)
+}
+
+// fundLoadKeys sends the amount from the funding key to each load account
+func fundLoadKeys(client loadClient, key *ecdsa.PrivateKey, opts *loadOpts, amount *big.Int) error {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(opts.keys))*opts.sendTimeout)
+	defer cancel()
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return fmt.Errorf("Cannot get nonce of %s: %w", from.Hex(), err)
+	}
+	gasLimit := fees.EncodeTxGasLimit(nil, opts.l1GasPrice, new(big.Int).SetUint64(params.TxGas), opts.l2GasPrice)
+	for i, k := range opts.keys {
+		to := crypto.PubkeyToAddress(k.PublicKey)
+		tx := types.NewTransaction(nonce+uint64(i), to, amount, gasLimit.Uint64(), fees.BigTxGasPrice, nil)
+		signed, err := types.SignTx(tx, opts.signer, key)
+		if err != nil {
+			return err
+		}
+		if err := client.SendTransaction(ctx, signed); err != nil {
+			return fmt.Errorf("Cannot fund %s: %w", to.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// loadResult is the admission result of a single transaction
+type loadResult struct {
+	class   txClass
+	latency time.Duration
+	err     error
+}
+
+// runLoad sends the transactions from each of the load accounts concurrently
+// and collects the admission results
+func runLoad(client loadClient, opts *loadOpts) (*loadReport, error) {
+	var (
+		results = make(chan *loadResult, opts.count)
+		work    = make(chan struct{}, opts.count)
+		wg      sync.WaitGroup
+		errc    = make(chan error, len(opts.keys))
+	)
+	for i := 0; i < opts.count; i++ {
+		work <- struct{}{}
+	}
+	close(work)
+
+	start := time.Now()
+	for i, key := range opts.keys {
+		wg.Add(1)
+		go func(i int, key *ecdsa.PrivateKey) {
+			defer wg.Done()
+			if err := loadWorker(client, key, rand.New(rand.NewSource(opts.seed+int64(i))), opts, work, results); err != nil {
+				errc <- err
+			}
+		}(i, key)
+	}
+	wg.Wait()
+	close(results)
+	close(errc)
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	report := newLoadReport(time.Since(start))
+	for result := range results {
+		report.add(result)
+	}
+	report.finish()
+	return report, nil
+}
+
+// loadWorker sends transactions from a single account until there is no work
+// left. The nonce is only advanced when a transaction is accepted.
+func loadWorker(client loadClient, key *ecdsa.PrivateKey, r *rand.Rand, opts *loadOpts, work <-chan struct{}, results chan<- *loadResult) error {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	nonce, err := pendingNonce(client, from, opts.sendTimeout)
+	if err != nil {
+		return err
+	}
+	for range work {
+		class := opts.mix.pick(r)
+		tx := newLoadTx(nonce, from, class, opts.sizes[r.Intn(len(opts.sizes))], r, opts)
+		signed, err := types.SignTx(tx, opts.signer, key)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), opts.sendTimeout)
+		start := time.Now()
+		err = client.SendTransaction(ctx, signed)
+		results <- &loadResult{class: class, latency: time.Since(start), err: err}
+		cancel()
+
+		switch {
+		case err == nil:
+			nonce++
+		case class == classValid:
+			// The nonce may be out of sync after an unexpected rejection
+			if nonce, err = pendingNonce(client, from, opts.sendTimeout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func pendingNonce(client loadClient, account common.Address, timeout time.Duration) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	nonce, err := client.PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, fmt.Errorf("Cannot get nonce of %s: %w", account.Hex(), err)
+	}
+	return nonce, nil
+}
+
+// classReport are the admission results of a class of transactions, with
+// the latencies in milliseconds
+type classReport struct {
+	Sent     int            `json:"sent"`
+	Accepted int            `json:"accepted"`
+	Rejected int            `json:"rejected"`
+	Rate     float64        `json:"acceptanceRate"`
+	P50      float64        `json:"p50LatencyMs"`
+	P99      float64        `json:"p99LatencyMs"`
+	Errors   map[string]int `json:"errors,omitempty"`
+
+	latencies []time.Duration
+}
+
+// loadReport are the admission results of a load run. Unexpected counts the
+// valid transactions that were rejected and the fee violating transactions
+// that were accepted.
+type loadReport struct {
+	Duration   float64                 `json:"durationSeconds"`
+	Sent       int                     `json:"sent"`
+	Accepted   int                     `json:"accepted"`
+	Throughput float64                 `json:"throughput"`
+	Unexpected int                     `json:"unexpected"`
+	Classes    map[string]*classReport `json:"classes"`
+	P99        float64                 `json:"p99LatencyMs"`
+
+	latencies []time.Duration
+}
+
+func newLoadReport(duration time.Duration) *loadReport {
+	return &loadReport{
+		Duration: duration.Seconds(),
+		Classes:  make(map[string]*classReport),
+	}
+}
+
+func (r *loadReport) add(result *loadResult) {
+	c, ok := r.Classes[result.class.String()]
+	if !ok {
+		c = &classReport{Errors: make(map[string]int)}
+		r.Classes[result.class.String()] = c
+	}
+	r.Sent++
+	c.Sent++
+	c.latencies = append(c.latencies, result.latency)
+	r.latencies = append(r.latencies, result.latency)
+	if result.err == nil {
+		r.Accepted++
+		c.Accepted++
+		if result.class != classValid {
+			r.Unexpected++
+		}
+		return
+	}
+	c.Rejected++
+	if result.class == classValid {
+		r.Unexpected++
+	}
+	// Group the errors by their prefix, the rest of the message holds the
+	// fee of the transaction
+	reason := result.err.Error()
+	if i := strings.Index(reason, ":"); i > 0 {
+		reason = reason[:i]
+	}
+	c.Errors[reason]++
+}
+
+func (r *loadReport) finish() {
+	if r.Duration > 0 {
+		r.Throughput = float64(r.Sent) / r.Duration
+	}
+	r.P99 = latencyPercentile(r.latencies, 99)
+	for _, c := range r.Classes {
+		c.Rate = float64(c.Accepted) / float64(c.Sent)
+		c.P50 = latencyPercentile(c.latencies, 50)
+		c.P99 = latencyPercentile(c.latencies, 99)
+	}
+}
+
+// latencyPercentile returns the nearest rank percentile of the latencies in
+// milliseconds, the latencies are sorted in place
+func latencyPercentile(latencies []time.Duration, p int) float64 {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	rank := (p*len(latencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return float64(latencies[rank-1]) / float64(time.Millisecond)
+}
+
+// print writes the report in human-readable format
+func (r *loadReport) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "Sent\t%d in %.1fs (%.1f tx/s)\n", r.Sent, r.Duration, r.Throughput)
+	fmt.Fprintf(tw, "Accepted\t%d\n", r.Accepted)
+	fmt.Fprintf(tw, "Unexpected\t%d\n", r.Unexpected)
+	fmt.Fprintf(tw, "p99 latency\t%.2fms\n\n", r.P99)
+	fmt.Fprintln(tw, "Class\tSent\tAccepted\tRate\tp50\tp99\tErrors")
+	for _, name := range txClassNames {
+		c, ok := r.Classes[name]
+		if !ok {
+			continue
+		}
+		var errs []string
+		for reason, count := range c.Errors {
+			errs = append(errs, fmt.Sprintf("%s (%d)", reason, count))
+		}
+		sort.Strings(errs)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%.2fms\t%.2fms\t%s\n", name, c.Sent, c.Accepted, c.Rate*100, c.P50, c.P99, strings.Join(errs, ", "))
+	}
+}
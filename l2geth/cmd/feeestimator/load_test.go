@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// fakeSequencer admits transactions with the same fee checks as the
+// sequencer and tracks the nonces of the senders
+type fakeSequencer struct {
+	mu         sync.Mutex
+	signer     types.Signer
+	l1GasPrice *big.Int
+	l2GasPrice *big.Int
+	nonces     map[common.Address]uint64
+}
+
+func (s *fakeSequencer) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from, err := types.Sender(s.signer, tx)
+	if err != nil {
+		return err
+	}
+	if tx.Nonce() != s.nonces[from] {
+		return errors.New("invalid nonce")
+	}
+	if tx.GasPrice().Cmp(fees.BigTxGasPrice) != 0 {
+		return errors.New("tx.gasPrice must be 1000000")
+	}
+	l2GasLimit := fees.DecodeL2GasLimit(new(big.Int).SetUint64(tx.Gas()))
+	expected := fees.EncodeTxGasLimit(tx.Data(), s.l1GasPrice, l2GasLimit, s.l2GasPrice)
+	err = fees.PaysEnough(&fees.PaysEnoughOpts{
+		UserFee:     new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()),
+		ExpectedFee: new(big.Int).Mul(expected, fees.BigTxGasPrice),
+		ThresholdUp: new(big.Float).SetFloat64(3),
+	})
+	if err != nil {
+		return err
+	}
+	s.nonces[from]++
+	return nil
+}
+
+func (s *fakeSequencer) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nonces[account], nil
+}
+
+func TestRunLoad(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	l1GasPrice, l2GasPrice := big.NewInt(100_000_000_000), big.NewInt(15_000_000)
+	sequencer := &fakeSequencer{
+		signer:     signer,
+		l1GasPrice: l1GasPrice,
+		l2GasPrice: l2GasPrice,
+		nonces:     make(map[common.Address]uint64),
+	}
+	opts := &loadOpts{
+		signer:      signer,
+		keys:        loadKeys(key, 4),
+		count:       400,
+		sizes:       []int{0, 64, 512},
+		mix:         &loadMix{underpay: 0.2, overpay: 0.1, badGasPrice: 0.1},
+		l1GasPrice:  l1GasPrice,
+		l2GasPrice:  l2GasPrice,
+		seed:        1,
+		sendTimeout: time.Second,
+	}
+	report, err := runLoad(sequencer, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Sent != opts.count {
+		t.Fatalf("mismatched sent: got %d, expect %d", report.Sent, opts.count)
+	}
+	if report.Unexpected != 0 {
+		t.Fatalf("unexpected admission results: %d", report.Unexpected)
+	}
+	valid := report.Classes[classValid.String()]
+	if valid == nil || valid.Rate != 1 {
+		t.Fatalf("valid transactions rejected: %+v", valid)
+	}
+	for _, class := range []txClass{classUnderpay, classOverpay, classBadGasPrice} {
+		c := report.Classes[class.String()]
+		if c == nil || c.Sent == 0 || c.Accepted != 0 {
+			t.Fatalf("mismatched %s results: %+v", class, c)
+		}
+	}
+	if _, ok := report.Classes[classUnderpay.String()].Errors[fees.ErrFeeTooLow.Error()]; !ok {
+		t.Fatalf("missing fee too low errors: %v", report.Classes[classUnderpay.String()].Errors)
+	}
+	// The accepted transactions advance the nonces of the load accounts
+	var nonces uint64
+	for _, nonce := range sequencer.nonces {
+		nonces += nonce
+	}
+	if nonces != uint64(valid.Accepted) {
+		t.Fatalf("mismatched nonces: got %d, expect %d", nonces, valid.Accepted)
+	}
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	tests := map[int]float64{50: 50, 99: 99, 100: 100, 1: 1}
+	for p, expect := range tests {
+		if got := latencyPercentile(latencies, p); got != expect {
+			t.Fatalf("mismatched p%d: got %f, expect %f", p, got, expect)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// feeestimator prints the breakdown of the fee that the sequencer charges
+// for a transaction without running a node.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+var (
+	txFlag = cli.StringFlag{
+		Name:  "tx",
+		Usage: "hex encoded raw transaction, read from the file argument when not set",
+	}
+	l1GasPriceFlag = cli.StringFlag{
+		Name:  "l1gasprice",
+		Usage: "L1 gas price in wei, overrides the value from --rpc",
+	}
+	l2GasPriceFlag = cli.StringFlag{
+		Name:  "l2gasprice",
+		Usage: "L2 gas price in wei, overrides the value from --rpc",
+	}
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "URL of an L2 node to read the gas prices and slots from",
+	}
+	chainIDFlag = cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "chain id used to recover the sender of the transaction",
+	}
+	thresholdDownFlag = cli.Float64Flag{
+		Name:  "thresholddown",
+		Usage: "fraction of the expected fee that the sequencer accepts, see --rollup.feethresholddown",
+	}
+	thresholdUpFlag = cli.Float64Flag{
+		Name:  "thresholdup",
+		Usage: "fraction of the expected fee that the sequencer accepts as overpayment, see --rollup.feethresholdup",
+	}
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "output JSON instead of human-readable format",
+	}
+)
+
+func init() {
+	// The app is not created with utils.NewApp so that the estimator does
+	// not link in the node
+	app = cli.NewApp()
+	app.Name = filepath.Base(os.Args[0])
+	app.Version = params.VersionWithCommit(gitCommit, gitDate)
+	app.Usage = "an offline fee estimator for rollup transactions"
+	app.ArgsUsage = "[<txfile>]"
+	app.Flags = []cli.Flag{
+		txFlag,
+		l1GasPriceFlag,
+		l2GasPriceFlag,
+		rpcFlag,
+		chainIDFlag,
+		thresholdDownFlag,
+		thresholdUpFlag,
+		jsonFlag,
+	}
+	app.Action = estimateFee
+	app.Commands = []cli.Command{
+		commandAnalyze,
+		commandGPO,
+		commandBacktest,
+		commandLoad,
+		commandFixture,
+		commandThresholds,
+	}
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func estimateFee(ctx *cli.Context) error {
+	tx, err := readTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	l1GasPrice, l2GasPrice, err := gasPrices(ctx)
+	if err != nil {
+		return err
+	}
+	opts := &estimateOpts{
+		l1GasPrice: l1GasPrice,
+		l2GasPrice: l2GasPrice,
+	}
+	if ctx.IsSet(chainIDFlag.Name) {
+		opts.chainID = new(big.Int).SetUint64(ctx.Uint64(chainIDFlag.Name))
+	}
+	if ctx.IsSet(thresholdDownFlag.Name) {
+		opts.thresholdDown = new(big.Float).SetFloat64(ctx.Float64(thresholdDownFlag.Name))
+	}
+	if ctx.IsSet(thresholdUpFlag.Name) {
+		opts.thresholdUp = new(big.Float).SetFloat64(ctx.Float64(thresholdUpFlag.Name))
+	}
+	b, err := estimate(tx, opts)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool(jsonFlag.Name) {
+		out, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	b.print(os.Stdout)
+	return nil
+}
+
+// readTransaction decodes the transaction from the --tx flag or from the file
+// argument, which may hold the transaction as hex or as raw bytes
+func readTransaction(ctx *cli.Context) (*types.Transaction, error) {
+	var data []byte
+	switch {
+	case ctx.IsSet(txFlag.Name):
+		data = []byte(ctx.String(txFlag.Name))
+	case ctx.NArg() == 1:
+		var err error
+		data, err = ioutil.ReadFile(ctx.Args().First())
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read transaction file: %w", err)
+		}
+	default:
+		return nil, errors.New("Specify the transaction with --tx or a file argument")
+	}
+	if trimmed := strings.TrimPrefix(string(bytes.TrimSpace(data)), "0x"); isHex(trimmed) {
+		decoded, err := hex.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot decode transaction hex: %w", err)
+		}
+		data = decoded
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(data, tx); err != nil {
+		return nil, fmt.Errorf("Cannot decode transaction: %w", err)
+	}
+	return tx, nil
+}
+
+func isHex(s string) bool {
+	if len(s) == 0 || len(s)%2 != 0 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// gasPrices returns the gas prices from the flags, reading the ones that are
+// not set from the node at --rpc
+func gasPrices(ctx *cli.Context) (*big.Int, *big.Int, error) {
+	var l1GasPrice, l2GasPrice *big.Int
+	if ctx.IsSet(l1GasPriceFlag.Name) {
+		price, ok := new(big.Int).SetString(ctx.String(l1GasPriceFlag.Name), 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("Invalid L1 gas price: %s", ctx.String(l1GasPriceFlag.Name))
+		}
+		l1GasPrice = price
+	}
+	if ctx.IsSet(l2GasPriceFlag.Name) {
+		price, ok := new(big.Int).SetString(ctx.String(l2GasPriceFlag.Name), 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("Invalid L2 gas price: %s", ctx.String(l2GasPriceFlag.Name))
+		}
+		l2GasPrice = price
+	}
+	if l1GasPrice != nil && l2GasPrice != nil {
+		return l1GasPrice, l2GasPrice, nil
+	}
+	if !ctx.IsSet(rpcFlag.Name) {
+		return nil, nil, errors.New("Specify the gas prices with --l1gasprice and --l2gasprice or a node with --rpc")
+	}
+	client, err := rpc.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Cannot connect to node: %w", err)
+	}
+	defer client.Close()
+	tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var prices struct {
+		L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+		L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	}
+	if err := client.CallContext(tctx, &prices, "rollup_gasPrices"); err != nil {
+		return nil, nil, fmt.Errorf("Cannot fetch gas prices: %w", err)
+	}
+	if prices.L1GasPrice == nil || prices.L2GasPrice == nil {
+		return nil, nil, errors.New("Node returned no gas prices")
+	}
+	if l1GasPrice == nil {
+		l1GasPrice = prices.L1GasPrice.ToInt()
+	}
+	if l2GasPrice == nil {
+		l2GasPrice = prices.L2GasPrice.ToInt()
+	}
+	return l1GasPrice, l2GasPrice, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	upFlag = cli.StringFlag{
+		Name:  "up",
+		Usage: "comma separated candidates of the fee threshold up, off does not check overpaying",
+		Value: "off",
+	}
+	downFlag = cli.StringFlag{
+		Name:  "down",
+		Usage: "comma separated candidates of the fee threshold down, off requires the full fee",
+		Value: "off",
+	}
+)
+
+var commandThresholds = cli.Command{
+	Name:      "thresholds",
+	Usage:     "replay recorded fee checks against candidate fee thresholds",
+	ArgsUsage: "<recordfile> [<recordfile>...]",
+	Description: `
+Replay the fee checks recorded by a sequencer with --rollup.feecheckrecord
+against every combination of the candidates of the fee thresholds, and print
+the number of transactions that would have been rejected for paying too
+little or too much under each setting as CSV or JSON.
+
+Fee checks of transactions whose fee policy sets its own thresholds keep
+those thresholds.`,
+	Flags: []cli.Flag{
+		upFlag,
+		downFlag,
+		formatFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() == 0 {
+			return errors.New("Specify the files of recorded fee checks")
+		}
+		format := ctx.String(formatFlag.Name)
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("Unknown format: %s", format)
+		}
+		ups, err := parseThresholds(ctx.String(upFlag.Name))
+		if err != nil {
+			return fmt.Errorf("Invalid --up: %w", err)
+		}
+		downs, err := parseThresholds(ctx.String(downFlag.Name))
+		if err != nil {
+			return fmt.Errorf("Invalid --down: %w", err)
+		}
+		var records []*fees.FeeCheckRecord
+		for _, path := range ctx.Args() {
+			read, err := readFeeCheckRecords(path)
+			if err != nil {
+				return err
+			}
+			records = append(records, read...)
+		}
+		var candidates []fees.Thresholds
+		for _, up := range ups {
+			for _, down := range downs {
+				candidates = append(candidates, fees.Thresholds{Up: up, Down: down})
+			}
+		}
+		results := fees.BacktestThresholds(records, candidates)
+		if format == "json" {
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+		return writeThresholdsCSV(os.Stdout, results)
+	},
+}
+
+// parseThresholds parses a comma separated list of thresholds, in which off
+// is an unset threshold
+func parseThresholds(list string) ([]*float64, error) {
+	var thresholds []*float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "off" {
+			thresholds = append(thresholds, nil)
+			continue
+		}
+		threshold, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		if threshold < 0 {
+			return nil, fmt.Errorf("negative threshold: %s", field)
+		}
+		thresholds = append(thresholds, &threshold)
+	}
+	return thresholds, nil
+}
+
+// readFeeCheckRecords reads a file of fee checks with a JSON record per line
+func readFeeCheckRecords(path string) ([]*fees.FeeCheckRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []*fees.FeeCheckRecord
+	dec := json.NewDecoder(file)
+	for {
+		var record fees.FeeCheckRecord
+		if err := dec.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("Cannot decode fee check %d of %s: %w", len(records)+1, path, err)
+		}
+		records = append(records, &record)
+	}
+}
+
+// writeThresholdsCSV writes the number of rejected transactions under each
+// setting of the thresholds
+func writeThresholdsCSV(w io.Writer, results []*fees.ThresholdBacktest) error {
+	format := func(threshold *float64) string {
+		if threshold == nil {
+			return "off"
+		}
+		return strconv.FormatFloat(*threshold, 'f', -1, 64)
+	}
+	out := csv.NewWriter(w)
+	out.Write([]string{"thresholdUp", "thresholdDown", "checks", "tooLow", "tooHigh", "rejected"})
+	for _, r := range results {
+		out.Write([]string{
+			format(r.Up),
+			format(r.Down),
+			strconv.Itoa(r.Checks),
+			strconv.Itoa(r.TooLow),
+			strconv.Itoa(r.TooHigh),
+			strconv.Itoa(r.Rejected),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := parseThresholds("off, 0.9,1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thresholds) != 3 || thresholds[0] != nil || *thresholds[1] != 0.9 || *thresholds[2] != 1 {
+		t.Fatalf("unexpected thresholds: %v", thresholds)
+	}
+	for _, list := range []string{"", "1,", "-1", "none"} {
+		if _, err := parseThresholds(list); err == nil {
+			t.Fatalf("parsed invalid list %q", list)
+		}
+	}
+}
+
+func TestReadFeeCheckRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "feeestimator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "records.jsonl")
+	lines := `{"time":1,"userFee":"0x3e8","expectedFee":"0x3e8"}
+{"time":2,"userFee":"0x3e8","expectedFee":"0x1f4","thresholdUp":1}
+`
+	if err := ioutil.WriteFile(path, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := readFeeCheckRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("mismatched records: got %d, expect 2", len(records))
+	}
+	if records[0].ThresholdUp != nil || records[1].ThresholdUp == nil || *records[1].ThresholdUp != 1 {
+		t.Fatalf("unexpected policy thresholds: %v, %v", records[0].ThresholdUp, records[1].ThresholdUp)
+	}
+	if records[1].ExpectedFee.ToInt().Int64() != 500 {
+		t.Fatalf("mismatched expected fee: got %d, expect 500", records[1].ExpectedFee.ToInt())
+	}
+}
@@ -0,0 +1,130 @@
+// feeexporter exports the fee components of every block and the L1 cost of
+// every transaction batch of a rollup node to Postgres.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/feesql"
+	"github.com/ethereum/go-ethereum/rpc"
+	_ "github.com/lib/pq"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+var (
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "URL of the L2 node that the fees are read from, which must expose the rollup_personal namespace",
+		Value: "http://localhost:8545",
+	}
+	dbFlag = cli.StringFlag{
+		Name:  "db",
+		Usage: "connection string of the Postgres database",
+	}
+	intervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Usage: "interval between exports",
+		Value: feesql.DefaultInterval,
+	}
+	onceFlag = cli.BoolFlag{
+		Name:  "once",
+		Usage: "export up to the head of the node once and exit",
+	}
+	labelsFlag = cli.StringFlag{
+		Name:  "labels",
+		Usage: "JSON file of the labels of contract addresses to aggregate the fees of applications by",
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = filepath.Base(os.Args[0])
+	app.Version = params.VersionWithCommit(gitCommit, gitDate)
+	app.Usage = "an exporter of the rollup fee and batch economics to Postgres"
+	app.Flags = []cli.Flag{
+		rpcFlag,
+		dbFlag,
+		intervalFlag,
+		onceFlag,
+		labelsFlag,
+	}
+	app.Action = run
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx *cli.Context) error {
+	if !ctx.IsSet(dbFlag.Name) {
+		return errors.New("Specify the Postgres database with --db")
+	}
+	db, err := sql.Open("postgres", ctx.String(dbFlag.Name))
+	if err != nil {
+		return fmt.Errorf("Cannot open database: %w", err)
+	}
+	defer db.Close()
+
+	client, err := rpc.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		return fmt.Errorf("Cannot connect to node: %w", err)
+	}
+	defer client.Close()
+
+	loopCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+		<-sigc
+		stop()
+	}()
+
+	exporter := feesql.New(db)
+	if err := exporter.Migrate(loopCtx); err != nil {
+		return err
+	}
+	if ctx.IsSet(labelsFlag.Name) {
+		raw, err := ioutil.ReadFile(ctx.String(labelsFlag.Name))
+		if err != nil {
+			return fmt.Errorf("Cannot read labels: %w", err)
+		}
+		var labels map[common.Address]string
+		if err := json.Unmarshal(raw, &labels); err != nil {
+			return fmt.Errorf("Cannot decode labels: %w", err)
+		}
+		if err := exporter.WriteDappLabels(loopCtx, labels); err != nil {
+			return err
+		}
+		log.Info("Wrote dapp labels", "count", len(labels))
+	}
+	source := feesql.NewRPCSource(client)
+	if ctx.Bool(onceFlag.Name) {
+		return exporter.Sync(loopCtx, source)
+	}
+	log.Info("Fee exporter started", "rpc", ctx.String(rpcFlag.Name), "interval", ctx.Duration(intervalFlag.Name))
+	exporter.Loop(loopCtx, source, ctx.Duration(intervalFlag.Name))
+	return nil
+}
@@ -0,0 +1,172 @@
+// feesweeper withdraws the balances of the fee vaults to L1 once they reach
+// their thresholds, with transactions signed by an external signer.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/sweeper"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+var (
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "URL of the L2 node that the vaults are read from and withdrawals are sent to",
+		Value: "http://localhost:8545",
+	}
+	signerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "URL of the external signer, such as clef, that signs the withdrawals",
+	}
+	accountFlag = cli.StringFlag{
+		Name:  "account",
+		Usage: "address of the account that sends the withdrawals, the only account of the signer when not set",
+	}
+	vaultsFlag = cli.StringFlag{
+		Name:  "vaults",
+		Usage: "JSON file of the swept fee vaults and their thresholds by name",
+	}
+	intervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Usage: "how often the balances of the vaults are polled",
+		Value: time.Minute,
+	}
+	// The metrics are only collected when the process is started with
+	// --metrics, see metrics.Enabled
+	metricsFlag = cli.BoolFlag{
+		Name:  "metrics",
+		Usage: "enable metrics collection and reporting",
+	}
+	metricsAddrFlag = cli.StringFlag{
+		Name:  "metrics.addr",
+		Usage: "listening address of the prometheus metrics endpoint",
+		Value: "localhost:6061",
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = filepath.Base(os.Args[0])
+	app.Version = params.VersionWithCommit(gitCommit, gitDate)
+	app.Usage = "a service that sweeps the fee vaults to L1"
+	app.Flags = []cli.Flag{
+		rpcFlag,
+		signerFlag,
+		accountFlag,
+		vaultsFlag,
+		intervalFlag,
+		metricsFlag,
+		metricsAddrFlag,
+	}
+	app.Action = run
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx *cli.Context) error {
+	if !ctx.IsSet(vaultsFlag.Name) {
+		return errors.New("Specify the swept vaults with --vaults")
+	}
+	vaults, err := sweeper.LoadVaults(ctx.String(vaultsFlag.Name))
+	if err != nil {
+		return err
+	}
+	if !ctx.IsSet(signerFlag.Name) {
+		return errors.New("Specify the external signer with --signer")
+	}
+	signer, err := external.NewExternalSigner(ctx.String(signerFlag.Name))
+	if err != nil {
+		return fmt.Errorf("Cannot connect to signer: %w", err)
+	}
+	account, err := sweeperAccount(ctx, signer)
+	if err != nil {
+		return err
+	}
+	client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		return fmt.Errorf("Cannot connect to node: %w", err)
+	}
+	defer client.Close()
+
+	tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	chainID, err := client.ChainID(tctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Cannot fetch chain id: %w", err)
+	}
+	s, err := sweeper.New(client, signer, sweeper.Config{
+		Vaults:   vaults,
+		Account:  account,
+		ChainID:  chainID,
+		Interval: ctx.Duration(intervalFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	if metrics.Enabled {
+		addr := ctx.String(metricsAddrFlag.Name)
+		log.Info("Starting metrics server", "addr", fmt.Sprintf("http://%s/debug/metrics/prometheus", addr))
+		mux := http.NewServeMux()
+		mux.Handle("/debug/metrics/prometheus", prometheus.Handler(metrics.DefaultRegistry))
+		go func() {
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				log.Error("Failure in running metrics server", "err", err)
+			}
+		}()
+	}
+
+	loopCtx, stop := context.WithCancel(context.Background())
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+		<-sigc
+		stop()
+	}()
+	log.Info("Fee vault sweeper started", "account", account.Hex(), "vaults", len(vaults))
+	s.Loop(loopCtx)
+	return nil
+}
+
+// sweeperAccount returns the account that sends the withdrawals
+func sweeperAccount(ctx *cli.Context, signer *external.ExternalSigner) (common.Address, error) {
+	if ctx.IsSet(accountFlag.Name) {
+		if !common.IsHexAddress(ctx.String(accountFlag.Name)) {
+			return common.Address{}, fmt.Errorf("Invalid account address: %q", ctx.String(accountFlag.Name))
+		}
+		return common.HexToAddress(ctx.String(accountFlag.Name)), nil
+	}
+	accounts := signer.Accounts()
+	if len(accounts) != 1 {
+		return common.Address{}, fmt.Errorf("Signer has %d accounts, specify the account with --account", len(accounts))
+	}
+	return accounts[0].Address, nil
+}
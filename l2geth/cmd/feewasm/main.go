@@ -0,0 +1,174 @@
+//go:build js && wasm
+// +build js,wasm
+
+// feewasm exposes the fee calculation of the sequencer to JavaScript, so that
+// wallets can compute fees locally from the gas price oracle parameters.
+//
+// The functions are set on the global rollupFees object. Numbers are passed
+// and returned as decimal or 0x prefixed hex strings and calldata as hex.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"syscall/js"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func main() {
+	js.Global().Set("rollupFees", map[string]interface{}{
+		"txGasPrice":       strconv.FormatUint(fees.TxGasPrice, 10),
+		"l1GasUsed":        wrap(l1GasUsed),
+		"encodeTxGasLimit": wrap(encodeTxGasLimit),
+		"decodeL2GasLimit": wrap(decodeL2GasLimit),
+		"fee":              wrap(fee),
+		"paysEnough":       wrap(paysEnough),
+	})
+	// Keep the functions available to JavaScript
+	select {}
+}
+
+// wrap converts a function over strings into a JavaScript function, which
+// returns an Error on failure
+func wrap(fn func(args []string) (string, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		strs := make([]string, len(args))
+		for i, arg := range args {
+			strs[i] = arg.String()
+		}
+		result, err := fn(strs)
+		if err != nil {
+			return js.Global().Get("Error").New(err.Error())
+		}
+		return result
+	})
+}
+
+func parseArgs(args []string, names ...string) error {
+	if len(args) != len(names) {
+		return fmt.Errorf("expected %d arguments: %v", len(names), names)
+	}
+	return nil
+}
+
+func parseBig(name, s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s: %s", name, s)
+	}
+	return n, nil
+}
+
+func parseData(s string) ([]byte, error) {
+	if s == "" || s == "0x" {
+		return nil, nil
+	}
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %v", err)
+	}
+	return data, nil
+}
+
+// l1GasUsed(data) returns the L1 gas charged for the calldata
+func l1GasUsed(args []string) (string, error) {
+	if err := parseArgs(args, "data"); err != nil {
+		return "", err
+	}
+	data, err := parseData(args[0])
+	if err != nil {
+		return "", err
+	}
+	return fees.CalculateL1GasUsed(data).String(), nil
+}
+
+// encodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice) returns the
+// tx.gasLimit that pays the fee at tx.gasPrice = txGasPrice
+func encodeTxGasLimit(args []string) (string, error) {
+	gasLimit, err := txGasLimit(args)
+	if err != nil {
+		return "", err
+	}
+	return gasLimit.String(), nil
+}
+
+// fee(data, l1GasPrice, l2GasLimit, l2GasPrice) returns the fee in wei
+func fee(args []string) (string, error) {
+	gasLimit, err := txGasLimit(args)
+	if err != nil {
+		return "", err
+	}
+	return gasLimit.Mul(gasLimit, fees.BigTxGasPrice).String(), nil
+}
+
+func txGasLimit(args []string) (*big.Int, error) {
+	if err := parseArgs(args, "data", "l1GasPrice", "l2GasLimit", "l2GasPrice"); err != nil {
+		return nil, err
+	}
+	data, err := parseData(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var values [3]*big.Int
+	for i, name := range []string{"l1GasPrice", "l2GasLimit", "l2GasPrice"} {
+		if values[i], err = parseBig(name, args[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return fees.EncodeTxGasLimit(data, values[0], values[1], values[2]), nil
+}
+
+// decodeL2GasLimit(gasLimit) returns the L2 gas limit encoded in tx.gasLimit
+func decodeL2GasLimit(args []string) (string, error) {
+	if err := parseArgs(args, "gasLimit"); err != nil {
+		return "", err
+	}
+	gasLimit, err := parseBig("gasLimit", args[0])
+	if err != nil {
+		return "", err
+	}
+	return fees.DecodeL2GasLimit(gasLimit).String(), nil
+}
+
+// paysEnough(userFee, expectedFee, thresholdDown, thresholdUp) returns an
+// empty string if the fee is accepted, or the reason it is rejected. The
+// thresholds are optional and may be empty.
+func paysEnough(args []string) (string, error) {
+	if err := parseArgs(args, "userFee", "expectedFee", "thresholdDown", "thresholdUp"); err != nil {
+		return "", err
+	}
+	opts := new(fees.PaysEnoughOpts)
+	var err error
+	if opts.UserFee, err = parseBig("userFee", args[0]); err != nil {
+		return "", err
+	}
+	if opts.ExpectedFee, err = parseBig("expectedFee", args[1]); err != nil {
+		return "", err
+	}
+	if opts.ThresholdDown, err = parseThreshold("thresholdDown", args[2]); err != nil {
+		return "", err
+	}
+	if opts.ThresholdUp, err = parseThreshold("thresholdUp", args[3]); err != nil {
+		return "", err
+	}
+	err = fees.PaysEnough(opts)
+	if errors.Is(err, fees.ErrFeeTooLow) || errors.Is(err, fees.ErrFeeTooHigh) {
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+func parseThreshold(name, s string) (*big.Float, error) {
+	if s == "" || s == "undefined" || s == "null" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", name, s)
+	}
+	return new(big.Float).SetFloat64(f), nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	cli "gopkg.in/urfave/cli.v1"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+var checkConfigCommand = cli.Command{
+	Action:    utils.MigrateFlags(checkConfig),
+	Name:      "check-config",
+	Usage:     "Validate configuration values and show the effective configuration",
+	ArgsUsage: "[<genesisPath>]",
+	Flags:     append(append(append(nodeFlags, optimismFlags...), rpcFlags...), whisperFlags...),
+	Category:  "MISCELLANEOUS COMMANDS",
+	Description: `
+The check-config command loads the configuration from the same flags and
+config file as the node, validates it and prints the effective configuration.
+It exits with an error when the node would refuse to start with it, so that
+a configuration can be checked before the node is restarted.
+
+The fork ordering and the OVM_GasPriceOracle are checked against the genesis
+file when one is given, or against the developer genesis in dev mode.`,
+}
+
+// configCheck is the outcome of one group of checks of check-config
+type configCheck struct {
+	name    string
+	err     error
+	warning string
+}
+
+// checkConfig is the check-config command.
+func checkConfig(ctx *cli.Context) error {
+	_, cfg := makeConfigNode(ctx)
+
+	genesis := cfg.Eth.Genesis
+	if path := ctx.Args().First(); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("Failed to read genesis file: %v", err)
+		}
+		defer file.Close()
+
+		genesis = new(core.Genesis)
+		if err := json.NewDecoder(file).Decode(genesis); err != nil {
+			return fmt.Errorf("invalid genesis file: %v", err)
+		}
+	}
+	// The gas price oracle is read where the chain config puts it
+	if genesis != nil {
+		rcfg.Configure(genesis.Config)
+	}
+	checks := []configCheck{
+		checkRollupConfig(&cfg.Eth.Rollup, genesis),
+		checkChainConfig(genesis),
+		checkGasPriceOracle(&cfg.Eth.Rollup, genesis),
+	}
+	failed := writeConfigChecks(os.Stdout, checks)
+
+	// The effective config holds the parameters filled in by the network
+	// profile, but neither the genesis nor any secret
+	cfg.Eth.Genesis = nil
+	redacted := redactRollupConfig(&cfg.Eth.Rollup)
+	out, err := tomlSettings.Marshal(&cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	os.Stdout.Write(out)
+	// The rollup config is only set from flags, it is not part of the TOML
+	// config file and is shown as JSON
+	out, err = json.MarshalIndent(&cfg.Eth.Rollup, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n# Rollup\n%s\n", out)
+	if len(redacted) != 0 {
+		fmt.Printf("# Redacted: %s\n", strings.Join(redacted, ", "))
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d configuration checks failed", failed)
+	}
+	return nil
+}
+
+// writeConfigChecks writes a line per check and returns the number of checks
+// that failed
+func writeConfigChecks(w io.Writer, checks []configCheck) int {
+	failed := 0
+	for _, check := range checks {
+		switch {
+		case check.err != nil:
+			failed++
+			fmt.Fprintf(w, "error    %s: %v\n", check.name, check.err)
+		case check.warning != "":
+			fmt.Fprintf(w, "warning  %s: %s\n", check.name, check.warning)
+		default:
+			fmt.Fprintf(w, "ok       %s\n", check.name)
+		}
+	}
+	return failed
+}
+
+// redactRollupConfig clears the secrets of the rollup config and returns the
+// names of those that were set
+func redactRollupConfig(cfg *rollup.Config) []string {
+	var redacted []string
+	keys := []struct {
+		name string
+		key  **ecdsa.PrivateKey
+	}{
+		{"FeeQuoteKey", &cfg.FeeQuoteKey},
+		{"FeeAttestationKey", &cfg.FeeAttestationKey},
+		{"FeeCollectorKey", &cfg.FeeCollectorKey},
+		{"FeeRebateKey", &cfg.FeeRebateKey},
+	}
+	for _, k := range keys {
+		if *k.key != nil {
+			*k.key = nil
+			redacted = append(redacted, k.name)
+		}
+	}
+	if cfg.FeeAnomalyRoutingKey != "" {
+		cfg.FeeAnomalyRoutingKey = ""
+		redacted = append(redacted, "FeeAnomalyRoutingKey")
+	}
+	return redacted
+}
+
+// checkRollupConfig applies the network profile of the chain to the rollup
+// config the same way as the sync service does, and validates the result and
+// the shared rollup config against the genesis
+func checkRollupConfig(cfg *rollup.Config, genesis *core.Genesis) configCheck {
+	check := configCheck{name: "rollup"}
+	if genesis == nil || genesis.Config == nil || genesis.Config.ChainID == nil {
+		if cfg.NetworkProfiles != nil {
+			check.warning = "no genesis, the network profile is not applied"
+		}
+	} else if !cfg.ApplyNetworkProfile(genesis.Config.ChainID) {
+		check.warning = fmt.Sprintf("no network profile for chain %d", genesis.Config.ChainID)
+	}
+	check.err = cfg.Validate()
+	if check.err == nil && cfg.RollupConfig != nil && genesis != nil && genesis.Config != nil {
+		check.err = cfg.RollupConfig.CheckChainConfig(genesis.Config)
+	}
+	return check
+}
+
+// checkChainConfig checks that the forks of the genesis are in order
+func checkChainConfig(genesis *core.Genesis) configCheck {
+	check := configCheck{name: "chain"}
+	if genesis == nil || genesis.Config == nil {
+		check.warning = "no genesis, the chain config is read from the database at startup"
+		return check
+	}
+	check.err = genesis.Config.CheckConfigForkOrder()
+	return check
+}
+
+// checkGasPriceOracle checks that the genesis of a sequencer that charges
+// fees deploys the OVM_GasPriceOracle with an owner that can update it, and
+// that the gas oracle of the network profile updates the same contract
+func checkGasPriceOracle(cfg *rollup.Config, genesis *core.Genesis) configCheck {
+	check := configCheck{name: "gpo"}
+	if cfg.IsVerifier || cfg.NoFees {
+		return check
+	}
+	if genesis != nil && genesis.Config != nil {
+		profile := cfg.NetworkProfiles.Profile(genesis.Config.ChainID)
+		if profile != nil && profile.GasPriceOracleAddress != nil && *profile.GasPriceOracleAddress != rcfg.L2GasPriceOracleAddress {
+			check.err = fmt.Errorf("network profile gas price oracle %s is not the predeploy %s",
+				profile.GasPriceOracleAddress.Hex(), rcfg.L2GasPriceOracleAddress.Hex())
+			return check
+		}
+	}
+	if genesis == nil {
+		check.warning = "no genesis, the gas price oracle is not checked"
+		return check
+	}
+	db := rawdb.NewMemoryDatabase()
+	statedb, err := state.New(genesis.ToBlock(db).Root(), state.NewDatabase(db))
+	if err != nil {
+		check.err = err
+		return check
+	}
+	if len(statedb.GetCode(rcfg.L2GasPriceOracleAddress)) == 0 {
+		check.err = fmt.Errorf("no gas price oracle deployed at %s", rcfg.L2GasPriceOracleAddress.Hex())
+		return check
+	}
+	slots, err := rcfg.ReadGPOStorageSlots(statedb)
+	if err != nil {
+		check.err = err
+		return check
+	}
+	if slots.Owner == (common.Address{}) {
+		check.err = errors.New("gas price oracle has no owner, the gas prices cannot be updated")
+		return check
+	}
+	if !slots.Initialized() {
+		check.warning = "gas price oracle has no gas price, fees are zero until it is set"
+	}
+	return check
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+func TestCheckGasPriceOracle(t *testing.T) {
+	gpo := func(owner common.Address) *core.Genesis {
+		return &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				rcfg.L2GasPriceOracleAddress: {
+					Code:    []byte{0x60, 0x00},
+					Balance: new(big.Int),
+					Storage: map[common.Hash]common.Hash{
+						rcfg.L2GasPriceOracleOwnerSlot: common.BytesToHash(owner.Bytes()),
+						rcfg.L2GasPriceSlot:            common.BigToHash(big.NewInt(1)),
+					},
+				},
+			},
+		}
+	}
+	other := common.HexToAddress("0x4200000000000000000000000000000000000010")
+
+	tests := map[string]struct {
+		cfg     rollup.Config
+		genesis *core.Genesis
+		err     bool
+		warning bool
+	}{
+		"ok": {
+			genesis: gpo(common.HexToAddress("0x01")),
+		},
+		"no-genesis": {
+			warning: true,
+		},
+		"not-deployed": {
+			genesis: &core.Genesis{Config: params.TestChainConfig},
+			err:     true,
+		},
+		"no-owner": {
+			genesis: gpo(common.Address{}),
+			err:     true,
+		},
+		"verifier": {
+			cfg:     rollup.Config{IsVerifier: true},
+			genesis: &core.Genesis{Config: params.TestChainConfig},
+		},
+		"no-fees": {
+			cfg:     rollup.Config{NoFees: true},
+			genesis: &core.Genesis{Config: params.TestChainConfig},
+		},
+		"profile-address": {
+			cfg: rollup.Config{
+				NetworkProfiles: rollup.NetworkProfiles{
+					params.TestChainConfig.ChainID.String(): {GasPriceOracleAddress: &other},
+				},
+			},
+			genesis: gpo(common.HexToAddress("0x01")),
+			err:     true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			check := checkGasPriceOracle(&tt.cfg, tt.genesis)
+			if (check.err != nil) != tt.err {
+				t.Fatalf("mismatched error: got %v, expect error %t", check.err, tt.err)
+			}
+			if (check.warning != "") != tt.warning {
+				t.Fatalf("mismatched warning: got %q, expect warning %t", check.warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestCheckRollupConfig(t *testing.T) {
+	up := 1.5
+	cfg := rollup.Config{
+		FeeThresholdUp: big.NewFloat(0.5),
+		NetworkProfiles: rollup.NetworkProfiles{
+			params.TestChainConfig.ChainID.String(): {
+				Sequencer: &rollup.SequencerProfile{FeeThresholdUp: &up},
+			},
+		},
+	}
+	genesis := &core.Genesis{Config: params.TestChainConfig}
+	if check := checkRollupConfig(&cfg, genesis); check.err == nil {
+		t.Fatal("expected invalid fee threshold up")
+	}
+
+	// The profile fills in the threshold that is not set
+	cfg.FeeThresholdUp = nil
+	if check := checkRollupConfig(&cfg, genesis); check.err != nil || check.warning != "" {
+		t.Fatalf("unexpected check: %v %s", check.err, check.warning)
+	}
+	if cfg.FeeThresholdUp == nil || cfg.FeeThresholdUp.Cmp(big.NewFloat(up)) != 0 {
+		t.Fatalf("mismatched fee threshold up: got %v, expect %f", cfg.FeeThresholdUp, up)
+	}
+
+	// There is no profile of another chain
+	genesis = &core.Genesis{Config: &params.ChainConfig{ChainID: big.NewInt(69)}}
+	if check := checkRollupConfig(&cfg, genesis); check.warning == "" {
+		t.Fatal("expected warning for missing network profile")
+	}
+}
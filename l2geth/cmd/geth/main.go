@@ -18,8 +18,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"net"
 	"os"
 	"runtime"
 	godebug "runtime/debug"
@@ -38,10 +40,13 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/internal/debug"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/les"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/feerpc"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
@@ -81,6 +86,7 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolPersistFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -133,6 +139,7 @@ var (
 		utils.NodeKeyHexFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
+		utils.DeveloperNoFeesFlag,
 		utils.TestnetFlag,
 		utils.RinkebyFlag,
 		utils.GoerliFlag,
@@ -170,7 +177,82 @@ var (
 		utils.RollupEnforceFeesFlag,
 		utils.RollupMinL2GasLimitFlag,
 		utils.RollupFeeThresholdDownFlag,
+		utils.RollupFeeThresholdDownAutoFlag,
+		utils.RollupFeeThresholdDownMinFlag,
+		utils.RollupFeeThresholdDownTargetFlag,
+		utils.RollupFeePolicyFileFlag,
+		utils.RollupFeePolicyShadowFileFlag,
+		utils.RollupFeeHookPluginsFlag,
 		utils.RollupFeeThresholdUpFlag,
+		utils.RollupThrottleBacklogBytesFlag,
+		utils.RollupMaxBacklogBytesFlag,
+		utils.RollupMaxBatchLagFlag,
+		utils.RollupThrottleMaxDelayFlag,
+		utils.RollupGasTokenSymbolFlag,
+		utils.RollupGasTokenDecimalsFlag,
+		utils.RollupGasTokenConversionRateFlag,
+		utils.RollupP2PSyncFlag,
+		utils.RollupL1NodeHttpFlag,
+		utils.RollupProtocolVersionsAddressFlag,
+		utils.RollupProtocolVersionHaltFlag,
+		utils.RollupPeerRegistryAddressFlag,
+		utils.RollupPeerRegistryL2Flag,
+		utils.RollupPeerRegistryIntervalFlag,
+		utils.RollupFeeReconciliationFlag,
+		utils.RollupFeeAnomalyMultipleFlag,
+		utils.RollupFeeAnomalyGasPriceJumpFlag,
+		utils.RollupFeeAnomalyWebhooksFlag,
+		utils.RollupFeeAnomalyRoutingKeyFlag,
+		utils.RollupFeeEventsUrlFlag,
+		utils.RollupFeeEventsTopicFlag,
+		utils.RollupFeeAuditLogFlag,
+		utils.RollupFeeAuditLogSizeFlag,
+		utils.RollupFeeCheckRecordFlag,
+		utils.RollupFeeCheckRecordPeriodFlag,
+		utils.RollupFeeEstimateMarginFlag,
+		utils.RollupHydrateReceiptsFlag,
+		utils.RollupConversionFeedFlag,
+		utils.RollupQuoteSymbolFlag,
+		utils.RollupQuoteDecimalsFlag,
+		utils.RollupConversionFeedMaxAgeFlag,
+		utils.RollupDABackendFlag,
+		utils.RollupDACostPerByteFlag,
+		utils.RollupDAPriceFeedFlag,
+		utils.RollupDATokenDecimalsFlag,
+		utils.RollupFeeTokensFlag,
+		utils.RollupFeeCollectorKeyFlag,
+		utils.RollupFeeRebateKeyFlag,
+		utils.RollupPnLFlag,
+		utils.RollupStateCommitmentChainFlag,
+		utils.RollupExpressLaneMultiplierFlag,
+		utils.RollupExpressLaneCapacityFlag,
+		utils.RollupFCFSFlag,
+		utils.RollupDappLabelsFlag,
+		utils.RollupReceiptProfilesFlag,
+		utils.RollupFeeGRPCAddrFlag,
+		utils.RollupFeeGRPCIntervalFlag,
+		utils.RollupFeeGatewayAddrFlag,
+		utils.RollupFeeGatewayRateFlag,
+		utils.RollupFeeGatewayBurstFlag,
+		utils.RollupFeeGatewayCorsFlag,
+		utils.RollupFeeQuoteKeyFlag,
+		utils.RollupFeeQuoteValidityFlag,
+		utils.RollupFeeQuoteMaxVolumeFlag,
+		utils.RollupConfigFileFlag,
+		utils.RollupNetworkProfilesFlag,
+		utils.RollupGasPriceOracleLayoutMigrationFlag,
+		utils.RollupFeeAttestationKeyFlag,
+		utils.RollupFeeValidationWorkersFlag,
+		utils.RollupAllowUninitializedGPOFlag,
+		utils.RollupFeeAssertionFlag,
+		utils.RollupGPOUpstreamHttpFlag,
+		utils.RollupGPOUpstreamTTLFlag,
+		utils.RollupGPOUpstreamMaxStalenessFlag,
+		utils.RollupGPOReplicaHttpFlag,
+		utils.RollupGPOReplicaMaxLagFlag,
+		utils.RollupRemoteOracleHttpFlag,
+		utils.RollupRemoteOracleTTLFlag,
+		utils.RollupVerifierL1GasPriceFlag,
 		utils.GasPriceOracleOwnerAddress,
 	}
 
@@ -213,6 +295,8 @@ var (
 		utils.MetricsInfluxDBUsernameFlag,
 		utils.MetricsInfluxDBPasswordFlag,
 		utils.MetricsInfluxDBTagsFlag,
+		utils.TracingOTLPEndpointFlag,
+		utils.TracingSampleRatioFlag,
 	}
 )
 
@@ -246,6 +330,8 @@ func init() {
 		licenseCommand,
 		// See config.go
 		dumpConfigCommand,
+		// See checkconfig.go
+		checkConfigCommand,
 		// See retesteth.go
 		retestethCommand,
 	}
@@ -316,6 +402,7 @@ func prepare(ctx *cli.Context) {
 
 	// Start metrics export if enabled
 	utils.SetupMetrics(ctx)
+	utils.SetupTracing(ctx)
 
 	// Start system runtime metrics collection
 	go metrics.CollectProcessMetrics(3 * time.Second)
@@ -470,6 +557,108 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 			}
 		}
 	}
+	// The persisted transaction pool is admitted once the chain is set up,
+	// with the OVM the sync service replays it when it starts instead
+	if ctx.GlobalIsSet(utils.TxPoolPersistFlag.Name) {
+		var ethereum *eth.Ethereum
+		if err := stack.Service(&ethereum); err == nil {
+			ethereum.LoadPersistedTxPool()
+		}
+	}
+	if ctx.GlobalIsSet(utils.RollupPeerRegistryAddressFlag.Name) {
+		startPeerRegistry(ctx, stack)
+	}
+	if ctx.GlobalIsSet(utils.RollupFeeGRPCAddrFlag.Name) {
+		startFeeGRPC(ctx, stack)
+	}
+	if ctx.GlobalIsSet(utils.RollupFeeGatewayAddrFlag.Name) {
+		startFeeGateway(ctx, stack)
+	}
+}
+
+// startPeerRegistry keeps the peers of the node in sync with the peer
+// registry contract, which is read from L1 or from an L2 predeploy
+func startPeerRegistry(ctx *cli.Context, stack *node.Node) {
+	var client *ethclient.Client
+	if ctx.GlobalBool(utils.RollupPeerRegistryL2Flag.Name) {
+		rpcClient, err := stack.Attach()
+		if err != nil {
+			utils.Fatalf("Failed to attach to self: %v", err)
+		}
+		client = ethclient.NewClient(rpcClient)
+	} else {
+		url := ctx.GlobalString(utils.RollupL1NodeHttpFlag.Name)
+		if url == "" {
+			utils.Fatalf("Peer registry on L1 requires %s", utils.RollupL1NodeHttpFlag.Name)
+		}
+		var err error
+		client, err = ethclient.Dial(url)
+		if err != nil {
+			utils.Fatalf("Failed to connect to L1 node: %v", err)
+		}
+	}
+	address := common.HexToAddress(ctx.GlobalString(utils.RollupPeerRegistryAddressFlag.Name))
+	interval := ctx.GlobalDuration(utils.RollupPeerRegistryIntervalFlag.Name)
+	registry, err := rollup.NewPeerRegistry(client, address, interval, stack.Server())
+	if err != nil {
+		utils.Fatalf("Failed to create peer registry: %v", err)
+	}
+	log.Info("Starting peer registry", "address", address.Hex(), "interval", interval)
+	go registry.Loop(context.Background())
+}
+
+// newFeeServer creates the fee estimation service of the node
+func newFeeServer(ctx *cli.Context, stack *node.Node) *feerpc.Server {
+	var ethereum *eth.Ethereum
+	if err := stack.Service(&ethereum); err != nil {
+		utils.Fatalf("Fee estimation service requires a full node: %v", err)
+	}
+	interval := ctx.GlobalDuration(utils.RollupFeeGRPCIntervalFlag.Name)
+	return feerpc.NewServer(ethereum.APIBackend, ethapi.NewPublicBlockChainAPI(ethereum.APIBackend), interval)
+}
+
+// startFeeGRPC serves the fee estimation service over gRPC
+func startFeeGRPC(ctx *cli.Context, stack *node.Node) {
+	addr := ctx.GlobalString(utils.RollupFeeGRPCAddrFlag.Name)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		utils.Fatalf("Failed to listen for fee gRPC requests: %v", err)
+	}
+	server := newFeeServer(ctx, stack)
+	log.Info("Starting fee gRPC server", "addr", listener.Addr(), "interval", ctx.GlobalDuration(utils.RollupFeeGRPCIntervalFlag.Name))
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Error("Fee gRPC server failed", "err", err)
+		}
+	}()
+}
+
+// startFeeGateway serves fee quotes over HTTP/JSON
+func startFeeGateway(ctx *cli.Context, stack *node.Node) {
+	config := feerpc.GatewayConfig{
+		RateLimit: ctx.GlobalFloat64(utils.RollupFeeGatewayRateFlag.Name),
+		RateBurst: ctx.GlobalInt(utils.RollupFeeGatewayBurstFlag.Name),
+	}
+	for _, origin := range strings.Split(ctx.GlobalString(utils.RollupFeeGatewayCorsFlag.Name), ",") {
+		if trimmed := strings.TrimSpace(origin); trimmed != "" {
+			config.CorsOrigins = append(config.CorsOrigins, trimmed)
+		}
+	}
+	gateway, err := feerpc.NewGateway(newFeeServer(ctx, stack), config)
+	if err != nil {
+		utils.Fatalf("Failed to create fee gateway: %v", err)
+	}
+	addr := ctx.GlobalString(utils.RollupFeeGatewayAddrFlag.Name)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		utils.Fatalf("Failed to listen for fee gateway requests: %v", err)
+	}
+	log.Info("Starting fee gateway", "addr", listener.Addr(), "rate", config.RateLimit, "burst", config.RateBurst)
+	go func() {
+		if err := gateway.Serve(listener); err != nil {
+			log.Error("Fee gateway failed", "err", err)
+		}
+	}()
 }
 
 // unlockAccounts unlocks any account specifically requested.
@@ -83,7 +83,82 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RollupEnforceFeesFlag,
 			utils.RollupMinL2GasLimitFlag,
 			utils.RollupFeeThresholdDownFlag,
+			utils.RollupFeeThresholdDownAutoFlag,
+			utils.RollupFeeThresholdDownMinFlag,
+			utils.RollupFeeThresholdDownTargetFlag,
+			utils.RollupFeePolicyFileFlag,
+			utils.RollupFeePolicyShadowFileFlag,
+			utils.RollupFeeHookPluginsFlag,
 			utils.RollupFeeThresholdUpFlag,
+			utils.RollupThrottleBacklogBytesFlag,
+			utils.RollupMaxBacklogBytesFlag,
+			utils.RollupMaxBatchLagFlag,
+			utils.RollupThrottleMaxDelayFlag,
+			utils.RollupGasTokenSymbolFlag,
+			utils.RollupGasTokenDecimalsFlag,
+			utils.RollupGasTokenConversionRateFlag,
+			utils.RollupP2PSyncFlag,
+			utils.RollupL1NodeHttpFlag,
+			utils.RollupProtocolVersionsAddressFlag,
+			utils.RollupProtocolVersionHaltFlag,
+			utils.RollupPeerRegistryAddressFlag,
+			utils.RollupPeerRegistryL2Flag,
+			utils.RollupPeerRegistryIntervalFlag,
+			utils.RollupFeeReconciliationFlag,
+			utils.RollupFeeAnomalyMultipleFlag,
+			utils.RollupFeeAnomalyGasPriceJumpFlag,
+			utils.RollupFeeAnomalyWebhooksFlag,
+			utils.RollupFeeAnomalyRoutingKeyFlag,
+			utils.RollupFeeEventsUrlFlag,
+			utils.RollupFeeEventsTopicFlag,
+			utils.RollupFeeAuditLogFlag,
+			utils.RollupFeeAuditLogSizeFlag,
+			utils.RollupFeeCheckRecordFlag,
+			utils.RollupFeeCheckRecordPeriodFlag,
+			utils.RollupFeeEstimateMarginFlag,
+			utils.RollupHydrateReceiptsFlag,
+			utils.RollupConversionFeedFlag,
+			utils.RollupQuoteSymbolFlag,
+			utils.RollupQuoteDecimalsFlag,
+			utils.RollupConversionFeedMaxAgeFlag,
+			utils.RollupDABackendFlag,
+			utils.RollupDACostPerByteFlag,
+			utils.RollupDAPriceFeedFlag,
+			utils.RollupDATokenDecimalsFlag,
+			utils.RollupFeeTokensFlag,
+			utils.RollupFeeCollectorKeyFlag,
+			utils.RollupFeeRebateKeyFlag,
+			utils.RollupPnLFlag,
+			utils.RollupStateCommitmentChainFlag,
+			utils.RollupExpressLaneMultiplierFlag,
+			utils.RollupExpressLaneCapacityFlag,
+			utils.RollupFCFSFlag,
+			utils.RollupDappLabelsFlag,
+			utils.RollupReceiptProfilesFlag,
+			utils.RollupFeeGRPCAddrFlag,
+			utils.RollupFeeGRPCIntervalFlag,
+			utils.RollupFeeGatewayAddrFlag,
+			utils.RollupFeeGatewayRateFlag,
+			utils.RollupFeeGatewayBurstFlag,
+			utils.RollupFeeGatewayCorsFlag,
+			utils.RollupFeeQuoteKeyFlag,
+			utils.RollupFeeQuoteValidityFlag,
+			utils.RollupFeeQuoteMaxVolumeFlag,
+			utils.RollupConfigFileFlag,
+			utils.RollupNetworkProfilesFlag,
+			utils.RollupGasPriceOracleLayoutMigrationFlag,
+			utils.RollupFeeAttestationKeyFlag,
+			utils.RollupFeeValidationWorkersFlag,
+			utils.RollupAllowUninitializedGPOFlag,
+			utils.RollupFeeAssertionFlag,
+			utils.RollupGPOUpstreamHttpFlag,
+			utils.RollupGPOUpstreamTTLFlag,
+			utils.RollupGPOUpstreamMaxStalenessFlag,
+			utils.RollupGPOReplicaHttpFlag,
+			utils.RollupGPOReplicaMaxLagFlag,
+			utils.RollupRemoteOracleHttpFlag,
+			utils.RollupRemoteOracleTTLFlag,
+			utils.RollupVerifierL1GasPriceFlag,
 			utils.GasPriceOracleOwnerAddress,
 		},
 	},
@@ -127,6 +202,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.DeveloperFlag,
 			utils.DeveloperPeriodFlag,
+			utils.DeveloperNoFeesFlag,
 		},
 	},
 	{
@@ -147,6 +223,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolPersistFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
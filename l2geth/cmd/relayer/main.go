@@ -0,0 +1,248 @@
+// relayer sponsors meta-transactions: it executes the EIP-712 signed
+// requests of a forwarder contract and pays their rollup fees from a sponsor
+// account within per-app quotas.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/pricefeed"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/relayer"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+var (
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "URL of the L2 node that transactions are sent to",
+		Value: "http://localhost:8545",
+	}
+	keyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "hex encoded private key of the sponsor account",
+	}
+	forwarderFlag = cli.StringFlag{
+		Name:  "forwarder",
+		Usage: "address of the forwarder contract that executes the requests",
+	}
+	domainNameFlag = cli.StringFlag{
+		Name:  "domain.name",
+		Usage: "EIP-712 domain name of the forwarder",
+		Value: "MinimalForwarder",
+	}
+	domainVersionFlag = cli.StringFlag{
+		Name:  "domain.version",
+		Usage: "EIP-712 domain version of the forwarder",
+		Value: "0.0.1",
+	}
+	quotasFlag = cli.StringFlag{
+		Name:  "quotas",
+		Usage: "JSON file of the fee quotas of the sponsored apps by target contract",
+	}
+	maxFeeFlag = cli.StringFlag{
+		Name:  "maxfee",
+		Usage: "most wei that the sponsor pays for a single request, no limit when not set",
+	}
+	conversionRPCFlag = cli.StringFlag{
+		Name:  "conversion.rpc",
+		Usage: "URL of the L1 node that the conversion feed is read from",
+	}
+	conversionFeedFlag = cli.StringFlag{
+		Name:  "conversion.feed",
+		Usage: "address of a price feed of ether in the quote currency that fees are reported in, fees are only reported in wei when not set",
+	}
+	conversionSymbolFlag = cli.StringFlag{
+		Name:  "conversion.symbol",
+		Usage: "symbol of the quote currency of the conversion feed",
+		Value: "USD",
+	}
+	conversionDecimalsFlag = cli.UintFlag{
+		Name:  "conversion.decimals",
+		Usage: "decimals of the quote currency of the conversion feed",
+		Value: 2,
+	}
+	conversionMaxAgeFlag = cli.DurationFlag{
+		Name:  "conversion.maxage",
+		Usage: "refuse prices of the conversion feed that are older than this, 0 to accept any price",
+		Value: time.Hour,
+	}
+	addrFlag = cli.StringFlag{
+		Name:  "addr",
+		Usage: "listening address of the HTTP API",
+		Value: "localhost:8560",
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = filepath.Base(os.Args[0])
+	app.Version = params.VersionWithCommit(gitCommit, gitDate)
+	app.Usage = "a relayer that sponsors the fees of meta-transactions"
+	app.Flags = []cli.Flag{
+		rpcFlag,
+		keyFlag,
+		forwarderFlag,
+		domainNameFlag,
+		domainVersionFlag,
+		quotasFlag,
+		maxFeeFlag,
+		conversionRPCFlag,
+		conversionFeedFlag,
+		conversionSymbolFlag,
+		conversionDecimalsFlag,
+		conversionMaxAgeFlag,
+		addrFlag,
+	}
+	app.Action = run
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx *cli.Context) error {
+	config, err := relayerConfig(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := rpc.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		return fmt.Errorf("Cannot connect to node: %w", err)
+	}
+	defer client.Close()
+	backend := relayer.NewClientBackend(client)
+
+	tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	config.Domain.ChainID, err = ethclient.NewClient(client).ChainID(tctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Cannot fetch chain id: %w", err)
+	}
+	r, err := relayer.New(backend, *config)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", ctx.String(addrFlag.Name))
+	if err != nil {
+		return err
+	}
+	loopCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go r.Loop(loopCtx)
+
+	server := &http.Server{Handler: r.Handler()}
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+		<-sigc
+		server.Close()
+	}()
+	log.Info("Relayer started", "addr", listener.Addr(), "sponsor", r.Sponsor().Hex(), "forwarder", config.Domain.Forwarder.Hex(), "apps", len(config.Quotas))
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// relayerConfig returns the configuration of the relayer from the flags,
+// without the chain id
+func relayerConfig(ctx *cli.Context) (*relayer.Config, error) {
+	if !ctx.IsSet(keyFlag.Name) {
+		return nil, errors.New("Specify the sponsor key with --key")
+	}
+	key, err := crypto.HexToECDSA(ctx.String(keyFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid sponsor key: %w", err)
+	}
+	if !common.IsHexAddress(ctx.String(forwarderFlag.Name)) {
+		return nil, fmt.Errorf("Invalid forwarder address: %q", ctx.String(forwarderFlag.Name))
+	}
+	if !ctx.IsSet(quotasFlag.Name) {
+		return nil, errors.New("Specify the quotas of the sponsored apps with --quotas")
+	}
+	quotas, err := relayer.LoadQuotas(ctx.String(quotasFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	config := &relayer.Config{
+		Domain: relayer.Domain{
+			Name:      ctx.String(domainNameFlag.Name),
+			Version:   ctx.String(domainVersionFlag.Name),
+			Forwarder: common.HexToAddress(ctx.String(forwarderFlag.Name)),
+		},
+		Key:    key,
+		Quotas: quotas,
+	}
+	if ctx.IsSet(maxFeeFlag.Name) {
+		maxFee, ok := new(big.Int).SetString(ctx.String(maxFeeFlag.Name), 10)
+		if !ok {
+			return nil, fmt.Errorf("Invalid max fee: %s", ctx.String(maxFeeFlag.Name))
+		}
+		config.MaxFee = maxFee
+	}
+	if ctx.IsSet(conversionFeedFlag.Name) {
+		oracle, err := conversionRate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		config.ConversionRate = oracle
+	}
+	return config, nil
+}
+
+// conversionRateTTL is how long the price of the conversion feed is used for
+// before it is read again
+const conversionRateTTL = 30 * time.Second
+
+// conversionRate returns the conversion rate oracle of the conversion feed
+func conversionRate(ctx *cli.Context) (fees.ConversionRateOracle, error) {
+	if !common.IsHexAddress(ctx.String(conversionFeedFlag.Name)) {
+		return nil, fmt.Errorf("Invalid conversion feed address: %q", ctx.String(conversionFeedFlag.Name))
+	}
+	if !ctx.IsSet(conversionRPCFlag.Name) {
+		return nil, errors.New("Specify the L1 node of the conversion feed with --conversion.rpc")
+	}
+	decimals := ctx.Uint(conversionDecimalsFlag.Name)
+	if decimals > math.MaxUint8 {
+		return nil, fmt.Errorf("Invalid conversion decimals: %d", decimals)
+	}
+	l1, err := ethclient.Dial(ctx.String(conversionRPCFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+	}
+	feed, err := pricefeed.New(common.HexToAddress(ctx.String(conversionFeedFlag.Name)), l1)
+	if err != nil {
+		return nil, err
+	}
+	return fees.NewPriceFeedConversionRate(feed, ctx.String(conversionSymbolFlag.Name), uint8(decimals),
+		conversionRateTTL, ctx.Duration(conversionMaxAgeFlag.Name)), nil
+}
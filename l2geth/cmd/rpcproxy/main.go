@@ -0,0 +1,88 @@
+// rpcproxy is a JSON-RPC proxy that strips or injects the rollup fee fields
+// of the receipts of a rollup node for clients that expect Ethereum receipts.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/rpcproxy"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+var (
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "HTTP JSON-RPC endpoint of the L2 node that requests are forwarded to",
+		Value: "http://localhost:8545",
+	}
+	addrFlag = cli.StringFlag{
+		Name:  "addr",
+		Usage: "address that the proxy listens on",
+		Value: "localhost:8546",
+	}
+	modeFlag = cli.StringFlag{
+		Name:  "mode",
+		Usage: "how the rollup fee fields of receipts are adapted: strip removes them, inject adds zero fields to receipts without them",
+		Value: rpcproxy.ModeStrip.String(),
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = filepath.Base(os.Args[0])
+	app.Version = params.VersionWithCommit(gitCommit, gitDate)
+	app.Usage = "a JSON-RPC proxy that adapts rollup receipts for legacy clients"
+	app.Flags = []cli.Flag{
+		rpcFlag,
+		addrFlag,
+		modeFlag,
+	}
+	app.Action = run
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx *cli.Context) error {
+	mode, err := rpcproxy.NewMode(ctx.String(modeFlag.Name))
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", ctx.String(addrFlag.Name))
+	if err != nil {
+		return fmt.Errorf("Cannot listen: %w", err)
+	}
+	server := &http.Server{Handler: rpcproxy.New(ctx.String(rpcFlag.Name), mode)}
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+		<-sigc
+		server.Close()
+	}()
+
+	log.Info("RPC proxy started", "addr", listener.Addr(), "rpc", ctx.String(rpcFlag.Name), "mode", mode)
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -61,7 +62,11 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rollup/rollupconfig"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/tracing"
 	whisper "github.com/ethereum/go-ethereum/whisper/whisperv6"
 	pcsclite "github.com/gballet/go-libpcsclite"
 	cli "gopkg.in/urfave/cli.v1"
@@ -200,6 +205,11 @@ var (
 		Name:  "dev.period",
 		Usage: "Block period to use in developer mode (0 = mine only if transaction pending)",
 	}
+	DeveloperNoFeesFlag = cli.BoolFlag{
+		Name:   "dev.nofees",
+		Usage:  "Accept transactions without a fee and keep the L1 and L2 gas prices at zero, for local development",
+		EnvVar: "DEV_NOFEES",
+	}
 	IdentityFlag = cli.StringFlag{
 		Name:  "identity",
 		Usage: "Custom node name",
@@ -352,6 +362,10 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolPersistFlag = cli.StringFlag{
+		Name:  "txpool.persist",
+		Usage: "Disk file that the pool is persisted to on shutdown and re-admitted from on startup, after its fees are checked again",
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -796,6 +810,17 @@ var (
 		Usage: "Comma-separated InfluxDB tags (key/values) attached to all measurements",
 		Value: "host=localhost",
 	}
+	TracingOTLPEndpointFlag = cli.StringFlag{
+		Name:   "tracing.otlp.endpoint",
+		Usage:  "OTLP/HTTP endpoint to export trace spans to, tracing is disabled when not set",
+		EnvVar: "TRACING_OTLP_ENDPOINT",
+	}
+	TracingSampleRatioFlag = cli.Float64Flag{
+		Name:   "tracing.sampleratio",
+		Usage:  "Ratio of traces to sample between 0 and 1",
+		Value:  1,
+		EnvVar: "TRACING_SAMPLE_RATIO",
+	}
 
 	EWASMInterpreterFlag = cli.StringFlag{
 		Name:  "vm.ewasm",
@@ -902,11 +927,412 @@ var (
 		Usage:  "Allow txs with fees below the current fee up to this amount, must be < 1",
 		EnvVar: "ROLLUP_FEE_THRESHOLD_DOWN",
 	}
+	RollupFeeThresholdDownAutoFlag = cli.BoolFlag{
+		Name:   "rollup.feethresholddownauto",
+		Usage:  "Tune the fee threshold down from the volatility of the L1 gas price",
+		EnvVar: "ROLLUP_FEE_THRESHOLD_DOWN_AUTO",
+	}
+	RollupFeeThresholdDownMinFlag = cli.Float64Flag{
+		Name:   "rollup.feethresholddownmin",
+		Usage:  "Lowest fee threshold down that is set automatically",
+		Value:  0.5,
+		EnvVar: "ROLLUP_FEE_THRESHOLD_DOWN_MIN",
+	}
+	RollupFeeThresholdDownTargetFlag = cli.Float64Flag{
+		Name:   "rollup.feethresholddowntarget",
+		Usage:  "Tune the fee threshold down to keep the share of fee checks falsely rejected for paying too little under this target",
+		EnvVar: "ROLLUP_FEE_THRESHOLD_DOWN_TARGET",
+	}
+	RollupFeePolicyFileFlag = cli.StringFlag{
+		Name:   "rollup.feepolicyfile",
+		Usage:  "JSON file of fee thresholds and subsidies for specific contracts and methods, reloaded when it changes",
+		EnvVar: "ROLLUP_FEE_POLICY_FILE",
+	}
+	RollupFeePolicyShadowFileFlag = cli.StringFlag{
+		Name:   "rollup.feepolicyshadowfile",
+		Usage:  "JSON file of candidate fee policies that are evaluated next to the active ones, logging where their decisions differ without enforcing them",
+		EnvVar: "ROLLUP_FEE_POLICY_SHADOW_FILE",
+	}
+	RollupFeeHookPluginsFlag = cli.StringFlag{
+		Name:   "rollup.feehookplugins",
+		Usage:  "Comma separated list of Go plugins that export a FeeHook to veto or adjust fee decisions",
+		EnvVar: "ROLLUP_FEE_HOOK_PLUGINS",
+	}
 	RollupFeeThresholdUpFlag = cli.Float64Flag{
 		Name:   "rollup.feethresholdup",
 		Usage:  "Allow txs with fees above the current fee up to this amount, must be > 1",
 		EnvVar: "ROLLUP_FEE_THRESHOLD_UP",
 	}
+	RollupThrottleBacklogBytesFlag = cli.Uint64Flag{
+		Name:   "rollup.throttlebacklogbytes",
+		Usage:  "Slow down accepting txs when this many bytes have not been batch submitted",
+		EnvVar: "ROLLUP_THROTTLE_BACKLOG_BYTES",
+	}
+	RollupMaxBacklogBytesFlag = cli.Uint64Flag{
+		Name:   "rollup.maxbacklogbytes",
+		Usage:  "Reject txs when this many bytes have not been batch submitted",
+		EnvVar: "ROLLUP_MAX_BACKLOG_BYTES",
+	}
+	RollupMaxBatchLagFlag = cli.DurationFlag{
+		Name:   "rollup.maxbatchlag",
+		Usage:  "Reject txs when the oldest tx that has not been batch submitted is older than this",
+		EnvVar: "ROLLUP_MAX_BATCH_LAG",
+	}
+	RollupThrottleMaxDelayFlag = cli.DurationFlag{
+		Name:   "rollup.throttlemaxdelay",
+		Usage:  "Maximum delay applied to each tx when throttling due to the unbatched backlog",
+		Value:  time.Second,
+		EnvVar: "ROLLUP_THROTTLE_MAX_DELAY",
+	}
+	RollupGasTokenSymbolFlag = cli.StringFlag{
+		Name:   "rollup.gastokensymbol",
+		Usage:  "Symbol of the native token that fees are paid in",
+		Value:  "ETH",
+		EnvVar: "ROLLUP_GAS_TOKEN_SYMBOL",
+	}
+	RollupGasTokenDecimalsFlag = cli.UintFlag{
+		Name:   "rollup.gastokendecimals",
+		Usage:  "Decimals of the native token that fees are paid in",
+		Value:  18,
+		EnvVar: "ROLLUP_GAS_TOKEN_DECIMALS",
+	}
+	RollupGasTokenConversionRateFlag = cli.Float64Flag{
+		Name:   "rollup.gastokenconversionrate",
+		Usage:  "Amount of the native token equal in value to 1 wei, fees are paid in ETH when unset",
+		EnvVar: "ROLLUP_GAS_TOKEN_CONVERSION_RATE",
+	}
+	RollupP2PSyncFlag = cli.BoolFlag{
+		Name:   "rollup.p2psync",
+		Usage:  "Sync the chain from peers before deriving it, verifier only",
+		EnvVar: "ROLLUP_P2P_SYNC",
+	}
+	RollupL1NodeHttpFlag = cli.StringFlag{
+		Name:   "rollup.l1nodehttp",
+		Usage:  "HTTP endpoint of a layer 1 node",
+		EnvVar: "ROLLUP_L1_NODE_HTTP",
+	}
+	RollupProtocolVersionsAddressFlag = cli.StringFlag{
+		Name:   "rollup.protocolversionsaddress",
+		Usage:  "Address of the L1 contract that signals protocol versions",
+		EnvVar: "ROLLUP_PROTOCOL_VERSIONS_ADDRESS",
+	}
+	RollupProtocolVersionHaltFlag = cli.StringFlag{
+		Name:   "rollup.protocolversionhalt",
+		Usage:  "Halt on an unsupported required protocol version: none, major, minor or patch",
+		Value:  "none",
+		EnvVar: "ROLLUP_PROTOCOL_VERSION_HALT",
+	}
+	RollupPeerRegistryAddressFlag = cli.StringFlag{
+		Name:   "rollup.peerregistryaddress",
+		Usage:  "Address of the contract that holds the list of peers to connect to",
+		EnvVar: "ROLLUP_PEER_REGISTRY_ADDRESS",
+	}
+	RollupPeerRegistryL2Flag = cli.BoolFlag{
+		Name:   "rollup.peerregistryl2",
+		Usage:  "Read the peer registry from L2 instead of L1",
+		EnvVar: "ROLLUP_PEER_REGISTRY_L2",
+	}
+	RollupPeerRegistryIntervalFlag = cli.DurationFlag{
+		Name:   "rollup.peerregistryinterval",
+		Usage:  "Interval for refreshing the peers from the peer registry",
+		Value:  time.Minute * 5,
+		EnvVar: "ROLLUP_PEER_REGISTRY_INTERVAL",
+	}
+	RollupFeeReconciliationFlag = cli.BoolFlag{
+		Name:   "rollup.feereconciliation",
+		Usage:  "Reconcile the fees collected for each batch against its L1 cost, requires an L1 node",
+		EnvVar: "ROLLUP_FEE_RECONCILIATION",
+	}
+	RollupFeeAnomalyMultipleFlag = cli.Float64Flag{
+		Name:   "rollup.feeanomalymultiple",
+		Usage:  "Alert when the expected fee exceeds this multiple of the trailing median, 0 to disable",
+		Value:  10,
+		EnvVar: "ROLLUP_FEE_ANOMALY_MULTIPLE",
+	}
+	RollupFeeAnomalyGasPriceJumpFlag = cli.Float64Flag{
+		Name:   "rollup.feeanomalygaspricejump",
+		Usage:  "Alert when a gas price changes by more than this fraction between updates, 0 to disable",
+		Value:  0.5,
+		EnvVar: "ROLLUP_FEE_ANOMALY_GAS_PRICE_JUMP",
+	}
+	RollupFeeAnomalyWebhooksFlag = cli.StringFlag{
+		Name:   "rollup.feeanomalywebhooks",
+		Usage:  "Comma separated webhook URLs that fee anomaly alerts are posted to",
+		EnvVar: "ROLLUP_FEE_ANOMALY_WEBHOOKS",
+	}
+	RollupFeeAnomalyRoutingKeyFlag = cli.StringFlag{
+		Name:   "rollup.feeanomalyroutingkey",
+		Usage:  "PagerDuty routing key included in fee anomaly alerts",
+		EnvVar: "ROLLUP_FEE_ANOMALY_ROUTING_KEY",
+	}
+	RollupFeeEventsUrlFlag = cli.StringFlag{
+		Name:   "rollup.feeeventsurl",
+		Usage:  "URL of a Kafka REST Proxy that the fee breakdown of every batched transaction is published to, disabled when not set",
+		EnvVar: "ROLLUP_FEE_EVENTS_URL",
+	}
+	RollupFeeEventsTopicFlag = cli.StringFlag{
+		Name:   "rollup.feeeventstopic",
+		Usage:  "Kafka topic that fee events are published to",
+		Value:  "l2-fee-events",
+		EnvVar: "ROLLUP_FEE_EVENTS_TOPIC",
+	}
+	RollupFeeAuditLogFlag = cli.StringFlag{
+		Name:   "rollup.feeauditlog",
+		Usage:  "Directory that a JSON record of every fee decision is appended to, disabled when not set",
+		EnvVar: "ROLLUP_FEE_AUDIT_LOG",
+	}
+	RollupFeeAuditLogSizeFlag = cli.UintFlag{
+		Name:   "rollup.feeauditlogsize",
+		Usage:  "Size in bytes of a fee audit log file before a new file is started",
+		Value:  64 * 1024 * 1024,
+		EnvVar: "ROLLUP_FEE_AUDIT_LOG_SIZE",
+	}
+	RollupFeeCheckRecordFlag = cli.StringFlag{
+		Name:   "rollup.feecheckrecord",
+		Usage:  "File that the anonymized inputs of the fee checks are appended to for backtesting the fee thresholds, disabled when not set",
+		EnvVar: "ROLLUP_FEE_CHECK_RECORD",
+	}
+	RollupFeeCheckRecordPeriodFlag = cli.DurationFlag{
+		Name:   "rollup.feecheckrecordperiod",
+		Usage:  "Period after which the fee checks are no longer recorded",
+		Value:  24 * time.Hour,
+		EnvVar: "ROLLUP_FEE_CHECK_RECORD_PERIOD",
+	}
+	RollupFeeEstimateMarginFlag = cli.Uint64Flag{
+		Name:   "rollup.feeestimatemargin",
+		Usage:  "Percentage that the gas prices of the fee and gas estimates are raised by",
+		EnvVar: "ROLLUP_FEE_ESTIMATE_MARGIN",
+	}
+	RollupHydrateReceiptsFlag = cli.BoolFlag{
+		Name:   "rollup.hydratereceipts",
+		Usage:  "Record the L1 fee in the receipts of historical blocks in the background, requires an archive node",
+		EnvVar: "ROLLUP_HYDRATE_RECEIPTS",
+	}
+	RollupConversionFeedFlag = cli.StringFlag{
+		Name:   "rollup.conversionfeed",
+		Usage:  "Address of an L1 price feed of ether in the quote currency that fees are converted into, requires the L1 node",
+		EnvVar: "ROLLUP_CONVERSION_FEED",
+	}
+	RollupQuoteSymbolFlag = cli.StringFlag{
+		Name:   "rollup.quotesymbol",
+		Usage:  "Symbol of the quote currency of the conversion feed",
+		Value:  "USD",
+		EnvVar: "ROLLUP_QUOTE_SYMBOL",
+	}
+	RollupQuoteDecimalsFlag = cli.UintFlag{
+		Name:   "rollup.quotedecimals",
+		Usage:  "Decimals of the quote currency of the conversion feed",
+		Value:  2,
+		EnvVar: "ROLLUP_QUOTE_DECIMALS",
+	}
+	RollupConversionFeedMaxAgeFlag = cli.DurationFlag{
+		Name:   "rollup.conversionfeedmaxage",
+		Usage:  "Refuse prices of the conversion feed that are older than this, 0 to accept any price",
+		Value:  time.Hour,
+		EnvVar: "ROLLUP_CONVERSION_FEED_MAX_AGE",
+	}
+	RollupDABackendFlag = cli.StringFlag{
+		Name:   "rollup.dabackend",
+		Usage:  "Data availability layer that the batch submitter posts to, which the L1 fee is priced with: calldata, blob or external",
+		Value:  "calldata",
+		EnvVar: "ROLLUP_DA_BACKEND",
+	}
+	RollupDACostPerByteFlag = BigFlag{
+		Name:   "rollup.dacostperbyte",
+		Usage:  "Price of a byte on the external data availability layer in the smallest denomination of its token",
+		EnvVar: "ROLLUP_DA_COST_PER_BYTE",
+	}
+	RollupDAPriceFeedFlag = cli.StringFlag{
+		Name:   "rollup.dapricefeed",
+		Usage:  "Address of an L1 price feed of ether in the token of the external data availability layer, requires the L1 node",
+		EnvVar: "ROLLUP_DA_PRICE_FEED",
+	}
+	RollupDATokenDecimalsFlag = cli.UintFlag{
+		Name:   "rollup.datokendecimals",
+		Usage:  "Decimals of the token of the external data availability layer",
+		Value:  18,
+		EnvVar: "ROLLUP_DA_TOKEN_DECIMALS",
+	}
+	RollupFeeTokensFlag = cli.StringFlag{
+		Name:   "rollup.feetokens",
+		Usage:  "JSON file of the EIP-3009 tokens that fees can be paid in with a transfer authorization",
+		EnvVar: "ROLLUP_FEE_TOKENS",
+	}
+	RollupFeeCollectorKeyFlag = cli.StringFlag{
+		Name:   "rollup.feecollectorkey",
+		Usage:  "Hex encoded private key of the account that collects the fees paid in fee tokens",
+		EnvVar: "ROLLUP_FEE_COLLECTOR_KEY",
+	}
+	RollupFeeRebateKeyFlag = cli.StringFlag{
+		Name:   "rollup.feerebatekey",
+		Usage:  "Hex encoded private key of the operator that records fee rebates of overcharged senders, requires fee reconciliation",
+		EnvVar: "ROLLUP_FEE_REBATE_KEY",
+	}
+	RollupPnLFlag = cli.BoolFlag{
+		Name:   "rollup.pnl",
+		Usage:  "Keep a profit and loss ledger of the sequencer, requires fee reconciliation",
+		EnvVar: "ROLLUP_PNL",
+	}
+	RollupStateCommitmentChainFlag = cli.StringFlag{
+		Name:   "rollup.statecommitmentchain",
+		Usage:  "Address of the L1 state commitment chain, adds the proposer spend to the profit and loss ledger",
+		EnvVar: "ROLLUP_STATE_COMMITMENT_CHAIN",
+	}
+	RollupExpressLaneMultiplierFlag = cli.Float64Flag{
+		Name:   "rollup.expresslanemultiplier",
+		Usage:  "Apply txs paying this multiple of the expected fee ahead of other txs, must be > 1",
+		EnvVar: "ROLLUP_EXPRESS_LANE_MULTIPLIER",
+	}
+	RollupExpressLaneCapacityFlag = cli.IntFlag{
+		Name:   "rollup.expresslanecapacity",
+		Usage:  "Number of txs that can wait in the express lane",
+		Value:  64,
+		EnvVar: "ROLLUP_EXPRESS_LANE_CAPACITY",
+	}
+	RollupFCFSFlag = cli.BoolFlag{
+		Name:   "rollup.fcfs",
+		Usage:  "Apply txs strictly in order of arrival regardless of their fee",
+		EnvVar: "ROLLUP_FCFS",
+	}
+	RollupDappLabelsFlag = cli.StringFlag{
+		Name:   "rollup.dapplabels",
+		Usage:  "JSON file of the labels of contract addresses to report fee revenue and calldata usage per application",
+		EnvVar: "ROLLUP_DAPP_LABELS",
+	}
+	RollupReceiptProfilesFlag = cli.StringFlag{
+		Name:   "rollup.receiptprofiles",
+		Usage:  "JSON file of the names and presence of the rollup fields of receipts per RPC namespace",
+		EnvVar: "ROLLUP_RECEIPT_PROFILES",
+	}
+	RollupFeeGRPCAddrFlag = cli.StringFlag{
+		Name:   "rollup.feegrpcaddr",
+		Usage:  "Listening address of the gRPC fee estimation server, disabled when not set",
+		EnvVar: "ROLLUP_FEE_GRPC_ADDR",
+	}
+	RollupFeeGRPCIntervalFlag = cli.DurationFlag{
+		Name:   "rollup.feegrpcinterval",
+		Usage:  "Interval for checking the fee parameters for changes when streaming them over gRPC",
+		Value:  time.Second,
+		EnvVar: "ROLLUP_FEE_GRPC_INTERVAL",
+	}
+	RollupFeeGatewayAddrFlag = cli.StringFlag{
+		Name:   "rollup.feegatewayaddr",
+		Usage:  "Listening address of the HTTP/JSON fee quoting gateway, disabled when not set",
+		EnvVar: "ROLLUP_FEE_GATEWAY_ADDR",
+	}
+	RollupFeeGatewayRateFlag = cli.Float64Flag{
+		Name:   "rollup.feegatewayrate",
+		Usage:  "Requests per second allowed for each client of the fee quoting gateway",
+		Value:  10,
+		EnvVar: "ROLLUP_FEE_GATEWAY_RATE",
+	}
+	RollupFeeGatewayBurstFlag = cli.IntFlag{
+		Name:   "rollup.feegatewayburst",
+		Usage:  "Requests that a client of the fee quoting gateway can make at once",
+		Value:  20,
+		EnvVar: "ROLLUP_FEE_GATEWAY_BURST",
+	}
+	RollupFeeGatewayCorsFlag = cli.StringFlag{
+		Name:   "rollup.feegatewaycorsdomain",
+		Usage:  "Comma separated list of domains from which to accept cross origin requests to the fee quoting gateway",
+		EnvVar: "ROLLUP_FEE_GATEWAY_CORS_DOMAIN",
+	}
+	RollupFeeQuoteKeyFlag = cli.StringFlag{
+		Name:   "rollup.feequotekey",
+		Usage:  "Hex encoded private key that fee quotes are signed with, fee quotes are disabled when not set",
+		EnvVar: "ROLLUP_FEE_QUOTE_KEY",
+	}
+	RollupFeeQuoteValidityFlag = cli.Uint64Flag{
+		Name:   "rollup.feequotevalidity",
+		Usage:  "Number of L2 blocks that fee quotes are valid for",
+		Value:  10,
+		EnvVar: "ROLLUP_FEE_QUOTE_VALIDITY",
+	}
+	RollupFeeQuoteMaxVolumeFlag = BigFlag{
+		Name:   "rollup.feequotemaxvolume",
+		Usage:  "Fees in wei per block that are accepted at quoted gas prices, averaged over the fee quote validity, 0 for unbounded",
+		Value:  new(big.Int),
+		EnvVar: "ROLLUP_FEE_QUOTE_MAX_VOLUME",
+	}
+	RollupConfigFileFlag = cli.StringFlag{
+		Name:   "rollup.config",
+		Usage:  "JSON rollup config shared with the other services of the rollup, it fills in the options that are not set",
+		EnvVar: "ROLLUP_CONFIG",
+	}
+	RollupNetworkProfilesFlag = cli.StringFlag{
+		Name:   "rollup.networkprofiles",
+		Usage:  "JSON file of fee parameter profiles keyed by chain id, the profile of the chain fills in the options that are not set",
+		EnvVar: "ROLLUP_NETWORK_PROFILES",
+	}
+	RollupGasPriceOracleLayoutMigrationFlag = cli.StringFlag{
+		Name:   "rollup.gpolayoutmigration",
+		Usage:  "Read the gas price oracle with both storage layouts of a migration in progress, as from:to versions",
+		EnvVar: "ROLLUP_GPO_LAYOUT_MIGRATION",
+	}
+	RollupFeeAttestationKeyFlag = cli.StringFlag{
+		Name:   "rollup.feeattestationkey",
+		Usage:  "Hex encoded private key that replayed fee reports are signed with, enables rollup_replayFees, requires an archive node",
+		EnvVar: "ROLLUP_FEE_ATTESTATION_KEY",
+	}
+	RollupFeeValidationWorkersFlag = cli.IntFlag{
+		Name:   "rollup.feevalidationworkers",
+		Usage:  "Number of workers that verify the fees of a set of sequencer transactions concurrently, such as the persisted transaction pool (default = number of CPUs)",
+		EnvVar: "ROLLUP_FEE_VALIDATION_WORKERS",
+	}
+	RollupAllowUninitializedGPOFlag = cli.BoolFlag{
+		Name:   "rollup.allowuninitializedgpo",
+		Usage:  "Accept transactions while the OVM_GasPriceOracle is not initialized, for local development",
+		EnvVar: "ROLLUP_ALLOW_UNINITIALIZED_GPO",
+	}
+	RollupFeeAssertionFlag = cli.BoolFlag{
+		Name:   "rollup.feeassertion",
+		Usage:  "Fail verification of transactions whose fees do not match the gas price oracle of the parent state, not compatible with fee quotes",
+		EnvVar: "ROLLUP_FEE_ASSERTION",
+	}
+	RollupGPOUpstreamHttpFlag = cli.StringFlag{
+		Name:   "rollup.gpoupstreamhttp",
+		Usage:  "HTTP endpoint of a node that the gas price oracle is read from when the local state is not available",
+		EnvVar: "ROLLUP_GPO_UPSTREAM_HTTP",
+	}
+	RollupGPOUpstreamTTLFlag = cli.DurationFlag{
+		Name:   "rollup.gpoupstreamttl",
+		Usage:  "How long the gas price oracle values read from the upstream node are cached",
+		Value:  15 * time.Second,
+		EnvVar: "ROLLUP_GPO_UPSTREAM_TTL",
+	}
+	RollupGPOUpstreamMaxStalenessFlag = cli.DurationFlag{
+		Name:   "rollup.gpoupstreammaxstaleness",
+		Usage:  "How long the gas price oracle values read from the upstream node are used while it cannot be reached",
+		Value:  5 * time.Minute,
+		EnvVar: "ROLLUP_GPO_UPSTREAM_MAX_STALENESS",
+	}
+	RollupGPOReplicaHttpFlag = cli.StringFlag{
+		Name:   "rollup.gporeplicahttp",
+		Usage:  "HTTP endpoint of a read replica that the gas price oracle is read from while txs are applied to the local state",
+		EnvVar: "ROLLUP_GPO_REPLICA_HTTP",
+	}
+	RollupGPOReplicaMaxLagFlag = cli.Uint64Flag{
+		Name:   "rollup.gporeplicamaxlag",
+		Usage:  "Number of blocks that the gas price oracle replica can be behind the local chain",
+		Value:  4,
+		EnvVar: "ROLLUP_GPO_REPLICA_MAX_LAG",
+	}
+	RollupRemoteOracleHttpFlag = cli.StringFlag{
+		Name:   "rollup.remoteoraclehttp",
+		Usage:  "HTTP endpoint of a node, usually the sequencer, that the gas prices served over RPC are read from",
+		EnvVar: "ROLLUP_REMOTE_ORACLE_HTTP",
+	}
+	RollupRemoteOracleTTLFlag = cli.DurationFlag{
+		Name:   "rollup.remoteoraclettl",
+		Usage:  "How long the gas prices read from the remote node are cached",
+		Value:  gasprice.DefaultRemoteRollupOracleTTL,
+		EnvVar: "ROLLUP_REMOTE_ORACLE_TTL",
+	}
+	RollupVerifierL1GasPriceFlag = cli.BoolFlag{
+		Name:   "rollup.verifierl1gasprice",
+		Usage:  "Read the L1 gas price from the base fee of the L1 node and compare it to the L1 gas price the sequencer charged, verifier only",
+		EnvVar: "ROLLUP_VERIFIER_L1_GAS_PRICE",
+	}
 	GasPriceOracleOwnerAddress = cli.StringFlag{
 		Name:   "rollup.gaspriceoracleowneraddress",
 		Usage:  "Owner of the OVM_GasPriceOracle",
@@ -1165,6 +1591,35 @@ func setEth1(ctx *cli.Context, cfg *rollup.Config) {
 	}
 }
 
+// UsingOVM
+// setRollupConfigFile fills in the options that are not set with the rollup
+// config that is shared with the other services of the rollup
+func setRollupConfigFile(ctx *cli.Context, cfg *rollup.Config) {
+	if !ctx.GlobalIsSet(RollupConfigFileFlag.Name) {
+		return
+	}
+	config, err := rollupconfig.Load(ctx.GlobalString(RollupConfigFileFlag.Name))
+	if err != nil {
+		Fatalf("Option %q: %v", RollupConfigFileFlag.Name, err)
+	}
+	cfg.RollupConfig = config
+	if !ctx.GlobalIsSet(Eth1ChainIdFlag.Name) {
+		cfg.Eth1ChainId = config.L1ChainID
+	}
+	if config.BatchInboxDeployHeight != 0 && !ctx.GlobalIsSet(Eth1CanonicalTransactionChainDeployHeightFlag.Name) {
+		cfg.CanonicalTransactionChainDeployHeight = new(big.Int).SetUint64(config.BatchInboxDeployHeight)
+	}
+	if config.StateCommitmentChain != (common.Address{}) && !ctx.GlobalIsSet(RollupStateCommitmentChainFlag.Name) {
+		cfg.StateCommitmentChainAddress = config.StateCommitmentChain
+	}
+	if config.Fees.ThresholdUp != nil && !ctx.GlobalIsSet(RollupFeeThresholdUpFlag.Name) {
+		cfg.FeeThresholdUp = new(big.Float).SetFloat64(*config.Fees.ThresholdUp)
+	}
+	if config.Fees.ThresholdDown != nil && !ctx.GlobalIsSet(RollupFeeThresholdDownFlag.Name) {
+		cfg.FeeThresholdDown = new(big.Float).SetFloat64(*config.Fees.ThresholdDown)
+	}
+}
+
 // UsingOVM
 // setRollup configures the rollup
 func setRollup(ctx *cli.Context, cfg *rollup.Config) {
@@ -1216,10 +1671,245 @@ func setRollup(ctx *cli.Context, cfg *rollup.Config) {
 		val := ctx.GlobalFloat64(RollupFeeThresholdDownFlag.Name)
 		cfg.FeeThresholdDown = new(big.Float).SetFloat64(val)
 	}
+	if ctx.GlobalIsSet(RollupFeeThresholdDownAutoFlag.Name) {
+		cfg.FeeThresholdDownAuto = true
+	}
+	if ctx.GlobalIsSet(RollupFeeThresholdDownMinFlag.Name) {
+		cfg.FeeThresholdDownMin = ctx.GlobalFloat64(RollupFeeThresholdDownMinFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFeeThresholdDownTargetFlag.Name) {
+		cfg.FeeThresholdDownTarget = ctx.GlobalFloat64(RollupFeeThresholdDownTargetFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFeePolicyFileFlag.Name) {
+		cfg.FeePolicyFile = ctx.GlobalString(RollupFeePolicyFileFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFeePolicyShadowFileFlag.Name) {
+		cfg.FeePolicyShadowFile = ctx.GlobalString(RollupFeePolicyShadowFileFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFeeHookPluginsFlag.Name) {
+		cfg.FeeHookPlugins = splitAndTrim(ctx.GlobalString(RollupFeeHookPluginsFlag.Name))
+	}
 	if ctx.GlobalIsSet(RollupFeeThresholdUpFlag.Name) {
 		val := ctx.GlobalFloat64(RollupFeeThresholdUpFlag.Name)
 		cfg.FeeThresholdUp = new(big.Float).SetFloat64(val)
 	}
+	if ctx.GlobalIsSet(RollupThrottleBacklogBytesFlag.Name) {
+		cfg.ThrottleBacklogBytes = ctx.GlobalUint64(RollupThrottleBacklogBytesFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupMaxBacklogBytesFlag.Name) {
+		cfg.MaxBacklogBytes = ctx.GlobalUint64(RollupMaxBacklogBytesFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupMaxBatchLagFlag.Name) {
+		cfg.MaxBatchLag = ctx.GlobalDuration(RollupMaxBatchLagFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupThrottleMaxDelayFlag.Name) {
+		cfg.ThrottleMaxDelay = ctx.GlobalDuration(RollupThrottleMaxDelayFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupGasTokenConversionRateFlag.Name) {
+		val := ctx.GlobalFloat64(RollupGasTokenConversionRateFlag.Name)
+		cfg.GasToken = &fees.GasToken{
+			Symbol:         ctx.GlobalString(RollupGasTokenSymbolFlag.Name),
+			Decimals:       uint8(ctx.GlobalUint(RollupGasTokenDecimalsFlag.Name)),
+			ConversionRate: new(big.Float).SetFloat64(val),
+		}
+	}
+	if ctx.GlobalIsSet(RollupP2PSyncFlag.Name) {
+		cfg.P2PSync = ctx.GlobalBool(RollupP2PSyncFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupL1NodeHttpFlag.Name) {
+		cfg.L1NodeHttp = ctx.GlobalString(RollupL1NodeHttpFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupProtocolVersionsAddressFlag.Name) {
+		addr := ctx.GlobalString(RollupProtocolVersionsAddressFlag.Name)
+		cfg.ProtocolVersionsAddress = common.HexToAddress(addr)
+	}
+	if ctx.GlobalIsSet(RollupProtocolVersionHaltFlag.Name) {
+		halt, err := rollup.ParseProtocolVersionPrecision(ctx.GlobalString(RollupProtocolVersionHaltFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupProtocolVersionHaltFlag.Name, err)
+		}
+		cfg.ProtocolVersionHalt = halt
+	}
+	if ctx.GlobalIsSet(RollupFeeReconciliationFlag.Name) {
+		cfg.FeeReconciliation = ctx.GlobalBool(RollupFeeReconciliationFlag.Name)
+	}
+	cfg.FeeAnomalyMultiple = ctx.GlobalFloat64(RollupFeeAnomalyMultipleFlag.Name)
+	cfg.FeeAnomalyGasPriceJump = ctx.GlobalFloat64(RollupFeeAnomalyGasPriceJumpFlag.Name)
+	if webhooks := ctx.GlobalString(RollupFeeAnomalyWebhooksFlag.Name); webhooks != "" {
+		cfg.FeeAnomalyWebhooks = splitAndTrim(webhooks)
+	}
+	if ctx.GlobalIsSet(RollupFeeAnomalyRoutingKeyFlag.Name) {
+		cfg.FeeAnomalyRoutingKey = ctx.GlobalString(RollupFeeAnomalyRoutingKeyFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFeeEventsUrlFlag.Name) {
+		cfg.FeeEventsUrl = ctx.GlobalString(RollupFeeEventsUrlFlag.Name)
+	}
+	cfg.FeeEventsTopic = ctx.GlobalString(RollupFeeEventsTopicFlag.Name)
+	if ctx.GlobalIsSet(RollupFeeAuditLogFlag.Name) {
+		cfg.FeeAuditLog = ctx.GlobalString(RollupFeeAuditLogFlag.Name)
+	}
+	cfg.FeeAuditLogSize = ctx.GlobalUint(RollupFeeAuditLogSizeFlag.Name)
+	if ctx.GlobalIsSet(RollupFeeCheckRecordFlag.Name) {
+		cfg.FeeCheckRecord = ctx.GlobalString(RollupFeeCheckRecordFlag.Name)
+	}
+	cfg.FeeCheckRecordPeriod = ctx.GlobalDuration(RollupFeeCheckRecordPeriodFlag.Name)
+	if ctx.GlobalIsSet(DeveloperNoFeesFlag.Name) {
+		cfg.NoFees = ctx.GlobalBool(DeveloperNoFeesFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFeeEstimateMarginFlag.Name) {
+		cfg.FeeEstimateMargin = ctx.GlobalUint64(RollupFeeEstimateMarginFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupHydrateReceiptsFlag.Name) {
+		cfg.HydrateReceipts = ctx.GlobalBool(RollupHydrateReceiptsFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupConversionFeedFlag.Name) {
+		addr := ctx.GlobalString(RollupConversionFeedFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Option %q: invalid address %q", RollupConversionFeedFlag.Name, addr)
+		}
+		cfg.ConversionFeedAddress = common.HexToAddress(addr)
+	}
+	cfg.QuoteCurrencySymbol = ctx.GlobalString(RollupQuoteSymbolFlag.Name)
+	decimals := ctx.GlobalUint(RollupQuoteDecimalsFlag.Name)
+	if decimals > math.MaxUint8 {
+		Fatalf("Option %q: too many decimals %d", RollupQuoteDecimalsFlag.Name, decimals)
+	}
+	cfg.QuoteCurrencyDecimals = uint8(decimals)
+	cfg.ConversionFeedMaxAge = ctx.GlobalDuration(RollupConversionFeedMaxAgeFlag.Name)
+	cfg.DABackend = ctx.GlobalString(RollupDABackendFlag.Name)
+	if ctx.GlobalIsSet(RollupDACostPerByteFlag.Name) {
+		cfg.DACostPerByte = GlobalBig(ctx, RollupDACostPerByteFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupDAPriceFeedFlag.Name) {
+		addr := ctx.GlobalString(RollupDAPriceFeedFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Option %q: invalid address %q", RollupDAPriceFeedFlag.Name, addr)
+		}
+		cfg.DAPriceFeedAddress = common.HexToAddress(addr)
+	}
+	decimals = ctx.GlobalUint(RollupDATokenDecimalsFlag.Name)
+	if decimals > math.MaxUint8 {
+		Fatalf("Option %q: too many decimals %d", RollupDATokenDecimalsFlag.Name, decimals)
+	}
+	cfg.DATokenDecimals = uint8(decimals)
+	if ctx.GlobalIsSet(RollupFeeTokensFlag.Name) {
+		tokens, err := rollup.LoadFeeTokens(ctx.GlobalString(RollupFeeTokensFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupFeeTokensFlag.Name, err)
+		}
+		cfg.FeeTokens = tokens
+	}
+	if ctx.GlobalIsSet(RollupFeeCollectorKeyFlag.Name) {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.GlobalString(RollupFeeCollectorKeyFlag.Name), "0x"))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupFeeCollectorKeyFlag.Name, err)
+		}
+		cfg.FeeCollectorKey = key
+	}
+	if ctx.GlobalIsSet(RollupFeeRebateKeyFlag.Name) {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.GlobalString(RollupFeeRebateKeyFlag.Name), "0x"))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupFeeRebateKeyFlag.Name, err)
+		}
+		cfg.FeeRebateKey = key
+	}
+	if ctx.GlobalIsSet(RollupPnLFlag.Name) {
+		cfg.PnL = ctx.GlobalBool(RollupPnLFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupStateCommitmentChainFlag.Name) {
+		addr := ctx.GlobalString(RollupStateCommitmentChainFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Option %q: invalid address %q", RollupStateCommitmentChainFlag.Name, addr)
+		}
+		cfg.StateCommitmentChainAddress = common.HexToAddress(addr)
+	}
+	if ctx.GlobalIsSet(RollupExpressLaneMultiplierFlag.Name) {
+		val := ctx.GlobalFloat64(RollupExpressLaneMultiplierFlag.Name)
+		cfg.ExpressLaneMultiplier = new(big.Float).SetFloat64(val)
+		cfg.ExpressLaneCapacity = ctx.GlobalInt(RollupExpressLaneCapacityFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupFCFSFlag.Name) {
+		cfg.FCFS = ctx.GlobalBool(RollupFCFSFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupDappLabelsFlag.Name) {
+		labels, err := rollup.LoadDappLabels(ctx.GlobalString(RollupDappLabelsFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupDappLabelsFlag.Name, err)
+		}
+		cfg.DappLabels = labels
+	}
+	if ctx.GlobalIsSet(RollupReceiptProfilesFlag.Name) {
+		profiles, err := rollup.LoadReceiptProfiles(ctx.GlobalString(RollupReceiptProfilesFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupReceiptProfilesFlag.Name, err)
+		}
+		cfg.ReceiptProfiles = profiles
+	}
+	if ctx.GlobalIsSet(RollupFeeQuoteKeyFlag.Name) {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.GlobalString(RollupFeeQuoteKeyFlag.Name), "0x"))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupFeeQuoteKeyFlag.Name, err)
+		}
+		cfg.FeeQuoteKey = key
+	}
+	cfg.FeeQuoteValidity = ctx.GlobalUint64(RollupFeeQuoteValidityFlag.Name)
+	if ctx.GlobalIsSet(RollupFeeQuoteMaxVolumeFlag.Name) {
+		cfg.FeeQuoteMaxVolume = GlobalBig(ctx, RollupFeeQuoteMaxVolumeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupNetworkProfilesFlag.Name) {
+		profiles, err := rollup.LoadNetworkProfiles(ctx.GlobalString(RollupNetworkProfilesFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupNetworkProfilesFlag.Name, err)
+		}
+		cfg.NetworkProfiles = profiles
+	}
+	if ctx.GlobalIsSet(RollupGasPriceOracleLayoutMigrationFlag.Name) {
+		migration, err := rcfg.ParseLayoutMigration(ctx.GlobalString(RollupGasPriceOracleLayoutMigrationFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupGasPriceOracleLayoutMigrationFlag.Name, err)
+		}
+		cfg.GasPriceOracleLayoutMigration = migration
+	}
+	if ctx.GlobalIsSet(RollupFeeAttestationKeyFlag.Name) {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.GlobalString(RollupFeeAttestationKeyFlag.Name), "0x"))
+		if err != nil {
+			Fatalf("Option %q: %v", RollupFeeAttestationKeyFlag.Name, err)
+		}
+		cfg.FeeAttestationKey = key
+	}
+	if ctx.GlobalIsSet(RollupFeeValidationWorkersFlag.Name) {
+		cfg.FeeValidationWorkers = ctx.GlobalInt(RollupFeeValidationWorkersFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupAllowUninitializedGPOFlag.Name) {
+		cfg.AllowUninitializedGPO = true
+	}
+	if ctx.GlobalIsSet(RollupFeeAssertionFlag.Name) {
+		cfg.FeeAssertion = true
+	}
+	if ctx.GlobalIsSet(RollupGPOUpstreamHttpFlag.Name) {
+		cfg.GasPriceOracleUpstreamHttp = ctx.GlobalString(RollupGPOUpstreamHttpFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupGPOUpstreamTTLFlag.Name) {
+		cfg.GasPriceOracleUpstreamTTL = ctx.GlobalDuration(RollupGPOUpstreamTTLFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupGPOUpstreamMaxStalenessFlag.Name) {
+		cfg.GasPriceOracleUpstreamMaxStaleness = ctx.GlobalDuration(RollupGPOUpstreamMaxStalenessFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupGPOReplicaHttpFlag.Name) {
+		cfg.GasPriceOracleReplicaHttp = ctx.GlobalString(RollupGPOReplicaHttpFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupGPOReplicaMaxLagFlag.Name) {
+		cfg.GasPriceOracleReplicaMaxLag = ctx.GlobalUint64(RollupGPOReplicaMaxLagFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupRemoteOracleHttpFlag.Name) {
+		cfg.RemoteRollupOracleHttp = ctx.GlobalString(RollupRemoteOracleHttpFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupRemoteOracleTTLFlag.Name) {
+		cfg.RemoteRollupOracleTTL = ctx.GlobalDuration(RollupRemoteOracleTTLFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupVerifierL1GasPriceFlag.Name) {
+		cfg.VerifierL1GasPrice = ctx.GlobalBool(RollupVerifierL1GasPriceFlag.Name)
+	}
 }
 
 // setLes configures the les server and ultra light client settings from the command line flags.
@@ -1507,6 +2197,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPersistFlag.Name) {
+		cfg.Persist = ctx.GlobalString(TxPoolPersistFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1681,6 +2374,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	setLes(ctx, cfg)
 	setEth1(ctx, &cfg.Rollup)
 	setRollup(ctx, &cfg.Rollup)
+	setRollupConfigFile(ctx, &cfg.Rollup)
 
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
@@ -1774,6 +2468,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		if ctx.GlobalIsSet(ChainIdFlag.Name) {
 			id := ctx.GlobalUint64(ChainIdFlag.Name)
 			chainID = new(big.Int).SetUint64(id)
+		} else if cfg.Rollup.RollupConfig != nil {
+			chainID = new(big.Int).SetUint64(cfg.Rollup.RollupConfig.L2ChainID)
 		}
 
 		// UsingOVM
@@ -1887,6 +2583,17 @@ func SetupMetrics(ctx *cli.Context) {
 	}
 }
 
+// SetupTracing registers the OTLP exporter when an endpoint is configured
+func SetupTracing(ctx *cli.Context) {
+	endpoint := ctx.GlobalString(TracingOTLPEndpointFlag.Name)
+	if endpoint == "" {
+		return
+	}
+	ratio := ctx.GlobalFloat64(TracingSampleRatioFlag.Name)
+	log.Info("Enabling tracing export", "endpoint", endpoint, "sample-ratio", ratio)
+	tracing.Register(tracing.NewOTLPExporter(endpoint, "l2geth"), ratio)
+}
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
@@ -0,0 +1,82 @@
+// Package gaspriceoracle reads the OVM_GasPriceOracle predeploy that
+// configures the fees of the rollup.
+package gaspriceoracle
+
+//go:generate abigen --abi contract/gaspriceoracle.abi --bin contract/gaspriceoracle.bin --pkg contract --type GasPriceOracle --out contract/gaspriceoracle.go
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle/contract"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// Backend is the part of a remote node that the OVM_GasPriceOracle is read
+// from, an ethclient.Client satisfies it
+type Backend interface {
+	bind.ContractCaller
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+// GPOReader reads the values of the OVM_GasPriceOracle that configure fees,
+// either from a local state or from a remote node
+type GPOReader struct {
+	read func(ctx context.Context) (*rcfg.GPOStorageSlots, error)
+}
+
+// NewStateGPOReader returns a reader of the OVM_GasPriceOracle in a state.
+// The storage slots are read with the layout that the contract reports, or
+// with both layouts of the migration when it is not nil.
+func NewStateGPOReader(db rcfg.StateReader, migration *rcfg.LayoutMigration) *GPOReader {
+	return &GPOReader{
+		read: func(context.Context) (*rcfg.GPOStorageSlots, error) {
+			if migration != nil {
+				return migration.Read(db)
+			}
+			return rcfg.ReadGPOStorageSlots(db)
+		},
+	}
+}
+
+// NewCallGPOReader returns a reader of the OVM_GasPriceOracle of a remote
+// node at a block, nil is the latest block. The values are read with
+// eth_call through the getters of the contract, so they do not depend on its
+// storage layout. Only the layout version, which has no getter, is read from
+// storage.
+func NewCallGPOReader(backend Backend, block *big.Int) (*GPOReader, error) {
+	caller, err := contract.NewGasPriceOracleCaller(rcfg.L2GasPriceOracleAddress, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &GPOReader{
+		read: func(ctx context.Context) (*rcfg.GPOStorageSlots, error) {
+			opts := &bind.CallOpts{Context: ctx, BlockNumber: block}
+			owner, err := caller.Owner(opts)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot call owner: %w", err)
+			}
+			gasPrice, err := caller.GasPrice(opts)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot call gasPrice: %w", err)
+			}
+			version, err := backend.StorageAt(ctx, rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceOracleVersionSlot, block)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot read layout version: %w", err)
+			}
+			return &rcfg.GPOStorageSlots{
+				Version:  new(big.Int).SetBytes(version).Uint64(),
+				Owner:    owner,
+				GasPrice: gasPrice,
+			}, nil
+		},
+	}, nil
+}
+
+// Read returns the values of the OVM_GasPriceOracle
+func (r *GPOReader) Read(ctx context.Context) (*rcfg.GPOStorageSlots, error) {
+	return r.read(ctx)
+}
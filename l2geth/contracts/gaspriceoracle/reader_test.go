@@ -0,0 +1,83 @@
+package gaspriceoracle
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// deployedCode returns the runtime code of the OVM_GasPriceOracle
+func deployedCode(t *testing.T) []byte {
+	key, _ := crypto.GenerateKey()
+	auth := bind.NewKeyedTransactor(key)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 10_000_000)
+	defer backend.Close()
+
+	addr, _, _, err := contract.DeployGasPriceOracle(auth, backend, auth.From, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("cannot deploy gas price oracle: %v", err)
+	}
+	backend.Commit()
+	code, err := backend.CodeAt(context.Background(), addr, nil)
+	if err != nil || len(code) == 0 {
+		t.Fatalf("cannot get code of gas price oracle: %v", err)
+	}
+	return code
+}
+
+func TestGPOReader(t *testing.T) {
+	owner := common.HexToAddress("0x1234")
+	gasPrice := big.NewInt(15_000_000)
+	storage := map[common.Hash]common.Hash{
+		rcfg.L2GasPriceOracleOwnerSlot: common.BytesToHash(owner.Bytes()),
+		rcfg.L2GasPriceSlot:            common.BigToHash(gasPrice),
+	}
+	code := deployedCode(t)
+
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		rcfg.L2GasPriceOracleAddress: {Code: code, Storage: storage, Balance: new(big.Int)},
+	}, 10_000_000)
+	defer backend.Close()
+	callReader, err := NewCallGPOReader(backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.SetCode(rcfg.L2GasPriceOracleAddress, code)
+	for key, value := range storage {
+		statedb.SetState(rcfg.L2GasPriceOracleAddress, key, value)
+	}
+
+	tests := map[string]*GPOReader{
+		"state": NewStateGPOReader(statedb, nil),
+		"call":  callReader,
+	}
+	for name, reader := range tests {
+		t.Run(name, func(t *testing.T) {
+			slots, err := reader.Read(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if slots.Owner != owner {
+				t.Fatalf("mismatched owner: got %s, expect %s", slots.Owner.Hex(), owner.Hex())
+			}
+			if slots.GasPrice.Cmp(gasPrice) != 0 {
+				t.Fatalf("mismatched gas price: got %d, expect %d", slots.GasPrice, gasPrice)
+			}
+			if slots.Version != 0 {
+				t.Fatalf("mismatched version: got %d, expect 0", slots.Version)
+			}
+		})
+	}
+}
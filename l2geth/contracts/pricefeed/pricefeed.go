@@ -0,0 +1,60 @@
+// Package pricefeed reads the Chainlink style price feeds that the fees of
+// the rollup are converted into other currencies with.
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// aggregatorABI is the part of the interface of a Chainlink aggregator that
+// the latest price is read with
+const aggregatorABI = `[{"inputs":[],"name":"decimals","outputs":[{"internalType":"uint8","name":"","type":"uint8"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"latestRoundData","outputs":[{"internalType":"uint80","name":"roundId","type":"uint80"},{"internalType":"int256","name":"answer","type":"int256"},{"internalType":"uint256","name":"startedAt","type":"uint256"},{"internalType":"uint256","name":"updatedAt","type":"uint256"},{"internalType":"uint80","name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}]`
+
+// Feed is a price feed of ether in a currency, such as the ETH/USD feed. It
+// satisfies fees.PriceSource.
+type Feed struct {
+	address  common.Address
+	contract *bind.BoundContract
+}
+
+// New returns the price feed at the address, the feed may live on L1 or on
+// L2 depending on the caller
+func New(address common.Address, caller bind.ContractCaller) (*Feed, error) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Feed{
+		address:  address,
+		contract: bind.NewBoundContract(address, parsed, caller, nil, nil),
+	}, nil
+}
+
+// Price returns the latest price of the feed with the number of decimals of
+// the price and the time that it was updated at
+func (f *Feed) Price(ctx context.Context) (*big.Int, uint8, time.Time, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	var decimals uint8
+	if err := f.contract.Call(opts, &decimals, "decimals"); err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("Cannot call decimals of %s: %w", f.address.Hex(), err)
+	}
+	var round struct {
+		RoundId         *big.Int
+		Answer          *big.Int
+		StartedAt       *big.Int
+		UpdatedAt       *big.Int
+		AnsweredInRound *big.Int
+	}
+	if err := f.contract.Call(opts, &round, "latestRoundData"); err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("Cannot call latestRoundData of %s: %w", f.address.Hex(), err)
+	}
+	return round.Answer, decimals, time.Unix(round.UpdatedAt.Int64(), 0), nil
+}
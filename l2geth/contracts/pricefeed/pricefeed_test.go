@@ -0,0 +1,63 @@
+package pricefeed
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testAggregator answers the calls of the aggregator interface
+type testAggregator struct {
+	abi     abi.ABI
+	answer  *big.Int
+	updated int64
+}
+
+func (a *testAggregator) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func (a *testAggregator) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := a.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(uint8(8))
+	default:
+		one := big.NewInt(1)
+		return method.Outputs.Pack(one, a.answer, big.NewInt(a.updated), big.NewInt(a.updated), one)
+	}
+}
+
+func TestFeedPrice(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregator := &testAggregator{abi: parsed, answer: big.NewInt(2000_00000000), updated: 1700000000}
+	feed, err := New(common.Address{1}, aggregator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	price, decimals, updated, err := feed.Price(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Cmp(aggregator.answer) != 0 {
+		t.Fatalf("mismatched price: got %d, expect %d", price, aggregator.answer)
+	}
+	if decimals != 8 {
+		t.Fatalf("mismatched decimals: got %d, expect 8", decimals)
+	}
+	if !updated.Equal(time.Unix(aggregator.updated, 0)) {
+		t.Fatalf("mismatched update time: got %v", updated)
+	}
+}
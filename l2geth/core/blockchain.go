@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 	"github.com/ethereum/go-ethereum/trie"
 	lru "github.com/hashicorp/golang-lru"
 )
@@ -174,6 +175,8 @@ type BlockChain struct {
 	processor  Processor  // Block transaction processor interface
 	vmConfig   vm.Config
 
+	feeAssertion    atomic.Value                   // FeeAssertion run against the pre-state of imported blocks
+	txMetaSource    atomic.Value                   // TxMetaSource that restores the metadata of imported blocks
 	badBlocks       *lru.Cache                     // Bad block cache
 	shouldPreserve  func(*types.Block) bool        // Function used to determine whether should preserve the given block.
 	terminateInsert func(common.Hash, uint64) bool // Testing hook used to terminate ancient receipt chain insertion.
@@ -512,6 +515,43 @@ func (bc *BlockChain) CurrentFastBlock() *types.Block {
 	return bc.currentFastBlock.Load().(*types.Block)
 }
 
+// FeeAssertion checks the fees that the transactions of a block were charged
+// against the state of its parent, returning an error fails the import of the
+// block
+type FeeAssertion func(block *types.Block, parent *state.StateDB) error
+
+// SetFeeAssertion sets the fee assertion that imported blocks are checked
+// with before they are processed
+func (bc *BlockChain) SetFeeAssertion(assertion FeeAssertion) {
+	bc.feeAssertion.Store(assertion)
+}
+
+// TxMetaSource restores the metadata of the transactions of a block that was
+// imported from the network, which is not part of the blocks that peers send.
+// Returning an error fails the import of the block.
+type TxMetaSource func(block *types.Block) error
+
+// SetTxMetaSource sets the source that the metadata of the transactions of
+// imported blocks is restored from before they are processed
+func (bc *BlockChain) SetTxMetaSource(source TxMetaSource) {
+	bc.txMetaSource.Store(source)
+}
+
+// restoreTxMeta restores the metadata of the transactions of the blocks,
+// returning the index of the block that failed
+func (bc *BlockChain) restoreTxMeta(chain types.Blocks) (int, error) {
+	source, ok := bc.txMetaSource.Load().(TxMetaSource)
+	if !ok || source == nil {
+		return 0, nil
+	}
+	for i, block := range chain {
+		if err := source(block); err != nil {
+			return i, fmt.Errorf("Cannot restore transaction meta of block %d: %w", block.NumberU64(), err)
+		}
+	}
+	return 0, nil
+}
+
 // Validator returns the current validator.
 func (bc *BlockChain) Validator() Validator {
 	return bc.validator
@@ -1012,6 +1052,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			liveBlocks, liveReceipts = append(liveBlocks, blockChain[i]), append(liveReceipts, receiptChain[i])
 		}
 	}
+	// The metadata of the transactions is written along with the blocks
+	if n, err := bc.restoreTxMeta(blockChain); err != nil {
+		return n, err
+	}
 
 	var (
 		stats = struct{ processed, ignored int32 }{}
@@ -1130,6 +1174,9 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			// Flush data into ancient database.
 			size += rawdb.WriteAncientBlock(bc.db, block, receiptChain[i], bc.GetTd(block.Hash(), block.NumberU64()))
 			rawdb.WriteTxLookupEntries(batch, block)
+			for _, tx := range block.Transactions() {
+				rawdb.WriteTransactionMeta(batch, block.NumberU64(), tx.GetMeta())
+			}
 
 			stats.processed++
 		}
@@ -1208,6 +1255,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			for _, tx := range block.Transactions() {
 				rawdb.WriteTransactionMeta(batch, block.NumberU64(), tx.GetMeta())
 			}
+			rawdb.WriteBlockFees(batch, newBlockFees(bc.chainConfig, block, receiptChain[i]))
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts,
@@ -1300,16 +1348,38 @@ func (bc *BlockChain) writeKnownBlock(block *types.Block) error {
 }
 
 // WriteBlockWithState writes the block and all associated state to the database.
-func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
+// The fee params must be read with ReadBlockFeeParams from the state of the
+// parent before the block is applied.
+func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, feeParams *fees.BlockFeeParams, emitHeadEvent bool) (status WriteStatus, err error) {
 	bc.chainmu.Lock()
 	defer bc.chainmu.Unlock()
 
-	return bc.writeBlockWithState(block, receipts, logs, state, emitHeadEvent)
+	return bc.writeBlockWithState(block, receipts, logs, state, feeParams, emitHeadEvent)
+}
+
+// newBlockFees records the fee components of the transactions in a block so
+// that fee statistics can be served without scanning the chain
+func newBlockFees(config *params.ChainConfig, block *types.Block, receipts types.Receipts) *fees.BlockFees {
+	blockFees := fees.NewBlockFees(block.NumberU64(), block.Time())
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		zeroes, nonZeroes := tx.DataCounts()
+		l1GasUsed := tx.L1GasUsedWith(BlockL1CalldataGas(config, block.Number(), tx.L1BlockNumber()))
+		if to := tx.To(); to != nil {
+			blockFees.AddL1GasTo(*to, l1GasUsed, zeroes+nonZeroes, receipts[i].GasUsed, tx.GasPrice())
+		} else {
+			blockFees.AddL1Gas(l1GasUsed, zeroes+nonZeroes, receipts[i].GasUsed, tx.GasPrice())
+		}
+	}
+	return blockFees
 }
 
 // writeBlockWithState writes the block and all associated state to the database,
-// but is expects the chain mutex to be held.
-func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
+// but is expects the chain mutex to be held. The fee params are those of the
+// gas price oracle in the state of the parent, nil when they are unknown.
+func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, feeParams *fees.BlockFeeParams, emitHeadEvent bool) (status WriteStatus, err error) {
 	bc.wg.Add(1)
 	defer bc.wg.Done()
 
@@ -1333,7 +1403,12 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	for _, tx := range block.Transactions() {
 		rawdb.WriteTransactionMeta(blockBatch, block.NumberU64(), tx.GetMeta())
 	}
+	rawdb.WriteBlockFees(blockBatch, newBlockFees(bc.chainConfig, block, receipts))
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	rawdb.WriteL1FeeReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	if feeParams != nil {
+		rawdb.WriteBlockFeeParams(blockBatch, block.Hash(), feeParams)
+	}
 	rawdb.WritePreimages(blockBatch, state.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
@@ -1495,6 +1570,11 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 				prev.Hash().Bytes()[:4], i, block.NumberU64(), block.Hash().Bytes()[:4], block.ParentHash().Bytes()[:4])
 		}
 	}
+	// The transactions are executed with their metadata, which is restored
+	// before the chain mutex is taken as the source may be remote
+	if n, err := bc.restoreTxMeta(chain); err != nil {
+		return n, err
+	}
 	// Pre-checks passed, start the full block imports
 	bc.wg.Add(1)
 	bc.chainmu.Lock()
@@ -1680,6 +1760,16 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 				}(time.Now())
 			}
 		}
+		// Check the fees against the parent state before it is modified
+		if assertion, ok := bc.feeAssertion.Load().(FeeAssertion); ok && assertion != nil {
+			if err := assertion(block, statedb); err != nil {
+				bc.reportBlock(block, nil, err)
+				return it.index, err
+			}
+		}
+		// Record the oracle params that the fees were charged at
+		feeParams := ReadBlockFeeParams(statedb, block.NumberU64())
+
 		// Process block using the parent state as reference point
 		substart := time.Now()
 		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
@@ -1717,7 +1807,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 
 		// Write the block to the chain and get the status.
 		substart = time.Now()
-		status, err := bc.writeBlockWithState(block, receipts, logs, statedb, false)
+		status, err := bc.writeBlockWithState(block, receipts, logs, statedb, feeParams, false)
 		if err != nil {
 			atomic.StoreUint32(&followupInterrupt, 1)
 			return it.index, err
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -473,6 +474,78 @@ func testBadHashes(t *testing.T, full bool) {
 	}
 }
 
+// Tests that a failing fee assertion aborts the import of a block before it is
+// processed.
+func TestFeeAssertion(t *testing.T) {
+	db, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	blocks := makeBlockChain(blockchain.CurrentBlock(), 3, ethash.NewFaker(), db, 10)
+	errAssertion := errors.New("fee mismatch")
+	var checked []uint64
+	blockchain.SetFeeAssertion(func(block *types.Block, parent *state.StateDB) error {
+		checked = append(checked, block.NumberU64())
+		if block.NumberU64() == 3 {
+			return errAssertion
+		}
+		return nil
+	})
+	n, err := blockchain.InsertChain(blocks)
+	if !errors.Is(err, errAssertion) {
+		t.Fatalf("error mismatch: have: %v, want: %v", err, errAssertion)
+	}
+	if n != 2 {
+		t.Fatalf("mismatched failed index: have %d, want 2", n)
+	}
+	if len(checked) != 3 {
+		t.Fatalf("mismatched checked blocks: have %v", checked)
+	}
+	if head := blockchain.CurrentBlock().NumberU64(); head != 2 {
+		t.Fatalf("mismatched head: have %d, want 2", head)
+	}
+}
+
+// Tests that the metadata of the transactions of every block is restored
+// before the chain is imported, and that a failure aborts the import.
+func TestTxMetaSource(t *testing.T) {
+	db, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	blocks := makeBlockChain(blockchain.CurrentBlock(), 3, ethash.NewFaker(), db, 10)
+	errSource := errors.New("transaction mismatch")
+	var restored []uint64
+	blockchain.SetTxMetaSource(func(block *types.Block) error {
+		restored = append(restored, block.NumberU64())
+		if block.NumberU64() == 3 {
+			return errSource
+		}
+		return nil
+	})
+	n, err := blockchain.InsertChain(blocks)
+	if !errors.Is(err, errSource) {
+		t.Fatalf("error mismatch: have: %v, want: %v", err, errSource)
+	}
+	if n != 2 {
+		t.Fatalf("mismatched failed index: have %d, want 2", n)
+	}
+	if len(restored) != 3 {
+		t.Fatalf("mismatched restored blocks: have %v", restored)
+	}
+	if head := blockchain.CurrentBlock().NumberU64(); head != 0 {
+		t.Fatalf("mismatched head: have %d, want 0", head)
+	}
+	blockchain.SetTxMetaSource(nil)
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+}
+
 // Tests that bad hashes are detected on boot, and the chain rolled back to a
 // good state prior to the bad hash.
 func TestReorgBadHeaderHashes(t *testing.T) { testReorgBadHashes(t, false) }
@@ -55,6 +55,8 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		Difficulty:  new(big.Int).Set(header.Difficulty),
 		GasLimit:    header.GasLimit,
 		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		// The rollup rules are selected by the L1 block number
+		L1BlockNumber: msg.L1BlockNumber(),
 	}
 }
 
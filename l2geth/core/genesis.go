@@ -247,6 +247,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
 	}
 	compatErr := storedcfg.CheckCompatible(newcfg, *height)
+	if rollupErr := checkRollupCompatible(db, storedcfg, newcfg, *height); rollupErr != nil && (compatErr == nil || rollupErr.RewindTo < compatErr.RewindTo) {
+		compatErr = rollupErr
+	}
 	if compatErr != nil && *height != 0 && compatErr.RewindTo != 0 {
 		return newcfg, stored, compatErr
 	}
@@ -254,6 +257,28 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 	return newcfg, stored, nil
 }
 
+// checkRollupCompatible checks the rollup forks of the new chain config
+// against the L1 block number of the transaction of the head block. The
+// returned error rewinds to the last block with a transaction of an L1 block
+// before the conflict.
+func checkRollupCompatible(db ethdb.Reader, storedcfg, newcfg *params.ChainConfig, height uint64) *params.ConfigCompatError {
+	meta := rawdb.ReadTransactionMeta(db, height)
+	if meta == nil || meta.L1BlockNumber == nil {
+		return nil
+	}
+	compatErr := storedcfg.CheckRollupCompatible(newcfg, meta.L1BlockNumber.Uint64())
+	if compatErr == nil {
+		return nil
+	}
+	// The blocks are ordered by the L1 block numbers of their transactions
+	l1RewindTo := compatErr.RewindTo
+	compatErr.RewindTo = uint64(sort.Search(int(height), func(i int) bool {
+		meta := rawdb.ReadTransactionMeta(db, uint64(i)+1)
+		return meta == nil || meta.L1BlockNumber == nil || meta.L1BlockNumber.Uint64() > l1RewindTo
+	}))
+	return compatErr
+}
+
 func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	switch {
 	case g != nil:
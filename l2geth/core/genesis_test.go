@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
@@ -166,3 +167,25 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckRollupCompatible(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	// The transactions of blocks 1 to 5 are of the L1 blocks 10 to 50
+	for number := uint64(1); number <= 5; number++ {
+		l1Block := new(big.Int).SetUint64(number * 10)
+		rawdb.WriteTransactionMeta(db, number, types.NewTransactionMeta(l1Block, 0, nil, types.QueueOriginSequencer, nil, nil, nil))
+	}
+	subsidy := func(l1Block int64) *params.ChainConfig {
+		return &params.ChainConfig{RollupForks: []params.RollupFork{{Name: "subsidy", L1Block: big.NewInt(l1Block), Features: []params.RollupFeature{params.RollupFeatureFeeSubsidy}}}}
+	}
+
+	if err := checkRollupCompatible(db, subsidy(25), subsidy(60), 5); err == nil || err.RewindTo != 2 {
+		t.Fatalf("mismatched rewind: got %v, expect block 2", err)
+	}
+	if err := checkRollupCompatible(db, subsidy(25), subsidy(60), 2); err != nil {
+		t.Fatalf("unexpected error before the fork: %v", err)
+	}
+	if err := checkRollupCompatible(db, subsidy(5), subsidy(10), 5); err == nil || err.RewindTo != 0 {
+		t.Fatalf("mismatched rewind: got %v, expect block 0", err)
+	}
+}
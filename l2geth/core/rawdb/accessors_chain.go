@@ -540,6 +540,7 @@ func ReadReceipts(db ethdb.Reader, hash common.Hash, number uint64, config *para
 		log.Error("Failed to derive block receipts fields", "hash", hash, "number", number, "err", err)
 		return nil
 	}
+	ReadL1FeeReceipts(db, hash, number, receipts)
 	return receipts
 }
 
@@ -565,6 +566,7 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	if err := db.Delete(blockReceiptsKey(number, hash)); err != nil {
 		log.Crit("Failed to delete block receipts", "err", err)
 	}
+	DeleteL1FeeReceipts(db, hash, number)
 }
 
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
@@ -637,10 +639,12 @@ func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
+	DeleteBlockFeeParams(db, hash, number)
 }
 
 // DeleteBlockWithoutNumber removes all block data associated with a hash, except
-// the hash to number mapping.
+// the hash to number mapping. The fee params of the block are kept, as the
+// blocks that are moved into the ancient store are deleted with it.
 func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
 	deleteHeaderWithoutNumber(db, hash, number)
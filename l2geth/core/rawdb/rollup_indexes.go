@@ -1,10 +1,16 @@
 package rawdb
 
 import (
+	"bytes"
+	"encoding/binary"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 )
 
 // ReadHeadIndex will read the known tip of the CTC
@@ -90,3 +96,223 @@ func WriteHeadBatchIndex(db ethdb.KeyValueWriter, index uint64) {
 		log.Crit("Failed to store head batch index", "err", err)
 	}
 }
+
+// ReadReceiptHydrationProgress will read the next block whose receipts are
+// hydrated with the L1 fee
+func ReadReceiptHydrationProgress(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(receiptHydrationKey)
+	if len(data) == 0 {
+		return nil
+	}
+	ret := new(big.Int).SetBytes(data).Uint64()
+	return &ret
+}
+
+// WriteReceiptHydrationProgress will write the next block whose receipts are
+// hydrated with the L1 fee
+func WriteReceiptHydrationProgress(db ethdb.KeyValueWriter, number uint64) {
+	value := new(big.Int).SetUint64(number).Bytes()
+	if number == 0 {
+		value = []byte{0}
+	}
+	if err := db.Put(receiptHydrationKey, value); err != nil {
+		log.Crit("Failed to store receipt hydration progress", "err", err)
+	}
+}
+
+// ReadBlockFees will read the fee components recorded for a block
+func ReadBlockFees(db ethdb.KeyValueReader, number uint64) *fees.BlockFees {
+	data, _ := db.Get(blockFeesKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var blockFees fees.BlockFees
+	if err := rlp.DecodeBytes(data, &blockFees); err != nil {
+		log.Error("Invalid block fees", "number", number, "err", err)
+		return nil
+	}
+	return &blockFees
+}
+
+// WriteBlockFees will write the fee components of a block
+func WriteBlockFees(db ethdb.KeyValueWriter, blockFees *fees.BlockFees) {
+	data, err := rlp.EncodeToBytes(blockFees)
+	if err != nil {
+		log.Crit("Failed to encode block fees", "err", err)
+	}
+	if err := db.Put(blockFeesKey(blockFees.Number), data); err != nil {
+		log.Crit("Failed to store block fees", "err", err)
+	}
+}
+
+// l1FeeReceipt is the storage form of the L1 fee of a receipt
+type l1FeeReceipt struct {
+	L1GasUsed  *big.Int
+	L1GasPrice *big.Int
+	L1Fee      *big.Int
+}
+
+// ReadL1FeeReceipts will read the L1 fees recorded for the receipts of a
+// block into the receipts and return whether the block has them
+func ReadL1FeeReceipts(db ethdb.KeyValueReader, hash common.Hash, number uint64, receipts types.Receipts) bool {
+	data, _ := db.Get(l1FeeReceiptsKey(number, hash))
+	if len(data) == 0 {
+		return false
+	}
+	var stored []l1FeeReceipt
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		log.Error("Invalid L1 fee receipts", "hash", hash, "number", number, "err", err)
+		return false
+	}
+	if len(stored) != len(receipts) {
+		log.Error("Mismatched L1 fee receipts", "hash", hash, "number", number, "have", len(stored), "want", len(receipts))
+		return false
+	}
+	for i, receipt := range receipts {
+		receipt.L1GasUsed = stored[i].L1GasUsed
+		receipt.L1GasPrice = stored[i].L1GasPrice
+		receipt.L1Fee = stored[i].L1Fee
+	}
+	return true
+}
+
+// WriteL1FeeReceipts will write the L1 fees of the receipts of a block, it
+// writes nothing when the receipts have no L1 fee
+func WriteL1FeeReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts) {
+	if len(receipts) == 0 || receipts[0].L1Fee == nil {
+		return
+	}
+	stored := make([]l1FeeReceipt, len(receipts))
+	for i, receipt := range receipts {
+		stored[i] = l1FeeReceipt{receipt.L1GasUsed, receipt.L1GasPrice, receipt.L1Fee}
+	}
+	data, err := rlp.EncodeToBytes(stored)
+	if err != nil {
+		log.Crit("Failed to encode L1 fee receipts", "err", err)
+	}
+	if err := db.Put(l1FeeReceiptsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store L1 fee receipts", "err", err)
+	}
+}
+
+// DeleteL1FeeReceipts will remove the L1 fees of the receipts of a block
+func DeleteL1FeeReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(l1FeeReceiptsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete L1 fee receipts", "err", err)
+	}
+}
+
+// ReadBlockFeeParams will read the gas price oracle parameters that the
+// fees of a block were checked against
+func ReadBlockFeeParams(db ethdb.KeyValueReader, hash common.Hash, number uint64) *fees.BlockFeeParams {
+	data, _ := db.Get(blockFeeParamsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var params fees.BlockFeeParams
+	if err := rlp.DecodeBytes(data, &params); err != nil {
+		log.Error("Invalid block fee params", "hash", hash, "number", number, "err", err)
+		return nil
+	}
+	return &params
+}
+
+// WriteBlockFeeParams will write the gas price oracle parameters that the
+// fees of a block were checked against
+func WriteBlockFeeParams(db ethdb.KeyValueWriter, hash common.Hash, params *fees.BlockFeeParams) {
+	data, err := rlp.EncodeToBytes(params)
+	if err != nil {
+		log.Crit("Failed to encode block fee params", "err", err)
+	}
+	if err := db.Put(blockFeeParamsKey(params.Number, hash), data); err != nil {
+		log.Crit("Failed to store block fee params", "err", err)
+	}
+}
+
+// DeleteBlockFeeParams will remove the gas price oracle parameters of a block
+func DeleteBlockFeeParams(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockFeeParamsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block fee params", "err", err)
+	}
+}
+
+// ReadPnLL1Block will read the next L1 block whose spend is added to the
+// profit and loss ledger
+func ReadPnLL1Block(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(pnlL1BlockKey)
+	if len(data) == 0 {
+		return nil
+	}
+	ret := new(big.Int).SetBytes(data).Uint64()
+	return &ret
+}
+
+// WritePnLL1Block will write the next L1 block whose spend is added to the
+// profit and loss ledger
+func WritePnLL1Block(db ethdb.KeyValueWriter, number uint64) {
+	value := new(big.Int).SetUint64(number).Bytes()
+	if number == 0 {
+		value = []byte{0}
+	}
+	if err := db.Put(pnlL1BlockKey, value); err != nil {
+		log.Crit("Failed to store profit and loss progress", "err", err)
+	}
+}
+
+// WritePnLEntry will write an entry of the profit and loss ledger, replacing
+// the entry of the same category for the same reference
+func WritePnLEntry(db ethdb.KeyValueWriter, entry *fees.PnLEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode profit and loss entry", "err", err)
+	}
+	if err := db.Put(pnlKey(entry.Timestamp, entry.Reference, entry.Category), data); err != nil {
+		log.Crit("Failed to store profit and loss entry", "err", err)
+	}
+}
+
+// ReadPnLEntries will read the entries of the profit and loss ledger between
+// the from and to timestamps, inclusive, in order of time
+func ReadPnLEntries(db ethdb.Iteratee, from, to uint64) []*fees.PnLEntry {
+	it := db.NewIteratorWithStart(append(pnlPrefix, encodeBlockNumber(from)...))
+	defer it.Release()
+
+	var entries []*fees.PnLEntry
+	for it.Next() {
+		key := it.Key()
+		if len(key) < len(pnlPrefix)+8+common.HashLength || !bytes.HasPrefix(key, pnlPrefix) {
+			break
+		}
+		if binary.BigEndian.Uint64(key[len(pnlPrefix):]) > to {
+			break
+		}
+		var entry fees.PnLEntry
+		if err := rlp.DecodeBytes(it.Value(), &entry); err != nil {
+			log.Error("Invalid profit and loss entry", "key", key, "err", err)
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+// ReadTxArrival will read the unix time in milliseconds at which the
+// sequencer received the transaction
+func ReadTxArrival(db ethdb.KeyValueReader, hash common.Hash) *uint64 {
+	data, _ := db.Get(txArrivalKey(hash))
+	if len(data) != 8 {
+		return nil
+	}
+	ret := binary.BigEndian.Uint64(data)
+	return &ret
+}
+
+// WriteTxArrival will write the unix time in milliseconds at which the
+// sequencer received the transaction
+func WriteTxArrival(db ethdb.KeyValueWriter, hash common.Hash, time uint64) {
+	var value [8]byte
+	binary.BigEndian.PutUint64(value[:], time)
+	if err := db.Put(txArrivalKey(hash), value[:]); err != nil {
+		log.Crit("Failed to store transaction arrival", "err", err)
+	}
+}
@@ -1,7 +1,12 @@
 package rawdb
 
 import (
+	"math/big"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 )
 
 func TestReadWriteHeadIndex(t *testing.T) {
@@ -39,3 +44,105 @@ func TestReadWriteHeadQueueIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestReadWriteL1FeeReceipts(t *testing.T) {
+	db := NewMemoryDatabase()
+	hash := common.Hash{1}
+
+	WriteL1FeeReceipts(db, hash, 1, types.Receipts{{}})
+	if ReadL1FeeReceipts(db, hash, 1, types.Receipts{{}}) {
+		t.Fatal("L1 fee receipts written without L1 fees")
+	}
+
+	WriteL1FeeReceipts(db, hash, 1, types.Receipts{{
+		L1GasUsed:  big.NewInt(3000),
+		L1GasPrice: big.NewInt(2),
+		L1Fee:      big.NewInt(6000),
+	}})
+	receipts := types.Receipts{{}}
+	if !ReadL1FeeReceipts(db, hash, 1, receipts) {
+		t.Fatal("L1 fee receipts not found")
+	}
+	if receipts[0].L1Fee.Cmp(big.NewInt(6000)) != 0 || receipts[0].L1GasUsed.Cmp(big.NewInt(3000)) != 0 {
+		t.Fatalf("mismatched L1 fee receipt: got %v/%v, expect 6000/3000", receipts[0].L1Fee, receipts[0].L1GasUsed)
+	}
+	if ReadL1FeeReceipts(db, hash, 1, types.Receipts{{}, {}}) {
+		t.Fatal("L1 fee receipts read into mismatched receipts")
+	}
+
+	DeleteReceipts(db, hash, 1)
+	if ReadL1FeeReceipts(db, hash, 1, types.Receipts{{}}) {
+		t.Fatal("L1 fee receipts not deleted")
+	}
+}
+
+func TestReadWriteBlockFeeParams(t *testing.T) {
+	db := NewMemoryDatabase()
+	hash := common.Hash{1}
+
+	if ReadBlockFeeParams(db, hash, 1) != nil {
+		t.Fatal("block fee params found before they were written")
+	}
+	WriteBlockFeeParams(db, hash, &fees.BlockFeeParams{
+		Number:     1,
+		GPOVersion: 2,
+		GPOOwner:   common.Address{2},
+		L2GasPrice: big.NewInt(1000),
+	})
+	params := ReadBlockFeeParams(db, hash, 1)
+	if params == nil {
+		t.Fatal("block fee params not found")
+	}
+	if params.GPOVersion != 2 || params.GPOOwner != (common.Address{2}) || params.L2GasPrice.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("mismatched block fee params: got %d/%x/%v, expect 2/%x/1000", params.GPOVersion, params.GPOOwner, params.L2GasPrice, common.Address{2})
+	}
+	if ReadBlockFeeParams(db, common.Hash{2}, 1) != nil {
+		t.Fatal("block fee params found for another block")
+	}
+
+	DeleteBlock(db, hash, 1)
+	if ReadBlockFeeParams(db, hash, 1) != nil {
+		t.Fatal("block fee params not deleted")
+	}
+}
+
+func TestReadWritePnLEntries(t *testing.T) {
+	db := NewMemoryDatabase()
+	for i, timestamp := range []uint64{10, 20, 20, 30} {
+		WritePnLEntry(db, &fees.PnLEntry{
+			Timestamp: timestamp,
+			Category:  fees.PnLRevenue,
+			Amount:    big.NewInt(int64(i)),
+			Reference: common.Hash{byte(i)},
+		})
+	}
+	// Entries of the same category for the same reference are replaced
+	WritePnLEntry(db, &fees.PnLEntry{Timestamp: 30, Category: fees.PnLRevenue, Amount: big.NewInt(5), Reference: common.Hash{3}})
+	WriteHeadIndex(db, 1)
+
+	tests := map[string]struct {
+		from, to uint64
+		entries  int
+	}{
+		"all":    {0, 100, 4},
+		"range":  {15, 25, 2},
+		"single": {30, 30, 1},
+		"none":   {31, 100, 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries := ReadPnLEntries(db, tt.from, tt.to)
+			if len(entries) != tt.entries {
+				t.Fatalf("mismatched entries: got %d, expect %d", len(entries), tt.entries)
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].Timestamp < entries[i-1].Timestamp {
+					t.Fatal("entries not ordered by time")
+				}
+			}
+		})
+	}
+	if entries := ReadPnLEntries(db, 30, 30); entries[0].Amount.Int64() != 5 {
+		t.Fatalf("mismatched replaced amount: got %d, expect 5", entries[0].Amount)
+	}
+}
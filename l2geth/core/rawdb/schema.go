@@ -55,6 +55,16 @@ var (
 
 	// Optimism specific
 	txMetaPrefix = []byte("x") // txMetaPrefix + hash -> transaction metadata
+	// blockFeesPrefix + num (uint64 big endian) -> fee components of the block
+	blockFeesPrefix = []byte("f")
+	// l1FeeReceiptsPrefix + num (uint64 big endian) + hash -> L1 fees of the block receipts
+	l1FeeReceiptsPrefix = []byte("F")
+	// blockFeeParamsPrefix + num (uint64 big endian) + hash -> gas price oracle parameters of the block
+	blockFeeParamsPrefix = []byte("g")
+	// pnlPrefix + timestamp (uint64 big endian) + reference + category -> profit and loss entry
+	pnlPrefix = []byte("p")
+	// txArrivalPrefix + hash -> unix time in milliseconds at which the sequencer received the transaction
+	txArrivalPrefix = []byte("a")
 
 	// headIndexKey tracks the last processed ctc index
 	headIndexKey = []byte("LastIndex")
@@ -64,6 +74,12 @@ var (
 	headVerifiedIndexKey = []byte("LastVerifiedIndex")
 	// headBatchKey tracks the latest processed batch
 	headBatchKey = []byte("LastBatch")
+	// receiptHydrationKey tracks the next block whose receipts are hydrated
+	// with the L1 fee
+	receiptHydrationKey = []byte("ReceiptHydration")
+	// pnlL1BlockKey tracks the next L1 block whose spend is added to the
+	// profit and loss ledger
+	pnlL1BlockKey = []byte("PnLL1Block")
 
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
@@ -162,6 +178,32 @@ func txMetaKey(number uint64) []byte {
 	return append(txMetaPrefix, encodeBlockNumber(number)...)
 }
 
+// blockFeesKey = blockFeesPrefix + num (uint64 big endian)
+func blockFeesKey(number uint64) []byte {
+	return append(blockFeesPrefix, encodeBlockNumber(number)...)
+}
+
+// l1FeeReceiptsKey = l1FeeReceiptsPrefix + num (uint64 big endian) + hash
+func l1FeeReceiptsKey(number uint64, hash common.Hash) []byte {
+	return append(append(l1FeeReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// blockFeeParamsKey = blockFeeParamsPrefix + num (uint64 big endian) + hash
+func blockFeeParamsKey(number uint64, hash common.Hash) []byte {
+	return append(append(blockFeeParamsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// pnlKey = pnlPrefix + timestamp (uint64 big endian) + reference + category
+func pnlKey(timestamp uint64, reference common.Hash, category string) []byte {
+	key := append(append(pnlPrefix, encodeBlockNumber(timestamp)...), reference.Bytes()...)
+	return append(key, category...)
+}
+
+// txArrivalKey = txArrivalPrefix + hash
+func txArrivalKey(hash common.Hash) []byte {
+	return append(txArrivalPrefix, hash.Bytes()...)
+}
+
 // bloomBitsKey = bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash
 func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	key := append(append(bloomBitsPrefix, make([]byte, 10)...), hash.Bytes()...)
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feerules"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// L1CalldataGas returns the calldata gas schedule of the L1 chain at the L1
+// block number and the minimum size of calldata, which the L1 fee of
+// transactions is computed with. The parts that apply depend on the formula
+// of the L1 fee that is active at the L1 block number.
+func L1CalldataGas(config *params.ChainConfig, l1Block *big.Int) fees.CalldataGas {
+	return feerules.CalldataGasFor(config, config.FeeAlgorithmAt(l1Block), l1Block)
+}
+
+// BlockL1CalldataGas is L1CalldataGas for a transaction of the L2 block
+// number. Chains that migrated between fee formulas serve the fees of the
+// blocks before the migration with the legacy formula that they were
+// charged with.
+func BlockL1CalldataGas(config *params.ChainConfig, number, l1Block *big.Int) fees.CalldataGas {
+	return feerules.CalldataGasFor(config, config.FeeAlgorithmFor(number, l1Block), l1Block)
+}
+
+// L1FeeAt returns the L1 fee of the calldata of a transaction of the L2
+// block number at the L1 fee params of the gas price oracle in the state,
+// with the calldata priced by the fee schedule of the chain config at the L1
+// block number. It returns rcfg.ErrUnknownLayout when the layout of the
+// oracle does not store the L1 fee params.
+func L1FeeAt(config *params.ChainConfig, number, l1Block *big.Int, data []byte, statedb rcfg.StateReader) (*big.Int, error) {
+	gpo, err := rcfg.ReadL1FeeParams(statedb)
+	if err != nil {
+		return nil, err
+	}
+	calldataGas := BlockL1CalldataGas(config, number, l1Block)
+	_, l1Fee, err := fees.ScaledL1Fee(calldataGas.DataGasOf(data), gpo.Overhead, gpo.L1GasPrice, gpo.Scalar, gpo.Decimals)
+	return l1Fee, err
+}
+
+// feeSubsidyDebit returns the subsidy of the L1 fee that a sequencer
+// transaction to a subsidized contract received, nil when it received none
+// or when the feeSubsidy rule is not active. It must be called before the
+// transaction is applied, as the sequencer decided the fee with the state
+// before it. The subsidy that the balance granted is only debited as far as
+// the L1 fee encoded in the gas limit falls short of the full L1 fee, so that
+// transactions that paid the full fee are not debited and a high gas limit
+// does not inflate the debit.
+func (st *StateTransition) feeSubsidyDebit() *big.Int {
+	msg, statedb := st.msg, st.state
+	if !vm.UsingOVM || st.evm.EthCallSender != nil || !st.evm.RollupRules().FeeSubsidy {
+		return nil
+	}
+	if msg.QueueOrigin() != types.QueueOriginSequencer || msg.To() == nil || msg.GasPrice().Sign() == 0 {
+		return nil
+	}
+	subsidy := rcfg.ReadFeeSubsidy(statedb, *msg.To())
+	if subsidy.Rate == 0 || subsidy.Balance.Sign() == 0 {
+		return nil
+	}
+	slots, err := rcfg.ReadGPOStorageSlots(statedb)
+	if err != nil {
+		return nil
+	}
+	l1Fee, err := L1FeeAt(st.evm.ChainConfig(), st.evm.BlockNumber, msg.L1BlockNumber(), msg.Data(), statedb)
+	if err != nil {
+		return nil
+	}
+	_, granted := fees.SubsidizeL1Fee(l1Fee, subsidy.Rate, subsidy.Balance)
+	debit := new(big.Int).Sub(l1Fee, fees.MaxChargedL1Fee(msg.Gas(), slots.GasPrice))
+	if debit.Sign() <= 0 || granted.Sign() == 0 {
+		return nil
+	}
+	if debit.Cmp(granted) > 0 {
+		debit.Set(granted)
+	}
+	return debit
+}
+
+// debitFeeSubsidy debits the OVM_FeeSubsidyRegistry for the subsidy that a
+// transaction to the contract received, capped at the balance that is left
+// after the transaction
+func debitFeeSubsidy(statedb vm.StateDB, contract common.Address, debit *big.Int) {
+	if balance := rcfg.ReadFeeSubsidy(statedb, contract).Balance; debit.Cmp(balance) > 0 {
+		debit = balance
+	}
+	if debit.Sign() > 0 {
+		rcfg.DebitFeeSubsidy(statedb, contract, debit)
+	}
+}
+
+// gpoL2GasPrice returns the L2 gas price of the gas price oracle in the
+// state, which is zero when the storage layout of the oracle is unknown
+func gpoL2GasPrice(statedb vm.StateDB) *big.Int {
+	slots, err := rcfg.ReadGPOStorageSlots(statedb)
+	if err != nil {
+		return new(big.Int)
+	}
+	return slots.GasPrice
+}
+
+// ReadBlockFeeParams reads the parameters of the gas price oracle in the
+// state before a block, nil when the storage layout of the oracle is unknown.
+// The state must be read before the transactions of the block are applied.
+func ReadBlockFeeParams(statedb vm.StateDB, number uint64) *fees.BlockFeeParams {
+	slots, err := rcfg.ReadGPOStorageSlots(statedb)
+	if err != nil {
+		return nil
+	}
+	return &fees.BlockFeeParams{
+		Number:     number,
+		GPOVersion: slots.Version,
+		GPOOwner:   slots.Owner,
+		L2GasPrice: slots.GasPrice,
+	}
+}
+
+// setL1Fee records the L1 fee of a transaction in its receipt. The fee is
+// recovered at the L2 gas price of the gas price oracle in the state before
+// the transaction, which the fee was checked against. Transactions from L1
+// pay for their gas on L1.
+func setL1Fee(receipt *types.Receipt, config *params.ChainConfig, number *big.Int, tx *types.Transaction, l2GasPrice *big.Int) {
+	l1Fee := fees.NewHistoricalL1Fee(tx.Hash(), 0, tx.Gas(), common.Big0, 0, common.Big0)
+	if tx.QueueOrigin() != types.QueueOriginL1ToL2 {
+		l1GasUsed := tx.L1GasUsedWith(BlockL1CalldataGas(config, number, tx.L1BlockNumber()))
+		l1Fee = fees.NewHistoricalL1Fee(tx.Hash(), 0, tx.Gas(), tx.GasPrice(), l1GasUsed, l2GasPrice)
+	}
+	receipt.L1GasUsed = new(big.Int).SetUint64(uint64(l1Fee.L1GasUsed))
+	receipt.L1GasPrice = l1Fee.L1GasPrice.ToInt()
+	receipt.L1Fee = l1Fee.L1Fee.ToInt()
+}
+
+// HydrateL1FeeReceipts records the L1 fee of the transactions of a block in
+// receipts that were generated before the L1 fee was recorded in receipts.
+// The fee is recovered with the gas price oracle in the state of the parent
+// block, which is the state before the transaction of a rollup block.
+func HydrateL1FeeReceipts(config *params.ChainConfig, block *types.Block, receipts types.Receipts, parent vm.StateDB) {
+	HydrateL1FeeReceiptsAt(config, block, receipts, gpoL2GasPrice(parent))
+}
+
+// HydrateL1FeeReceiptsAt is HydrateL1FeeReceipts at the L2 gas price of the
+// gas price oracle before the block, such as the one of the fee params that
+// were recorded with the block
+func HydrateL1FeeReceiptsAt(config *params.ChainConfig, block *types.Block, receipts types.Receipts, l2GasPrice *big.Int) {
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		setL1Fee(receipts[i], config, block.Number(), tx, l2GasPrice)
+	}
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// TestFeeSubsidyDebit checks that the state transition of a transaction to a
+// subsidized contract debits the subsidy that the transaction received, and
+// leaves the balance of transactions that paid the full L1 fee unchanged
+func TestFeeSubsidyDebit(t *testing.T) {
+	vm.UsingOVM = true
+	defer func() { vm.UsingOVM = false }()
+
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	l1GasPrice, l2GasPrice := big.NewInt(100_000_000_000), big.NewInt(1_000_000_000)
+	l2GasLimit := big.NewInt(100_000)
+	balance := big.NewInt(params.Ether)
+	data := make([]byte, 1000)
+	config := *params.TestChainConfig
+	config.RollupForks = []params.RollupFork{{L1Block: big.NewInt(0), Features: []params.RollupFeature{params.RollupFeatureFeeSubsidy}}}
+
+	// newState returns a state with the gas price oracle at the layout
+	// version, the L1 fee params are only stored by version 1
+	newState := func(version, rate uint64, balance *big.Int) *state.StateDB {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+		layout := rcfg.Layouts[version]
+		statedb.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceOracleVersionSlot, common.BigToHash(new(big.Int).SetUint64(version)))
+		statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.GasPrice, common.BigToHash(l2GasPrice))
+		if layout.L1Fee != nil {
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.L1GasPrice, common.BigToHash(l1GasPrice))
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.Overhead, common.BigToHash(new(big.Int).SetUint64(fees.Overhead)))
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.Scalar, common.BigToHash(big.NewInt(1000)))
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.Decimals, common.BigToHash(big.NewInt(3)))
+		}
+		statedb.SetState(rcfg.L2FeeSubsidyRegistryAddress, rcfg.FeeSubsidyRateSlot(contract), common.BigToHash(new(big.Int).SetUint64(rate)))
+		statedb.SetState(rcfg.L2FeeSubsidyRegistryAddress, rcfg.FeeSubsidyBalanceSlot(contract), common.BigToHash(balance))
+		return statedb
+	}
+	l1Fee, err := L1FeeAt(&config, common.Big1, common.Big1, data, newState(1, 0, balance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, subsidy := fees.SubsidizeL1Fee(l1Fee, 5000, balance)
+
+	tests := map[string]struct {
+		version     uint64
+		rate        uint64
+		balance     *big.Int
+		queueOrigin types.QueueOrigin
+		// The L1 fee that the transaction was charged
+		charged *big.Int
+		debited bool
+	}{
+		"subsidized":    {1, 5000, balance, types.QueueOriginSequencer, new(big.Int).Sub(l1Fee, subsidy), true},
+		"full-fee":      {1, 5000, balance, types.QueueOriginSequencer, l1Fee, false},
+		"overpaid":      {1, 5000, balance, types.QueueOriginSequencer, new(big.Int).Mul(l1Fee, big.NewInt(2)), false},
+		"no-rate":       {1, 0, balance, types.QueueOriginSequencer, new(big.Int).Sub(l1Fee, subsidy), false},
+		"no-balance":    {1, 5000, new(big.Int), types.QueueOriginSequencer, new(big.Int).Sub(l1Fee, subsidy), false},
+		"low-balance":   {1, 5000, new(big.Int).Sub(subsidy, common.Big1), types.QueueOriginSequencer, new(big.Int).Sub(l1Fee, subsidy), false},
+		"l1-to-l2":      {1, 5000, balance, types.QueueOriginL1ToL2, new(big.Int).Sub(l1Fee, subsidy), false},
+		"legacy-layout": {0, 5000, balance, types.QueueOriginSequencer, new(big.Int).Sub(l1Fee, subsidy), false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			statedb := newState(tt.version, tt.rate, tt.balance)
+			gasLimit := fees.EncodeTxGasLimitForL1Fee(tt.charged, l2GasLimit, l2GasPrice)
+			msg := types.NewMessage(common.Address{}, &contract, 0, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, data, false, nil, common.Big1, tt.queueOrigin)
+			evm := vm.NewEVM(vm.Context{BlockNumber: common.Big1, L1BlockNumber: common.Big1}, statedb, &config, vm.Config{})
+			st := NewStateTransition(evm, msg, new(GasPool).AddGas(gasLimit.Uint64()))
+			if debit := st.feeSubsidyDebit(); debit != nil {
+				debitFeeSubsidy(statedb, contract, debit)
+			}
+
+			debit := new(big.Int).Sub(tt.balance, rcfg.ReadFeeSubsidy(statedb, contract).Balance)
+			if !tt.debited {
+				if debit.Sign() != 0 {
+					t.Fatalf("unexpected debit: %d", debit)
+				}
+				return
+			}
+			// The charged L1 fee is recovered from the gas limit up to its
+			// rounding, so the debit is at most the subsidy
+			if debit.Sign() <= 0 || debit.Cmp(subsidy) > 0 {
+				t.Fatalf("mismatched debit: got %d, expect at most %d", debit, subsidy)
+			}
+		})
+	}
+}
+
+func TestL1CalldataGasFeeForks(t *testing.T) {
+	config := &params.ChainConfig{
+		L1CalldataGas: &params.L1CalldataGasConfig{ZeroGas: 2, NonZeroGas: 8, MinTxSize: 100},
+		FeeForks: &params.FeeForksConfig{
+			L1CalldataGasBlock: big.NewInt(100),
+			MinTxSizeBlock:     big.NewInt(200),
+		},
+	}
+	tests := map[string]struct {
+		l1Block *big.Int
+		expect  fees.CalldataGas
+	}{
+		"legacy":          {big.NewInt(99), fees.DefaultCalldataGas},
+		"l1-calldata-gas": {big.NewInt(100), fees.CalldataGas{Zero: 2, NonZero: 8}},
+		"min-tx-size":     {big.NewInt(200), fees.CalldataGas{Zero: 2, NonZero: 8, MinSize: 100}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := L1CalldataGas(config, tt.l1Block); got != tt.expect {
+				t.Fatalf("mismatched calldata gas: got %+v, expect %+v", got, tt.expect)
+			}
+		})
+	}
+
+	// Migrated chains charged the blocks before the migration with the
+	// legacy formula
+	config.FeeMigrationBlock = big.NewInt(10)
+	if got := BlockL1CalldataGas(config, big.NewInt(9), big.NewInt(200)); got != fees.DefaultCalldataGas {
+		t.Fatalf("mismatched calldata gas before migration: got %+v, expect %+v", got, fees.DefaultCalldataGas)
+	}
+	if got, expect := BlockL1CalldataGas(config, big.NewInt(10), big.NewInt(200)), L1CalldataGas(config, big.NewInt(200)); got != expect {
+		t.Fatalf("mismatched calldata gas after migration: got %+v, expect %+v", got, expect)
+	}
+}
+
+func TestBlockFeeParams(t *testing.T) {
+	l2GasPrice := big.NewInt(params.GWei)
+	db := rawdb.NewMemoryDatabase()
+	genesis := &Genesis{
+		Config: params.TestChainConfig,
+		Alloc: GenesisAlloc{
+			rcfg.L2GasPriceOracleAddress: {
+				Balance: new(big.Int),
+				Storage: map[common.Hash]common.Hash{
+					rcfg.L2GasPriceSlot: common.BigToHash(l2GasPrice),
+				},
+			},
+		},
+	}
+	genesis.MustCommit(db)
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	blocks, _ := GenerateChain(params.TestChainConfig, chain.CurrentBlock(), ethash.NewFaker(), db, 2, func(i int, b *BlockGen) {})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		recorded := rawdb.ReadBlockFeeParams(db, block.Hash(), block.NumberU64())
+		if recorded == nil {
+			t.Fatalf("block fee params of block %d not recorded", block.NumberU64())
+		}
+		if recorded.Number != block.NumberU64() || recorded.L2GasPrice.Cmp(l2GasPrice) != 0 {
+			t.Fatalf("mismatched block fee params: got %d/%v, expect %d/%v", recorded.Number, recorded.L2GasPrice, block.NumberU64(), l2GasPrice)
+		}
+	}
+}
@@ -17,6 +17,8 @@
 package core
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -113,6 +115,11 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, statedb, config, cfg)
+	// The L1 fee is recovered with the L2 gas price before the transaction
+	var l2GasPrice *big.Int
+	if vmenv.RollupRules().L1FeeReceipts {
+		l2GasPrice = gpoL2GasPrice(statedb)
+	}
 	// Apply the transaction to the current state (included in the env)
 	_, gas, failed, err := ApplyMessage(vmenv, msg, gp)
 	if err != nil {
@@ -142,6 +149,8 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	receipt.BlockHash = statedb.BlockHash()
 	receipt.BlockNumber = header.Number
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-
+	if l2GasPrice != nil {
+		setL1Fee(receipt, config, header.Number, tx, l2GasPrice)
+	}
 	return receipt, err
 }
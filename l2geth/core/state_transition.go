@@ -18,7 +18,6 @@ package core
 
 import (
 	"errors"
-	"math"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -26,7 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 )
 
 var (
@@ -82,39 +81,12 @@ type Message interface {
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
 func IntrinsicGas(data []byte, contractCreation, isHomestead bool, isEIP2028 bool) (uint64, error) {
-	// Set the starting gas for the raw transaction
-	var gas uint64
-	if contractCreation && isHomestead {
-		gas = params.TxGasContractCreation
-	} else {
-		gas = params.TxGas
+	// The fee package computes the same intrinsic gas for estimators
+	gas, err := fees.ComputeIntrinsicGas(data, contractCreation, isHomestead, isEIP2028)
+	if errors.Is(err, fees.ErrIntrinsicGasOverflow) {
+		return 0, vm.ErrOutOfGas
 	}
-	// Bump the required gas by the amount of transactional data
-	if len(data) > 0 {
-		// Zero and non-zero bytes are priced differently
-		var nz uint64
-		for _, byt := range data {
-			if byt != 0 {
-				nz++
-			}
-		}
-		// Make sure we don't exceed uint64 for all data combinations
-		nonZeroGas := params.TxDataNonZeroGasFrontier
-		if isEIP2028 {
-			nonZeroGas = params.TxDataNonZeroGasEIP2028
-		}
-		if (math.MaxUint64-gas)/nonZeroGas < nz {
-			return 0, vm.ErrOutOfGas
-		}
-		gas += nz * nonZeroGas
-
-		z := uint64(len(data)) - nz
-		if (math.MaxUint64-gas)/params.TxDataZeroGas < z {
-			return 0, vm.ErrOutOfGas
-		}
-		gas += z * params.TxDataZeroGas
-	}
-	return gas, nil
+	return gas, err
 }
 
 // NewStateTransition initialises and returns a new state transition object.
@@ -211,6 +183,12 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	if err = st.preCheck(); err != nil {
 		return
 	}
+	// The transaction as it was sent, before it is wrapped in the call to
+	// the execution manager
+	original := st.msg
+	// The subsidy of the L1 fee is determined by the state before the
+	// transaction, which the sequencer decided the fee with
+	subsidy := st.feeSubsidyDebit()
 
 	if vm.UsingOVM {
 		// When the execution is not an `eth_call`, abi encode the user transaction
@@ -281,6 +259,9 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	}
 	st.refundGas()
 
+	if subsidy != nil {
+		debitFeeSubsidy(st.state, *original.To(), subsidy)
+	}
 	if !vm.UsingOVM {
 		// Do not pay the gas to the coinbase address when running the OVM
 		st.state.AddBalance(evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"os"
 	"sort"
 	"sync"
 	"time"
@@ -34,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
@@ -147,6 +149,7 @@ type TxPoolConfig struct {
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	Persist   string           // File that the pool is persisted to on shutdown and re-admitted from by LoadPersisted or ReplayPersisted
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -301,11 +304,16 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 
-		if err := pool.journal.load(pool.AddLocals); err != nil {
-			log.Warn("Failed to load transaction journal", "err", err)
-		}
-		if err := pool.journal.rotate(pool.local()); err != nil {
-			log.Warn("Failed to rotate transaction journal", "err", err)
+		// A persisted pool is loaded together with the journal by
+		// LoadPersisted or ReplayPersisted, so that the local transactions
+		// are admitted again the same way as the remote ones
+		if config.Persist == "" {
+			if err := pool.journal.load(pool.AddLocals); err != nil {
+				log.Warn("Failed to load transaction journal", "err", err)
+			}
+			if err := pool.journal.rotate(pool.local()); err != nil {
+				log.Warn("Failed to rotate transaction journal", "err", err)
+			}
 		}
 	}
 
@@ -404,9 +412,94 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.config.Persist != "" {
+		if err := pool.persist(); err != nil {
+			log.Warn("Failed to persist transaction pool", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 }
 
+// persist writes the remote transactions of the pool to the persist file,
+// the local transactions are already in the journal
+func (pool *TxPool) persist() error {
+	pool.mu.RLock()
+	txs := make(types.Transactions, 0, pool.all.Count())
+	for addr, list := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs = append(txs, list.Flatten()...)
+		}
+	}
+	for addr, list := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs = append(txs, list.Flatten()...)
+		}
+	}
+	pool.mu.RUnlock()
+
+	// Write to a new file and replace the old one, so that a failure does
+	// not leave a partial file behind
+	path := pool.config.Persist + ".new"
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		if err = rlp.Encode(out, tx); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	out.Close()
+	if err := os.Rename(path, pool.config.Persist); err != nil {
+		return err
+	}
+	log.Info("Persisted transaction pool", "transactions", len(txs))
+	return nil
+}
+
+// LoadPersisted admits the transactions of the pool that was persisted on
+// shutdown and the journaled local transactions to the pool again, dropping
+// the ones that the pool rejects. It must be called once after the pool is
+// created when the pool is persisted. The persist file is removed once it is
+// loaded, so that a crash does not admit the same transactions twice.
+func (pool *TxPool) LoadPersisted() {
+	pool.loadPersisted(pool.AddLocals, pool.AddRemotesSync)
+}
+
+// ReplayPersisted hands the transactions of the pool that was persisted on
+// shutdown and the journaled local transactions to replay in batches,
+// instead of admitting them to the pool. It is used in place of
+// LoadPersisted by the rollup sequencer, which applies transactions itself
+// rather than building blocks from the pool. The transactions that replay
+// returns an error for are dropped.
+func (pool *TxPool) ReplayPersisted(replay func([]*types.Transaction) []error) {
+	pool.loadPersisted(replay, replay)
+}
+
+// loadPersisted loads the journaled local transactions with locals and the
+// persisted pool with remotes, then removes the persist file. Without a
+// persist file the journal has already been loaded by NewTxPool.
+func (pool *TxPool) loadPersisted(locals, remotes func([]*types.Transaction) []error) {
+	if pool.config.Persist == "" {
+		return
+	}
+	if pool.journal != nil {
+		if err := pool.journal.load(locals); err != nil {
+			log.Warn("Failed to load transaction journal", "err", err)
+		}
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate transaction journal", "err", err)
+		}
+	}
+	if err := newTxJournal(pool.config.Persist).load(remotes); err != nil {
+		log.Warn("Failed to load persisted transaction pool", "err", err)
+	}
+	if err := os.Remove(pool.config.Persist); err != nil && !os.IsNotExist(err) {
+		log.Warn("Failed to remove persisted transaction pool", "err", err)
+	}
+}
+
 // SubscribeNewTxsEvent registers a subscription of NewTxsEvent and
 // starts sending event to the given channel.
 func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscription {
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -1762,6 +1763,79 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that the pool is persisted on shutdown and that it is either admitted
+// again or replayed on startup.
+func TestTransactionPersistence(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = filepath.Join(dir, "transactions.rlp")
+	config.Persist = filepath.Join(dir, "pool.rlp")
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	pool.LoadPersisted()
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	for i, price := range []int64{3, 2, 1} {
+		if err := pool.addRemoteSync(pricedTransaction(uint64(i), 100000, big.NewInt(price), key)); err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	pool.Stop()
+
+	// The persisted transactions are admitted to the pool again, and
+	// persisted again on shutdown
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	pool.LoadPersisted()
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("mismatched transactions: have %d/%d, want 3/0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if _, err := os.Stat(config.Persist); !os.IsNotExist(err) {
+		t.Fatalf("persisted pool not removed after loading: %v", err)
+	}
+	pool.Stop()
+
+	// The replayed transactions are handed over in nonce order instead of
+	// being admitted, the rejected ones are dropped
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+	var replayed []uint64
+	pool.ReplayPersisted(func(txs []*types.Transaction) []error {
+		errs := make([]error, len(txs))
+		for i, tx := range txs {
+			if tx.GasPrice().Cmp(big.NewInt(2)) < 0 {
+				errs[i] = ErrUnderpriced
+				continue
+			}
+			replayed = append(replayed, tx.Nonce())
+		}
+		return errs
+	})
+	if len(replayed) != 2 || replayed[0] != 0 || replayed[1] != 1 {
+		t.Fatalf("mismatched replayed nonces: have %v, want [0 1]", replayed)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("mismatched transactions: have %d/%d, want 0/0", pending, queued)
+	}
+	if _, err := os.Stat(config.Persist); !os.IsNotExist(err) {
+		t.Fatalf("persisted pool not removed after replaying: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
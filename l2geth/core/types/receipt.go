@@ -66,6 +66,13 @@ type Receipt struct {
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
 	BlockNumber      *big.Int    `json:"blockNumber,omitempty"`
 	TransactionIndex uint        `json:"transactionIndex"`
+
+	// Rollup fields: These fields record the L1 fee of the transaction once the
+	// rollup fork that adds it to receipts is active. They are stored apart from
+	// the receipt.
+	L1GasUsed  *big.Int `json:"l1GasUsed,omitempty"`
+	L1GasPrice *big.Int `json:"l1GasPrice,omitempty"`
+	L1Fee      *big.Int `json:"l1Fee,omitempty"`
 }
 
 type receiptMarshaling struct {
@@ -75,6 +82,9 @@ type receiptMarshaling struct {
 	GasUsed           hexutil.Uint64
 	BlockNumber       *hexutil.Big
 	TransactionIndex  hexutil.Uint
+	L1GasUsed         *hexutil.Big
+	L1GasPrice        *hexutil.Big
+	L1Fee             *hexutil.Big
 }
 
 // receiptRLP is the consensus encoding of a receipt.
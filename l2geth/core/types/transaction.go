@@ -40,9 +40,47 @@ type Transaction struct {
 	data txdata
 	meta TransactionMeta
 	// caches
-	hash atomic.Value
-	size atomic.Value
-	from atomic.Value
+	hash    atomic.Value
+	size    atomic.Value
+	from    atomic.Value
+	counts  atomic.Value
+	encoded atomic.Value
+}
+
+// dataCounts are the number of zero and non-zero bytes of the calldata of a
+// transaction, they are cached as they are counted whenever the fee of the
+// transaction is checked or recorded
+type dataCounts struct {
+	zeroes, nonZeroes uint64
+}
+
+// RLPCounts are the size of an RLP encoding and its number of zero and
+// non-zero bytes. It is an io.Writer that counts the bytes of an encoding as
+// it is written, so that an encoding can be measured without allocating it.
+type RLPCounts struct {
+	Size      uint64
+	Zeroes    uint64
+	NonZeroes uint64
+}
+
+// Write implements io.Writer
+func (c *RLPCounts) Write(b []byte) (int, error) {
+	for _, byt := range b {
+		if byt == 0 {
+			c.Zeroes++
+		} else {
+			c.NonZeroes++
+		}
+	}
+	c.Size += uint64(len(b))
+	return len(b), nil
+}
+
+// CountRLP returns the counts of the RLP encoding of a value
+func CountRLP(val interface{}) (RLPCounts, error) {
+	var c RLPCounts
+	err := rlp.Encode(&c, val)
+	return c, err
 }
 
 type txdata struct {
@@ -222,7 +260,22 @@ func (tx *Transaction) Value() *big.Int    { return new(big.Int).Set(tx.data.Amo
 func (tx *Transaction) Nonce() uint64      { return tx.data.AccountNonce }
 func (tx *Transaction) CheckNonce() bool   { return true }
 
-func (tx *Transaction) SetNonce(nonce uint64) { tx.data.AccountNonce = nonce }
+// SetNonce sets the nonce of the transaction, which changes its encoding, so
+// the cached hash, size and sender are dropped
+func (tx *Transaction) SetNonce(nonce uint64) {
+	tx.data.AccountNonce = nonce
+	tx.invalidate()
+}
+
+// invalidate drops the caches that depend on the encoding of the transaction.
+// The calldata of a transaction cannot be modified, so its byte counts are
+// kept.
+func (tx *Transaction) invalidate() {
+	tx.hash = atomic.Value{}
+	tx.size = atomic.Value{}
+	tx.from = atomic.Value{}
+	tx.encoded = atomic.Value{}
+}
 
 // To returns the recipient address of the transaction.
 // It returns nil if the transaction is a contract creation.
@@ -277,10 +330,53 @@ func (tx *Transaction) Size() common.StorageSize {
 	if size := tx.size.Load(); size != nil {
 		return size.(common.StorageSize)
 	}
-	c := writeCounter(0)
-	rlp.Encode(&c, &tx.data)
-	tx.size.Store(common.StorageSize(c))
-	return common.StorageSize(c)
+	return common.StorageSize(tx.EncodedCounts().Size)
+}
+
+// EncodedCounts returns the size of the RLP encoding of the transaction and
+// its number of zero and non-zero bytes, either by counting them while the
+// transaction is encoded or returning previously cached values. The encoding
+// is not kept, so that large transactions are measured without allocating
+// it.
+func (tx *Transaction) EncodedCounts() RLPCounts {
+	if counts := tx.encoded.Load(); counts != nil {
+		return counts.(RLPCounts)
+	}
+	c, _ := CountRLP(&tx.data)
+	tx.encoded.Store(c)
+	tx.size.Store(common.StorageSize(c.Size))
+	return c
+}
+
+// DataCounts returns the number of zero and non-zero bytes of the calldata,
+// either by counting them or returning previously cached values.
+func (tx *Transaction) DataCounts() (uint64, uint64) {
+	if counts := tx.counts.Load(); counts != nil {
+		c := counts.(dataCounts)
+		return c.zeroes, c.nonZeroes
+	}
+	var c dataCounts
+	for _, b := range tx.data.Payload {
+		if b == 0 {
+			c.zeroes++
+		} else {
+			c.nonZeroes++
+		}
+	}
+	tx.counts.Store(c)
+	return c.zeroes, c.nonZeroes
+}
+
+// L1GasUsed returns the L1 gas that the transaction is charged for, see
+// fees.CalculateL1GasUsed
+func (tx *Transaction) L1GasUsed() uint64 {
+	return fees.CalldataL1GasUsed(tx.DataCounts())
+}
+
+// L1GasUsedWith returns the L1 gas that the transaction is charged for with
+// the calldata gas schedule of the L1 chain
+func (tx *Transaction) L1GasUsedWith(gas fees.CalldataGas) uint64 {
+	return gas.L1GasUsed(tx.DataCounts())
 }
 
 // AsMessage returns the transaction as a core.Message.
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 )
 
 // The values in those tests are from the Transaction Tests
@@ -264,3 +265,104 @@ func TestOVMMetaDataHash(t *testing.T) {
 		t.Errorf("L1MessageSender, should not affect the hash, want %x, got %x with L1MessageSender", emptyTx.Hash(), emptyTxEmptyL1Sender.Hash())
 	}
 }
+
+func TestTransactionDataCounts(t *testing.T) {
+	tests := map[string]struct {
+		data      []byte
+		zeroes    uint64
+		nonZeroes uint64
+	}{
+		"empty":  {nil, 0, 0},
+		"zeroes": {[]byte{0, 0, 0}, 3, 0},
+		"mixed":  {[]byte{0, 1, 0, 2, 3}, 2, 3},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := NewTransaction(0, common.Address{}, big.NewInt(0), 0, big.NewInt(0), tt.data)
+			for i := 0; i < 2; i++ {
+				zeroes, nonZeroes := tx.DataCounts()
+				if zeroes != tt.zeroes || nonZeroes != tt.nonZeroes {
+					t.Fatalf("mismatched counts: got %d/%d, expect %d/%d", zeroes, nonZeroes, tt.zeroes, tt.nonZeroes)
+				}
+			}
+			if l1GasUsed := tx.L1GasUsed(); l1GasUsed != fees.CalculateL1GasUsed(tt.data).Uint64() {
+				t.Fatalf("mismatched l1 gas used: got %d, expect %d", l1GasUsed, fees.CalculateL1GasUsed(tt.data))
+			}
+		})
+	}
+}
+
+func TestTransactionSetNonceInvalidates(t *testing.T) {
+	tx := NewTransaction(0, common.Address{}, big.NewInt(0), 0, big.NewInt(0), []byte{1, 2})
+	hash, size := tx.Hash(), tx.Size()
+	tx.SetNonce(1000)
+	if tx.Hash() == hash {
+		t.Fatal("hash not invalidated by SetNonce")
+	}
+	if tx.Size() == size {
+		t.Fatal("size not invalidated by SetNonce")
+	}
+	if expect := NewTransaction(1000, common.Address{}, big.NewInt(0), 0, big.NewInt(0), []byte{1, 2}).Hash(); tx.Hash() != expect {
+		t.Fatalf("mismatched hash: got %x, expect %x", tx.Hash(), expect)
+	}
+}
+
+func TestTransactionEncodedCounts(t *testing.T) {
+	deploy := make([]byte, 24576)
+	for i := 0; i < len(deploy); i += 3 {
+		deploy[i] = 0x60
+	}
+	tests := map[string]*Transaction{
+		"empty":  NewTransaction(0, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil),
+		"call":   NewTransaction(1, common.Address{1}, big.NewInt(10), 21000, big.NewInt(1), []byte{0, 1, 0, 2}),
+		"deploy": NewContractCreation(2, big.NewInt(0), 8_000_000, big.NewInt(1), deploy),
+	}
+	for name, tx := range tests {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := rlp.EncodeToBytes(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expect RLPCounts
+			for _, b := range encoded {
+				if b == 0 {
+					expect.Zeroes++
+				} else {
+					expect.NonZeroes++
+				}
+			}
+			expect.Size = uint64(len(encoded))
+			if counts := tx.EncodedCounts(); counts != expect {
+				t.Fatalf("mismatched counts: got %+v, expect %+v", counts, expect)
+			}
+			if size := uint64(tx.Size()); size != expect.Size {
+				t.Fatalf("mismatched size: got %d, expect %d", size, expect.Size)
+			}
+		})
+	}
+	// The counts change with the encoding
+	tx := tests["call"]
+	counts := tx.EncodedCounts()
+	tx.SetNonce(1 << 40)
+	if tx.EncodedCounts() == counts {
+		t.Fatal("counts not invalidated by SetNonce")
+	}
+}
+
+func BenchmarkTransactionEncodedCounts(b *testing.B) {
+	tx := NewContractCreation(0, big.NewInt(0), 8_000_000, big.NewInt(1), make([]byte, 24576))
+	b.Run("counting", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CountRLP(tx)
+		}
+	})
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encoded, _ := rlp.EncodeToBytes(tx)
+			var c RLPCounts
+			c.Write(encoded)
+		}
+	})
+}
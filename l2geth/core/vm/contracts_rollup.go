@@ -0,0 +1,48 @@
+package vm
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// L1FeePrecompileAddress is the address of the precompile that returns the L1
+// fee of a byte payload, active with the l1FeePrecompile rollup feature
+var L1FeePrecompileAddress = common.HexToAddress("0x4200000000000000000000000000000000000100")
+
+// l1FeeSlotReads is the number of storage slots of the OVM_GasPriceOracle
+// that the L1 fee precompile reads, the layout version and the L1 fee params
+const l1FeeSlotReads = 5
+
+// l1Fee implements a native L1 fee calculation with the calldata gas
+// schedule of the L1 block of the message, so that contracts price data with
+// the same formula as the node. The L1 gas price, the overhead and the scalar
+// are read from the OVM_GasPriceOracle in the state, the input is the
+// payload. The L1 gas used and the L1 fee of the payload are returned as two
+// words, the fee wraps around like the arithmetic of the EVM.
+type l1Fee struct {
+	gas   fees.CalldataGas
+	state rcfg.StateReader
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+//
+// This method does not require any overflow checking as the input size gas costs
+// required for anything significant is so high it's impossible to pay for.
+func (c *l1Fee) RequiredGas(input []byte) uint64 {
+	return uint64(len(input)+31)/32*params.L1FeePerWordGas + params.L1FeeBaseGas + l1FeeSlotReads*params.SloadGasEIP1884
+}
+
+func (c *l1Fee) Run(input []byte) ([]byte, error) {
+	gpo, err := rcfg.ReadL1FeeParams(c.state)
+	if err != nil {
+		return nil, err
+	}
+	l1GasUsed, l1Fee, err := fees.ScaledL1Fee(c.gas.DataGasOf(input), gpo.Overhead, gpo.L1GasPrice, gpo.Scalar, gpo.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	return append(math.PaddedBigBytes(math.U256(l1GasUsed), 32), math.PaddedBigBytes(math.U256(l1Fee), 32)...), nil
+}
@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// gpoState is the storage of the OVM_GasPriceOracle
+type gpoState map[common.Hash]common.Hash
+
+func (s gpoState) GetState(addr common.Address, key common.Hash) common.Hash {
+	if addr != rcfg.L2GasPriceOracleAddress {
+		return common.Hash{}
+	}
+	return s[key]
+}
+
+func TestL1FeePrecompile(t *testing.T) {
+	gas := fees.CalldataGas{Zero: 4, NonZero: 16, MinSize: 8}
+	layout := rcfg.Layouts[1].L1Fee
+	state := gpoState{
+		rcfg.L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(1)),
+		layout.L1GasPrice:                common.BigToHash(big.NewInt(3)),
+		layout.Overhead:                  common.BigToHash(big.NewInt(2000)),
+		// A scalar of 1.5
+		layout.Scalar:   common.BigToHash(big.NewInt(1500)),
+		layout.Decimals: common.BigToHash(big.NewInt(3)),
+	}
+	tests := map[string]struct {
+		input     []byte
+		state     gpoState
+		l1GasUsed uint64
+		l1Fee     *big.Int
+		err       error
+	}{
+		"empty": {
+			input:     nil,
+			state:     state,
+			l1GasUsed: gas.DataGas(0, 0) + 2000,
+			l1Fee:     new(big.Int).SetUint64((gas.DataGas(0, 0) + 2000) * 3 * 3 / 2),
+		},
+		"min-size": {
+			input:     []byte{0x00, 0x01},
+			state:     state,
+			l1GasUsed: gas.DataGas(1, 1) + 2000,
+			l1Fee:     new(big.Int).SetUint64((gas.DataGas(1, 1) + 2000) * 3 * 3 / 2),
+		},
+		"payload": {
+			input:     make([]byte, 16),
+			state:     state,
+			l1GasUsed: gas.DataGas(16, 0) + 2000,
+			l1Fee:     new(big.Int).SetUint64((gas.DataGas(16, 0) + 2000) * 3 * 3 / 2),
+		},
+		"no-l1-fee-layout": {
+			input: make([]byte, 16),
+			state: gpoState{},
+			err:   rcfg.ErrUnknownLayout,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := (&l1Fee{gas: gas, state: tt.state}).Run(tt.input)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if len(out) != 64 {
+				t.Fatalf("mismatched output size: got %d, expect 64", len(out))
+			}
+			if l1GasUsed := new(big.Int).SetBytes(out[:32]).Uint64(); l1GasUsed != tt.l1GasUsed {
+				t.Fatalf("mismatched L1 gas used: got %d, expect %d", l1GasUsed, tt.l1GasUsed)
+			}
+			if l1Fee := new(big.Int).SetBytes(out[32:]); l1Fee.Cmp(tt.l1Fee) != 0 {
+				t.Fatalf("mismatched L1 fee: got %d, expect %d", l1Fee, tt.l1Fee)
+			}
+		})
+	}
+}
+
+func TestL1FeePrecompileFork(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:       big.NewInt(420),
+		IstanbulBlock: big.NewInt(0),
+		RollupForks: []params.RollupFork{
+			{Name: "precompile", L1Block: big.NewInt(100), Features: []params.RollupFeature{params.RollupFeatureL1FeePrecompile}},
+		},
+	}
+	tests := map[string]struct {
+		l1Block *big.Int
+		active  bool
+	}{
+		"before-fork": {big.NewInt(99), false},
+		"fork":        {big.NewInt(100), true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evm := NewEVM(Context{BlockNumber: new(big.Int), L1BlockNumber: tt.l1Block}, nil, config, Config{})
+			if active := evm.precompile(L1FeePrecompileAddress) != nil; active != tt.active {
+				t.Fatalf("mismatched precompile: got %t, expect %t", active, tt.active)
+			}
+		})
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rollup/dump"
+	"github.com/ethereum/go-ethereum/rollup/fees/feerules"
 )
 
 // codec is a decoder for the return values of the execution manager. It decodes
@@ -112,6 +113,25 @@ type (
 	GetHashFunc func(uint64) common.Hash
 )
 
+// precompile returns the precompiled contract at the address under the rules
+// of the chain, nil when there is none. The L1 fee precompile prices data
+// with the formula of the L1 fee at the L1 block number of the message and
+// the gas price oracle in the state.
+func (evm *EVM) precompile(addr common.Address) PrecompiledContract {
+	if addr == L1FeePrecompileAddress && evm.rollupRules.L1FeePrecompile {
+		algorithm := evm.chainConfig.FeeAlgorithmAt(evm.Context.L1BlockNumber)
+		return &l1Fee{gas: feerules.CalldataGasFor(evm.chainConfig, algorithm, evm.Context.L1BlockNumber), state: evm.StateDB}
+	}
+	precompiles := PrecompiledContractsHomestead
+	if evm.chainRules.IsByzantium {
+		precompiles = PrecompiledContractsByzantium
+	}
+	if evm.chainRules.IsIstanbul {
+		precompiles = PrecompiledContractsIstanbul
+	}
+	return precompiles[addr]
+}
+
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	if UsingOVM {
@@ -138,14 +158,7 @@ func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, err
 	}
 
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
-		if evm.chainRules.IsByzantium {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if evm.chainRules.IsIstanbul {
-			precompiles = PrecompiledContractsIstanbul
-		}
-		if p := precompiles[*contract.CodeAddr]; p != nil {
+		if p := evm.precompile(*contract.CodeAddr); p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
 	}
@@ -190,6 +203,7 @@ type Context struct {
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
 
 	// OVM_ADDITION
+	L1BlockNumber             *big.Int // L1 block number of the message, which the rollup rules depend on
 	EthCallSender             *common.Address
 	IsL1ToL2Message           bool
 	IsSuccessfulL1ToL2Message bool
@@ -222,6 +236,9 @@ type EVM struct {
 	chainConfig *params.ChainConfig
 	// chain rules contains the chain rules for the current epoch
 	chainRules params.Rules
+	// rollup rules contains the rollup features that are active at the L1
+	// block number of the message
+	rollupRules params.RollupRules
 	// virtual machine configuration options used to initialise the
 	// evm.
 	vmConfig Config
@@ -258,6 +275,7 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 		vmConfig:     vmConfig,
 		chainConfig:  chainConfig,
 		chainRules:   chainConfig.Rules(ctx.BlockNumber),
+		rollupRules:  chainConfig.RollupRules(ctx.L1BlockNumber),
 		interpreters: make([]Interpreter, 0, 1),
 	}
 
@@ -342,14 +360,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	)
 
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
-		if evm.chainRules.IsByzantium {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if evm.chainRules.IsIstanbul {
-			precompiles = PrecompiledContractsIstanbul
-		}
-		if precompiles[addr] == nil && evm.chainRules.IsEIP158 && value.Sign() == 0 {
+		if evm.precompile(addr) == nil && evm.chainRules.IsEIP158 && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
@@ -727,6 +738,9 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
+// RollupRules returns the rollup features that are active for the message.
+func (evm *EVM) RollupRules() params.RollupRules { return evm.rollupRules }
+
 // OvmADDRESS will be set by the execution manager to the target address whenever it's
 // about to create a new contract. This value is currently stored at the [15] storage slot.
 // Can pull this specific storage slot to get the address that the execution manager is
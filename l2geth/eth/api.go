@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -264,6 +265,41 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// errPnLDisabled is returned by the profit and loss methods when the node
+// keeps no profit and loss ledger
+var errPnLDisabled = errors.New("profit and loss ledger disabled, enable it with --rollup.pnl")
+
+// RollupPnL returns the summary of the profit and loss ledger of the
+// sequencer between the from and to unix timestamps
+func (api *PrivateAdminAPI) RollupPnL(from, to hexutil.Uint64) (*fees.PnLSummary, error) {
+	summary, _ := api.eth.syncService.PnL(uint64(from), uint64(to))
+	if summary == nil {
+		return nil, errPnLDisabled
+	}
+	return summary, nil
+}
+
+// ExportRollupPnL exports the entries of the profit and loss ledger of the
+// sequencer between the from and to unix timestamps into a local CSV file
+func (api *PrivateAdminAPI) ExportRollupPnL(file string, from, to hexutil.Uint64) (bool, error) {
+	summary, entries := api.eth.syncService.PnL(uint64(from), uint64(to))
+	if summary == nil {
+		return false, errPnLDisabled
+	}
+	if _, err := os.Stat(file); err == nil {
+		return false, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return false, err
+	}
+	defer out.Close()
+	if err := fees.WritePnLCSV(out, entries); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // PublicDebugAPI is the collection of Ethereum full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -37,6 +37,8 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -45,7 +47,8 @@ type EthAPIBackend struct {
 	extRPCEnabled   bool
 	eth             *Ethereum
 	gpo             *gasprice.Oracle
-	rollupGpo       *gasprice.RollupOracle
+	rollupGpo       gasprice.RollupGasPriceOracle
+	feeSnapshots    *gasprice.FeeSnapshotter
 	verifier        bool
 	gasLimit        uint64
 	UsingOVM        bool
@@ -314,7 +317,7 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 				return fmt.Errorf("Calldata cannot be larger than %d, sent %d", b.MaxCallDataSize, len(signedTx.Data()))
 			}
 		}
-		return b.eth.syncService.ValidateAndApplySequencerTransaction(signedTx)
+		return b.eth.syncService.ValidateAndApplySequencerTransaction(ctx, signedTx)
 	}
 	// OVM Disabled
 	return b.eth.txPool.AddLocal(signedTx)
@@ -377,6 +380,84 @@ func (b *EthAPIBackend) SuggestL2GasPrice(ctx context.Context) (*big.Int, error)
 	return b.rollupGpo.SuggestL2GasPrice(ctx)
 }
 
+func (b *EthAPIBackend) FeeSnapshot() *fees.OracleSnapshot {
+	return b.feeSnapshots.Snapshot()
+}
+
+func (b *EthAPIBackend) PoolTxFee(tx *types.Transaction) *fees.PoolTxFee {
+	if !b.UsingOVM || b.verifier {
+		return nil
+	}
+	return b.eth.syncService.PoolTxFee(tx)
+}
+
+func (b *EthAPIBackend) EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, snapshot *fees.OracleSnapshot) (*fees.TxFeeEstimate, error) {
+	if !b.UsingOVM {
+		return fees.EstimateTxFee(ctx, fees.CalldataDACost{}, snapshot, tx.Data(), l2GasLimit)
+	}
+	return b.eth.syncService.EstimateTxFee(ctx, tx, l2GasLimit, snapshot)
+}
+
+func (b *EthAPIBackend) GasToken() *fees.GasToken {
+	return b.rollupGpo.GasToken()
+}
+
+func (b *EthAPIBackend) ReceiptProfile(namespace string) *fees.ReceiptProfile {
+	return b.eth.config.Rollup.ReceiptProfiles.Profile(namespace)
+}
+
+func (b *EthAPIBackend) L1GasPriceHistory() []fees.L1GasPriceSample {
+	return b.rollupGpo.L1GasPriceHistory()
+}
+
+// ConversionRateOracle returns the oracle of the conversion feed, falling
+// back to the conversion rate of the gas token on chains with a gas token
+func (b *EthAPIBackend) ConversionRateOracle() fees.ConversionRateOracle {
+	if oracle := b.eth.syncService.ConversionRateOracle(); oracle != nil {
+		return oracle
+	}
+	if token := b.GasToken(); !token.IsETH() {
+		return &fees.StaticConversionRate{Currency: token}
+	}
+	return nil
+}
+
+func (b *EthAPIBackend) FeeReconciliation(count int) []*fees.Reconciliation {
+	return b.eth.syncService.FeeReconciliation(count)
+}
+
+func (b *EthAPIBackend) IssueFeeQuote(ctx context.Context, sender common.Address) (*feesig.FeeQuote, error) {
+	return b.eth.syncService.IssueFeeQuote(ctx, sender)
+}
+
+func (b *EthAPIBackend) FeeQuoteConsumption(hash common.Hash) *feesig.FeeQuoteConsumption {
+	return b.eth.syncService.FeeQuoteConsumption(hash)
+}
+
+func (b *EthAPIBackend) InclusionHint(ctx context.Context, blocks, seconds uint64) (*fees.InclusionHint, error) {
+	return b.eth.syncService.InclusionHint(ctx, blocks, seconds)
+}
+
+func (b *EthAPIBackend) InclusionHintAccuracy() *fees.InclusionAccuracy {
+	return b.eth.syncService.InclusionHintAccuracy()
+}
+
+func (b *EthAPIBackend) FeeThresholdControl() *fees.ThresholdControl {
+	return b.eth.syncService.FeeThresholdControl()
+}
+
+func (b *EthAPIBackend) SubscribeAdmissionStats(ch chan<- *fees.AdmissionStats) event.Subscription {
+	return b.eth.syncService.SubscribeAdmissionStats(ch)
+}
+
+func (b *EthAPIBackend) ReplayFees(ctx context.Context, number uint64) (*feesig.FeeAttestation, error) {
+	return b.eth.syncService.ReplayFees(ctx, number)
+}
+
+func (b *EthAPIBackend) HistoricalL1Fee(ctx context.Context, hash common.Hash) (*fees.HistoricalL1Fee, error) {
+	return b.eth.syncService.HistoricalL1Fee(ctx, hash)
+}
+
 func (b *EthAPIBackend) SetL1GasPrice(ctx context.Context, gasPrice *big.Int) error {
 	return b.rollupGpo.SetL1GasPrice(gasPrice)
 }
@@ -20,9 +20,11 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"runtime"
 	"sync"
@@ -39,6 +41,8 @@ import (
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -703,7 +707,7 @@ func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*
 // and returns them as a JSON object.
 func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (interface{}, error) {
 	// Retrieve the transaction and assemble its EVM context
-	tx, blockHash, _, index := rawdb.ReadTransaction(api.eth.ChainDb(), hash)
+	tx, blockHash, number, index := rawdb.ReadTransaction(api.eth.ChainDb(), hash)
 	if tx == nil {
 		return nil, fmt.Errorf("transaction %#x not found", hash)
 	}
@@ -715,8 +719,66 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if err != nil {
 		return nil, err
 	}
-	// Trace the transaction and return
-	return api.traceTx(ctx, msg, vmctx, statedb, config)
+	// The rollup fee is computed from the state before the transaction
+	rollup := api.rollupTrace(tx, blockHash, number, statedb)
+	result, err := api.traceTx(ctx, msg, vmctx, statedb, config)
+	if err != nil || rollup == nil {
+		return result, err
+	}
+	// Add the rollup fee to the results of the tracers that return objects
+	switch result := result.(type) {
+	case *ethapi.ExecutionResult:
+		result.Rollup = rollup
+	case json.RawMessage:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(result, &fields); err != nil || fields == nil {
+			return result, nil
+		}
+		if _, ok := fields["rollup"]; ok {
+			return result, nil
+		}
+		if fields["rollup"], err = json.Marshal(rollup); err != nil {
+			return nil, err
+		}
+		merged, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(merged), nil
+	}
+	return result, nil
+}
+
+// rollupTrace returns the rollup fee of a transaction with the gas price
+// oracle of the fee params recorded with its block, or else in the state that
+// it is executed on, nil when the gas price oracle cannot be read
+func (api *PrivateDebugAPI) rollupTrace(tx *types.Transaction, blockHash common.Hash, number uint64, statedb *state.StateDB) *ethapi.RollupTraceResult {
+	var slots *rcfg.GPOStorageSlots
+	if params := rawdb.ReadBlockFeeParams(api.eth.ChainDb(), blockHash, number); params != nil {
+		slots = &rcfg.GPOStorageSlots{Version: params.GPOVersion, Owner: params.GPOOwner, GasPrice: params.L2GasPrice}
+	} else {
+		var err error
+		if slots, err = rcfg.ReadGPOStorageSlots(statedb); err != nil {
+			log.Debug("Cannot read gas price oracle for trace", "hash", tx.Hash(), "err", err)
+			return nil
+		}
+	}
+	calldataGas := core.BlockL1CalldataGas(api.eth.blockchain.Config(), new(big.Int).SetUint64(number), tx.L1BlockNumber())
+	result := &ethapi.RollupTraceResult{
+		L2GasLimit:  hexutil.Uint64(fees.DecodeL2GasLimitU64(tx.Gas())),
+		L2GasPrice:  (*hexutil.Big)(slots.GasPrice),
+		GPOVersion:  hexutil.Uint64(slots.Version),
+		CalldataGas: calldataGas,
+	}
+	// Transactions from L1 pay for their gas on L1
+	gasPrice, l1GasUsed := tx.GasPrice(), tx.L1GasUsedWith(calldataGas)
+	if tx.QueueOrigin() == types.QueueOriginL1ToL2 {
+		gasPrice, l1GasUsed = common.Big0, 0
+		result.L2GasLimit = hexutil.Uint64(tx.Gas())
+	}
+	fee := fees.NewHistoricalL1Fee(tx.Hash(), 0, tx.Gas(), gasPrice, l1GasUsed, slots.GasPrice)
+	result.L1GasUsed, result.L1Fee, result.L1GasPrice = fee.L1GasUsed, fee.L1Fee, fee.L1GasPrice
+	return result
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
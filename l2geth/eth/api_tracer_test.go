@@ -0,0 +1,135 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// newTraceTestAPI returns a debug API over a chain with a transaction in its
+// first block, with the gas price oracle storage of the genesis
+func newTraceTestAPI(t *testing.T, gpoStorage map[common.Hash]common.Hash) (*PrivateDebugAPI, *types.Transaction) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	db := rawdb.NewMemoryDatabase()
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			from:                         {Balance: big.NewInt(params.Ether)},
+			rcfg.L2GasPriceOracleAddress: {Balance: new(big.Int), Storage: gpoStorage},
+		},
+	}
+	genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(chain.Stop)
+
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainID)
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{1}, big.NewInt(1), 1_000_000, big.NewInt(params.GWei), []byte{0x00, 0x01}), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, _ := core.GenerateChain(params.TestChainConfig, chain.CurrentBlock(), ethash.NewFaker(), db, 1, func(i int, b *core.BlockGen) {
+		b.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	return NewPrivateDebugAPI(&Ethereum{blockchain: chain, chainDb: db}), tx
+}
+
+func TestTraceTransactionRollup(t *testing.T) {
+	l2GasPrice := big.NewInt(params.GWei)
+	api, tx := newTraceTestAPI(t, map[common.Hash]common.Hash{
+		rcfg.L2GasPriceSlot: common.BigToHash(l2GasPrice),
+	})
+	calldataGas := core.BlockL1CalldataGas(params.TestChainConfig, common.Big1, tx.L1BlockNumber())
+	fee := fees.NewHistoricalL1Fee(tx.Hash(), 0, tx.Gas(), tx.GasPrice(), tx.L1GasUsedWith(calldataGas), l2GasPrice)
+	expect := &ethapi.RollupTraceResult{
+		L1GasUsed:   fee.L1GasUsed,
+		L1Fee:       fee.L1Fee,
+		L1GasPrice:  fee.L1GasPrice,
+		L2GasLimit:  hexutil.Uint64(fees.DecodeL2GasLimitU64(tx.Gas())),
+		L2GasPrice:  (*hexutil.Big)(l2GasPrice),
+		CalldataGas: calldataGas,
+	}
+	expectJSON, _ := json.Marshal(expect)
+
+	// The struct logger result carries the rollup fee
+	result, err := api.TraceTransaction(context.Background(), tx.Hash(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execution, ok := result.(*ethapi.ExecutionResult)
+	if !ok {
+		t.Fatalf("mismatched result type: %T", result)
+	}
+	if got, _ := json.Marshal(execution.Rollup); string(got) != string(expectJSON) {
+		t.Fatalf("mismatched rollup fee: got %s, expect %s", got, expectJSON)
+	}
+
+	tests := map[string]struct {
+		tracer string
+		expect string
+	}{
+		"object":  {`{step: function() {}, fault: function() {}, result: function() { return {calls: 1}; }}`, `{"calls":1,"rollup":` + string(expectJSON) + `}`},
+		"rollup":  {`{step: function() {}, fault: function() {}, result: function() { return {rollup: 1}; }}`, `{"rollup":1}`},
+		"number":  {`{step: function() {}, fault: function() {}, result: function() { return 1; }}`, `1`},
+		"array":   {`{step: function() {}, fault: function() {}, result: function() { return [1]; }}`, `[1]`},
+		"nothing": {`{step: function() {}, fault: function() {}, result: function() { return null; }}`, `null`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tracer := tt.tracer
+			result, err := api.TraceTransaction(context.Background(), tx.Hash(), &TraceConfig{Tracer: &tracer})
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, ok := result.(json.RawMessage)
+			if !ok {
+				t.Fatalf("mismatched result type: %T", result)
+			}
+			if string(raw) != tt.expect {
+				t.Fatalf("mismatched result: got %s, expect %s", raw, tt.expect)
+			}
+		})
+	}
+}
+
+func TestTraceTransactionRollupErrors(t *testing.T) {
+	// The rollup fee is left out when the gas price oracle cannot be read
+	api, tx := newTraceTestAPI(t, map[common.Hash]common.Hash{
+		rcfg.L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(99)),
+	})
+	result, err := api.TraceTransaction(context.Background(), tx.Hash(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rollup := result.(*ethapi.ExecutionResult).Rollup; rollup != nil {
+		t.Fatalf("unexpected rollup fee: %+v", rollup)
+	}
+
+	if _, err := api.TraceTransaction(context.Background(), common.Hash{1}, nil); err == nil {
+		t.Fatal("expected error for unknown transaction")
+	}
+	tracer := "invalid"
+	if _, err := api.TraceTransaction(context.Background(), tx.Hash(), &TraceConfig{Tracer: &tracer}); err == nil {
+		t.Fatal("expected error for invalid tracer")
+	}
+}
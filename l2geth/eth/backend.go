@@ -27,6 +27,7 @@ import (
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -144,6 +145,11 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		return nil, genesisErr
 	}
 	log.Info("Initialised chain configuration", "config", chainConfig)
+	rcfg.Configure(chainConfig)
+	if chainConfig.GasPriceOracle != nil {
+		log.Info("Configured gas price oracle", "address", rcfg.L2GasPriceOracleAddress.Hex(),
+			"owner-slot", rcfg.L2GasPriceOracleOwnerSlot.Hex(), "gas-price-slot", rcfg.L2GasPriceSlot.Hex())
+	}
 
 	eth := &Ethereum{
 		config:         config,
@@ -204,6 +210,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Persist != "" {
+		config.TxPool.Persist = ctx.ResolvePath(config.TxPool.Persist)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	eth.syncService, err = rollup.NewSyncService(context.Background(), config.Rollup, eth.txPool, eth.blockchain, eth.chainDb)
@@ -224,7 +233,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
 	log.Info("Backend Config", "max-calldata-size", config.Rollup.MaxCallDataSize, "gas-limit", config.Rollup.GasLimit, "is-verifier", config.Rollup.IsVerifier, "using-ovm", vm.UsingOVM)
-	eth.APIBackend = &EthAPIBackend{ctx.ExtRPCEnabled(), eth, nil, nil, config.Rollup.IsVerifier, config.Rollup.GasLimit, vm.UsingOVM, config.Rollup.MaxCallDataSize}
+	eth.APIBackend = &EthAPIBackend{ctx.ExtRPCEnabled(), eth, nil, nil, nil, config.Rollup.IsVerifier, config.Rollup.GasLimit, vm.UsingOVM, config.Rollup.MaxCallDataSize}
 	gpoParams := config.GPO
 	if gpoParams.Default == nil {
 		gpoParams.Default = config.Miner.GasPrice
@@ -232,7 +241,21 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
 	// create the Rollup GPO and allow the API backend and the sync service to access it
 	rollupGpo := gasprice.NewRollupOracle()
+	if err := rollupGpo.SetGasToken(config.Rollup.GasToken); err != nil {
+		return nil, fmt.Errorf("Cannot configure gas token: %w", err)
+	}
 	eth.APIBackend.rollupGpo = rollupGpo
+	// Nodes without the state or the L1 connection of the sequencer serve the
+	// gas prices of a remote node instead
+	if config.Rollup.RemoteRollupOracleHttp != "" {
+		client, err := rpc.Dial(config.Rollup.RemoteRollupOracleHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to remote rollup oracle: %w", err)
+		}
+		eth.APIBackend.rollupGpo = gasprice.NewRemoteRollupOracle(client, config.Rollup.RemoteRollupOracleTTL)
+		log.Info("Configured remote rollup oracle", "url", config.Rollup.RemoteRollupOracleHttp)
+	}
+	eth.APIBackend.feeSnapshots = gasprice.NewFeeSnapshotter(eth.APIBackend.rollupGpo, chainConfig, eth.syncService.GetLatestL1BlockNumber, config.Rollup.FeeEstimateMargin)
 	eth.syncService.RollupGpo = rollupGpo
 	return eth, nil
 }
@@ -542,6 +565,17 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	return protos
 }
 
+// LoadPersistedTxPool admits the transactions of the persisted transaction
+// pool again. With the OVM, blocks are not built from the pool, so the sync
+// service of the sequencer replays the transactions instead once it has
+// started.
+func (s *Ethereum) LoadPersistedTxPool() {
+	if vm.UsingOVM {
+		return
+	}
+	s.txPool.LoadPersisted()
+}
+
 // Start implements node.Service, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start(srvr *p2p.Server) error {
@@ -550,6 +584,9 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
 
+	// Take fee snapshots at every new block
+	s.APIBackend.feeSnapshots.Start(s.blockchain)
+
 	// Start the RPC service
 	s.netRPCService = ethapi.NewPublicNetAPI(srvr, s.NetVersion())
 	return nil
@@ -565,6 +602,7 @@ func (s *Ethereum) Stop() error {
 	s.miner.Stop()
 	s.eventMux.Stop()
 	s.syncService.Stop()
+	s.APIBackend.feeSnapshots.Stop()
 
 	s.chainDb.Close()
 	close(s.shutdownChan)
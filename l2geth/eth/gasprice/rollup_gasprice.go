@@ -4,16 +4,46 @@ import (
 	"context"
 	"math/big"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/tracing"
 )
 
+const (
+	// l1GasPriceHistorySize is the maximum number of L1 gas prices that are
+	// kept to project the L1 gas price from
+	l1GasPriceHistorySize = 512
+	// l1GasPriceHistoryWindow is how long the L1 gas prices are kept
+	l1GasPriceHistoryWindow = time.Hour
+)
+
+// RollupGasPriceOracle is the source of the gas prices that the fees of the
+// rollup are estimated with, a RollupOracle or a
+// RemoteRollupOracle
+type RollupGasPriceOracle interface {
+	SuggestL1GasPrice(ctx context.Context) (*big.Int, error)
+	SuggestL2GasPrice(ctx context.Context) (*big.Int, error)
+	SetL1GasPrice(gasPrice *big.Int) error
+	SetL2GasPrice(gasPrice *big.Int) error
+	L1GasPriceHistory() []fees.L1GasPriceSample
+	GasToken() *fees.GasToken
+	Version() uint64
+}
+
 // RollupOracle holds the L1 and L2 gas prices for fee calculation
 type RollupOracle struct {
 	l1GasPrice     *big.Int
+	l1History      []fees.L1GasPriceSample
 	l2GasPrice     *big.Int
+	gasToken       *fees.GasToken
 	l1GasPriceLock sync.RWMutex
 	l2GasPriceLock sync.RWMutex
+	gasTokenLock   sync.RWMutex
+	// version is incremented whenever a gas price or the gas token changes
+	version uint64
 }
 
 // NewRollupOracle returns an initialized RollupOracle
@@ -23,15 +53,25 @@ func NewRollupOracle() *RollupOracle {
 		l2GasPrice:     new(big.Int),
 		l1GasPriceLock: sync.RWMutex{},
 		l2GasPriceLock: sync.RWMutex{},
+		gasTokenLock:   sync.RWMutex{},
 	}
 }
 
 // SuggestL1GasPrice returns the gas price which should be charged per byte of published
-// data by the sequencer.
+// data by the sequencer. It is denominated in the native token of the chain.
 func (gpo *RollupOracle) SuggestL1GasPrice(ctx context.Context) (*big.Int, error) {
+	_, span := tracing.StartSpan(ctx, "gasprice.SuggestL1GasPrice")
 	gpo.l1GasPriceLock.RLock()
 	defer gpo.l1GasPriceLock.RUnlock()
-	return gpo.l1GasPrice, nil
+	gpo.gasTokenLock.RLock()
+	defer gpo.gasTokenLock.RUnlock()
+	price := gpo.l1GasPrice
+	if !gpo.gasToken.IsETH() {
+		price = gpo.gasToken.FromWei(gpo.l1GasPrice)
+	}
+	span.SetAttribute("l1GasPrice", price)
+	span.Finish(nil)
+	return price, nil
 }
 
 // SetL1GasPrice returns the current L1 gas price
@@ -39,15 +79,45 @@ func (gpo *RollupOracle) SetL1GasPrice(gasPrice *big.Int) error {
 	gpo.l1GasPriceLock.Lock()
 	defer gpo.l1GasPriceLock.Unlock()
 	gpo.l1GasPrice = gasPrice
+	atomic.AddUint64(&gpo.version, 1)
+	gpo.l1History = append(gpo.l1History, fees.L1GasPriceSample{Time: uint64(time.Now().Unix()), Price: gasPrice})
+	if len(gpo.l1History) > l1GasPriceHistorySize {
+		gpo.l1History = gpo.l1History[len(gpo.l1History)-l1GasPriceHistorySize:]
+	}
 	log.Info("Set L1 Gas Price", "gasprice", gpo.l1GasPrice)
 	return nil
 }
 
+// L1GasPriceHistory returns the L1 gas prices that were set within the
+// history window, ordered by time and converted to the native token the
+// same way as SuggestL1GasPrice
+func (gpo *RollupOracle) L1GasPriceHistory() []fees.L1GasPriceSample {
+	gpo.l1GasPriceLock.RLock()
+	defer gpo.l1GasPriceLock.RUnlock()
+	gpo.gasTokenLock.RLock()
+	defer gpo.gasTokenLock.RUnlock()
+	cutoff := uint64(time.Now().Add(-l1GasPriceHistoryWindow).Unix())
+	samples := make([]fees.L1GasPriceSample, 0, len(gpo.l1History))
+	for _, sample := range gpo.l1History {
+		if sample.Time < cutoff {
+			continue
+		}
+		if !gpo.gasToken.IsETH() {
+			sample.Price = gpo.gasToken.FromWei(sample.Price)
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
 // SuggestL2GasPrice returns the gas price which should be charged per unit of gas
 // set manually by the sequencer depending on congestion
 func (gpo *RollupOracle) SuggestL2GasPrice(ctx context.Context) (*big.Int, error) {
+	_, span := tracing.StartSpan(ctx, "gasprice.SuggestL2GasPrice")
 	gpo.l2GasPriceLock.RLock()
 	defer gpo.l2GasPriceLock.RUnlock()
+	span.SetAttribute("l2GasPrice", gpo.l2GasPrice)
+	span.Finish(nil)
 	return gpo.l2GasPrice, nil
 }
 
@@ -56,6 +126,38 @@ func (gpo *RollupOracle) SetL2GasPrice(gasPrice *big.Int) error {
 	gpo.l2GasPriceLock.Lock()
 	defer gpo.l2GasPriceLock.Unlock()
 	gpo.l2GasPrice = gasPrice
+	atomic.AddUint64(&gpo.version, 1)
 	log.Info("Set L2 Gas Price", "gasprice", gpo.l2GasPrice)
 	return nil
 }
+
+// GasToken returns the native token that fees are paid in. A nil value
+// represents ETH.
+func (gpo *RollupOracle) GasToken() *fees.GasToken {
+	gpo.gasTokenLock.RLock()
+	defer gpo.gasTokenLock.RUnlock()
+	return gpo.gasToken
+}
+
+// SetGasToken sets the native token that fees are paid in. The L1 gas price
+// is always set in wei and is converted using the gas token's conversion rate.
+func (gpo *RollupOracle) SetGasToken(gasToken *fees.GasToken) error {
+	if err := gasToken.Validate(); err != nil {
+		return err
+	}
+	gpo.gasTokenLock.Lock()
+	defer gpo.gasTokenLock.Unlock()
+	gpo.gasToken = gasToken
+	atomic.AddUint64(&gpo.version, 1)
+	if !gasToken.IsETH() {
+		log.Info("Set Gas Token", "symbol", gasToken.Symbol, "decimals", gasToken.Decimals,
+			"conversion-rate", gasToken.ConversionRate)
+	}
+	return nil
+}
+
+// Version returns a number that changes whenever a gas price or the gas
+// token changes
+func (gpo *RollupOracle) Version() uint64 {
+	return atomic.LoadUint64(&gpo.version)
+}
@@ -0,0 +1,190 @@
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+const (
+	// DefaultRemoteRollupOracleTTL is how long the gas prices read from the
+	// remote node are used before they are read again
+	DefaultRemoteRollupOracleTTL = 5 * time.Second
+	// remoteRollupOracleMaxStaleness is how long the gas prices read from the
+	// remote node are used while it cannot be reached
+	remoteRollupOracleMaxStaleness = 5 * time.Minute
+	// remoteRollupOracleTimeout is the timeout of a read from the remote node
+	remoteRollupOracleTimeout = 5 * time.Second
+)
+
+// errRemoteRollupOracle is the error for when the gas prices cannot be read
+// from the remote node and the values that were read before are too old
+var errRemoteRollupOracle = errors.New("cannot read gas prices from remote node")
+
+var (
+	remoteRollupOracleReadMeter  = metrics.NewRegisteredMeter("rollup/gpo/remote/read", nil)
+	remoteRollupOracleStaleMeter = metrics.NewRegisteredMeter("rollup/gpo/remote/stale", nil)
+	remoteRollupOracleErrorMeter = metrics.NewRegisteredMeter("rollup/gpo/remote/error", nil)
+)
+
+// RemoteCaller is the client of the remote node, an rpc.Client satisfies it
+type RemoteCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// remoteGasPrices is the result of rollup_gasPrices
+type remoteGasPrices struct {
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	GasToken   *fees.GasToken `json:"gasToken,omitempty"`
+}
+
+// RemoteRollupOracle is a RollupGasPriceOracle that reads the gas prices from
+// the rollup_gasPrices endpoint of another node, usually the sequencer, so
+// that nodes without the state or the L1 connection of the sequencer can
+// estimate fees. The gas prices are cached for the ttl and are used for up
+// to the max staleness while the remote node cannot be reached. They are
+// already in the native token and include the fee estimate margin of the
+// remote node.
+type RemoteRollupOracle struct {
+	client RemoteCaller
+	ttl    time.Duration
+	now    func() time.Time
+
+	lock       sync.Mutex
+	l1GasPrice *big.Int
+	l2GasPrice *big.Int
+	gasToken   *fees.GasToken
+	l1History  []fees.L1GasPriceSample
+	updated    time.Time
+	version    uint64
+}
+
+// NewRemoteRollupOracle returns a RemoteRollupOracle of the node that the
+// client is connected to
+func NewRemoteRollupOracle(client RemoteCaller, ttl time.Duration) *RemoteRollupOracle {
+	if ttl == 0 {
+		ttl = DefaultRemoteRollupOracleTTL
+	}
+	return &RemoteRollupOracle{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// refresh reads the gas prices from the remote node when they are older than
+// the ttl. It must be called holding the lock.
+func (r *RemoteRollupOracle) refresh() error {
+	now := r.now()
+	if r.l1GasPrice != nil && now.Sub(r.updated) < r.ttl {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteRollupOracleTimeout)
+	defer cancel()
+
+	var prices remoteGasPrices
+	err := r.client.CallContext(ctx, &prices, "rollup_gasPrices")
+	if err == nil && (prices.L1GasPrice == nil || prices.L2GasPrice == nil) {
+		err = errors.New("missing gas prices")
+	}
+	if err != nil {
+		remoteRollupOracleErrorMeter.Mark(1)
+		if r.l1GasPrice != nil && now.Sub(r.updated) < remoteRollupOracleMaxStaleness {
+			remoteRollupOracleStaleMeter.Mark(1)
+			log.Warn("Cannot read gas prices from remote node, using stale values", "age", now.Sub(r.updated), "msg", err)
+			return nil
+		}
+		return fmt.Errorf("%w: %v", errRemoteRollupOracle, err)
+	}
+	remoteRollupOracleReadMeter.Mark(1)
+	l1GasPrice, l2GasPrice := prices.L1GasPrice.ToInt(), prices.L2GasPrice.ToInt()
+	if r.l1GasPrice == nil || r.l1GasPrice.Cmp(l1GasPrice) != 0 || r.l2GasPrice.Cmp(l2GasPrice) != 0 {
+		r.version++
+	}
+	if r.l1GasPrice == nil || r.l1GasPrice.Cmp(l1GasPrice) != 0 {
+		r.l1History = append(r.l1History, fees.L1GasPriceSample{Time: uint64(now.Unix()), Price: l1GasPrice})
+		if len(r.l1History) > l1GasPriceHistorySize {
+			r.l1History = r.l1History[len(r.l1History)-l1GasPriceHistorySize:]
+		}
+	}
+	r.l1GasPrice, r.l2GasPrice, r.gasToken, r.updated = l1GasPrice, l2GasPrice, prices.GasToken, now
+	return nil
+}
+
+// SuggestL1GasPrice returns the L1 gas price of the remote node
+func (r *RemoteRollupOracle) SuggestL1GasPrice(ctx context.Context) (*big.Int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.refresh(); err != nil {
+		return nil, err
+	}
+	return r.l1GasPrice, nil
+}
+
+// SuggestL2GasPrice returns the L2 gas price of the remote node
+func (r *RemoteRollupOracle) SuggestL2GasPrice(ctx context.Context) (*big.Int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.refresh(); err != nil {
+		return nil, err
+	}
+	return r.l2GasPrice, nil
+}
+
+// SetL1GasPrice is not supported, the gas prices are set on the remote node
+func (r *RemoteRollupOracle) SetL1GasPrice(gasPrice *big.Int) error {
+	return errors.New("gas prices are read from a remote node")
+}
+
+// SetL2GasPrice is not supported, the gas prices are set on the remote node
+func (r *RemoteRollupOracle) SetL2GasPrice(gasPrice *big.Int) error {
+	return errors.New("gas prices are read from a remote node")
+}
+
+// L1GasPriceHistory returns the L1 gas prices that were read from the remote
+// node within the history window, ordered by time
+func (r *RemoteRollupOracle) L1GasPriceHistory() []fees.L1GasPriceSample {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cutoff := uint64(r.now().Add(-l1GasPriceHistoryWindow).Unix())
+	samples := make([]fees.L1GasPriceSample, 0, len(r.l1History))
+	for _, sample := range r.l1History {
+		if sample.Time >= cutoff {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// GasToken returns the native token of the remote node, nil for ETH or
+// before the gas prices were read
+func (r *RemoteRollupOracle) GasToken() *fees.GasToken {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.gasToken
+}
+
+// Version returns a number that changes whenever the gas prices of the
+// remote node change. The gas prices are read again when they are older than
+// the ttl, so that the snapshots of the oracle follow the remote node.
+func (r *RemoteRollupOracle) Version() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.refresh(); err != nil {
+		log.Debug("Cannot refresh remote gas prices", "msg", err)
+	}
+	return r.version
+}
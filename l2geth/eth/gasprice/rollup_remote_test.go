@@ -0,0 +1,86 @@
+package gasprice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testRemote answers rollup_gasPrices with its gas prices
+type testRemote struct {
+	l1GasPrice int64
+	l2GasPrice int64
+	err        error
+	calls      int
+}
+
+func (r *testRemote) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	r.calls++
+	if r.err != nil {
+		return r.err
+	}
+	raw := []byte(`{"l1GasPrice":"0x` + big.NewInt(r.l1GasPrice).Text(16) + `","l2GasPrice":"0x` + big.NewInt(r.l2GasPrice).Text(16) + `"}`)
+	return json.Unmarshal(raw, result)
+}
+
+func TestRemoteRollupOracle(t *testing.T) {
+	remote := &testRemote{}
+	now := time.Unix(1600000000, 0)
+	oracle := NewRemoteRollupOracle(remote, time.Minute)
+	oracle.now = func() time.Time { return now }
+
+	steps := []struct {
+		elapsed    time.Duration
+		l1GasPrice int64
+		err        error
+		expect     int64
+		calls      int
+		version    uint64
+		fails      bool
+	}{
+		// Read from the remote node
+		{elapsed: 0, l1GasPrice: 10, expect: 10, calls: 1, version: 1},
+		// Cached for the ttl
+		{elapsed: 30 * time.Second, l1GasPrice: 20, expect: 10, calls: 1, version: 1},
+		// Read again after the ttl
+		{elapsed: 31 * time.Second, l1GasPrice: 20, expect: 20, calls: 2, version: 2},
+		// The version does not change when the gas prices do not
+		{elapsed: time.Minute, l1GasPrice: 20, expect: 20, calls: 3, version: 2},
+		// The stale values are used while the remote node fails
+		{elapsed: 2 * time.Minute, err: errors.New("unreachable"), expect: 20, calls: 4, version: 2},
+		// Until they are older than the max staleness
+		{elapsed: 4 * time.Minute, err: errors.New("unreachable"), calls: 5, fails: true},
+	}
+	for i, step := range steps {
+		now = now.Add(step.elapsed)
+		remote.l1GasPrice, remote.l2GasPrice, remote.err = step.l1GasPrice, 1, step.err
+		price, err := oracle.SuggestL1GasPrice(context.Background())
+		if step.fails {
+			if !errors.Is(err, errRemoteRollupOracle) {
+				t.Fatalf("step %d: mismatched error: got %v, expect %v", i, err, errRemoteRollupOracle)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if price.Int64() != step.expect {
+			t.Fatalf("step %d: mismatched L1 gas price: got %d, expect %d", i, price, step.expect)
+		}
+		if remote.calls != step.calls {
+			t.Fatalf("step %d: mismatched calls: got %d, expect %d", i, remote.calls, step.calls)
+		}
+		if version := oracle.Version(); version != step.version {
+			t.Fatalf("step %d: mismatched version: got %d, expect %d", i, version, step.version)
+		}
+	}
+	if history := oracle.L1GasPriceHistory(); len(history) != 2 {
+		t.Fatalf("mismatched history: got %d samples, expect %d", len(history), 2)
+	}
+	if err := oracle.SetL1GasPrice(big.NewInt(1)); err == nil {
+		t.Fatal("set the L1 gas price of a remote node")
+	}
+}
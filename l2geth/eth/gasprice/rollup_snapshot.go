@@ -0,0 +1,151 @@
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// maxFeeSnapshotAge is how old a snapshot can get before it is taken again
+// when it is read, which only happens when no blocks are produced
+const maxFeeSnapshotAge = 30 * time.Second
+
+var (
+	feeSnapshotAgeGauge     = metrics.NewRegisteredGauge("rollup/feesnapshot/age", nil)
+	feeSnapshotRefreshMeter = metrics.NewRegisteredMeter("rollup/feesnapshot/refresh", nil)
+	feeSnapshotStaleMeter   = metrics.NewRegisteredMeter("rollup/feesnapshot/stale", nil)
+)
+
+// chainHeadSubscriber is the chain that snapshots are taken at the head of
+type chainHeadSubscriber interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// snapshot is a snapshot along with the version of the oracle it was taken
+// at
+type snapshot struct {
+	*fees.OracleSnapshot
+	version uint64
+}
+
+// FeeSnapshotter keeps a snapshot of the RollupOracle that is taken again
+// at every new block and whenever the oracle changes, so that RPC handlers
+// read all fee parameters at once without taking the locks of the oracle
+type FeeSnapshotter struct {
+	gpo           RollupGasPriceOracle
+	config        *params.ChainConfig
+	l1BlockNumber func() uint64
+	margin        uint64
+
+	current atomic.Value // *snapshot
+	lock    sync.Mutex   // Serializes taking snapshots
+
+	sub  event.Subscription
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewFeeSnapshotter creates a FeeSnapshotter of the oracle. The latest L1
+// block number selects the calldata gas schedule of the snapshots, and the
+// gas prices of the snapshots are raised by the margin in percent.
+func NewFeeSnapshotter(gpo RollupGasPriceOracle, config *params.ChainConfig, l1BlockNumber func() uint64, margin uint64) *FeeSnapshotter {
+	return &FeeSnapshotter{
+		gpo:           gpo,
+		config:        config,
+		l1BlockNumber: l1BlockNumber,
+		margin:        margin,
+		quit:          make(chan struct{}),
+	}
+}
+
+// Start takes a snapshot at every new head of the chain until Stop is
+// called
+func (s *FeeSnapshotter) Start(chain chainHeadSubscriber) {
+	heads := make(chan core.ChainHeadEvent, 16)
+	s.sub = chain.SubscribeChainHeadEvent(heads)
+	s.wg.Add(1)
+	go s.loop(heads)
+}
+
+// Stop stops taking snapshots at new heads
+func (s *FeeSnapshotter) Stop() {
+	if s.sub != nil {
+		s.sub.Unsubscribe()
+	}
+	close(s.quit)
+	s.wg.Wait()
+}
+
+func (s *FeeSnapshotter) loop(heads chan core.ChainHeadEvent) {
+	defer s.wg.Done()
+	for {
+		select {
+		case head := <-heads:
+			s.refresh(head.Block.NumberU64())
+		case err := <-s.sub.Err():
+			if err != nil {
+				log.Warn("Fee snapshot subscription failed", "msg", err)
+			}
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// Snapshot returns the latest snapshot. A new snapshot is taken when the
+// oracle changed since the latest one or when it is too old.
+func (s *FeeSnapshotter) Snapshot() *fees.OracleSnapshot {
+	current, _ := s.current.Load().(*snapshot)
+	switch {
+	case current == nil || current.version != s.gpo.Version():
+		var number uint64
+		if current != nil {
+			number = current.BlockNumber
+		}
+		current = s.refresh(number)
+	case time.Since(current.Time) > maxFeeSnapshotAge:
+		feeSnapshotStaleMeter.Mark(1)
+		current = s.refresh(current.BlockNumber)
+	}
+	feeSnapshotAgeGauge.Update(time.Since(current.Time).Milliseconds())
+	return current.OracleSnapshot
+}
+
+// refresh takes a snapshot of the oracle at the block
+func (s *FeeSnapshotter) refresh(number uint64) *snapshot {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The version is read first so that a change while the snapshot is
+	// taken leads to another snapshot
+	version := s.gpo.Version()
+	ctx := context.Background()
+	l1GasPrice, _ := s.gpo.SuggestL1GasPrice(ctx)
+	l2GasPrice, _ := s.gpo.SuggestL2GasPrice(ctx)
+	l1BlockNumber := s.l1BlockNumber()
+	next := &snapshot{
+		OracleSnapshot: &fees.OracleSnapshot{
+			BlockNumber:   number,
+			L1BlockNumber: l1BlockNumber,
+			L1GasPrice:    fees.AddMargin(l1GasPrice, s.margin),
+			L2GasPrice:    fees.AddMargin(l2GasPrice, s.margin),
+			CalldataGas:   core.L1CalldataGas(s.config, new(big.Int).SetUint64(l1BlockNumber)),
+			Margin:        s.margin,
+			Time:          time.Now(),
+		},
+		version: version,
+	}
+	s.current.Store(next)
+	feeSnapshotRefreshMeter.Mark(1)
+	return next
+}
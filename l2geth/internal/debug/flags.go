@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
+	"github.com/ethereum/go-ethereum/profiling"
 	"github.com/fjl/memsize/memsizeui"
 	colorable "github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
@@ -86,13 +87,31 @@ var (
 		Name:  "trace",
 		Usage: "Write execution trace to the given file",
 	}
+	logFormatFlag = cli.StringFlag{
+		Name:   "logformat",
+		Usage:  "Log format for the console: terminal, logfmt or json",
+		Value:  "terminal",
+		EnvVar: "LOG_FORMAT",
+	}
+	profileDirFlag = cli.StringFlag{
+		Name:   "profile.dir",
+		Usage:  "Directory to write profiles to when a hot path exceeds its latency threshold",
+		EnvVar: "PROFILE_DIR",
+	}
+	profileThresholdsFlag = cli.StringFlag{
+		Name:   "profile.thresholds",
+		Usage:  "Comma separated latency thresholds that trigger a profile capture (block, fee, batch)",
+		Value:  "block=500ms,fee=10ms,batch=5s",
+		EnvVar: "PROFILE_THRESHOLDS",
+	}
 )
 
 // Flags holds all command-line flags required for debugging.
 var Flags = []cli.Flag{
-	verbosityFlag, vmoduleFlag, backtraceAtFlag, debugFlag,
+	verbosityFlag, vmoduleFlag, backtraceAtFlag, debugFlag, logFormatFlag,
 	pprofFlag, pprofAddrFlag, pprofPortFlag,
 	memprofilerateFlag, blockprofilerateFlag, cpuprofileFlag, traceFlag,
+	profileDirFlag, profileThresholdsFlag,
 }
 
 var (
@@ -115,6 +134,17 @@ func init() {
 func Setup(ctx *cli.Context, logdir string) error {
 	// logging
 	log.PrintOrigins(ctx.GlobalBool(debugFlag.Name))
+	switch format := ctx.GlobalString(logFormatFlag.Name); format {
+	case "", "terminal":
+	case "logfmt":
+		ostream = log.StreamHandler(os.Stderr, log.LogfmtFormat())
+		glogger.SetHandler(ostream)
+	case "json":
+		ostream = log.StreamHandler(os.Stderr, log.JSONFormat())
+		glogger.SetHandler(ostream)
+	default:
+		return fmt.Errorf("unknown log format: %s", format)
+	}
 	if logdir != "" {
 		rfh, err := log.RotatingFileHandler(
 			logdir,
@@ -144,6 +174,16 @@ func Setup(ctx *cli.Context, logdir string) error {
 			return err
 		}
 	}
+	if profileDir := ctx.GlobalString(profileDirFlag.Name); profileDir != "" {
+		thresholds, err := profiling.ParseThresholds(ctx.GlobalString(profileThresholdsFlag.Name))
+		if err != nil {
+			return err
+		}
+		if profiling.Default, err = profiling.NewProfiler(expandHome(profileDir), thresholds); err != nil {
+			return err
+		}
+		log.Info("Latency triggered profiling enabled", "dir", profileDir, "thresholds", thresholds)
+	}
 
 	// pprof server
 	if ctx.GlobalBool(pprofFlag.Name) {
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -45,7 +47,10 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/tracing"
 	"github.com/tyler-smith/go-bip39"
 )
 
@@ -119,11 +124,14 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	}
 	pending, queue := s.b.TxPoolContent()
 
-	// Flatten the pending transactions
+	// Flatten the pending transactions, along with the check of their fee
+	// at the latest gas prices
 	for account, txs := range pending {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+			rpcTx := newRPCPendingTransaction(tx)
+			rpcTx.PoolFee = s.b.PoolTxFee(tx)
+			dump[fmt.Sprintf("%d", tx.Nonce())] = rpcTx
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -163,11 +171,27 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 		}
 		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
 	}
+	// Pending transactions are followed by the check of their fee
+	var formatFee = func(fee *fees.PoolTxFee) string {
+		switch {
+		case fee.PaysEnough && fee.L1Fee == nil:
+			return "fee accepted"
+		case fee.PaysEnough:
+			return fmt.Sprintf("L1 fee %v wei, fee accepted", fee.L1Fee.ToInt())
+		case fee.L1Fee == nil:
+			return fmt.Sprintf("fee rejected: %s", fee.Reason)
+		}
+		return fmt.Sprintf("L1 fee %v wei, fee rejected: %s", fee.L1Fee.ToInt(), fee.Reason)
+	}
 	// Flatten the pending transactions
 	for account, txs := range pending {
 		dump := make(map[string]string)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
+			summary := format(tx)
+			if fee := s.b.PoolTxFee(tx); fee != nil {
+				summary = fmt.Sprintf("%s (%s)", summary, formatFee(fee))
+			}
+			dump[fmt.Sprintf("%d", tx.Nonce())] = summary
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -790,26 +814,12 @@ type account struct {
 	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
 }
 
-func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides map[common.Address]account, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]account
 
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
-	if state == nil || err != nil {
-		return nil, 0, false, err
-	}
-	// Set sender address or use a default if none specified
-	var addr common.Address
-	if args.From == nil {
-		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
-			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
-				addr = accounts[0].Address
-			}
-		}
-	} else {
-		addr = *args.From
-	}
-	// Override the fields of specified contracts before execution.
-	for addr, account := range overrides {
+// Apply overrides the fields of specified accounts into the given state.
+func (diff StateOverride) Apply(state *state.StateDB) error {
+	for addr, account := range diff {
 		// Override account nonce.
 		if account.Nonce != nil {
 			state.SetNonce(addr, uint64(*account.Nonce))
@@ -823,7 +833,7 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 			state.SetBalance(addr, (*big.Int)(*account.Balance))
 		}
 		if account.State != nil && account.StateDiff != nil {
-			return nil, 0, false, fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
 		}
 		// Replace entire state if caller requires.
 		if account.State != nil {
@@ -836,6 +846,31 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 			}
 		}
 	}
+	return nil
+}
+
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides map[common.Address]account, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, 0, false, err
+	}
+	// Set sender address or use a default if none specified
+	var addr common.Address
+	if args.From == nil {
+		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
+			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
+				addr = accounts[0].Address
+			}
+		}
+	} else {
+		addr = *args.From
+	}
+	// Override the fields of specified contracts before execution.
+	if err := StateOverride(overrides).Apply(state); err != nil {
+		return nil, 0, false, err
+	}
 	// Set default gas & gas price if none were set
 	gas := uint64(math.MaxUint64 / 2)
 	if args.Gas != nil {
@@ -934,18 +969,121 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 	return res, gas, failed, err
 }
 
+// CallOptions are the optional results of eth_call and eth_estimateGas
+type CallOptions struct {
+	// L1Fee returns the L1 fee of the transaction that is equivalent to the
+	// call along with the result
+	L1Fee bool `json:"l1Fee"`
+}
+
+// CallL1Fee is the L1 fee of the transaction that is equivalent to a call
+type CallL1Fee struct {
+	L1GasUsed  hexutil.Uint64 `json:"l1GasUsed"`
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	// L1Fee is the cost of making the data of the transaction available
+	// after the fee subsidy of its target, the L1 gas used at the L1 gas
+	// price when it is posted as calldata
+	L1Fee *hexutil.Big `json:"l1Fee"`
+	// MaxL1Fee is the most L1 fee that the transaction is charged once its
+	// fee is rounded up into its gas limit
+	MaxL1Fee *hexutil.Big `json:"maxL1Fee"`
+}
+
+// CallResult is the result of eth_call with the L1 fee of the call
+type CallResult struct {
+	Result  hexutil.Bytes  `json:"result"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Fee     *CallL1Fee     `json:"fee"`
+}
+
+// EstimateGasResult is the result of eth_estimateGas with the L1 fee of the
+// estimated transaction
+type EstimateGasResult struct {
+	Gas hexutil.Uint64 `json:"gas"`
+	Fee *CallL1Fee     `json:"fee"`
+}
+
+// estimateTx returns the transaction of a call that its fee is estimated
+// for. The nonce and the gas limit do not take part in the fee.
+func (args *CallArgs) estimateTx() *types.Transaction {
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+	if args.To == nil {
+		return types.NewContractCreation(0, value, 0, fees.BigTxGasPrice, data)
+	}
+	return types.NewTransaction(0, *args.To, value, 0, fees.BigTxGasPrice, data)
+}
+
+// callL1Fee returns the L1 fee of a transaction that uses the L2 gas at the
+// gas prices that are suggested to senders, priced by the backend the same
+// way as the sequencer prices the transactions that it accepts, after the fee
+// subsidies and the fee policies. The data of
+// a call is all that is known of the transaction, the rest of the
+// transaction is charged for with the fixed overhead, which is an upper
+// bound of its RLP encoding.
+func callL1Fee(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, tx *types.Transaction, l2GasUsed uint64) (*CallL1Fee, error) {
+	snapshot := feeSnapshotAt(ctx, b, blockNrOrHash)
+	estimate, err := b.EstimateTxFee(ctx, tx, new(big.Int).SetUint64(l2GasUsed), snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if !estimate.GasLimit.IsUint64() {
+		return nil, fmt.Errorf("fee overflow: %s", estimate.GasLimit)
+	}
+	return &CallL1Fee{
+		L1GasUsed:  hexutil.Uint64(snapshot.CalldataGas.CalculateL1GasUsed(tx.Data()).Uint64()),
+		L1GasPrice: (*hexutil.Big)(snapshot.L1GasPrice),
+		L2GasPrice: (*hexutil.Big)(snapshot.L2GasPrice),
+		L1Fee:      (*hexutil.Big)(estimate.L1Fee),
+		MaxL1Fee:   (*hexutil.Big)(fees.MaxChargedL1Fee(estimate.GasLimit.Uint64(), snapshot.L2GasPrice)),
+	}, nil
+}
+
+// feeSnapshotAt returns the fee snapshot that a transaction on the state of
+// a block is charged with. On chains that migrated between fee formulas, the
+// transactions of the blocks before the migration were charged with the
+// legacy formula.
+func feeSnapshotAt(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash) *fees.OracleSnapshot {
+	snapshot := b.FeeSnapshot()
+	config := b.ChainConfig()
+	if config.FeeMigrationBlock == nil {
+		return snapshot
+	}
+	header, err := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil || header == nil {
+		return snapshot
+	}
+	// The transaction would be part of the next block
+	number := new(big.Int).Add(header.Number, common.Big1)
+	if config.IsFeeMigrated(number) {
+		return snapshot
+	}
+	legacy := *snapshot
+	legacy.CalldataGas = core.BlockL1CalldataGas(config, number, new(big.Int).SetUint64(snapshot.L1BlockNumber))
+	return &legacy
+}
+
 // Call executes the given transaction on the state for the given block number.
 //
 // Additionally, the caller can specify a batch of contract for fields overriding.
+// When the l1Fee option is set, the result is returned along with the gas used
+// and the L1 fee of the equivalent transaction.
 //
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]account) (hexutil.Bytes, error) {
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]account, opts *CallOptions) (interface{}, error) {
 	var accounts map[common.Address]account
 	if overrides != nil {
 		accounts = *overrides
 	}
-	result, _, failed, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, 5*time.Second, s.b.RPCGasCap())
+	result, gas, failed, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, 5*time.Second, s.b.RPCGasCap())
 	if err != nil {
 		return nil, err
 	}
@@ -957,7 +1095,14 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOr
 		}
 		return (hexutil.Bytes)(result), err
 	}
-	return (hexutil.Bytes)(result), err
+	if opts == nil || !opts.L1Fee {
+		return (hexutil.Bytes)(result), nil
+	}
+	fee, err := callL1Fee(ctx, s.b, blockNrOrHash, args.estimateTx(), gas)
+	if err != nil {
+		return nil, err
+	}
+	return &CallResult{Result: result, GasUsed: hexutil.Uint64(gas), Fee: fee}, nil
 }
 
 // Optimism note: The gasPrice in Optimism is modified to always return 1 gwei. We
@@ -967,29 +1112,30 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOr
 // transaction calldata
 func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap *big.Int) (hexutil.Uint64, error) {
 	// 1. get the gas that would be used by the transaction
-	gasUsed, err := legacyDoEstimateGas(ctx, b, args, blockNrOrHash, gasCap)
+	execCtx, span := tracing.StartSpan(ctx, "ethapi.estimateExecutionGas")
+	gasUsed, err := legacyDoEstimateGas(execCtx, b, args, blockNrOrHash, gasCap)
+	span.SetAttribute("gasUsed", uint64(gasUsed))
+	span.Finish(err)
 	if err != nil {
 		return 0, err
 	}
-	// 2a. fetch the data price, depends on how the sequencer has chosen to update their values based on the
-	// l1 gas prices
-	l1GasPrice, err := b.SuggestL1GasPrice(ctx)
-	if err != nil {
-		return 0, err
-	}
-	// 2b. fetch the execution gas price, by the typical mempool dynamics
-	l2GasPrice, err := b.SuggestL2GasPrice(ctx)
+	// 2. fetch the data price, which depends on how the sequencer has
+	// chosen to update their values based on the l1 gas prices, and the
+	// execution gas price, by the typical mempool dynamics
+	snapshot := feeSnapshotAt(ctx, b, blockNrOrHash)
+	// 3. price the calldata the same way as the sequencer does, after the
+	// fee subsidies and the fee policies. The additional overhead of RLP
+	// encoding is covered by the fixed cost of the data availability layer
+	l2GasLimit := new(big.Int).SetUint64(uint64(gasUsed))
+	feeCtx, span := tracing.StartSpan(ctx, "ethapi.EstimateTxFee")
+	estimate, err := b.EstimateTxFee(feeCtx, args.estimateTx(), l2GasLimit, snapshot)
 	if err != nil {
+		span.Finish(err)
 		return 0, err
 	}
-	data := []byte{}
-	if args.Data != nil {
-		data = *args.Data
-	}
-	// 3. calculate the fee using just the calldata. The additional overhead of
-	// RLP encoding is covered inside of EncodeL2GasLimit
-	l2GasLimit := new(big.Int).SetUint64(uint64(gasUsed))
-	fee := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+	fee := estimate.GasLimit
+	span.SetAttribute("fee", fee)
+	span.Finish(nil)
 	if !fee.IsUint64() {
 		return 0, fmt.Errorf("estimate gas overflow: %s", fee)
 	}
@@ -1072,10 +1218,23 @@ func legacyDoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrO
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block. This is modified to
-// encode the fee in wei as gas price is always 1
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+// encode the fee in wei as gas price is always 1. When the l1Fee option is
+// set, the estimate is returned along with the L1 fee of the transaction.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, opts *CallOptions) (interface{}, error) {
 	blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
-	return DoEstimateGas(ctx, s.b, args, blockNrOrHash, s.b.RPCGasCap())
+	if opts == nil || !opts.L1Fee {
+		return DoEstimateGas(ctx, s.b, args, blockNrOrHash, s.b.RPCGasCap())
+	}
+	// The L1 fee is priced with the execution gas that the estimate encodes
+	estimate, err := DoEstimateGas(ctx, s.b, args, blockNrOrHash, s.b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	fee, err := callL1Fee(ctx, s.b, blockNrOrHash, args.estimateTx(), fees.DecodeL2GasLimitU64(uint64(estimate)))
+	if err != nil {
+		return nil, err
+	}
+	return &EstimateGasResult{Gas: estimate, Fee: fee}, nil
 }
 
 // EstimateExecutionGas returns an estimate of the amount of gas needed to execute the
@@ -1101,6 +1260,23 @@ type ExecutionResult struct {
 	Failed      bool           `json:"failed"`
 	ReturnValue string         `json:"returnValue"`
 	StructLogs  []StructLogRes `json:"structLogs"`
+	// Rollup is the rollup fee of the transaction, only set when a single
+	// transaction is traced
+	Rollup *RollupTraceResult `json:"rollup,omitempty"`
+}
+
+// RollupTraceResult is the rollup fee of a traced transaction along with the
+// parameters of the gas price oracle that its fee was checked with
+type RollupTraceResult struct {
+	L1GasUsed hexutil.Uint64 `json:"l1GasUsed"`
+	// The L1 fee that is encoded in the gas limit, up to the rounding of
+	// the gas limit
+	L1Fee       *hexutil.Big     `json:"l1Fee"`
+	L1GasPrice  *hexutil.Big     `json:"l1GasPrice"`
+	L2GasLimit  hexutil.Uint64   `json:"l2GasLimit"`
+	L2GasPrice  *hexutil.Big     `json:"l2GasPrice"`
+	GPOVersion  hexutil.Uint64   `json:"gpoVersion"`
+	CalldataGas fees.CalldataGas `json:"calldataGas"`
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
@@ -1258,6 +1434,8 @@ type RPCTransaction struct {
 	Index            *hexutil.Uint64 `json:"index"`
 	QueueIndex       *hexutil.Uint64 `json:"queueIndex"`
 	RawTransaction   hexutil.Bytes   `json:"rawTransaction"`
+	// PoolFee is only set for the pending transactions of txpool_content
+	PoolFee *fees.PoolTxFee `json:"poolFee,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1464,11 +1642,17 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
 func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
-	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash)
+	return rpcMarshalReceipt(ctx, s.b, hash, s.b.ReceiptProfile("eth"))
+}
+
+// rpcMarshalReceipt returns the receipt of a transaction with its rollup
+// fields named as in the receipt profile, nil when the transaction is unknown
+func rpcMarshalReceipt(ctx context.Context, b Backend, hash common.Hash, profile *fees.ReceiptProfile) (map[string]interface{}, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.ChainDb(), hash)
 	if tx == nil {
 		return nil, nil
 	}
-	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	receipts, err := b.GetReceipts(ctx, blockHash)
 	if err != nil {
 		return nil, err
 	}
@@ -1510,6 +1694,27 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// The L1 fee is recorded once the rollup fork that adds it is active,
+	// some clients expect it in every receipt
+	if receipt.L1Fee != nil {
+		profile.Set(fields, fees.ReceiptFieldL1GasUsed, (*hexutil.Big)(receipt.L1GasUsed))
+		profile.Set(fields, fees.ReceiptFieldL1GasPrice, (*hexutil.Big)(receipt.L1GasPrice))
+		profile.Set(fields, fees.ReceiptFieldL1Fee, (*hexutil.Big)(receipt.L1Fee))
+	} else if profile.AlwaysL1Fee {
+		zero := (*hexutil.Big)(new(big.Int))
+		profile.Set(fields, fees.ReceiptFieldL1GasUsed, zero)
+		profile.Set(fields, fees.ReceiptFieldL1GasPrice, zero)
+		profile.Set(fields, fees.ReceiptFieldL1Fee, zero)
+	}
+	// Fees are paid in the native token, include it when it is not ETH
+	if gasToken := b.GasToken(); !gasToken.IsETH() {
+		profile.Set(fields, fees.ReceiptFieldGasToken, gasToken.Symbol)
+	}
+	// The arrival is recorded by sequencers that apply transactions first
+	// come first served
+	if arrival := rawdb.ReadTxArrival(b.ChainDb(), hash); arrival != nil {
+		profile.Set(fields, fees.ReceiptFieldArrivalTime, hexutil.Uint64(*arrival))
+	}
 	return fields, nil
 }
 
@@ -1703,11 +1908,16 @@ func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args Sen
 // SendRawTransaction will add the signed transaction to the transaction pool.
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
-	if s.b.IsVerifier() {
+	return sendRawTransaction(ctx, s.b, encodedTx)
+}
+
+// sendRawTransaction submits a raw transaction to the sequencer
+func sendRawTransaction(ctx context.Context, b Backend, encodedTx hexutil.Bytes) (common.Hash, error) {
+	if b.IsVerifier() {
 		return common.Hash{}, errors.New("Cannot send raw transaction in verifier mode")
 	}
 
-	if s.b.IsSyncing() {
+	if b.IsSyncing() {
 		return common.Hash{}, errors.New("Cannot send raw transaction while syncing")
 	}
 
@@ -1718,7 +1928,7 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	// L1Timestamp and L1BlockNumber will be set right before execution
 	txMeta := types.NewTransactionMeta(nil, 0, nil, types.QueueOriginSequencer, nil, nil, encodedTx)
 	tx.SetTransactionMeta(txMeta)
-	return SubmitTransaction(ctx, s.b, tx)
+	return SubmitTransaction(ctx, b, tx)
 }
 
 // Sign calculates an ECDSA signature for:
@@ -1916,23 +2126,476 @@ func (api *PublicRollupAPI) GetInfo(ctx context.Context) rollupInfo {
 }
 
 type gasPrices struct {
-	L1GasPrice *hexutil.Big `json:"l1GasPrice"`
-	L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	GasToken   *fees.GasToken `json:"gasToken,omitempty"`
 }
 
 // GasPrices returns the L1 and L2 gas price known by the node
 func (api *PublicRollupAPI) GasPrices(ctx context.Context) (*gasPrices, error) {
-	l1GasPrice, err := api.b.SuggestL1GasPrice(ctx)
+	snapshot := api.b.FeeSnapshot()
+	prices := &gasPrices{
+		L1GasPrice: (*hexutil.Big)(snapshot.L1GasPrice),
+		L2GasPrice: (*hexutil.Big)(snapshot.L2GasPrice),
+	}
+	// Both gas prices are denominated in the native token, include the token
+	// when it is not ETH so that clients can interpret them
+	if gasToken := api.b.GasToken(); !gasToken.IsETH() {
+		prices.GasToken = gasToken
+	}
+	return prices, nil
+}
+
+// SuggestFeeTiers returns slow, standard and fast L1 gas prices to encode
+// the fee of a transaction with. Each tier comes with the share of recent
+// windows in which its fee would have covered the L1 gas price when the
+// sequencer checked it, for wallets that offer a choice of fees.
+func (api *PublicRollupAPI) SuggestFeeTiers(ctx context.Context) (*fees.FeeTiers, error) {
+	return fees.SuggestFeeTiers(api.b.L1GasPriceHistory(), api.b.FeeSnapshot().L2GasPrice)
+}
+
+// GetFeeQuote returns a fee quote signed by the sequencer. Transactions of the
+// sender that are submitted with the quote through SendRawTransactionWithQuote
+// pay the quoted gas prices until the quote expires, even if the gas prices
+// change in the meantime.
+func (api *PublicRollupAPI) GetFeeQuote(ctx context.Context, sender common.Address) (*feesig.FeeQuote, error) {
+	return api.b.IssueFeeQuote(ctx, sender)
+}
+
+// SendRawTransactionWithQuote submits a raw transaction whose fee is checked
+// against the gas prices of the fee quote instead of the current gas prices
+func (api *PublicRollupAPI) SendRawTransactionWithQuote(ctx context.Context, encodedTx hexutil.Bytes, quote feesig.FeeQuote) (common.Hash, error) {
+	return sendRawTransaction(feesig.WithFeeQuote(ctx, &quote), api.b, encodedTx)
+}
+
+// SendRawTransactionWithAuthorization submits a raw transaction with a zero
+// gas price whose fee is paid in a fee token with the EIP-3009 transfer
+// authorization. The sequencer executes the authorization before the
+// transaction.
+func (api *PublicRollupAPI) SendRawTransactionWithAuthorization(ctx context.Context, encodedTx hexutil.Bytes, auth feesig.TransferAuthorization) (common.Hash, error) {
+	return sendRawTransaction(feesig.WithTransferAuthorization(ctx, &auth), api.b, encodedTx)
+}
+
+// GetHistoricalL1Fee returns the L1 fee of a transaction recomputed with the
+// gas price oracle at the block that it was included in rather than the
+// current one. The gas price oracle is read from the fee params recorded
+// with the block, the state of blocks without them is only available on
+// archive nodes.
+func (api *PublicRollupAPI) GetHistoricalL1Fee(ctx context.Context, hash common.Hash) (*fees.HistoricalL1Fee, error) {
+	return api.b.HistoricalL1Fee(ctx, hash)
+}
+
+// GetTransactionReceipt returns the receipt of a transaction like
+// eth_getTransactionReceipt, with the rollup fields named as in the receipt
+// profile of the rollup namespace
+func (api *PublicRollupAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	return rpcMarshalReceipt(ctx, api.b, hash, api.b.ReceiptProfile("rollup"))
+}
+
+// GetBlockFeeParams returns the parameters of the gas price oracle that the
+// fees of a block were checked against, as they were recorded when the
+// block was imported
+func (api *PublicRollupAPI) GetBlockFeeParams(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*fees.BlockFeeParamsResult, error) {
+	header, err := api.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("block not found")
+	}
+	params := rawdb.ReadBlockFeeParams(api.b.ChainDb(), header.Hash(), header.Number.Uint64())
+	if params == nil {
+		return nil, fmt.Errorf("no fee params recorded for block %d", header.Number)
+	}
+	return params.Result(), nil
+}
+
+// maxL1FeeProjectionHorizon is the maximum number of minutes that
+// EstimateFutureL1Fee projects the L1 fee over
+const maxL1FeeProjectionHorizon = 24 * 60
+
+// EstimateFutureL1Fee returns the L1 fee of a raw transaction at the current
+// L1 gas price and at the L1 gas price projected horizon minutes ahead from
+// the trend of the recent L1 gas prices. The projected fee is meant for
+// quotes that must remain valid until they are settled.
+func (api *PublicRollupAPI) EstimateFutureL1Fee(ctx context.Context, encodedTx hexutil.Bytes, horizon hexutil.Uint64) (*fees.L1FeeProjection, error) {
+	if horizon > maxL1FeeProjectionHorizon {
+		return nil, fmt.Errorf("horizon too long: %d minutes, at most %d are allowed", horizon, maxL1FeeProjectionHorizon)
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return nil, fmt.Errorf("cannot decode transaction: %w", err)
+	}
+	calldataGas := api.b.FeeSnapshot().CalldataGas
+	return fees.EstimateFutureL1Fee(tx.L1GasUsedWith(calldataGas), api.b.L1GasPriceHistory(), uint64(horizon)*60)
+}
+
+// InclusionDeadline is the deadline that a transaction must be applied
+// within, in either blocks or seconds
+type InclusionDeadline struct {
+	Blocks  *hexutil.Uint64 `json:"blocks"`
+	Seconds *hexutil.Uint64 `json:"seconds"`
+}
+
+// GetInclusionHint returns the gas prices to encode the fee of a transaction
+// with so that it is applied within the deadline, from the transactions that
+// wait to be applied and the trend of the L1 gas price. Transactions that
+// cannot meet the deadline otherwise are told to pay the premium of the
+// express lane.
+func (api *PublicRollupAPI) GetInclusionHint(ctx context.Context, deadline InclusionDeadline) (*fees.InclusionHint, error) {
+	var blocks, seconds uint64
+	if deadline.Blocks != nil {
+		blocks = uint64(*deadline.Blocks)
+	}
+	if deadline.Seconds != nil {
+		seconds = uint64(*deadline.Seconds)
+	}
+	return api.b.InclusionHint(ctx, blocks, seconds)
+}
+
+// AdmissionStats sends the number of transactions that the sequencer
+// accepted and rejected, the mean fee that they paid and the lowest gas
+// prices that it accepts, aggregated over every second
+func (api *PublicRollupAPI) AdmissionStats(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		stats := make(chan *fees.AdmissionStats, 16)
+		statsSub := api.b.SubscribeAdmissionStats(stats)
+
+		for {
+			select {
+			case s := <-stats:
+				notifier.Notify(rpcSub.ID, s)
+			case <-rpcSub.Err():
+				statsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				statsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// DepositArgs is a deposit from L1, the message that the L1 sender enqueues
+// for the target on L2
+type DepositArgs struct {
+	From  common.Address  `json:"from"`
+	To    common.Address  `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+	Data  *hexutil.Bytes  `json:"data"`
+	Gas   *hexutil.Uint64 `json:"gas"`
+}
+
+// EstimateDepositFee returns the L2 gas limit that the message of a deposit
+// needs and the L1 cost of the enqueue transaction that submits it, so that
+// bridges can quote the total cost of a deposit. The L2 gas limit is
+// estimated by executing the message from the L1 sender against the pending
+// block, unless the gas is set.
+func (api *PublicRollupAPI) EstimateDepositFee(ctx context.Context, args DepositArgs) (*fees.DepositEstimate, error) {
+	return estimateDeposit(ctx, api.b, args, api.b.FeeSnapshot().L1GasPrice)
+}
+
+// estimateDeposit returns the cost of a deposit at the L1 gas price
+func estimateDeposit(ctx context.Context, b Backend, args DepositArgs, l1GasPrice *big.Int) (*fees.DepositEstimate, error) {
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	gas := args.Gas
+	if gas == nil {
+		call := CallArgs{From: &args.From, To: &args.To, Value: args.Value, Data: args.Data}
+		blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+		estimate, err := legacyDoEstimateGas(ctx, b, call, blockNrOrHash, b.RPCGasCap())
+		if err != nil {
+			return nil, err
+		}
+		gas = &estimate
+	}
+	return fees.NewDepositEstimate(data, uint64(*gas), l1GasPrice), nil
+}
+
+// WithdrawalArgs is a withdrawal to L1, the message that the L2 sender passes
+// to L1. Gas is the L1 gas of the execution of the message.
+type WithdrawalArgs struct {
+	From common.Address  `json:"from"`
+	Data *hexutil.Bytes  `json:"data"`
+	Gas  *hexutil.Uint64 `json:"gas"`
+}
+
+// EstimateWithdrawalCost returns the L1 gas and the L1 cost of proving and
+// finalizing a withdrawal at the current L1 gas price, so that wallets can
+// warn users about the cost of exiting before they initiate a withdrawal.
+// The proof is sized from the witnesses of a message sent by the sender in
+// the latest state.
+func (api *PublicRollupAPI) EstimateWithdrawalCost(ctx context.Context, args WithdrawalArgs) (*fees.WithdrawalEstimate, error) {
+	return estimateWithdrawal(ctx, api.b, args, api.b.FeeSnapshot().L1GasPrice)
+}
+
+// estimateWithdrawal returns the cost of a withdrawal at the L1 gas price
+func estimateWithdrawal(ctx context.Context, b Backend, args WithdrawalArgs, l1GasPrice *big.Int) (*fees.WithdrawalEstimate, error) {
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	var messageGas uint64
+	if args.Gas != nil {
+		messageGas = uint64(*args.Gas)
+	}
+	state, _, err := b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// The message passer records the hash of the message and the sender in
+	// the mapping of the sent messages
+	key := crypto.Keccak256(data, args.From.Bytes())
+	slot := crypto.Keccak256Hash(key, rcfg.SentMessagesSlot.Bytes())
+	accountProof, err := state.GetProof(rcfg.L2ToL1MessagePasserAddress)
+	if err != nil {
+		return nil, err
+	}
+	storageProof, err := state.GetStorageProof(rcfg.L2ToL1MessagePasserAddress, slot)
+	if err != nil {
+		return nil, err
+	}
+	var proof fees.WithdrawalProof
+	for _, node := range append(accountProof, storageProof...) {
+		proof.Nodes++
+		proof.Size += uint64(len(node))
+	}
+	return fees.NewWithdrawalEstimate(data, proof, messageGas, l1GasPrice), nil
+}
+
+// bridgeQuoteValidity is the number of seconds that bridge quotes are valid
+// for
+const bridgeQuoteValidity = 5 * 60
+
+// BridgeQuoteArgs is a round trip through the bridge, the L2 transaction is
+// optional
+type BridgeQuoteArgs struct {
+	Deposit    DepositArgs    `json:"deposit"`
+	Execution  *CallArgs      `json:"execution"`
+	Withdrawal WithdrawalArgs `json:"withdrawal"`
+}
+
+// GetBridgeQuote returns the cost of a round trip through the bridge with the
+// cost of the deposit, of the L2 transaction and of the withdrawal, for
+// exchanges and aggregators that quote the total cost of moving funds. The
+// quote is priced at the L1 gas price projected to the end of its validity
+// window from the trend of the recent L1 gas prices, so that it still covers
+// the cost when it expires.
+func (api *PublicRollupAPI) GetBridgeQuote(ctx context.Context, args BridgeQuoteArgs) (*fees.BridgeQuote, error) {
+	snapshot := api.b.FeeSnapshot()
+	l1GasPrice, err := fees.ProjectL1GasPrice(api.b.L1GasPriceHistory(), bridgeQuoteValidity)
+	if err != nil {
+		l1GasPrice = snapshot.L1GasPrice
+	}
+	deposit, err := estimateDeposit(ctx, api.b, args.Deposit, l1GasPrice)
+	if err != nil {
+		return nil, fmt.Errorf("cannot estimate deposit: %w", err)
+	}
+	var execution *fees.ExecutionEstimate
+	if args.Execution != nil {
+		blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+		gas, err := legacyDoEstimateGas(ctx, api.b, *args.Execution, blockNrOrHash, api.b.RPCGasCap())
+		if err != nil {
+			return nil, fmt.Errorf("cannot estimate execution: %w", err)
+		}
+		var data []byte
+		if args.Execution.Data != nil {
+			data = *args.Execution.Data
+		}
+		execution, err = fees.NewExecutionEstimate(snapshot.CalldataGas, data, uint64(gas), l1GasPrice, snapshot.L2GasPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+	withdrawal, err := estimateWithdrawal(ctx, api.b, args.Withdrawal, l1GasPrice)
+	if err != nil {
+		return nil, fmt.Errorf("cannot estimate withdrawal: %w", err)
+	}
+	quote := fees.NewBridgeQuote(deposit, execution, withdrawal, snapshot.L2GasPrice, uint64(time.Now().Unix()), bridgeQuoteValidity)
+	if oracle := api.b.ConversionRateOracle(); oracle != nil {
+		quote.Converted, err = fees.ConvertFee(ctx, oracle, quote.Total.ToInt())
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert quote: %w", err)
+		}
+	}
+	return quote, nil
+}
+
+// ConvertFee converts a fee in wei into the quote currency of the node, the
+// gas token on chains with a gas token unless a conversion feed is
+// configured
+func (api *PublicRollupAPI) ConvertFee(ctx context.Context, amount hexutil.Big) (*fees.ConvertedFee, error) {
+	return fees.ConvertFee(ctx, api.b.ConversionRateOracle(), amount.ToInt())
+}
+
+// maxFeeSimulationTxs is the maximum number of sample transactions accepted
+// by SimulateFees
+const maxFeeSimulationTxs = 10000
+
+type feeSimulation struct {
+	Current   *fees.FeeDistribution `json:"current"`
+	Simulated *fees.FeeDistribution `json:"simulated"`
+	// Insufficient are the indexes of the transactions whose senders cannot
+	// pay the simulated fee and value, only set with state overrides
+	Insufficient []int `json:"insufficient,omitempty"`
+}
+
+// SimulateFees returns the distribution of the fees that the sample raw
+// transactions pay under the current gas price oracle parameters and under
+// the hypothetical parameters. Gas prices that are not set in the
+// hypothetical parameters default to the current gas prices. With state
+// overrides the current L2 gas price is read from the gas price oracle in the
+// overridden latest state, and the balances of the senders are checked.
+func (api *PublicRollupAPI) SimulateFees(ctx context.Context, params fees.FeeParams, txs []hexutil.Bytes, overrides *StateOverride) (*feeSimulation, error) {
+	if len(txs) > maxFeeSimulationTxs {
+		return nil, fmt.Errorf("too many transactions: %d, at most %d are allowed", len(txs), maxFeeSimulationTxs)
+	}
+	decoded := make([]*types.Transaction, len(txs))
+	samples := make([]fees.SimulationTx, len(txs))
+	for i, encoded := range txs {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(encoded, tx); err != nil {
+			return nil, fmt.Errorf("cannot decode transaction %d: %w", i, err)
+		}
+		decoded[i] = tx
+		samples[i] = fees.SimulationTx{Data: tx.Data(), L2GasLimit: tx.L2Gas()}
+	}
+	snapshot := api.b.FeeSnapshot()
+	current := &fees.FeeParams{
+		L1GasPrice: (*hexutil.Big)(snapshot.L1GasPrice),
+		L2GasPrice: (*hexutil.Big)(snapshot.L2GasPrice),
+	}
+	var statedb *state.StateDB
+	if overrides != nil {
+		var err error
+		statedb, err = overriddenState(ctx, api.b, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), *overrides)
+		if err != nil {
+			return nil, err
+		}
+		slots, err := rcfg.ReadGPOStorageSlots(statedb)
+		if err != nil {
+			return nil, err
+		}
+		current.L2GasPrice = (*hexutil.Big)(slots.GasPrice)
+	}
+	if params.L1GasPrice == nil {
+		params.L1GasPrice = current.L1GasPrice
+	}
+	if params.L2GasPrice == nil {
+		params.L2GasPrice = current.L2GasPrice
+	}
+	simulation := &feeSimulation{
+		Current:   fees.Simulate(current, samples),
+		Simulated: fees.Simulate(&params, samples),
+	}
+	if statedb != nil {
+		signer := types.NewEIP155Signer(api.b.ChainConfig().ChainID)
+		for i, tx := range decoded {
+			from, err := types.Sender(signer, tx)
+			if err != nil {
+				continue
+			}
+			cost := new(big.Int).Add(simulation.Simulated.Fees[i].ToInt(), tx.Value())
+			if statedb.GetBalance(from).Cmp(cost) < 0 {
+				simulation.Insufficient = append(simulation.Insufficient, i)
+			}
+		}
+	}
+	return simulation, nil
+}
+
+// overriddenState returns the state at the block with the state overrides
+// applied
+func overriddenState(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, overrides StateOverride) (*state.StateDB, error) {
+	statedb, _, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(statedb); err != nil {
+		return nil, err
+	}
+	return statedb, nil
+}
+
+// l1FeeResult is the fee of a transaction at a block with state overrides
+type l1FeeResult struct {
+	L1GasUsed  hexutil.Uint64 `json:"l1GasUsed"`
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+	L1Fee      *hexutil.Big   `json:"l1Fee"`
+	L2GasLimit hexutil.Uint64 `json:"l2GasLimit"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	// Fee is the L1 and L2 fee after the subsidies, which the gas limit of
+	// the transaction encodes
+	Fee     *hexutil.Big   `json:"fee"`
+	Sender  common.Address `json:"sender"`
+	Balance *hexutil.Big   `json:"balance"`
+	// Sufficient is whether the balance of the sender pays for the fee and
+	// the value of the transaction
+	Sufficient bool `json:"sufficient"`
+}
+
+// GetL1Fee returns the fee of a raw transaction at the block, the latest one
+// by default, after the state overrides are applied. The L2 gas price is
+// read from the gas price oracle in the overridden state so that its slots
+// can be overridden, the L1 gas price is the current one as it is not part
+// of the state. The fee is priced by the backend the same way as the
+// sequencer prices the transactions that it accepts, after the fee subsidies
+// and the fee policies.
+func (api *PublicRollupAPI) GetL1Fee(ctx context.Context, encodedTx hexutil.Bytes, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride) (*l1FeeResult, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return nil, fmt.Errorf("cannot decode transaction: %w", err)
+	}
+	from, err := types.Sender(types.NewEIP155Signer(api.b.ChainConfig().ChainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	var diff StateOverride
+	if overrides != nil {
+		diff = *overrides
+	}
+	statedb, err := overriddenState(ctx, api.b, bNrOrHash, diff)
 	if err != nil {
 		return nil, err
 	}
-	l2GasPrice, err := api.b.SuggestL2GasPrice(ctx)
+	slots, err := rcfg.ReadGPOStorageSlots(statedb)
 	if err != nil {
 		return nil, err
 	}
-	return &gasPrices{
-		L1GasPrice: (*hexutil.Big)(l1GasPrice),
-		L2GasPrice: (*hexutil.Big)(l2GasPrice),
+	snapshot := *api.b.FeeSnapshot()
+	snapshot.L2GasPrice = slots.GasPrice
+	estimate, err := api.b.EstimateTxFee(ctx, tx, new(big.Int).SetUint64(tx.L2Gas()), &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	l1GasUsed := tx.L1GasUsedWith(snapshot.CalldataGas)
+	l1Fee, fee := estimate.L1Fee, estimate.Fee
+	balance := statedb.GetBalance(from)
+	return &l1FeeResult{
+		L1GasUsed:  hexutil.Uint64(l1GasUsed),
+		L1GasPrice: (*hexutil.Big)(snapshot.L1GasPrice),
+		L1Fee:      (*hexutil.Big)(l1Fee),
+		L2GasLimit: hexutil.Uint64(tx.L2Gas()),
+		L2GasPrice: (*hexutil.Big)(slots.GasPrice),
+		Fee:        (*hexutil.Big)(fee),
+		Sender:     from,
+		Balance:    (*hexutil.Big)(balance),
+		Sufficient: balance.Cmp(new(big.Int).Add(fee, tx.Value())) >= 0,
 	}, nil
 }
 
@@ -1959,6 +2622,164 @@ func (api *PrivateRollupAPI) SetL2GasPrice(ctx context.Context, gasPrice hexutil
 	return api.b.SetL2GasPrice(ctx, (*big.Int)(&gasPrice))
 }
 
+// GetFeeReconciliation returns the fees collected for the most recently
+// reconciled transaction batches along with the L1 cost of submitting them.
+// All of the kept batches are returned when count is not set.
+func (api *PrivateRollupAPI) GetFeeReconciliation(ctx context.Context, count *int) []*fees.Reconciliation {
+	n := 0
+	if count != nil {
+		n = *count
+	}
+	return api.b.FeeReconciliation(n)
+}
+
+// GetFeeQuoteConsumption returns the number of transactions and the fees that
+// the sequencer accepted with the fee quote of the hash, null when it has not
+// accepted any transactions with the quote
+func (api *PrivateRollupAPI) GetFeeQuoteConsumption(ctx context.Context, hash common.Hash) *feesig.FeeQuoteConsumption {
+	return api.b.FeeQuoteConsumption(hash)
+}
+
+// GetInclusionHintAccuracy returns how often the L1 gas price of inclusion
+// hints covered the L1 gas price until their deadline
+func (api *PrivateRollupAPI) GetInclusionHintAccuracy(ctx context.Context) *fees.InclusionAccuracy {
+	return api.b.InclusionHintAccuracy()
+}
+
+// GetFeeThresholdControl returns the fee threshold down that is tuned from
+// the false rejections along with the recent decisions of its controller,
+// null when it is not tuned from the false rejections
+func (api *PrivateRollupAPI) GetFeeThresholdControl(ctx context.Context) *fees.ThresholdControl {
+	return api.b.FeeThresholdControl()
+}
+
+// ReplayFees recomputes the fees of the transactions in a historical block
+// from the archived state of its parent and the L1 submission of its batch,
+// and returns a report signed with the attestation key of the node
+func (api *PrivateRollupAPI) ReplayFees(ctx context.Context, number hexutil.Uint64) (*feesig.FeeAttestation, error) {
+	return api.b.ReplayFees(ctx, uint64(number))
+}
+
+// maxFeeStatsBlocks is the maximum number of blocks that GetFeeStats
+// aggregates in a single call
+const maxFeeStatsBlocks = 1_000_000
+
+// FeeStatsArgs selects the blocks that fee statistics are computed over,
+// either by block number or by timestamp. Interval splits the range into
+// buckets of that many seconds and Format is either json or csv.
+type FeeStatsArgs struct {
+	FromBlock     *hexutil.Uint64 `json:"fromBlock"`
+	ToBlock       *hexutil.Uint64 `json:"toBlock"`
+	FromTimestamp *hexutil.Uint64 `json:"fromTimestamp"`
+	ToTimestamp   *hexutil.Uint64 `json:"toTimestamp"`
+	Interval      hexutil.Uint64  `json:"interval"`
+	Format        string          `json:"format"`
+}
+
+// GetFeeStats aggregates the fee components that were recorded when blocks
+// were imported over a range of blocks. The statistics are returned as JSON
+// or as a CSV string.
+func (api *PrivateRollupAPI) GetFeeStats(ctx context.Context, args FeeStatsArgs) (interface{}, error) {
+	if args.Format != "" && args.Format != "json" && args.Format != "csv" {
+		return nil, fmt.Errorf("unknown format: %s", args.Format)
+	}
+	head := api.b.CurrentBlock().NumberU64()
+	from, to := uint64(1), head
+	if args.FromBlock != nil {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil {
+		to = uint64(*args.ToBlock)
+	}
+	var err error
+	if args.FromTimestamp != nil {
+		if from, err = api.blockAtTimestamp(ctx, uint64(*args.FromTimestamp), head); err != nil {
+			return nil, err
+		}
+	}
+	if args.ToTimestamp != nil {
+		// The last block of the range is the one before the first block
+		// after the timestamp
+		next, err := api.blockAtTimestamp(ctx, uint64(*args.ToTimestamp)+1, head)
+		if err != nil {
+			return nil, err
+		}
+		to = next - 1
+	}
+	if to > head {
+		to = head
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: %d to %d", from, to)
+	}
+	if to-from+1 > maxFeeStatsBlocks {
+		return nil, fmt.Errorf("block range too large: %d blocks, at most %d are allowed", to-from+1, maxFeeStatsBlocks)
+	}
+
+	blocks := make([]*fees.BlockFees, 0, to-from+1)
+	db := api.b.ChainDb()
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blockFees := rawdb.ReadBlockFees(db, number)
+		if blockFees == nil {
+			return nil, fmt.Errorf("no fees recorded for block %d", number)
+		}
+		blocks = append(blocks, blockFees)
+	}
+	stats := fees.AggregateFeesByInterval(blocks, uint64(args.Interval))
+	if args.Format == "csv" {
+		var out bytes.Buffer
+		if err := fees.WriteFeeStatsCSV(&out, stats); err != nil {
+			return nil, err
+		}
+		return out.String(), nil
+	}
+	return stats, nil
+}
+
+// maxBlockFees is the maximum number of blocks that GetBlockFees returns in
+// a single call
+const maxBlockFees = 10_000
+
+// GetBlockFees returns the fee components that were recorded when blocks
+// were imported for up to count blocks from a block, stopping at the head
+func (api *PrivateRollupAPI) GetBlockFees(ctx context.Context, from hexutil.Uint64, count hexutil.Uint64) ([]*fees.BlockFeesResult, error) {
+	if count > maxBlockFees {
+		return nil, fmt.Errorf("too many blocks: %d, at most %d are allowed", count, maxBlockFees)
+	}
+	head := api.b.CurrentBlock().NumberU64()
+	results := make([]*fees.BlockFeesResult, 0, count)
+	db := api.b.ChainDb()
+	for number := uint64(from); number < uint64(from+count) && number <= head; number++ {
+		blockFees := rawdb.ReadBlockFees(db, number)
+		if blockFees == nil {
+			return nil, fmt.Errorf("no fees recorded for block %d", number)
+		}
+		results = append(results, blockFees.Result())
+	}
+	return results, nil
+}
+
+// blockAtTimestamp returns the number of the first block with a timestamp at
+// or after the given timestamp, or the block after head if there is none
+func (api *PrivateRollupAPI) blockAtTimestamp(ctx context.Context, timestamp, head uint64) (uint64, error) {
+	var err error
+	n := sort.Search(int(head)+1, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		header, herr := api.b.HeaderByNumber(ctx, rpc.BlockNumber(i))
+		if herr != nil || header == nil {
+			err = fmt.Errorf("cannot get header %d: %v", i, herr)
+			return true
+		}
+		return header.Time >= timestamp
+	})
+	return uint64(n), err
+}
+
 // PublicDebugAPI is the collection of Ethereum APIs exposed over the public
 // debugging endpoint.
 type PublicDebugAPI struct {
@@ -0,0 +1,458 @@
+package ethapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr     = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance  = big.NewInt(params.Ether)
+	testReverter = common.Address{0xfd}
+
+	testL1GasPrice = big.NewInt(20 * params.GWei)
+	testL2GasPrice = big.NewInt(params.GWei)
+)
+
+// testBackend is a backend over a chain that only serves the calls and the
+// fees, the methods that are not overridden panic
+type testBackend struct {
+	Backend
+	chain    *core.BlockChain
+	snapshot *fees.OracleSnapshot
+	feeErr   error
+}
+
+// newTestBackend returns a backend over a chain with a funded account, a
+// contract that always reverts and the legacy gas price oracle storage
+func newTestBackend(t *testing.T) *testBackend {
+	db := rawdb.NewMemoryDatabase()
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			testAddr: {Balance: testBalance},
+			// PUSH1 0 PUSH1 0 REVERT
+			testReverter: {Balance: new(big.Int), Code: common.FromHex("0x60006000fd")},
+			rcfg.L2GasPriceOracleAddress: {
+				Balance: new(big.Int),
+				Storage: map[common.Hash]common.Hash{rcfg.L2GasPriceSlot: common.BigToHash(testL2GasPrice)},
+			},
+		},
+	}
+	genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(chain.Stop)
+	return &testBackend{
+		chain: chain,
+		snapshot: &fees.OracleSnapshot{
+			L1GasPrice:  testL1GasPrice,
+			L2GasPrice:  testL2GasPrice,
+			CalldataGas: fees.DefaultCalldataGas,
+		},
+	}
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+func (b *testBackend) RPCGasCap() *big.Int              { return nil }
+
+func (b *testBackend) FeeSnapshot() *fees.OracleSnapshot { return b.snapshot }
+
+func (b *testBackend) EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, snapshot *fees.OracleSnapshot) (*fees.TxFeeEstimate, error) {
+	if b.feeErr != nil {
+		return nil, b.feeErr
+	}
+	return fees.EstimateTxFee(ctx, fees.CalldataDACost{}, snapshot, tx.Data(), l2GasLimit)
+}
+
+func (b *testBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	block, err := b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (b *testBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	if number, ok := blockNrOrHash.Number(); ok {
+		if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+			return b.chain.CurrentBlock(), nil
+		}
+		return b.chain.GetBlockByNumber(uint64(number)), nil
+	}
+	hash, _ := blockNrOrHash.Hash()
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	header, err := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header) (*vm.EVM, func() error, error) {
+	state.SetBalance(msg.From(), math.MaxBig256)
+	context := core.NewEVMContext(msg, header, b.chain, nil)
+	return vm.NewEVM(context, state, b.chain.Config(), vm.Config{}), func() error { return nil }, nil
+}
+
+// expectCallL1Fee returns the L1 fee of a call with the data that uses the
+// L2 gas at the gas prices of the test backend
+func expectCallL1Fee(data []byte, l2GasUsed uint64) *CallL1Fee {
+	l1GasUsed := fees.DefaultCalldataGas.CalculateL1GasUsed(data)
+	l1Fee := new(big.Int).Mul(l1GasUsed, testL1GasPrice)
+	gasLimit := fees.EncodeTxGasLimitForL1Fee(l1Fee, new(big.Int).SetUint64(l2GasUsed), testL2GasPrice)
+	return &CallL1Fee{
+		L1GasUsed:  hexutil.Uint64(l1GasUsed.Uint64()),
+		L1GasPrice: (*hexutil.Big)(testL1GasPrice),
+		L2GasPrice: (*hexutil.Big)(testL2GasPrice),
+		L1Fee:      (*hexutil.Big)(l1Fee),
+		MaxL1Fee:   (*hexutil.Big)(fees.MaxChargedL1Fee(gasLimit.Uint64(), testL2GasPrice)),
+	}
+}
+
+func checkCallL1Fee(t *testing.T, got, expect *CallL1Fee) {
+	t.Helper()
+	if got == nil {
+		t.Fatal("missing L1 fee")
+	}
+	if got.L1GasUsed != expect.L1GasUsed {
+		t.Fatalf("mismatched L1 gas used: got %d, expect %d", got.L1GasUsed, expect.L1GasUsed)
+	}
+	for name, pair := range map[string][2]*hexutil.Big{
+		"L1 gas price": {got.L1GasPrice, expect.L1GasPrice},
+		"L2 gas price": {got.L2GasPrice, expect.L2GasPrice},
+		"L1 fee":       {got.L1Fee, expect.L1Fee},
+		"max L1 fee":   {got.MaxL1Fee, expect.MaxL1Fee},
+	} {
+		if pair[0].ToInt().Cmp(pair[1].ToInt()) != 0 {
+			t.Fatalf("mismatched %s: got %s, expect %s", name, pair[0], pair[1])
+		}
+	}
+}
+
+func TestCallL1Fee(t *testing.T) {
+	api := NewPublicBlockChainAPI(newTestBackend(t))
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	data := hexutil.Bytes{0x00, 0x01, 0x02}
+	args := CallArgs{From: &testAddr, To: &common.Address{1}, Data: &data}
+	l2GasUsed := params.TxGas + params.TxDataZeroGas + 2*params.TxDataNonZeroGasEIP2028
+
+	// Without the option only the result of the call is returned
+	result, err := api.Call(context.Background(), args, latest, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.(hexutil.Bytes); !ok {
+		t.Fatalf("mismatched result type: %T", result)
+	}
+	result, err = api.Call(context.Background(), args, latest, nil, &CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.(hexutil.Bytes); !ok {
+		t.Fatalf("mismatched result type: %T", result)
+	}
+
+	result, err = api.Call(context.Background(), args, latest, nil, &CallOptions{L1Fee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, ok := result.(*CallResult)
+	if !ok {
+		t.Fatalf("mismatched result type: %T", result)
+	}
+	if uint64(call.GasUsed) != l2GasUsed {
+		t.Fatalf("mismatched gas used: got %d, expect %d", call.GasUsed, l2GasUsed)
+	}
+	if len(call.Result) != 0 {
+		t.Fatalf("unexpected result: %x", call.Result)
+	}
+	checkCallL1Fee(t, call.Fee, expectCallL1Fee(data, l2GasUsed))
+}
+
+func TestCallL1FeeErrors(t *testing.T) {
+	b := newTestBackend(t)
+	api := NewPublicBlockChainAPI(b)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	opts := &CallOptions{L1Fee: true}
+
+	// A reverted call is not priced
+	result, err := api.Call(context.Background(), CallArgs{From: &testAddr, To: &testReverter}, latest, nil, opts)
+	if err == nil || err.Error() != "execution reverted" {
+		t.Fatalf("mismatched revert error: %v", err)
+	}
+	if _, ok := result.(hexutil.Bytes); !ok {
+		t.Fatalf("mismatched result type: %T", result)
+	}
+
+	// The state and the state diff of an account cannot be overridden together
+	storage := map[common.Hash]common.Hash{}
+	overrides := map[common.Address]account{
+		common.Address{1}: {State: &storage, StateDiff: &storage},
+	}
+	if _, err := api.Call(context.Background(), CallArgs{From: &testAddr, To: &common.Address{1}}, latest, &overrides, opts); err == nil {
+		t.Fatal("expected error for conflicting state overrides")
+	}
+
+	// The errors of the fee estimation are returned instead of the result
+	b.feeErr = errors.New("no fee")
+	if _, err := api.Call(context.Background(), CallArgs{From: &testAddr, To: &common.Address{1}}, latest, nil, opts); err != b.feeErr {
+		t.Fatalf("mismatched fee error: got %v, expect %v", err, b.feeErr)
+	}
+	if _, err := api.Call(context.Background(), CallArgs{From: &testAddr, To: &common.Address{1}}, latest, nil, nil); err != nil {
+		t.Fatalf("unexpected error without the L1 fee: %v", err)
+	}
+}
+
+func TestEstimateGasL1Fee(t *testing.T) {
+	api := NewPublicBlockChainAPI(newTestBackend(t))
+	data := hexutil.Bytes{0x00, 0x01, 0x02}
+	args := CallArgs{From: &testAddr, To: &common.Address{1}, Data: &data}
+	l2GasUsed := params.TxGas + params.TxDataZeroGas + 2*params.TxDataNonZeroGasEIP2028
+	expect := expectCallL1Fee(data, l2GasUsed)
+	gasLimit := fees.EncodeTxGasLimitForL1Fee(expect.L1Fee.ToInt(), new(big.Int).SetUint64(l2GasUsed), testL2GasPrice).Uint64()
+
+	result, err := api.EstimateGas(context.Background(), args, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gas, ok := result.(hexutil.Uint64); !ok || uint64(gas) != gasLimit {
+		t.Fatalf("mismatched estimate: got %v, expect %d", result, gasLimit)
+	}
+
+	result, err = api.EstimateGas(context.Background(), args, &CallOptions{L1Fee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimate, ok := result.(*EstimateGasResult)
+	if !ok {
+		t.Fatalf("mismatched result type: %T", result)
+	}
+	if uint64(estimate.Gas) != gasLimit {
+		t.Fatalf("mismatched estimate: got %d, expect %d", estimate.Gas, gasLimit)
+	}
+	checkCallL1Fee(t, estimate.Fee, expect)
+}
+
+func TestEstimateGasL1FeeErrors(t *testing.T) {
+	b := newTestBackend(t)
+	api := NewPublicBlockChainAPI(b)
+	opts := &CallOptions{L1Fee: true}
+
+	if _, err := api.EstimateGas(context.Background(), CallArgs{From: &testAddr, To: &testReverter}, opts); err == nil {
+		t.Fatal("expected error for reverting call")
+	}
+	b.feeErr = errors.New("no fee")
+	for _, opts := range []*CallOptions{nil, opts} {
+		if _, err := api.EstimateGas(context.Background(), CallArgs{From: &testAddr, To: &common.Address{1}}, opts); err != b.feeErr {
+			t.Fatalf("mismatched fee error: got %v, expect %v", err, b.feeErr)
+		}
+	}
+}
+
+// signTestTx returns the encoding of a transaction of the key with the data
+func signTestTx(t *testing.T, key *ecdsa.PrivateKey, data []byte) (*types.Transaction, hexutil.Bytes) {
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{1}, big.NewInt(1), 1_000_000, fees.BigTxGasPrice, data), types.NewEIP155Signer(params.TestChainConfig.ChainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, encoded
+}
+
+func TestGetL1FeeOverrides(t *testing.T) {
+	api := NewPublicRollupAPI(newTestBackend(t))
+	tx, encoded := signTestTx(t, testKey, []byte{0x00, 0x01, 0x02})
+	l2GasPrice := big.NewInt(10 * params.GWei)
+	zero := new(hexutil.Big)
+
+	tests := map[string]struct {
+		overrides  *StateOverride
+		l2GasPrice *big.Int
+		balance    *big.Int
+		sufficient bool
+	}{
+		"none": {nil, testL2GasPrice, testBalance, true},
+		"l2-gas-price": {
+			&StateOverride{rcfg.L2GasPriceOracleAddress: {StateDiff: &map[common.Hash]common.Hash{rcfg.L2GasPriceSlot: common.BigToHash(l2GasPrice)}}},
+			l2GasPrice, testBalance, true,
+		},
+		"balance": {
+			&StateOverride{testAddr: {Balance: &zero}},
+			testL2GasPrice, new(big.Int), false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := api.GetL1Fee(context.Background(), encoded, nil, tt.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			snapshot := fees.OracleSnapshot{L1GasPrice: testL1GasPrice, L2GasPrice: tt.l2GasPrice, CalldataGas: fees.DefaultCalldataGas}
+			expect, err := fees.EstimateTxFee(context.Background(), fees.CalldataDACost{}, &snapshot, tx.Data(), new(big.Int).SetUint64(tx.L2Gas()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.L2GasPrice.ToInt().Cmp(tt.l2GasPrice) != 0 {
+				t.Fatalf("mismatched L2 gas price: got %s, expect %d", result.L2GasPrice, tt.l2GasPrice)
+			}
+			if result.L1Fee.ToInt().Cmp(expect.L1Fee) != 0 {
+				t.Fatalf("mismatched L1 fee: got %s, expect %d", result.L1Fee, expect.L1Fee)
+			}
+			if result.Fee.ToInt().Cmp(expect.Fee) != 0 {
+				t.Fatalf("mismatched fee: got %s, expect %d", result.Fee, expect.Fee)
+			}
+			if result.Sender != testAddr {
+				t.Fatalf("mismatched sender: got %s, expect %s", result.Sender.Hex(), testAddr.Hex())
+			}
+			if result.Balance.ToInt().Cmp(tt.balance) != 0 {
+				t.Fatalf("mismatched balance: got %s, expect %d", result.Balance, tt.balance)
+			}
+			if result.Sufficient != tt.sufficient {
+				t.Fatalf("mismatched sufficient: got %t, expect %t", result.Sufficient, tt.sufficient)
+			}
+		})
+	}
+}
+
+func TestGetL1FeeOverridesErrors(t *testing.T) {
+	api := NewPublicRollupAPI(newTestBackend(t))
+	_, encoded := signTestTx(t, testKey, nil)
+	unsigned, err := rlp.EncodeToBytes(types.NewTransaction(0, common.Address{1}, new(big.Int), 1_000_000, fees.BigTxGasPrice, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := map[common.Hash]common.Hash{}
+	unknown := rpc.BlockNumberOrHashWithHash(common.Hash{1}, false)
+
+	tests := map[string]struct {
+		encoded   hexutil.Bytes
+		block     *rpc.BlockNumberOrHash
+		overrides *StateOverride
+	}{
+		"bad-rlp":  {hexutil.Bytes{0x01}, nil, nil},
+		"unsigned": {unsigned, nil, nil},
+		"unknown-layout": {encoded, nil, &StateOverride{
+			rcfg.L2GasPriceOracleAddress: {StateDiff: &map[common.Hash]common.Hash{rcfg.L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(99))}},
+		}},
+		"state-and-diff": {encoded, nil, &StateOverride{testAddr: {State: &storage, StateDiff: &storage}}},
+		"unknown-block":  {encoded, &unknown, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := api.GetL1Fee(context.Background(), tt.encoded, tt.block, tt.overrides); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	_, err = api.GetL1Fee(context.Background(), encoded, nil, tests["unknown-layout"].overrides)
+	if !errors.Is(err, rcfg.ErrUnknownLayout) {
+		t.Fatalf("mismatched layout error: got %v, expect %v", err, rcfg.ErrUnknownLayout)
+	}
+}
+
+func TestSimulateFeesOverrides(t *testing.T) {
+	api := NewPublicRollupAPI(newTestBackend(t))
+	unfunded, _ := crypto.GenerateKey()
+	funded, fundedTx := signTestTx(t, testKey, []byte{0x01})
+	underfunded, underfundedTx := signTestTx(t, unfunded, []byte{0x01, 0x02})
+	samples := []fees.SimulationTx{
+		{Data: funded.Data(), L2GasLimit: funded.L2Gas()},
+		{Data: underfunded.Data(), L2GasLimit: underfunded.L2Gas()},
+	}
+	txs := []hexutil.Bytes{fundedTx, underfundedTx}
+	l2GasPrice := big.NewInt(10 * params.GWei)
+	zero := new(hexutil.Big)
+
+	tests := map[string]struct {
+		overrides    *StateOverride
+		l2GasPrice   *big.Int
+		insufficient []int
+	}{
+		"none": {nil, testL2GasPrice, nil},
+		"l2-gas-price": {
+			&StateOverride{rcfg.L2GasPriceOracleAddress: {StateDiff: &map[common.Hash]common.Hash{rcfg.L2GasPriceSlot: common.BigToHash(l2GasPrice)}}},
+			l2GasPrice, []int{1},
+		},
+		"empty":   {&StateOverride{}, testL2GasPrice, []int{1}},
+		"balance": {&StateOverride{testAddr: {Balance: &zero}}, testL2GasPrice, []int{0, 1}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			simulation, err := api.SimulateFees(context.Background(), fees.FeeParams{}, txs, tt.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := fees.Simulate(&fees.FeeParams{L1GasPrice: (*hexutil.Big)(testL1GasPrice), L2GasPrice: (*hexutil.Big)(tt.l2GasPrice)}, samples)
+			for i, fee := range expect.Fees {
+				if simulation.Current.Fees[i].ToInt().Cmp(fee.ToInt()) != 0 {
+					t.Fatalf("mismatched current fee %d: got %s, expect %s", i, simulation.Current.Fees[i], fee)
+				}
+				if simulation.Simulated.Fees[i].ToInt().Cmp(fee.ToInt()) != 0 {
+					t.Fatalf("mismatched simulated fee %d: got %s, expect %s", i, simulation.Simulated.Fees[i], fee)
+				}
+			}
+			if !reflect.DeepEqual(simulation.Insufficient, tt.insufficient) {
+				t.Fatalf("mismatched insufficient: got %v, expect %v", simulation.Insufficient, tt.insufficient)
+			}
+		})
+	}
+}
+
+func TestSimulateFeesOverridesErrors(t *testing.T) {
+	api := NewPublicRollupAPI(newTestBackend(t))
+	_, encoded := signTestTx(t, testKey, nil)
+	storage := map[common.Hash]common.Hash{}
+
+	tests := map[string]struct {
+		txs       []hexutil.Bytes
+		overrides *StateOverride
+	}{
+		"too-many": {make([]hexutil.Bytes, maxFeeSimulationTxs+1), nil},
+		"bad-rlp":  {[]hexutil.Bytes{encoded, {0x01}}, nil},
+		"unknown-layout": {[]hexutil.Bytes{encoded}, &StateOverride{
+			rcfg.L2GasPriceOracleAddress: {StateDiff: &map[common.Hash]common.Hash{rcfg.L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(99))}},
+		}},
+		"state-and-diff": {[]hexutil.Bytes{encoded}, &StateOverride{testAddr: {State: &storage, StateDiff: &storage}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := api.SimulateFees(context.Background(), fees.FeeParams{}, tt.txs, tt.overrides); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -32,6 +32,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -93,6 +95,32 @@ type Backend interface {
 	GasLimit() uint64
 	SuggestL1GasPrice(ctx context.Context) (*big.Int, error)
 	SetL1GasPrice(context.Context, *big.Int) error
+	GasToken() *fees.GasToken
+	// ReceiptProfile returns the names and presence of the rollup fields of
+	// the receipts served by the RPC namespace
+	ReceiptProfile(namespace string) *fees.ReceiptProfile
+	L1GasPriceHistory() []fees.L1GasPriceSample
+	// FeeSnapshot returns the gas prices and the calldata gas schedule of
+	// the latest block, read together
+	FeeSnapshot() *fees.OracleSnapshot
+	// PoolTxFee checks the fee of a transaction of the pool at the latest
+	// gas prices, nil when the node does not check fees
+	PoolTxFee(tx *types.Transaction) *fees.PoolTxFee
+	// EstimateTxFee prices a transaction at the gas prices of the snapshot
+	// the same way that the sequencer prices the transactions it accepts
+	EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, snapshot *fees.OracleSnapshot) (*fees.TxFeeEstimate, error)
+	// ConversionRateOracle returns the oracle that fees are converted into
+	// the quote currency with, nil when fees are only quoted in wei
+	ConversionRateOracle() fees.ConversionRateOracle
+	FeeReconciliation(count int) []*fees.Reconciliation
+	IssueFeeQuote(ctx context.Context, sender common.Address) (*feesig.FeeQuote, error)
+	FeeQuoteConsumption(hash common.Hash) *feesig.FeeQuoteConsumption
+	InclusionHint(ctx context.Context, blocks, seconds uint64) (*fees.InclusionHint, error)
+	InclusionHintAccuracy() *fees.InclusionAccuracy
+	FeeThresholdControl() *fees.ThresholdControl
+	SubscribeAdmissionStats(ch chan<- *fees.AdmissionStats) event.Subscription
+	ReplayFees(ctx context.Context, number uint64) (*feesig.FeeAttestation, error)
+	HistoricalL1Fee(ctx context.Context, hash common.Hash) (*fees.HistoricalL1Fee, error)
 	SuggestL2GasPrice(context.Context) (*big.Int, error)
 	SetL2GasPrice(context.Context, *big.Int) error
 	IngestTransactions([]*types.Transaction) error
@@ -180,6 +180,16 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'rollupPnL',
+			call: 'admin_rollupPnL',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'exportRollupPnL',
+			call: 'admin_exportRollupPnL',
+			params: 3
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
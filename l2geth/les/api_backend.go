@@ -36,6 +36,8 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -286,6 +288,75 @@ func (b *LesApiBackend) SuggestL2GasPrice(ctx context.Context) (*big.Int, error)
 	panic("SuggestL2GasPrice not implemented")
 }
 
+// NB: Non sequencer nodes do not know the gas token of the chain.
+func (b *LesApiBackend) GasToken() *fees.GasToken {
+	panic("GasToken not implemented")
+}
+
+func (b *LesApiBackend) ReceiptProfile(namespace string) *fees.ReceiptProfile {
+	return b.eth.config.Rollup.ReceiptProfiles.Profile(namespace)
+}
+
+func (b *LesApiBackend) L1GasPriceHistory() []fees.L1GasPriceSample {
+	panic("L1GasPriceHistory not implemented")
+}
+
+func (b *LesApiBackend) ConversionRateOracle() fees.ConversionRateOracle {
+	panic("ConversionRateOracle not implemented")
+}
+
+func (b *LesApiBackend) FeeReconciliation(count int) []*fees.Reconciliation {
+	panic("FeeReconciliation not implemented")
+}
+
+func (b *LesApiBackend) IssueFeeQuote(ctx context.Context, sender common.Address) (*feesig.FeeQuote, error) {
+	panic("IssueFeeQuote not implemented")
+}
+
+func (b *LesApiBackend) FeeQuoteConsumption(hash common.Hash) *feesig.FeeQuoteConsumption {
+	panic("FeeQuoteConsumption not implemented")
+}
+
+func (b *LesApiBackend) InclusionHint(ctx context.Context, blocks, seconds uint64) (*fees.InclusionHint, error) {
+	panic("InclusionHint not implemented")
+}
+
+func (b *LesApiBackend) InclusionHintAccuracy() *fees.InclusionAccuracy {
+	panic("InclusionHintAccuracy not implemented")
+}
+
+func (b *LesApiBackend) FeeThresholdControl() *fees.ThresholdControl {
+	panic("FeeThresholdControl not implemented")
+}
+
+func (b *LesApiBackend) SubscribeAdmissionStats(ch chan<- *fees.AdmissionStats) event.Subscription {
+	panic("SubscribeAdmissionStats not implemented")
+}
+
+func (b *LesApiBackend) FeeSnapshot() *fees.OracleSnapshot {
+	panic("FeeSnapshot not implemented")
+}
+
+// Light clients do not check fees, so the fees of the transactions in their
+// pool are not reported
+func (b *LesApiBackend) PoolTxFee(tx *types.Transaction) *fees.PoolTxFee {
+	return nil
+}
+
+// Light clients do not know the data availability layer of the sequencer, so
+// transactions are priced as calldata on L1
+func (b *LesApiBackend) EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, snapshot *fees.OracleSnapshot) (*fees.TxFeeEstimate, error) {
+	return fees.EstimateTxFee(ctx, fees.CalldataDACost{}, snapshot, tx.Data(), l2GasLimit)
+}
+
+func (b *LesApiBackend) ReplayFees(ctx context.Context, number uint64) (*feesig.FeeAttestation, error) {
+	panic("ReplayFees not implemented")
+}
+
+func (b *LesApiBackend) HistoricalL1Fee(ctx context.Context, hash common.Hash) (*fees.HistoricalL1Fee, error) {
+	panic("HistoricalL1Fee not implemented")
+}
+
 // NB: Non sequencer nodes cannot set L1 gas prices.
 func (b *LesApiBackend) SetL1GasPrice(ctx context.Context, gasPrice *big.Int) error {
 	panic("SetDataPrice is not implemented")
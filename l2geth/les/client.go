@@ -43,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -79,6 +80,11 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 		return nil, genesisErr
 	}
 	log.Info("Initialised chain configuration", "config", chainConfig)
+	rcfg.Configure(chainConfig)
+	if chainConfig.GasPriceOracle != nil {
+		log.Info("Configured gas price oracle", "address", rcfg.L2GasPriceOracleAddress.Hex(),
+			"owner-slot", rcfg.L2GasPriceOracleOwnerSlot.Hex(), "gas-price-slot", rcfg.L2GasPriceSlot.Hex())
+	}
 
 	peers := newPeerSet()
 	leth := &LightEthereum{
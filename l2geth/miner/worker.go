@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
 )
 
 const (
@@ -91,12 +92,15 @@ type environment struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+
+	feeParams *fees.BlockFeeParams // gas price oracle params of the parent state
 }
 
 // task contains all information for consensus engine sealing and result submitting.
 type task struct {
 	receipts  []*types.Receipt
 	state     *state.StateDB
+	feeParams *fees.BlockFeeParams
 	block     *types.Block
 	createdAt time.Time
 }
@@ -652,7 +656,7 @@ func (w *worker) resultLoop() {
 				logs = append(logs, receipt.Logs...)
 			}
 			// Commit block and state to database.
-			_, err := w.chain.WriteBlockWithState(block, receipts, logs, task.state, true)
+			_, err := w.chain.WriteBlockWithState(block, receipts, logs, task.state, task.feeParams, true)
 			if err != nil {
 				log.Error("Failed writing block to chain", "err", err)
 				continue
@@ -683,6 +687,7 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 		family:    mapset.NewSet(),
 		uncles:    mapset.NewSet(),
 		header:    header,
+		feeParams: core.ReadBlockFeeParams(state, header.Number.Uint64()),
 	}
 
 	// when 08 is processed ancestors contain 07 (quick block)
@@ -1087,7 +1092,7 @@ func (w *worker) commit(uncles []*types.Header, interval func(), update bool, st
 		// Writing to the taskCh will result in the block being added to the
 		// chain via the resultCh
 		select {
-		case w.taskCh <- &task{receipts: receipts, state: s, block: block, createdAt: time.Now()}:
+		case w.taskCh <- &task{receipts: receipts, state: s, feeParams: w.current.feeParams, block: block, createdAt: time.Now()}:
 			w.unconfirmed.Shift(block.NumberU64() - 1)
 
 			feesWei := new(big.Int)
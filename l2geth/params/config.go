@@ -216,16 +216,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(108), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(108), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(420), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(420), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, nil, nil, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -304,6 +304,20 @@ type ChainConfig struct {
 
 	// OVM Specific
 	StateDump *dump.OvmDump `json:"-"`
+	// Gas costs of calldata on the L1 chain that transactions are settled
+	// on, nil = TxDataZeroGas and TxDataNonZeroGasEIP2028
+	L1CalldataGas *L1CalldataGasConfig `json:"l1CalldataGas,omitempty"`
+	// Forks of the formula of the L1 fee, nil = the legacy formula
+	FeeForks *FeeForksConfig `json:"feeForks,omitempty"`
+	// Rollup hardforks in order of activation, nil = no rollup feature is
+	// active
+	RollupForks []RollupFork `json:"rollupForks,omitempty"`
+	// L2 block number that a migrated chain switched from the legacy fee
+	// formula to the fee formula of the rollup rules at, nil = no migration
+	FeeMigrationBlock *big.Int `json:"feeMigrationBlock,omitempty"`
+	// Location of the OVM_GasPriceOracle and of its values in its storage,
+	// nil = the predeploy of the OVM
+	GasPriceOracle *GasPriceOracleConfig `json:"gasPriceOracle,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -325,6 +339,207 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// L1CalldataGasConfig is the gas schedule of calldata on the L1 chain that the
+// L1 fee of transactions is computed with.
+type L1CalldataGasConfig struct {
+	ZeroGas    uint64 `json:"zeroGas"`    // Gas per zero byte of calldata, 0 = TxDataZeroGas
+	NonZeroGas uint64 `json:"nonZeroGas"` // Gas per non-zero byte of calldata, 0 = TxDataNonZeroGasEIP2028
+	MinTxSize  uint64 `json:"minTxSize"`  // Calldata size that smaller transactions are charged for
+
+	// L1 block that EIP 2028 activated at on the L1 chain. Calldata of
+	// earlier L1 blocks is priced at the Frontier costs, nil = ZeroGas and
+	// NonZeroGas apply from the first L1 block
+	IstanbulBlock *big.Int `json:"istanbulBlock,omitempty"`
+	// Forks of the L1 chain that reprice calldata, in order of activation
+	Forks []L1CalldataGasFork `json:"forks,omitempty"`
+}
+
+// L1CalldataGasFork is a repricing of calldata on the L1 chain.
+type L1CalldataGasFork struct {
+	Block      *big.Int `json:"block"` // L1 block number that the prices activate at
+	ZeroGas    uint64   `json:"zeroGas"`
+	NonZeroGas uint64   `json:"nonZeroGas"`
+}
+
+// L1CalldataGasAt returns the gas costs of a zero and a non-zero byte of
+// calldata on the L1 chain at the L1 block number. The latest fork is used
+// when the L1 block number is nil.
+func (c *ChainConfig) L1CalldataGasAt(l1Block *big.Int) (uint64, uint64) {
+	if c == nil {
+		return TxDataZeroGas, TxDataNonZeroGasEIP2028
+	}
+	return c.L1CalldataGas.GasAt(l1Block)
+}
+
+// GasAt returns the gas costs of a zero and a non-zero byte of calldata at
+// the L1 block number, see ChainConfig.L1CalldataGasAt. Without a schedule
+// calldata is priced as of EIP 2028, as are the costs that the schedule
+// leaves unset.
+func (c *L1CalldataGasConfig) GasAt(l1Block *big.Int) (uint64, uint64) {
+	if c == nil {
+		return TxDataZeroGas, TxDataNonZeroGasEIP2028
+	}
+	if c.IstanbulBlock != nil && l1Block != nil && !isForked(c.IstanbulBlock, l1Block) {
+		return TxDataZeroGas, TxDataNonZeroGasFrontier
+	}
+	zero, nonZero := c.ZeroGas, c.NonZeroGas
+	if zero == 0 {
+		zero = TxDataZeroGas
+	}
+	if nonZero == 0 {
+		nonZero = TxDataNonZeroGasEIP2028
+	}
+	for _, fork := range c.Forks {
+		if l1Block == nil || isForked(fork.Block, l1Block) {
+			zero, nonZero = fork.ZeroGas, fork.NonZeroGas
+		}
+	}
+	return zero, nonZero
+}
+
+// L1MinTxSize returns the calldata size that smaller transactions are
+// charged the L1 fee for, so that the fee of a transaction covers its share
+// of the batch overhead. Zero means that there is no floor.
+func (c *ChainConfig) L1MinTxSize() uint64 {
+	if c == nil || c.L1CalldataGas == nil {
+		return 0
+	}
+	return c.L1CalldataGas.MinTxSize
+}
+
+// CheckL1CalldataGas checks that the calldata repricings are ordered by their
+// activation blocks, after Istanbul, and that they price calldata.
+func (c *L1CalldataGasConfig) CheckL1CalldataGas() error {
+	last := c.IstanbulBlock
+	for i, fork := range c.Forks {
+		if fork.Block == nil {
+			return fmt.Errorf("l1 calldata gas fork %d has no block", i)
+		}
+		if fork.ZeroGas == 0 || fork.NonZeroGas == 0 {
+			return fmt.Errorf("l1 calldata gas fork %d at block %v has zero gas", i, fork.Block)
+		}
+		if last != nil && fork.Block.Cmp(last) <= 0 {
+			return fmt.Errorf("l1 calldata gas fork %d at block %v not after block %v", i, fork.Block, last)
+		}
+		last = fork.Block
+	}
+	return nil
+}
+
+// GasPriceOracleConfig is the location of the OVM_GasPriceOracle of
+// deployments whose gas price oracle is not the predeploy of the OVM. The
+// slots are those of the original storage layout, version 0. Values that are
+// not set are those of the predeploy.
+type GasPriceOracleConfig struct {
+	Address      *common.Address `json:"address,omitempty"`
+	OwnerSlot    *common.Hash    `json:"ownerSlot,omitempty"`
+	GasPriceSlot *common.Hash    `json:"gasPriceSlot,omitempty"`
+	VersionSlot  *common.Hash    `json:"versionSlot,omitempty"`
+}
+
+// CheckGasPriceOracle checks that the gas price oracle has an address and
+// that its values are stored in distinct slots.
+func (c *GasPriceOracleConfig) CheckGasPriceOracle() error {
+	if c.Address != nil && *c.Address == (common.Address{}) {
+		return fmt.Errorf("gas price oracle at the zero address")
+	}
+	slots := make(map[common.Hash]string)
+	for _, slot := range []struct {
+		name string
+		key  *common.Hash
+	}{{"owner", c.OwnerSlot}, {"gas price", c.GasPriceSlot}, {"version", c.VersionSlot}} {
+		if slot.key == nil {
+			continue
+		}
+		if other, ok := slots[*slot.key]; ok {
+			return fmt.Errorf("gas price oracle %s and %s in the same slot %s", other, slot.name, slot.key.Hex())
+		}
+		slots[*slot.key] = slot.name
+	}
+	return nil
+}
+
+// FeeAlgorithm is a version of the formula of the L1 fee of transactions.
+type FeeAlgorithm uint8
+
+const (
+	// FeeAlgorithmLegacy charges calldata at the gas schedule of Ethereum
+	// since EIP 2028
+	FeeAlgorithmLegacy FeeAlgorithm = iota
+	// FeeAlgorithmL1CalldataGas charges calldata at the L1 calldata gas
+	// schedule of the chain config
+	FeeAlgorithmL1CalldataGas
+	// FeeAlgorithmMinTxSize also charges transactions with less calldata than
+	// the minimum size for the minimum size
+	FeeAlgorithmMinTxSize
+)
+
+// String implements the fmt.Stringer interface.
+func (a FeeAlgorithm) String() string {
+	switch a {
+	case FeeAlgorithmLegacy:
+		return "legacy"
+	case FeeAlgorithmL1CalldataGas:
+		return "l1CalldataGas"
+	case FeeAlgorithmMinTxSize:
+		return "minTxSize"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(a))
+	}
+}
+
+// FeeForksConfig schedules the changes of the formula of the L1 fee like any
+// other hardfork. The changes activate at the L1 block number of a
+// transaction rather than at the L2 block number, so that the sequencer and
+// the verifiers charge a transaction with the same formula before it is
+// mined (nil = no fork, 0 = already activated). The fee forks are a shorthand
+// for a rollup hardfork table that only schedules the fee features.
+type FeeForksConfig struct {
+	L1CalldataGasBlock *big.Int `json:"l1CalldataGasBlock,omitempty"` // FeeAlgorithmL1CalldataGas switch block
+	MinTxSizeBlock     *big.Int `json:"minTxSizeBlock,omitempty"`     // FeeAlgorithmMinTxSize switch block
+}
+
+// FeeAlgorithmAt returns the formula of the L1 fee at the L1 block number,
+// which follows from the rollup features that are active at it.
+func (c *ChainConfig) FeeAlgorithmAt(l1Block *big.Int) FeeAlgorithm {
+	return c.RollupRules(l1Block).FeeAlgorithm()
+}
+
+// IsFeeMigrated returns whether the L2 block number is at or after the fee
+// migration, every block is when the chain was not migrated.
+func (c *ChainConfig) IsFeeMigrated(num *big.Int) bool {
+	if c == nil || c.FeeMigrationBlock == nil || num == nil {
+		return true
+	}
+	return isForked(c.FeeMigrationBlock, num)
+}
+
+// FeeAlgorithmFor returns the formula of the L1 fee of a transaction of the
+// L2 block number at the L1 block number. The transactions of the blocks
+// before the fee migration were charged with the legacy formula.
+func (c *ChainConfig) FeeAlgorithmFor(num, l1Block *big.Int) FeeAlgorithm {
+	if !c.IsFeeMigrated(num) {
+		return FeeAlgorithmLegacy
+	}
+	return c.FeeAlgorithmAt(l1Block)
+}
+
+// CheckFeeForks checks that the changes of the formula of the L1 fee are
+// scheduled in order, each formula builds on the previous one.
+func (c *FeeForksConfig) CheckFeeForks() error {
+	if c.MinTxSizeBlock == nil {
+		return nil
+	}
+	if c.L1CalldataGasBlock == nil {
+		return fmt.Errorf("unsupported fee fork ordering: l1CalldataGasBlock not enabled, but minTxSizeBlock enabled at %v", c.MinTxSizeBlock)
+	}
+	if c.L1CalldataGasBlock.Cmp(c.MinTxSizeBlock) > 0 {
+		return fmt.Errorf("unsupported fee fork ordering: l1CalldataGasBlock enabled at %v, but minTxSizeBlock enabled at %v",
+			c.L1CalldataGasBlock, c.MinTxSizeBlock)
+	}
+	return nil
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -431,6 +646,24 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *Confi
 // CheckConfigForkOrder checks that we don't "skip" any forks, geth isn't pluggable enough
 // to guarantee that forks can be implemented in a different order than on official networks
 func (c *ChainConfig) CheckConfigForkOrder() error {
+	if c.L1CalldataGas != nil {
+		if err := c.L1CalldataGas.CheckL1CalldataGas(); err != nil {
+			return err
+		}
+	}
+	if c.FeeForks != nil {
+		if err := c.FeeForks.CheckFeeForks(); err != nil {
+			return err
+		}
+	}
+	if c.GasPriceOracle != nil {
+		if err := c.GasPriceOracle.CheckGasPriceOracle(); err != nil {
+			return err
+		}
+	}
+	if err := c.CheckRollupForks(); err != nil {
+		return err
+	}
 	type fork struct {
 		name  string
 		block *big.Int
@@ -505,6 +738,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.FeeMigrationBlock, newcfg.FeeMigrationBlock, head) {
+		return newCompatError("fee migration block", c.FeeMigrationBlock, newcfg.FeeMigrationBlock)
+	}
 	return nil
 }
 
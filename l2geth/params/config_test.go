@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +72,17 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored: &ChainConfig{FeeMigrationBlock: big.NewInt(10)},
+			new:    &ChainConfig{FeeMigrationBlock: big.NewInt(20)},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "fee migration block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +92,217 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckRollupCompatible(t *testing.T) {
+	subsidy := func(l1Block int64) *ChainConfig {
+		return &ChainConfig{RollupForks: []RollupFork{{Name: "subsidy", L1Block: big.NewInt(l1Block), Features: []RollupFeature{RollupFeatureFeeSubsidy}}}}
+	}
+	calldataGas := func(istanbul, fork int64, nonZeroGas uint64) *ChainConfig {
+		return &ChainConfig{L1CalldataGas: &L1CalldataGasConfig{
+			IstanbulBlock: big.NewInt(istanbul),
+			Forks:         []L1CalldataGasFork{{Block: big.NewInt(fork), ZeroGas: 4, NonZeroGas: nonZeroGas}},
+		}}
+	}
+	tests := map[string]struct {
+		stored, new *ChainConfig
+		l1Head      uint64
+		wantErr     *ConfigCompatError
+	}{
+		"rollup-fork-unchanged": {subsidy(10), subsidy(10), 100, nil},
+		"rollup-fork-ahead":     {subsidy(10), subsidy(20), 9, nil},
+		"rollup-fork-moved": {subsidy(10), subsidy(20), 15, &ConfigCompatError{
+			What: "rollup feature feeSubsidy l1 block", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(20), RewindTo: 9,
+		}},
+		"rollup-fork-removed": {subsidy(10), &ChainConfig{}, 15, &ConfigCompatError{
+			What: "rollup feature feeSubsidy l1 block", StoredConfig: big.NewInt(10), NewConfig: nil, RewindTo: 9,
+		}},
+		"fee-fork-moved": {
+			&ChainConfig{FeeForks: &FeeForksConfig{L1CalldataGasBlock: big.NewInt(10)}},
+			&ChainConfig{FeeForks: &FeeForksConfig{L1CalldataGasBlock: big.NewInt(5)}},
+			15,
+			&ConfigCompatError{What: "rollup feature l1CalldataGas l1 block", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(5), RewindTo: 4},
+		},
+		// The fee forks schedule the same features as the rollup forks
+		"fee-forks-to-rollup-forks": {
+			&ChainConfig{FeeForks: &FeeForksConfig{L1CalldataGasBlock: big.NewInt(10)}},
+			&ChainConfig{RollupForks: []RollupFork{{Name: "calldata", L1Block: big.NewInt(10), Features: []RollupFeature{RollupFeatureL1CalldataGas}}}},
+			15,
+			nil,
+		},
+		"calldata-gas-unchanged": {calldataGas(5, 10, 8), calldataGas(5, 10, 8), 100, nil},
+		"calldata-gas-istanbul-moved": {calldataGas(5, 10, 8), calldataGas(6, 10, 8), 100, &ConfigCompatError{
+			What: "l1 calldata gas Istanbul block", StoredConfig: big.NewInt(5), NewConfig: big.NewInt(6), RewindTo: 4,
+		}},
+		"calldata-gas-fork-moved": {calldataGas(5, 10, 8), calldataGas(5, 20, 8), 15, &ConfigCompatError{
+			What: "l1 calldata gas fork 0 block", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(20), RewindTo: 9,
+		}},
+		"calldata-gas-fork-repriced": {calldataGas(5, 10, 8), calldataGas(5, 10, 6), 15, &ConfigCompatError{
+			What: "l1 calldata gas fork 0 gas", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(10), RewindTo: 9,
+		}},
+		"calldata-gas-fork-ahead": {calldataGas(5, 10, 8), calldataGas(5, 10, 6), 9, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.stored.CheckRollupCompatible(tt.new, tt.l1Head)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error mismatch: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestL1CalldataGasAt(t *testing.T) {
+	config := &ChainConfig{
+		L1CalldataGas: &L1CalldataGasConfig{
+			ZeroGas:    4,
+			NonZeroGas: 16,
+			Forks: []L1CalldataGasFork{
+				{Block: big.NewInt(100), ZeroGas: 4, NonZeroGas: 8},
+				{Block: big.NewInt(200), ZeroGas: 2, NonZeroGas: 4},
+			},
+		},
+	}
+	istanbul := &ChainConfig{
+		L1CalldataGas: &L1CalldataGasConfig{
+			ZeroGas:       4,
+			NonZeroGas:    16,
+			IstanbulBlock: big.NewInt(50),
+			Forks:         []L1CalldataGasFork{{Block: big.NewInt(100), ZeroGas: 4, NonZeroGas: 8}},
+		},
+	}
+	// Configs that only set the other fields of the schedule price calldata
+	// as of EIP 2028
+	minTxSize := &ChainConfig{L1CalldataGas: &L1CalldataGasConfig{MinTxSize: 100}}
+	istanbulOnly := &ChainConfig{L1CalldataGas: &L1CalldataGasConfig{IstanbulBlock: big.NewInt(50)}}
+	tests := map[string]struct {
+		config        *ChainConfig
+		l1Block       *big.Int
+		zero, nonZero uint64
+	}{
+		"nil-config":    {nil, big.NewInt(0), TxDataZeroGas, TxDataNonZeroGasEIP2028},
+		"default":       {&ChainConfig{}, big.NewInt(1000), TxDataZeroGas, TxDataNonZeroGasEIP2028},
+		"before-forks":  {config, big.NewInt(99), 4, 16},
+		"first-fork":    {config, big.NewInt(100), 4, 8},
+		"between-forks": {config, big.NewInt(199), 4, 8},
+		"second-fork":   {config, big.NewInt(200), 2, 4},
+		"latest":        {config, nil, 2, 4},
+		"pre-istanbul":  {istanbul, big.NewInt(49), TxDataZeroGas, TxDataNonZeroGasFrontier},
+		"istanbul":      {istanbul, big.NewInt(50), 4, 16},
+		"istanbul-fork": {istanbul, big.NewInt(100), 4, 8},
+		"min-tx-size":   {minTxSize, big.NewInt(1000), TxDataZeroGas, TxDataNonZeroGasEIP2028},
+		"istanbul-only": {istanbulOnly, big.NewInt(50), TxDataZeroGas, TxDataNonZeroGasEIP2028},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			zero, nonZero := tt.config.L1CalldataGasAt(tt.l1Block)
+			if zero != tt.zero || nonZero != tt.nonZero {
+				t.Fatalf("mismatched calldata gas: got %d/%d, expect %d/%d", zero, nonZero, tt.zero, tt.nonZero)
+			}
+		})
+	}
+
+	if size := (&ChainConfig{}).L1MinTxSize(); size != 0 {
+		t.Fatalf("mismatched default min tx size: got %d, expect 0", size)
+	}
+	config.L1CalldataGas.MinTxSize = 100
+	if size := config.L1MinTxSize(); size != 100 {
+		t.Fatalf("mismatched min tx size: got %d, expect 100", size)
+	}
+
+	config.L1CalldataGas.Forks[1].Block = big.NewInt(50)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for unordered calldata gas forks")
+	}
+	istanbul.L1CalldataGas.IstanbulBlock = big.NewInt(100)
+	if err := istanbul.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for calldata gas fork at istanbul")
+	}
+	istanbul.L1CalldataGas.IstanbulBlock = big.NewInt(50)
+	istanbul.L1CalldataGas.Forks[0].NonZeroGas = 0
+	if err := istanbul.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for calldata gas fork without gas")
+	}
+}
+
+func TestFeeAlgorithmAt(t *testing.T) {
+	config := &ChainConfig{
+		FeeForks: &FeeForksConfig{
+			L1CalldataGasBlock: big.NewInt(100),
+			MinTxSizeBlock:     big.NewInt(200),
+		},
+	}
+	tests := map[string]struct {
+		config    *ChainConfig
+		l1Block   *big.Int
+		algorithm FeeAlgorithm
+	}{
+		"nil-config":    {nil, big.NewInt(0), FeeAlgorithmLegacy},
+		"no-forks":      {&ChainConfig{}, big.NewInt(0), FeeAlgorithmLegacy},
+		"unscheduled":   {&ChainConfig{FeeForks: &FeeForksConfig{}}, big.NewInt(1000), FeeAlgorithmLegacy},
+		"before-forks":  {config, big.NewInt(99), FeeAlgorithmLegacy},
+		"first-fork":    {config, big.NewInt(100), FeeAlgorithmL1CalldataGas},
+		"between-forks": {config, big.NewInt(199), FeeAlgorithmL1CalldataGas},
+		"second-fork":   {config, big.NewInt(200), FeeAlgorithmMinTxSize},
+		"latest":        {config, nil, FeeAlgorithmMinTxSize},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if algorithm := tt.config.FeeAlgorithmAt(tt.l1Block); algorithm != tt.algorithm {
+				t.Fatalf("mismatched fee algorithm: got %v, expect %v", algorithm, tt.algorithm)
+			}
+		})
+	}
+
+	config.FeeForks.MinTxSizeBlock = big.NewInt(50)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for unordered fee forks")
+	}
+	config.FeeForks.L1CalldataGasBlock = nil
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for skipped fee fork")
+	}
+}
+
+func TestFeeAlgorithmFor(t *testing.T) {
+	forks := &FeeForksConfig{L1CalldataGasBlock: big.NewInt(0), MinTxSizeBlock: big.NewInt(0)}
+	migrated := &ChainConfig{FeeForks: forks, FeeMigrationBlock: big.NewInt(1000)}
+	tests := map[string]struct {
+		config    *ChainConfig
+		number    *big.Int
+		algorithm FeeAlgorithm
+	}{
+		"not-migrated":     {&ChainConfig{FeeForks: forks}, big.NewInt(0), FeeAlgorithmMinTxSize},
+		"before-migration": {migrated, big.NewInt(999), FeeAlgorithmLegacy},
+		"at-migration":     {migrated, big.NewInt(1000), FeeAlgorithmMinTxSize},
+		"pending":          {migrated, nil, FeeAlgorithmMinTxSize},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if algorithm := tt.config.FeeAlgorithmFor(tt.number, big.NewInt(1)); algorithm != tt.algorithm {
+				t.Fatalf("mismatched fee algorithm: got %v, expect %v", algorithm, tt.algorithm)
+			}
+		})
+	}
+}
+
+func TestCheckGasPriceOracle(t *testing.T) {
+	zero := common.Address{}
+	slot := common.BigToHash(big.NewInt(3))
+	other := common.BigToHash(big.NewInt(4))
+	tests := map[string]struct {
+		config *GasPriceOracleConfig
+		err    bool
+	}{
+		"predeploy":    {&GasPriceOracleConfig{}, false},
+		"distinct":     {&GasPriceOracleConfig{OwnerSlot: &slot, GasPriceSlot: &other}, false},
+		"zero-address": {&GasPriceOracleConfig{Address: &zero}, true},
+		"same-slot":    {&GasPriceOracleConfig{OwnerSlot: &slot, VersionSlot: &slot}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tt.config.CheckGasPriceOracle(); (err != nil) != tt.err {
+				t.Fatalf("mismatched error: got %v, expect error %t", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// RollupFeature is a rollup specific change of the behavior of the chain that
+// is activated by a rollup hardfork.
+type RollupFeature string
+
+const (
+	// RollupFeatureL1CalldataGas charges calldata at the L1 calldata gas
+	// schedule of the chain config in the L1 fee
+	RollupFeatureL1CalldataGas RollupFeature = "l1CalldataGas"
+	// RollupFeatureMinTxSize charges transactions with less calldata than
+	// the minimum size for the minimum size in the L1 fee
+	RollupFeatureMinTxSize RollupFeature = "minTxSize"
+	// RollupFeatureFeeSubsidy debits the OVM_FeeSubsidyRegistry for the
+	// subsidized L1 fee of sequencer transactions
+	RollupFeatureFeeSubsidy RollupFeature = "feeSubsidy"
+	// RollupFeatureL1FeeReceipts records the L1 fee of transactions in their
+	// receipts
+	RollupFeatureL1FeeReceipts RollupFeature = "l1FeeReceipts"
+	// RollupFeatureL1FeePrecompile adds the precompile that returns the L1
+	// fee of a byte payload to the EVM
+	RollupFeatureL1FeePrecompile RollupFeature = "l1FeePrecompile"
+)
+
+// rollupFeatures are the known rollup features with the features that each
+// one builds on
+var rollupFeatures = map[RollupFeature][]RollupFeature{
+	RollupFeatureL1CalldataGas: nil,
+	RollupFeatureMinTxSize:     {RollupFeatureL1CalldataGas},
+	RollupFeatureFeeSubsidy:    nil,
+	RollupFeatureL1FeeReceipts: nil,
+	// The precompile prices payloads with the L1 fee formula of the other
+	// features that are active
+	RollupFeatureL1FeePrecompile: nil,
+}
+
+// RollupFork is a hardfork of the rollup. It activates a set of features at
+// once so that upgrades that span the EVM, the receipts and the fees are
+// coordinated.
+type RollupFork struct {
+	Name     string          `json:"name"`
+	L1Block  *big.Int        `json:"l1Block"` // L1 block number that the features activate at
+	Features []RollupFeature `json:"features"`
+}
+
+// RollupRules are the rollup features that are active at an L1 block number.
+// The rollup forks activate at the L1 block number of a transaction rather
+// than at the L2 block number, so that the sequencer and the verifiers apply
+// the same rules to a transaction before it is mined.
+type RollupRules struct {
+	L1CalldataGas, MinTxSize  bool
+	FeeSubsidy, L1FeeReceipts bool
+	L1FeePrecompile           bool
+}
+
+// Active returns whether a rollup feature is active.
+func (r RollupRules) Active(feature RollupFeature) bool {
+	switch feature {
+	case RollupFeatureL1CalldataGas:
+		return r.L1CalldataGas
+	case RollupFeatureMinTxSize:
+		return r.MinTxSize
+	case RollupFeatureFeeSubsidy:
+		return r.FeeSubsidy
+	case RollupFeatureL1FeeReceipts:
+		return r.L1FeeReceipts
+	case RollupFeatureL1FeePrecompile:
+		return r.L1FeePrecompile
+	default:
+		return false
+	}
+}
+
+// FeeAlgorithm returns the formula of the L1 fee under the rules.
+func (r RollupRules) FeeAlgorithm() FeeAlgorithm {
+	switch {
+	case r.MinTxSize:
+		return FeeAlgorithmMinTxSize
+	case r.L1CalldataGas:
+		return FeeAlgorithmL1CalldataGas
+	default:
+		return FeeAlgorithmLegacy
+	}
+}
+
+// activate sets a rollup feature active.
+func (r *RollupRules) activate(feature RollupFeature) {
+	switch feature {
+	case RollupFeatureL1CalldataGas:
+		r.L1CalldataGas = true
+	case RollupFeatureMinTxSize:
+		r.MinTxSize = true
+	case RollupFeatureFeeSubsidy:
+		r.FeeSubsidy = true
+	case RollupFeatureL1FeeReceipts:
+		r.L1FeeReceipts = true
+	case RollupFeatureL1FeePrecompile:
+		r.L1FeePrecompile = true
+	}
+}
+
+// RollupRules returns the rollup features that are active at the L1 block
+// number. A feature is only active once a rollup fork activates it, chains
+// without rollup forks keep the behavior that they were started with. The
+// latest scheduled features are used when the L1 block number is nil.
+func (c *ChainConfig) RollupRules(l1Block *big.Int) RollupRules {
+	var rules RollupRules
+	for _, fork := range c.rollupForks() {
+		if fork.L1Block != nil && (l1Block == nil || isForked(fork.L1Block, l1Block)) {
+			for _, feature := range fork.Features {
+				rules.activate(feature)
+			}
+		}
+	}
+	return rules
+}
+
+// rollupForks returns the rollup hardfork table. The fee forks are turned
+// into a table that only schedules the features of the fee formula, the
+// other features are only scheduled by rollup forks.
+func (c *ChainConfig) rollupForks() []RollupFork {
+	if c == nil {
+		return nil
+	}
+	if c.FeeForks == nil {
+		return c.RollupForks
+	}
+	var forks []RollupFork
+	if c.FeeForks.L1CalldataGasBlock != nil {
+		forks = append(forks, RollupFork{
+			Name:     string(RollupFeatureL1CalldataGas),
+			L1Block:  c.FeeForks.L1CalldataGasBlock,
+			Features: []RollupFeature{RollupFeatureL1CalldataGas},
+		})
+	}
+	if c.FeeForks.MinTxSizeBlock != nil {
+		forks = append(forks, RollupFork{
+			Name:     string(RollupFeatureMinTxSize),
+			L1Block:  c.FeeForks.MinTxSizeBlock,
+			Features: []RollupFeature{RollupFeatureMinTxSize},
+		})
+	}
+	return forks
+}
+
+// CheckRollupForks checks that the rollup forks are ordered by their
+// activation blocks, that they only activate known features once and that
+// every feature activates with or after the features that it builds on.
+func (c *ChainConfig) CheckRollupForks() error {
+	if c.FeeForks != nil && c.RollupForks != nil {
+		return errors.New("feeForks and rollupForks are exclusive, schedule the fee features in rollupForks")
+	}
+	var (
+		last      *big.Int
+		names     = make(map[string]bool)
+		activated = make(map[RollupFeature]bool)
+	)
+	for i, fork := range c.RollupForks {
+		if fork.Name == "" {
+			return fmt.Errorf("rollup fork %d has no name", i)
+		}
+		if names[fork.Name] {
+			return fmt.Errorf("rollup fork %s is scheduled twice", fork.Name)
+		}
+		names[fork.Name] = true
+		if fork.L1Block == nil {
+			return fmt.Errorf("rollup fork %s has no l1 block", fork.Name)
+		}
+		if last != nil && fork.L1Block.Cmp(last) < 0 {
+			return fmt.Errorf("unsupported rollup fork ordering: %s enabled at %v, before %v", fork.Name, fork.L1Block, last)
+		}
+		last = fork.L1Block
+		for _, feature := range fork.Features {
+			if _, ok := rollupFeatures[feature]; !ok {
+				return fmt.Errorf("rollup fork %s activates unknown feature %s", fork.Name, feature)
+			}
+			if activated[feature] {
+				return fmt.Errorf("rollup fork %s activates %s again", fork.Name, feature)
+			}
+			activated[feature] = true
+		}
+	}
+	// A feature is active from the L1 block of its fork, the features that
+	// it builds on must be active at that block
+	for _, fork := range c.RollupForks {
+		rules := c.RollupRules(fork.L1Block)
+		for _, feature := range fork.Features {
+			for _, required := range rollupFeatures[feature] {
+				if !rules.Active(required) {
+					return fmt.Errorf("rollup fork %s activates %s before %s", fork.Name, feature, required)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rollupFeatureBlock returns the L1 block number that the rollup feature
+// activates at, nil when it is not scheduled
+func (c *ChainConfig) rollupFeatureBlock(feature RollupFeature) *big.Int {
+	for _, fork := range c.rollupForks() {
+		for _, f := range fork.Features {
+			if f == feature {
+				return fork.L1Block
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRollupCompatible checks whether the rollup forks and the L1 calldata
+// gas schedule have been applied to the transactions up to the L1 block
+// number with a mismatching chain configuration. They activate at L1 block
+// numbers, so the block numbers of the error, including the block to rewind
+// to, are L1 block numbers as well.
+func (c *ChainConfig) CheckRollupCompatible(newcfg *ChainConfig, l1Head uint64) *ConfigCompatError {
+	bhead := new(big.Int).SetUint64(l1Head)
+
+	// Iterate checkRollupCompatible to find the lowest conflict.
+	var lasterr *ConfigCompatError
+	for {
+		err := c.checkRollupCompatible(newcfg, bhead)
+		if err == nil || (lasterr != nil && err.RewindTo == lasterr.RewindTo) {
+			break
+		}
+		lasterr = err
+		bhead.SetUint64(err.RewindTo)
+	}
+	return lasterr
+}
+
+func (c *ChainConfig) checkRollupCompatible(newcfg *ChainConfig, l1Head *big.Int) *ConfigCompatError {
+	features := make([]string, 0, len(rollupFeatures))
+	for feature := range rollupFeatures {
+		features = append(features, string(feature))
+	}
+	sort.Strings(features)
+	for _, feature := range features {
+		storedBlock, newBlock := c.rollupFeatureBlock(RollupFeature(feature)), newcfg.rollupFeatureBlock(RollupFeature(feature))
+		if isForkIncompatible(storedBlock, newBlock, l1Head) {
+			return newCompatError(fmt.Sprintf("rollup feature %s l1 block", feature), storedBlock, newBlock)
+		}
+	}
+
+	var storedGas, newGas L1CalldataGasConfig
+	if c.L1CalldataGas != nil {
+		storedGas = *c.L1CalldataGas
+	}
+	if newcfg.L1CalldataGas != nil {
+		newGas = *newcfg.L1CalldataGas
+	}
+	if isForkIncompatible(storedGas.IstanbulBlock, newGas.IstanbulBlock, l1Head) {
+		return newCompatError("l1 calldata gas Istanbul block", storedGas.IstanbulBlock, newGas.IstanbulBlock)
+	}
+	for i := 0; i < len(storedGas.Forks) || i < len(newGas.Forks); i++ {
+		var storedFork, newFork L1CalldataGasFork
+		if i < len(storedGas.Forks) {
+			storedFork = storedGas.Forks[i]
+		}
+		if i < len(newGas.Forks) {
+			newFork = newGas.Forks[i]
+		}
+		if isForkIncompatible(storedFork.Block, newFork.Block, l1Head) {
+			return newCompatError(fmt.Sprintf("l1 calldata gas fork %d block", i), storedFork.Block, newFork.Block)
+		}
+		// The calldata of the blocks since an active fork was priced at its
+		// costs
+		if isForked(storedFork.Block, l1Head) && (storedFork.ZeroGas != newFork.ZeroGas || storedFork.NonZeroGas != newFork.NonZeroGas) {
+			return newCompatError(fmt.Sprintf("l1 calldata gas fork %d gas", i), storedFork.Block, newFork.Block)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package params
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRollupRules(t *testing.T) {
+	config := &ChainConfig{
+		RollupForks: []RollupFork{
+			{Name: "fees", L1Block: big.NewInt(100), Features: []RollupFeature{RollupFeatureL1CalldataGas, RollupFeatureL1FeeReceipts}},
+			{Name: "subsidies", L1Block: big.NewInt(200), Features: []RollupFeature{RollupFeatureMinTxSize, RollupFeatureFeeSubsidy, RollupFeatureL1FeePrecompile}},
+		},
+	}
+	all := RollupRules{L1CalldataGas: true, MinTxSize: true, FeeSubsidy: true, L1FeeReceipts: true, L1FeePrecompile: true}
+	tests := map[string]struct {
+		config  *ChainConfig
+		l1Block *big.Int
+		rules   RollupRules
+	}{
+		"nil-config":   {nil, big.NewInt(0), RollupRules{}},
+		"no-forks":     {&ChainConfig{}, big.NewInt(0), RollupRules{}},
+		"before-forks": {config, big.NewInt(99), RollupRules{}},
+		"first-fork":   {config, big.NewInt(100), RollupRules{L1CalldataGas: true, L1FeeReceipts: true}},
+		"second-fork":  {config, big.NewInt(200), all},
+		"latest":       {config, nil, all},
+		"fee-forks": {
+			&ChainConfig{FeeForks: &FeeForksConfig{L1CalldataGasBlock: big.NewInt(100)}}, big.NewInt(100),
+			RollupRules{L1CalldataGas: true},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rules := tt.config.RollupRules(tt.l1Block); rules != tt.rules {
+				t.Fatalf("mismatched rollup rules: got %+v, expect %+v", rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestCheckRollupForks(t *testing.T) {
+	fork := func(name string, block int64, features ...RollupFeature) RollupFork {
+		return RollupFork{Name: name, L1Block: big.NewInt(block), Features: features}
+	}
+	tests := map[string]struct {
+		config *ChainConfig
+		valid  bool
+	}{
+		"no-forks": {&ChainConfig{}, true},
+		"ordered": {&ChainConfig{RollupForks: []RollupFork{
+			fork("a", 1, RollupFeatureL1CalldataGas), fork("b", 2, RollupFeatureMinTxSize),
+		}}, true},
+		"same-fork": {&ChainConfig{RollupForks: []RollupFork{
+			fork("a", 1, RollupFeatureMinTxSize, RollupFeatureL1CalldataGas),
+		}}, true},
+		"unordered": {&ChainConfig{RollupForks: []RollupFork{
+			fork("a", 2, RollupFeatureL1CalldataGas), fork("b", 1, RollupFeatureFeeSubsidy),
+		}}, false},
+		"missing-requirement": {&ChainConfig{RollupForks: []RollupFork{
+			fork("a", 1, RollupFeatureMinTxSize), fork("b", 2, RollupFeatureL1CalldataGas),
+		}}, false},
+		"duplicate-name": {&ChainConfig{RollupForks: []RollupFork{
+			fork("a", 1, RollupFeatureL1CalldataGas), fork("a", 2, RollupFeatureFeeSubsidy),
+		}}, false},
+		"duplicate-feature": {&ChainConfig{RollupForks: []RollupFork{
+			fork("a", 1, RollupFeatureFeeSubsidy), fork("b", 2, RollupFeatureFeeSubsidy),
+		}}, false},
+		"unknown-feature": {&ChainConfig{RollupForks: []RollupFork{fork("a", 1, "unknown")}}, false},
+		"no-block":        {&ChainConfig{RollupForks: []RollupFork{{Name: "a"}}}, false},
+		"with-fee-forks": {&ChainConfig{
+			FeeForks:    &FeeForksConfig{},
+			RollupForks: []RollupFork{fork("a", 1, RollupFeatureFeeSubsidy)},
+		}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.config.CheckConfigForkOrder()
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
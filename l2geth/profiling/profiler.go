@@ -0,0 +1,142 @@
+// Package profiling captures pprof profiles of the node when instrumented hot
+// paths exceed their latency thresholds, so that slow fee calculations and
+// batch processing can be diagnosed after the fact without running a
+// profiler continuously.
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// profileDuration is how long the CPU profile runs once triggered
+	profileDuration = 10 * time.Second
+	// profileCooldown is the minimum amount of time between two captures
+	// triggered by the same operation
+	profileCooldown = 5 * time.Minute
+)
+
+var profileCaptureMeter = metrics.NewRegisteredMeter("profiling/captures", nil)
+
+// Profiler captures profiles when an instrumented operation takes longer
+// than its configured latency threshold. Because the slow operation has
+// already finished by the time it is observed, a goroutine and heap snapshot
+// is taken immediately and a CPU profile is recorded for the operations that
+// follow. Captures are rate limited per operation.
+type Profiler struct {
+	dir        string
+	thresholds map[string]time.Duration
+
+	lock sync.Mutex
+	last map[string]time.Time
+}
+
+// Default is the profiler used by the instrumented hot paths. It is nil,
+// and observing operations is a no-op, unless a profile directory is set.
+var Default *Profiler
+
+// NewProfiler creates a Profiler that writes profiles to dir when an
+// operation exceeds its threshold
+func NewProfiler(dir string, thresholds map[string]time.Duration) (*Profiler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Profiler{
+		dir:        dir,
+		thresholds: thresholds,
+		last:       make(map[string]time.Time),
+	}, nil
+}
+
+// ParseThresholds parses a comma separated list of operation=duration
+// pairs, for example "block=200ms,fee=5ms"
+func ParseThresholds(s string) (map[string]time.Duration, error) {
+	thresholds := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid profile threshold: %s", pair)
+		}
+		d, err := time.ParseDuration(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile threshold %s: %v", pair, err)
+		}
+		thresholds[kv[0]] = d
+	}
+	return thresholds, nil
+}
+
+// Observe checks the latency of an operation that started at start against
+// the threshold for name and triggers a capture when it is exceeded
+func (p *Profiler) Observe(name string, start time.Time) {
+	if p == nil {
+		return
+	}
+	threshold, ok := p.thresholds[name]
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < threshold {
+		return
+	}
+	now := time.Now()
+	p.lock.Lock()
+	if last, ok := p.last[name]; ok && now.Sub(last) < profileCooldown {
+		p.lock.Unlock()
+		return
+	}
+	p.last[name] = now
+	p.lock.Unlock()
+
+	log.Warn("Operation exceeded latency threshold, capturing profiles", "name", name,
+		"elapsed", elapsed, "threshold", threshold, "dir", p.dir)
+	profileCaptureMeter.Mark(1)
+	go p.capture(name, now)
+}
+
+// capture writes the snapshot profiles and records a CPU profile
+func (p *Profiler) capture(name string, now time.Time) {
+	prefix := filepath.Join(p.dir, fmt.Sprintf("%s-%s", name, now.UTC().Format("20060102T150405Z")))
+	for _, profile := range []string{"goroutine", "heap"} {
+		if err := writeProfile(profile, prefix+"."+profile+".pprof"); err != nil {
+			log.Error("Cannot write profile", "profile", profile, "msg", err)
+		}
+	}
+	f, err := os.Create(prefix + ".cpu.pprof")
+	if err != nil {
+		log.Error("Cannot create CPU profile", "msg", err)
+		return
+	}
+	defer f.Close()
+	// Only a single CPU profile can run at a time, skip it when one was
+	// already started through the debug API
+	if err := pprof.StartCPUProfile(f); err != nil {
+		log.Warn("Cannot start CPU profile", "msg", err)
+		os.Remove(f.Name())
+		return
+	}
+	time.Sleep(profileDuration)
+	pprof.StopCPUProfile()
+}
+
+func writeProfile(name, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return pprof.Lookup(name).WriteTo(f, 0)
+}
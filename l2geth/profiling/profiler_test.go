@@ -0,0 +1,91 @@
+package profiling
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseThresholds(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		expect map[string]time.Duration
+		err    bool
+	}{
+		"empty": {
+			input:  "",
+			expect: map[string]time.Duration{},
+		},
+		"multiple": {
+			input: "block=200ms, fee=5ms,batch=1s",
+			expect: map[string]time.Duration{
+				"block": 200 * time.Millisecond,
+				"fee":   5 * time.Millisecond,
+				"batch": time.Second,
+			},
+		},
+		"missing-duration": {
+			input: "block",
+			err:   true,
+		},
+		"bad-duration": {
+			input: "block=fast",
+			err:   true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseThresholds(tt.input)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.expect) {
+				t.Fatalf("mismatched thresholds: got %v, expect %v", got, tt.expect)
+			}
+			for k, v := range tt.expect {
+				if got[k] != v {
+					t.Fatalf("mismatched threshold %s: got %s, expect %s", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestProfilerObserveCooldown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := NewProfiler(dir, map[string]time.Duration{"fee": time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nil profilers and untracked operations are ignored
+	var nilProfiler *Profiler
+	nilProfiler.Observe("fee", time.Now().Add(-time.Second))
+	p.Observe("block", time.Now().Add(-time.Second))
+	// Fast operations do not trigger a capture
+	p.Observe("fee", time.Now())
+	if len(p.last) != 0 {
+		t.Fatalf("unexpected capture: %v", p.last)
+	}
+
+	p.Observe("fee", time.Now().Add(-time.Second))
+	first, ok := p.last["fee"]
+	if !ok {
+		t.Fatal("expected capture")
+	}
+	p.Observe("fee", time.Now().Add(-time.Second))
+	if p.last["fee"] != first {
+		t.Fatal("capture not rate limited")
+	}
+}
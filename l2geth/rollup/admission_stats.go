@@ -0,0 +1,110 @@
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// admissionStats aggregates the fee decisions of the sequencer and publishes
+// the aggregate of every second to its subscribers
+type admissionStats struct {
+	lock     sync.Mutex
+	accepted uint64
+	rejected uint64
+	feeSum   *big.Int
+	feeCount int64
+
+	// minGasPrices returns the lowest gas prices that are accepted
+	minGasPrices func() (*big.Int, *big.Int)
+	feed         event.Feed
+	scope        event.SubscriptionScope
+}
+
+// observe adds a fee decision to the aggregate of the current second
+func (a *admissionStats) observe(decision *feeDecision, err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if err != nil {
+		a.rejected++
+		return
+	}
+	a.accepted++
+	if decision.userFee != nil {
+		if a.feeSum == nil {
+			a.feeSum = new(big.Int)
+		}
+		a.feeSum.Add(a.feeSum, decision.userFee)
+		a.feeCount++
+	}
+}
+
+// collect returns the aggregate of the second that ended at the time and
+// starts the next one
+func (a *admissionStats) collect(now time.Time) *fees.AdmissionStats {
+	a.lock.Lock()
+	stats := &fees.AdmissionStats{
+		Time:     hexutil.Uint64(now.Unix()),
+		Accepted: hexutil.Uint64(a.accepted),
+		Rejected: hexutil.Uint64(a.rejected),
+	}
+	if a.feeCount != 0 {
+		stats.MeanFee = (*hexutil.Big)(new(big.Int).Div(a.feeSum, big.NewInt(a.feeCount)))
+	}
+	a.accepted, a.rejected, a.feeSum, a.feeCount = 0, 0, nil, 0
+	a.lock.Unlock()
+
+	if a.minGasPrices != nil {
+		l1GasPrice, l2GasPrice := a.minGasPrices()
+		stats.MinL1GasPrice = (*hexutil.Big)(l1GasPrice)
+		stats.MinL2GasPrice = (*hexutil.Big)(l2GasPrice)
+	}
+	return stats
+}
+
+// Loop publishes the aggregate of every second until the context is done
+func (a *admissionStats) Loop(ctx context.Context) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	defer a.scope.Close()
+	for {
+		select {
+		case now := <-t.C:
+			stats := a.collect(now)
+			a.feed.Send(stats)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// minAcceptedGasPrices returns the current gas prices scaled by the fee
+// threshold down, the lowest gas prices that the fee of a transaction can be
+// encoded with and still be accepted
+func (s *SyncService) minAcceptedGasPrices() (*big.Int, *big.Int) {
+	l1GasPrice, err := s.RollupGpo.SuggestL1GasPrice(context.Background())
+	if err != nil {
+		return nil, nil
+	}
+	l2GasPrice, err := s.RollupGpo.SuggestL2GasPrice(context.Background())
+	if err != nil {
+		return nil, nil
+	}
+	if thresholdDown := s.effectiveFeeThresholdDown(); thresholdDown != nil {
+		l1GasPrice, _ = new(big.Float).Mul(new(big.Float).SetInt(l1GasPrice), thresholdDown).Int(nil)
+		l2GasPrice, _ = new(big.Float).Mul(new(big.Float).SetInt(l2GasPrice), thresholdDown).Int(nil)
+	}
+	return l1GasPrice, l2GasPrice
+}
+
+// SubscribeAdmissionStats subscribes to the aggregate of the fee checks of
+// every second
+func (s *SyncService) SubscribeAdmissionStats(ch chan<- *fees.AdmissionStats) event.Subscription {
+	return s.admissions.scope.Track(s.admissions.feed.Subscribe(ch))
+}
@@ -0,0 +1,45 @@
+package rollup
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestAdmissionStats(t *testing.T) {
+	stats := &admissionStats{
+		minGasPrices: func() (*big.Int, *big.Int) { return big.NewInt(9), big.NewInt(1) },
+	}
+	stats.observe(&feeDecision{userFee: big.NewInt(100)}, nil)
+	stats.observe(&feeDecision{userFee: big.NewInt(200)}, nil)
+	stats.observe(&feeDecision{decision: feeDecisionOwner}, nil)
+	stats.observe(&feeDecision{userFee: big.NewInt(1)}, errors.New("fee too low"))
+
+	ch := make(chan *fees.AdmissionStats, 1)
+	sub := stats.feed.Subscribe(ch)
+	defer sub.Unsubscribe()
+	stats.feed.Send(stats.collect(time.Unix(1000, 0)))
+
+	got := <-ch
+	if got.Time != 1000 {
+		t.Fatalf("mismatched time: got %d, expect 1000", got.Time)
+	}
+	if got.Accepted != 3 || got.Rejected != 1 {
+		t.Fatalf("mismatched decisions: got %d accepted and %d rejected, expect 3 and 1", got.Accepted, got.Rejected)
+	}
+	// Only the transactions that pay with the gas price count towards the
+	// mean fee
+	if got.MeanFee.ToInt().Int64() != 150 {
+		t.Fatalf("mismatched mean fee: got %d, expect 150", got.MeanFee.ToInt())
+	}
+	if got.MinL1GasPrice.ToInt().Int64() != 9 || got.MinL2GasPrice.ToInt().Int64() != 1 {
+		t.Fatalf("mismatched min gas prices: got %d and %d, expect 9 and 1", got.MinL1GasPrice.ToInt(), got.MinL2GasPrice.ToInt())
+	}
+	// The next second starts empty
+	if next := stats.collect(time.Unix(1001, 0)); next.Accepted != 0 || next.MeanFee != nil {
+		t.Fatalf("aggregate not reset: got %d accepted", next.Accepted)
+	}
+}
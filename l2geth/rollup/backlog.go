@@ -0,0 +1,129 @@
+package rollup
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// errBacklogFull is the error for when the sequencer has accepted too
+	// many bytes of transactions that have not yet been batch submitted to L1
+	errBacklogFull = errors.New("unbatched backlog full")
+	// errBatchLag is the error for when the oldest transaction that has not
+	// been batch submitted to L1 is older than the configured maximum
+	errBatchLag = errors.New("batch submission lagging")
+)
+
+var (
+	backlogBytesGauge    = metrics.NewRegisteredGauge("rollup/backlog/bytes", nil)
+	backlogTxsGauge      = metrics.NewRegisteredGauge("rollup/backlog/txs", nil)
+	backlogLagGauge      = metrics.NewRegisteredGauge("rollup/backlog/lag", nil)
+	backlogDelayTimer    = metrics.NewRegisteredTimer("rollup/backlog/delay", nil)
+	backlogRejectMeter   = metrics.NewRegisteredMeter("rollup/backlog/reject", nil)
+	backlogThrottleMeter = metrics.NewRegisteredMeter("rollup/backlog/throttle", nil)
+)
+
+// backlogEntry represents a transaction that has been applied to the tip of
+// the chain by the sequencer but has not yet been batch submitted to L1
+type backlogEntry struct {
+	index uint64
+	size  uint64
+	time  time.Time
+}
+
+// backlog tracks the transactions that have been sequenced but not yet
+// observed in a transaction batch on L1. Entries are added in index order so
+// pruning only ever needs to look at the front of the queue.
+type backlog struct {
+	lock    sync.Mutex
+	entries []backlogEntry
+	bytes   uint64
+}
+
+// add appends a sequenced transaction to the backlog
+func (b *backlog) add(index, size uint64, t time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.entries = append(b.entries, backlogEntry{index: index, size: size, time: t})
+	b.bytes += size
+}
+
+// prune removes all of the entries with an index less than or equal to the
+// latest verified index
+func (b *backlog) prune(verified uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	i := 0
+	for ; i < len(b.entries); i++ {
+		if b.entries[i].index > verified {
+			break
+		}
+		b.bytes -= b.entries[i].size
+	}
+	b.entries = b.entries[i:]
+}
+
+// reset clears the backlog
+func (b *backlog) reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.entries = nil
+	b.bytes = 0
+}
+
+// stats returns the number of unbatched bytes, the number of unbatched
+// transactions and the age of the oldest unbatched transaction
+func (b *backlog) stats(now time.Time) (uint64, int, time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var lag time.Duration
+	if len(b.entries) > 0 {
+		lag = now.Sub(b.entries[0].time)
+	}
+	return b.bytes, len(b.entries), lag
+}
+
+// backlogThrottle is a proportional controller that slows down the rate at
+// which the sequencer accepts transactions as the unbatched backlog grows.
+// Between throttleBytes and maxBytes each transaction is delayed by a linearly
+// increasing amount up to maxDelay. Past maxBytes, or when the oldest
+// unbatched transaction is older than maxLag, transactions are rejected until
+// the batch submitter catches up. A zero value disables the corresponding
+// check.
+type backlogThrottle struct {
+	throttleBytes uint64
+	maxBytes      uint64
+	maxLag        time.Duration
+	maxDelay      time.Duration
+}
+
+// enabled returns true if any of the throttling conditions are configured
+func (t *backlogThrottle) enabled() bool {
+	return t.throttleBytes != 0 || t.maxBytes != 0 || t.maxLag != 0
+}
+
+// delay returns the amount of time that the sequencer should wait before
+// accepting the next transaction given the current backlog. An error is
+// returned when the transaction should not be accepted at all.
+func (t *backlogThrottle) delay(bytes uint64, lag time.Duration) (time.Duration, error) {
+	if t.maxBytes != 0 && bytes >= t.maxBytes {
+		return 0, errBacklogFull
+	}
+	if t.maxLag != 0 && lag >= t.maxLag {
+		return 0, errBatchLag
+	}
+	if t.throttleBytes == 0 || bytes <= t.throttleBytes {
+		return 0, nil
+	}
+	// Without an upper bound there is nothing to scale against, so apply
+	// the full delay once the throttle threshold has been crossed
+	if t.maxBytes <= t.throttleBytes {
+		return t.maxDelay, nil
+	}
+	over := float64(bytes - t.throttleBytes)
+	window := float64(t.maxBytes - t.throttleBytes)
+	return time.Duration(float64(t.maxDelay) * over / window), nil
+}
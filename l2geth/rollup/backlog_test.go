@@ -0,0 +1,124 @@
+package rollup
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBacklogPrune(t *testing.T) {
+	b := backlog{}
+	now := time.Now()
+	b.add(0, 100, now.Add(-3*time.Minute))
+	b.add(1, 200, now.Add(-2*time.Minute))
+	b.add(2, 300, now.Add(-1*time.Minute))
+
+	bytes, count, lag := b.stats(now)
+	if bytes != 600 {
+		t.Fatalf("Unexpected backlog bytes: got %d, expected %d", bytes, 600)
+	}
+	if count != 3 {
+		t.Fatalf("Unexpected backlog count: got %d, expected %d", count, 3)
+	}
+	if lag != 3*time.Minute {
+		t.Fatalf("Unexpected backlog lag: got %s, expected %s", lag, 3*time.Minute)
+	}
+
+	b.prune(1)
+	bytes, count, lag = b.stats(now)
+	if bytes != 300 {
+		t.Fatalf("Unexpected backlog bytes: got %d, expected %d", bytes, 300)
+	}
+	if count != 1 {
+		t.Fatalf("Unexpected backlog count: got %d, expected %d", count, 1)
+	}
+	if lag != time.Minute {
+		t.Fatalf("Unexpected backlog lag: got %s, expected %s", lag, time.Minute)
+	}
+
+	b.prune(10)
+	bytes, count, lag = b.stats(now)
+	if bytes != 0 || count != 0 || lag != 0 {
+		t.Fatalf("Backlog not empty: %d bytes, %d txs, %s lag", bytes, count, lag)
+	}
+}
+
+var backlogThrottleTests = map[string]struct {
+	throttle backlogThrottle
+	bytes    uint64
+	lag      time.Duration
+	delay    time.Duration
+	err      error
+}{
+	"disabled": {
+		throttle: backlogThrottle{},
+		bytes:    1_000_000,
+		lag:      time.Hour,
+		delay:    0,
+		err:      nil,
+	},
+	"below-threshold": {
+		throttle: backlogThrottle{throttleBytes: 1000, maxBytes: 2000, maxDelay: time.Second},
+		bytes:    1000,
+		delay:    0,
+		err:      nil,
+	},
+	"half-way": {
+		throttle: backlogThrottle{throttleBytes: 1000, maxBytes: 2000, maxDelay: time.Second},
+		bytes:    1500,
+		delay:    time.Second / 2,
+		err:      nil,
+	},
+	"no-max": {
+		throttle: backlogThrottle{throttleBytes: 1000, maxDelay: time.Second},
+		bytes:    1001,
+		delay:    time.Second,
+		err:      nil,
+	},
+	"full": {
+		throttle: backlogThrottle{throttleBytes: 1000, maxBytes: 2000, maxDelay: time.Second},
+		bytes:    2000,
+		delay:    0,
+		err:      errBacklogFull,
+	},
+	"lagging": {
+		throttle: backlogThrottle{maxLag: time.Minute},
+		lag:      time.Minute,
+		delay:    0,
+		err:      errBatchLag,
+	},
+}
+
+func TestBacklogThrottle(t *testing.T) {
+	for name, tt := range backlogThrottleTests {
+		t.Run(name, func(t *testing.T) {
+			delay, err := tt.throttle.delay(tt.bytes, tt.lag)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Unexpected error: got %v, expected %v", err, tt.err)
+			}
+			if delay != tt.delay {
+				t.Fatalf("Unexpected delay: got %s, expected %s", delay, tt.delay)
+			}
+		})
+	}
+}
+
+func TestSyncServiceBacklogFull(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.backlogThrottle = backlogThrottle{maxBytes: 100}
+	service.backlog.add(0, 100, time.Now())
+
+	err = service.throttle()
+	if !errors.Is(err, errBacklogFull) {
+		t.Fatalf("Unexpected error: got %v, expected %v", err, errBacklogFull)
+	}
+
+	// Once the transaction has been batch submitted, the backlog is cleared
+	service.backlog.prune(0)
+	if err := service.throttle(); err != nil {
+		t.Fatalf("Unexpected error after prune: %v", err)
+	}
+}
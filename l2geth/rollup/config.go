@@ -1,10 +1,15 @@
 package rollup
 
 import (
+	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rollup/rollupconfig"
 )
 
 type Config struct {
@@ -48,4 +53,305 @@ type Config struct {
 	// quoted and the transaction being executed
 	FeeThresholdDown *big.Float
 	FeeThresholdUp   *big.Float
+	// Slow down the acceptance of transactions once this many bytes of
+	// transactions have been sequenced but not yet batch submitted
+	ThrottleBacklogBytes uint64
+	// Reject transactions once this many bytes of transactions have been
+	// sequenced but not yet batch submitted
+	MaxBacklogBytes uint64
+	// Reject transactions when the oldest transaction that has not yet been
+	// batch submitted is older than this
+	MaxBatchLag time.Duration
+	// The maximum amount of time to delay a transaction when throttling
+	ThrottleMaxDelay time.Duration
+	// The native token that fees are paid in, nil when fees are paid in ETH
+	GasToken *fees.GasToken
+	// Sync the chain over the p2p network before deriving it, verifier only
+	P2PSync bool
+	// HTTP endpoint of a layer 1 node, used to read protocol version signals
+	L1NodeHttp string
+	// Address of the L1 contract that signals protocol versions, protocol
+	// version checks are disabled when not set
+	ProtocolVersionsAddress common.Address
+	// Halt when the required protocol version differs from the supported
+	// protocol version at this precision
+	ProtocolVersionHalt ProtocolVersionPrecision
+	// Reconcile the fees collected for each transaction batch against the
+	// cost of submitting it to L1, requires an L1 node
+	FeeReconciliation bool
+	// Report expected fees that are greater than this multiple of the
+	// trailing median, disabled when zero
+	FeeAnomalyMultiple float64
+	// Report gas prices that change by more than this fraction between two
+	// updates, disabled when zero
+	FeeAnomalyGasPriceJump float64
+	// Webhooks that fee anomaly alerts are posted to
+	FeeAnomalyWebhooks []string
+	// PagerDuty routing key included in fee anomaly alerts
+	FeeAnomalyRoutingKey string
+	// Key that fee quotes are signed with, fee quotes are disabled when nil
+	FeeQuoteKey *ecdsa.PrivateKey
+	// Number of L2 blocks that fee quotes are valid for
+	FeeQuoteValidity uint64
+	// Fees per block that are accepted at quoted gas prices, averaged over
+	// the blocks that quotes are valid for, unbounded when nil
+	FeeQuoteMaxVolume *big.Int
+	// Fee parameters of several networks, the profile of the L2 chain fills
+	// in the parameters that are not set
+	NetworkProfiles NetworkProfiles
+	// Read the OVM_GasPriceOracle with both storage layouts of a migration
+	// while it is in progress
+	GasPriceOracleLayoutMigration *rcfg.LayoutMigration
+	// Key that the reports of replayed fees are signed with, fee replay is
+	// disabled when it is not set
+	FeeAttestationKey *ecdsa.PrivateKey
+	// Number of workers that verify the fees of a set of sequencer
+	// transactions concurrently, the number of CPUs when zero
+	FeeValidationWorkers int
+	// Accept sequencer transactions while the OVM_GasPriceOracle is not
+	// initialized, when fees are enforced they are refused until it is
+	AllowUninitializedGPO bool
+	// Fail the verification of blocks whose transactions were charged fees
+	// that do not match the gas price oracle of the parent state
+	FeeAssertion bool
+	// Upstream node that the OVM_GasPriceOracle is read from with eth_call
+	// when the local state is not available, such as on a replica without
+	// the state of the tip
+	GasPriceOracleUpstreamHttp string
+	// How long the values read from the upstream node are cached
+	GasPriceOracleUpstreamTTL time.Duration
+	// How long the values read from the upstream node are used while it
+	// cannot be reached
+	GasPriceOracleUpstreamMaxStaleness time.Duration
+	// Read replica of the chain that the OVM_GasPriceOracle is read from
+	// with eth_call while transactions are applied to the local state
+	GasPriceOracleReplicaHttp string
+	// Number of blocks that the replica can be behind the local chain
+	GasPriceOracleReplicaMaxLag uint64
+	// Node that the gas prices served over RPC are read from with
+	// rollup_gasPrices, for nodes without the state or the L1 connection of
+	// the sequencer
+	RemoteRollupOracleHttp string
+	// How long the gas prices read from the remote node are cached
+	RemoteRollupOracleTTL time.Duration
+	// Read the L1 gas price from the base fee of the L1 node rather than
+	// from the data transport layer, verifier only
+	VerifierL1GasPrice bool
+	// Tune the fee threshold down from the volatility of the L1 gas price,
+	// FeeThresholdDown is used until enough L1 gas prices are observed
+	FeeThresholdDownAuto bool
+	// Lowest fee threshold down that is set automatically
+	FeeThresholdDownMin float64
+	// Tune the fee threshold down to keep the rate of false rejections under
+	// this target, disabled when zero
+	FeeThresholdDownTarget float64
+	// JSON file of fee policies for specific contracts and methods, reloaded
+	// when it changes
+	FeePolicyFile string
+	// JSON file of candidate fee policies that fees are checked against as
+	// well, logging where the outcome differs without enforcing them
+	FeePolicyShadowFile string
+	// Go plugins that export a rollup.FeeHook that can veto or adjust the fee
+	// of a transaction, run in order before the hooks compiled into the node
+	FeeHookPlugins []string
+	// URL of the Kafka REST Proxy that fee events are published to
+	FeeEventsUrl string
+	// Kafka topic that fee events are published to
+	FeeEventsTopic string
+	// Directory that a JSON record of every fee decision is appended to,
+	// disabled when empty
+	FeeAuditLog string
+	// Size in bytes that a file of the fee audit log grows to before a new
+	// file is started
+	FeeAuditLogSize uint
+	// File that the anonymized inputs of the fee checks are appended to for
+	// backtesting the fee thresholds, disabled when empty
+	FeeCheckRecord string
+	// Period after which the fee checks are no longer recorded
+	FeeCheckRecordPeriod time.Duration
+	// Percentage that the gas prices that fees are suggested from are raised
+	// by, so that fewer transactions are rejected when the L1 gas price rises
+	FeeEstimateMargin uint64
+	// Accept every transaction without a fee and keep the gas prices at
+	// zero, for local development and tests without a gas price oracle
+	NoFees bool
+	// Record the L1 fee in the receipts of the blocks that were processed
+	// before it was recorded, from their historical state
+	HydrateReceipts bool
+	// Address of the L1 price feed of ether in the quote currency that fees
+	// are quoted in, the gas token is the quote currency when not set
+	ConversionFeedAddress common.Address
+	// Symbol and decimals of the quote currency of the price feed
+	QuoteCurrencySymbol   string
+	QuoteCurrencyDecimals uint8
+	// Prices of the price feed that are older than this are refused, no
+	// limit when zero
+	ConversionFeedMaxAge time.Duration
+	// Data availability layer that the batch submitter posts the data of
+	// the transactions to, the L1 fee prices the data on it. One of
+	// calldata, the default, blob or external.
+	DABackend string
+	// Price of a byte on the external data availability layer in the
+	// smallest denomination of its token, and the L1 price feed of ether in
+	// the token with the decimals of the token
+	DACostPerByte      *big.Int
+	DAPriceFeedAddress common.Address
+	DATokenDecimals    uint8
+	// EIP-3009 tokens that fees can be paid in with a transfer authorization
+	FeeTokens FeeTokens
+	// Key of the account that executes the transfer authorizations and
+	// receives the fee tokens, required with fee tokens
+	FeeCollectorKey *ecdsa.PrivateKey
+	// Key of the operator that records the rebates of overcharged senders in
+	// the OVM_FeeRebateVault once the L1 cost of their batch is known,
+	// requires fee reconciliation
+	FeeRebateKey *ecdsa.PrivateKey
+	// Keep a profit and loss ledger of the sequencer, requires fee
+	// reconciliation
+	PnL bool
+	// Address of the L1 state commitment chain, the proposer spend is added
+	// to the profit and loss ledger when set
+	StateCommitmentChainAddress common.Address
+	// Premium over the expected fee that a transaction must pay to be
+	// applied ahead of the transactions that wait in the default lane, the
+	// express lane is disabled when nil
+	ExpressLaneMultiplier *big.Float
+	// Number of transactions that can wait in the express lane
+	ExpressLaneCapacity int
+	// Apply sequencer transactions strictly in the order in which they
+	// arrived regardless of their fee, and record their arrival
+	FCFS bool
+	// Labels of the applications that contracts belong to, the fee revenue
+	// and calldata usage of their transactions are reported per label
+	DappLabels DappLabels
+	// Names and presence of the rollup fields of the receipts served by
+	// each RPC namespace
+	ReceiptProfiles ReceiptProfiles
+	// Configuration shared with the other services of the rollup, checked
+	// against the chain config at startup when set
+	RollupConfig *rollupconfig.RollupConfig
+}
+
+// Validate checks the values of the config and the constraints between them
+// that can be checked without connecting to any other service. It is called
+// when the SyncService is created and by the check-config command of geth.
+func (c *Config) Validate() error {
+	// Ensure sane values for the fee thresholds
+	if c.FeeThresholdDown != nil && c.FeeThresholdDown.Cmp(float1) != -1 {
+		return fmt.Errorf("%w: fee threshold down not lower than 1: %f", errBadConfig, c.FeeThresholdDown)
+	}
+	if c.FeeThresholdDownMin < 0 || c.FeeThresholdDownMin >= 1 {
+		return fmt.Errorf("%w: min fee threshold down not between 0 and 1: %f", errBadConfig, c.FeeThresholdDownMin)
+	}
+	if c.FeeThresholdUp != nil && c.FeeThresholdUp.Cmp(float1) != 1 {
+		return fmt.Errorf("%w: fee threshold up not larger than 1: %f", errBadConfig, c.FeeThresholdUp)
+	}
+	// Fees suggested with the estimate margin must not be rejected for
+	// overpaying
+	if c.FeeThresholdUp != nil && c.FeeEstimateMargin > 0 {
+		margin := new(big.Float).SetFloat64(float64(c.FeeEstimateMargin) / 100)
+		if margin.Cmp(c.FeeThresholdUp) == 1 {
+			return fmt.Errorf("%w: fee estimate margin of %d%% above the fee threshold up: %f", errBadConfig,
+				c.FeeEstimateMargin, c.FeeThresholdUp)
+		}
+	}
+	if c.FeeThresholdDownTarget != 0 {
+		if c.FeeThresholdDownAuto {
+			return fmt.Errorf("%w: fee threshold down is tuned either from the L1 gas price or from false rejections", errBadConfig)
+		}
+		if c.FeeThresholdDownTarget < 0 || c.FeeThresholdDownTarget >= 1 {
+			return fmt.Errorf("%w: false rejection target not between 0 and 1: %f", errBadConfig, c.FeeThresholdDownTarget)
+		}
+		min := c.FeeThresholdDownMin
+		if min <= 0 {
+			min = defaultThresholdDownMin
+		}
+		if c.FeeThresholdDown != nil && c.FeeThresholdDown.Cmp(big.NewFloat(min)) == -1 {
+			return fmt.Errorf("%w: min fee threshold down above fee threshold down: %f", errBadConfig, min)
+		}
+	}
+	// Only verifiers can sync the chain from peers, the sequencer is the
+	// source of the chain
+	if c.P2PSync && !c.IsVerifier {
+		return fmt.Errorf("%w: p2p sync is only supported in verifier mode", errBadConfig)
+	}
+	// Ensure sane values for the backlog throttle
+	if c.MaxBacklogBytes != 0 && c.ThrottleBacklogBytes > c.MaxBacklogBytes {
+		return fmt.Errorf("%w: throttle backlog bytes %d larger than max backlog bytes %d",
+			errBadConfig, c.ThrottleBacklogBytes, c.MaxBacklogBytes)
+	}
+	switch c.DABackend {
+	case "", fees.DABackendCalldata, fees.DABackendBlob:
+	case fees.DABackendExternal:
+		if c.DACostPerByte == nil || c.DACostPerByte.Sign() <= 0 {
+			return fmt.Errorf("%w: external data availability requires a positive cost per byte", errBadConfig)
+		}
+		if c.DAPriceFeedAddress == (common.Address{}) {
+			return fmt.Errorf("%w: external data availability requires a price feed", errBadConfig)
+		}
+	default:
+		return fmt.Errorf("%w: %s %q", errBadConfig, fees.ErrUnknownDABackend, c.DABackend)
+	}
+	if len(c.FeeTokens) != 0 && c.FeeCollectorKey == nil {
+		return fmt.Errorf("%w: fee tokens require a fee collector key", errBadConfig)
+	}
+	if c.FeeEventsUrl != "" && c.FeeEventsTopic == "" {
+		return fmt.Errorf("%w: no topic for fee events", errBadConfig)
+	}
+	if c.FeeAuditLog != "" && c.FeeAuditLogSize == 0 {
+		return fmt.Errorf("%w: fee audit log size must be positive", errBadConfig)
+	}
+	if c.FeeCheckRecord != "" && c.FeeCheckRecordPeriod <= 0 {
+		return fmt.Errorf("%w: fee check record period must be positive", errBadConfig)
+	}
+	if c.FeeQuoteKey != nil && c.FeeQuoteValidity == 0 {
+		return fmt.Errorf("%w: fee quotes must be valid for at least one block", errBadConfig)
+	}
+	// Features that read from L1
+	if c.L1NodeHttp == "" {
+		switch {
+		case c.ProtocolVersionsAddress != (common.Address{}):
+			return fmt.Errorf("%w: protocol versions address requires an L1 node", errBadConfig)
+		case c.ConversionFeedAddress != (common.Address{}):
+			return fmt.Errorf("%w: conversion feed requires an L1 node", errBadConfig)
+		case c.FeeReconciliation:
+			return fmt.Errorf("%w: fee reconciliation requires an L1 node", errBadConfig)
+		case c.DABackend == fees.DABackendBlob || c.DABackend == fees.DABackendExternal:
+			return fmt.Errorf("%w: %s data availability requires an L1 node", errBadConfig, c.DABackend)
+		}
+	}
+	if c.VerifierL1GasPrice {
+		if !c.IsVerifier {
+			return fmt.Errorf("%w: the sequencer sets the L1 gas price", errBadConfig)
+		}
+		if c.L1NodeHttp == "" {
+			return fmt.Errorf("%w: observing the L1 gas price requires an L1 node", errBadConfig)
+		}
+	}
+	if c.FeeRebateKey != nil {
+		if !c.FeeReconciliation {
+			return fmt.Errorf("%w: fee rebates require fee reconciliation", errBadConfig)
+		}
+		if c.IsVerifier {
+			return fmt.Errorf("%w: fee rebates are recorded by the sequencer", errBadConfig)
+		}
+	}
+	if c.PnL && !c.FeeReconciliation {
+		return fmt.Errorf("%w: the profit and loss ledger requires fee reconciliation", errBadConfig)
+	}
+	if c.ExpressLaneMultiplier != nil {
+		if c.FCFS {
+			return fmt.Errorf("%w: the express lane cannot be used first come first served", errBadConfig)
+		}
+		if c.ExpressLaneMultiplier.Cmp(float1) != 1 {
+			return fmt.Errorf("%w: express lane multiplier not larger than 1: %f", errBadConfig, c.ExpressLaneMultiplier)
+		}
+		// Transactions that pay the premium must not be rejected for
+		// overpaying
+		if c.FeeThresholdUp != nil && c.ExpressLaneMultiplier.Cmp(c.FeeThresholdUp) == 1 {
+			return fmt.Errorf("%w: express lane multiplier %f above the fee threshold up: %f", errBadConfig,
+				c.ExpressLaneMultiplier, c.FeeThresholdUp)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,69 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+type testBlobBaseFee struct {
+	fee *big.Int
+	err error
+}
+
+func (s *testBlobBaseFee) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	return s.fee, s.err
+}
+
+func TestDACostBackend(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = 1
+	}
+	// Enough for the batch overhead and a thousand bytes of blob space, far
+	// too little for a thousand bytes of calldata
+	l1Fee := new(big.Int).Mul(new(big.Int).SetUint64(fees.Overhead), l1GasPrice)
+	l1Fee.Add(l1Fee, big.NewInt(10_000*params.GWei))
+	gasLimit := fees.EncodeTxGasLimitForL1Fee(l1Fee, big.NewInt(100_000), l2GasPrice)
+	tx, err := types.SignTx(types.NewTransaction(0, testPolicyToken, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, data), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errUnavailable := errors.New("unavailable")
+
+	tests := map[string]struct {
+		estimator fees.DACostEstimator
+		err       error
+	}{
+		"calldata":         {fees.CalldataDACost{}, fees.ErrFeeTooLow},
+		"blob":             {fees.NewBlobDACost(&testBlobBaseFee{fee: big.NewInt(1 * params.GWei)}, time.Minute), nil},
+		"blob-unavailable": {fees.NewBlobDACost(&testBlobBaseFee{err: errUnavailable}, time.Minute), errUnavailable},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service.daCost = tt.estimator
+			err := service.verifyFee(context.Background(), tx)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"regexp"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// dappLabelPattern is the pattern of labels, which are part of metric names
+var dappLabelPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// DappLabels are the labels of the applications that contracts belong to,
+// keyed by the address of the contract. Several contracts can share a label.
+type DappLabels map[common.Address]string
+
+// LoadDappLabels reads the labels of contracts from a JSON file
+func LoadDappLabels(path string) (DappLabels, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read dapp labels: %w", err)
+	}
+	var labels DappLabels
+	if err := json.Unmarshal(raw, &labels); err != nil {
+		return nil, fmt.Errorf("Cannot decode dapp labels: %w", err)
+	}
+	for address, label := range labels {
+		if !dappLabelPattern.MatchString(label) {
+			return nil, fmt.Errorf("%w: label %q of %s must be lowercase letters, digits, dashes and underscores", errBadConfig, label, address.Hex())
+		}
+	}
+	return labels, nil
+}
+
+// dappMeters are the meters of the fee revenue and calldata usage of the
+// transactions to the contracts of a label
+type dappMeters struct {
+	txs       metrics.Counter
+	fee       metrics.Counter
+	l1GasUsed metrics.Counter
+	calldata  metrics.Counter
+}
+
+// dappMetrics aggregates the fee components of the blocks per label. The fee
+// is reported in gwei to fit in the int64 counter.
+type dappMetrics struct {
+	labels DappLabels
+	lock   sync.Mutex
+	meters map[string]*dappMeters
+}
+
+func newDappMetrics(labels DappLabels) *dappMetrics {
+	return &dappMetrics{labels: labels, meters: make(map[string]*dappMeters)}
+}
+
+// metersOf returns the meters of a label, which are registered on first use
+func (d *dappMetrics) metersOf(label string) *dappMeters {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	m, ok := d.meters[label]
+	if !ok {
+		prefix := "rollup/dapp/" + label + "/"
+		m = &dappMeters{
+			txs:       metrics.GetOrRegisterCounter(prefix+"txs", nil),
+			fee:       metrics.GetOrRegisterCounter(prefix+"fee", nil),
+			l1GasUsed: metrics.GetOrRegisterCounter(prefix+"l1gasused", nil),
+			calldata:  metrics.GetOrRegisterCounter(prefix+"calldata", nil),
+		}
+		d.meters[label] = m
+	}
+	return m
+}
+
+// record adds the fee components of the transactions to labeled contracts in
+// a block to the meters of their label
+func (d *dappMetrics) record(blockFees *fees.BlockFees) {
+	if d == nil || blockFees == nil {
+		return
+	}
+	for _, c := range blockFees.Contracts {
+		label, ok := d.labels[c.Address]
+		if !ok {
+			continue
+		}
+		m := d.metersOf(label)
+		m.txs.Inc(int64(c.Transactions))
+		m.fee.Inc(new(big.Int).Div(c.Fee, big.NewInt(params.GWei)).Int64())
+		m.l1GasUsed.Inc(int64(c.L1GasUsed))
+		m.calldata.Inc(int64(c.CalldataSize))
+	}
+}
@@ -0,0 +1,82 @@
+package rollup
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestLoadDappLabels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dapp-labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		json  string
+		count int
+		err   error
+	}{
+		"valid": {
+			json:  `{"0x0000000000000000000000000000000000000001": "dex", "0x0000000000000000000000000000000000000002": "dex"}`,
+			count: 2,
+		},
+		"bad-label": {
+			json: `{"0x0000000000000000000000000000000000000001": "My Dex"}`,
+			err:  errBadConfig,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".json")
+			if err := ioutil.WriteFile(path, []byte(tt.json), 0600); err != nil {
+				t.Fatal(err)
+			}
+			labels, err := LoadDappLabels(path)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if len(labels) != tt.count {
+				t.Fatalf("mismatched labels: got %d, expect %d", len(labels), tt.count)
+			}
+		})
+	}
+}
+
+func TestDappMetricsRecord(t *testing.T) {
+	// Counters are only counted when metrics are enabled
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	router, pair, other := common.Address{0x01}, common.Address{0x02}, common.Address{0x03}
+	d := newDappMetrics(DappLabels{router: "test-dex", pair: "test-dex"})
+
+	b := fees.NewBlockFees(1, 10)
+	b.AddL1GasTo(router, 1000, 100, 21000, big.NewInt(1e9))
+	b.AddL1GasTo(pair, 2000, 200, 42000, big.NewInt(1e9))
+	b.AddL1GasTo(other, 4000, 400, 21000, big.NewInt(1e9))
+	d.record(b)
+
+	m := d.metersOf("test-dex")
+	if txs := m.txs.Count(); txs != 2 {
+		t.Fatalf("mismatched transactions: got %d, expect %d", txs, 2)
+	}
+	if fee := m.fee.Count(); fee != 63000 {
+		t.Fatalf("mismatched fee: got %d, expect %d", fee, 63000)
+	}
+	if calldata := m.calldata.Count(); calldata != 300 {
+		t.Fatalf("mismatched calldata size: got %d, expect %d", calldata, 300)
+	}
+	if len(d.meters) != 1 {
+		t.Fatalf("mismatched labels: got %d, expect %d", len(d.meters), 1)
+	}
+}
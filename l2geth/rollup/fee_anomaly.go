@@ -0,0 +1,220 @@
+package rollup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// feeAnomalyWindow is the number of trailing expected fees that the
+	// median is computed over
+	feeAnomalyWindow = 256
+	// feeAnomalyMinSamples is the number of expected fees that must be
+	// observed before fee anomalies are reported
+	feeAnomalyMinSamples = 32
+	// feeAnomalyCooldown is the minimum amount of time between two alerts of
+	// the same kind so that a sustained anomaly does not flood the webhooks
+	feeAnomalyCooldown = 10 * time.Minute
+	// feeAnomalyTimeout is the timeout for delivering an alert to a webhook
+	feeAnomalyTimeout = 10 * time.Second
+)
+
+// The kinds of anomalies that are detected, used as the dedup key of alerts
+const (
+	anomalyExpectedFee = "expected-fee"
+	anomalyL1GasPrice  = "l1-gas-price"
+	anomalyL2GasPrice  = "l2-gas-price"
+)
+
+var (
+	feeAnomalyMeter       = metrics.NewRegisteredMeter("rollup/anomaly/fee", nil)
+	gasPriceAnomalyMeter  = metrics.NewRegisteredMeter("rollup/anomaly/gasprice", nil)
+	anomalyAlertFailMeter = metrics.NewRegisteredMeter("rollup/anomaly/alert/failed", nil)
+)
+
+// anomalyAlert is the body posted to the alert webhooks. It follows the
+// PagerDuty Events API v2 format so that it can be sent to PagerDuty
+// directly, other webhooks receive the same body.
+type anomalyAlert struct {
+	RoutingKey  string         `json:"routing_key,omitempty"`
+	EventAction string         `json:"event_action"`
+	DedupKey    string         `json:"dedup_key"`
+	Payload     anomalyPayload `json:"payload"`
+}
+
+type anomalyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+// anomalyDetector flags fee conditions that are statistically unusual: an
+// expected fee that is a large multiple of the trailing median, or a gas
+// price that jumps by a large fraction between two updates. Anomalies are
+// logged, metered and sent to the configured webhooks.
+type anomalyDetector struct {
+	multiple   float64
+	jump       float64
+	webhooks   []string
+	routingKey string
+	source     string
+	client     *http.Client
+
+	lock      sync.Mutex
+	fees      []*big.Int
+	next      int
+	gasPrices map[string]*big.Int
+	alerted   map[string]time.Time
+}
+
+// newAnomalyDetector creates an anomalyDetector. An expected fee greater
+// than multiple times the trailing median or a relative gas price change
+// greater than jump is an anomaly, either check is disabled when zero.
+func newAnomalyDetector(multiple, jump float64, webhooks []string, routingKey, source string) *anomalyDetector {
+	return &anomalyDetector{
+		multiple:   multiple,
+		jump:       jump,
+		webhooks:   webhooks,
+		routingKey: routingKey,
+		source:     source,
+		client:     &http.Client{Timeout: feeAnomalyTimeout},
+		gasPrices:  make(map[string]*big.Int),
+		alerted:    make(map[string]time.Time),
+	}
+}
+
+// observeFee records an expected fee and reports it if it is anomalous
+// compared to the trailing window of expected fees
+func (d *anomalyDetector) observeFee(fee *big.Int) {
+	if d == nil || d.multiple <= 0 || fee == nil {
+		return
+	}
+	d.lock.Lock()
+	median := d.median()
+	if len(d.fees) < feeAnomalyWindow {
+		d.fees = append(d.fees, new(big.Int).Set(fee))
+	} else {
+		d.fees[d.next] = new(big.Int).Set(fee)
+		d.next = (d.next + 1) % feeAnomalyWindow
+	}
+	d.lock.Unlock()
+
+	if median == nil || median.Sign() == 0 {
+		return
+	}
+	limit, _ := new(big.Float).Mul(new(big.Float).SetInt(median), big.NewFloat(d.multiple)).Int(nil)
+	if fee.Cmp(limit) <= 0 {
+		return
+	}
+	feeAnomalyMeter.Mark(1)
+	d.report(anomalyExpectedFee, fmt.Sprintf("Expected fee %s exceeds %g times the trailing median %s", fee, d.multiple, median),
+		map[string]string{
+			"expectedFee": fee.String(),
+			"median":      median.String(),
+			"multiple":    fmt.Sprint(d.multiple),
+		})
+}
+
+// median returns the median of the trailing window of expected fees, nil
+// when not enough fees have been observed. Must be called with the lock held.
+func (d *anomalyDetector) median() *big.Int {
+	if len(d.fees) < feeAnomalyMinSamples {
+		return nil
+	}
+	sorted := make([]*big.Int, len(d.fees))
+	copy(sorted, d.fees)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	return sorted[len(sorted)/2]
+}
+
+// observeGasPrice records a new value of a gas price and reports it if it
+// changed by more than the jump threshold since the previous value
+func (d *anomalyDetector) observeGasPrice(kind string, price *big.Int) {
+	if d == nil || d.jump <= 0 || price == nil {
+		return
+	}
+	d.lock.Lock()
+	prev := d.gasPrices[kind]
+	d.gasPrices[kind] = new(big.Int).Set(price)
+	d.lock.Unlock()
+
+	if prev == nil || prev.Sign() == 0 || prev.Cmp(price) == 0 {
+		return
+	}
+	diff := new(big.Float).SetInt(new(big.Int).Sub(price, prev))
+	change, _ := diff.Quo(diff, new(big.Float).SetInt(prev)).Float64()
+	if change <= d.jump && change >= -d.jump {
+		return
+	}
+	gasPriceAnomalyMeter.Mark(1)
+	d.report(kind, fmt.Sprintf("%s changed by %.0f%% from %s to %s", kind, change*100, prev, price),
+		map[string]string{
+			"previous": prev.String(),
+			"current":  price.String(),
+			"change":   fmt.Sprintf("%.4f", change),
+		})
+}
+
+// report logs the anomaly and sends it to the webhooks unless an alert of
+// the same kind was sent recently
+func (d *anomalyDetector) report(kind, summary string, details map[string]string) {
+	log.Warn("Fee anomaly detected", "kind", kind, "summary", summary)
+	if len(d.webhooks) == 0 {
+		return
+	}
+	now := time.Now()
+	d.lock.Lock()
+	if last, ok := d.alerted[kind]; ok && now.Sub(last) < feeAnomalyCooldown {
+		d.lock.Unlock()
+		return
+	}
+	d.alerted[kind] = now
+	d.lock.Unlock()
+
+	alert := &anomalyAlert{
+		RoutingKey:  d.routingKey,
+		EventAction: "trigger",
+		DedupKey:    fmt.Sprintf("%s/%s", d.source, kind),
+		Payload: anomalyPayload{
+			Summary:       summary,
+			Source:        d.source,
+			Severity:      "warning",
+			Timestamp:     now.UTC().Format(time.RFC3339),
+			CustomDetails: details,
+		},
+	}
+	go d.send(alert)
+}
+
+// send posts the alert to every webhook
+func (d *anomalyDetector) send(alert *anomalyAlert) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		log.Error("Cannot encode fee anomaly alert", "msg", err)
+		return
+	}
+	for _, url := range d.webhooks {
+		resp, err := d.client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Error("Cannot send fee anomaly alert", "url", url, "msg", err)
+			anomalyAlertFailMeter.Mark(1)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			log.Error("Fee anomaly alert rejected", "url", url, "status", resp.Status)
+			anomalyAlertFailMeter.Mark(1)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package rollup
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAnomalyDetectorFee(t *testing.T) {
+	alerts, server := newAlertServer(t)
+	defer server.Close()
+
+	d := newAnomalyDetector(10, 0, []string{server.URL}, "routing-key", "test")
+	// No alerts are sent before the window has enough samples
+	d.observeFee(big.NewInt(1_000_000))
+	for i := 0; i < feeAnomalyMinSamples; i++ {
+		d.observeFee(big.NewInt(1000))
+	}
+	select {
+	case alert := <-alerts:
+		t.Fatalf("unexpected alert: %s", alert.Payload.Summary)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A fee within the multiple is not an anomaly
+	d.observeFee(big.NewInt(10_000))
+	// A fee above the multiple is an anomaly
+	d.observeFee(big.NewInt(10_001))
+	select {
+	case alert := <-alerts:
+		if alert.RoutingKey != "routing-key" {
+			t.Fatalf("unexpected routing key: %s", alert.RoutingKey)
+		}
+		if alert.DedupKey != "test/"+anomalyExpectedFee {
+			t.Fatalf("unexpected dedup key: %s", alert.DedupKey)
+		}
+		if alert.Payload.CustomDetails["expectedFee"] != "10001" {
+			t.Fatalf("unexpected fee: %s", alert.Payload.CustomDetails["expectedFee"])
+		}
+		if alert.Payload.CustomDetails["median"] != "1000" {
+			t.Fatalf("unexpected median: %s", alert.Payload.CustomDetails["median"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected alert")
+	}
+
+	// Repeated anomalies of the same kind are rate limited
+	d.observeFee(big.NewInt(1_000_000))
+	select {
+	case alert := <-alerts:
+		t.Fatalf("unexpected alert: %s", alert.Payload.Summary)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAnomalyDetectorGasPrice(t *testing.T) {
+	tests := map[string]struct {
+		prev    int64
+		current int64
+		alert   bool
+	}{
+		"unchanged":     {100, 100, false},
+		"small-rise":    {100, 150, false},
+		"large-rise":    {100, 151, true},
+		"small-drop":    {100, 50, false},
+		"large-drop":    {100, 49, true},
+		"from-zero":     {0, 100, false},
+		"large-to-zero": {100, 0, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			alerts, server := newAlertServer(t)
+			defer server.Close()
+
+			d := newAnomalyDetector(0, 0.5, []string{server.URL}, "", "test")
+			d.observeGasPrice(anomalyL2GasPrice, big.NewInt(tt.prev))
+			d.observeGasPrice(anomalyL2GasPrice, big.NewInt(tt.current))
+			timeout := 50 * time.Millisecond
+			if tt.alert {
+				timeout = time.Second
+			}
+			select {
+			case alert := <-alerts:
+				if !tt.alert {
+					t.Fatalf("unexpected alert: %s", alert.Payload.Summary)
+				}
+				if alert.DedupKey != "test/"+anomalyL2GasPrice {
+					t.Fatalf("unexpected dedup key: %s", alert.DedupKey)
+				}
+			case <-time.After(timeout):
+				if tt.alert {
+					t.Fatal("expected alert")
+				}
+			}
+		})
+	}
+}
+
+// newAlertServer starts a webhook server that decodes the alerts it
+// receives into the returned channel
+func newAlertServer(t *testing.T) (chan *anomalyAlert, *httptest.Server) {
+	alerts := make(chan *anomalyAlert, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert anomalyAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("cannot decode alert: %v", err)
+		}
+		alerts <- &alert
+	}))
+	return alerts, server
+}
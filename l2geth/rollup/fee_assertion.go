@@ -0,0 +1,99 @@
+package rollup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// errFeeAssertion is the error for when the fee that a transaction was
+// charged does not match the fee that is recomputed from the state of its
+// parent block
+var errFeeAssertion = errors.New("fee assertion failed")
+
+var feeAssertionFailureMeter = metrics.NewRegisteredMeter("rollup/fee/assertion/failure", nil)
+
+// assertFees checks the fees of the transactions of a block against the
+// state of its parent, which holds the gas price oracle that the sequencer
+// charged the transactions with. It is run as the fee assertion of the
+// chain, so a mismatch fails the import of the block.
+func (s *SyncService) assertFees(block *types.Block, parent *state.StateDB) error {
+	for i, tx := range block.Transactions() {
+		if err := assertFee(s.bc.Config(), block.Number(), tx, parent); err != nil {
+			return fmt.Errorf("block %d tx %d: %w", block.NumberU64(), i, err)
+		}
+	}
+	return nil
+}
+
+// assertFee checks the fee that a transaction of the L2 block number was
+// charged against the fee that is recomputed from chain data alone: the
+// transaction, the gas price oracle in the state of its parent and the chain
+// config. The flags of the node do not take part, so that every verifier
+// comes to the same result. The gas limit encodes the L1 fee up to rounding,
+// which must cover the expected L1 fee after the L2 fee at the L2 gas price
+// of the oracle.
+func assertFee(config *params.ChainConfig, number *big.Int, tx *types.Transaction, parent rcfg.StateReader) error {
+	// Transactions from L1 and transactions of the owner of the gas price
+	// oracle do not pay a fee
+	if tx.QueueOrigin() != types.QueueOriginSequencer || tx.GasPrice().Sign() == 0 {
+		return nil
+	}
+	slots, err := rcfg.ReadGPOStorageSlots(parent)
+	if err != nil {
+		return fmt.Errorf("%w: cannot read gas price oracle: %v", errFeeAssertion, err)
+	}
+	expect, err := expectedL1Fee(config, number, tx, parent)
+	if err != nil {
+		return fmt.Errorf("%w: cannot compute L1 fee: %v", errFeeAssertion, err)
+	}
+	charged := fees.MaxChargedL1Fee(tx.Gas(), slots.GasPrice)
+	if charged.Cmp(expect) >= 0 {
+		return nil
+	}
+	feeAssertionFailureMeter.Mark(1)
+	log.Error("Fee assertion failed", "hash", tx.Hash().Hex(), "gas", tx.Gas(), "l2-gas-price", slots.GasPrice, "l1-fee", charged, "expected-l1-fee", expect)
+	return fmt.Errorf("%w: tx %s charged L1 fee %d at L2 gas price %d, expected %d", errFeeAssertion, tx.Hash().Hex(), charged, slots.GasPrice, expect)
+}
+
+// expectedL1Fee returns the L1 fee of a transaction at the L1 fee params of
+// the gas price oracle in the state, with the calldata priced by the fee
+// schedule of the chain config. The subsidy of the target of the transaction
+// is deducted when the feeSubsidy rule is active. Layouts of the oracle that
+// do not store the L1 gas price only assert that the L2 fee is covered.
+func expectedL1Fee(config *params.ChainConfig, number *big.Int, tx *types.Transaction, statedb rcfg.StateReader) (*big.Int, error) {
+	l1Fee, err := core.L1FeeAt(config, number, tx.L1BlockNumber(), tx.Data(), statedb)
+	if errors.Is(err, rcfg.ErrUnknownLayout) {
+		return new(big.Int), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if to := tx.To(); to != nil && config.RollupRules(tx.L1BlockNumber()).FeeSubsidy {
+		subsidy := rcfg.ReadFeeSubsidy(statedb, *to)
+		l1Fee, _ = fees.SubsidizeL1Fee(l1Fee, subsidy.Rate, subsidy.Balance)
+	}
+	return l1Fee, nil
+}
+
+// assertTipFee checks the fee of a transaction that the verifier is about to
+// apply against the state of the tip, which becomes the parent state of the
+// block of the transaction
+func (s *SyncService) assertTipFee(tx *types.Transaction) error {
+	statedb, err := s.bc.State()
+	if err != nil {
+		return err
+	}
+	number := new(big.Int).Add(s.bc.CurrentBlock().Number(), common.Big1)
+	return assertFee(s.bc.Config(), number, tx, statedb)
+}
@@ -0,0 +1,109 @@
+package rollup
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+func TestAssertFee(t *testing.T) {
+	data := make([]byte, 100)
+	l1GasPrice := big.NewInt(30 * params.GWei)
+	l2GasPrice := big.NewInt(params.GWei)
+	l2GasLimit := big.NewInt(100_000)
+	to := common.Address{0x01}
+
+	// newTx returns a transaction that pays the fee at the gas prices
+	newTx := func(queueOrigin types.QueueOrigin, l2GasPrice, l1GasPrice, gasPrice *big.Int) *types.Transaction {
+		gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+		tx := types.NewTransaction(0, to, new(big.Int), gasLimit.Uint64(), gasPrice, data)
+		index := uint64(0)
+		tx.SetTransactionMeta(types.NewTransactionMeta(new(big.Int), 0, nil, queueOrigin, &index, nil, nil))
+		return tx
+	}
+	// newState returns a state with the gas price oracle at the layout
+	// version, the L1 fee params are only stored by version 1
+	newState := func(version uint64, subsidyRate uint64) *state.StateDB {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+		layout := rcfg.Layouts[version]
+		statedb.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceOracleVersionSlot, common.BigToHash(new(big.Int).SetUint64(version)))
+		statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.GasPrice, common.BigToHash(l2GasPrice))
+		if layout.L1Fee != nil {
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.L1GasPrice, common.BigToHash(l1GasPrice))
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.Overhead, common.BigToHash(new(big.Int).SetUint64(fees.Overhead)))
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.Scalar, common.BigToHash(big.NewInt(1000)))
+			statedb.SetState(rcfg.L2GasPriceOracleAddress, layout.L1Fee.Decimals, common.BigToHash(big.NewInt(3)))
+		}
+		statedb.SetState(rcfg.L2FeeSubsidyRegistryAddress, rcfg.FeeSubsidyRateSlot(to), common.BigToHash(new(big.Int).SetUint64(subsidyRate)))
+		statedb.SetState(rcfg.L2FeeSubsidyRegistryAddress, rcfg.FeeSubsidyBalanceSlot(to), common.BigToHash(big.NewInt(params.Ether)))
+		return statedb
+	}
+	subsidized := &params.ChainConfig{
+		RollupForks: []params.RollupFork{{L1Block: big.NewInt(0), Features: []params.RollupFeature{params.RollupFeatureFeeSubsidy}}},
+	}
+	halfL1GasPrice := new(big.Int).Div(l1GasPrice, big.NewInt(2))
+
+	tests := map[string]struct {
+		config *params.ChainConfig
+		state  *state.StateDB
+		tx     *types.Transaction
+		expect error
+	}{
+		"covers-l2-fee": {
+			state: newState(0, 0),
+			tx:    newTx(types.QueueOriginSequencer, l2GasPrice, new(big.Int), fees.BigTxGasPrice),
+		},
+		"does-not-cover-l2-fee": {
+			state:  newState(0, 0),
+			tx:     newTx(types.QueueOriginSequencer, big.NewInt(params.GWei/2), new(big.Int), fees.BigTxGasPrice),
+			expect: errFeeAssertion,
+		},
+		"matching-l1-fee": {
+			state: newState(1, 0),
+			tx:    newTx(types.QueueOriginSequencer, l2GasPrice, l1GasPrice, fees.BigTxGasPrice),
+		},
+		"low-l1-fee": {
+			state:  newState(1, 0),
+			tx:     newTx(types.QueueOriginSequencer, l2GasPrice, halfL1GasPrice, fees.BigTxGasPrice),
+			expect: errFeeAssertion,
+		},
+		"subsidized-l1-fee": {
+			config: subsidized,
+			state:  newState(1, 5000),
+			tx:     newTx(types.QueueOriginSequencer, l2GasPrice, halfL1GasPrice, fees.BigTxGasPrice),
+		},
+		"subsidy-not-active": {
+			state:  newState(1, 5000),
+			tx:     newTx(types.QueueOriginSequencer, l2GasPrice, halfL1GasPrice, fees.BigTxGasPrice),
+			expect: errFeeAssertion,
+		},
+		"zero-gas-price": {
+			state: newState(1, 0),
+			tx:    newTx(types.QueueOriginSequencer, l2GasPrice, new(big.Int), new(big.Int)),
+		},
+		"l1-to-l2": {
+			state: newState(1, 0),
+			tx:    newTx(types.QueueOriginL1ToL2, new(big.Int), new(big.Int), fees.BigTxGasPrice),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &params.ChainConfig{}
+			}
+			err := assertFee(config, big.NewInt(1), tt.tx, tt.state)
+			if !errors.Is(err, tt.expect) || (tt.expect == nil && err != nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package rollup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+)
+
+// feeCollectionL2Gas is the L2 gas limit of the transactions that execute
+// transfer authorizations, which covers a transferWithAuthorization of the
+// common EIP-3009 tokens
+const feeCollectionL2Gas = 100_000
+
+// FeeTokens are the EIP-3009 tokens that the sequencer accepts fees in, keyed
+// by the address of the token
+type FeeTokens map[common.Address]*feesig.FeeToken
+
+// LoadFeeTokens reads the fee tokens from a JSON file
+func LoadFeeTokens(path string) (FeeTokens, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read fee tokens: %w", err)
+	}
+	var tokens FeeTokens
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		return nil, fmt.Errorf("Cannot decode fee tokens: %w", err)
+	}
+	for address, token := range tokens {
+		if err := token.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: fee token %s: %v", errBadConfig, address.Hex(), err)
+		}
+	}
+	return tokens, nil
+}
+
+// verifyFeeAuthorization verifies that a transaction with a zero gas price
+// pays its fee with the transfer authorization. The authorization must move
+// enough of a fee token from the sender to the fee collector to pay for the
+// transaction and for the transaction that executes the authorization.
+func (s *SyncService) verifyFeeAuthorization(tx *types.Transaction, auth *feesig.TransferAuthorization, snapshot *feeSnapshot, decision *feeDecision) error {
+	if s.feeCollector == nil {
+		return fmt.Errorf("%w: fee authorizations are not enabled", feesig.ErrInvalidAuthorization)
+	}
+	token, ok := s.feeTokens[auth.Token]
+	if !ok {
+		return fmt.Errorf("%w: %s is not a fee token", feesig.ErrInvalidAuthorization, auth.Token.Hex())
+	}
+	if collector := crypto.PubkeyToAddress(s.feeCollector.PublicKey); auth.To != collector {
+		return fmt.Errorf("%w: transfer to %s, fees are collected by %s", feesig.ErrInvalidAuthorization,
+			auth.To.Hex(), collector.Hex())
+	}
+	signer, err := auth.Signer(token.DomainSeparator(s.bc.Config().ChainID, auth.Token))
+	if err != nil {
+		return err
+	}
+	from, err := types.Sender(s.signer, tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %w", core.ErrInvalidSender)
+	}
+	if signer != from || auth.From != from {
+		return fmt.Errorf("%w: authorized by %s, transaction sent by %s", feesig.ErrInvalidAuthorization,
+			signer.Hex(), from.Hex())
+	}
+	if err := auth.ValidAt(uint64(time.Now().Unix())); err != nil {
+		return err
+	}
+	if snapshot.err != nil {
+		return snapshot.err
+	}
+	l2GasLimit := fees.DecodeL2GasLimit(new(big.Int).SetUint64(tx.Gas()))
+	if l2GasLimit.Cmp(s.minL2GasLimit) == -1 {
+		return fmt.Errorf("%w: %d, use at least %d", fees.ErrL2GasLimitTooLow, l2GasLimit, s.minL2GasLimit)
+	}
+	price, err := s.priceTx(tx, snapshot, snapshot.l1FeeParams, l2GasLimit, snapshot.l2GasPrice)
+	if err != nil {
+		return err
+	}
+	collection := feeCollectionGasLimit(auth, snapshot)
+	expectedFee := new(big.Int).Add(price.expectedTxGasLimit, collection)
+	expectedFee.Mul(expectedFee, fees.BigTxGasPrice)
+	decision.decision = feeDecisionAuthorization
+	decision.l1GasPrice = snapshot.l1GasPrice
+	decision.l2GasPrice = snapshot.l2GasPrice
+	decision.l2GasLimit = l2GasLimit
+	decision.expectedFee = expectedFee
+	if amount := token.FromWei(expectedFee); auth.Value.ToInt().Cmp(amount) < 0 {
+		return fmt.Errorf("%w: %d %s, use at least %d", feesig.ErrAuthorizationTooLow, auth.Value.ToInt(),
+			token.Symbol, amount)
+	}
+	return nil
+}
+
+// feeCollectionGasLimit returns the gas limit of the transaction that
+// executes the transfer authorization
+func feeCollectionGasLimit(auth *feesig.TransferAuthorization, snapshot *feeSnapshot) *big.Int {
+	l1Fee := snapshot.l1FeeParams.daCost.CostOf(auth.Calldata())
+	return fees.EncodeTxGasLimitForL1Fee(l1Fee, big.NewInt(feeCollectionL2Gas), snapshot.l2GasPrice)
+}
+
+// collectFee executes the transfer authorization in a transaction of the fee
+// collector that is applied right before the transaction that it pays for,
+// with the same L1 context. The fee collector pays the fee of this
+// transaction in ETH. It must be called holding the tip of the chain, and
+// the transaction that is paid for must already be validated.
+func (s *SyncService) collectFee(ctx context.Context, auth *feesig.TransferAuthorization, paid *types.Transaction) error {
+	s.feeCollectorLock.Lock()
+	defer s.feeCollectorLock.Unlock()
+
+	collector := crypto.PubkeyToAddress(s.feeCollector.PublicKey)
+	statedb, err := s.bc.State()
+	if err != nil {
+		return fmt.Errorf("Cannot read fee collector nonce: %w", err)
+	}
+	gasLimit := feeCollectionGasLimit(auth, s.snapshotFees(ctx))
+	tx := types.NewTransaction(statedb.GetNonce(collector), auth.Token, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, auth.Calldata())
+	tx, err = types.SignTx(tx, s.signer, s.feeCollector)
+	if err != nil {
+		return err
+	}
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+	tx.SetTransactionMeta(types.NewTransactionMeta(paid.L1BlockNumber(), paid.L1Timestamp(), nil, types.QueueOriginSequencer, nil, nil, raw))
+	// The backlog was checked with the transaction that is paid for, so
+	// the fee collection is not throttled on its own
+	if err := s.txpool.ValidateTx(tx); err != nil {
+		return fmt.Errorf("Cannot collect fee: invalid transaction: %w", err)
+	}
+	if err := s.applyValidatedAtTip(ctx, tx, nil); err != nil {
+		return fmt.Errorf("Cannot collect fee: %w", err)
+	}
+	block := s.bc.CurrentBlock()
+	receipts := s.bc.GetReceiptsByHash(block.Hash())
+	if len(receipts) == 0 || receipts[0].TxHash != tx.Hash() || receipts[0].Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: transfer %s failed", feesig.ErrInvalidAuthorization, tx.Hash().Hex())
+	}
+	log.Debug("Collected fee", "hash", tx.Hash().Hex(), "token", auth.Token.Hex(), "from", auth.From.Hex(), "value", auth.Value.ToInt())
+	return nil
+}
@@ -0,0 +1,116 @@
+package rollup
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+)
+
+func TestFeeAuthorization(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	service.feeCollector, _ = crypto.GenerateKey()
+	tokenAddress := common.HexToAddress("0x7F5c764cBc14f9669B88837ca1490cCa17c31607")
+	token := &feesig.FeeToken{
+		GasToken: fees.GasToken{Symbol: "USDC", Decimals: 6, ConversionRate: big.NewFloat(2e-9)},
+		Name:     "USD Coin",
+		Version:  "2",
+	}
+	service.feeTokens = FeeTokens{tokenAddress: token}
+	service.RollupGpo.SetL1GasPrice(big.NewInt(params.GWei))
+	service.RollupGpo.SetL2GasPrice(big.NewInt(params.GWei))
+
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	data := []byte{0x01, 0x02}
+	gasLimit := fees.EncodeTxGasLimit(data, big.NewInt(params.GWei), big.NewInt(21000), big.NewInt(params.GWei))
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{}, new(big.Int), gasLimit.Uint64(), new(big.Int), data), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := token.DomainSeparator(big.NewInt(420), tokenAddress)
+	now := uint64(time.Now().Unix())
+	authorize := func(key *ecdsa.PrivateKey, modify func(*feesig.TransferAuthorization)) *feesig.TransferAuthorization {
+		auth := &feesig.TransferAuthorization{
+			Token:       tokenAddress,
+			From:        crypto.PubkeyToAddress(key.PublicKey),
+			To:          crypto.PubkeyToAddress(service.feeCollector.PublicKey),
+			Value:       (*hexutil.Big)(big.NewInt(1e6)),
+			ValidBefore: hexutil.Uint64(now + 60),
+			Nonce:       common.Hash{0x01},
+		}
+		if modify != nil {
+			modify(auth)
+		}
+		sig, err := crypto.Sign(auth.Hash(domain).Bytes(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth.Signature = sig
+		return auth
+	}
+
+	tests := map[string]struct {
+		auth *feesig.TransferAuthorization
+		err  error
+	}{
+		"no-authorization": {
+			auth: nil,
+			err:  errZeroGasPriceTx,
+		},
+		"authorization": {
+			auth: authorize(key, nil),
+		},
+		"other-signer": {
+			auth: authorize(otherKey, nil),
+			err:  feesig.ErrInvalidAuthorization,
+		},
+		"other-collector": {
+			auth: authorize(key, func(a *feesig.TransferAuthorization) { a.To = common.Address{0x01} }),
+			err:  feesig.ErrInvalidAuthorization,
+		},
+		"unknown-token": {
+			auth: authorize(key, func(a *feesig.TransferAuthorization) { a.Token = common.Address{0x01} }),
+			err:  feesig.ErrInvalidAuthorization,
+		},
+		"expired": {
+			auth: authorize(key, func(a *feesig.TransferAuthorization) { a.ValidBefore = hexutil.Uint64(now - 1) }),
+			err:  feesig.ErrInvalidAuthorization,
+		},
+		"too-low": {
+			auth: authorize(key, func(a *feesig.TransferAuthorization) { a.Value = (*hexutil.Big)(big.NewInt(1)) }),
+			err:  feesig.ErrAuthorizationTooLow,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.auth != nil {
+				ctx = feesig.WithTransferAuthorization(ctx, tt.auth)
+			}
+			err := service.verifyFee(ctx, tx)
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+}
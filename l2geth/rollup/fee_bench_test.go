@@ -0,0 +1,135 @@
+package rollup
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// loadBenchTransactions reads the transaction corpus that is shared with the
+// benchmarks of the fees package
+func loadBenchTransactions(b *testing.B) (map[string]*types.Transaction, []string, *big.Int, *big.Int) {
+	raw, err := ioutil.ReadFile(filepath.Join("fees", "testdata", "transactions.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var corpus struct {
+		L1GasPrice   *hexutil.Big `json:"l1GasPrice"`
+		L2GasPrice   *hexutil.Big `json:"l2GasPrice"`
+		Transactions []struct {
+			Name string        `json:"name"`
+			Raw  hexutil.Bytes `json:"raw"`
+		} `json:"transactions"`
+	}
+	if err := json.Unmarshal(raw, &corpus); err != nil {
+		b.Fatal(err)
+	}
+	txs := make(map[string]*types.Transaction)
+	var names []string
+	for _, entry := range corpus.Transactions {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(entry.Raw, tx); err != nil {
+			b.Fatalf("cannot decode %s: %v", entry.Name, err)
+		}
+		txs[entry.Name] = tx
+		names = append(names, entry.Name)
+	}
+	return txs, names, corpus.L1GasPrice.ToInt(), corpus.L2GasPrice.ToInt()
+}
+
+// BenchmarkVerifyFee measures the fee check that the sequencer runs for every
+// transaction, including reading the gas prices from the oracle and
+// recovering the sender for the fee decision log
+func BenchmarkVerifyFee(b *testing.B) {
+	txs, names, l1GasPrice, l2GasPrice := loadBenchTransactions(b)
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	service.enforceFees = true
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	for _, name := range names {
+		tx := txs[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if err := service.verifyFee(context.Background(), tx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEncodeRawTransaction(b *testing.B) {
+	txs, names, _, _ := loadBenchTransactions(b)
+	for _, name := range names {
+		tx := txs[name]
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(tx.Size()))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if _, err := rlp.EncodeToBytes(tx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeRawTransaction(b *testing.B) {
+	txs, names, _, _ := loadBenchTransactions(b)
+	for _, name := range names {
+		raw, err := rlp.EncodeToBytes(txs[name])
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(raw)))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if err := rlp.DecodeBytes(raw, new(types.Transaction)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkVerifyFees measures the fee check of the whole corpus as a set,
+// verified concurrently against a single snapshot of the oracle
+func BenchmarkVerifyFees(b *testing.B) {
+	txs, names, l1GasPrice, l2GasPrice := loadBenchTransactions(b)
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	service.enforceFees = true
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	var set []*types.Transaction
+	for len(set) < 1024 {
+		for _, name := range names {
+			set = append(set, txs[name])
+		}
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		_, errs := service.verifyFees(context.Background(), set)
+		for _, err := range errs {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// l1FeeCacheSize is the number of L1 fees that are memoized, enough for the
+// pending transactions of several blocks
+const l1FeeCacheSize = 4096
+
+var (
+	l1FeeCacheHitMeter  = metrics.NewRegisteredMeter("rollup/fee/l1feecache/hit", nil)
+	l1FeeCacheMissMeter = metrics.NewRegisteredMeter("rollup/fee/l1feecache/miss", nil)
+)
+
+// l1FeeKey identifies the L1 fee of a transaction at a set of gas price
+// oracle parameters
+type l1FeeKey struct {
+	txHash     common.Hash
+	paramsHash common.Hash
+}
+
+// l1FeeCache memoizes the L1 fees of transactions, so that a pending
+// transaction that is validated again in a later block at the same
+// parameters does not recompute its fee. The cached fees must not be
+// modified, a nil cache computes every fee.
+type l1FeeCache struct {
+	cache *lru.Cache
+}
+
+func newL1FeeCache(size int) *l1FeeCache {
+	cache, _ := lru.New(size)
+	return &l1FeeCache{cache: cache}
+}
+
+// l1FeeParams are the parameters that the L1 fee of a transaction depends
+// on, with a hash that commits to them
+type l1FeeParams struct {
+	l1GasPrice  *big.Int
+	calldataGas fees.CalldataGas
+	daCost      *fees.DACost
+	hash        common.Hash
+}
+
+// newL1FeeParams returns the parameters of the L1 fee at the L1 gas price
+// and the prices of the data availability layer, which are computed from it
+func newL1FeeParams(l1GasPrice *big.Int, calldataGas fees.CalldataGas, daCost *fees.DACost) *l1FeeParams {
+	return &l1FeeParams{
+		l1GasPrice:  l1GasPrice,
+		calldataGas: calldataGas,
+		daCost:      daCost,
+		hash:        daCostHash(daCost),
+	}
+}
+
+// daCostHash commits to the prices of the data availability layer, so that
+// costs that are computed at the same prices can be memoized
+func daCostHash(c *fees.DACost) common.Hash {
+	scale := common.Big1
+	if c.Scale != nil {
+		scale = c.Scale
+	}
+	return crypto.Keccak256Hash(
+		common.BigToHash(c.Zero).Bytes(),
+		common.BigToHash(c.NonZero).Bytes(),
+		common.BigToHash(scale).Bytes(),
+		common.BigToHash(c.Fixed).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(c.MinSize)).Bytes(),
+	)
+}
+
+// l1FeeParamsAt returns the parameters of the L1 fee at the L1 gas price,
+// with the data of transactions priced on the data availability layer that
+// the batch submitter posts them to
+func (s *SyncService) l1FeeParamsAt(ctx context.Context, l1GasPrice *big.Int, calldataGas fees.CalldataGas) (*l1FeeParams, error) {
+	daCost, err := s.daCost.DACost(ctx, l1GasPrice, calldataGas)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot price %s data availability: %w", s.daCost.Backend(), err)
+	}
+	return newL1FeeParams(l1GasPrice, calldataGas, daCost), nil
+}
+
+// l1Fee returns the L1 fee of the transaction at the parameters
+func (c *l1FeeCache) l1Fee(tx *types.Transaction, params *l1FeeParams) *big.Int {
+	if c == nil {
+		return params.daCost.Cost(tx.DataCounts())
+	}
+	key := l1FeeKey{txHash: tx.Hash(), paramsHash: params.hash}
+	if fee, ok := c.cache.Get(key); ok {
+		l1FeeCacheHitMeter.Mark(1)
+		return fee.(*big.Int)
+	}
+	l1FeeCacheMissMeter.Mark(1)
+	fee := params.daCost.Cost(tx.DataCounts())
+	c.cache.Add(key, fee)
+	return fee
+}
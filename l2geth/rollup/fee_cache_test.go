@@ -0,0 +1,69 @@
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestL1FeeCache(t *testing.T) {
+	cache := newL1FeeCache(2)
+	tx := types.NewTransaction(0, common.Address{}, new(big.Int), 0, new(big.Int), []byte{0, 1, 2})
+	other := types.NewTransaction(1, common.Address{}, new(big.Int), 0, new(big.Int), []byte{0, 1, 2})
+
+	repriced := fees.CalldataGas{Zero: 4, NonZero: 4}
+
+	tests := map[string]struct {
+		tx          *types.Transaction
+		l1GasPrice  *big.Int
+		calldataGas fees.CalldataGas
+		cached      bool
+	}{
+		"miss":              {tx, big.NewInt(10), fees.DefaultCalldataGas, false},
+		"hit":               {tx, big.NewInt(10), fees.DefaultCalldataGas, true},
+		"price-changed":     {tx, big.NewInt(20), fees.DefaultCalldataGas, false},
+		"calldata-repriced": {tx, big.NewInt(20), repriced, false},
+		"other-transaction": {other, big.NewInt(20), repriced, false},
+	}
+	for _, name := range []string{"miss", "hit", "price-changed", "calldata-repriced", "other-transaction"} {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			daCost, _ := fees.CalldataDACost{}.DACost(context.Background(), tt.l1GasPrice, tt.calldataGas)
+			params := newL1FeeParams(tt.l1GasPrice, tt.calldataGas, daCost)
+			_, cached := cache.cache.Get(l1FeeKey{txHash: tt.tx.Hash(), paramsHash: params.hash})
+			if cached != tt.cached {
+				t.Fatalf("mismatched cached: got %t, expect %t", cached, tt.cached)
+			}
+			fee := cache.l1Fee(tt.tx, params)
+			expect := new(big.Int).Mul(new(big.Int).SetUint64(tt.tx.L1GasUsedWith(tt.calldataGas)), tt.l1GasPrice)
+			if fee.Cmp(expect) != 0 {
+				t.Fatalf("mismatched l1 fee: got %d, expect %d", fee, expect)
+			}
+			var nilCache *l1FeeCache
+			if fee := nilCache.l1Fee(tt.tx, params); fee.Cmp(expect) != 0 {
+				t.Fatalf("mismatched uncached l1 fee: got %d, expect %d", fee, expect)
+			}
+		})
+	}
+	// The cache holds two entries, the first fee was evicted
+	daCost, _ := fees.CalldataDACost{}.DACost(context.Background(), big.NewInt(10), fees.DefaultCalldataGas)
+	if _, ok := cache.cache.Get(l1FeeKey{txHash: tx.Hash(), paramsHash: daCostHash(daCost)}); ok {
+		t.Fatal("expected least recently used fee to be evicted")
+	}
+}
+
+func TestDACostHash(t *testing.T) {
+	a, _ := fees.CalldataDACost{}.DACost(context.Background(), big.NewInt(1), fees.DefaultCalldataGas)
+	b, _ := fees.CalldataDACost{}.DACost(context.Background(), big.NewInt(1), fees.DefaultCalldataGas)
+	if daCostHash(a) != daCostHash(b) {
+		t.Fatal("mismatched hash of the same prices")
+	}
+	c, _ := fees.CalldataDACost{}.DACost(context.Background(), big.NewInt(2), fees.DefaultCalldataGas)
+	if daCostHash(a) == daCostHash(c) {
+		t.Fatal("same hash of different prices")
+	}
+}
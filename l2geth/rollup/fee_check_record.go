@@ -0,0 +1,75 @@
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// feeCheckRecorder appends the anonymized inputs of the fee checks to a file
+// as JSON lines for a period, so that the fee thresholds can be backtested
+// against real traffic with feeestimator thresholds
+type feeCheckRecorder struct {
+	lock  sync.Mutex
+	file  *os.File
+	enc   *json.Encoder
+	until time.Time
+	now   func() time.Time
+}
+
+func newFeeCheckRecorder(path string, period time.Duration) (*feeCheckRecorder, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open fee check record: %w", err)
+	}
+	return &feeCheckRecorder{
+		file:  file,
+		enc:   json.NewEncoder(file),
+		until: time.Now().Add(period),
+		now:   time.Now,
+	}, nil
+}
+
+// record appends the inputs of a fee decision that reached the fee check.
+// The file is closed once the period has passed.
+func (r *feeCheckRecorder) record(d *feeDecision) {
+	if d.userFee == nil || d.expectedFee == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	now := r.now()
+	if now.After(r.until) {
+		r.close()
+		log.Info("Stopped recording fee checks")
+		return
+	}
+	record := &fees.FeeCheckRecord{
+		Time:          uint64(now.Unix()),
+		UserFee:       (*hexutil.Big)(d.userFee),
+		ExpectedFee:   (*hexutil.Big)(d.expectedFee),
+		ThresholdUp:   d.policyThresholdUp,
+		ThresholdDown: d.policyThresholdDown,
+	}
+	if err := r.enc.Encode(record); err != nil {
+		log.Error("Cannot record fee check", "msg", err)
+	}
+}
+
+// close closes the file, it must be called holding the lock
+func (r *feeCheckRecorder) close() {
+	if err := r.file.Close(); err != nil {
+		log.Error("Cannot close fee check record", "msg", err)
+	}
+	r.file = nil
+}
@@ -0,0 +1,70 @@
+package rollup
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestFeeCheckRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fee-check-record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "records.jsonl")
+	recorder, err := newFeeCheckRecorder(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	recorder.now = func() time.Time { return now }
+	recorder.until = now.Add(time.Hour)
+
+	up := 1.0
+	tx := mockTx()
+	recorder.record(&feeDecision{tx: tx, userFee: big.NewInt(1000), expectedFee: big.NewInt(900)})
+	recorder.record(&feeDecision{tx: tx, userFee: big.NewInt(1000), expectedFee: big.NewInt(500), policyThresholdUp: &up})
+	// Decisions that did not reach the fee check are not recorded
+	recorder.record(&feeDecision{tx: tx, decision: feeDecisionNoFees})
+	// Nothing is recorded after the period
+	now = now.Add(2 * time.Hour)
+	recorder.record(&feeDecision{tx: tx, userFee: big.NewInt(1), expectedFee: big.NewInt(1)})
+	if recorder.file != nil {
+		t.Fatal("File not closed after the period")
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(raw), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("mismatched records: got %d, expect 2", len(lines))
+	}
+	var records []*fees.FeeCheckRecord
+	for _, line := range lines {
+		if bytes.Contains(line, []byte(tx.Hash().Hex()[2:])) {
+			t.Fatalf("Record not anonymized: %s", line)
+		}
+		var record fees.FeeCheckRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("Invalid record %q: %v", line, err)
+		}
+		records = append(records, &record)
+	}
+	if records[0].Time != 1000 || records[0].ExpectedFee.ToInt().Int64() != 900 || records[0].ThresholdUp != nil {
+		t.Fatalf("Unexpected record: %+v", records[0])
+	}
+	if records[1].ThresholdUp == nil || *records[1].ThresholdUp != up || records[1].ThresholdDown != nil {
+		t.Fatalf("Unexpected policy thresholds: %+v", records[1])
+	}
+}
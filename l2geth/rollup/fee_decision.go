@@ -0,0 +1,130 @@
+package rollup
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	feeDecisionAccept     = "accept"
+	feeDecisionOwner      = "accept-gpo-owner"
+	feeDecisionUnenforced = "accept-unenforced"
+	feeDecisionNoFees     = "accept-no-fees"
+	feeDecisionReject     = "reject"
+	// feeDecisionAuthorization accepts a transaction that pays its fee
+	// with a transfer authorization of a fee token
+	feeDecisionAuthorization = "accept-authorization"
+)
+
+// feeDecision collects the context of a fee check as it is performed so that
+// the outcome can be logged as a single line with structured fields
+type feeDecision struct {
+	tx          *types.Transaction
+	signer      types.Signer
+	decision    string
+	userFee     *big.Int
+	expectedFee *big.Int
+	l1GasPrice  *big.Int
+	l2GasPrice  *big.Int
+	l2GasLimit  *big.Int
+	l1Subsidy   *big.Int
+	l1Fee       *big.Int
+	policy      string
+	// The thresholds of the fee policy, set only when it overrides the
+	// configured thresholds
+	policyThresholdUp   *float64
+	policyThresholdDown *float64
+	// The fee hook that vetoed the fee, and whether the hooks adjusted it
+	hook         string
+	hookAdjusted bool
+}
+
+// fields returns the structured logging context of the fee decision
+func (d *feeDecision) fields(err error) []interface{} {
+	decision := d.decision
+	if err != nil {
+		decision = feeDecisionReject
+	}
+	ctx := []interface{}{
+		"txHash", d.tx.Hash().Hex(),
+		"decision", decision,
+	}
+	if sender, err := types.Sender(d.signer, d.tx); err == nil {
+		ctx = append(ctx, "sender", sender.Hex())
+	}
+	for _, field := range []struct {
+		key   string
+		value *big.Int
+	}{
+		{"userFee", d.userFee},
+		{"expectedFee", d.expectedFee},
+		{"l1GasPrice", d.l1GasPrice},
+		{"l2GasPrice", d.l2GasPrice},
+		{"l2GasLimit", d.l2GasLimit},
+		{"l1Subsidy", d.l1Subsidy},
+	} {
+		if field.value != nil {
+			ctx = append(ctx, field.key, field.value)
+		}
+	}
+	if d.policy != "" {
+		ctx = append(ctx, "policy", d.policy)
+	}
+	if d.hook != "" {
+		ctx = append(ctx, "feeHook", d.hook)
+	}
+	if d.hookAdjusted {
+		ctx = append(ctx, "feeHookAdjusted", true)
+	}
+	if err != nil {
+		ctx = append(ctx, "reason", err)
+	}
+	return ctx
+}
+
+// log writes the fee decision. Rejections are logged at info level so that
+// they can be found in log aggregation systems, accepted transactions only
+// at debug level.
+func (d *feeDecision) log(err error) {
+	if err != nil {
+		log.Info("Fee check", d.fields(err)...)
+		return
+	}
+	log.Debug("Fee check", d.fields(nil)...)
+}
+
+// feeAuditRecord is the message of the records of the fee audit log
+const feeAuditRecord = "fee-decision"
+
+// newFeeAuditLog creates a logger that appends a JSON record per line to
+// files in the directory, starting a new file when a file grows past the
+// limit in bytes. The audit log is separate from the node log so that every
+// fee decision is kept without enabling debug logging.
+func newFeeAuditLog(dir string, limit uint) (log.Logger, error) {
+	handler, err := log.RotatingFileHandler(dir, limit, log.JSONFormat())
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open fee audit log: %w", err)
+	}
+	logger := log.New()
+	logger.SetHandler(handler)
+	return logger, nil
+}
+
+// audit writes the fee decision to the audit log along with the fields of
+// the transaction that it was based on, so that the decision can be checked
+// again offline
+func (d *feeDecision) audit(logger log.Logger, err error) {
+	ctx := append(d.fields(err),
+		"nonce", d.tx.Nonce(),
+		"gasLimit", d.tx.Gas(),
+		"gasPrice", d.tx.GasPrice(),
+		"calldataSize", len(d.tx.Data()),
+	)
+	if to := d.tx.To(); to != nil {
+		ctx = append(ctx, "to", to.Hex())
+	}
+	logger.Info(feeAuditRecord, ctx...)
+}
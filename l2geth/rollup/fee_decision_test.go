@@ -0,0 +1,119 @@
+package rollup
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestFeeDecisionFields(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	tx, err := types.SignTx(mockTx(), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decision := feeDecision{
+		tx:          tx,
+		signer:      signer,
+		decision:    feeDecisionAccept,
+		userFee:     big.NewInt(1),
+		expectedFee: big.NewInt(2),
+	}
+
+	fields := decision.fields(fees.ErrFeeTooLow)
+	ctx := make(map[string]interface{})
+	for i := 0; i < len(fields); i += 2 {
+		ctx[fields[i].(string)] = fields[i+1]
+	}
+	if ctx["decision"] != feeDecisionReject {
+		t.Fatalf("Unexpected decision: %v", ctx["decision"])
+	}
+	if ctx["sender"] != crypto.PubkeyToAddress(key.PublicKey).Hex() {
+		t.Fatalf("Unexpected sender: %v", ctx["sender"])
+	}
+	if ctx["txHash"] != tx.Hash().Hex() {
+		t.Fatalf("Unexpected tx hash: %v", ctx["txHash"])
+	}
+	if ctx["reason"] != fees.ErrFeeTooLow {
+		t.Fatalf("Unexpected reason: %v", ctx["reason"])
+	}
+	if _, ok := ctx["l1GasPrice"]; ok {
+		t.Fatal("Unset field logged")
+	}
+
+	fields = decision.fields(nil)
+	if fields[3] != feeDecisionAccept {
+		t.Fatalf("Unexpected decision: %v", fields[3])
+	}
+}
+
+func TestFeeAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fee-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	tx, err := types.SignTx(mockTx(), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every record starts a new file with a limit of one byte
+	logger, err := newFeeAuditLog(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decision := feeDecision{
+		tx:          tx,
+		signer:      signer,
+		decision:    feeDecisionAccept,
+		userFee:     big.NewInt(1),
+		expectedFee: big.NewInt(2),
+	}
+	decision.audit(logger, nil)
+	time.Sleep(20 * time.Millisecond)
+	decision.audit(logger, fees.ErrFeeTooLow)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Unexpected number of files: %d", len(files))
+	}
+	var records []map[string]interface{}
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(raw, &record); err != nil {
+			t.Fatalf("Invalid record %q: %v", raw, err)
+		}
+		records = append(records, record)
+	}
+	if records[0]["decision"] != feeDecisionAccept || records[1]["decision"] != feeDecisionReject {
+		t.Fatalf("Unexpected decisions: %v, %v", records[0]["decision"], records[1]["decision"])
+	}
+	if records[0]["expectedFee"] != "2" {
+		t.Fatalf("Unexpected expected fee: %v", records[0]["expectedFee"])
+	}
+	if records[1]["reason"] != fees.ErrFeeTooLow.Error() {
+		t.Fatalf("Unexpected reason: %v", records[1]["reason"])
+	}
+	if records[1]["txHash"] != tx.Hash().Hex() || records[1]["gasLimit"] != float64(tx.Gas()) {
+		t.Fatalf("Unexpected transaction fields: %v", records[1])
+	}
+}
@@ -0,0 +1,275 @@
+package rollup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+const (
+	// feeEventQueueSize is the number of fee events that can wait to be
+	// published, events are dropped when the queue is full so that a slow
+	// sink does not hold up syncing
+	feeEventQueueSize = 4096
+	// feeEventBatchSize is the most fee events that are published at once
+	feeEventBatchSize = 256
+	// feeEventTimeout is the timeout for publishing a batch of fee events
+	feeEventTimeout = 10 * time.Second
+	// feeEventRetries is the number of times that a batch of fee events is
+	// published before it is dropped
+	feeEventRetries = 3
+	// feeEventRetryDelay is the delay before the first retry, it doubles
+	// with every retry
+	feeEventRetryDelay = time.Second
+	// kafkaRestContentType is the content type of the JSON records of the
+	// Kafka REST Proxy v2 API
+	kafkaRestContentType = "application/vnd.kafka.json.v2+json"
+)
+
+var (
+	feeEventPublishedMeter = metrics.NewRegisteredMeter("rollup/feeevents/published", nil)
+	feeEventDroppedMeter   = metrics.NewRegisteredMeter("rollup/feeevents/dropped", nil)
+	feeEventFailedMeter    = metrics.NewRegisteredMeter("rollup/feeevents/failed", nil)
+)
+
+// FeeEvent is the fee breakdown of a transaction, published once the
+// transaction is part of a batch on L1
+type FeeEvent struct {
+	Hash        common.Hash    `json:"hash"`
+	Index       hexutil.Uint64 `json:"index"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// BatchIndex is the index of the transaction batch on L1 that includes
+	// the transaction
+	BatchIndex    hexutil.Uint64  `json:"batchIndex"`
+	QueueOrigin   string          `json:"queueOrigin"`
+	QueueIndex    *hexutil.Uint64 `json:"queueIndex,omitempty"`
+	L1BlockNumber *hexutil.Big    `json:"l1BlockNumber"`
+	L1Timestamp   hexutil.Uint64  `json:"l1Timestamp"`
+	From          common.Address  `json:"from"`
+	To            *common.Address `json:"to"`
+	GasLimit      hexutil.Uint64  `json:"gasLimit"`
+	GasPrice      *hexutil.Big    `json:"gasPrice"`
+	// Fee is the fee that the sender paid, the gas limit times the gas price
+	Fee       *hexutil.Big   `json:"fee"`
+	L1GasUsed hexutil.Uint64 `json:"l1GasUsed"`
+	// L1Fee is the part of the fee that pays for L1 data, recovered from
+	// the gas limit at the L2 gas price
+	L1Fee      *hexutil.Big   `json:"l1Fee"`
+	L2GasLimit hexutil.Uint64 `json:"l2GasLimit"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Status     hexutil.Uint64 `json:"status"`
+}
+
+// newFeeEvent returns the fee event of a transaction that was included with
+// the receipt at the L2 gas price
+func newFeeEvent(tx *types.Transaction, from common.Address, receipt *types.Receipt, calldataGas fees.CalldataGas, l2GasPrice *big.Int, blockNumber, batchIndex uint64) *FeeEvent {
+	event := &FeeEvent{
+		Hash:          tx.Hash(),
+		BlockNumber:   hexutil.Uint64(blockNumber),
+		BatchIndex:    hexutil.Uint64(batchIndex),
+		QueueOrigin:   "sequencer",
+		L1BlockNumber: (*hexutil.Big)(tx.L1BlockNumber()),
+		L1Timestamp:   hexutil.Uint64(tx.L1Timestamp()),
+		From:          from,
+		To:            tx.To(),
+		GasLimit:      hexutil.Uint64(tx.Gas()),
+		GasPrice:      (*hexutil.Big)(tx.GasPrice()),
+		Fee:           (*hexutil.Big)(new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())),
+		L1GasUsed:     hexutil.Uint64(tx.L1GasUsedWith(calldataGas)),
+		L1Fee:         (*hexutil.Big)(new(big.Int)),
+		L2GasLimit:    hexutil.Uint64(fees.DecodeL2GasLimitU64(tx.Gas())),
+		L2GasPrice:    (*hexutil.Big)(l2GasPrice),
+	}
+	meta := tx.GetMeta()
+	if meta.Index != nil {
+		event.Index = hexutil.Uint64(*meta.Index)
+	}
+	if meta.QueueIndex != nil {
+		queueIndex := hexutil.Uint64(*meta.QueueIndex)
+		event.QueueIndex = &queueIndex
+	}
+	if tx.QueueOrigin() == types.QueueOriginL1ToL2 {
+		// Transactions from L1 pay for their gas on L1
+		event.QueueOrigin = "l1"
+		event.L1GasUsed = 0
+		event.L2GasLimit = hexutil.Uint64(tx.Gas())
+	} else if tx.GasPrice().Sign() != 0 && l2GasPrice != nil {
+		event.L1Fee = (*hexutil.Big)(fees.MaxChargedL1Fee(tx.Gas(), l2GasPrice))
+	}
+	if receipt != nil {
+		event.GasUsed = hexutil.Uint64(receipt.GasUsed)
+		event.Status = hexutil.Uint64(receipt.Status)
+	}
+	return event
+}
+
+// feeEventSink publishes fee events to a topic of a Kafka REST Proxy, so
+// that analytics and billing pipelines can consume them from Kafka without
+// a Kafka client in the node. Events are published in the background in
+// batches and keyed by transaction hash.
+type feeEventSink struct {
+	url    string
+	client *http.Client
+	queue  chan *FeeEvent
+}
+
+// newFeeEventSink creates a feeEventSink that publishes to the topic of the
+// Kafka REST Proxy at the URL
+func newFeeEventSink(proxy, topic string) *feeEventSink {
+	return &feeEventSink{
+		url:    strings.TrimSuffix(proxy, "/") + "/topics/" + topic,
+		client: &http.Client{Timeout: feeEventTimeout},
+		queue:  make(chan *FeeEvent, feeEventQueueSize),
+	}
+}
+
+// publish queues the event, it is dropped when the queue is full
+func (k *feeEventSink) publish(event *FeeEvent) {
+	select {
+	case k.queue <- event:
+	default:
+		feeEventDroppedMeter.Mark(1)
+	}
+}
+
+// Loop publishes the queued events until the context is done
+func (k *feeEventSink) Loop(ctx context.Context) {
+	for {
+		select {
+		case event := <-k.queue:
+			events := []*FeeEvent{event}
+		batch:
+			for len(events) < feeEventBatchSize {
+				select {
+				case event := <-k.queue:
+					events = append(events, event)
+				default:
+					break batch
+				}
+			}
+			k.send(ctx, events)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// send publishes a batch of events, retrying with a backoff before the
+// batch is dropped
+func (k *feeEventSink) send(ctx context.Context, events []*FeeEvent) {
+	delay := feeEventRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := k.post(ctx, events)
+		if err == nil {
+			feeEventPublishedMeter.Mark(int64(len(events)))
+			return
+		}
+		if attempt == feeEventRetries {
+			feeEventFailedMeter.Mark(int64(len(events)))
+			log.Error("Cannot publish fee events, dropping them", "count", len(events), "msg", err)
+			return
+		}
+		log.Warn("Cannot publish fee events, retrying", "count", len(events), "attempt", attempt, "msg", err)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type kafkaRecord struct {
+	Key   common.Hash `json:"key"`
+	Value *FeeEvent   `json:"value"`
+}
+
+// post publishes the events with the Kafka REST Proxy v2 API
+func (k *feeEventSink) post(ctx context.Context, events []*FeeEvent) error {
+	records := make([]kafkaRecord, len(events))
+	for i, event := range events {
+		records[i] = kafkaRecord{Key: event.Hash, Value: event}
+	}
+	body, err := json.Marshal(struct {
+		Records []kafkaRecord `json:"records"`
+	}{records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRestContentType)
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// publishFeeEvents publishes the fee events of the transactions of a batch
+// from the local chain, which holds their receipts
+func (s *SyncService) publishFeeEvents(batchIndex uint64, txs []*types.Transaction) {
+	if s.feeEvents == nil {
+		return
+	}
+	for _, batched := range txs {
+		index := batched.GetMeta().Index
+		if index == nil {
+			continue
+		}
+		// Handle the off by one
+		block := s.bc.GetBlockByNumber(*index + 1)
+		if block == nil || len(block.Transactions()) != 1 {
+			log.Warn("Cannot find transaction for fee event", "index", *index)
+			continue
+		}
+		tx := block.Transactions()[0]
+		from := tx.L1MessageSender()
+		if tx.QueueOrigin() == types.QueueOriginSequencer {
+			sender, err := types.Sender(s.signer, tx)
+			if err != nil {
+				log.Warn("Cannot recover sender for fee event", "index", *index, "msg", err)
+				continue
+			}
+			from = &sender
+		}
+		if from == nil {
+			from = new(common.Address)
+		}
+		var receipt *types.Receipt
+		if receipts := s.bc.GetReceiptsByHash(block.Hash()); len(receipts) == 1 {
+			receipt = receipts[0]
+		}
+		// The fee was checked against the L2 gas price of the parent state
+		var l2GasPrice *big.Int
+		if parent := s.bc.GetBlock(block.ParentHash(), block.NumberU64()-1); parent != nil {
+			if statedb, err := s.bc.StateAt(parent.Root()); err == nil {
+				if slots, err := s.readGPOStorageSlots(statedb); err == nil {
+					l2GasPrice = slots.GasPrice
+				}
+			}
+		}
+		calldataGas := core.BlockL1CalldataGas(s.bc.Config(), block.Number(), tx.L1BlockNumber())
+		s.feeEvents.publish(newFeeEvent(tx, *from, receipt, calldataGas, l2GasPrice, block.NumberU64(), batchIndex))
+	}
+}
@@ -0,0 +1,84 @@
+package rollup
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestNewFeeEvent(t *testing.T) {
+	l1GasPrice, l2GasPrice := big.NewInt(100_000_000_000), big.NewInt(1_000_000_000)
+	data := make([]byte, 100)
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, big.NewInt(100_000), l2GasPrice).Uint64()
+	to := common.HexToAddress("0x4200000000000000000000000000000000000006")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	tx := types.NewTransaction(0, to, new(big.Int), gasLimit, fees.BigTxGasPrice, data)
+	tx.SetIndex(41)
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 50_000}
+	event := newFeeEvent(tx, from, receipt, fees.DefaultCalldataGas, l2GasPrice, 42, 7)
+
+	if event.Index != 41 || event.BlockNumber != 42 || event.BatchIndex != 7 || event.QueueOrigin != "sequencer" {
+		t.Fatalf("mismatched linkage: %+v", event)
+	}
+	if uint64(event.L2GasLimit) != 100_000 || uint64(event.GasUsed) != 50_000 {
+		t.Fatalf("mismatched gas: L2 gas limit %d, gas used %d", event.L2GasLimit, event.GasUsed)
+	}
+	// The recovered L1 fee is an upper bound of the L1 fee within the
+	// rounding of the gas limit encoding
+	l1Fee := new(big.Int).Mul(fees.CalculateL1GasUsed(data), l1GasPrice)
+	if event.L1Fee.ToInt().Cmp(l1Fee) < 0 || event.L1Fee.ToInt().Cmp(event.Fee.ToInt()) > 0 {
+		t.Fatalf("mismatched L1 fee: got %d, L1 fee %d, fee %d", event.L1Fee.ToInt(), l1Fee, event.Fee.ToInt())
+	}
+
+	enqueued := types.NewTransaction(0, to, new(big.Int), 1_000_000, new(big.Int), data)
+	enqueued.SetTransactionMeta(types.NewTransactionMeta(big.NewInt(10), 100, &from, types.QueueOriginL1ToL2, nil, nil, nil))
+	event = newFeeEvent(enqueued, from, receipt, fees.DefaultCalldataGas, l2GasPrice, 42, 7)
+	if event.QueueOrigin != "l1" || event.L1GasUsed != 0 || event.L1Fee.ToInt().Sign() != 0 || event.L2GasLimit != 1_000_000 {
+		t.Fatalf("mismatched L1 to L2 event: %+v", event)
+	}
+}
+
+func TestFeeEventSink(t *testing.T) {
+	var calls int32
+	var published []kafkaRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt fails and is retried
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/topics/fees" || r.Header.Get("Content-Type") != kafkaRestContentType {
+			t.Errorf("mismatched request: %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var body struct {
+			Records []kafkaRecord `json:"records"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		published = body.Records
+	}))
+	defer server.Close()
+
+	sink := newFeeEventSink(server.URL+"/", "fees")
+	events := []*FeeEvent{
+		{Hash: common.HexToHash("0x01"), Index: 1},
+		{Hash: common.HexToHash("0x02"), Index: 2},
+	}
+	sink.send(context.Background(), events)
+	if calls != 2 {
+		t.Fatalf("mismatched attempts: got %d, expect 2", calls)
+	}
+	if len(published) != 2 || published[1].Key != events[1].Hash || published[1].Value.Index != 2 {
+		t.Fatalf("mismatched records: %+v", published)
+	}
+}
@@ -0,0 +1,214 @@
+package rollup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// feeHookSymbol is the symbol that a fee hook plugin exports its hook as
+const feeHookSymbol = "FeeHook"
+
+var (
+	feeHookVetoMeter   = metrics.NewRegisteredMeter("rollup/fee/hook/vetoes", nil)
+	feeHookAdjustMeter = metrics.NewRegisteredMeter("rollup/fee/hook/adjustments", nil)
+)
+
+// ErrFeeVetoed is the error for a transaction whose fee is rejected by a fee
+// hook
+var ErrFeeVetoed = errors.New("fee vetoed")
+
+// FeeCheck is the fee check of a transaction as it is passed to the fee
+// hooks, after the fee subsidies and fee policies are applied and before the
+// fee is compared with the expected fee
+type FeeCheck struct {
+	Tx     *types.Transaction
+	Sender common.Address
+	// The components that the expected fee is computed from
+	L1Fee      *big.Int
+	L1GasPrice *big.Int
+	L2GasPrice *big.Int
+	L2GasLimit *big.Int
+	UserFee    *big.Int
+	// The expected fee and the thresholds that the user fee is compared with,
+	// hooks can adjust them
+	ExpectedFee   *big.Int
+	ThresholdUp   *big.Float
+	ThresholdDown *big.Float
+}
+
+// FeeHook implements a chain specific fee policy. A hook can veto the fee of
+// a transaction by returning an error, or adjust the expected fee and the
+// thresholds of the check. Hooks run in the order that they are configured
+// in, each one sees the adjustments of the previous ones.
+type FeeHook interface {
+	CheckFee(check *FeeCheck) error
+}
+
+// FeeHookFunc is a function that implements FeeHook
+type FeeHookFunc func(check *FeeCheck) error
+
+// CheckFee calls the function
+func (f FeeHookFunc) CheckFee(check *FeeCheck) error { return f(check) }
+
+// NamedFeeHook is a fee hook with the name that its decisions are logged with
+type NamedFeeHook struct {
+	Name string
+	Hook FeeHook
+}
+
+var (
+	feeHooksLock sync.Mutex
+	feeHooks     []NamedFeeHook
+)
+
+// RegisterFeeHook registers a fee hook that is compiled into the node, so
+// that a chain can add its fee policy from the init function of its own
+// package instead of forking the fee package. Registered hooks run after the
+// hooks that are loaded from plugins.
+func RegisterFeeHook(name string, hook FeeHook) {
+	feeHooksLock.Lock()
+	defer feeHooksLock.Unlock()
+
+	for _, registered := range feeHooks {
+		if registered.Name == name {
+			panic(fmt.Sprintf("fee hook %s registered twice", name))
+		}
+	}
+	feeHooks = append(feeHooks, NamedFeeHook{Name: name, Hook: hook})
+}
+
+// RegisteredFeeHooks returns the registered fee hooks in the order that they
+// were registered in
+func RegisteredFeeHooks() []NamedFeeHook {
+	feeHooksLock.Lock()
+	defer feeHooksLock.Unlock()
+
+	return append([]NamedFeeHook(nil), feeHooks...)
+}
+
+// runFeeHooks runs the fee hooks on the fee check and returns the name of the
+// hook that vetoed the fee. A hook that panics vetoes the fee as well, so that
+// a faulty policy cannot stop the sequencer. Adjustments that the fee check
+// cannot be performed with are rejected.
+func runFeeHooks(hooks []NamedFeeHook, check *FeeCheck) (string, error) {
+	for _, hook := range hooks {
+		if err := runFeeHook(hook.Hook, check); err != nil {
+			return hook.Name, fmt.Errorf("%w by %s: %v", ErrFeeVetoed, hook.Name, err)
+		}
+		if err := check.validate(); err != nil {
+			return hook.Name, fmt.Errorf("%w: invalid adjustment by %s: %v", ErrFeeVetoed, hook.Name, err)
+		}
+	}
+	return "", nil
+}
+
+// runFeeHook runs a single fee hook, turning a panic into an error
+func runFeeHook(hook FeeHook, check *FeeCheck) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.CheckFee(check)
+}
+
+// validate checks the values that hooks can adjust with the same bounds as
+// the command line flags
+func (c *FeeCheck) validate() error {
+	if c.ExpectedFee == nil || c.ExpectedFee.Sign() < 0 {
+		return fmt.Errorf("expected fee %v", c.ExpectedFee)
+	}
+	if c.ThresholdUp != nil && c.ThresholdUp.Cmp(big.NewFloat(1)) <= 0 {
+		return fmt.Errorf("threshold up not larger than 1: %v", c.ThresholdUp)
+	}
+	if c.ThresholdDown != nil && (c.ThresholdDown.Sign() <= 0 || c.ThresholdDown.Cmp(big.NewFloat(1)) >= 0) {
+		return fmt.Errorf("threshold down not between 0 and 1: %v", c.ThresholdDown)
+	}
+	return nil
+}
+
+// loadFeeHookPlugin opens a Go plugin that exports a FeeHook as the
+// variable FeeHook. The hook is named after the file of the plugin. Plugins
+// must be built from the same version of l2geth with the same build tags as
+// the node, both with the generic tag as the assembly of the bn256 curve
+// cannot be linked dynamically.
+func loadFeeHookPlugin(path string) (NamedFeeHook, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	p, err := plugin.Open(path)
+	if err != nil {
+		return NamedFeeHook{}, fmt.Errorf("Cannot open fee hook plugin: %w", err)
+	}
+	symbol, err := p.Lookup(feeHookSymbol)
+	if err != nil {
+		return NamedFeeHook{}, fmt.Errorf("Cannot load fee hook plugin %s: %w", path, err)
+	}
+	// Lookup returns a pointer to an exported variable
+	switch hook := symbol.(type) {
+	case *FeeHook:
+		if *hook != nil {
+			return NamedFeeHook{Name: name, Hook: *hook}, nil
+		}
+	case FeeHook:
+		return NamedFeeHook{Name: name, Hook: hook}, nil
+	}
+	return NamedFeeHook{}, fmt.Errorf("%w: fee hook plugin %s: %s is not a rollup.FeeHook", errBadConfig, path, feeHookSymbol)
+}
+
+// checkFeeHooks runs the fee hooks on the fee check of the decision and
+// updates the options of PaysEnough with their adjustments. It returns true
+// when a hook adjusted the check, the hook that vetoes the fee is recorded in
+// the decision.
+func (s *SyncService) checkFeeHooks(decision *feeDecision, opts *fees.PaysEnoughOpts) (bool, error) {
+	// Hooks get copies of the values that they can adjust
+	check := &FeeCheck{
+		Tx:            decision.tx,
+		L1Fee:         decision.l1Fee,
+		L1GasPrice:    decision.l1GasPrice,
+		L2GasPrice:    decision.l2GasPrice,
+		L2GasLimit:    decision.l2GasLimit,
+		UserFee:       opts.UserFee,
+		ExpectedFee:   new(big.Int).Set(opts.ExpectedFee),
+		ThresholdUp:   copyFloat(opts.ThresholdUp),
+		ThresholdDown: copyFloat(opts.ThresholdDown),
+	}
+	if sender, err := types.Sender(decision.signer, decision.tx); err == nil {
+		check.Sender = sender
+	}
+	hook, err := runFeeHooks(s.feeHooks, check)
+	if err != nil {
+		decision.hook = hook
+		return false, err
+	}
+	if check.ExpectedFee.Cmp(opts.ExpectedFee) == 0 && equalFloat(check.ThresholdUp, opts.ThresholdUp) &&
+		equalFloat(check.ThresholdDown, opts.ThresholdDown) {
+		return false, nil
+	}
+	opts.ExpectedFee, opts.ThresholdUp, opts.ThresholdDown = check.ExpectedFee, check.ThresholdUp, check.ThresholdDown
+	return true, nil
+}
+
+// copyFloat returns a copy of a threshold that may be nil
+func copyFloat(f *big.Float) *big.Float {
+	if f == nil {
+		return nil
+	}
+	return new(big.Float).Copy(f)
+}
+
+// equalFloat compares two thresholds that may be nil
+func equalFloat(a, b *big.Float) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
@@ -0,0 +1,78 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestFeeHooks(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	full := fees.EncodeTxGasLimit(nil, l1GasPrice, big.NewInt(100_000), l2GasPrice)
+	half := new(big.Int).Add(new(big.Int).Div(full, big.NewInt(2)), big.NewInt(1))
+
+	halve := FeeHookFunc(func(check *FeeCheck) error {
+		check.ExpectedFee.Div(check.ExpectedFee, big.NewInt(2))
+		return nil
+	})
+	veto := FeeHookFunc(func(check *FeeCheck) error {
+		if check.UserFee.Cmp(new(big.Int).Mul(full, fees.BigTxGasPrice)) < 0 {
+			return errors.New("underpriced")
+		}
+		return nil
+	})
+	panics := FeeHookFunc(func(check *FeeCheck) error {
+		panic("faulty policy")
+	})
+	invalid := FeeHookFunc(func(check *FeeCheck) error {
+		check.ThresholdDown = big.NewFloat(2)
+		return nil
+	})
+
+	tests := map[string]struct {
+		hooks    []NamedFeeHook
+		gasLimit *big.Int
+		err      error
+	}{
+		"none":          {nil, half, fees.ErrFeeTooLow},
+		"adjust":        {[]NamedFeeHook{{Name: "halve", Hook: halve}}, half, nil},
+		"veto":          {[]NamedFeeHook{{Name: "veto", Hook: veto}}, half, ErrFeeVetoed},
+		"veto-adjusted": {[]NamedFeeHook{{Name: "halve", Hook: halve}, {Name: "veto", Hook: veto}}, half, ErrFeeVetoed},
+		"pass":          {[]NamedFeeHook{{Name: "veto", Hook: veto}}, full, nil},
+		"panic":         {[]NamedFeeHook{{Name: "panics", Hook: panics}}, full, ErrFeeVetoed},
+		"invalid":       {[]NamedFeeHook{{Name: "invalid", Hook: invalid}}, full, ErrFeeVetoed},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service.feeHooks = tt.hooks
+			tx, err := types.SignTx(types.NewTransaction(0, testPolicyToken, new(big.Int), tt.gasLimit.Uint64(), fees.BigTxGasPrice, nil), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = service.verifyFee(context.Background(), tx)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			// The transaction pool explains the same decision
+			if result := service.PoolTxFee(tx); result.PaysEnough != (tt.err == nil) {
+				t.Fatalf("mismatched pool decision: got %t, expect %t", result.PaysEnough, tt.err == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// feeTestOracleCode stands in for the OVM_GasPriceOracle. It stores the first
+// word of the calldata as the L2 gas price:
+// PUSH1 0 CALLDATALOAD PUSH1 1 SSTORE STOP
+var feeTestOracleCode = common.FromHex("0x60003560015500")
+
+// feeBoundary is a block at which the fee parameters change. The fee is
+// checked with the parameters of the parent of each block, so the
+// transactions of the boundary block are still checked with the parameters
+// from before the boundary.
+type feeBoundary struct {
+	name       string
+	block      uint64
+	l2GasPrice *big.Int
+}
+
+// TestFeeBoundaries builds an in-process chain whose fee parameters change at
+// known blocks and checks that the receipts, the estimates and the fee
+// checks of the sequencer are consistent on both sides of every boundary.
+func TestFeeBoundaries(t *testing.T) {
+	var (
+		key, _      = crypto.GenerateKey()
+		from        = crypto.PubkeyToAddress(key.PublicKey)
+		ownerKey, _ = crypto.GenerateKey()
+		owner       = crypto.PubkeyToAddress(ownerKey.PublicKey)
+		chainID     = big.NewInt(420)
+		signer      = types.NewEIP155Signer(chainID)
+		l1GasPrice  = big.NewInt(params.GWei)
+		l2GasPrice  = big.NewInt(params.GWei)
+		boundaries  = []feeBoundary{
+			{"l2-gas-price-increase", 3, big.NewInt(10 * params.GWei)},
+			{"l2-gas-price-decrease", 6, big.NewInt(2 * params.GWei)},
+		}
+		last = uint64(8)
+	)
+	chainCfg := *params.AllEthashProtocolChanges
+	chainCfg.ChainID = chainID
+	genesis := &core.Genesis{
+		Config:   &chainCfg,
+		GasLimit: 10_000_000_000,
+		Alloc: core.GenesisAlloc{
+			from:  {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))},
+			owner: {Balance: big.NewInt(params.Ether)},
+			rcfg.L2GasPriceOracleAddress: {
+				Code:    feeTestOracleCode,
+				Balance: new(big.Int),
+				Storage: map[common.Hash]common.Hash{
+					rcfg.L2GasPriceOracleOwnerSlot: owner.Hash(),
+					rcfg.L2GasPriceSlot:            common.BigToHash(l2GasPrice),
+				},
+			},
+		},
+	}
+	engine := ethash.NewFaker()
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, &chainCfg, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	txPool := core.NewTxPool(core.TxPoolConfig{PriceLimit: 0}, &chainCfg, chain)
+	defer txPool.Stop()
+	service, err := NewSyncService(context.Background(), Config{
+		CanonicalTransactionChainDeployHeight: big.NewInt(0),
+		Backend:                               BackendL2,
+		FeeThresholdUp:                        new(big.Float).SetFloat64(3),
+	}, txPool, chain, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.RollupGpo = gasprice.NewRollupOracle()
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.enforceFees = true
+
+	data := []byte{0x00, 0x01, 0x02, 0x03}
+	l2GasUsed := params.TxGas + params.TxDataZeroGas + 3*params.TxDataNonZeroGasEIP2028
+	estimate := func(l2GasPrice *big.Int) uint64 {
+		return fees.EncodeTxGasLimit(data, l1GasPrice, new(big.Int).SetUint64(l2GasUsed), l2GasPrice).Uint64()
+	}
+	newTx := func(nonce, gasLimit uint64) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{}, new(big.Int), gasLimit, fees.BigTxGasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	// priceAt returns the L2 gas price that the transactions of a block are
+	// checked with
+	priceAt := func(number uint64) *big.Int {
+		price := l2GasPrice
+		for _, boundary := range boundaries {
+			if boundary.block < number {
+				price = boundary.l2GasPrice
+			}
+		}
+		return price
+	}
+
+	var nonce, ownerNonce uint64
+	for number := uint64(1); number <= last; number++ {
+		// Refresh the view of the sequencer from the state of the parent
+		statedb, err := chain.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := service.updateL2GasPrice(statedb); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.cacheGasPriceOracleOwner(statedb); err != nil {
+			t.Fatal(err)
+		}
+		price, _ := service.RollupGpo.SuggestL2GasPrice(context.Background())
+		if expect := priceAt(number); price.Cmp(expect) != 0 {
+			t.Fatalf("block %d: mismatched L2 gas price: got %d, expect %d", number, price, expect)
+		}
+
+		tx := newTx(nonce, estimate(price))
+		if err := service.verifyFee(context.Background(), tx); err != nil {
+			t.Fatalf("block %d: estimated transaction rejected: %v", number, err)
+		}
+		txs := []*types.Transaction{tx}
+		for _, boundary := range boundaries {
+			if boundary.block == number {
+				// The owner sets the new parameters without paying a fee
+				update := types.NewTransaction(ownerNonce, rcfg.L2GasPriceOracleAddress, new(big.Int), 100_000, new(big.Int), common.BigToHash(boundary.l2GasPrice).Bytes())
+				update, err = types.SignTx(update, signer, ownerKey)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := service.verifyFee(context.Background(), update); err != nil {
+					t.Fatalf("%s: owner transaction rejected: %v", boundary.name, err)
+				}
+				txs = append(txs, update)
+				ownerNonce++
+			}
+			// The first block after a boundary rejects the estimates made
+			// with the parameters from before the boundary
+			if boundary.block+1 == number {
+				before := priceAt(boundary.block)
+				stale := newTx(nonce, estimate(before))
+				err := service.verifyFee(context.Background(), stale)
+				expect := fees.ErrFeeTooLow
+				if boundary.l2GasPrice.Cmp(before) < 0 {
+					expect = fees.ErrFeeTooHigh
+				}
+				if !errors.Is(err, expect) {
+					t.Fatalf("%s: mismatched stale estimate error: got %v, expect %v", boundary.name, err, expect)
+				}
+			}
+		}
+
+		blocks, receipts := core.GenerateChain(&chainCfg, chain.CurrentBlock(), engine, db, 1, func(i int, b *core.BlockGen) {
+			for _, tx := range txs {
+				b.AddTx(tx)
+			}
+		})
+		if _, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("block %d: %v", number, err)
+		}
+		// The sync service is not started, so consume the new head the same
+		// way as when applying a transaction to the tip
+		<-service.chainHeadCh
+		nonce++
+
+		for i, receipt := range receipts[0] {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				t.Fatalf("block %d: transaction %d failed", number, i)
+			}
+		}
+		if receipt := receipts[0][0]; receipt.GasUsed != l2GasUsed {
+			t.Fatalf("block %d: mismatched gas used: got %d, expect %d", number, receipt.GasUsed, l2GasUsed)
+		}
+		// The transactions of the owner do not pay a fee and are not part
+		// of the recorded fees
+		blockFees := rawdb.ReadBlockFees(db, number)
+		if blockFees == nil || blockFees.Transactions != 1 {
+			t.Fatalf("block %d: mismatched recorded fees: %+v", number, blockFees)
+		}
+	}
+}
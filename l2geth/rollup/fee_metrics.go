@@ -0,0 +1,44 @@
+package rollup
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// The L1 fee histograms describe the transactions accepted by the sequencer
+// so that the cost of posting them to L1 can be planned for and so that
+// transactions that are unusually heavy on calldata stand out. The fee is
+// recorded in gwei to fit in the int64 samples.
+var (
+	l1FeeHistogram     = metrics.NewRegisteredHistogram("rollup/tx/l1fee", nil, metrics.NewExpDecaySample(1028, 0.015))
+	l1GasUsedHistogram = metrics.NewRegisteredHistogram("rollup/tx/l1gasused", nil, metrics.NewExpDecaySample(1028, 0.015))
+	txSizeHistogram    = metrics.NewRegisteredHistogram("rollup/tx/size", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// recordL1Fee updates the L1 fee histograms for a transaction that is being
+// added to the chain by the sequencer. Transactions that originate on L1 do
+// not pay the L1 fee and are not recorded.
+func (s *SyncService) recordL1Fee(tx *types.Transaction) {
+	if tx.QueueOrigin() != types.QueueOriginSequencer {
+		return
+	}
+	l1GasUsed := new(big.Int).SetUint64(tx.L1GasUsedWith(core.L1CalldataGas(s.bc.Config(), tx.L1BlockNumber())))
+	l1GasUsedHistogram.Update(l1GasUsed.Int64())
+	txSizeHistogram.Update(int64(tx.Size()))
+	if s.RollupGpo == nil {
+		return
+	}
+	l1GasPrice, err := s.RollupGpo.SuggestL1GasPrice(context.Background())
+	if err != nil {
+		log.Warn("Cannot get L1 gas price for fee metrics", "msg", err)
+		return
+	}
+	l1Fee := new(big.Int).Mul(l1GasUsed, l1GasPrice)
+	l1FeeHistogram.Update(l1Fee.Div(l1Fee, big.NewInt(params.GWei)).Int64())
+}
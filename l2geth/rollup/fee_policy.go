@@ -0,0 +1,279 @@
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// feePolicyReloadInterval is how often the fee policy file is checked for
+// changes
+const feePolicyReloadInterval = 10 * time.Second
+
+var (
+	feePolicyMatchMeter  = metrics.NewRegisteredMeter("rollup/fee/policy/match", nil)
+	feePolicyReloadMeter = metrics.NewRegisteredMeter("rollup/fee/policy/reload", nil)
+)
+
+// Selector is the 4-byte selector of a contract method
+type Selector [4]byte
+
+// UnmarshalText parses a selector in hex syntax
+func (s *Selector) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("Selector", input, s[:])
+}
+
+// MarshalText returns the hex representation of the selector
+func (s Selector) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(s[:]).MarshalText()
+}
+
+// FeePolicy overrides how the fee of a transaction is verified. Values that
+// are not set are left to the command line flags.
+type FeePolicy struct {
+	// ThresholdDown replaces the fee threshold down
+	ThresholdDown *float64 `json:"thresholdDown,omitempty"`
+	// ThresholdUp replaces the fee threshold up
+	ThresholdUp *float64 `json:"thresholdUp,omitempty"`
+	// Subsidy is the fraction of the expected fee that is waived, 1 waives
+	// the whole fee
+	Subsidy *float64 `json:"subsidy,omitempty"`
+}
+
+// ContractFeePolicy is the fee policy of a contract, with overrides for some
+// of its methods
+type ContractFeePolicy struct {
+	FeePolicy
+	Selectors map[Selector]*FeePolicy `json:"selectors,omitempty"`
+}
+
+// FeePolicies are the fee policies of the sequencer. The policy of a
+// transaction is the most specific one that matches it: the policy of the
+// method of the target contract, then the policy of the target contract,
+// then the policy of the method selector on any contract.
+type FeePolicies struct {
+	Contracts map[common.Address]*ContractFeePolicy `json:"contracts,omitempty"`
+	Selectors map[Selector]*FeePolicy               `json:"selectors,omitempty"`
+}
+
+// LoadFeePolicies reads the fee policies from a JSON file
+func LoadFeePolicies(path string) (*FeePolicies, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read fee policies: %w", err)
+	}
+	var policies FeePolicies
+	if err := json.Unmarshal(raw, &policies); err != nil {
+		return nil, fmt.Errorf("Cannot decode fee policies: %w", err)
+	}
+	if err := policies.validate(); err != nil {
+		return nil, err
+	}
+	return &policies, nil
+}
+
+// validate checks the values of the policies with the same bounds as the
+// command line flags
+func (p *FeePolicies) validate() error {
+	check := func(name string, policy *FeePolicy) error {
+		if policy == nil {
+			return nil
+		}
+		if policy.ThresholdDown != nil && (*policy.ThresholdDown <= 0 || *policy.ThresholdDown >= 1) {
+			return fmt.Errorf("%w: fee policy %s: threshold down not between 0 and 1: %f", errBadConfig, name, *policy.ThresholdDown)
+		}
+		if policy.ThresholdUp != nil && *policy.ThresholdUp <= 1 {
+			return fmt.Errorf("%w: fee policy %s: threshold up not larger than 1: %f", errBadConfig, name, *policy.ThresholdUp)
+		}
+		if policy.Subsidy != nil && (*policy.Subsidy < 0 || *policy.Subsidy > 1) {
+			return fmt.Errorf("%w: fee policy %s: subsidy not between 0 and 1: %f", errBadConfig, name, *policy.Subsidy)
+		}
+		return nil
+	}
+	for addr, contract := range p.Contracts {
+		if contract == nil {
+			continue
+		}
+		if err := check(addr.Hex(), &contract.FeePolicy); err != nil {
+			return err
+		}
+		for selector, policy := range contract.Selectors {
+			if err := check(fmt.Sprintf("%s:%x", addr.Hex(), selector), policy); err != nil {
+				return err
+			}
+		}
+	}
+	for selector, policy := range p.Selectors {
+		if err := check(fmt.Sprintf("%x", selector), policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the policy of the transaction and its name, nil when no
+// policy matches
+func (p *FeePolicies) lookup(tx *types.Transaction) (*FeePolicy, string) {
+	if p == nil {
+		return nil, ""
+	}
+	var selector *Selector
+	if data := tx.Data(); len(data) >= 4 {
+		selector = new(Selector)
+		copy(selector[:], data)
+	}
+	if to := tx.To(); to != nil {
+		if contract := p.Contracts[*to]; contract != nil {
+			if selector != nil {
+				if policy := contract.Selectors[*selector]; policy != nil {
+					return policy, fmt.Sprintf("%s:%x", to.Hex(), *selector)
+				}
+			}
+			return &contract.FeePolicy, to.Hex()
+		}
+	}
+	if selector != nil {
+		if policy := p.Selectors[*selector]; policy != nil {
+			return policy, fmt.Sprintf("%x", *selector)
+		}
+	}
+	return nil, ""
+}
+
+// thresholdDown returns the fee threshold down of the policy, or the
+// default when it does not set one
+func (p *FeePolicy) thresholdDown(def *big.Float) *big.Float {
+	if p == nil || p.ThresholdDown == nil {
+		return def
+	}
+	return big.NewFloat(*p.ThresholdDown)
+}
+
+// thresholdUp returns the fee threshold up of the policy, or the default
+// when it does not set one
+func (p *FeePolicy) thresholdUp(def *big.Float) *big.Float {
+	if p == nil || p.ThresholdUp == nil {
+		return def
+	}
+	return big.NewFloat(*p.ThresholdUp)
+}
+
+// subsidize returns the fee that is charged after the subsidy of the policy,
+// rounded up
+func (p *FeePolicy) subsidize(fee *big.Int) *big.Int {
+	if p == nil || p.Subsidy == nil || *p.Subsidy == 0 {
+		return fee
+	}
+	charged := new(big.Float).Mul(new(big.Float).SetInt(fee), big.NewFloat(1-*p.Subsidy))
+	result, accuracy := charged.Int(nil)
+	if accuracy == big.Below {
+		result.Add(result, common.Big1)
+	}
+	return result
+}
+
+// subsidizeGasLimit returns the transaction gas limit that pays the fee that
+// the gas limit encodes after the subsidy of the policy. Only the fee part
+// is subsidized, the gas limit keeps encoding the same L2 gas limit.
+func (p *FeePolicy) subsidizeGasLimit(gasLimit *big.Int) *big.Int {
+	if p == nil || p.Subsidy == nil || *p.Subsidy == 0 {
+		return gasLimit
+	}
+	l2GasLimit := new(big.Int).Mod(gasLimit, fees.BigTenThousand)
+	fee := p.subsidize(new(big.Int).Sub(gasLimit, l2GasLimit))
+	fee = fees.Ceilmod(fee, fees.BigTenThousand)
+	return fee.Add(fee, l2GasLimit)
+}
+
+// feePolicyFile holds the fee policies of a file and reloads them when the
+// file changes, so that policies can be updated without a restart. A file
+// that cannot be loaded keeps the previous policies in effect.
+type feePolicyFile struct {
+	path string
+
+	lock     sync.RWMutex
+	policies *FeePolicies
+	modTime  time.Time
+}
+
+func newFeePolicyFile(path string) (*feePolicyFile, error) {
+	f := &feePolicyFile{path: path}
+	if _, err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// reload loads the policies again when the file was modified, it returns
+// true when they were reloaded
+func (f *feePolicyFile) reload() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("Cannot read fee policies: %w", err)
+	}
+	f.lock.RLock()
+	unchanged := f.policies != nil && info.ModTime().Equal(f.modTime)
+	f.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	policies, err := LoadFeePolicies(f.path)
+	if err != nil {
+		return false, err
+	}
+	f.lock.Lock()
+	f.policies, f.modTime = policies, info.ModTime()
+	f.lock.Unlock()
+	feePolicyReloadMeter.Mark(1)
+	log.Info("Loaded fee policies", "path", f.path, "contracts", len(policies.Contracts), "selectors", len(policies.Selectors))
+	return true, nil
+}
+
+// lookup returns the policy of the transaction and its name, nil when there
+// is no policy file or no policy matches
+func (f *feePolicyFile) lookup(tx *types.Transaction) (*FeePolicy, string) {
+	if f == nil {
+		return nil, ""
+	}
+	f.lock.RLock()
+	policies := f.policies
+	f.lock.RUnlock()
+	policy, name := policies.lookup(tx)
+	if policy != nil {
+		feePolicyMatchMeter.Mark(1)
+	}
+	return policy, name
+}
+
+// FeePolicyLoop reloads the fee policies and the shadow fee policies when
+// their files change
+func (s *SyncService) FeePolicyLoop() {
+	t := time.NewTicker(feePolicyReloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			for _, f := range []*feePolicyFile{s.feePolicies, s.shadowPolicies} {
+				if f == nil {
+					continue
+				}
+				if _, err := f.reload(); err != nil {
+					log.Error("Cannot reload fee policies, keeping the previous policies", "path", f.path, "msg", err)
+				}
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rollup
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+const (
+	feeOutcomeTooLow  = "reject-too-low"
+	feeOutcomeTooHigh = "reject-too-high"
+)
+
+var (
+	feePolicyShadowCheckMeter  = metrics.NewRegisteredMeter("rollup/fee/policy/shadow/checks", nil)
+	feePolicyShadowRejectMeter = metrics.NewRegisteredMeter("rollup/fee/policy/shadow/rejects", nil)
+	feePolicyShadowAcceptMeter = metrics.NewRegisteredMeter("rollup/fee/policy/shadow/accepts", nil)
+)
+
+// feeCheckOutcome returns the outcome of the fee check of PaysEnough
+func feeCheckOutcome(err error) string {
+	switch {
+	case err == nil:
+		return feeDecisionAccept
+	case errors.Is(err, fees.ErrFeeTooLow):
+		return feeOutcomeTooLow
+	case errors.Is(err, fees.ErrFeeTooHigh):
+		return feeOutcomeTooHigh
+	}
+	return feeDecisionReject
+}
+
+// shadowFeeCheck is the fee check of a transaction under the candidate fee
+// policies
+type shadowFeeCheck struct {
+	outcome     string
+	policy      string
+	expectedFee *big.Int
+}
+
+// checkShadowFee checks the fee of a transaction under the candidate fee
+// policies. The gas limit is the expected transaction gas limit before any
+// fee policy is applied, the thresholds are those of the command line flags.
+func checkShadowFee(policies *FeePolicies, tx *types.Transaction, gasLimit, userFee *big.Int, thresholdUp, thresholdDown *big.Float) *shadowFeeCheck {
+	policy, name := policies.lookup(tx)
+	expectedFee := new(big.Int).Mul(policy.subsidize(gasLimit), fees.BigTxGasPrice)
+	err := fees.PaysEnough(&fees.PaysEnoughOpts{
+		UserFee:       userFee,
+		ExpectedFee:   expectedFee,
+		ThresholdUp:   policy.thresholdUp(thresholdUp),
+		ThresholdDown: policy.thresholdDown(thresholdDown),
+	})
+	return &shadowFeeCheck{outcome: feeCheckOutcome(err), policy: name, expectedFee: expectedFee}
+}
+
+// shadowFeePolicy checks the fee of a transaction under the candidate fee
+// policies as well and logs when the outcome differs from the outcome under
+// the active fee policies, so that a change of the policies can be validated
+// against live traffic before it is enforced. It never changes the decision.
+func (s *SyncService) shadowFeePolicy(tx *types.Transaction, decision *feeDecision, gasLimit *big.Int, thresholdDown *big.Float, err error) {
+	s.shadowPolicies.lock.RLock()
+	policies := s.shadowPolicies.policies
+	s.shadowPolicies.lock.RUnlock()
+
+	shadow := checkShadowFee(policies, tx, gasLimit, decision.userFee, s.feeThresholdUp, thresholdDown)
+	feePolicyShadowCheckMeter.Mark(1)
+	active := feeCheckOutcome(err)
+	if shadow.outcome == active {
+		return
+	}
+	if active == feeDecisionAccept {
+		feePolicyShadowRejectMeter.Mark(1)
+	} else if shadow.outcome == feeDecisionAccept {
+		feePolicyShadowAcceptMeter.Mark(1)
+	}
+	log.Info("Shadow fee policy differs", "txHash", tx.Hash().Hex(), "active", active, "shadow", shadow.outcome,
+		"activePolicy", decision.policy, "shadowPolicy", shadow.policy, "userFee", decision.userFee,
+		"expectedFee", decision.expectedFee, "shadowExpectedFee", shadow.expectedFee)
+}
@@ -0,0 +1,280 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+var (
+	testPolicyBridge = common.HexToAddress("0x4200000000000000000000000000000000000010")
+	testPolicyToken  = common.HexToAddress("0x4200000000000000000000000000000000000006")
+)
+
+const testFeePolicies = `{
+	"contracts": {
+		"0x4200000000000000000000000000000000000010": {
+			"thresholdDown": 0.5,
+			"selectors": {
+				"0x1532ec34": {"subsidy": 1}
+			}
+		}
+	},
+	"selectors": {
+		"0xa9059cbb": {"subsidy": 0.5}
+	}
+}`
+
+func writeFeePolicies(t *testing.T, path, policies string, modTime time.Time) {
+	if err := ioutil.WriteFile(path, []byte(policies), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFeePolicyLookup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "feepolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policies.json")
+	writeFeePolicies(t, path, testFeePolicies, time.Unix(1000, 0))
+	file, err := newFeePolicyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		to     *common.Address
+		data   []byte
+		policy string
+	}{
+		"contract-method": {&testPolicyBridge, common.FromHex("0x1532ec34ff"), testPolicyBridge.Hex() + ":1532ec34"},
+		"contract":        {&testPolicyBridge, common.FromHex("0xa9059cbb"), testPolicyBridge.Hex()},
+		"selector":        {&testPolicyToken, common.FromHex("0xa9059cbb00"), "a9059cbb"},
+		"short-data":      {&testPolicyToken, common.FromHex("0xa9059c"), ""},
+		"creation":        {nil, common.FromHex("0x1532ec34"), ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var tx *types.Transaction
+			if tt.to == nil {
+				tx = types.NewContractCreation(0, new(big.Int), 0, new(big.Int), tt.data)
+			} else {
+				tx = types.NewTransaction(0, *tt.to, new(big.Int), 0, new(big.Int), tt.data)
+			}
+			if _, policy := file.lookup(tx); policy != tt.policy {
+				t.Fatalf("mismatched policy: got %q, expect %q", policy, tt.policy)
+			}
+		})
+	}
+
+	// A change to the file is picked up, an invalid file keeps the policies
+	writeFeePolicies(t, path, `{"selectors": {"0xa9059cbb": {"subsidy": 2}}}`, time.Unix(2000, 0))
+	if _, err := file.reload(); !errors.Is(err, errBadConfig) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, errBadConfig)
+	}
+	tx := types.NewTransaction(0, testPolicyBridge, new(big.Int), 0, new(big.Int), nil)
+	if _, policy := file.lookup(tx); policy != testPolicyBridge.Hex() {
+		t.Fatalf("policies not kept after invalid reload: got %q", policy)
+	}
+	writeFeePolicies(t, path, `{}`, time.Unix(3000, 0))
+	if reloaded, err := file.reload(); err != nil || !reloaded {
+		t.Fatalf("policies not reloaded: %v", err)
+	}
+	if _, policy := file.lookup(tx); policy != "" {
+		t.Fatalf("mismatched policy after reload: got %q", policy)
+	}
+}
+
+func TestFeePolicySubsidy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "feepolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policies.json")
+	writeFeePolicies(t, path, testFeePolicies, time.Unix(1000, 0))
+
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	if service.feePolicies, err = newFeePolicyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	data := common.FromHex("0xa9059cbb0000000000000000000000000000000000000000000000000000000000001234")
+	full := fees.EncodeTxGasLimit(data, l1GasPrice, big.NewInt(100_000), l2GasPrice).Uint64()
+
+	// The same calldata with a selector that has no policy
+	other := append(common.FromHex("0x095ea7b3"), data[4:]...)
+
+	tests := map[string]struct {
+		to       common.Address
+		data     []byte
+		gasLimit uint64
+		err      error
+	}{
+		"subsidized":     {testPolicyToken, data, full/2 + 1, nil},
+		"not-subsidized": {testPolicyToken, other, full/2 + 1, fees.ErrFeeTooLow},
+		"free-method":    {testPolicyBridge, common.FromHex("0x1532ec34"), 1, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx, err := types.SignTx(types.NewTransaction(0, tt.to, new(big.Int), tt.gasLimit, fees.BigTxGasPrice, tt.data), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := service.verifyFee(context.Background(), tx); !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFeePolicyEstimate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "feepolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policies.json")
+	writeFeePolicies(t, path, testFeePolicies, time.Unix(1000, 0))
+
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	if service.feePolicies, err = newFeePolicyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+	oracle := &fees.OracleSnapshot{L1GasPrice: l1GasPrice, L2GasPrice: l2GasPrice, CalldataGas: service.calldataGas()}
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	data := common.FromHex("0xa9059cbb0000000000000000000000000000000000000000000000000000000000001234")
+	l2GasLimit := big.NewInt(100_000)
+	full := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+
+	tests := map[string]struct {
+		to         common.Address
+		data       []byte
+		subsidized bool
+	}{
+		"subsidized":     {testPolicyToken, data, true},
+		"not-subsidized": {testPolicyToken, append(common.FromHex("0x095ea7b3"), data[4:]...), false},
+		"free-method":    {testPolicyBridge, common.FromHex("0x1532ec34"), true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := types.NewTransaction(0, tt.to, new(big.Int), 0, fees.BigTxGasPrice, tt.data)
+			estimate, err := service.EstimateTxFee(context.Background(), tx, l2GasLimit, oracle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded := fees.DecodeL2GasLimit(estimate.GasLimit); decoded.Cmp(l2GasLimit) != 0 {
+				t.Fatalf("mismatched L2 gas limit: got %d, expect %d", decoded, l2GasLimit)
+			}
+			if subsidized := estimate.GasLimit.Cmp(full) < 0; subsidized != tt.subsidized {
+				t.Fatalf("mismatched subsidy: got gas limit %d, full gas limit %d", estimate.GasLimit, full)
+			}
+			tx, err = types.SignTx(types.NewTransaction(0, tt.to, new(big.Int), estimate.GasLimit.Uint64(), fees.BigTxGasPrice, tt.data), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := service.verifyFee(context.Background(), tx); err != nil {
+				t.Fatalf("estimate rejected: %v", err)
+			}
+		})
+	}
+}
+
+func TestShadowFeePolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "feepolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policies.json")
+	writeFeePolicies(t, path, testFeePolicies, time.Unix(1000, 0))
+	policies, err := LoadFeePolicies(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	if service.shadowPolicies, err = newFeePolicyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	data := common.FromHex("0xa9059cbb0000000000000000000000000000000000000000000000000000000000001234")
+	full := fees.EncodeTxGasLimit(data, l1GasPrice, big.NewInt(100_000), l2GasPrice)
+	half := new(big.Int).Add(new(big.Int).Div(full, big.NewInt(2)), common.Big1)
+
+	tests := map[string]struct {
+		policies    *FeePolicies
+		gasLimit    *big.Int
+		thresholdUp *big.Float
+		active      error
+		shadow      string
+	}{
+		// The candidate subsidy accepts half of the fee
+		"shadow-accepts": {policies, half, nil, fees.ErrFeeTooLow, feeDecisionAccept},
+		// The candidate subsidy makes the full fee an overpayment
+		"shadow-rejects": {policies, full, big.NewFloat(0.5), nil, feeOutcomeTooHigh},
+		"same":           {policies, full, nil, nil, feeDecisionAccept},
+		"no-policies":    {nil, half, nil, fees.ErrFeeTooLow, feeOutcomeTooLow},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			userFee := new(big.Int).Mul(tt.gasLimit, fees.BigTxGasPrice)
+			tx, err := types.SignTx(types.NewTransaction(0, testPolicyToken, new(big.Int), tt.gasLimit.Uint64(), fees.BigTxGasPrice, data), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check := checkShadowFee(tt.policies, tx, full, userFee, tt.thresholdUp, nil)
+			if check.outcome != tt.shadow {
+				t.Fatalf("mismatched shadow outcome: got %s, expect %s", check.outcome, tt.shadow)
+			}
+			// The shadow policies never change the decision
+			service.feeThresholdUp = tt.thresholdUp
+			if err := service.verifyFee(context.Background(), tx); !errors.Is(err, tt.active) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.active)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package rollup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+var (
+	feeQuoteHonoredMeter   = metrics.NewRegisteredMeter("rollup/feequote/honored", nil)
+	feeQuoteExpiredMeter   = metrics.NewRegisteredMeter("rollup/feequote/expired", nil)
+	feeQuoteInvalidMeter   = metrics.NewRegisteredMeter("rollup/feequote/invalid", nil)
+	feeQuoteExhaustedMeter = metrics.NewRegisteredMeter("rollup/feequote/exhausted", nil)
+)
+
+// feeQuoteConsumptionLimit is the number of quotes whose consumption is
+// remembered
+const feeQuoteConsumptionLimit = 4096
+
+// feeQuoteLedger tracks the fees accepted at quoted gas prices. It bounds the
+// exposure of the sequencer to gas price changes by limiting the fees that
+// are accepted with quotes to a volume per block. The sequencer creates a
+// block for every transaction, so the volume is averaged over the commitment
+// window of the quotes, which is the number of blocks that they are valid for.
+type feeQuoteLedger struct {
+	lock   sync.Mutex
+	window uint64
+	// Volume of fees per block, unbounded when nil
+	maxVolume *big.Int
+	// Volume of fees accepted with quotes by block number
+	volumes     map[uint64]*big.Int
+	consumption *lru.Cache
+}
+
+func newFeeQuoteLedger(window uint64, maxVolume *big.Int) *feeQuoteLedger {
+	consumption, _ := lru.New(feeQuoteConsumptionLimit)
+	if maxVolume != nil && maxVolume.Sign() == 0 {
+		maxVolume = nil
+	}
+	return &feeQuoteLedger{
+		window:      window,
+		maxVolume:   maxVolume,
+		volumes:     make(map[uint64]*big.Int),
+		consumption: consumption,
+	}
+}
+
+// consume records that a transaction in the block pays the fee with the
+// quote, unless that would exceed the volume allowed over the commitment
+// window ending at the block
+func (l *feeQuoteLedger) consume(quote *feesig.FeeQuote, block uint64, fee *big.Int) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	total := new(big.Int).Set(fee)
+	for number, volume := range l.volumes {
+		if number+l.window <= block {
+			delete(l.volumes, number)
+			continue
+		}
+		total.Add(total, volume)
+	}
+	if l.maxVolume != nil {
+		limit := new(big.Int).Mul(l.maxVolume, new(big.Int).SetUint64(l.window))
+		if total.Cmp(limit) > 0 {
+			return fmt.Errorf("%w: %d of %d in the last %d blocks", feesig.ErrFeeQuoteVolumeExhausted,
+				new(big.Int).Sub(total, fee), limit, l.window)
+		}
+	}
+	if volume, ok := l.volumes[block]; ok {
+		volume.Add(volume, fee)
+	} else {
+		l.volumes[block] = new(big.Int).Set(fee)
+	}
+
+	hash := quote.Hash()
+	consumption := &feesig.FeeQuoteConsumption{Volume: (*hexutil.Big)(new(big.Int))}
+	if prev, ok := l.consumption.Get(hash); ok {
+		consumption = prev.(*feesig.FeeQuoteConsumption)
+	}
+	l.consumption.Add(hash, &feesig.FeeQuoteConsumption{
+		Transactions: consumption.Transactions + 1,
+		Volume:       (*hexutil.Big)(new(big.Int).Add(consumption.Volume.ToInt(), fee)),
+	})
+	return nil
+}
+
+// Consumption returns the transactions accepted with the quote of the hash,
+// nil when none were accepted
+func (l *feeQuoteLedger) Consumption(hash common.Hash) *feesig.FeeQuoteConsumption {
+	consumption, ok := l.consumption.Get(hash)
+	if !ok {
+		return nil
+	}
+	return consumption.(*feesig.FeeQuoteConsumption)
+}
+
+// markFeeQuote records the outcome of checking a transaction that was
+// submitted with a fee quote
+func markFeeQuote(err error) {
+	switch {
+	case err == nil:
+		feeQuoteHonoredMeter.Mark(1)
+	case errors.Is(err, feesig.ErrFeeQuoteExpired):
+		feeQuoteExpiredMeter.Mark(1)
+	case errors.Is(err, feesig.ErrFeeQuoteVolumeExhausted):
+		feeQuoteExhaustedMeter.Mark(1)
+	case errors.Is(err, feesig.ErrInvalidFeeQuote):
+		feeQuoteInvalidMeter.Mark(1)
+	}
+}
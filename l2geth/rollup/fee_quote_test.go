@@ -0,0 +1,160 @@
+package rollup
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+)
+
+func TestFeeQuote(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	service.feeQuoteKey, _ = crypto.GenerateKey()
+	service.feeQuoteValidity = 10
+	service.feeQuotes = newFeeQuoteLedger(10, nil)
+	service.RollupGpo.SetL1GasPrice(big.NewInt(params.GWei))
+	service.RollupGpo.SetL2GasPrice(big.NewInt(params.GWei))
+
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	otherKey, _ := crypto.GenerateKey()
+
+	quote, err := service.IssueFeeQuote(context.Background(), sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uint64(quote.ValidUntil) != 10 {
+		t.Fatalf("mismatched valid until: got %d, expect 10", quote.ValidUntil)
+	}
+	// The fee is estimated with the quoted gas prices, which then increase
+	data := []byte{0x01, 0x02}
+	gasLimit := fees.EncodeTxGasLimit(data, quote.L1GasPrice.ToInt(), big.NewInt(21000), quote.L2GasPrice.ToInt())
+	service.RollupGpo.SetL2GasPrice(big.NewInt(10 * params.GWei))
+
+	newTx := func(key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewTransaction(0, common.Address{}, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	resign := func(quote feesig.FeeQuote, key *ecdsa.PrivateKey) *feesig.FeeQuote {
+		if err := quote.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		return &quote
+	}
+	tampered := *quote
+	tampered.L2GasPrice = (*hexutil.Big)(big.NewInt(1))
+	expired := *quote
+	expired.ValidUntil = 0
+
+	tests := map[string]struct {
+		tx    *types.Transaction
+		quote *feesig.FeeQuote
+		err   error
+	}{
+		"no-quote": {
+			tx:  newTx(key),
+			err: fees.ErrFeeTooLow,
+		},
+		"quote": {
+			tx:    newTx(key),
+			quote: quote,
+		},
+		"other-sender": {
+			tx:    newTx(otherKey),
+			quote: quote,
+			err:   feesig.ErrInvalidFeeQuote,
+		},
+		"other-signer": {
+			tx:    newTx(key),
+			quote: resign(*quote, otherKey),
+			err:   feesig.ErrInvalidFeeQuote,
+		},
+		"tampered": {
+			tx:    newTx(key),
+			quote: &tampered,
+			err:   feesig.ErrInvalidFeeQuote,
+		},
+		"expired": {
+			tx:    newTx(key),
+			quote: resign(expired, service.feeQuoteKey),
+			err:   feesig.ErrFeeQuoteExpired,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.quote != nil {
+				ctx = feesig.WithFeeQuote(ctx, tt.quote)
+			}
+			err := service.verifyFee(ctx, tt.tx)
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFeeQuoteLedger(t *testing.T) {
+	quote := &feesig.FeeQuote{
+		ChainID:    (*hexutil.Big)(big.NewInt(420)),
+		L1GasPrice: (*hexutil.Big)(big.NewInt(1)),
+		L2GasPrice: (*hexutil.Big)(big.NewInt(1)),
+	}
+	other := *quote
+	other.ValidUntil = 1
+
+	// Up to 100 per block, averaged over 3 blocks
+	ledger := newFeeQuoteLedger(3, big.NewInt(100))
+	steps := []struct {
+		quote *feesig.FeeQuote
+		block uint64
+		fee   int64
+		err   error
+	}{
+		{quote, 1, 200, nil},
+		{quote, 2, 100, nil},
+		{&other, 3, 1, feesig.ErrFeeQuoteVolumeExhausted},
+		// The fee of block 1 leaves the window
+		{&other, 4, 200, nil},
+		{quote, 5, 101, feesig.ErrFeeQuoteVolumeExhausted},
+		{quote, 7, 300, nil},
+	}
+	for i, step := range steps {
+		err := ledger.consume(step.quote, step.block, big.NewInt(step.fee))
+		if !errors.Is(err, step.err) || (step.err == nil && err != nil) {
+			t.Fatalf("step %d: mismatched error: got %v, expect %v", i, err, step.err)
+		}
+	}
+	consumption := ledger.Consumption(quote.Hash())
+	if consumption == nil || consumption.Transactions != 3 || consumption.Volume.ToInt().Int64() != 600 {
+		t.Fatalf("mismatched consumption: %+v", consumption)
+	}
+	if consumption := ledger.Consumption(other.Hash()); consumption == nil || consumption.Transactions != 1 {
+		t.Fatalf("mismatched consumption of other quote: %+v", consumption)
+	}
+	if ledger.Consumption(common.Hash{}) != nil {
+		t.Fatal("consumption of unknown quote")
+	}
+}
@@ -0,0 +1,191 @@
+package rollup
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// feeRebateVaultABI is the interface of the OVM_FeeRebateVault that the
+// rebate operator records the rebates of a transaction batch with
+const feeRebateVaultABI = `[{"inputs":[{"internalType":"uint256","name":"_batchIndex","type":"uint256"},{"internalType":"address[]","name":"_senders","type":"address[]"},{"internalType":"uint256[]","name":"_amounts","type":"uint256[]"}],"name":"recordRebates","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+const (
+	// maxRebatesPerTx is the number of rebates recorded by a single
+	// transaction of the rebate operator
+	maxRebatesPerTx = 256
+	// feeRebateL2Gas is the L2 gas limit of a transaction that records
+	// rebates, before the gas of each rebate
+	feeRebateL2Gas = 50_000
+	// feeRebateL2GasPerRebate is the L2 gas of each rebate recorded by a
+	// transaction, which writes a new storage slot in the worst case
+	feeRebateL2GasPerRebate = 30_000
+)
+
+var feeRebateAmountCounter = metrics.NewRegisteredCounter("rollup/rebate/amount", nil)
+
+// feeRebater records the rebates of the senders of the transactions in a
+// reconciled transaction batch in the OVM_FeeRebateVault
+type feeRebater struct {
+	abi abi.ABI
+	key *ecdsa.PrivateKey
+}
+
+func newFeeRebater(key *ecdsa.PrivateKey) (*feeRebater, error) {
+	parsed, err := abi.JSON(strings.NewReader(feeRebateVaultABI))
+	if err != nil {
+		return nil, err
+	}
+	return &feeRebater{abi: parsed, key: key}, nil
+}
+
+// batchRebates returns the rebates of the senders of the transactions in the
+// reconciled batch. The L1 fee that a transaction was charged is the one in
+// its receipt when the receipt records it, otherwise it is recovered from
+// its gas limit at the L2 gas price of the parent state, up to the rounding
+// of the gas limit. Transactions from L1 paid for their gas on L1 and are
+// not rebated.
+func (s *SyncService) batchRebates(report *fees.Reconciliation) ([]fees.Rebate, error) {
+	var txs []fees.RebateTx
+	for number := uint64(report.StartBlock); number <= uint64(report.EndBlock); number++ {
+		block := s.bc.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("Cannot get block %d", number)
+		}
+		receipts := s.bc.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(block.Transactions()) {
+			return nil, fmt.Errorf("Cannot get receipts for block %d", number)
+		}
+		for i, tx := range block.Transactions() {
+			if tx.QueueOrigin() != types.QueueOriginSequencer || tx.GasPrice().Sign() == 0 {
+				continue
+			}
+			sender, err := types.Sender(s.signer, tx)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot recover sender of %s: %w", tx.Hash().Hex(), err)
+			}
+			charged := receipts[i].L1Fee
+			if charged == nil {
+				parent := s.bc.GetBlock(block.ParentHash(), number-1)
+				if parent == nil {
+					return nil, fmt.Errorf("Cannot get block %d", number-1)
+				}
+				statedb, err := s.bc.StateAt(parent.Root())
+				if err != nil {
+					return nil, fmt.Errorf("Cannot get state of block %d: %w", number-1, err)
+				}
+				slots, err := s.readGPOStorageSlots(statedb)
+				if err != nil {
+					return nil, err
+				}
+				charged = fees.MaxChargedL1Fee(tx.Gas(), slots.GasPrice)
+			}
+			if charged.Sign() <= 0 {
+				charged = new(big.Int)
+			}
+			calldataGas := core.BlockL1CalldataGas(s.bc.Config(), block.Number(), tx.L1BlockNumber())
+			txs = append(txs, fees.RebateTx{
+				Sender:       sender,
+				L1GasUsed:    tx.L1GasUsedWith(calldataGas),
+				ChargedL1Fee: charged,
+			})
+		}
+	}
+	return fees.ComputeRebates(report.Cost.ToInt(), txs), nil
+}
+
+// recordRebates records the rebates of a reconciled batch in the
+// OVM_FeeRebateVault with transactions of the rebate operator. Batches whose
+// rebates are already recorded in the vault are skipped, so that a batch is
+// not rebated twice after a restart.
+func (s *SyncService) recordRebates(ctx context.Context, report *fees.Reconciliation) error {
+	statedb, err := s.bc.State()
+	if err != nil {
+		return fmt.Errorf("Cannot read fee rebate vault: %w", err)
+	}
+	index := uint64(report.BatchIndex)
+	if next := rcfg.ReadFeeRebateNextBatch(statedb); index < next {
+		log.Debug("Skipping recorded fee rebates", "index", index, "next", next)
+		return nil
+	}
+	rebates, err := s.batchRebates(report)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(rebates); start += maxRebatesPerTx {
+		end := start + maxRebatesPerTx
+		if end > len(rebates) {
+			end = len(rebates)
+		}
+		if err := s.applyRebates(ctx, index, rebates[start:end]); err != nil {
+			return err
+		}
+	}
+	total := new(big.Int)
+	for _, rebate := range rebates {
+		total.Add(total, rebate.Amount.ToInt())
+	}
+	feeRebateAmountCounter.Inc(new(big.Int).Div(total, big.NewInt(params.GWei)).Int64())
+	log.Info("Recorded fee rebates", "index", index, "senders", len(rebates), "total", total)
+	return nil
+}
+
+// applyRebates applies a transaction of the rebate operator that records the
+// rebates in the OVM_FeeRebateVault
+func (s *SyncService) applyRebates(ctx context.Context, index uint64, rebates []fees.Rebate) error {
+	senders := make([]common.Address, len(rebates))
+	amounts := make([]*big.Int, len(rebates))
+	for i, rebate := range rebates {
+		senders[i], amounts[i] = rebate.Sender, rebate.Amount.ToInt()
+	}
+	data, err := s.feeRebater.abi.Pack("recordRebates", new(big.Int).SetUint64(index), senders, amounts)
+	if err != nil {
+		return err
+	}
+	snapshot := s.snapshotFees(ctx)
+	if snapshot.err != nil {
+		return snapshot.err
+	}
+	l1Fee := snapshot.l1FeeParams.daCost.CostOf(data)
+	l2Gas := big.NewInt(feeRebateL2Gas + feeRebateL2GasPerRebate*int64(len(rebates)))
+	gasLimit := fees.EncodeTxGasLimitForL1Fee(l1Fee, l2Gas, snapshot.l2GasPrice)
+
+	operator := crypto.PubkeyToAddress(s.feeRebater.key.PublicKey)
+	statedb, err := s.bc.State()
+	if err != nil {
+		return fmt.Errorf("Cannot read rebate operator nonce: %w", err)
+	}
+	tx := types.NewTransaction(statedb.GetNonce(operator), rcfg.L2FeeRebateVaultAddress, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, data)
+	tx, err = types.SignTx(tx, s.signer, s.feeRebater.key)
+	if err != nil {
+		return err
+	}
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+	tx.SetTransactionMeta(types.NewTransactionMeta(nil, 0, nil, types.QueueOriginSequencer, nil, nil, raw))
+	if err := s.applySequencerTransaction(ctx, tx, laneDefault); err != nil {
+		return fmt.Errorf("Cannot record fee rebates: %w", err)
+	}
+	block := s.bc.CurrentBlock()
+	receipts := s.bc.GetReceiptsByHash(block.Hash())
+	if len(receipts) == 0 || receipts[0].TxHash != tx.Hash() || receipts[0].Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("Cannot record fee rebates: transaction %s failed", tx.Hash().Hex())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package rollup
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+var (
+	// errFeeReplayDisabled is the error for when fees are replayed on a node
+	// that is not configured with an attestation key
+	errFeeReplayDisabled = errors.New("fee replay is not enabled")
+	// errReplayStateUnavailable is the error for when the state of the parent
+	// of a replayed block has been pruned
+	errReplayStateUnavailable = errors.New("state not available, replaying fees requires an archive node")
+)
+
+// feeReplayer recomputes the fees of the transactions in historical blocks
+// from archived state and batch data, without relying on any value that
+// is only known to the sequencer at the time, so that independent nodes
+// produce the same report for the same block
+type feeReplayer struct {
+	bc      *core.BlockChain
+	db      ethdb.Database
+	client  RollupClient
+	key     *ecdsa.PrivateKey
+	readGPO func(*state.StateDB) (*rcfg.GPOStorageSlots, error)
+	// Looks up the L1 submissions of batches, nil without an L1 node
+	batches *reconciler
+}
+
+// replay returns the signed fee attestation of a block
+func (r *feeReplayer) replay(ctx context.Context, number uint64) (*feesig.FeeAttestation, error) {
+	if number == 0 {
+		return nil, errors.New("Cannot replay the genesis block")
+	}
+	block := r.bc.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("Cannot get block %d: %w", number, errElementNotFound)
+	}
+	parent := r.bc.GetBlock(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, fmt.Errorf("Cannot get parent of block %d", number)
+	}
+	receipts := r.bc.GetReceiptsByHash(block.Hash())
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("Cannot get receipts for block %d", number)
+	}
+	// The sequencer checks the fees of a block with the gas prices of its
+	// parent, which are recorded with the block unless it was imported
+	// before they were
+	l2GasPrice, err := r.l2GasPrice(block, parent)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := r.batch(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*feesig.ReplayedFee, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		gasUsed := new(big.Int).SetUint64(receipts[i].GasUsed)
+		l2GasLimit := fees.DecodeL2GasLimit(new(big.Int).SetUint64(tx.Gas()))
+		l1GasUsed := new(big.Int).SetUint64(tx.L1GasUsedWith(core.BlockL1CalldataGas(r.bc.Config(), block.Number(), tx.L1BlockNumber())))
+		l2Fee := new(big.Int).Mul(l2GasPrice, fees.Ceilmod(l2GasLimit, fees.BigTenThousand))
+		replayed := &feesig.ReplayedFee{
+			TxHash:     tx.Hash(),
+			GasUsed:    hexutil.Uint64(receipts[i].GasUsed),
+			L2GasLimit: hexutil.Uint64(l2GasLimit.Uint64()),
+			L1GasUsed:  hexutil.Uint64(l1GasUsed.Uint64()),
+			Paid:       (*hexutil.Big)(gasUsed.Mul(gasUsed, tx.GasPrice())),
+			L2Fee:      (*hexutil.Big)(l2Fee),
+		}
+		if batch != nil {
+			replayed.L1Fee = (*hexutil.Big)(new(big.Int).Mul(l1GasUsed, batch.L1GasPrice.ToInt()))
+		}
+		txs[i] = replayed
+	}
+	attestation := feesig.NewFeeAttestation(r.bc.Config().ChainID, number, block.Hash(), parent.Root(), l2GasPrice, batch, txs)
+	if err := attestation.Sign(r.key); err != nil {
+		return nil, fmt.Errorf("Cannot sign fee attestation: %w", err)
+	}
+	return attestation, nil
+}
+
+// l2GasPrice returns the L2 gas price of the gas price oracle that the fees
+// of the block were checked against
+func (r *feeReplayer) l2GasPrice(block, parent *types.Block) (*big.Int, error) {
+	if params := rawdb.ReadBlockFeeParams(r.db, block.Hash(), block.NumberU64()); params != nil {
+		return params.L2GasPrice, nil
+	}
+	statedb, err := r.bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", parent.NumberU64(), errReplayStateUnavailable)
+	}
+	slots, err := r.readGPO(statedb)
+	if err != nil {
+		return nil, err
+	}
+	return slots.GasPrice, nil
+}
+
+// batch returns the L1 submission of the batch that includes the block, nil
+// when the block is not batched yet or there is no L1 node to read from
+func (r *feeReplayer) batch(ctx context.Context, number uint64) (*feesig.AttestedBatch, error) {
+	if r.batches == nil {
+		return nil, nil
+	}
+	latest, err := r.client.GetLatestTransactionBatchIndex()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get latest batch index: %w", err)
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	// Handle the off by one, the transaction of block n has index n-1. The
+	// batches are ordered by the index of their first transaction, so the
+	// batch that includes it is found with a binary search.
+	index := number - 1
+	lo, hi := uint64(0), *latest
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		batch, _, err := r.client.GetTransactionBatch(mid)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot get batch %d: %w", mid, err)
+		}
+		if batch == nil {
+			return nil, fmt.Errorf("Cannot get batch %d: %w", mid, errElementNotFound)
+		}
+		start := uint64(batch.PrevTotalElements)
+		switch {
+		case index < start:
+			if mid == 0 {
+				return nil, nil
+			}
+			hi = mid - 1
+		case index >= start+uint64(batch.Size):
+			lo = mid + 1
+		default:
+			txHash, _, gasPrice, err := r.batches.batchSubmission(ctx, batch)
+			if err != nil {
+				return nil, err
+			}
+			return &feesig.AttestedBatch{
+				Index:      hexutil.Uint64(batch.Index),
+				L1TxHash:   txHash,
+				L1GasPrice: (*hexutil.Big)(gasPrice),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReplayFees recomputes the fees of the transactions in a historical block
+// and returns a report signed with the attestation key of the node
+func (s *SyncService) ReplayFees(ctx context.Context, number uint64) (*feesig.FeeAttestation, error) {
+	if s.feeReplayer == nil {
+		return nil, errFeeReplayDisabled
+	}
+	return s.feeReplayer.replay(ctx, number)
+}
@@ -0,0 +1,177 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// replayTestClient serves a fixed list of transaction batches
+type replayTestClient struct {
+	*mockClient
+	batches []*Batch
+}
+
+func (c *replayTestClient) GetLatestTransactionBatchIndex() (*uint64, error) {
+	if len(c.batches) == 0 {
+		return nil, nil
+	}
+	latest := uint64(len(c.batches) - 1)
+	return &latest, nil
+}
+
+func (c *replayTestClient) GetTransactionBatch(index uint64) (*Batch, []*types.Transaction, error) {
+	if index >= uint64(len(c.batches)) {
+		return nil, nil, errElementNotFound
+	}
+	return c.batches[index], nil, nil
+}
+
+func TestFeeReplay(t *testing.T) {
+	var (
+		key, _         = crypto.GenerateKey()
+		from           = crypto.PubkeyToAddress(key.PublicKey)
+		attestorKey, _ = crypto.GenerateKey()
+		chainID        = big.NewInt(420)
+		signer         = types.NewEIP155Signer(chainID)
+		l1GasPrice     = big.NewInt(params.GWei)
+		l2GasPrice     = big.NewInt(params.GWei)
+		batchGasPrice  = big.NewInt(10 * params.GWei)
+	)
+	chainCfg := *params.AllEthashProtocolChanges
+	chainCfg.ChainID = chainID
+	genesis := &core.Genesis{
+		Config:   &chainCfg,
+		GasLimit: 10_000_000_000,
+		Alloc: core.GenesisAlloc{
+			from: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))},
+			rcfg.L2GasPriceOracleAddress: {
+				Balance: new(big.Int),
+				Storage: map[common.Hash]common.Hash{
+					rcfg.L2GasPriceSlot: common.BigToHash(l2GasPrice),
+				},
+			},
+		},
+	}
+	engine := ethash.NewFaker()
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, &chainCfg, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	data := []byte{0x00, 0x01}
+	l2GasLimit := big.NewInt(30_000)
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+	blocks, _ := core.GenerateChain(&chainCfg, chain.CurrentBlock(), engine, db, 2, func(i int, b *core.BlockGen) {
+		tx := types.NewTransaction(uint64(i), common.Address{}, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(signed)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first block is batched
+	client := &replayTestClient{
+		mockClient: newMockClient(nil),
+		batches:    []*Batch{{Index: 0, Size: 1, BlockNumber: 10}},
+	}
+	l1 := &mockL1RPC{responses: map[string]string{
+		"eth_getLogs":               `[{"transactionHash":"0x0000000000000000000000000000000000000000000000000000000000000001","removed":false}]`,
+		"eth_getTransactionReceipt": `{"gasUsed":"0x64","effectiveGasPrice":"0x2540be400"}`,
+	}}
+	readGPO := func(statedb *state.StateDB) (*rcfg.GPOStorageSlots, error) {
+		return rcfg.ReadGPOStorageSlots(statedb)
+	}
+
+	tests := map[string]struct {
+		number  uint64
+		batches *reconciler
+		batched bool
+		err     error
+	}{
+		"batched": {
+			number:  1,
+			batches: newReconciler(l1, client, chain),
+			batched: true,
+		},
+		"not-batched": {
+			number:  2,
+			batches: newReconciler(l1, client, chain),
+		},
+		"no-l1": {
+			number: 1,
+		},
+		"unknown-block": {
+			number: 3,
+			err:    errElementNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			replayer := &feeReplayer{
+				bc:      chain,
+				db:      db,
+				client:  client,
+				key:     attestorKey,
+				readGPO: readGPO,
+				batches: tt.batches,
+			}
+			attestation, err := replayer.replay(context.Background(), tt.number)
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if signer, err := attestation.Signer(); err != nil || signer != crypto.PubkeyToAddress(attestorKey.PublicKey) {
+				t.Fatalf("mismatched signer: %s, %v", signer.Hex(), err)
+			}
+			if (attestation.Batch != nil) != tt.batched {
+				t.Fatalf("mismatched batch: %+v", attestation.Batch)
+			}
+			if len(attestation.Transactions) != 1 {
+				t.Fatalf("mismatched transactions: %d", len(attestation.Transactions))
+			}
+			tx := attestation.Transactions[0]
+			paid := new(big.Int).Mul(new(big.Int).SetUint64(uint64(tx.GasUsed)), fees.BigTxGasPrice)
+			if tx.Paid.ToInt().Cmp(paid) != 0 || attestation.Paid.ToInt().Cmp(paid) != 0 {
+				t.Fatalf("mismatched paid: got %d, expect %d", tx.Paid.ToInt(), paid)
+			}
+			l2Fee := new(big.Int).Mul(l2GasPrice, l2GasLimit)
+			if tx.L2Fee.ToInt().Cmp(l2Fee) != 0 {
+				t.Fatalf("mismatched l2 fee: got %d, expect %d", tx.L2Fee.ToInt(), l2Fee)
+			}
+			if !tt.batched {
+				if tx.L1Fee != nil || attestation.L1Fee != nil {
+					t.Fatalf("unexpected l1 fee: %v", tx.L1Fee)
+				}
+				return
+			}
+			l1Fee := new(big.Int).Mul(fees.CalculateL1GasUsed(data), batchGasPrice)
+			if tx.L1Fee.ToInt().Cmp(l1Fee) != 0 || attestation.L1Fee.ToInt().Cmp(l1Fee) != 0 {
+				t.Fatalf("mismatched l1 fee: got %d, expect %d", tx.L1Fee.ToInt(), l1Fee)
+			}
+		})
+	}
+}
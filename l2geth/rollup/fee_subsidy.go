@@ -0,0 +1,51 @@
+package rollup
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+var feeSubsidyMeter = metrics.NewRegisteredMeter("rollup/fee/subsidy", nil)
+
+// feeSubsidyReader reads the subsidies of the OVM_FeeSubsidyRegistry from the
+// state of the tip. The state is not safe for concurrent use, so the reads of
+// fee checks that run at the same time are serialized.
+type feeSubsidyReader struct {
+	lock  sync.Mutex
+	state *state.StateDB
+}
+
+// read returns the subsidy of the contract, nil when there is no state
+func (r *feeSubsidyReader) read(contract common.Address) *rcfg.FeeSubsidy {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return rcfg.ReadFeeSubsidy(r.state, contract)
+}
+
+// feeSubsidies returns the reader of the fee subsidies at the tip. It is nil
+// when the state is not available, when the feeSubsidy rule is not active at
+// the latest L1 block, which new transactions are assigned, or when the gas
+// price oracle does not store the L1 fee params, as the subsidies are then
+// not debited.
+func (s *SyncService) feeSubsidies() *feeSubsidyReader {
+	l1Block := new(big.Int).SetUint64(s.GetLatestL1BlockNumber())
+	if !s.bc.Config().RollupRules(l1Block).FeeSubsidy {
+		return nil
+	}
+	statedb, err := s.bc.State()
+	if err != nil {
+		return nil
+	}
+	if _, err := rcfg.ReadL1FeeParams(statedb); err != nil {
+		return nil
+	}
+	return &feeSubsidyReader{state: statedb}
+}
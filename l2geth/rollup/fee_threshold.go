@@ -0,0 +1,134 @@
+package rollup
+
+import (
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// thresholdWindow is the number of trailing L1 gas price changes that
+	// the volatility is measured over
+	thresholdWindow = 64
+	// thresholdMinSamples is the number of L1 gas price changes that must be
+	// observed before the threshold is tuned, the static threshold is used
+	// until then
+	thresholdMinSamples = 8
+	// thresholdVolatilityMultiple is the number of standard deviations of
+	// the L1 gas price changes that the buffer covers
+	thresholdVolatilityMultiple = 3
+	// defaultThresholdDownMin is the widest buffer that the controller sets
+	// when no minimum is configured
+	defaultThresholdDownMin = 0.5
+	// thresholdLogStep is the change of the threshold that is logged
+	thresholdLogStep = 0.01
+)
+
+var feeThresholdDownGauge = metrics.NewRegisteredGaugeFloat64("rollup/fee/thresholddown", nil)
+
+// thresholdController tunes the fee threshold down from the volatility of
+// the L1 gas price. The threshold lets transactions that were priced at a
+// slightly older L1 gas price be accepted, so it is widened when the L1 gas
+// price moves a lot between updates and narrowed when it is stable. The
+// buffer covers a multiple of the standard deviation of the relative changes
+// of the L1 gas price, bounded by the minimum threshold.
+type thresholdController struct {
+	min    float64
+	static *big.Float
+
+	lock      sync.Mutex
+	last      *big.Int
+	changes   []float64
+	next      int
+	threshold *big.Float
+	logged    float64
+}
+
+// newThresholdController creates a thresholdController that uses the static
+// threshold until enough L1 gas prices are observed and never sets the
+// threshold below min
+func newThresholdController(static *big.Float, min float64) *thresholdController {
+	if min <= 0 {
+		min = defaultThresholdDownMin
+	}
+	return &thresholdController{
+		min:       min,
+		static:    static,
+		changes:   make([]float64, 0, thresholdWindow),
+		threshold: static,
+	}
+}
+
+// observe records an L1 gas price and tunes the threshold
+func (c *thresholdController) observe(l1GasPrice *big.Int) {
+	if c == nil || l1GasPrice == nil || l1GasPrice.Sign() <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	last := c.last
+	c.last = new(big.Int).Set(l1GasPrice)
+	if last == nil {
+		return
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(l1GasPrice), new(big.Float).SetInt(last)).Float64()
+	change := ratio - 1
+	if len(c.changes) < thresholdWindow {
+		c.changes = append(c.changes, change)
+	} else {
+		c.changes[c.next] = change
+		c.next = (c.next + 1) % thresholdWindow
+	}
+	if len(c.changes) < thresholdMinSamples {
+		return
+	}
+	threshold := 1 - thresholdVolatilityMultiple*stddev(c.changes)
+	if threshold < c.min {
+		threshold = c.min
+	}
+	c.threshold = big.NewFloat(threshold)
+	feeThresholdDownGauge.Update(threshold)
+	if math.Abs(threshold-c.logged) >= thresholdLogStep {
+		log.Info("Tuned fee threshold down", "threshold", threshold, "samples", len(c.changes))
+		c.logged = threshold
+	}
+}
+
+// thresholdDown returns the effective fee threshold down, nil when there is
+// no threshold
+func (c *thresholdController) thresholdDown() *big.Float {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.threshold
+}
+
+// stddev returns the standard deviation of the values
+func stddev(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+// effectiveFeeThresholdDown returns the fee threshold down that fees are
+// verified with, tuned from the volatility of the L1 gas price or from the
+// false rejections when enabled
+func (s *SyncService) effectiveFeeThresholdDown() *big.Float {
+	if s.thresholdController != nil {
+		return s.thresholdController.thresholdDown()
+	}
+	if s.rejectionController != nil {
+		return s.rejectionController.thresholdDown()
+	}
+	return s.feeThresholdDown
+}
@@ -0,0 +1,247 @@
+package rollup
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+const (
+	// falseRejectionHorizon is how long after a rejection the L1 gas price is
+	// read that decides whether the rejection was false, about the time it
+	// takes to submit the batch of a transaction
+	falseRejectionHorizon = 2 * time.Minute
+	// falseRejectionPendingLimit is the number of rejections that wait to be
+	// settled, further rejections are not settled
+	falseRejectionPendingLimit = 4096
+	// falseRejectionMinChecks is the number of fee checks that a window must
+	// have before the threshold is adjusted
+	falseRejectionMinChecks = 200
+	// falseRejectionStep is the largest change of the threshold per window
+	falseRejectionStep = 0.01
+	// falseRejectionDecisions is the number of decisions that are kept
+	falseRejectionDecisions = 64
+	// checkBucketWidth is the span of time whose fee checks are counted
+	// together until their horizon has passed
+	checkBucketWidth = 10 * time.Second
+)
+
+const (
+	thresholdActionWiden  = "widen"
+	thresholdActionNarrow = "narrow"
+	thresholdActionHold   = "hold"
+)
+
+var (
+	falseRejectionMeter     = metrics.NewRegisteredMeter("rollup/fee/falserejections", nil)
+	falseRejectionRateGauge = metrics.NewRegisteredGaugeFloat64("rollup/fee/falserejectionrate", nil)
+)
+
+// pendingRejection is a rejection for paying too little that is settled once
+// the horizon has passed
+type pendingRejection struct {
+	rejected    time.Time
+	userFee     *big.Int
+	expectedFee *big.Int
+	l1Fee       *big.Int
+	l1GasPrice  *big.Int
+}
+
+// checkBucket is the number of fee checks in a span of time
+type checkBucket struct {
+	start  time.Time
+	checks uint64
+}
+
+// rejectionController tunes the fee threshold down to keep the rate of false
+// rejections under a target. A rejection for paying too little is false when
+// the fee of the transaction covers its expected fee at the L1 gas price
+// observed after the horizon, which is what the batch of the transaction
+// would have paid. At the end of every window of fee checks the threshold is
+// lowered by a step when the rate is above the target and raised by a step
+// when it is below half of the target, within the min and max. Fee checks
+// are counted in the window once their horizon has passed as well, so that
+// the rate covers the same checks as the false rejections.
+type rejectionController struct {
+	target float64
+	min    float64
+	max    float64
+	now    func() time.Time
+
+	lock            sync.Mutex
+	threshold       float64
+	pending         []pendingRejection
+	unsettled       []checkBucket
+	checks          uint64
+	falseRejections uint64
+	decisions       []fees.ThresholdDecision
+}
+
+// newRejectionController creates a rejectionController that starts at the
+// static threshold, which is also the highest threshold that it sets
+func newRejectionController(static *big.Float, min, target float64) *rejectionController {
+	if min <= 0 {
+		min = defaultThresholdDownMin
+	}
+	max := 1.0
+	if static != nil {
+		max, _ = static.Float64()
+	}
+	feeThresholdDownGauge.Update(max)
+	return &rejectionController{
+		target:    target,
+		min:       min,
+		max:       max,
+		now:       time.Now,
+		threshold: max,
+	}
+}
+
+// observeCheck counts a fee decision that reached the fee check and keeps it
+// to be settled when it was rejected for paying too little
+func (c *rejectionController) observeCheck(d *feeDecision, err error) {
+	if c == nil || d.userFee == nil || d.expectedFee == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	if n := len(c.unsettled); n != 0 && now.Sub(c.unsettled[n-1].start) < checkBucketWidth {
+		c.unsettled[n-1].checks++
+	} else {
+		c.unsettled = append(c.unsettled, checkBucket{start: now, checks: 1})
+	}
+	if !errors.Is(err, fees.ErrFeeTooLow) || d.l1Fee == nil || d.l1GasPrice == nil || d.l1GasPrice.Sign() == 0 {
+		return
+	}
+	if len(c.pending) < falseRejectionPendingLimit {
+		c.pending = append(c.pending, pendingRejection{
+			rejected:    now,
+			userFee:     d.userFee,
+			expectedFee: d.expectedFee,
+			l1Fee:       d.l1Fee,
+			l1GasPrice:  d.l1GasPrice,
+		})
+	}
+}
+
+// observe settles the rejections whose horizon has passed at an L1 gas price
+// and adjusts the threshold once the window has enough fee checks
+func (c *rejectionController) observe(l1GasPrice *big.Int) {
+	if c == nil || l1GasPrice == nil || l1GasPrice.Sign() <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	settled := 0
+	for _, b := range c.unsettled {
+		if now.Sub(b.start) < falseRejectionHorizon+checkBucketWidth {
+			break
+		}
+		c.checks += b.checks
+		settled++
+	}
+	c.unsettled = c.unsettled[settled:]
+	pending := c.pending[:0]
+	for _, r := range c.pending {
+		if now.Sub(r.rejected) < falseRejectionHorizon {
+			pending = append(pending, r)
+			continue
+		}
+		// The L1 fee moves with the L1 gas price, the rest of the fee does
+		// not
+		l1Fee := new(big.Int).Mul(r.l1Fee, l1GasPrice)
+		l1Fee.Div(l1Fee, r.l1GasPrice)
+		cost := new(big.Int).Sub(r.expectedFee, r.l1Fee)
+		cost.Add(cost, l1Fee)
+		if r.userFee.Cmp(cost) >= 0 {
+			c.falseRejections++
+			falseRejectionMeter.Mark(1)
+		}
+	}
+	c.pending = pending
+	if c.checks < falseRejectionMinChecks {
+		return
+	}
+	c.adjust(now)
+}
+
+// adjust moves the threshold by a step towards the target rate and starts a
+// new window. It must be called holding the lock.
+func (c *rejectionController) adjust(now time.Time) {
+	rate := float64(c.falseRejections) / float64(c.checks)
+	decision := fees.ThresholdDecision{
+		Time:            uint64(now.Unix()),
+		Checks:          c.checks,
+		FalseRejections: c.falseRejections,
+		Rate:            rate,
+		From:            c.threshold,
+		To:              c.threshold,
+		Action:          thresholdActionHold,
+	}
+	switch {
+	case rate > c.target:
+		decision.To = math.Max(c.threshold-falseRejectionStep, c.min)
+	case rate < c.target/2:
+		decision.To = math.Min(c.threshold+falseRejectionStep, c.max)
+	}
+	switch {
+	case decision.To < decision.From:
+		decision.Action = thresholdActionWiden
+	case decision.To > decision.From:
+		decision.Action = thresholdActionNarrow
+	}
+	c.threshold = decision.To
+	c.checks, c.falseRejections = 0, 0
+	if len(c.decisions) == falseRejectionDecisions {
+		c.decisions = c.decisions[1:]
+	}
+	c.decisions = append(c.decisions, decision)
+
+	falseRejectionRateGauge.Update(rate)
+	feeThresholdDownGauge.Update(decision.To)
+	log.Info("Adjusted fee threshold down", "action", decision.Action, "from", decision.From, "to", decision.To,
+		"rate", rate, "target", c.target, "checks", decision.Checks, "false", decision.FalseRejections)
+}
+
+// thresholdDown returns the fee threshold down that is in effect
+func (c *rejectionController) thresholdDown() *big.Float {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return big.NewFloat(c.threshold)
+}
+
+// state returns the state of the controller and its recent decisions
+func (c *rejectionController) state() *fees.ThresholdControl {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return &fees.ThresholdControl{
+		Threshold:       c.threshold,
+		Target:          c.target,
+		Min:             c.min,
+		Max:             c.max,
+		Checks:          c.checks,
+		FalseRejections: c.falseRejections,
+		Pending:         len(c.pending),
+		Decisions:       append([]fees.ThresholdDecision{}, c.decisions...),
+	}
+}
+
+// FeeThresholdControl returns the state of the controller of the fee
+// threshold down, nil when the threshold is not tuned from false rejections
+func (s *SyncService) FeeThresholdControl() *fees.ThresholdControl {
+	if s.rejectionController == nil {
+		return nil
+	}
+	return s.rejectionController.state()
+}
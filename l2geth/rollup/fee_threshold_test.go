@@ -0,0 +1,110 @@
+package rollup
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestThresholdController(t *testing.T) {
+	static := big.NewFloat(0.9)
+	tests := map[string]struct {
+		prices   []int64
+		min      float64
+		expected float64
+	}{
+		"not-enough-samples": {
+			prices:   []int64{100, 200, 100},
+			expected: 0.9,
+		},
+		"stable": {
+			prices:   []int64{100, 100, 100, 100, 100, 100, 100, 100, 100, 100},
+			expected: 1,
+		},
+		// Alternating 10% moves widen the buffer
+		"volatile": {
+			prices:   []int64{100, 110, 100, 110, 100, 110, 100, 110, 100, 110},
+			expected: 0.71,
+		},
+		"bounded": {
+			prices:   []int64{100, 200, 100, 200, 100, 200, 100, 200, 100, 200},
+			min:      0.6,
+			expected: 0.6,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newThresholdController(static, tt.min)
+			for _, price := range tt.prices {
+				c.observe(big.NewInt(price))
+			}
+			threshold, _ := c.thresholdDown().Float64()
+			if threshold < tt.expected-0.01 || threshold > tt.expected+0.01 {
+				t.Fatalf("mismatched threshold: got %f, expect %f", threshold, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRejectionController(t *testing.T) {
+	tests := map[string]struct {
+		checks     int
+		rejections int
+		min        float64
+		settle     int64
+		threshold  float64
+		action     string
+	}{
+		// The L1 gas price fell, so the rejected fees covered the cost
+		"false-rejections": {200, 10, 0.9, 80, 0.94, thresholdActionWiden},
+		"true-rejections":  {200, 10, 0.9, 100, 0.95, thresholdActionHold},
+		"min":              {200, 10, 0.95, 80, 0.95, thresholdActionHold},
+		"not-enough":       {100, 10, 0.9, 80, 0.95, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newRejectionController(big.NewFloat(0.95), tt.min, 0.01)
+			now := time.Unix(1000, 0)
+			c.now = func() time.Time { return now }
+			for i := 0; i < tt.checks; i++ {
+				d := &feeDecision{
+					userFee:     big.NewInt(950),
+					expectedFee: big.NewInt(1000),
+					l1Fee:       big.NewInt(500),
+					l1GasPrice:  big.NewInt(100),
+				}
+				var err error
+				if i < tt.rejections {
+					err = fees.NewFeeRejection(fees.ErrFeeTooLow, &fees.PaysEnoughOpts{}, 0)
+				}
+				c.observeCheck(d, err)
+			}
+			// Rejections are not settled before the horizon
+			c.observe(big.NewInt(tt.settle))
+			if len(c.pending) != tt.rejections {
+				t.Fatalf("mismatched pending: got %d, expect %d", len(c.pending), tt.rejections)
+			}
+			now = now.Add(falseRejectionHorizon + checkBucketWidth)
+			c.observe(big.NewInt(tt.settle))
+
+			state := c.state()
+			if state.Pending != 0 {
+				t.Fatalf("mismatched pending: got %d, expect 0", state.Pending)
+			}
+			if state.Threshold < tt.threshold-1e-9 || state.Threshold > tt.threshold+1e-9 {
+				t.Fatalf("mismatched threshold: got %f, expect %f", state.Threshold, tt.threshold)
+			}
+			if tt.action == "" {
+				if len(state.Decisions) != 0 {
+					t.Fatalf("unexpected decisions: %v", state.Decisions)
+				}
+				return
+			}
+			if len(state.Decisions) != 1 || state.Decisions[0].Action != tt.action {
+				t.Fatalf("mismatched decisions: got %v, expect %s", state.Decisions, tt.action)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+var feeValidationBatchTimer = metrics.NewRegisteredTimer("rollup/fee/validation/batch", nil)
+
+// feeSnapshot are the values of the gas price oracle that the fees of
+// transactions are verified against. Taking them once for a set of
+// transactions means that every transaction of the set is verified against
+// the same values, even when the oracle is updated while they are verified.
+type feeSnapshot struct {
+	l1GasPrice *big.Int
+	l2GasPrice *big.Int
+	gpoOwner   *common.Address
+	// The parameters of the L1 fee, set with the L1 gas price
+	l1FeeParams *l1FeeParams
+	// The fee threshold down at the time of the snapshot
+	thresholdDown *big.Float
+	// Reads the fee subsidies at the tip, nil when the state is not
+	// available or the subsidies are not active
+	subsidies *feeSubsidyReader
+	// Set in strict mode when the oracle has not been initialized
+	uninitialized error
+	// The error reading the gas prices, returned only for transactions that
+	// need them so that the owner of the oracle can still update it
+	err error
+}
+
+// snapshotFees reads the current values of the gas price oracle
+func (s *SyncService) snapshotFees(ctx context.Context) *feeSnapshot {
+	snapshot := &feeSnapshot{
+		gpoOwner:      s.GasPriceOracleOwnerAddress(),
+		thresholdDown: s.effectiveFeeThresholdDown(),
+		uninitialized: s.gpoUninitializedErr(),
+		subsidies:     s.feeSubsidies(),
+	}
+	snapshot.l1GasPrice, snapshot.err = s.RollupGpo.SuggestL1GasPrice(ctx)
+	if snapshot.err != nil {
+		return snapshot
+	}
+	snapshot.l2GasPrice, snapshot.err = s.RollupGpo.SuggestL2GasPrice(ctx)
+	if snapshot.err != nil {
+		return snapshot
+	}
+	snapshot.l1FeeParams, snapshot.err = s.l1FeeParamsAt(ctx, snapshot.l1GasPrice, s.calldataGas())
+	return snapshot
+}
+
+// calldataGas returns the calldata gas schedule of the L1 chain at the latest
+// L1 block number, which is the L1 block number that new transactions are
+// assigned
+func (s *SyncService) calldataGas() fees.CalldataGas {
+	l1Block := new(big.Int).SetUint64(s.GetLatestL1BlockNumber())
+	return core.L1CalldataGas(s.bc.Config(), l1Block)
+}
+
+// verifyFees verifies the fees of a set of transactions concurrently against
+// a single snapshot of the gas price oracle. The fee checks are independent
+// of each other, so they are spread over a pool of workers. The decisions and
+// the errors are returned in the order of the transactions, the errors are
+// nil for the transactions that pay a valid fee.
+func (s *SyncService) verifyFees(ctx context.Context, txs []*types.Transaction) ([]*feeDecision, []error) {
+	defer feeValidationBatchTimer.UpdateSince(time.Now())
+
+	decisions := make([]*feeDecision, len(txs))
+	errs := make([]error, len(txs))
+	if len(txs) == 0 {
+		return decisions, errs
+	}
+	snapshot := s.snapshotFees(ctx)
+
+	workers := s.feeValidationWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	indices := make(chan int, len(txs))
+	for i := range txs {
+		indices <- i
+	}
+	close(indices)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if txs[i] == nil {
+					errs[i] = errors.New("nil transaction passed to ValidateAndApplySequencerTransactions")
+					continue
+				}
+				// Fee quotes are attached to the context of a single
+				// transaction, so they do not apply to a set
+				decisions[i] = &feeDecision{tx: txs[i], signer: s.signer, decision: feeDecisionAccept}
+				errs[i] = s.decideFee(ctx, txs[i], snapshot, nil, decisions[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return decisions, errs
+}
+
+// ValidateAndApplySequencerTransactions is ValidateAndApplySequencerTransaction
+// for a set of transactions, such as the persisted transaction pool that is
+// replayed on startup. The fees of the set are verified concurrently, then
+// the transactions that pay a valid fee are applied in order. The errors are
+// returned in the order of the transactions.
+func (s *SyncService) ValidateAndApplySequencerTransactions(ctx context.Context, txs []*types.Transaction) []error {
+	if s.verifier {
+		errs := make([]error, len(txs))
+		for i := range errs {
+			errs[i] = errors.New("Verifier does not accept transactions out of band")
+		}
+		return errs
+	}
+	// Transactions that are applied first come first served take their
+	// place in line before their fees are verified
+	arrivals := make([]*laneWaiter, len(txs))
+	for i := range txs {
+		arrivals[i] = s.txLanes.arrive()
+	}
+	decisions, errs := s.verifyFees(ctx, txs)
+	for i, tx := range txs {
+		if errs[i] != nil {
+			s.txLanes.leave(arrivals[i])
+			continue
+		}
+		w := arrivals[i]
+		if w == nil {
+			w = s.txLanes.join(s.txLanes.laneOf(decisions[i]))
+		}
+		s.waitTurn(ctx, w)
+		errs[i] = s.applyAtTip(ctx, tx, w)
+		s.txLanes.release()
+	}
+	return errs
+}
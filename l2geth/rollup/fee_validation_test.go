@@ -0,0 +1,112 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestVerifyFeesMatchesSequential(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	service.feeValidationWorkers = 4
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	newTx := func(nonce, gasLimit uint64, gasPrice *big.Int, data []byte) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{}, new(big.Int), gasLimit, gasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	var txs []*types.Transaction
+	for i := 0; i < 32; i++ {
+		data := make([]byte, i*7)
+		for j := range data {
+			data[j] = byte(j % 3)
+		}
+		gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, big.NewInt(100_000), l2GasPrice).Uint64()
+		switch i % 4 {
+		case 1:
+			gasLimit /= 2
+		case 2:
+			gasLimit *= 10
+		}
+		gasPrice := fees.BigTxGasPrice
+		if i%8 == 3 {
+			gasPrice = new(big.Int)
+		}
+		txs = append(txs, newTx(uint64(i), gasLimit, gasPrice, data))
+	}
+
+	decisions, errs := service.verifyFees(context.Background(), txs)
+	if len(decisions) != len(txs) || len(errs) != len(txs) {
+		t.Fatalf("mismatched number of results: got %d/%d, expect %d", len(decisions), len(errs), len(txs))
+	}
+	var accepted int
+	for i, tx := range txs {
+		expect := service.verifyFee(context.Background(), tx)
+		if (errs[i] == nil) != (expect == nil) || (expect != nil && errs[i].Error() != expect.Error()) {
+			t.Fatalf("tx %d: mismatched error: got %v, expect %v", i, errs[i], expect)
+		}
+		if decisions[i] == nil || decisions[i].tx != tx {
+			t.Fatalf("tx %d: missing fee decision", i)
+		}
+		if errs[i] == nil {
+			accepted++
+		}
+	}
+	if accepted == 0 || accepted == len(txs) {
+		t.Fatalf("expected a mix of accepted and rejected transactions, got %d of %d accepted", accepted, len(txs))
+	}
+}
+
+func TestSnapshotFees(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	service.RollupGpo.SetL1GasPrice(big.NewInt(1))
+	service.RollupGpo.SetL2GasPrice(big.NewInt(1))
+
+	snapshot := service.snapshotFees(context.Background())
+	// Updates to the oracle after the snapshot are not seen by the
+	// transactions that are verified against it
+	service.RollupGpo.SetL2GasPrice(big.NewInt(1000))
+	if snapshot.l2GasPrice.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("mismatched snapshot L2 gas price: got %d, expect 1", snapshot.l2GasPrice)
+	}
+
+	_, errs := service.verifyFees(context.Background(), []*types.Transaction{nil})
+	if errs[0] == nil {
+		t.Fatal("expected error for nil transaction")
+	}
+	if _, errs := service.verifyFees(context.Background(), nil); len(errs) != 0 {
+		t.Fatalf("mismatched number of errors: got %d, expect 0", len(errs))
+	}
+
+	verifier, _, _, err := newTestSyncService(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = verifier.ValidateAndApplySequencerTransactions(context.Background(), []*types.Transaction{mockTx()})
+	if errs[0] == nil || errors.Is(errs[0], fees.ErrFeeTooLow) {
+		t.Fatalf("expected verifier to reject transaction, got %v", errs[0])
+	}
+}
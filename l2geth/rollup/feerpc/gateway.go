@@ -0,0 +1,227 @@
+package feerpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/rs/cors"
+	"golang.org/x/time/rate"
+)
+
+// The paths of the HTTP/JSON gateway
+const (
+	gatewayFeeParamsPath = "/v1/fee-params"
+	gatewayEstimatePath  = "/v1/estimate"
+)
+
+// maxGatewayRequestSize is the largest request body that the gateway accepts,
+// the calldata is hex encoded so it is twice the size of gRPC messages
+const maxGatewayRequestSize = 2 * maxMessageSize
+
+// gatewayClients is the number of clients whose rate limits are remembered
+const gatewayClients = 10000
+
+// GatewayConfig configures the HTTP/JSON gateway
+type GatewayConfig struct {
+	// Requests per second allowed for each client address
+	RateLimit float64
+	// Number of requests that a client can make at once before being rate
+	// limited
+	RateBurst int
+	// Origins allowed to make cross origin requests, cross origin requests
+	// are not allowed when empty
+	CorsOrigins []string
+}
+
+// Gateway serves the fee quotes of the Server as HTTP/JSON, so that web
+// frontends can fetch quotes without access to the JSON-RPC API of the node
+type Gateway struct {
+	server   *Server
+	config   GatewayConfig
+	limiters *lru.Cache
+}
+
+// NewGateway creates an HTTP/JSON gateway to the server
+func NewGateway(server *Server, config GatewayConfig) (*Gateway, error) {
+	if config.RateLimit <= 0 || config.RateBurst <= 0 {
+		return nil, fmt.Errorf("invalid rate limit %f with burst %d", config.RateLimit, config.RateBurst)
+	}
+	limiters, err := lru.New(gatewayClients)
+	if err != nil {
+		return nil, err
+	}
+	return &Gateway{
+		server:   server,
+		config:   config,
+		limiters: limiters,
+	}, nil
+}
+
+// Serve accepts HTTP connections on the listener, it blocks until the
+// listener is closed
+func (g *Gateway) Serve(listener net.Listener) error {
+	server := &http.Server{Handler: g.Handler()}
+	return server.Serve(listener)
+}
+
+// Handler returns the HTTP handler of the gateway, which handles cross
+// origin requests from the configured origins
+func (g *Gateway) Handler() http.Handler {
+	if len(g.config.CorsOrigins) == 0 {
+		return g
+	}
+	c := cors.New(cors.Options{
+		AllowedOrigins: g.config.CorsOrigins,
+		AllowedMethods: []string{http.MethodPost, http.MethodGet},
+		MaxAge:         600,
+		AllowedHeaders: []string{"Content-Type"},
+	})
+	return c.Handler(g)
+}
+
+// GatewayFeeParams are the fee parameters returned by the gateway
+type GatewayFeeParams struct {
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	Overhead   hexutil.Uint64 `json:"overhead"`
+}
+
+// GatewayEstimateArgs is the transaction that the gateway estimates the fee of
+type GatewayEstimateArgs struct {
+	From       *common.Address `json:"from"`
+	To         *common.Address `json:"to"`
+	Value      *hexutil.Big    `json:"value"`
+	Data       hexutil.Bytes   `json:"data"`
+	L2GasLimit hexutil.Uint64  `json:"l2GasLimit"`
+}
+
+// GatewayEstimate is the fee estimate returned by the gateway
+type GatewayEstimate struct {
+	GasLimit   hexutil.Uint64    `json:"gasLimit"`
+	GasPrice   *hexutil.Big      `json:"gasPrice"`
+	Fee        *hexutil.Big      `json:"fee"`
+	L2GasLimit hexutil.Uint64    `json:"l2GasLimit"`
+	L1GasUsed  hexutil.Uint64    `json:"l1GasUsed"`
+	Params     *GatewayFeeParams `json:"params"`
+}
+
+func newGatewayFeeParams(params *FeeParams) *GatewayFeeParams {
+	return &GatewayFeeParams{
+		L1GasPrice: (*hexutil.Big)(new(big.Int).SetBytes(params.L1GasPrice)),
+		L2GasPrice: (*hexutil.Big)(new(big.Int).SetBytes(params.L2GasPrice)),
+		Overhead:   hexutil.Uint64(params.Overhead),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !g.allow(r) {
+		writeGatewayError(w, http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+		return
+	}
+	switch r.URL.Path {
+	case gatewayFeeParamsPath:
+		if r.Method != http.MethodGet {
+			writeGatewayError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		params, err := g.server.GetFeeParams(r.Context(), nil)
+		if err != nil {
+			writeGatewayError(w, gatewayStatus(err), err)
+			return
+		}
+		writeGatewayResult(w, newGatewayFeeParams(params))
+	case gatewayEstimatePath:
+		if r.Method != http.MethodPost {
+			writeGatewayError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		var args GatewayEstimateArgs
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGatewayRequestSize)).Decode(&args); err != nil {
+			writeGatewayError(w, http.StatusBadRequest, fmt.Errorf("cannot decode request: %w", err))
+			return
+		}
+		req := &EstimateFeeRequest{
+			Data:       args.Data,
+			L2GasLimit: uint64(args.L2GasLimit),
+		}
+		if args.From != nil {
+			req.From = args.From.Bytes()
+		}
+		if args.To != nil {
+			req.To = args.To.Bytes()
+		}
+		if args.Value != nil {
+			req.Value = args.Value.ToInt().Bytes()
+		}
+		estimate, err := g.server.EstimateFee(r.Context(), req)
+		if err != nil {
+			writeGatewayError(w, gatewayStatus(err), err)
+			return
+		}
+		writeGatewayResult(w, &GatewayEstimate{
+			GasLimit:   hexutil.Uint64(estimate.GasLimit),
+			GasPrice:   (*hexutil.Big)(new(big.Int).SetBytes(estimate.GasPrice)),
+			Fee:        (*hexutil.Big)(new(big.Int).SetBytes(estimate.Fee)),
+			L2GasLimit: hexutil.Uint64(estimate.L2GasLimit),
+			L1GasUsed:  hexutil.Uint64(estimate.L1GasUsed),
+			Params:     newGatewayFeeParams(estimate.Params),
+		})
+	default:
+		writeGatewayError(w, http.StatusNotFound, fmt.Errorf("unknown path %s", r.URL.Path))
+	}
+}
+
+// allow returns whether the client that sent the request is within its rate
+// limit. Clients are identified by their address.
+func (g *Gateway) allow(r *http.Request) bool {
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	limiter, ok := g.limiters.Get(client)
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(g.config.RateLimit), g.config.RateBurst)
+		g.limiters.Add(client, limiter)
+	}
+	return limiter.(*rate.Limiter).Allow()
+}
+
+// gatewayStatus returns the HTTP status of an error of the server. Errors
+// without a status are returned by estimating the transaction, which fails
+// when the transaction reverts.
+func gatewayStatus(err error) int {
+	var status *statusError
+	if errors.As(err, &status) && status.code == codeInternal {
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+func writeGatewayResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Debug("Cannot write fee gateway response", "err", err)
+	}
+}
+
+func writeGatewayError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	message := err.Error()
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		message = statusErr.message
+	}
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{message})
+}
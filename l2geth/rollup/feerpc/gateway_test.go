@@ -0,0 +1,116 @@
+package feerpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func newTestGateway(t *testing.T, burst int) *Gateway {
+	backend := &testBackend{l1GasPrice: big.NewInt(100), l2GasPrice: big.NewInt(1)}
+	gateway, err := NewGateway(NewServer(backend, testEstimator{}, time.Second), GatewayConfig{
+		RateLimit: 1,
+		RateBurst: burst,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gateway
+}
+
+func TestGateway(t *testing.T) {
+	gateway := newTestGateway(t, 100)
+	expect := fees.EncodeTxGasLimit([]byte{0x01}, big.NewInt(100), big.NewInt(30000), big.NewInt(1))
+
+	tests := map[string]struct {
+		method string
+		path   string
+		body   string
+		status int
+		result string
+	}{
+		"fee-params": {
+			method: http.MethodGet,
+			path:   "/v1/fee-params",
+			status: http.StatusOK,
+			result: `{"l1GasPrice":"0x64","l2GasPrice":"0x1","overhead":"0xabe"}`,
+		},
+		"estimate": {
+			method: http.MethodPost,
+			path:   "/v1/estimate",
+			body:   `{"to":"0x0000000000000000000000000000000000000001","data":"0x01"}`,
+			status: http.StatusOK,
+			result: `{"gasLimit":"` + hexutil.EncodeUint64(expect.Uint64()) + `"`,
+		},
+		"estimate-reverted": {
+			method: http.MethodPost,
+			path:   "/v1/estimate",
+			body:   `{"data":"0x01"}`,
+			status: http.StatusBadRequest,
+			result: `{"error":"execution reverted"}`,
+		},
+		"estimate-invalid-json": {
+			method: http.MethodPost,
+			path:   "/v1/estimate",
+			body:   `{"data":1}`,
+			status: http.StatusBadRequest,
+		},
+		"wrong-method": {
+			method: http.MethodPost,
+			path:   "/v1/fee-params",
+			status: http.StatusMethodNotAllowed,
+		},
+		"unknown-path": {
+			method: http.MethodGet,
+			path:   "/v1/unknown",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			gateway.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("mismatched status: got %d, expect %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Fatalf("invalid response: %s", rec.Body)
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.result) {
+				t.Fatalf("mismatched response: got %s, expect %s", rec.Body, tt.result)
+			}
+		})
+	}
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	gateway := newTestGateway(t, 2)
+	request := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/fee-params", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		gateway.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for i := 0; i < 2; i++ {
+		if status := request("10.0.0.1:1000"); status != http.StatusOK {
+			t.Fatalf("request %d: mismatched status: got %d, expect %d", i, status, http.StatusOK)
+		}
+	}
+	// Clients are limited by their address regardless of their port
+	if status := request("10.0.0.1:2000"); status != http.StatusTooManyRequests {
+		t.Fatalf("mismatched status: got %d, expect %d", status, http.StatusTooManyRequests)
+	}
+	if status := request("10.0.0.2:1000"); status != http.StatusOK {
+		t.Fatalf("mismatched status for another client: got %d, expect %d", status, http.StatusOK)
+	}
+}
@@ -0,0 +1,58 @@
+package feerpc
+
+import "github.com/golang/protobuf/proto"
+
+// The messages of fees.proto. They are declared with the struct tags that the
+// protobuf runtime encodes them with, the field numbers must match fees.proto.
+
+// EstimateFeeRequest is the request of EstimateFee
+type EstimateFeeRequest struct {
+	From       []byte `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To         []byte `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Value      []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Data       []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	L2GasLimit uint64 `protobuf:"varint,5,opt,name=l2_gas_limit,json=l2GasLimit,proto3" json:"l2_gas_limit,omitempty"`
+}
+
+func (m *EstimateFeeRequest) Reset()         { *m = EstimateFeeRequest{} }
+func (m *EstimateFeeRequest) String() string { return proto.CompactTextString(m) }
+func (*EstimateFeeRequest) ProtoMessage()    {}
+
+// EstimateFeeResponse is the response of EstimateFee
+type EstimateFeeResponse struct {
+	GasLimit   uint64     `protobuf:"varint,1,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	GasPrice   []byte     `protobuf:"bytes,2,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
+	Fee        []byte     `protobuf:"bytes,3,opt,name=fee,proto3" json:"fee,omitempty"`
+	L2GasLimit uint64     `protobuf:"varint,4,opt,name=l2_gas_limit,json=l2GasLimit,proto3" json:"l2_gas_limit,omitempty"`
+	L1GasUsed  uint64     `protobuf:"varint,5,opt,name=l1_gas_used,json=l1GasUsed,proto3" json:"l1_gas_used,omitempty"`
+	Params     *FeeParams `protobuf:"bytes,6,opt,name=params,proto3" json:"params,omitempty"`
+}
+
+func (m *EstimateFeeResponse) Reset()         { *m = EstimateFeeResponse{} }
+func (m *EstimateFeeResponse) String() string { return proto.CompactTextString(m) }
+func (*EstimateFeeResponse) ProtoMessage()    {}
+
+// GetFeeParamsRequest is the request of GetFeeParams
+type GetFeeParamsRequest struct{}
+
+func (m *GetFeeParamsRequest) Reset()         { *m = GetFeeParamsRequest{} }
+func (m *GetFeeParamsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetFeeParamsRequest) ProtoMessage()    {}
+
+// StreamFeeParamsRequest is the request of StreamFeeParams
+type StreamFeeParamsRequest struct{}
+
+func (m *StreamFeeParamsRequest) Reset()         { *m = StreamFeeParamsRequest{} }
+func (m *StreamFeeParamsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamFeeParamsRequest) ProtoMessage()    {}
+
+// FeeParams are the parameters that fees are computed with
+type FeeParams struct {
+	L1GasPrice []byte `protobuf:"bytes,1,opt,name=l1_gas_price,json=l1GasPrice,proto3" json:"l1_gas_price,omitempty"`
+	L2GasPrice []byte `protobuf:"bytes,2,opt,name=l2_gas_price,json=l2GasPrice,proto3" json:"l2_gas_price,omitempty"`
+	Overhead   uint64 `protobuf:"varint,3,opt,name=overhead,proto3" json:"overhead,omitempty"`
+}
+
+func (m *FeeParams) Reset()         { *m = FeeParams{} }
+func (m *FeeParams) String() string { return proto.CompactTextString(m) }
+func (*FeeParams) ProtoMessage()    {}
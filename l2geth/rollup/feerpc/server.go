@@ -0,0 +1,336 @@
+// Package feerpc implements the fee estimation service of fees.proto, which is
+// served over gRPC and through an HTTP/JSON gateway.
+//
+// The service speaks the gRPC wire protocol over cleartext HTTP/2 with the
+// protobuf runtime that geth already depends on, so that standard gRPC
+// clients generated from fees.proto can use it without geth depending on
+// grpc-go.
+package feerpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const serviceName = "optimism.rollup.fees.v1.FeeService"
+
+// The paths of the methods of the service
+const (
+	estimateFeePath     = "/" + serviceName + "/EstimateFee"
+	getFeeParamsPath    = "/" + serviceName + "/GetFeeParams"
+	streamFeeParamsPath = "/" + serviceName + "/StreamFeeParams"
+)
+
+// maxMessageSize is the largest request message that is accepted, the same as
+// the default of gRPC servers
+const maxMessageSize = 4 * 1024 * 1024
+
+// gRPC status codes
+const (
+	codeOK                = 0
+	codeUnknown           = 2
+	codeInvalidArgument   = 3
+	codeResourceExhausted = 8
+	codeUnimplemented     = 12
+	codeInternal          = 13
+)
+
+// statusError is an error that is returned to the client with a gRPC status
+// code. Errors of any other type are returned with codeUnknown.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", e.code, e.message)
+}
+
+func statusErrorf(code int, format string, args ...interface{}) error {
+	return &statusError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// Backend is the source of the fee parameters
+type Backend interface {
+	FeeSnapshot() *fees.OracleSnapshot
+	// EstimateTxFee prices a transaction at the gas prices of the snapshot
+	// the same way that the sequencer prices the transactions it accepts
+	EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, snapshot *fees.OracleSnapshot) (*fees.TxFeeEstimate, error)
+}
+
+// Estimator estimates the execution gas of transactions, it is implemented by
+// the eth namespace of the JSON-RPC API
+type Estimator interface {
+	EstimateExecutionGas(ctx context.Context, args ethapi.CallArgs, round *bool) (hexutil.Uint64, error)
+}
+
+// Server implements the FeeService
+type Server struct {
+	backend   Backend
+	estimator Estimator
+	// How often StreamFeeParams checks the fee parameters for changes
+	interval time.Duration
+}
+
+// NewServer creates a FeeService that checks the fee parameters for changes
+// at the interval when streaming them
+func NewServer(backend Backend, estimator Estimator, interval time.Duration) *Server {
+	return &Server{
+		backend:   backend,
+		estimator: estimator,
+		interval:  interval,
+	}
+}
+
+// Serve accepts gRPC connections on the listener, it blocks until the
+// listener is closed
+func (s *Server) Serve(listener net.Listener) error {
+	server := &http.Server{Handler: s.Handler()}
+	return server.Serve(listener)
+}
+
+// Handler returns an HTTP handler that serves the FeeService over cleartext
+// HTTP/2
+func (s *Server) Handler() http.Handler {
+	return h2c.NewHandler(s, &http2.Server{})
+}
+
+// GetFeeParams returns the current fee parameters
+func (s *Server) GetFeeParams(ctx context.Context, req *GetFeeParamsRequest) (*FeeParams, error) {
+	return feeParams(s.backend.FeeSnapshot()), nil
+}
+
+func feeParams(snapshot *fees.OracleSnapshot) *FeeParams {
+	return &FeeParams{
+		L1GasPrice: snapshot.L1GasPrice.Bytes(),
+		L2GasPrice: snapshot.L2GasPrice.Bytes(),
+		Overhead:   fees.Overhead,
+	}
+}
+
+// StreamFeeParams sends the current fee parameters and then sends them again
+// every time that they change, until the context is done
+func (s *Server) StreamFeeParams(ctx context.Context, req *StreamFeeParamsRequest, send func(*FeeParams) error) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	var last *FeeParams
+	for {
+		params, err := s.GetFeeParams(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if last == nil || !proto.Equal(params, last) {
+			if err := send(params); err != nil {
+				return err
+			}
+			last = params
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// EstimateFee returns the gas limit that encodes the fee of the transaction,
+// which is the same value that eth_estimateGas returns
+func (s *Server) EstimateFee(ctx context.Context, req *EstimateFeeRequest) (*EstimateFeeResponse, error) {
+	snapshot := s.backend.FeeSnapshot()
+	args, err := callArgs(req)
+	if err != nil {
+		return nil, err
+	}
+	l2GasLimit := req.L2GasLimit
+	if l2GasLimit == 0 {
+		round := true
+		estimate, err := s.estimator.EstimateExecutionGas(ctx, args, &round)
+		if err != nil {
+			return nil, err
+		}
+		l2GasLimit = uint64(estimate)
+	}
+	roundedL2GasLimit := fees.Ceilmod(new(big.Int).SetUint64(l2GasLimit), fees.BigTenThousand)
+	estimate, err := s.backend.EstimateTxFee(ctx, estimateTx(args), roundedL2GasLimit, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit := estimate.GasLimit
+	if !gasLimit.IsUint64() {
+		return nil, statusErrorf(codeInvalidArgument, "estimate gas overflow: %s", gasLimit)
+	}
+	return &EstimateFeeResponse{
+		GasLimit:   gasLimit.Uint64(),
+		GasPrice:   fees.BigTxGasPrice.Bytes(),
+		Fee:        new(big.Int).Mul(gasLimit, fees.BigTxGasPrice).Bytes(),
+		L2GasLimit: roundedL2GasLimit.Uint64(),
+		L1GasUsed:  snapshot.CalldataGas.CalculateL1GasUsed(req.Data).Uint64(),
+		Params:     feeParams(snapshot),
+	}, nil
+}
+
+// callArgs converts the request to the arguments of a call that the
+// execution gas is estimated with
+func callArgs(req *EstimateFeeRequest) (ethapi.CallArgs, error) {
+	var args ethapi.CallArgs
+	if len(req.From) != 0 {
+		if len(req.From) != common.AddressLength {
+			return args, statusErrorf(codeInvalidArgument, "invalid from address length %d", len(req.From))
+		}
+		from := common.BytesToAddress(req.From)
+		args.From = &from
+	}
+	if len(req.To) != 0 {
+		if len(req.To) != common.AddressLength {
+			return args, statusErrorf(codeInvalidArgument, "invalid to address length %d", len(req.To))
+		}
+		to := common.BytesToAddress(req.To)
+		args.To = &to
+	}
+	if len(req.Value) != 0 {
+		args.Value = (*hexutil.Big)(new(big.Int).SetBytes(req.Value))
+	}
+	data := hexutil.Bytes(req.Data)
+	args.Data = &data
+	return args, nil
+}
+
+// estimateTx converts the arguments of the call to the transaction that its
+// fee is estimated for
+func estimateTx(args ethapi.CallArgs) *types.Transaction {
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+	if args.To == nil {
+		return types.NewContractCreation(0, value, 0, fees.BigTxGasPrice, *args.Data)
+	}
+	return types.NewTransaction(0, *args.To, value, 0, fees.BigTxGasPrice, *args.Data)
+}
+
+// ServeHTTP implements http.Handler, it serves the gRPC requests that are
+// sent over HTTP/2
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+
+	var err error
+	switch r.URL.Path {
+	case estimateFeePath:
+		req := new(EstimateFeeRequest)
+		if err = readMessage(r.Body, req); err == nil {
+			var res *EstimateFeeResponse
+			if res, err = s.EstimateFee(r.Context(), req); err == nil {
+				err = writeMessage(w, res)
+			}
+		}
+	case getFeeParamsPath:
+		req := new(GetFeeParamsRequest)
+		if err = readMessage(r.Body, req); err == nil {
+			var res *FeeParams
+			if res, err = s.GetFeeParams(r.Context(), req); err == nil {
+				err = writeMessage(w, res)
+			}
+		}
+	case streamFeeParamsPath:
+		req := new(StreamFeeParamsRequest)
+		if err = readMessage(r.Body, req); err == nil {
+			err = s.StreamFeeParams(r.Context(), req, func(params *FeeParams) error {
+				return writeMessage(w, params)
+			})
+		}
+	default:
+		err = statusErrorf(codeUnimplemented, "unknown method %s", r.URL.Path)
+	}
+	code, message := codeOK, ""
+	if err != nil {
+		log.Debug("Fee gRPC request failed", "method", r.URL.Path, "err", err)
+		code, message = codeUnknown, err.Error()
+		var status *statusError
+		if errors.As(err, &status) {
+			code, message = status.code, status.message
+		}
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", encodeStatusMessage(message))
+}
+
+// readMessage reads a length prefixed message from a request
+func readMessage(r io.Reader, msg proto.Message) error {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return statusErrorf(codeInvalidArgument, "cannot read message: %v", err)
+	}
+	// Compression is never negotiated as no encodings are accepted
+	if prefix[0] != 0 {
+		return statusErrorf(codeUnimplemented, "compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxMessageSize {
+		return statusErrorf(codeResourceExhausted, "message too large: %d bytes, at most %d are allowed", size, maxMessageSize)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return statusErrorf(codeInvalidArgument, "cannot read message: %v", err)
+	}
+	if err := proto.Unmarshal(buf, msg); err != nil {
+		return statusErrorf(codeInvalidArgument, "cannot decode message: %v", err)
+	}
+	return nil
+}
+
+// writeMessage writes a length prefixed message to a response and flushes it
+// so that streamed messages are sent immediately
+func writeMessage(w http.ResponseWriter, msg proto.Message) error {
+	buf, err := proto.Marshal(msg)
+	if err != nil {
+		return statusErrorf(codeInternal, "cannot encode message: %v", err)
+	}
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(buf)))
+	if _, err := w.Write(append(prefix[:], buf...)); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// encodeStatusMessage percent encodes the status message as gRPC requires
+func encodeStatusMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,239 @@
+package feerpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+)
+
+type testBackend struct {
+	mu         sync.Mutex
+	l1GasPrice *big.Int
+	l2GasPrice *big.Int
+}
+
+func (b *testBackend) FeeSnapshot() *fees.OracleSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &fees.OracleSnapshot{
+		L1GasPrice:  b.l1GasPrice,
+		L2GasPrice:  b.l2GasPrice,
+		CalldataGas: core.L1CalldataGas(params.TestChainConfig, common.Big0),
+		Time:        time.Now(),
+	}
+}
+
+// testDACost prices the data of transactions at fixed prices that differ
+// from calldata on L1
+var testDACost = &fees.DACost{Zero: big.NewInt(1), NonZero: big.NewInt(3), Fixed: big.NewInt(500)}
+
+type testDAEstimator struct{}
+
+func (testDAEstimator) Backend() string { return fees.DABackendExternal }
+
+func (testDAEstimator) DACost(ctx context.Context, l1GasPrice *big.Int, calldataGas fees.CalldataGas) (*fees.DACost, error) {
+	return testDACost, nil
+}
+
+func (b *testBackend) EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, snapshot *fees.OracleSnapshot) (*fees.TxFeeEstimate, error) {
+	return fees.EstimateTxFee(ctx, testDAEstimator{}, snapshot, tx.Data(), l2GasLimit)
+}
+
+func (b *testBackend) setL2GasPrice(price *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.l2GasPrice = price
+}
+
+// testEstimator uses 21000 gas plus a gas per byte of data and rejects
+// transactions without a recipient
+type testEstimator struct{}
+
+func (testEstimator) EstimateExecutionGas(ctx context.Context, args ethapi.CallArgs, round *bool) (hexutil.Uint64, error) {
+	if args.To == nil {
+		return 0, errors.New("execution reverted")
+	}
+	gas := new(big.Int).SetUint64(21000 + uint64(len(*args.Data)))
+	if round != nil && *round {
+		gas = fees.Ceilmod(gas, fees.BigTenThousand)
+	}
+	return hexutil.Uint64(gas.Uint64()), nil
+}
+
+func newTestServer(t *testing.T) (*testBackend, *httptest.Server, *http.Client) {
+	backend := &testBackend{l1GasPrice: big.NewInt(100), l2GasPrice: big.NewInt(1)}
+	server := httptest.NewServer(NewServer(backend, testEstimator{}, 10*time.Millisecond).Handler())
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+	return backend, server, client
+}
+
+func frame(t *testing.T, msg proto.Message) []byte {
+	buf, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := make([]byte, 5)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(buf)))
+	return append(prefix, buf...)
+}
+
+// call sends a request to a method and returns the response body, which must
+// be read to completion before the status is available in the trailers
+func call(t *testing.T, server *httptest.Server, client *http.Client, path string, body []byte) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, server.URL+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func readFrame(t *testing.T, r io.Reader, msg proto.Message) bool {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err == io.EOF {
+		return false
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(buf, msg); err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func TestEstimateFee(t *testing.T) {
+	_, server, client := newTestServer(t)
+	defer server.Close()
+
+	to := make([]byte, 20)
+	data := []byte{0x00, 0x01, 0x02}
+	l2GasPrice := big.NewInt(1)
+
+	tests := map[string]struct {
+		req        *EstimateFeeRequest
+		status     string
+		l2GasLimit uint64
+	}{
+		"estimated": {
+			req:        &EstimateFeeRequest{To: to, Data: data},
+			status:     "0",
+			l2GasLimit: 30000,
+		},
+		"given-l2-gas-limit": {
+			req:        &EstimateFeeRequest{Data: data, L2GasLimit: 123456},
+			status:     "0",
+			l2GasLimit: 130000,
+		},
+		"estimation-error": {
+			req:    &EstimateFeeRequest{Data: data},
+			status: "2",
+		},
+		"invalid-address": {
+			req:    &EstimateFeeRequest{To: []byte{0x01}},
+			status: "3",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := call(t, server, client, estimateFeePath, frame(t, tt.req))
+			defer res.Body.Close()
+			estimate := new(EstimateFeeResponse)
+			ok := readFrame(t, res.Body, estimate)
+			io.Copy(ioutil.Discard, res.Body)
+			if status := res.Trailer.Get("Grpc-Status"); status != tt.status {
+				t.Fatalf("mismatched status: got %s (%s), expect %s", status, res.Trailer.Get("Grpc-Message"), tt.status)
+			}
+			if tt.status != "0" {
+				if ok {
+					t.Fatal("response sent for failed request")
+				}
+				return
+			}
+			if estimate.L2GasLimit != tt.l2GasLimit {
+				t.Fatalf("mismatched L2 gas limit: got %d, expect %d", estimate.L2GasLimit, tt.l2GasLimit)
+			}
+			expect := fees.EncodeTxGasLimitForL1Fee(testDACost.CostOf(data), new(big.Int).SetUint64(tt.l2GasLimit), l2GasPrice)
+			if estimate.GasLimit != expect.Uint64() {
+				t.Fatalf("mismatched gas limit: got %d, expect %d", estimate.GasLimit, expect)
+			}
+			if fee := new(big.Int).SetBytes(estimate.Fee); fee.Cmp(new(big.Int).Mul(expect, fees.BigTxGasPrice)) != 0 {
+				t.Fatalf("mismatched fee: got %d", fee)
+			}
+			if decoded := fees.DecodeL2GasLimitU64(estimate.GasLimit); decoded != tt.l2GasLimit {
+				t.Fatalf("gas limit decodes to %d, expect %d", decoded, tt.l2GasLimit)
+			}
+		})
+	}
+}
+
+func TestStreamFeeParams(t *testing.T) {
+	backend, server, client := newTestServer(t)
+	defer server.Close()
+
+	res := call(t, server, client, streamFeeParamsPath, frame(t, new(StreamFeeParamsRequest)))
+	defer res.Body.Close()
+
+	params := new(FeeParams)
+	if !readFrame(t, res.Body, params) {
+		t.Fatal("no fee parameters sent")
+	}
+	if new(big.Int).SetBytes(params.L2GasPrice).Int64() != 1 || params.Overhead != fees.Overhead {
+		t.Fatalf("mismatched fee parameters: %v", params)
+	}
+	backend.setL2GasPrice(big.NewInt(2))
+	if !readFrame(t, res.Body, params) {
+		t.Fatal("no fee parameters sent after an update")
+	}
+	if l2GasPrice := new(big.Int).SetBytes(params.L2GasPrice); l2GasPrice.Int64() != 2 {
+		t.Fatalf("mismatched L2 gas price: got %d, expect 2", l2GasPrice)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	_, server, client := newTestServer(t)
+	defer server.Close()
+
+	res := call(t, server, client, "/"+serviceName+"/Unknown", frame(t, new(GetFeeParamsRequest)))
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if status := res.Trailer.Get("Grpc-Status"); status != "12" {
+		t.Fatalf("mismatched status: got %s, expect 12", status)
+	}
+}
@@ -0,0 +1,21 @@
+package fees
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// AdmissionStats aggregates the fee checks of the sequencer over a second
+type AdmissionStats struct {
+	// Time is the unix timestamp of the second
+	Time     hexutil.Uint64 `json:"time"`
+	Accepted hexutil.Uint64 `json:"accepted"`
+	Rejected hexutil.Uint64 `json:"rejected"`
+	// MeanFee is the mean fee paid by the accepted transactions that pay
+	// their fee with the gas price, nil when there were none
+	MeanFee *hexutil.Big `json:"meanFee"`
+	// MinL1GasPrice and MinL2GasPrice are the lowest gas prices that the fee
+	// of a transaction can be encoded with at the end of the second and
+	// still be accepted
+	MinL1GasPrice *hexutil.Big `json:"minL1GasPrice"`
+	MinL2GasPrice *hexutil.Big `json:"minL2GasPrice"`
+}
@@ -0,0 +1,142 @@
+package fees
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// benchCorpus is a set of representative signed transactions along with the
+// gas prices that their gas limits were encoded with
+type benchCorpus struct {
+	ChainID      hexutil.Uint64 `json:"chainId"`
+	L1GasPrice   *hexutil.Big   `json:"l1GasPrice"`
+	L2GasPrice   *hexutil.Big   `json:"l2GasPrice"`
+	Transactions []*benchTx     `json:"transactions"`
+}
+
+type benchTx struct {
+	Name string        `json:"name"`
+	Raw  hexutil.Bytes `json:"raw"`
+}
+
+// benchTxData mirrors the RLP encoding of a transaction, the transaction
+// type cannot be used here as it depends on this package
+type benchTxData struct {
+	Nonce     uint64
+	Price     *big.Int
+	GasLimit  uint64
+	Recipient *common.Address `rlp:"nil"`
+	Amount    *big.Int
+	Payload   []byte
+	V, R, S   *big.Int
+}
+
+func loadBenchCorpus(b *testing.B) (*benchCorpus, []*benchTxData) {
+	raw, err := ioutil.ReadFile(filepath.Join("testdata", "transactions.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	corpus := new(benchCorpus)
+	if err := json.Unmarshal(raw, corpus); err != nil {
+		b.Fatal(err)
+	}
+	txs := make([]*benchTxData, len(corpus.Transactions))
+	for i, tx := range corpus.Transactions {
+		txs[i] = new(benchTxData)
+		if err := rlp.DecodeBytes(tx.Raw, txs[i]); err != nil {
+			b.Fatalf("cannot decode %s: %v", tx.Name, err)
+		}
+	}
+	return corpus, txs
+}
+
+func BenchmarkCalculateL1GasUsed(b *testing.B) {
+	corpus, txs := loadBenchCorpus(b)
+	for i, tx := range txs {
+		b.Run(corpus.Transactions[i].Name, func(b *testing.B) {
+			b.SetBytes(int64(len(tx.Payload)))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				CalculateL1GasUsed(tx.Payload)
+			}
+		})
+	}
+}
+
+func BenchmarkEncodeTxGasLimit(b *testing.B) {
+	corpus, txs := loadBenchCorpus(b)
+	for i, tx := range txs {
+		l2GasLimit := DecodeL2GasLimit(new(big.Int).SetUint64(tx.GasLimit))
+		b.Run(corpus.Transactions[i].Name, func(b *testing.B) {
+			b.SetBytes(int64(len(tx.Payload)))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				EncodeTxGasLimit(tx.Payload, corpus.L1GasPrice.ToInt(), l2GasLimit, corpus.L2GasPrice.ToInt())
+			}
+		})
+	}
+}
+
+func BenchmarkPaysEnough(b *testing.B) {
+	corpus, txs := loadBenchCorpus(b)
+	for i, tx := range txs {
+		l2GasLimit := DecodeL2GasLimit(new(big.Int).SetUint64(tx.GasLimit))
+		expected := EncodeTxGasLimit(tx.Payload, corpus.L1GasPrice.ToInt(), l2GasLimit, corpus.L2GasPrice.ToInt())
+		opts := &PaysEnoughOpts{
+			UserFee:       new(big.Int).Mul(new(big.Int).SetUint64(tx.GasLimit), tx.Price),
+			ExpectedFee:   new(big.Int).Mul(expected, BigTxGasPrice),
+			ThresholdUp:   new(big.Float).SetFloat64(3),
+			ThresholdDown: new(big.Float).SetFloat64(0.9),
+		}
+		b.Run(corpus.Transactions[i].Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if err := PaysEnough(opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCalculatorEncodeTxGasLimit(b *testing.B) {
+	corpus, txs := loadBenchCorpus(b)
+	var c Calculator
+	z := new(big.Int)
+	for i, tx := range txs {
+		l2GasLimit := DecodeL2GasLimit(new(big.Int).SetUint64(tx.GasLimit))
+		b.Run(corpus.Transactions[i].Name, func(b *testing.B) {
+			b.SetBytes(int64(len(tx.Payload)))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				c.EncodeTxGasLimit(z, tx.Payload, corpus.L1GasPrice.ToInt(), l2GasLimit, corpus.L2GasPrice.ToInt())
+			}
+		})
+	}
+}
+
+// BenchmarkBlockBuilding runs the fee arithmetic that the sequencer runs for
+// every transaction of a block, checking its fee and recording it in the fee
+// statistics of the block
+func BenchmarkBlockBuilding(b *testing.B) {
+	corpus, txs := loadBenchCorpus(b)
+	l2GasLimits := make([]*big.Int, len(txs))
+	for i, tx := range txs {
+		l2GasLimits[i] = DecodeL2GasLimit(new(big.Int).SetUint64(tx.GasLimit))
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		blockFees := NewBlockFees(uint64(n), 0)
+		for i, tx := range txs {
+			EncodeTxGasLimit(tx.Payload, corpus.L1GasPrice.ToInt(), l2GasLimits[i], corpus.L2GasPrice.ToInt())
+			blockFees.Add(tx.Payload, l2GasLimits[i].Uint64(), tx.Price)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package fees
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BlockFeeParams are the parameters of the gas price oracle in the state
+// before a block, which the fees of its transactions were checked against.
+// They are recorded with the block, so that the fees of past blocks can be
+// explained without the historical state.
+type BlockFeeParams struct {
+	Number     uint64
+	GPOVersion uint64
+	GPOOwner   common.Address
+	L2GasPrice *big.Int
+}
+
+// BlockFeeParamsResult is the JSON encoding of BlockFeeParams
+type BlockFeeParamsResult struct {
+	Number     hexutil.Uint64 `json:"number"`
+	GPOVersion hexutil.Uint64 `json:"gpoVersion"`
+	GPOOwner   common.Address `json:"gpoOwner"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+}
+
+// Result returns the JSON encoding of the parameters
+func (p *BlockFeeParams) Result() *BlockFeeParamsResult {
+	return &BlockFeeParamsResult{
+		Number:     hexutil.Uint64(p.Number),
+		GPOVersion: hexutil.Uint64(p.GPOVersion),
+		GPOOwner:   p.GPOOwner,
+		L2GasPrice: (*hexutil.Big)(p.L2GasPrice),
+	}
+}
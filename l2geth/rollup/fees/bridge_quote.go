@@ -0,0 +1,71 @@
+package fees
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ExecutionEstimate is the fee of an L2 transaction
+type ExecutionEstimate struct {
+	// GasLimit is the `tx.gasLimit` that encodes the fee
+	GasLimit   hexutil.Uint64 `json:"gasLimit"`
+	L2GasLimit hexutil.Uint64 `json:"l2GasLimit"`
+	L1GasUsed  hexutil.Uint64 `json:"l1GasUsed"`
+	Fee        *hexutil.Big   `json:"fee"`
+}
+
+// NewExecutionEstimate returns the fee of an L2 transaction with the calldata
+// that uses the L2 gas at the gas prices
+func NewExecutionEstimate(calldataGas CalldataGas, data []byte, l2GasLimit uint64, l1GasPrice, l2GasPrice *big.Int) (*ExecutionEstimate, error) {
+	gasLimit := calldataGas.EncodeTxGasLimit(data, l1GasPrice, new(big.Int).SetUint64(l2GasLimit), l2GasPrice)
+	if !gasLimit.IsUint64() {
+		return nil, fmt.Errorf("fee overflow: %s", gasLimit)
+	}
+	return &ExecutionEstimate{
+		GasLimit:   hexutil.Uint64(gasLimit.Uint64()),
+		L2GasLimit: hexutil.Uint64(l2GasLimit),
+		L1GasUsed:  hexutil.Uint64(calldataGas.CalculateL1GasUsed(data).Uint64()),
+		Fee:        (*hexutil.Big)(new(big.Int).Mul(gasLimit, BigTxGasPrice)),
+	}, nil
+}
+
+// BridgeQuote is the cost of a round trip through the bridge: a deposit from
+// L1, optionally an L2 transaction, and a withdrawal back to L1. The quote is
+// an estimate rather than a commitment, it is priced at an L1 gas price that
+// covers the rise of the L1 gas price until the quote expires.
+type BridgeQuote struct {
+	Deposit    *DepositEstimate    `json:"deposit"`
+	Execution  *ExecutionEstimate  `json:"execution,omitempty"`
+	Withdrawal *WithdrawalEstimate `json:"withdrawal"`
+	L1GasPrice *hexutil.Big        `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big        `json:"l2GasPrice"`
+	Total      *hexutil.Big        `json:"total"`
+	// Converted is the total in the quote currency of the node, nil when
+	// the node quotes fees only in wei
+	Converted *ConvertedFee `json:"converted,omitempty"`
+	// The unix times that the quote was made at and that it expires at
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+	ExpiresAt hexutil.Uint64 `json:"expiresAt"`
+}
+
+// NewBridgeQuote composes the estimates of a round trip into a quote that is
+// valid for validity seconds from the timestamp. The execution is nil for
+// round trips without an L2 transaction.
+func NewBridgeQuote(deposit *DepositEstimate, execution *ExecutionEstimate, withdrawal *WithdrawalEstimate, l2GasPrice *big.Int, timestamp, validity uint64) *BridgeQuote {
+	total := new(big.Int).Add(deposit.L1Fee.ToInt(), withdrawal.L1Fee.ToInt())
+	if execution != nil {
+		total.Add(total, execution.Fee.ToInt())
+	}
+	return &BridgeQuote{
+		Deposit:    deposit,
+		Execution:  execution,
+		Withdrawal: withdrawal,
+		L1GasPrice: deposit.L1GasPrice,
+		L2GasPrice: (*hexutil.Big)(new(big.Int).Set(l2GasPrice)),
+		Total:      (*hexutil.Big)(total),
+		Timestamp:  hexutil.Uint64(timestamp),
+		ExpiresAt:  hexutil.Uint64(timestamp + validity),
+	}
+}
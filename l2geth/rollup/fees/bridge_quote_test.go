@@ -0,0 +1,50 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBridgeQuote(t *testing.T) {
+	var (
+		l1GasPrice = big.NewInt(50_000_000_000)
+		l2GasPrice = big.NewInt(1_000_000_000)
+		data       = []byte{0x01, 0x02}
+	)
+	deposit := NewDepositEstimate(data, 100_000, l1GasPrice)
+	withdrawal := NewWithdrawalEstimate(data, WithdrawalProof{Nodes: 10, Size: 2000}, 0, l1GasPrice)
+	execution, err := NewExecutionEstimate(DefaultCalldataGas, data, 50_000, l1GasPrice, l2GasPrice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := EncodeTxGasLimit(data, l1GasPrice, big.NewInt(50_000), l2GasPrice)
+	if uint64(execution.GasLimit) != expected.Uint64() {
+		t.Fatalf("mismatched gas limit: got %d, expect %d", execution.GasLimit, expected)
+	}
+
+	tests := map[string]struct {
+		execution *ExecutionEstimate
+		total     *big.Int
+	}{
+		"round-trip": {
+			execution: nil,
+			total:     new(big.Int).Add(deposit.L1Fee.ToInt(), withdrawal.L1Fee.ToInt()),
+		},
+		"with-execution": {
+			execution: execution,
+			total: new(big.Int).Add(execution.Fee.ToInt(),
+				new(big.Int).Add(deposit.L1Fee.ToInt(), withdrawal.L1Fee.ToInt())),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			quote := NewBridgeQuote(deposit, tt.execution, withdrawal, l2GasPrice, 1000, 300)
+			if quote.Total.ToInt().Cmp(tt.total) != 0 {
+				t.Fatalf("mismatched total: got %d, expect %d", quote.Total.ToInt(), tt.total)
+			}
+			if quote.ExpiresAt != 1300 {
+				t.Fatalf("mismatched expiry: got %d, expect 1300", quote.ExpiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package fees
+
+import (
+	"math/big"
+	"sync"
+)
+
+// Calculator computes fees in preallocated scratch space. The fee arithmetic
+// runs for every transaction that the sequencer accepts and every block that
+// it builds, a Calculator that is reused across calls does not allocate once
+// its scratch space has grown to the size of the operands. It is not safe
+// for concurrent use.
+type Calculator struct {
+	l1GasLimit big.Int
+	l1Fee      big.Int
+	l2GasLimit big.Int
+	sum        big.Int
+	quo        big.Int
+	rem        big.Int
+}
+
+// calculators are the calculators used by the package level functions
+var calculators = sync.Pool{
+	New: func() interface{} { return new(Calculator) },
+}
+
+// EncodeTxGasLimit sets z to the `tx.gasLimit` for the L1/L2 gas prices and
+// the L2 gas limit and returns z, see the package level EncodeTxGasLimit
+func (c *Calculator) EncodeTxGasLimit(z *big.Int, data []byte, l1GasPrice, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	return c.EncodeTxGasLimitForL1Gas(z, calculateL1GasLimitU64(data, Overhead), l1GasPrice, l2GasLimit, l2GasPrice)
+}
+
+// EncodeTxGasLimitForL1Gas sets z to the `tx.gasLimit` for a transaction
+// whose L1 gas has already been computed and returns z
+func (c *Calculator) EncodeTxGasLimitForL1Gas(z *big.Int, l1GasUsed uint64, l1GasPrice, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	c.l1GasLimit.SetUint64(l1GasUsed)
+	c.l1Fee.Mul(l1GasPrice, &c.l1GasLimit)
+	return c.encodeTxGasLimit(z, &c.l1Fee, l2GasLimit, l2GasPrice)
+}
+
+// encodeTxGasLimit sets z to the `tx.gasLimit` for the L1 fee, the operands
+// may alias the scratch space of the calculator but not z
+func (c *Calculator) encodeTxGasLimit(z, l1Fee, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	c.ceilmod(&c.l2GasLimit, l2GasLimit, BigTenThousand)
+	c.sum.Mul(l2GasPrice, &c.l2GasLimit)
+	c.sum.Add(&c.sum, l1Fee)
+	// The operands are not negative, so truncated and Euclidean division
+	// agree. QuoRem reuses the remainder instead of allocating one.
+	c.sum.QuoRem(&c.sum, bigFeeScalar, &c.rem)
+	c.ceilmod(&c.sum, &c.sum, BigTenThousand)
+	c.l2GasLimit.QuoRem(&c.l2GasLimit, BigTenThousand, &c.rem)
+	return z.Add(&c.sum, &c.l2GasLimit)
+}
+
+// ceilmod sets z to a rounded up to the next multiple of b, see Ceilmod
+func (c *Calculator) ceilmod(z, a, b *big.Int) *big.Int {
+	c.quo.QuoRem(a, b, &c.rem)
+	if c.rem.Sign() == 0 {
+		return z.Set(a)
+	}
+	z.Add(a, b)
+	return z.Sub(z, &c.rem)
+}
@@ -0,0 +1,55 @@
+package fees
+
+import (
+	"math/big"
+)
+
+// CalldataGas is the gas schedule of calldata on the L1 chain that
+// transactions are settled on. The L1 fee tracks the schedule of the
+// settlement chain, which may reprice calldata in a fork.
+type CalldataGas struct {
+	Zero    uint64 `json:"zero"`
+	NonZero uint64 `json:"nonZero"`
+	// MinSize is the calldata size that smaller transactions are charged
+	// for. Every transaction adds the same overhead to a batch, so without a
+	// floor many tiny transactions cost more to submit than they pay.
+	MinSize uint64 `json:"minSize,omitempty"`
+}
+
+// DefaultCalldataGas is the calldata gas schedule of Ethereum since EIP 2028
+var DefaultCalldataGas = CalldataGas{Zero: txDataZeroGas, NonZero: txDataNonZeroGas}
+
+// L1GasUsed returns the L1 gas that a transaction with the given number of
+// zero and non-zero bytes of calldata is charged for, including the fixed
+// batch submission overhead. Calldata smaller than the minimum size is
+// charged as if it was padded with non-zero bytes.
+func (g CalldataGas) L1GasUsed(zeroes, nonZeroes uint64) uint64 {
+	return g.DataGas(zeroes, nonZeroes) + Overhead
+}
+
+// DataGas returns the L1 gas of the calldata of a transaction with the given
+// number of zero and non-zero bytes, without the batch submission overhead
+func (g CalldataGas) DataGas(zeroes, nonZeroes uint64) uint64 {
+	if size := zeroes + nonZeroes; size < g.MinSize {
+		nonZeroes += g.MinSize - size
+	}
+	return zeroes*g.Zero + nonZeroes*g.NonZero
+}
+
+// DataGasOf returns the L1 gas of the calldata, without the batch
+// submission overhead
+func (g CalldataGas) DataGasOf(data []byte) uint64 {
+	return g.DataGas(zeroesAndOnes(data))
+}
+
+// CalculateL1GasUsed is the package level CalculateL1GasUsed with the gas
+// schedule
+func (g CalldataGas) CalculateL1GasUsed(data []byte) *big.Int {
+	return new(big.Int).SetUint64(g.L1GasUsed(zeroesAndOnes(data)))
+}
+
+// EncodeTxGasLimit is the package level EncodeTxGasLimit with the gas
+// schedule
+func (g CalldataGas) EncodeTxGasLimit(data []byte, l1GasPrice, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	return EncodeTxGasLimitForL1Gas(g.L1GasUsed(zeroesAndOnes(data)), l1GasPrice, l2GasLimit, l2GasPrice)
+}
@@ -0,0 +1,153 @@
+package fees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	// ErrNoConversionRate represents the error case of converting a fee
+	// when no conversion rate oracle is configured
+	ErrNoConversionRate = errors.New("no conversion rate oracle")
+	// ErrStalePrice represents the error case of a price source whose
+	// latest price is older than the prices that are accepted
+	ErrStalePrice = errors.New("stale price")
+)
+
+// ConversionRateOracle converts fees from wei into a quote currency, such as
+// the gas token of the chain or USD. The currency is returned as a GasToken
+// whose conversion rate is the amount of the smallest denomination of the
+// currency that a wei is worth.
+type ConversionRateOracle interface {
+	ConversionRate(ctx context.Context) (*GasToken, error)
+}
+
+// StaticConversionRate is a conversion rate oracle with a fixed conversion
+// rate, such as the configured conversion rate of the gas token
+type StaticConversionRate struct {
+	Currency *GasToken
+}
+
+// ConversionRate implements ConversionRateOracle
+func (s *StaticConversionRate) ConversionRate(ctx context.Context) (*GasToken, error) {
+	if err := s.Currency.Validate(); err != nil {
+		return nil, err
+	}
+	return s.Currency, nil
+}
+
+// ConvertedFee is a fee converted into a quote currency
+type ConvertedFee struct {
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+	// Amount is the fee in the smallest denomination of the currency
+	Amount *hexutil.Big `json:"amount"`
+	// ConversionRate is the amount of the smallest denomination of the
+	// currency that a wei is worth
+	ConversionRate string `json:"conversionRate"`
+}
+
+// ConvertFee converts a fee in wei into the quote currency of the oracle. The
+// amount is rounded up like the fees of chains with a gas token.
+func ConvertFee(ctx context.Context, oracle ConversionRateOracle, wei *big.Int) (*ConvertedFee, error) {
+	if oracle == nil {
+		return nil, ErrNoConversionRate
+	}
+	currency, err := oracle.ConversionRate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	converted := &ConvertedFee{
+		Symbol:         currency.Symbol,
+		Decimals:       currency.Decimals,
+		Amount:         (*hexutil.Big)(currency.FromWei(wei)),
+		ConversionRate: "1",
+	}
+	if !currency.IsETH() {
+		converted.ConversionRate = currency.ConversionRate.Text('g', -1)
+	}
+	return converted, nil
+}
+
+// PriceSource is a source of the price of ether in a quote currency, such as
+// an on-chain price feed
+type PriceSource interface {
+	// Price returns the price of an ether in the quote currency with the
+	// number of decimals of the price, and the time of the price
+	Price(ctx context.Context) (*big.Int, uint8, time.Time, error)
+}
+
+// PriceFeedConversionRate is a conversion rate oracle that converts at the
+// price of a price source. The price is read again once it is older than the
+// ttl, and prices older than the max age are refused.
+type PriceFeedConversionRate struct {
+	source   PriceSource
+	symbol   string
+	decimals uint8
+	ttl      time.Duration
+	maxAge   time.Duration
+	now      func() time.Time
+
+	lock    sync.Mutex
+	rate    *GasToken
+	fetched time.Time
+}
+
+// NewPriceFeedConversionRate creates a conversion rate oracle into the
+// currency with the symbol and the decimals from the price source
+func NewPriceFeedConversionRate(source PriceSource, symbol string, decimals uint8, ttl, maxAge time.Duration) *PriceFeedConversionRate {
+	return &PriceFeedConversionRate{
+		source:   source,
+		symbol:   symbol,
+		decimals: decimals,
+		ttl:      ttl,
+		maxAge:   maxAge,
+		now:      time.Now,
+	}
+}
+
+// ConversionRate implements ConversionRateOracle
+func (p *PriceFeedConversionRate) ConversionRate(ctx context.Context) (*GasToken, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	if p.rate != nil && now.Sub(p.fetched) < p.ttl {
+		return p.rate, nil
+	}
+	price, decimals, updated, err := p.source.Price(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.maxAge != 0 && now.Sub(updated) > p.maxAge {
+		return nil, fmt.Errorf("%w: updated %v ago", ErrStalePrice, now.Sub(updated))
+	}
+	rate := &GasToken{
+		Symbol:         p.symbol,
+		Decimals:       p.decimals,
+		ConversionRate: conversionRateOf(price, decimals, p.decimals),
+	}
+	if err := rate.Validate(); err != nil {
+		return nil, err
+	}
+	p.rate, p.fetched = rate, now
+	return rate, nil
+}
+
+// conversionRateOf returns the amount of the smallest denomination of a
+// currency with the decimals that a wei is worth at the price of an ether
+// with the price decimals
+func conversionRateOf(price *big.Int, priceDecimals, decimals uint8) *big.Float {
+	rate := new(big.Float).SetPrec(256).SetInt(price)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	rate.Mul(rate, new(big.Float).SetInt(scale))
+	// An ether is 10^18 wei
+	scale.Exp(big.NewInt(10), big.NewInt(int64(priceDecimals)+18), nil)
+	return rate.Quo(rate, new(big.Float).SetInt(scale))
+}
@@ -0,0 +1,96 @@
+package fees
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+type testPriceSource struct {
+	price   *big.Int
+	updated time.Time
+	calls   int
+}
+
+func (s *testPriceSource) Price(ctx context.Context) (*big.Int, uint8, time.Time, error) {
+	s.calls++
+	return s.price, 8, s.updated, nil
+}
+
+func TestConvertFee(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	// ETH/USD at 2000 with 8 decimals
+	source := &testPriceSource{price: big.NewInt(2000_00000000), updated: now}
+	feed := NewPriceFeedConversionRate(source, "USDC", 6, time.Minute, time.Hour)
+	feed.now = func() time.Time { return now }
+
+	tests := map[string]struct {
+		oracle   ConversionRateOracle
+		wei      *big.Int
+		symbol   string
+		expected *big.Int
+		err      error
+	}{
+		"no-oracle": {
+			oracle: nil,
+			wei:    big.NewInt(1),
+			err:    ErrNoConversionRate,
+		},
+		"static": {
+			oracle:   &StaticConversionRate{Currency: &GasToken{Symbol: "BOBA", ConversionRate: big.NewFloat(2)}},
+			wei:      big.NewInt(1000),
+			symbol:   "BOBA",
+			expected: big.NewInt(2000),
+		},
+		// 0.001 ether is 2 USDC
+		"price-feed": {
+			oracle:   feed,
+			wei:      big.NewInt(1_000_000_000_000_000),
+			symbol:   "USDC",
+			expected: big.NewInt(2_000_000),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			converted, err := ConvertFee(context.Background(), tt.oracle, tt.wei)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if converted.Symbol != tt.symbol {
+				t.Fatalf("mismatched symbol: got %s, expect %s", converted.Symbol, tt.symbol)
+			}
+			if converted.Amount.ToInt().Cmp(tt.expected) != 0 {
+				t.Fatalf("mismatched amount: got %d, expect %d", converted.Amount.ToInt(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestPriceFeedConversionRate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	source := &testPriceSource{price: big.NewInt(2000_00000000), updated: now.Add(-30 * time.Minute)}
+	feed := NewPriceFeedConversionRate(source, "USD", 2, time.Minute, time.Hour)
+	feed.now = func() time.Time { return now }
+
+	if _, err := feed.ConversionRate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// The rate is cached for the ttl
+	feed.ConversionRate(context.Background())
+	if source.calls != 1 {
+		t.Fatalf("mismatched price reads: got %d, expect 1", source.calls)
+	}
+	now = now.Add(time.Hour)
+	if _, err := feed.ConversionRate(context.Background()); !errors.Is(err, ErrStalePrice) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrStalePrice)
+	}
+	source.price, source.updated = new(big.Int), now
+	if _, err := feed.ConversionRate(context.Background()); !errors.Is(err, ErrBadConversionRate) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrBadConversionRate)
+	}
+}
@@ -0,0 +1,220 @@
+package fees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The data availability layers that the batch submitter can post the data of
+// the transactions to
+const (
+	DABackendCalldata = "calldata"
+	DABackendBlob     = "blob"
+	DABackendExternal = "external"
+)
+
+// A blob holds 4096 field elements of 32 bytes. The batch submitter packs 31
+// bytes of data into each element so that every element stays below the
+// modulus of the curve.
+const (
+	BlobGasPerBlob = 1 << 17
+	BlobDataSize   = 4096 * 31
+)
+
+// ErrUnknownDABackend represents the error case of a data availability
+// backend that no estimator exists for
+var ErrUnknownDABackend = errors.New("unknown data availability backend")
+
+// DACost is the price in wei of making the data of transactions available.
+// The bytes of a transaction are priced at a fraction of wei, the cost of a
+// transaction is rounded up to the next wei.
+type DACost struct {
+	// Zero and NonZero are the prices of a zero and a non-zero byte of
+	// calldata in wei times the scale
+	Zero    *big.Int
+	NonZero *big.Int
+	// Scale divides the prices of the bytes, one when nil
+	Scale *big.Int
+	// Fixed is charged to every transaction for its share of the batch
+	// submission on L1, which is paid in L1 gas on every backend
+	Fixed *big.Int
+	// MinSize is the calldata size that smaller transactions are charged for
+	MinSize uint64
+}
+
+// Cost returns the price of a transaction with the given number of zero and
+// non-zero bytes of calldata. Calldata smaller than the minimum size is
+// charged as if it was padded with non-zero bytes.
+func (c *DACost) Cost(zeroes, nonZeroes uint64) *big.Int {
+	if size := zeroes + nonZeroes; size < c.MinSize {
+		nonZeroes += c.MinSize - size
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(zeroes), c.Zero)
+	cost.Add(cost, new(big.Int).Mul(new(big.Int).SetUint64(nonZeroes), c.NonZero))
+	if c.Scale != nil {
+		cost.Add(cost, new(big.Int).Sub(c.Scale, common.Big1))
+		cost.Div(cost, c.Scale)
+	}
+	return cost.Add(cost, c.Fixed)
+}
+
+// CostOf returns the price of a transaction with the calldata
+func (c *DACost) CostOf(data []byte) *big.Int {
+	return c.Cost(zeroesAndOnes(data))
+}
+
+// DACostEstimator prices the data of transactions on the data availability
+// layer that the batch submitter posts them to, so that the L1 fee tracks
+// what the batches actually cost
+type DACostEstimator interface {
+	// Backend returns the name of the data availability layer
+	Backend() string
+	// DACost returns the prices at the L1 gas price and the calldata gas
+	// schedule of the L1 chain
+	DACost(ctx context.Context, l1GasPrice *big.Int, calldataGas CalldataGas) (*DACost, error)
+}
+
+// CalldataDACost prices the data of transactions as calldata on L1, the same
+// as CalldataGas.L1GasUsed at the L1 gas price
+type CalldataDACost struct{}
+
+// Backend implements DACostEstimator
+func (CalldataDACost) Backend() string { return DABackendCalldata }
+
+// DACost implements DACostEstimator
+func (CalldataDACost) DACost(ctx context.Context, l1GasPrice *big.Int, calldataGas CalldataGas) (*DACost, error) {
+	return &DACost{
+		Zero:    new(big.Int).Mul(new(big.Int).SetUint64(calldataGas.Zero), l1GasPrice),
+		NonZero: new(big.Int).Mul(new(big.Int).SetUint64(calldataGas.NonZero), l1GasPrice),
+		Fixed:   overheadFee(l1GasPrice),
+		MinSize: calldataGas.MinSize,
+	}, nil
+}
+
+// BlobBaseFeeSource is a source of the blob base fee of the L1 chain
+type BlobBaseFeeSource interface {
+	BlobBaseFee(ctx context.Context) (*big.Int, error)
+}
+
+// BlobDACost prices the data of transactions in L1 blobs at the blob base
+// fee. Zero and non-zero bytes take the same space in a blob. Batches are
+// assumed to fill their blobs, the batch submitter waits for enough
+// transactions to do so. The blob base fee is read again once it is older
+// than the ttl.
+type BlobDACost struct {
+	source BlobBaseFeeSource
+	ttl    time.Duration
+	now    func() time.Time
+
+	lock    sync.Mutex
+	fee     *big.Int
+	fetched time.Time
+}
+
+// NewBlobDACost creates an estimator that prices blobs at the blob base fee
+// of the source
+func NewBlobDACost(source BlobBaseFeeSource, ttl time.Duration) *BlobDACost {
+	return &BlobDACost{source: source, ttl: ttl, now: time.Now}
+}
+
+// Backend implements DACostEstimator
+func (b *BlobDACost) Backend() string { return DABackendBlob }
+
+// DACost implements DACostEstimator
+func (b *BlobDACost) DACost(ctx context.Context, l1GasPrice *big.Int, calldataGas CalldataGas) (*DACost, error) {
+	fee, err := b.blobBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	price := new(big.Int).Mul(fee, big.NewInt(BlobGasPerBlob))
+	return &DACost{
+		Zero:    price,
+		NonZero: price,
+		Scale:   big.NewInt(BlobDataSize),
+		Fixed:   overheadFee(l1GasPrice),
+		MinSize: calldataGas.MinSize,
+	}, nil
+}
+
+// blobBaseFee returns the blob base fee of the source, cached for the ttl
+func (b *BlobDACost) blobBaseFee(ctx context.Context) (*big.Int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	if b.fee != nil && now.Sub(b.fetched) < b.ttl {
+		return b.fee, nil
+	}
+	fee, err := b.source.BlobBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if fee == nil || fee.Sign() < 0 {
+		return nil, fmt.Errorf("invalid blob base fee %v", fee)
+	}
+	b.fee, b.fetched = fee, now
+	return fee, nil
+}
+
+// ExternalDACost prices the data of transactions on a data availability
+// layer outside of L1 that charges a fixed price per byte in its own token.
+// The price is converted into wei at the conversion rate of the oracle,
+// which is the amount of the smallest denomination of the token that a wei
+// is worth.
+type ExternalDACost struct {
+	// CostPerByte is the price of a byte in the smallest denomination of the
+	// token of the data availability layer
+	CostPerByte *big.Int
+	Rate        ConversionRateOracle
+}
+
+// externalDAScale is the scale of the prices of the bytes of an external
+// data availability layer, which are usually a small fraction of a wei
+var externalDAScale = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// Backend implements DACostEstimator
+func (e *ExternalDACost) Backend() string { return DABackendExternal }
+
+// DACost implements DACostEstimator
+func (e *ExternalDACost) DACost(ctx context.Context, l1GasPrice *big.Int, calldataGas CalldataGas) (*DACost, error) {
+	token, err := e.Rate.ConversionRate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scaled := new(big.Int).Mul(e.CostPerByte, externalDAScale)
+	price := scaled
+	if !token.IsETH() {
+		price = quoByFloatCeil(scaled, token.ConversionRate)
+	}
+	return &DACost{
+		Zero:    price,
+		NonZero: price,
+		Scale:   externalDAScale,
+		Fixed:   overheadFee(l1GasPrice),
+		MinSize: calldataGas.MinSize,
+	}, nil
+}
+
+// quoByFloatCeil divides a big.Int by a big.Float and rounds the result up
+// to the nearest integer
+func quoByFloatCeil(num *big.Int, float *big.Float) *big.Int {
+	n := new(big.Float).SetPrec(256).SetInt(num)
+	quotient := n.Quo(n, float)
+	result, accuracy := quotient.Int(nil)
+	if accuracy == big.Below {
+		result.Add(result, common.Big1)
+	}
+	return result
+}
+
+// overheadFee returns the price of the fixed batch submission overhead of a
+// transaction at the L1 gas price
+func overheadFee(l1GasPrice *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(Overhead), l1GasPrice)
+}
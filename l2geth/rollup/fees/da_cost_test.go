@@ -0,0 +1,95 @@
+package fees
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+type testBlobBaseFee struct {
+	fee   *big.Int
+	calls int
+}
+
+func (s *testBlobBaseFee) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	s.calls++
+	return s.fee, nil
+}
+
+func TestDACost(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l1GasPrice := big.NewInt(20_000_000_000)
+	calldataGas := CalldataGas{Zero: 4, NonZero: 16, MinSize: 100}
+	data := append(bytes.Repeat([]byte{0}, 40), bytes.Repeat([]byte{1}, 200)...)
+	overhead := new(big.Int).Mul(new(big.Int).SetUint64(Overhead), l1GasPrice)
+
+	source := &testBlobBaseFee{fee: big.NewInt(1_000_000_000)}
+	blob := NewBlobDACost(source, time.Minute)
+	blob.now = func() time.Time { return now }
+
+	tests := map[string]struct {
+		estimator DACostEstimator
+		data      []byte
+		expected  *big.Int
+	}{
+		"calldata": {
+			estimator: CalldataDACost{},
+			data:      data,
+			expected:  new(big.Int).Mul(calldataGas.CalculateL1GasUsed(data), l1GasPrice),
+		},
+		"calldata-min-size": {
+			estimator: CalldataDACost{},
+			data:      []byte{1},
+			expected:  new(big.Int).Mul(calldataGas.CalculateL1GasUsed([]byte{1}), l1GasPrice),
+		},
+		// 240 bytes of a blob at 131072 blob gas per 126976 bytes, rounded up
+		"blob": {
+			estimator: blob,
+			data:      data,
+			expected:  new(big.Int).Add(big.NewInt(247741935484), overhead),
+		},
+		// 2 tokens per wei, a byte at 1000 tokens is 500 wei
+		"external": {
+			estimator: &ExternalDACost{
+				CostPerByte: big.NewInt(1000),
+				Rate:        &StaticConversionRate{Currency: &GasToken{Symbol: "TIA", ConversionRate: big.NewFloat(2)}},
+			},
+			data:     data,
+			expected: new(big.Int).Add(big.NewInt(240*500), overhead),
+		},
+		"external-eth": {
+			estimator: &ExternalDACost{CostPerByte: big.NewInt(3), Rate: &StaticConversionRate{}},
+			data:      data,
+			expected:  new(big.Int).Add(big.NewInt(240*3), overhead),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cost, err := tt.estimator.DACost(context.Background(), l1GasPrice, calldataGas)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cost.CostOf(tt.data); got.Cmp(tt.expected) != 0 {
+				t.Fatalf("mismatched cost: got %d, expect %d", got, tt.expected)
+			}
+		})
+	}
+
+	// The blob base fee is read once per ttl
+	calls := source.calls
+	if _, err := blob.DACost(context.Background(), l1GasPrice, calldataGas); err != nil {
+		t.Fatal(err)
+	}
+	if source.calls != calls {
+		t.Fatalf("mismatched blob base fee reads: got %d, expect %d", source.calls, calls)
+	}
+	now = now.Add(time.Minute)
+	if _, err := blob.DACost(context.Background(), l1GasPrice, calldataGas); err != nil {
+		t.Fatal(err)
+	}
+	if source.calls != calls+1 {
+		t.Fatalf("mismatched blob base fee reads: got %d, expect %d", source.calls, calls+1)
+	}
+}
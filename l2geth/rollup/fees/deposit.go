@@ -0,0 +1,85 @@
+package fees
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The parameters of the CanonicalTransactionChain that determine the L1 gas
+// that enqueueing a deposit costs
+const (
+	// EnqueueL2GasPrepaid is the L2 gas limit that a deposit gets without
+	// burning L1 gas
+	EnqueueL2GasPrepaid uint64 = 1_920_000
+	// L2GasDiscountDivisor is the ratio of the L2 gas limit above the
+	// prepaid gas to the L1 gas that enqueue burns for it
+	L2GasDiscountDivisor uint64 = 32
+	// enqueueExecutionGas is the L1 gas that the execution of enqueue uses
+	// besides the gas that it burns and the gas that grows with the size of
+	// the message, which is dominated by the storage of the queue element
+	enqueueExecutionGas uint64 = 60_000
+	// enqueueArgsSize is the size of the ABI encoded arguments of enqueue
+	// without the message: the selector, the target, the gas limit and the
+	// offset and length of the message. They are charged as non-zero bytes.
+	enqueueArgsSize uint64 = 4 + 4*32
+)
+
+// The L1 gas costs of the enqueue transaction, they match params.TxGas,
+// params.LogDataGas and params.Sha3WordGas
+const (
+	txGas       uint64 = 21000
+	logDataGas  uint64 = 8
+	sha3WordGas uint64 = 6
+)
+
+// DepositEstimate is the cost of a deposit from L1: the L2 gas limit that its
+// message needs and the L1 gas of the enqueue transaction that submits it
+type DepositEstimate struct {
+	L2GasLimit hexutil.Uint64 `json:"l2GasLimit"`
+	// L1GasUsed is the L1 gas of the enqueue transaction, including the
+	// gas that it burns for the L2 gas limit above the prepaid gas
+	L1GasUsed   hexutil.Uint64 `json:"l1GasUsed"`
+	L1GasBurned hexutil.Uint64 `json:"l1GasBurned"`
+	L1GasPrice  *hexutil.Big   `json:"l1GasPrice"`
+	// L1Fee is the L1 gas used at the L1 gas price, which is the total cost
+	// of the deposit as deposits do not pay an L2 fee
+	L1Fee *hexutil.Big `json:"l1Fee"`
+}
+
+// EnqueueGasBurned returns the L1 gas that enqueue burns for the L2 gas limit
+// of a deposit, the L2 gas above the prepaid gas is paid for with L1 gas at a
+// discount
+func EnqueueGasBurned(l2GasLimit uint64) uint64 {
+	if l2GasLimit <= EnqueueL2GasPrepaid {
+		return 0
+	}
+	return (l2GasLimit - EnqueueL2GasPrepaid) / L2GasDiscountDivisor
+}
+
+// EnqueueL1GasUsed returns the L1 gas of a transaction that calls enqueue of
+// the CanonicalTransactionChain with the message and the L2 gas limit. It is
+// an estimate of the transactions that call enqueue directly, deposits that
+// go through the bridges use more L1 gas for the execution of the bridges.
+func EnqueueL1GasUsed(data []byte, l2GasLimit uint64) uint64 {
+	words := (uint64(len(data)) + 31) / 32
+	// The message is padded with zero bytes to a multiple of 32 bytes
+	zeroes, nonZeroes := zeroesAndOnes(data)
+	zeroes += words*32 - uint64(len(data))
+	gas := txGas + (enqueueArgsSize+nonZeroes)*txDataNonZeroGas + zeroes*txDataZeroGas
+	gas += enqueueExecutionGas + logDataGas*uint64(len(data)) + sha3WordGas*words
+	return gas + EnqueueGasBurned(l2GasLimit)
+}
+
+// NewDepositEstimate returns the cost of a deposit of the message with the L2
+// gas limit at the L1 gas price
+func NewDepositEstimate(data []byte, l2GasLimit uint64, l1GasPrice *big.Int) *DepositEstimate {
+	l1GasUsed := EnqueueL1GasUsed(data, l2GasLimit)
+	return &DepositEstimate{
+		L2GasLimit:  hexutil.Uint64(l2GasLimit),
+		L1GasUsed:   hexutil.Uint64(l1GasUsed),
+		L1GasBurned: hexutil.Uint64(EnqueueGasBurned(l2GasLimit)),
+		L1GasPrice:  (*hexutil.Big)(new(big.Int).Set(l1GasPrice)),
+		L1Fee:       (*hexutil.Big)(new(big.Int).Mul(new(big.Int).SetUint64(l1GasUsed), l1GasPrice)),
+	}
+}
@@ -0,0 +1,59 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestEnqueueL1GasUsed(t *testing.T) {
+	tests := map[string]struct {
+		data       []byte
+		l2GasLimit uint64
+		burned     uint64
+		expected   uint64
+	}{
+		"empty": {
+			data:       nil,
+			l2GasLimit: 100_000,
+			expected:   txGas + enqueueArgsSize*txDataNonZeroGas + enqueueExecutionGas,
+		},
+		"prepaid": {
+			data:       nil,
+			l2GasLimit: EnqueueL2GasPrepaid,
+			expected:   txGas + enqueueArgsSize*txDataNonZeroGas + enqueueExecutionGas,
+		},
+		// The message is padded to a word of 31 zero bytes and 1 non-zero
+		// byte, the gas above the prepaid gas burns 100 L1 gas
+		"burn": {
+			data:       []byte{0x01, 0x00},
+			l2GasLimit: EnqueueL2GasPrepaid + 3200,
+			burned:     100,
+			expected:   txGas + (enqueueArgsSize+1)*txDataNonZeroGas + 31*txDataZeroGas + enqueueExecutionGas + 2*logDataGas + sha3WordGas + 100,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if burned := EnqueueGasBurned(tt.l2GasLimit); burned != tt.burned {
+				t.Fatalf("mismatched burned gas: got %d, expect %d", burned, tt.burned)
+			}
+			if gas := EnqueueL1GasUsed(tt.data, tt.l2GasLimit); gas != tt.expected {
+				t.Fatalf("mismatched L1 gas: got %d, expect %d", gas, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewDepositEstimate(t *testing.T) {
+	l1GasPrice := big.NewInt(50_000_000_000)
+	estimate := NewDepositEstimate([]byte{0x01}, 2_000_000, l1GasPrice)
+	if uint64(estimate.L2GasLimit) != 2_000_000 {
+		t.Fatalf("mismatched L2 gas limit: got %d", estimate.L2GasLimit)
+	}
+	if uint64(estimate.L1GasBurned) != 2500 {
+		t.Fatalf("mismatched burned gas: got %d, expect 2500", estimate.L1GasBurned)
+	}
+	expected := new(big.Int).Mul(new(big.Int).SetUint64(uint64(estimate.L1GasUsed)), l1GasPrice)
+	if estimate.L1Fee.ToInt().Cmp(expected) != 0 {
+		t.Fatalf("mismatched L1 fee: got %d, expect %d", estimate.L1Fee.ToInt(), expected)
+	}
+}
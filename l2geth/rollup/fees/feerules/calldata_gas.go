@@ -0,0 +1,26 @@
+// Package feerules applies the fork schedule of the chain config to the fee
+// arithmetic of the fees package. It is kept out of the fees package, which
+// is built for js/wasm without the node dependencies.
+package feerules
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// CalldataGasFor returns the calldata gas schedule of a formula of the L1 fee
+// of the chain config at the L1 block number
+func CalldataGasFor(config *params.ChainConfig, algorithm params.FeeAlgorithm, l1Block *big.Int) fees.CalldataGas {
+	switch algorithm {
+	case params.FeeAlgorithmLegacy:
+		return fees.DefaultCalldataGas
+	case params.FeeAlgorithmL1CalldataGas:
+		zero, nonZero := config.L1CalldataGasAt(l1Block)
+		return fees.CalldataGas{Zero: zero, NonZero: nonZero}
+	default:
+		zero, nonZero := config.L1CalldataGasAt(l1Block)
+		return fees.CalldataGas{Zero: zero, NonZero: nonZero, MinSize: config.L1MinTxSize()}
+	}
+}
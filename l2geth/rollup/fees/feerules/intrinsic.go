@@ -0,0 +1,20 @@
+package feerules
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// IntrinsicGas returns what a transaction is charged before any code runs,
+// with the rules of the L2 chain at the L2 block and the rollup rules that are
+// active at the L1 block. The L2 gas is the gas that core.IntrinsicGas
+// charges when the node executes the transaction. The L1 gas of the calldata
+// is priced with the L1 calldata gas schedule when the L1CalldataGas rule is
+// active, and padded to the minimum size when the MinTxSize rule is, as the
+// sequencer prices it.
+func IntrinsicGas(data []byte, contractCreation bool, config *params.ChainConfig, l2Block *big.Int, rules params.RollupRules, l1Block *big.Int) (fees.Intrinsic, error) {
+	calldataGas := CalldataGasFor(config, rules.FeeAlgorithm(), l1Block)
+	return fees.ComputeIntrinsic(data, contractCreation, config.IsHomestead(l2Block), config.IsIstanbul(l2Block), calldataGas)
+}
@@ -0,0 +1,45 @@
+package feerules
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestIntrinsicGas(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(420),
+		HomesteadBlock: big.NewInt(0),
+		IstanbulBlock:  big.NewInt(100),
+		L1CalldataGas:  &params.L1CalldataGasConfig{ZeroGas: 4, NonZeroGas: 8, MinTxSize: 10},
+	}
+	data := []byte{0x00, 0x01, 0x00, 0xff}
+
+	tests := map[string]struct {
+		data             []byte
+		contractCreation bool
+		block            int64
+		rules            params.RollupRules
+		expect           fees.Intrinsic
+	}{
+		"transfer":        {nil, false, 100, params.RollupRules{}, fees.Intrinsic{L2Gas: params.TxGas, L1GasUsed: fees.Overhead}},
+		"calldata":        {data, false, 100, params.RollupRules{}, fees.Intrinsic{L2Gas: params.TxGas + 2*4 + 2*16, L1GasUsed: 2*4 + 2*16 + fees.Overhead}},
+		"pre-istanbul":    {data, false, 99, params.RollupRules{}, fees.Intrinsic{L2Gas: params.TxGas + 2*4 + 2*68, L1GasUsed: 2*4 + 2*16 + fees.Overhead}},
+		"contract-create": {data, true, 100, params.RollupRules{}, fees.Intrinsic{L2Gas: params.TxGasContractCreation + 2*4 + 2*16, L1GasUsed: 2*4 + 2*16 + fees.Overhead}},
+		"l1-calldata-gas": {data, false, 100, params.RollupRules{L1CalldataGas: true}, fees.Intrinsic{L2Gas: params.TxGas + 2*4 + 2*16, L1GasUsed: 2*4 + 2*8 + fees.Overhead}},
+		"min-tx-size":     {data, false, 100, params.RollupRules{L1CalldataGas: true, MinTxSize: true}, fees.Intrinsic{L2Gas: params.TxGas + 2*4 + 2*16, L1GasUsed: 2*4 + 8*8 + fees.Overhead}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			intrinsic, err := IntrinsicGas(tt.data, tt.contractCreation, config, big.NewInt(tt.block), tt.rules, big.NewInt(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if intrinsic != tt.expect {
+				t.Fatalf("mismatched intrinsic gas: got %+v, expect %+v", intrinsic, tt.expect)
+			}
+		})
+	}
+}
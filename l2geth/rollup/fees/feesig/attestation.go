@@ -0,0 +1,127 @@
+package feesig
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ReplayedFee is the fee of a single transaction recomputed from archived
+// state and batch data
+type ReplayedFee struct {
+	TxHash common.Hash `json:"txHash"`
+	// The L2 gas used by the transaction and the L2 gas limit decoded from
+	// its gas limit
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	L2GasLimit hexutil.Uint64 `json:"l2GasLimit"`
+	// The L1 gas charged for the calldata including the overhead
+	L1GasUsed hexutil.Uint64 `json:"l1GasUsed"`
+	// The fee that the transaction paid
+	Paid *hexutil.Big `json:"paid"`
+	// The L2 fee at the L2 gas price of the parent block
+	L2Fee *hexutil.Big `json:"l2Fee"`
+	// The L1 fee at the gas price that the batch was submitted with, unset
+	// when the block is not batched yet
+	L1Fee *hexutil.Big `json:"l1Fee,omitempty"`
+}
+
+// AttestedBatch is the L1 submission of the batch that includes a block
+type AttestedBatch struct {
+	Index      hexutil.Uint64 `json:"index"`
+	L1TxHash   common.Hash    `json:"l1TxHash"`
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+}
+
+// FeeAttestation is a signed report of the fees of the transactions in a
+// block, recomputed by a node from its archived state so that the revenue of
+// the sequencer can be audited
+type FeeAttestation struct {
+	ChainID     *hexutil.Big   `json:"chainId"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	// The state root that the L2 gas price was read at
+	ParentRoot   common.Hash    `json:"parentRoot"`
+	L2GasPrice   *hexutil.Big   `json:"l2GasPrice"`
+	Batch        *AttestedBatch `json:"batch,omitempty"`
+	Transactions []*ReplayedFee `json:"transactions"`
+	Paid         *hexutil.Big   `json:"paid"`
+	L2Fee        *hexutil.Big   `json:"l2Fee"`
+	L1Fee        *hexutil.Big   `json:"l1Fee,omitempty"`
+	Signature    hexutil.Bytes  `json:"signature"`
+}
+
+// NewFeeAttestation creates an unsigned attestation for the fees, the L1 fees
+// are only summed when the block is batched
+func NewFeeAttestation(chainID *big.Int, number uint64, hash, parentRoot common.Hash, l2GasPrice *big.Int, batch *AttestedBatch, txs []*ReplayedFee) *FeeAttestation {
+	paid, l2Fee := new(big.Int), new(big.Int)
+	var l1Fee *big.Int
+	if batch != nil {
+		l1Fee = new(big.Int)
+	}
+	for _, tx := range txs {
+		paid.Add(paid, tx.Paid.ToInt())
+		l2Fee.Add(l2Fee, tx.L2Fee.ToInt())
+		if l1Fee != nil && tx.L1Fee != nil {
+			l1Fee.Add(l1Fee, tx.L1Fee.ToInt())
+		}
+	}
+	return &FeeAttestation{
+		ChainID:      (*hexutil.Big)(chainID),
+		BlockNumber:  hexutil.Uint64(number),
+		BlockHash:    hash,
+		ParentRoot:   parentRoot,
+		L2GasPrice:   (*hexutil.Big)(l2GasPrice),
+		Batch:        batch,
+		Transactions: txs,
+		Paid:         (*hexutil.Big)(paid),
+		L2Fee:        (*hexutil.Big)(l2Fee),
+		L1Fee:        (*hexutil.Big)(l1Fee),
+	}
+}
+
+// Hash returns the hash that the node signs, which commits to the JSON
+// encoding of every field of the attestation but the signature
+func (a *FeeAttestation) Hash() (common.Hash, error) {
+	unsigned := *a
+	unsigned.Signature = nil
+	encoded, err := json.Marshal(&unsigned)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte("\x19Optimism Fee Attestation:\n"), encoded), nil
+}
+
+// Sign signs the attestation with the key of the node
+func (a *FeeAttestation) Sign(key *ecdsa.PrivateKey) error {
+	hash, err := a.Hash()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		return err
+	}
+	a.Signature = sig
+	return nil
+}
+
+// Signer returns the address that signed the attestation
+func (a *FeeAttestation) Signer() (common.Address, error) {
+	if len(a.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature length %d", len(a.Signature))
+	}
+	hash, err := a.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(hash.Bytes(), a.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,54 @@
+package feesig
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestFeeAttestation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	txs := []*ReplayedFee{
+		{Paid: (*hexutil.Big)(big.NewInt(3)), L2Fee: (*hexutil.Big)(big.NewInt(2)), L1Fee: (*hexutil.Big)(big.NewInt(1))},
+		{Paid: (*hexutil.Big)(big.NewInt(5)), L2Fee: (*hexutil.Big)(big.NewInt(4)), L1Fee: (*hexutil.Big)(big.NewInt(1))},
+	}
+	batch := &AttestedBatch{L1GasPrice: (*hexutil.Big)(big.NewInt(1))}
+	attestation := NewFeeAttestation(big.NewInt(420), 1, common.Hash{0x01}, common.Hash{0x02}, big.NewInt(1), batch, txs)
+	if attestation.Paid.ToInt().Int64() != 8 || attestation.L2Fee.ToInt().Int64() != 6 || attestation.L1Fee.ToInt().Int64() != 2 {
+		t.Fatalf("mismatched totals: %+v", attestation)
+	}
+	if err := attestation.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The attestation is verified after a round trip through JSON
+	encoded, err := json.Marshal(attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded FeeAttestation
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := decoded.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("mismatched signer: got %s", signer.Hex())
+	}
+	decoded.Paid = (*hexutil.Big)(big.NewInt(9))
+	if signer, _ := decoded.Signer(); signer == crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("tampered attestation recovered the signer")
+	}
+
+	// Blocks that are not batched have no L1 fee
+	unbatched := NewFeeAttestation(big.NewInt(420), 1, common.Hash{}, common.Hash{}, big.NewInt(1), nil, txs)
+	if unbatched.L1Fee != nil {
+		t.Fatalf("unexpected l1 fee: %v", unbatched.L1Fee)
+	}
+}
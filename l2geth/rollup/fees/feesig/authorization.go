@@ -0,0 +1,161 @@
+package feesig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+var (
+	// ErrInvalidAuthorization represents the error case of a transfer
+	// authorization that cannot pay the fee of the transaction that it is
+	// submitted with
+	ErrInvalidAuthorization = errors.New("invalid transfer authorization")
+	// ErrAuthorizationTooLow represents the error case of a transfer
+	// authorization whose value is less than the fee in the token
+	ErrAuthorizationTooLow = errors.New("transfer authorization too low")
+)
+
+var (
+	// transferWithAuthorizationTypeHash is the EIP-712 type hash of the
+	// authorizations of EIP-3009
+	transferWithAuthorizationTypeHash = crypto.Keccak256Hash([]byte("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)"))
+	// eip712DomainTypeHash is the EIP-712 type hash of the domain of the
+	// tokens
+	eip712DomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	// transferWithAuthorizationSelector is the selector of the method of
+	// EIP-3009 tokens that executes an authorization
+	transferWithAuthorizationSelector = crypto.Keccak256([]byte("transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)"))[:4]
+)
+
+// FeeToken is a token that implements EIP-3009 and that the sequencer accepts
+// fees in. The name and the version are those of the EIP-712 domain of the
+// token.
+type FeeToken struct {
+	fees.GasToken
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Validate returns an error if the fee token is misconfigured
+func (t *FeeToken) Validate() error {
+	if t.IsETH() {
+		return fmt.Errorf("%w: fee token %s has no conversion rate", fees.ErrBadConversionRate, t.Symbol)
+	}
+	return t.GasToken.Validate()
+}
+
+// DomainSeparator returns the EIP-712 domain separator of the token at the
+// address on the chain
+func (t *FeeToken) DomainSeparator(chainID *big.Int, token common.Address) common.Hash {
+	return crypto.Keccak256Hash(
+		eip712DomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte(t.Name)),
+		crypto.Keccak256([]byte(t.Version)),
+		common.BigToHash(chainID).Bytes(),
+		common.LeftPadBytes(token.Bytes(), 32),
+	)
+}
+
+// TransferAuthorization is an EIP-3009 authorization of a transfer of a fee
+// token, signed by the holder of the tokens. A transaction that is submitted
+// with an authorization to the fee collector of the sequencer pays its fee in
+// the token instead of ETH.
+type TransferAuthorization struct {
+	Token       common.Address `json:"token"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Value       *hexutil.Big   `json:"value"`
+	ValidAfter  hexutil.Uint64 `json:"validAfter"`
+	ValidBefore hexutil.Uint64 `json:"validBefore"`
+	Nonce       common.Hash    `json:"nonce"`
+	// Signature is the 65 byte [R || S || V] signature of the holder, V may
+	// be 0 or 1 as well as 27 or 28
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Hash returns the EIP-712 hash of the authorization that the holder signs
+func (a *TransferAuthorization) Hash(domainSeparator common.Hash) common.Hash {
+	structHash := crypto.Keccak256(
+		transferWithAuthorizationTypeHash.Bytes(),
+		common.LeftPadBytes(a.From.Bytes(), 32),
+		common.LeftPadBytes(a.To.Bytes(), 32),
+		common.BigToHash(a.Value.ToInt()).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(uint64(a.ValidAfter))).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(uint64(a.ValidBefore))).Bytes(),
+		a.Nonce.Bytes(),
+	)
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domainSeparator.Bytes(), structHash)
+}
+
+// Signer returns the address that signed the authorization
+func (a *TransferAuthorization) Signer(domainSeparator common.Hash) (common.Address, error) {
+	if a.Value == nil {
+		return common.Address{}, fmt.Errorf("%w: missing value", ErrInvalidAuthorization)
+	}
+	if len(a.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: signature length %d", ErrInvalidAuthorization, len(a.Signature))
+	}
+	sig := common.CopyBytes(a.Signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(a.Hash(domainSeparator).Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidAuthorization, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// ValidAt returns an error if the authorization cannot be executed at the
+// unix time
+func (a *TransferAuthorization) ValidAt(time uint64) error {
+	if time <= uint64(a.ValidAfter) {
+		return fmt.Errorf("%w: valid after %d", ErrInvalidAuthorization, uint64(a.ValidAfter))
+	}
+	if time >= uint64(a.ValidBefore) {
+		return fmt.Errorf("%w: expired at %d", ErrInvalidAuthorization, uint64(a.ValidBefore))
+	}
+	return nil
+}
+
+// Calldata returns the calldata of the call to the token that executes the
+// authorization, whose signature must have been checked with Signer
+func (a *TransferAuthorization) Calldata() []byte {
+	v := a.Signature[crypto.RecoveryIDOffset]
+	if v < 27 {
+		v += 27
+	}
+	data := make([]byte, 0, 4+9*32)
+	data = append(data, transferWithAuthorizationSelector...)
+	data = append(data, common.LeftPadBytes(a.From.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(a.To.Bytes(), 32)...)
+	data = append(data, common.BigToHash(a.Value.ToInt()).Bytes()...)
+	data = append(data, common.BigToHash(new(big.Int).SetUint64(uint64(a.ValidAfter))).Bytes()...)
+	data = append(data, common.BigToHash(new(big.Int).SetUint64(uint64(a.ValidBefore))).Bytes()...)
+	data = append(data, a.Nonce.Bytes()...)
+	data = append(data, common.LeftPadBytes([]byte{v}, 32)...)
+	data = append(data, a.Signature[:32]...)
+	return append(data, a.Signature[32:64]...)
+}
+
+type transferAuthorizationKey struct{}
+
+// WithTransferAuthorization returns a context that carries the transfer
+// authorization that a transaction pays its fee with
+func WithTransferAuthorization(ctx context.Context, auth *TransferAuthorization) context.Context {
+	return context.WithValue(ctx, transferAuthorizationKey{}, auth)
+}
+
+// TransferAuthorizationFromContext returns the transfer authorization that a
+// transaction pays its fee with, nil when it pays its fee in ETH
+func TransferAuthorizationFromContext(ctx context.Context) *TransferAuthorization {
+	auth, _ := ctx.Value(transferAuthorizationKey{}).(*TransferAuthorization)
+	return auth
+}
@@ -0,0 +1,71 @@
+package feesig
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTransferAuthorization(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	token := &FeeToken{Name: "USD Coin", Version: "2"}
+	domain := token.DomainSeparator(big.NewInt(10), common.Address{0x01})
+	auth := &TransferAuthorization{
+		From:        crypto.PubkeyToAddress(key.PublicKey),
+		To:          common.Address{0x02},
+		Value:       (*hexutil.Big)(big.NewInt(1000)),
+		ValidAfter:  10,
+		ValidBefore: 20,
+		Nonce:       common.Hash{0x03},
+	}
+	sig, err := crypto.Sign(auth.Hash(domain).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wallets sign with a V of 27 or 28
+	sig[crypto.RecoveryIDOffset] += 27
+	auth.Signature = sig
+
+	signer, err := auth.Signer(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != auth.From {
+		t.Fatalf("mismatched signer: got %s, expect %s", signer.Hex(), auth.From.Hex())
+	}
+	if signer, _ := auth.Signer(token.DomainSeparator(big.NewInt(11), common.Address{0x01})); signer == auth.From {
+		t.Fatal("signature valid on another chain")
+	}
+
+	data := auth.Calldata()
+	if len(data) != 4+9*32 {
+		t.Fatalf("mismatched calldata size: got %d, expect %d", len(data), 4+9*32)
+	}
+	if !bytes.Equal(data[:4], common.FromHex("0xe3ee160e")) {
+		t.Fatalf("mismatched selector: got %x", data[:4])
+	}
+	if v := data[4+7*32-1]; v != sig[crypto.RecoveryIDOffset] {
+		t.Fatalf("mismatched v: got %d, expect %d", v, sig[crypto.RecoveryIDOffset])
+	}
+
+	tests := map[string]struct {
+		time uint64
+		err  error
+	}{
+		"before": {10, ErrInvalidAuthorization},
+		"valid":  {15, nil},
+		"after":  {20, ErrInvalidAuthorization},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := auth.ValidAt(tt.time); !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package feesig holds the fee messages that the sequencer and the nodes
+// sign: the fee quotes of the sequencer, the fee attestations of the nodes
+// and the EIP-3009 authorizations that pay fees in tokens. They are kept out
+// of the fees package, which is built for js/wasm without the node
+// dependencies.
+package feesig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrInvalidFeeQuote represents the error case of a fee quote that was
+	// not signed by the sequencer or that was issued for another sender
+	ErrInvalidFeeQuote = errors.New("invalid fee quote")
+	// ErrFeeQuoteExpired represents the error case of a fee quote that is
+	// used after the block that it is valid until
+	ErrFeeQuoteExpired = errors.New("fee quote expired")
+	// ErrFeeQuoteVolumeExhausted represents the error case of a fee quote
+	// that cannot be honored because the sequencer has already accepted as
+	// many fees at quoted gas prices as it is willing to
+	ErrFeeQuoteVolumeExhausted = errors.New("fee quote volume exhausted")
+)
+
+// FeeQuote is a commitment of the sequencer to accept the transactions of the
+// sender at the quoted gas prices until a block, regardless of how the gas
+// prices change in the meantime
+type FeeQuote struct {
+	ChainID    *hexutil.Big   `json:"chainId"`
+	Sender     common.Address `json:"sender"`
+	L1GasPrice *hexutil.Big   `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big   `json:"l2GasPrice"`
+	// The last L2 block that transactions can be included in at the quoted
+	// gas prices
+	ValidUntil hexutil.Uint64 `json:"validUntil"`
+	Signature  hexutil.Bytes  `json:"signature"`
+}
+
+// Hash returns the hash that the sequencer signs, which commits to every
+// field of the quote but the signature
+func (q *FeeQuote) Hash() common.Hash {
+	return crypto.Keccak256Hash(
+		[]byte("\x19Optimism Fee Quote:\n"),
+		common.BigToHash(q.ChainID.ToInt()).Bytes(),
+		q.Sender.Bytes(),
+		common.BigToHash(q.L1GasPrice.ToInt()).Bytes(),
+		common.BigToHash(q.L2GasPrice.ToInt()).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(uint64(q.ValidUntil))).Bytes(),
+	)
+}
+
+// Sign signs the quote with the key of the sequencer
+func (q *FeeQuote) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(q.Hash().Bytes(), key)
+	if err != nil {
+		return err
+	}
+	q.Signature = sig
+	return nil
+}
+
+// Signer returns the address that signed the quote
+func (q *FeeQuote) Signer() (common.Address, error) {
+	if q.ChainID == nil || q.L1GasPrice == nil || q.L2GasPrice == nil {
+		return common.Address{}, fmt.Errorf("%w: missing fields", ErrInvalidFeeQuote)
+	}
+	if len(q.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: signature length %d", ErrInvalidFeeQuote, len(q.Signature))
+	}
+	pub, err := crypto.SigToPub(q.Hash().Bytes(), q.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidFeeQuote, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// FeeQuoteConsumption describes the transactions that the sequencer accepted
+// with a fee quote
+type FeeQuoteConsumption struct {
+	Transactions hexutil.Uint64 `json:"transactions"`
+	// The sum of the fees paid by the transactions
+	Volume *hexutil.Big `json:"volume"`
+}
+
+type feeQuoteKey struct{}
+
+// WithFeeQuote returns a context that carries the fee quote that a
+// transaction is submitted with
+func WithFeeQuote(ctx context.Context, quote *FeeQuote) context.Context {
+	return context.WithValue(ctx, feeQuoteKey{}, quote)
+}
+
+// FeeQuoteFromContext returns the fee quote that a transaction is submitted
+// with, nil when it is submitted without a quote
+func FeeQuoteFromContext(ctx context.Context) *FeeQuote {
+	quote, _ := ctx.Value(feeQuoteKey{}).(*FeeQuote)
+	return quote
+}
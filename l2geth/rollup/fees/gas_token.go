@@ -0,0 +1,78 @@
+package fees
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrBadConversionRate represents the error case of a gas token configured
+// with a conversion rate that is not positive
+var ErrBadConversionRate = errors.New("bad gas token conversion rate")
+
+// GasToken describes the native token that fees are paid in. A nil GasToken
+// or one without a conversion rate represents ETH. Chains that pay for gas in
+// their own token still pay for L1 data availability in ETH, so the L1 portion
+// of the fee must be converted into the native token before it is charged.
+type GasToken struct {
+	// Symbol is the ticker of the native token, used for display only
+	Symbol string `json:"symbol"`
+	// Decimals is the number of decimals of the native token, used for
+	// display only
+	Decimals uint8 `json:"decimals"`
+	// ConversionRate is the amount of the smallest denomination of the native
+	// token that is equal in value to a single wei
+	ConversionRate *big.Float `json:"conversionRate"`
+}
+
+// IsETH returns true when fees are paid in ETH and no conversion is required
+func (g *GasToken) IsETH() bool {
+	return g == nil || g.ConversionRate == nil
+}
+
+// Validate returns an error if the gas token is misconfigured
+func (g *GasToken) Validate() error {
+	if g.IsETH() {
+		return nil
+	}
+	if g.ConversionRate.Sign() != 1 {
+		return fmt.Errorf("%w: %s", ErrBadConversionRate, g.ConversionRate.String())
+	}
+	return nil
+}
+
+// FromWei converts an amount denominated in wei into the smallest
+// denomination of the native token. The result is rounded up so that the
+// sequencer is never underpaid due to precision loss.
+func (g *GasToken) FromWei(wei *big.Int) *big.Int {
+	if g.IsETH() {
+		return new(big.Int).Set(wei)
+	}
+	return mulByFloatCeil(wei, g.ConversionRate)
+}
+
+// ToWei converts an amount denominated in the smallest denomination of the
+// native token into wei. The result is rounded down.
+func (g *GasToken) ToWei(amount *big.Int) *big.Int {
+	if g.IsETH() {
+		return new(big.Int).Set(amount)
+	}
+	n := new(big.Float).SetInt(amount)
+	quotient := n.Quo(n, g.ConversionRate)
+	result, _ := quotient.Int(nil)
+	return result
+}
+
+// mulByFloatCeil multiplies a big.Int by a big.Float and rounds the result up
+// to the nearest integer without going through a float64
+func mulByFloatCeil(num *big.Int, float *big.Float) *big.Int {
+	n := new(big.Float).SetInt(num)
+	product := n.Mul(n, float)
+	result, accuracy := product.Int(nil)
+	if accuracy == big.Below {
+		result.Add(result, common.Big1)
+	}
+	return result
+}
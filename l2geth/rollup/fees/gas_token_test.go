@@ -0,0 +1,54 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+var gasTokenTests = map[string]struct {
+	gasToken *GasToken
+	wei      *big.Int
+	expect   *big.Int
+}{
+	"nil":          {nil, big.NewInt(100), big.NewInt(100)},
+	"no-rate":      {&GasToken{Symbol: "ETH"}, big.NewInt(100), big.NewInt(100)},
+	"whole":        {&GasToken{ConversionRate: big.NewFloat(2000)}, big.NewInt(3), big.NewInt(6000)},
+	"fractional":   {&GasToken{ConversionRate: big.NewFloat(0.5)}, big.NewInt(3), big.NewInt(2)},
+	"fraction-one": {&GasToken{ConversionRate: big.NewFloat(0.001)}, big.NewInt(1), big.NewInt(1)},
+	"zero":         {&GasToken{ConversionRate: big.NewFloat(0.5)}, big.NewInt(0), big.NewInt(0)},
+}
+
+func TestGasTokenFromWei(t *testing.T) {
+	for name, tt := range gasTokenTests {
+		t.Run(name, func(t *testing.T) {
+			got := tt.gasToken.FromWei(tt.wei)
+			if got.Cmp(tt.expect) != 0 {
+				t.Fatalf("Unexpected conversion: got %d, expected %d", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestGasTokenToWei(t *testing.T) {
+	gasToken := &GasToken{ConversionRate: big.NewFloat(2000)}
+	got := gasToken.ToWei(big.NewInt(6001))
+	if got.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("Unexpected conversion: got %d, expected %d", got, 3)
+	}
+}
+
+func TestGasTokenValidate(t *testing.T) {
+	var gasToken *GasToken
+	if err := gasToken.Validate(); err != nil {
+		t.Fatalf("Nil gas token should be valid: %v", err)
+	}
+	gasToken = &GasToken{ConversionRate: big.NewFloat(0)}
+	if err := gasToken.Validate(); !errors.Is(err, ErrBadConversionRate) {
+		t.Fatalf("Unexpected error: got %v, expected %v", err, ErrBadConversionRate)
+	}
+	gasToken = &GasToken{ConversionRate: big.NewFloat(-1)}
+	if err := gasToken.Validate(); !errors.Is(err, ErrBadConversionRate) {
+		t.Fatalf("Unexpected error: got %v, expected %v", err, ErrBadConversionRate)
+	}
+}
@@ -0,0 +1,48 @@
+package fees
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// HistoricalL1Fee is the L1 fee of a transaction recomputed with the state
+// of the gas price oracle at the block that it was included in
+type HistoricalL1Fee struct {
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// The L1 gas charged for the calldata including the overhead
+	L1GasUsed hexutil.Uint64 `json:"l1GasUsed"`
+	// The L2 gas price of the gas price oracle when the fee was checked
+	L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	// The L1 fee that is encoded in the gas limit at the L2 gas price, up
+	// to the rounding of the gas limit
+	L1Fee *hexutil.Big `json:"l1Fee"`
+	// The L1 gas price that the L1 fee implies for the L1 gas used
+	L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+}
+
+// NewHistoricalL1Fee recovers the L1 fee from the gas limit and the gas price
+// of a transaction at the L2 gas price that its fee was checked with. The L1
+// fee is zero for transactions that did not pay a fee.
+func NewHistoricalL1Fee(txHash common.Hash, number uint64, gasLimit uint64, gasPrice *big.Int, l1GasUsed uint64, l2GasPrice *big.Int) *HistoricalL1Fee {
+	l1Fee, l1GasPrice := new(big.Int), new(big.Int)
+	if gasPrice.Sign() != 0 && l2GasPrice.Sign() != 0 {
+		l1Fee = MaxChargedL1Fee(gasLimit, l2GasPrice)
+		if l1Fee.Sign() < 0 {
+			l1Fee.SetUint64(0)
+		}
+		if l1GasUsed != 0 {
+			l1GasPrice.Div(l1Fee, new(big.Int).SetUint64(l1GasUsed))
+		}
+	}
+	return &HistoricalL1Fee{
+		TxHash:      txHash,
+		BlockNumber: hexutil.Uint64(number),
+		L1GasUsed:   hexutil.Uint64(l1GasUsed),
+		L2GasPrice:  (*hexutil.Big)(l2GasPrice),
+		L1Fee:       (*hexutil.Big)(l1Fee),
+		L1GasPrice:  (*hexutil.Big)(l1GasPrice),
+	}
+}
@@ -0,0 +1,50 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestNewHistoricalL1Fee(t *testing.T) {
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	l1GasUsed := CalculateL1GasUsed(data)
+
+	tests := map[string]struct {
+		l1GasPrice *big.Int
+		l2GasPrice *big.Int
+		gasPrice   *big.Int
+	}{
+		"low-l1-gas-price":  {big.NewInt(params.GWei), big.NewInt(params.GWei / 1000), BigTxGasPrice},
+		"high-l1-gas-price": {big.NewInt(200 * params.GWei), big.NewInt(params.GWei), BigTxGasPrice},
+		"no-fee":            {big.NewInt(params.GWei), big.NewInt(params.GWei), common.Big0},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gasLimit := EncodeTxGasLimit(data, tt.l1GasPrice, big.NewInt(100_000), tt.l2GasPrice)
+			fee := NewHistoricalL1Fee(common.Hash{}, 1, gasLimit.Uint64(), tt.gasPrice, l1GasUsed.Uint64(), tt.l2GasPrice)
+			if tt.gasPrice.Sign() == 0 {
+				if fee.L1Fee.ToInt().Sign() != 0 || fee.L1GasPrice.ToInt().Sign() != 0 {
+					t.Fatalf("fee without gas price: %d", fee.L1Fee.ToInt())
+				}
+				return
+			}
+			// The recovered L1 fee is at least the L1 fee and off by at
+			// most the rounding of the gas limit
+			l1Fee := new(big.Int).Mul(l1GasUsed, tt.l1GasPrice)
+			diff := new(big.Int).Sub(fee.L1Fee.ToInt(), l1Fee)
+			if diff.Sign() < 0 || diff.Cmp(new(big.Int).Mul(BigTenThousand, bigFeeScalar)) > 0 {
+				t.Fatalf("l1 fee mismatch: have %d, want %d", fee.L1Fee.ToInt(), l1Fee)
+			}
+			if fee.L1GasPrice.ToInt().Cmp(tt.l1GasPrice) < 0 {
+				t.Fatalf("l1 gas price too low: have %d, want %d", fee.L1GasPrice.ToInt(), tt.l1GasPrice)
+			}
+		})
+	}
+}
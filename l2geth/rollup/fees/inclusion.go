@@ -0,0 +1,94 @@
+package fees
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// InclusionHint is the fee guidance for a transaction that must be applied
+// by the sequencer before a deadline
+type InclusionHint struct {
+	// Deadline is the number of seconds that the hint covers, converted from
+	// blocks at the rate at which the sequencer applies transactions when
+	// the deadline is given in blocks
+	Deadline hexutil.Uint64 `json:"deadline"`
+	// QueueDepth is the number of transactions that wait to be applied
+	// ahead of a new transaction
+	QueueDepth hexutil.Uint64 `json:"queueDepth"`
+	// ExpectedWait is the number of seconds that a new transaction is
+	// expected to wait before it is applied
+	ExpectedWait hexutil.Uint64 `json:"expectedWait"`
+	// Express is set when the transaction must pay the premium of the
+	// express lane to meet the deadline
+	Express bool `json:"express"`
+	// FeeMultiplier is the multiple of the expected fee to pay
+	FeeMultiplier float64 `json:"feeMultiplier"`
+	// Feasible is false when the deadline cannot be met even in the express
+	// lane
+	Feasible   bool         `json:"feasible"`
+	L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	// Confidence is the share of recent deadline windows in which the L1 gas
+	// price would have covered the rise of the L1 gas price, 0 when there is
+	// not enough history to estimate it
+	Confidence float64 `json:"confidence"`
+	// Correction is the factor that the projected L1 gas price was scaled by
+	// to cover the L1 gas prices that past hints underestimated
+	Correction float64 `json:"correction"`
+}
+
+// InclusionAccuracy is the accuracy of the inclusion hints whose deadline
+// has passed. A hint is covered when its L1 gas price was at least the
+// highest L1 gas price observed before its deadline.
+type InclusionAccuracy struct {
+	Hints   uint64 `json:"hints"`
+	Settled uint64 `json:"settled"`
+	Covered uint64 `json:"covered"`
+	// MeanRatio is the mean of the highest L1 gas price observed before the
+	// deadline over the projected L1 gas price, before the correction
+	MeanRatio  float64 `json:"meanRatio"`
+	Correction float64 `json:"correction"`
+}
+
+// InclusionL1GasPrice returns the L1 gas price projected from the trend of
+// the L1 gas price over the horizon, and the L1 gas price to encode the fee
+// of a transaction with so that it is still accepted at the end of the
+// horizon, which is the projection scaled by the correction. The confidence
+// is the share of the recent windows of the horizon in which the price would
+// have covered the rise of the L1 gas price.
+func InclusionL1GasPrice(samples []L1GasPriceSample, horizon uint64, correction float64) (projected, price *big.Int, confidence float64, err error) {
+	projected, err = ProjectL1GasPrice(samples, horizon)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	price = new(big.Int).Set(projected)
+	if correction > 1 {
+		scaled := new(big.Float).SetInt(projected)
+		scaled.Mul(scaled, big.NewFloat(correction))
+		price, _ = scaled.Int(nil)
+		price.Add(price, big.NewInt(1))
+	}
+	latest := samples[len(samples)-1].Price
+	confidence = coveredShare(l1GasPriceRises(samples, horizon), latest, price)
+	return projected, price, confidence, nil
+}
+
+// InclusionCorrection returns the factor to scale projected L1 gas prices by
+// so that the quantile of the ratios of the observed over the projected L1
+// gas prices would have been covered, at least 1
+func InclusionCorrection(ratios []float64, quantile float64) float64 {
+	if len(ratios) == 0 {
+		return 1
+	}
+	sorted := make([]float64, len(ratios))
+	copy(sorted, ratios)
+	sort.Float64s(sorted)
+	correction := sorted[int(math.Ceil(quantile*float64(len(sorted))))-1]
+	if correction < 1 {
+		return 1
+	}
+	return correction
+}
@@ -0,0 +1,50 @@
+package fees
+
+import (
+	"testing"
+)
+
+func TestInclusionL1GasPrice(t *testing.T) {
+	samples := l1GasPriceSamples(100, 100, 100)
+	tests := map[string]struct {
+		correction float64
+		projected  int64
+		price      int64
+	}{
+		"uncorrected": {1, 100, 100},
+		"corrected":   {1.5, 100, 151},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			projected, price, _, err := InclusionL1GasPrice(samples, 60, tt.correction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if projected.Int64() != tt.projected {
+				t.Fatalf("mismatched projected L1 gas price: got %d, expect %d", projected, tt.projected)
+			}
+			if price.Int64() != tt.price {
+				t.Fatalf("mismatched L1 gas price: got %d, expect %d", price, tt.price)
+			}
+		})
+	}
+}
+
+func TestInclusionCorrection(t *testing.T) {
+	tests := map[string]struct {
+		ratios     []float64
+		quantile   float64
+		correction float64
+	}{
+		"none":        {nil, 0.95, 1},
+		"overpriced":  {[]float64{0.5, 0.8, 0.9}, 0.95, 1},
+		"underpriced": {[]float64{1, 1.2, 1.1, 1.3}, 0.75, 1.2},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if correction := InclusionCorrection(tt.ratios, tt.quantile); correction != tt.correction {
+				t.Fatalf("mismatched correction: got %f, expect %f", correction, tt.correction)
+			}
+		})
+	}
+}
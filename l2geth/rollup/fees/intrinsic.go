@@ -0,0 +1,91 @@
+package fees
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// ErrIntrinsicGasOverflow represents the error case of calldata whose
+// intrinsic gas does not fit in a uint64
+var ErrIntrinsicGasOverflow = errors.New("intrinsic gas overflow")
+
+// The intrinsic gas costs match params.TxGasContractCreation and
+// params.TxDataNonZeroGasFrontier. Like the calldata gas costs, they are not
+// imported so that the fee arithmetic can be built for js/wasm.
+const (
+	txGasContractCreation    uint64 = 53000
+	txDataNonZeroGasFrontier uint64 = 68
+)
+
+// ComputeIntrinsicGas returns the intrinsic gas of a transaction with the
+// calldata priced as of EIP 2028 or before it. The node charges the same
+// gas when it executes the transaction, core.IntrinsicGas is computed with
+// it as well.
+func ComputeIntrinsicGas(data []byte, contractCreation, isHomestead, isEIP2028 bool) (uint64, error) {
+	// Set the starting gas for the raw transaction
+	gas := txGas
+	if contractCreation && isHomestead {
+		gas = txGasContractCreation
+	}
+	if len(data) == 0 {
+		return gas, nil
+	}
+	// Zero and non-zero bytes are priced differently
+	zeroes, nonZeroes := zeroesAndOnes(data)
+	nonZeroGas := txDataNonZeroGasFrontier
+	if isEIP2028 {
+		nonZeroGas = txDataNonZeroGas
+	}
+	// Make sure we don't exceed uint64 for all data combinations
+	if (math.MaxUint64-gas)/nonZeroGas < nonZeroes {
+		return 0, ErrIntrinsicGasOverflow
+	}
+	gas += nonZeroes * nonZeroGas
+	if (math.MaxUint64-gas)/txDataZeroGas < zeroes {
+		return 0, ErrIntrinsicGasOverflow
+	}
+	return gas + zeroes*txDataZeroGas, nil
+}
+
+// Intrinsic is what a transaction is charged before any code runs: the
+// intrinsic gas of its execution on L2 and the L1 gas of its calldata
+type Intrinsic struct {
+	L2Gas uint64
+	// L1GasUsed includes the batch submission overhead
+	L1GasUsed uint64
+}
+
+// ComputeIntrinsic returns the intrinsic gas of a transaction and the L1 gas
+// that its calldata is charged with the calldata gas schedule, which carries
+// the rollup rules that reprice calldata and set a minimum size
+func ComputeIntrinsic(data []byte, contractCreation, isHomestead, isEIP2028 bool, calldataGas CalldataGas) (Intrinsic, error) {
+	l2Gas, err := ComputeIntrinsicGas(data, contractCreation, isHomestead, isEIP2028)
+	if err != nil {
+		return Intrinsic{}, err
+	}
+	return Intrinsic{L2Gas: l2Gas, L1GasUsed: calldataGas.L1GasUsed(zeroesAndOnes(data))}, nil
+}
+
+// MinGasLimit returns the smallest `tx.gasLimit` that the sequencer accepts
+// for the transaction at the gas prices: its L1 fee and an L2 gas limit that
+// covers the intrinsic gas and the minimum L2 gas limit of the sequencer
+func (i Intrinsic) MinGasLimit(l1GasPrice, l2GasPrice *big.Int, minL2GasLimit uint64) *big.Int {
+	l2GasLimit := new(big.Int).SetUint64(MinL2GasLimit(i.L2Gas, minL2GasLimit))
+	return EncodeTxGasLimitForL1Gas(i.L1GasUsed, l1GasPrice, l2GasLimit, l2GasPrice)
+}
+
+// MinL2GasLimit returns the smallest L2 gas limit that covers the intrinsic
+// gas and the minimum L2 gas limit of the sequencer. The L2 gas limit is
+// encoded in `tx.gasLimit` in multiples of ten thousand, so the result is
+// rounded up to one.
+func MinL2GasLimit(intrinsicGas, minL2GasLimit uint64) uint64 {
+	limit := intrinsicGas
+	if minL2GasLimit > limit {
+		limit = minL2GasLimit
+	}
+	if rem := limit % tenThousand; rem != 0 {
+		limit += tenThousand - rem
+	}
+	return limit
+}
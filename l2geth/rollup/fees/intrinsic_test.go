@@ -0,0 +1,75 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestIntrinsicGasCosts(t *testing.T) {
+	if txGas != params.TxGas || txGasContractCreation != params.TxGasContractCreation || txDataNonZeroGasFrontier != params.TxDataNonZeroGasFrontier {
+		t.Fatal("intrinsic gas costs do not match the protocol params")
+	}
+}
+
+func TestComputeIntrinsicGas(t *testing.T) {
+	data := []byte{0x00, 0x01, 0x00, 0xff}
+
+	tests := map[string]struct {
+		data                        []byte
+		contractCreation, isEIP2028 bool
+		expect                      uint64
+	}{
+		"transfer":        {nil, false, true, params.TxGas},
+		"calldata":        {data, false, true, params.TxGas + 2*4 + 2*16},
+		"pre-istanbul":    {data, false, false, params.TxGas + 2*4 + 2*68},
+		"contract-create": {data, true, true, params.TxGasContractCreation + 2*4 + 2*16},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gas, err := ComputeIntrinsicGas(tt.data, tt.contractCreation, true, tt.isEIP2028)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gas != tt.expect {
+				t.Fatalf("mismatched intrinsic gas: got %d, expect %d", gas, tt.expect)
+			}
+		})
+	}
+}
+
+func TestMinL2GasLimit(t *testing.T) {
+	tests := map[string]struct {
+		intrinsicGas, minL2GasLimit, expect uint64
+	}{
+		"intrinsic":  {21_000, 0, 30_000},
+		"min":        {21_000, 50_000, 50_000},
+		"rounded":    {21_000, 50_001, 60_000},
+		"multiple":   {40_000, 0, 40_000},
+		"no-minimum": {0, 0, 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if limit := MinL2GasLimit(tt.intrinsicGas, tt.minL2GasLimit); limit != tt.expect {
+				t.Fatalf("mismatched min L2 gas limit: got %d, expect %d", limit, tt.expect)
+			}
+		})
+	}
+}
+
+func TestIntrinsicMinGasLimit(t *testing.T) {
+	intrinsic, err := ComputeIntrinsic([]byte{0x00, 0x01}, false, true, true, DefaultCalldataGas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l1GasPrice, l2GasPrice := big.NewInt(params.GWei), big.NewInt(params.GWei)
+	limit := intrinsic.MinGasLimit(l1GasPrice, l2GasPrice, 50_000)
+	expect := EncodeTxGasLimitForL1Gas(4+16+Overhead, l1GasPrice, big.NewInt(50_000), l2GasPrice)
+	if limit.Cmp(expect) != 0 {
+		t.Fatalf("mismatched min gas limit: got %d, expect %d", limit, expect)
+	}
+	if l2GasLimit := DecodeL2GasLimit(limit); l2GasLimit.Uint64() < intrinsic.L2Gas {
+		t.Fatalf("L2 gas limit %d below the intrinsic gas %d", l2GasLimit, intrinsic.L2Gas)
+	}
+}
@@ -0,0 +1,88 @@
+package fees
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// invalidParamsCode is the JSON-RPC error code for invalid method parameters
+const invalidParamsCode = -32602
+
+// Message is the part of a core.Message or a types.Transaction that its fee
+// is computed from
+type Message interface {
+	GasPrice() *big.Int
+	Gas() uint64
+	Data() []byte
+}
+
+// InputError is the error for an input to the fee calculation that is
+// missing or invalid. It is caused by the caller, so RPC handlers report it
+// as invalid parameters instead of an internal error.
+type InputError struct {
+	Field  string
+	Reason string
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("%s: %s %s", errMissingInput, e.Field, e.Reason)
+}
+
+// ErrorCode returns the JSON-RPC error code of the error
+func (e *InputError) ErrorCode() int { return invalidParamsCode }
+
+// Unwrap makes the error match errMissingInput
+func (e *InputError) Unwrap() error { return errMissingInput }
+
+// MsgFee is the fee that a message pays compared to the fee that the
+// sequencer expects for it
+type MsgFee struct {
+	L1GasUsed          uint64
+	L2GasLimit         *big.Int
+	ExpectedTxGasLimit *big.Int
+	UserFee            *big.Int
+	ExpectedFee        *big.Int
+}
+
+// CalculateMsgFee computes the fee of a message the same way that the
+// sequencer verifies the fee of a transaction. The inputs are validated
+// first, a nil message or gas price returns an *InputError instead of
+// panicking. A message without calldata is charged for empty calldata.
+func CalculateMsgFee(msg Message, l1GasPrice, l2GasPrice *big.Int) (*MsgFee, error) {
+	// A nil pointer in the interface, such as a nil *types.Transaction,
+	// would panic on the first method call
+	if msg == nil || (reflect.ValueOf(msg).Kind() == reflect.Ptr && reflect.ValueOf(msg).IsNil()) {
+		return nil, &InputError{Field: "message", Reason: "is nil"}
+	}
+	gasPrice := msg.GasPrice()
+	if err := checkPrice("gasPrice", gasPrice); err != nil {
+		return nil, err
+	}
+	if err := checkPrice("l1GasPrice", l1GasPrice); err != nil {
+		return nil, err
+	}
+	if err := checkPrice("l2GasPrice", l2GasPrice); err != nil {
+		return nil, err
+	}
+	l1GasUsed := CalculateL1GasUsedU64(msg.Data())
+	l2GasLimit := new(big.Int).SetUint64(DecodeL2GasLimitU64(msg.Gas()))
+	expectedTxGasLimit := EncodeTxGasLimitForL1Gas(l1GasUsed, l1GasPrice, l2GasLimit, l2GasPrice)
+	return &MsgFee{
+		L1GasUsed:          l1GasUsed,
+		L2GasLimit:         l2GasLimit,
+		ExpectedTxGasLimit: expectedTxGasLimit,
+		UserFee:            new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), gasPrice),
+		ExpectedFee:        new(big.Int).Mul(expectedTxGasLimit, BigTxGasPrice),
+	}, nil
+}
+
+func checkPrice(field string, price *big.Int) error {
+	if price == nil {
+		return &InputError{Field: field, Reason: "is nil"}
+	}
+	if price.Sign() < 0 {
+		return &InputError{Field: field, Reason: "is negative"}
+	}
+	return nil
+}
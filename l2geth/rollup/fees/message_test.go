@@ -0,0 +1,108 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+type testMessage struct {
+	gasPrice *big.Int
+	gas      uint64
+	data     []byte
+}
+
+func (m *testMessage) GasPrice() *big.Int { return m.gasPrice }
+func (m *testMessage) Gas() uint64        { return m.gas }
+func (m *testMessage) Data() []byte       { return m.data }
+
+func TestCalculateMsgFeeInputs(t *testing.T) {
+	// Every combination of the nil fields of the inputs, a bit set means
+	// that the field is nil
+	const (
+		nilGasPrice = 1 << iota
+		nilData
+		nilL1GasPrice
+		nilL2GasPrice
+		combinations
+	)
+	for c := 0; c < combinations; c++ {
+		msg := &testMessage{gasPrice: BigTxGasPrice, gas: 21_000, data: []byte{0, 1}}
+		l1GasPrice, l2GasPrice := big.NewInt(100), big.NewInt(1)
+		if c&nilGasPrice != 0 {
+			msg.gasPrice = nil
+		}
+		if c&nilData != 0 {
+			msg.data = nil
+		}
+		if c&nilL1GasPrice != 0 {
+			l1GasPrice = nil
+		}
+		if c&nilL2GasPrice != 0 {
+			l2GasPrice = nil
+		}
+		fee, err := CalculateMsgFee(msg, l1GasPrice, l2GasPrice)
+
+		var field string
+		switch {
+		case c&nilGasPrice != 0:
+			field = "gasPrice"
+		case c&nilL1GasPrice != 0:
+			field = "l1GasPrice"
+		case c&nilL2GasPrice != 0:
+			field = "l2GasPrice"
+		}
+		if field == "" {
+			if err != nil {
+				t.Fatalf("combination %04b: unexpected error: %v", c, err)
+			}
+			expect := EncodeTxGasLimit(msg.data, l1GasPrice, new(big.Int).SetUint64(DecodeL2GasLimitU64(msg.gas)), l2GasPrice)
+			if fee.ExpectedTxGasLimit.Cmp(expect) != 0 {
+				t.Fatalf("combination %04b: mismatched gas limit: got %d, expect %d", c, fee.ExpectedTxGasLimit, expect)
+			}
+			continue
+		}
+		var inputErr *InputError
+		if !errors.As(err, &inputErr) {
+			t.Fatalf("combination %04b: mismatched error type: got %T", c, err)
+		}
+		if inputErr.Field != field {
+			t.Fatalf("combination %04b: mismatched field: got %s, expect %s", c, inputErr.Field, field)
+		}
+		if !errors.Is(err, errMissingInput) {
+			t.Fatalf("combination %04b: error does not match errMissingInput", c)
+		}
+		if inputErr.ErrorCode() != invalidParamsCode {
+			t.Fatalf("combination %04b: mismatched error code: got %d", c, inputErr.ErrorCode())
+		}
+	}
+}
+
+func TestCalculateMsgFeeInvalid(t *testing.T) {
+	tests := map[string]struct {
+		msg        Message
+		l1GasPrice *big.Int
+		field      string
+	}{
+		"nil-interface": {nil, big.NewInt(1), "message"},
+		"nil-pointer":   {(*testMessage)(nil), big.NewInt(1), "message"},
+		"negative-gas-price": {
+			&testMessage{gasPrice: big.NewInt(-1)}, big.NewInt(1), "gasPrice",
+		},
+		"negative-l1-gas-price": {
+			&testMessage{gasPrice: BigTxGasPrice}, big.NewInt(-1), "l1GasPrice",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := CalculateMsgFee(tt.msg, tt.l1GasPrice, big.NewInt(1))
+			var inputErr *InputError
+			if !errors.As(err, &inputErr) {
+				t.Fatalf("mismatched error type: got %T (%v)", err, err)
+			}
+			if inputErr.Field != tt.field {
+				t.Fatalf("mismatched field: got %s, expect %s", inputErr.Field, tt.field)
+			}
+		})
+	}
+}
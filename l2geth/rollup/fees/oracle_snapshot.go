@@ -0,0 +1,67 @@
+package fees
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+// OracleSnapshot is the state of the gas price oracle at a block. RPC
+// handlers read the snapshot instead of each gas price on its own, so that a
+// fee is computed from values that were read together.
+type OracleSnapshot struct {
+	// BlockNumber is the L2 block that the snapshot was taken at
+	BlockNumber uint64
+	// L1BlockNumber is the latest L1 block known when the snapshot was taken,
+	// which the calldata gas schedule is selected with
+	L1BlockNumber uint64
+	// L1GasPrice and L2GasPrice are denominated in the native token and
+	// include the margin
+	L1GasPrice  *big.Int
+	L2GasPrice  *big.Int
+	CalldataGas CalldataGas
+	// Margin is the percentage that the gas prices are raised by over the
+	// values of the oracle, so that the fees suggested from them are still
+	// accepted after the oracle rises by less than it
+	Margin uint64
+	// Time is when the snapshot was taken
+	Time time.Time
+}
+
+// AddMargin raises a gas price by a percentage, rounding up
+func AddMargin(price *big.Int, percent uint64) *big.Int {
+	if price == nil || percent == 0 {
+		return price
+	}
+	raised := new(big.Int).Mul(price, new(big.Int).SetUint64(100+percent))
+	raised.Add(raised, big.NewInt(99))
+	return raised.Div(raised, big.NewInt(100))
+}
+
+// TxFeeEstimate is the fee that the sequencer expects for a transaction,
+// after the subsidies that apply to it
+type TxFeeEstimate struct {
+	// L1Fee is the cost of making the data of the transaction available
+	L1Fee *big.Int
+	// Fee is the L1 fee and the L2 fee of the L2 gas limit
+	Fee *big.Int
+	// GasLimit encodes the fee at fees.BigTxGasPrice
+	GasLimit *big.Int
+}
+
+// EstimateTxFee prices the data of a transaction with the estimator of the
+// data availability layer at the gas prices of the snapshot, the same way
+// that the sequencer prices the transactions that it accepts
+func EstimateTxFee(ctx context.Context, estimator DACostEstimator, snapshot *OracleSnapshot, data []byte, l2GasLimit *big.Int) (*TxFeeEstimate, error) {
+	daCost, err := estimator.DACost(ctx, snapshot.L1GasPrice, snapshot.CalldataGas)
+	if err != nil {
+		return nil, err
+	}
+	l1Fee := daCost.CostOf(data)
+	fee := new(big.Int).Mul(l2GasLimit, snapshot.L2GasPrice)
+	return &TxFeeEstimate{
+		L1Fee:    l1Fee,
+		Fee:      fee.Add(fee, l1Fee),
+		GasLimit: EncodeTxGasLimitForL1Fee(l1Fee, l2GasLimit, snapshot.L2GasPrice),
+	}, nil
+}
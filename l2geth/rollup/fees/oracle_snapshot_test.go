@@ -0,0 +1,78 @@
+package fees
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func TestAddMargin(t *testing.T) {
+	tests := map[string]struct {
+		price   int64
+		percent uint64
+		expect  int64
+	}{
+		"no-margin":  {1000, 0, 1000},
+		"exact":      {1000, 10, 1100},
+		"round-up":   {1001, 10, 1102},
+		"zero-price": {0, 10, 0},
+		"double":     {7, 100, 14},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := AddMargin(big.NewInt(tt.price), tt.percent)
+			if got.Int64() != tt.expect {
+				t.Fatalf("mismatched price: got %d, expect %d", got, tt.expect)
+			}
+		})
+	}
+	if AddMargin(nil, 10) != nil {
+		t.Fatal("margin added to a nil price")
+	}
+}
+
+// fixedDACost prices the data of transactions at fixed prices
+type fixedDACost struct{ cost *DACost }
+
+func (fixedDACost) Backend() string { return DABackendExternal }
+
+func (e fixedDACost) DACost(ctx context.Context, l1GasPrice *big.Int, calldataGas CalldataGas) (*DACost, error) {
+	return e.cost, nil
+}
+
+func TestEstimateTxFee(t *testing.T) {
+	snapshot := &OracleSnapshot{
+		L1GasPrice:  big.NewInt(30_000_000_000),
+		L2GasPrice:  big.NewInt(1_000_000_000),
+		CalldataGas: CalldataGas{Zero: 4, NonZero: 16},
+	}
+	data := []byte{0x00, 0x01, 0x00, 0xff, 0x02}
+	l2GasLimit := big.NewInt(100_000)
+
+	tests := map[string]struct {
+		estimator DACostEstimator
+		l1Fee     *big.Int
+	}{
+		"calldata": {CalldataDACost{}, new(big.Int).Mul(snapshot.CalldataGas.CalculateL1GasUsed(data), snapshot.L1GasPrice)},
+		"external": {fixedDACost{&DACost{Zero: big.NewInt(1), NonZero: big.NewInt(10), Fixed: big.NewInt(1000)}}, big.NewInt(2*1 + 3*10 + 1000)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			estimate, err := EstimateTxFee(context.Background(), tt.estimator, snapshot, data, l2GasLimit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if estimate.L1Fee.Cmp(tt.l1Fee) != 0 {
+				t.Fatalf("mismatched L1 fee: got %d, expect %d", estimate.L1Fee, tt.l1Fee)
+			}
+			fee := new(big.Int).Add(new(big.Int).Mul(l2GasLimit, snapshot.L2GasPrice), tt.l1Fee)
+			if estimate.Fee.Cmp(fee) != 0 {
+				t.Fatalf("mismatched fee: got %d, expect %d", estimate.Fee, fee)
+			}
+			gasLimit := EncodeTxGasLimitForL1Fee(tt.l1Fee, l2GasLimit, snapshot.L2GasPrice)
+			if estimate.GasLimit.Cmp(gasLimit) != 0 {
+				t.Fatalf("mismatched gas limit: got %d, expect %d", estimate.GasLimit, gasLimit)
+			}
+		})
+	}
+}
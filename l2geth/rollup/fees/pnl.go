@@ -0,0 +1,103 @@
+package fees
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The categories of the entries of the profit and loss ledger of the
+// sequencer. Revenue is income, the other categories are spend.
+const (
+	PnLRevenue         = "revenue"
+	PnLBatchSubmission = "batchSubmission"
+	PnLProposer        = "proposer"
+	PnLOracleUpdater   = "oracleUpdater"
+)
+
+// PnLEntry is an entry of the profit and loss ledger of the sequencer
+type PnLEntry struct {
+	// Timestamp is the time of the L2 or L1 block that the entry is for
+	Timestamp uint64
+	Category  string
+	// Amount is the income or the spend in wei
+	Amount *big.Int
+	// Reference is the transaction that the entry is for, or the L1
+	// transaction that appended the batch for the revenue of a batch
+	Reference common.Hash
+}
+
+// PnLSummary sums the entries of the profit and loss ledger over a time
+// range
+type PnLSummary struct {
+	From            hexutil.Uint64 `json:"from"`
+	To              hexutil.Uint64 `json:"to"`
+	Entries         hexutil.Uint64 `json:"entries"`
+	Revenue         *hexutil.Big   `json:"revenue"`
+	BatchSubmission *hexutil.Big   `json:"batchSubmission"`
+	Proposer        *hexutil.Big   `json:"proposer"`
+	OracleUpdater   *hexutil.Big   `json:"oracleUpdater"`
+	Spend           *hexutil.Big   `json:"spend"`
+	// Net is the revenue minus the spend, negative for a loss
+	Net *hexutil.Big `json:"net"`
+}
+
+// SummarizePnL sums the entries of the ledger between the from and to
+// timestamps
+func SummarizePnL(from, to uint64, entries []*PnLEntry) *PnLSummary {
+	totals := map[string]*big.Int{
+		PnLRevenue:         new(big.Int),
+		PnLBatchSubmission: new(big.Int),
+		PnLProposer:        new(big.Int),
+		PnLOracleUpdater:   new(big.Int),
+	}
+	for _, entry := range entries {
+		if total, ok := totals[entry.Category]; ok {
+			total.Add(total, entry.Amount)
+		}
+	}
+	spend := new(big.Int).Add(totals[PnLBatchSubmission], totals[PnLProposer])
+	spend.Add(spend, totals[PnLOracleUpdater])
+	return &PnLSummary{
+		From:            hexutil.Uint64(from),
+		To:              hexutil.Uint64(to),
+		Entries:         hexutil.Uint64(len(entries)),
+		Revenue:         (*hexutil.Big)(totals[PnLRevenue]),
+		BatchSubmission: (*hexutil.Big)(totals[PnLBatchSubmission]),
+		Proposer:        (*hexutil.Big)(totals[PnLProposer]),
+		OracleUpdater:   (*hexutil.Big)(totals[PnLOracleUpdater]),
+		Spend:           (*hexutil.Big)(spend),
+		Net:             (*hexutil.Big)(new(big.Int).Sub(totals[PnLRevenue], spend)),
+	}
+}
+
+// WritePnLCSV writes the entries of the ledger as CSV with a header row.
+// Amounts are in wei and spend is negative, so that the amounts of a range
+// sum up to its net.
+func WritePnLCSV(w io.Writer, entries []*PnLEntry) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"timestamp", "category", "amount", "reference"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		amount := new(big.Int).Set(entry.Amount)
+		if entry.Category != PnLRevenue {
+			amount.Neg(amount)
+		}
+		record := []string{
+			strconv.FormatUint(entry.Timestamp, 10),
+			entry.Category,
+			amount.String(),
+			entry.Reference.Hex(),
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,56 @@
+package fees
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var testPnLEntries = []*PnLEntry{
+	{Timestamp: 10, Category: PnLRevenue, Amount: big.NewInt(1000), Reference: common.Hash{0x01}},
+	{Timestamp: 10, Category: PnLBatchSubmission, Amount: big.NewInt(600), Reference: common.Hash{0x01}},
+	{Timestamp: 11, Category: PnLProposer, Amount: big.NewInt(300), Reference: common.Hash{0x02}},
+	{Timestamp: 12, Category: PnLOracleUpdater, Amount: big.NewInt(200), Reference: common.Hash{0x03}},
+}
+
+func TestSummarizePnL(t *testing.T) {
+	summary := SummarizePnL(10, 12, testPnLEntries)
+	tests := map[string]struct {
+		got    *big.Int
+		expect int64
+	}{
+		"revenue":          {summary.Revenue.ToInt(), 1000},
+		"batch-submission": {summary.BatchSubmission.ToInt(), 600},
+		"proposer":         {summary.Proposer.ToInt(), 300},
+		"oracle-updater":   {summary.OracleUpdater.ToInt(), 200},
+		"spend":            {summary.Spend.ToInt(), 1100},
+		"net":              {summary.Net.ToInt(), -100},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.got.Int64() != tt.expect {
+				t.Fatalf("mismatched %s: got %d, expect %d", name, tt.got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestWritePnLCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePnLCSV(&buf, testPnLEntries); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(testPnLEntries)+1 {
+		t.Fatalf("mismatched lines: got %d, expect %d", len(lines), len(testPnLEntries)+1)
+	}
+	if expect := "10,revenue,1000," + (common.Hash{0x01}).Hex(); lines[1] != expect {
+		t.Fatalf("mismatched revenue line: got %s, expect %s", lines[1], expect)
+	}
+	if expect := "11,proposer,-300," + (common.Hash{0x02}).Hex(); lines[3] != expect {
+		t.Fatalf("mismatched proposer line: got %s, expect %s", lines[3], expect)
+	}
+}
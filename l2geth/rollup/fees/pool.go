@@ -0,0 +1,18 @@
+package fees
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PoolTxFee is the fee of a transaction in the transaction pool checked
+// against the latest values of the gas price oracle
+type PoolTxFee struct {
+	// L1Fee is the L1 fee that the transaction is charged, after the
+	// subsidies of the contract it is sent to
+	L1Fee       *hexutil.Big `json:"l1Fee,omitempty"`
+	UserFee     *hexutil.Big `json:"userFee,omitempty"`
+	ExpectedFee *hexutil.Big `json:"expectedFee,omitempty"`
+	PaysEnough  bool         `json:"paysEnough"`
+	// Reason is why the fee is rejected, empty when it pays enough
+	Reason string `json:"reason,omitempty"`
+}
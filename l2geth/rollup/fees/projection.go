@@ -0,0 +1,107 @@
+package fees
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// l1BlockTime is the number of seconds between two L1 blocks
+	l1BlockTime = 12
+	// maxBaseFeeGrowth is the largest factor that the L1 base fee can
+	// increase by from one block to the next
+	maxBaseFeeGrowth = 1.125
+)
+
+// errNoL1GasPriceSamples is the error for projecting the L1 fee before any
+// L1 gas price has been observed
+var errNoL1GasPriceSamples = errors.New("no L1 gas price samples")
+
+// L1GasPriceSample is an L1 gas price observed at a unix timestamp
+type L1GasPriceSample struct {
+	Time  uint64
+	Price *big.Int
+}
+
+// L1FeeProjection is the L1 fee of a transaction at the current L1 gas price
+// and at the L1 gas price projected over a horizon
+type L1FeeProjection struct {
+	L1GasUsed hexutil.Uint64 `json:"l1GasUsed"`
+	// Horizon is the number of seconds that the L1 gas price is projected
+	// over
+	Horizon             hexutil.Uint64 `json:"horizon"`
+	Samples             int            `json:"samples"`
+	L1GasPrice          *hexutil.Big   `json:"l1GasPrice"`
+	ProjectedL1GasPrice *hexutil.Big   `json:"projectedL1GasPrice"`
+	L1Fee               *hexutil.Big   `json:"l1Fee"`
+	ProjectedL1Fee      *hexutil.Big   `json:"projectedL1Fee"`
+}
+
+// ProjectL1GasPrice projects the L1 gas price horizon seconds after the
+// latest sample by fitting a least squares line to the samples, which must
+// be ordered by time. The projection is never lower than the latest price,
+// so that a falling trend does not quote a fee that the sequencer would
+// reject if the trend reverses, and never higher than the largest price that
+// the base fee can grow to over the horizon.
+func ProjectL1GasPrice(samples []L1GasPriceSample, horizon uint64) (*big.Int, error) {
+	if len(samples) == 0 {
+		return nil, errNoL1GasPriceSamples
+	}
+	latest := samples[len(samples)-1]
+	if len(samples) == 1 || horizon == 0 {
+		return new(big.Int).Set(latest.Price), nil
+	}
+	// Fit price = a + b*t with t relative to the latest sample
+	var sumT, sumP, sumTT, sumTP float64
+	for _, sample := range samples {
+		t := -float64(latest.Time - sample.Time)
+		p, _ := new(big.Float).SetInt(sample.Price).Float64()
+		sumT += t
+		sumP += p
+		sumTT += t * t
+		sumTP += t * p
+	}
+	n := float64(len(samples))
+	denom := n*sumTT - sumT*sumT
+	if denom == 0 {
+		return new(big.Int).Set(latest.Price), nil
+	}
+	slope := (n*sumTP - sumT*sumP) / denom
+	intercept := (sumP - slope*sumT) / n
+	projected := intercept + slope*float64(horizon)
+
+	lower, _ := new(big.Float).SetInt(latest.Price).Float64()
+	if projected <= lower || math.IsNaN(projected) {
+		return new(big.Int).Set(latest.Price), nil
+	}
+	upper := lower * math.Pow(maxBaseFeeGrowth, float64(horizon)/l1BlockTime)
+	if projected > upper {
+		projected = upper
+	}
+	price, _ := new(big.Float).SetFloat64(math.Ceil(projected)).Int(nil)
+	return price, nil
+}
+
+// EstimateFutureL1Fee returns the L1 fee of a transaction that uses the L1
+// gas at the latest L1 gas price and at the L1 gas price projected horizon
+// seconds ahead from the samples
+func EstimateFutureL1Fee(l1GasUsed uint64, samples []L1GasPriceSample, horizon uint64) (*L1FeeProjection, error) {
+	projected, err := ProjectL1GasPrice(samples, horizon)
+	if err != nil {
+		return nil, err
+	}
+	l1GasPrice := samples[len(samples)-1].Price
+	gas := new(big.Int).SetUint64(l1GasUsed)
+	return &L1FeeProjection{
+		L1GasUsed:           hexutil.Uint64(l1GasUsed),
+		Horizon:             hexutil.Uint64(horizon),
+		Samples:             len(samples),
+		L1GasPrice:          (*hexutil.Big)(new(big.Int).Set(l1GasPrice)),
+		ProjectedL1GasPrice: (*hexutil.Big)(projected),
+		L1Fee:               (*hexutil.Big)(new(big.Int).Mul(gas, l1GasPrice)),
+		ProjectedL1Fee:      (*hexutil.Big)(new(big.Int).Mul(gas, projected)),
+	}, nil
+}
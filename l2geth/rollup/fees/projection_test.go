@@ -0,0 +1,97 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func l1GasPriceSamples(prices ...int64) []L1GasPriceSample {
+	samples := make([]L1GasPriceSample, len(prices))
+	for i, price := range prices {
+		samples[i] = L1GasPriceSample{Time: uint64(1000 + 60*i), Price: big.NewInt(price)}
+	}
+	return samples
+}
+
+func TestProjectL1GasPrice(t *testing.T) {
+	tests := map[string]struct {
+		samples  []L1GasPriceSample
+		horizon  uint64
+		expected int64
+		err      error
+	}{
+		"no-samples": {
+			samples: nil,
+			horizon: 60,
+			err:     errNoL1GasPriceSamples,
+		},
+		"single-sample": {
+			samples:  l1GasPriceSamples(100),
+			horizon:  600,
+			expected: 100,
+		},
+		"flat": {
+			samples:  l1GasPriceSamples(100, 100, 100),
+			horizon:  600,
+			expected: 100,
+		},
+		// The price rises by 10 per minute
+		"rising": {
+			samples:  l1GasPriceSamples(100, 110, 120, 130),
+			horizon:  300,
+			expected: 180,
+		},
+		// A falling trend is projected at the latest price
+		"falling": {
+			samples:  l1GasPriceSamples(130, 120, 110, 100),
+			horizon:  300,
+			expected: 100,
+		},
+		"zero-horizon": {
+			samples:  l1GasPriceSamples(100, 110, 120, 130),
+			horizon:  0,
+			expected: 130,
+		},
+		// The trend is faster than the base fee can grow over one block
+		"capped": {
+			samples: []L1GasPriceSample{
+				{Time: 1000, Price: big.NewInt(100)},
+				{Time: 1012, Price: big.NewInt(200)},
+				{Time: 1024, Price: big.NewInt(300)},
+			},
+			horizon:  12,
+			expected: 338,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			price, err := ProjectL1GasPrice(tt.samples, tt.horizon)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if price.Int64() != tt.expected {
+				t.Fatalf("mismatched price: got %d, expect %d", price, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateFutureL1Fee(t *testing.T) {
+	projection, err := EstimateFutureL1Fee(1000, l1GasPriceSamples(100, 110, 120, 130), 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.L1Fee.ToInt().Int64() != 130_000 {
+		t.Fatalf("mismatched L1 fee: got %d", projection.L1Fee.ToInt())
+	}
+	if projection.ProjectedL1Fee.ToInt().Int64() != 180_000 {
+		t.Fatalf("mismatched projected L1 fee: got %d", projection.ProjectedL1Fee.ToInt())
+	}
+	if projection.Samples != 4 {
+		t.Fatalf("mismatched samples: got %d", projection.Samples)
+	}
+}
@@ -0,0 +1,199 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var quickConfig = &quick.Config{MaxCount: 1000}
+
+// l2GasLimitOf maps a random value to an L2 gas limit that can be encoded in
+// the lower digits of the gas limit
+func l2GasLimitOf(n uint32) *big.Int {
+	return new(big.Int).SetUint64(uint64(n) % 99_990_000)
+}
+
+func TestFeeMonotonicInDataSize(t *testing.T) {
+	f := func(data []byte, extra byte, l1GasPrice, l2GasPrice, l2GasLimit uint32) bool {
+		l1, l2, limit := new(big.Int).SetUint64(uint64(l1GasPrice)), new(big.Int).SetUint64(uint64(l2GasPrice)), l2GasLimitOf(l2GasLimit)
+		fee := EncodeTxGasLimit(data, l1, limit, l2)
+		larger := EncodeTxGasLimit(append(data, extra), l1, limit, l2)
+		return larger.Cmp(fee) >= 0
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFeeMonotonicInGasPrices(t *testing.T) {
+	f := func(data []byte, a, b, other, l2GasLimit uint32) bool {
+		if a > b {
+			a, b = b, a
+		}
+		lo, hi, fixed := new(big.Int).SetUint64(uint64(a)), new(big.Int).SetUint64(uint64(b)), new(big.Int).SetUint64(uint64(other))
+		limit := l2GasLimitOf(l2GasLimit)
+		// Raising either of the gas prices never lowers the fee
+		if EncodeTxGasLimit(data, lo, limit, fixed).Cmp(EncodeTxGasLimit(data, hi, limit, fixed)) > 0 {
+			return false
+		}
+		return EncodeTxGasLimit(data, fixed, limit, lo).Cmp(EncodeTxGasLimit(data, fixed, limit, hi)) <= 0
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFeeMonotonicInL2GasLimit(t *testing.T) {
+	f := func(data []byte, a, b, l1GasPrice, l2GasPrice uint32) bool {
+		lo, hi := l2GasLimitOf(a), l2GasLimitOf(b)
+		if lo.Cmp(hi) > 0 {
+			lo, hi = hi, lo
+		}
+		l1, l2 := new(big.Int).SetUint64(uint64(l1GasPrice)), new(big.Int).SetUint64(uint64(l2GasPrice))
+		return EncodeTxGasLimit(data, l1, lo, l2).Cmp(EncodeTxGasLimit(data, l1, hi, l2)) <= 0
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFeeMonotonicInScalar(t *testing.T) {
+	f := func(data []byte, a, b uint16, l1GasPrice, l2GasPrice, l2GasLimit uint32) bool {
+		if a > b {
+			a, b = b, a
+		}
+		lo, hi := float64(a)/100, float64(b)/100
+		params := func(scalar *float64) *FeeParams {
+			return &FeeParams{
+				L1GasPrice: (*hexutil.Big)(new(big.Int).SetUint64(uint64(l1GasPrice))),
+				L2GasPrice: (*hexutil.Big)(new(big.Int).SetUint64(uint64(l2GasPrice))),
+				Scalar:     scalar,
+			}
+		}
+		tx := SimulationTx{Data: data, L2GasLimit: l2GasLimitOf(l2GasLimit).Uint64()}
+		return params(&lo).Fee(tx).Cmp(params(&hi).Fee(tx)) <= 0
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The L2 gas limit always round trips through the encoded gas limit,
+// rounded up to a multiple of ten thousand
+func TestL2GasLimitRoundTrip(t *testing.T) {
+	f := func(data []byte, l1GasPrice, l2GasPrice, l2GasLimit uint32) bool {
+		limit := l2GasLimitOf(l2GasLimit)
+		l1, l2 := new(big.Int).SetUint64(uint64(l1GasPrice)), new(big.Int).SetUint64(uint64(l2GasPrice))
+		gasLimit := EncodeTxGasLimit(data, l1, limit, l2)
+		return DecodeL2GasLimit(gasLimit).Cmp(Ceilmod(limit, BigTenThousand)) == 0
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaysEnoughExpectedFee(t *testing.T) {
+	f := func(fee uint64, down, up uint16) bool {
+		expected := new(big.Int).SetUint64(fee >> 11)
+		err := PaysEnough(&PaysEnoughOpts{
+			UserFee:       new(big.Int).Set(expected),
+			ExpectedFee:   expected,
+			ThresholdDown: new(big.Float).SetFloat64(float64(down%1000+1) / 1000),
+			ThresholdUp:   new(big.Float).SetFloat64(float64(up) / 100),
+		})
+		return err == nil
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The fees that are accepted form a single range around the expected fee,
+// whose bounds match the thresholds on both sides up to the precision of the
+// floats that the bounds are computed with
+func TestPaysEnoughThresholds(t *testing.T) {
+	f := func(fee uint64, down, up uint16) bool {
+		// Keep the highest accepted fee within a uint64, which the
+		// thresholds are applied to
+		expected := new(big.Int).SetUint64(fee >> 11)
+		thresholdDown := new(big.Rat).SetFrac64(int64(down%1000+1), 1000)
+		thresholdUp := new(big.Rat).SetFrac64(int64(up), 100)
+		opts := func(userFee *big.Int) *PaysEnoughOpts {
+			return &PaysEnoughOpts{
+				UserFee:       userFee,
+				ExpectedFee:   expected,
+				ThresholdDown: new(big.Float).SetRat(thresholdDown),
+				ThresholdUp:   new(big.Float).SetRat(thresholdUp),
+			}
+		}
+		// The lowest accepted fee
+		lo := mulByFloat(expected, new(big.Float).SetRat(thresholdDown))
+		if !withinFloatPrecision(lo, new(big.Rat).Mul(new(big.Rat).SetInt(expected), thresholdDown)) {
+			return false
+		}
+		if PaysEnough(opts(lo)) != nil {
+			return false
+		}
+		if lo.Sign() > 0 && !errors.Is(PaysEnough(opts(new(big.Int).Sub(lo, big1))), ErrFeeTooLow) {
+			return false
+		}
+		// The highest accepted fee
+		hi := new(big.Int).Add(expected, mulByFloat(expected, new(big.Float).SetRat(thresholdUp)))
+		upper := new(big.Rat).Mul(new(big.Rat).SetInt(expected), new(big.Rat).Add(big.NewRat(1, 1), thresholdUp))
+		if !withinFloatPrecision(hi, upper) {
+			return false
+		}
+		if PaysEnough(opts(hi)) != nil {
+			return false
+		}
+		return errors.Is(PaysEnough(opts(new(big.Int).Add(hi, big1))), ErrFeeTooHigh)
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var big1 = big.NewInt(1)
+
+// withinFloatPrecision returns whether n is within a wei or the relative
+// precision of a float64 of the exact value
+func withinFloatPrecision(n *big.Int, exact *big.Rat) bool {
+	diff := new(big.Rat).Sub(new(big.Rat).SetInt(n), exact)
+	tolerance := new(big.Rat).Mul(exact, big.NewRat(1, 1<<50))
+	if tolerance.Cmp(big.NewRat(1, 1)) < 0 {
+		tolerance.SetInt64(1)
+	}
+	return diff.Abs(diff).Cmp(tolerance) <= 0
+}
+
+// referenceTxGasLimit is the allocating formula that the Calculator replaced
+func referenceTxGasLimit(data []byte, l1GasPrice, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	l1Fee := new(big.Int).Mul(l1GasPrice, calculateL1GasLimit(data, Overhead))
+	roundedL2GasLimit := Ceilmod(l2GasLimit, BigTenThousand)
+	l2Fee := new(big.Int).Mul(l2GasPrice, roundedL2GasLimit)
+	sum := new(big.Int).Add(l1Fee, l2Fee)
+	scaled := new(big.Int).Div(sum, bigFeeScalar)
+	rounded := Ceilmod(scaled, BigTenThousand)
+	roundedScaledL2GasLimit := new(big.Int).Div(roundedL2GasLimit, BigTenThousand)
+	return new(big.Int).Add(rounded, roundedScaledL2GasLimit)
+}
+
+func TestCalculatorMatchesReference(t *testing.T) {
+	// The calculator is reused across checks to catch state leaking between
+	// calls through the scratch space
+	var c Calculator
+	z := new(big.Int)
+	f := func(data []byte, l1GasPrice, l2GasPrice uint64, l2GasLimit uint32) bool {
+		l1, l2, limit := new(big.Int).SetUint64(l1GasPrice), new(big.Int).SetUint64(l2GasPrice), l2GasLimitOf(l2GasLimit)
+		expect := referenceTxGasLimit(data, l1, limit, l2)
+		return c.EncodeTxGasLimit(z, data, l1, limit, l2).Cmp(expect) == 0 &&
+			EncodeTxGasLimit(data, l1, limit, l2).Cmp(expect) == 0
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package fees
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// RebateTx is a transaction of a transaction batch that was charged an L1
+// fee
+type RebateTx struct {
+	Sender       common.Address
+	L1GasUsed    uint64
+	ChargedL1Fee *big.Int
+}
+
+// Rebate is the amount that a sender was overcharged for the L1 fees of its
+// transactions in a transaction batch and that it can claim back
+type Rebate struct {
+	Sender common.Address `json:"sender"`
+	Amount *hexutil.Big   `json:"amount"`
+}
+
+// ComputeRebates splits the actual L1 cost of a transaction batch between
+// its transactions in proportion to their L1 gas used and returns the
+// rebates of the senders that were charged more than their share, ordered by
+// sender. Senders that were charged less than their share of some
+// transactions are not charged the difference, it is netted against the
+// rebates of their other transactions in the batch.
+func ComputeRebates(cost *big.Int, txs []RebateTx) []Rebate {
+	total := new(big.Int)
+	for _, tx := range txs {
+		total.Add(total, new(big.Int).SetUint64(tx.L1GasUsed))
+	}
+	if total.Sign() == 0 {
+		return nil
+	}
+	overcharged := make(map[common.Address]*big.Int)
+	for _, tx := range txs {
+		share := new(big.Int).SetUint64(tx.L1GasUsed)
+		share.Mul(share, cost)
+		share.Div(share, total)
+		diff, ok := overcharged[tx.Sender]
+		if !ok {
+			diff = new(big.Int)
+			overcharged[tx.Sender] = diff
+		}
+		diff.Add(diff, tx.ChargedL1Fee)
+		diff.Sub(diff, share)
+	}
+	var rebates []Rebate
+	for sender, amount := range overcharged {
+		if amount.Sign() > 0 {
+			rebates = append(rebates, Rebate{Sender: sender, Amount: (*hexutil.Big)(amount)})
+		}
+	}
+	sort.Slice(rebates, func(i, j int) bool {
+		return bytes.Compare(rebates[i].Sender.Bytes(), rebates[j].Sender.Bytes()) < 0
+	})
+	return rebates
+}
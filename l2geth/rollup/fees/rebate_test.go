@@ -0,0 +1,63 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestComputeRebates(t *testing.T) {
+	alice, bob := common.Address{0x01}, common.Address{0x02}
+	tests := map[string]struct {
+		cost    int64
+		txs     []RebateTx
+		rebates map[common.Address]int64
+	}{
+		"no-txs": {
+			cost:    100,
+			rebates: map[common.Address]int64{},
+		},
+		"exact": {
+			cost: 100,
+			txs: []RebateTx{
+				{Sender: alice, L1GasUsed: 10, ChargedL1Fee: big.NewInt(50)},
+				{Sender: bob, L1GasUsed: 10, ChargedL1Fee: big.NewInt(50)},
+			},
+			rebates: map[common.Address]int64{},
+		},
+		"overcharged": {
+			cost: 100,
+			txs: []RebateTx{
+				{Sender: alice, L1GasUsed: 30, ChargedL1Fee: big.NewInt(90)},
+				{Sender: bob, L1GasUsed: 10, ChargedL1Fee: big.NewInt(30)},
+			},
+			rebates: map[common.Address]int64{alice: 15, bob: 5},
+		},
+		"netted": {
+			cost: 100,
+			txs: []RebateTx{
+				{Sender: alice, L1GasUsed: 10, ChargedL1Fee: big.NewInt(70)},
+				{Sender: alice, L1GasUsed: 10, ChargedL1Fee: big.NewInt(10)},
+				{Sender: bob, L1GasUsed: 20, ChargedL1Fee: big.NewInt(40)},
+			},
+			rebates: map[common.Address]int64{alice: 30},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rebates := ComputeRebates(big.NewInt(tt.cost), tt.txs)
+			if len(rebates) != len(tt.rebates) {
+				t.Fatalf("mismatched rebates: got %d, expect %d", len(rebates), len(tt.rebates))
+			}
+			for i, rebate := range rebates {
+				if i > 0 && rebates[i-1].Sender.Hex() >= rebate.Sender.Hex() {
+					t.Fatal("rebates not ordered by sender")
+				}
+				if amount := rebate.Amount.ToInt().Int64(); amount != tt.rebates[rebate.Sender] {
+					t.Fatalf("mismatched rebate of %s: got %d, expect %d", rebate.Sender.Hex(), amount, tt.rebates[rebate.Sender])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package fees
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The rollup fields of receipts, by the names that they are emitted under
+// by default
+const (
+	ReceiptFieldL1GasUsed   = "l1GasUsed"
+	ReceiptFieldL1GasPrice  = "l1GasPrice"
+	ReceiptFieldL1Fee       = "l1Fee"
+	ReceiptFieldGasToken    = "gasToken"
+	ReceiptFieldArrivalTime = "arrivalTime"
+)
+
+// receiptFields are the rollup fields of receipts
+var receiptFields = []string{
+	ReceiptFieldL1GasUsed,
+	ReceiptFieldL1GasPrice,
+	ReceiptFieldL1Fee,
+	ReceiptFieldGasToken,
+	ReceiptFieldArrivalTime,
+}
+
+// standardReceiptFields are the fields of Ethereum receipts, which the
+// rollup fields cannot be renamed to
+var standardReceiptFields = map[string]bool{
+	"blockHash":         true,
+	"blockNumber":       true,
+	"transactionHash":   true,
+	"transactionIndex":  true,
+	"from":              true,
+	"to":                true,
+	"gasUsed":           true,
+	"cumulativeGasUsed": true,
+	"contractAddress":   true,
+	"logs":              true,
+	"logsBloom":         true,
+	"root":              true,
+	"status":            true,
+}
+
+// ErrInvalidReceiptProfile represents the error case of a receipt profile
+// that cannot be applied to receipts
+var ErrInvalidReceiptProfile = errors.New("invalid receipt profile")
+
+// ReceiptProfile is how the rollup fields of receipts are presented to the
+// clients of an RPC namespace. Indexers disagree on the names of the fields
+// and on whether they may be missing.
+type ReceiptProfile struct {
+	// Names maps the rollup fields to the names that they are emitted
+	// under, the fields that are not in it are left out
+	Names map[string]string `json:"names"`
+	// AlwaysL1Fee emits the L1 fee fields as zero in the receipts that do
+	// not record an L1 fee
+	AlwaysL1Fee bool `json:"alwaysL1Fee"`
+}
+
+// The built in receipt profiles, named after the modes of the rpcproxy that
+// adapts the receipts of a node in the same way
+var (
+	// DefaultReceiptProfile emits the rollup fields under their own names
+	DefaultReceiptProfile = &ReceiptProfile{Names: defaultReceiptNames()}
+	// StripReceiptProfile leaves out the rollup fields, for clients that
+	// reject unknown receipt fields
+	StripReceiptProfile = &ReceiptProfile{Names: map[string]string{}}
+	// InjectReceiptProfile emits the L1 fee fields in every receipt, for
+	// clients that expect them in every receipt
+	InjectReceiptProfile = &ReceiptProfile{Names: defaultReceiptNames(), AlwaysL1Fee: true}
+)
+
+// ReceiptProfiles are the built in receipt profiles by name
+var ReceiptProfiles = map[string]*ReceiptProfile{
+	"default": DefaultReceiptProfile,
+	"strip":   StripReceiptProfile,
+	"inject":  InjectReceiptProfile,
+}
+
+// defaultReceiptNames maps every rollup field to its own name
+func defaultReceiptNames() map[string]string {
+	names := make(map[string]string, len(receiptFields))
+	for _, field := range receiptFields {
+		names[field] = field
+	}
+	return names
+}
+
+// Validate checks that the profile only renames known rollup fields, and
+// that the names neither collide with each other nor with the fields of
+// Ethereum receipts
+func (p *ReceiptProfile) Validate() error {
+	known := make(map[string]bool, len(receiptFields))
+	for _, field := range receiptFields {
+		known[field] = true
+	}
+	used := make(map[string]string, len(p.Names))
+	for field, name := range p.Names {
+		if !known[field] {
+			return fmt.Errorf("%w: unknown receipt field %q", ErrInvalidReceiptProfile, field)
+		}
+		if name == "" {
+			return fmt.Errorf("%w: empty name of receipt field %q", ErrInvalidReceiptProfile, field)
+		}
+		if standardReceiptFields[name] {
+			return fmt.Errorf("%w: receipt field %q renamed to the Ethereum receipt field %q", ErrInvalidReceiptProfile, field, name)
+		}
+		if other, ok := used[name]; ok {
+			return fmt.Errorf("%w: receipt fields %q and %q both named %q", ErrInvalidReceiptProfile, other, field, name)
+		}
+		used[name] = field
+	}
+	return nil
+}
+
+// Set sets a rollup field of a receipt under its name in the profile,
+// nothing when the profile leaves the field out. A nil profile is the
+// default one.
+func (p *ReceiptProfile) Set(receipt map[string]interface{}, field string, value interface{}) {
+	if p == nil {
+		p = DefaultReceiptProfile
+	}
+	if name, ok := p.Names[field]; ok {
+		receipt[name] = value
+	}
+}
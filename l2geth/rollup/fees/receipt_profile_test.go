@@ -0,0 +1,72 @@
+package fees
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReceiptProfileValidate(t *testing.T) {
+	tests := map[string]struct {
+		names map[string]string
+		err   error
+	}{
+		"snake-case": {
+			names: map[string]string{ReceiptFieldL1Fee: "l1_fee", ReceiptFieldL1GasUsed: "l1_gas_used"},
+		},
+		"unknown-field": {
+			names: map[string]string{"l1FeeScalar": "l1FeeScalar"},
+			err:   ErrInvalidReceiptProfile,
+		},
+		"empty-name": {
+			names: map[string]string{ReceiptFieldL1Fee: ""},
+			err:   ErrInvalidReceiptProfile,
+		},
+		"standard-field": {
+			names: map[string]string{ReceiptFieldL1GasUsed: "gasUsed"},
+			err:   ErrInvalidReceiptProfile,
+		},
+		"duplicate-name": {
+			names: map[string]string{ReceiptFieldL1Fee: "fee", ReceiptFieldL1GasPrice: "fee"},
+			err:   ErrInvalidReceiptProfile,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			profile := &ReceiptProfile{Names: tt.names}
+			if err := profile.Validate(); !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+	for name, profile := range ReceiptProfiles {
+		if err := profile.Validate(); err != nil {
+			t.Fatalf("invalid built in receipt profile %s: %v", name, err)
+		}
+	}
+}
+
+func TestReceiptProfileSet(t *testing.T) {
+	tests := map[string]struct {
+		profile *ReceiptProfile
+		expect  map[string]interface{}
+	}{
+		"nil":     {nil, map[string]interface{}{ReceiptFieldL1Fee: 1}},
+		"default": {DefaultReceiptProfile, map[string]interface{}{ReceiptFieldL1Fee: 1}},
+		"strip":   {StripReceiptProfile, map[string]interface{}{}},
+		"renamed": {&ReceiptProfile{Names: map[string]string{ReceiptFieldL1Fee: "l1_fee"}}, map[string]interface{}{"l1_fee": 1}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			receipt := make(map[string]interface{})
+			tt.profile.Set(receipt, ReceiptFieldL1Fee, 1)
+			if len(receipt) != len(tt.expect) {
+				t.Fatalf("mismatched fields: got %v, expect %v", receipt, tt.expect)
+			}
+			for field, value := range tt.expect {
+				if receipt[field] != value {
+					t.Fatalf("mismatched fields: got %v, expect %v", receipt, tt.expect)
+				}
+			}
+		})
+	}
+}
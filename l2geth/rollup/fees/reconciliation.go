@@ -0,0 +1,42 @@
+package fees
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Reconciliation compares the fees collected on L2 for the transactions in a
+// transaction batch against the cost of submitting that batch to L1
+type Reconciliation struct {
+	BatchIndex   hexutil.Uint64 `json:"batchIndex"`
+	L1TxHash     common.Hash    `json:"l1TxHash"`
+	L1GasUsed    hexutil.Uint64 `json:"l1GasUsed"`
+	L1GasPrice   *hexutil.Big   `json:"l1GasPrice"`
+	StartBlock   hexutil.Uint64 `json:"startBlock"`
+	EndBlock     hexutil.Uint64 `json:"endBlock"`
+	Transactions hexutil.Uint64 `json:"transactions"`
+	Revenue      *hexutil.Big   `json:"revenue"`
+	Cost         *hexutil.Big   `json:"cost"`
+	Margin       *hexutil.Big   `json:"margin"`
+}
+
+// NewReconciliation creates a Reconciliation, computing the cost of the batch
+// submission and the margin of the batch
+func NewReconciliation(index uint64, txHash common.Hash, gasUsed uint64, gasPrice *big.Int, start, end uint64, txs uint64, revenue *big.Int) *Reconciliation {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+	margin := new(big.Int).Sub(revenue, cost)
+	return &Reconciliation{
+		BatchIndex:   hexutil.Uint64(index),
+		L1TxHash:     txHash,
+		L1GasUsed:    hexutil.Uint64(gasUsed),
+		L1GasPrice:   (*hexutil.Big)(gasPrice),
+		StartBlock:   hexutil.Uint64(start),
+		EndBlock:     hexutil.Uint64(end),
+		Transactions: hexutil.Uint64(txs),
+		Revenue:      (*hexutil.Big)(revenue),
+		Cost:         (*hexutil.Big)(cost),
+		Margin:       (*hexutil.Big)(margin),
+	}
+}
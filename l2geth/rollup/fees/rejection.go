@@ -0,0 +1,58 @@
+package fees
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// FeeRejectionData is the machine readable part of a FeeRejection that
+// clients can price the transaction again with
+type FeeRejectionData struct {
+	UserFee     *hexutil.Big `json:"userFee"`
+	ExpectedFee *hexutil.Big `json:"expectedFee"`
+	// MinFee and MaxFee are the bounds of the fees that are accepted, MaxFee
+	// is omitted when overpaying is not limited
+	MinFee *hexutil.Big `json:"minFee"`
+	MaxFee *hexutil.Big `json:"maxFee,omitempty"`
+	// MinGasPrice is the gas price that the transaction must be sent with
+	// and GasLimit is the gas limit that encodes the expected fee at it
+	MinGasPrice *hexutil.Big   `json:"minGasPrice"`
+	GasLimit    hexutil.Uint64 `json:"gasLimit"`
+}
+
+// FeeRejection is the error for a transaction whose fee is rejected by
+// PaysEnough. It wraps ErrFeeTooLow or ErrFeeTooHigh and is sent to RPC
+// clients along with its data.
+type FeeRejection struct {
+	err  error
+	data *FeeRejectionData
+}
+
+// NewFeeRejection returns the FeeRejection for the error that PaysEnough
+// returned with the options, where gasLimit is the transaction gas limit
+// that pays the expected fee
+func NewFeeRejection(err error, opts *PaysEnoughOpts, gasLimit uint64) *FeeRejection {
+	minFee := opts.ExpectedFee
+	if opts.ThresholdDown != nil {
+		minFee = mulByFloat(opts.ExpectedFee, opts.ThresholdDown)
+	}
+	data := &FeeRejectionData{
+		UserFee:     (*hexutil.Big)(opts.UserFee),
+		ExpectedFee: (*hexutil.Big)(opts.ExpectedFee),
+		MinFee:      (*hexutil.Big)(minFee),
+		MinGasPrice: (*hexutil.Big)(BigTxGasPrice),
+		GasLimit:    hexutil.Uint64(gasLimit),
+	}
+	if opts.ThresholdUp != nil {
+		maxFee := mulByFloat(opts.ExpectedFee, opts.ThresholdUp)
+		data.MaxFee = (*hexutil.Big)(maxFee.Add(maxFee, opts.ExpectedFee))
+	}
+	return &FeeRejection{err: err, data: data}
+}
+
+func (e *FeeRejection) Error() string { return e.err.Error() }
+
+// Unwrap makes the error match the error of PaysEnough
+func (e *FeeRejection) Unwrap() error { return e.err }
+
+// ErrorData returns the data that is sent to RPC clients with the error
+func (e *FeeRejection) ErrorData() interface{} { return e.data }
@@ -0,0 +1,60 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestFeeRejection(t *testing.T) {
+	tests := map[string]struct {
+		opts   PaysEnoughOpts
+		err    error
+		minFee int64
+		maxFee int64
+	}{
+		"too-low": {
+			PaysEnoughOpts{UserFee: big.NewInt(500), ExpectedFee: big.NewInt(1000)},
+			ErrFeeTooLow, 1000, 0,
+		},
+		"too-low-with-buffer": {
+			PaysEnoughOpts{UserFee: big.NewInt(500), ExpectedFee: big.NewInt(1000), ThresholdDown: big.NewFloat(0.9)},
+			ErrFeeTooLow, 900, 0,
+		},
+		"too-high": {
+			PaysEnoughOpts{UserFee: big.NewInt(5000), ExpectedFee: big.NewInt(1000), ThresholdUp: big.NewFloat(3)},
+			ErrFeeTooHigh, 1000, 4000,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := PaysEnough(&tt.opts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched PaysEnough error: got %v, expect %v", err, tt.err)
+			}
+			rejection := NewFeeRejection(err, &tt.opts, 1234)
+			if !errors.Is(rejection, tt.err) {
+				t.Fatalf("rejection does not match %v", tt.err)
+			}
+			data := rejection.ErrorData().(*FeeRejectionData)
+			if data.MinFee.ToInt().Int64() != tt.minFee {
+				t.Fatalf("mismatched min fee: got %d, expect %d", data.MinFee.ToInt(), tt.minFee)
+			}
+			if tt.maxFee == 0 && data.MaxFee != nil {
+				t.Fatalf("unexpected max fee: %d", data.MaxFee.ToInt())
+			}
+			if tt.maxFee != 0 && data.MaxFee.ToInt().Int64() != tt.maxFee {
+				t.Fatalf("mismatched max fee: got %d, expect %d", data.MaxFee.ToInt(), tt.maxFee)
+			}
+			// The fee at the minimum gas price and the suggested gas limit
+			// is the expected fee
+			if data.MinGasPrice.ToInt().Cmp(BigTxGasPrice) != 0 || data.GasLimit != 1234 {
+				t.Fatalf("mismatched pricing: %d at %d", data.GasLimit, data.MinGasPrice.ToInt())
+			}
+			if _, err := json.Marshal(rejection.ErrorData()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
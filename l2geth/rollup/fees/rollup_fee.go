@@ -7,7 +7,6 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/params"
 )
 
 var (
@@ -23,9 +22,17 @@ var (
 	ErrL2GasLimitTooLow = errors.New("L2 gas limit too low")
 )
 
-// overhead represents the fixed cost of batch submission of a single
+// The calldata gas costs match params.TxDataZeroGas and
+// params.TxDataNonZeroGasEIP2028. They are not imported so that the fee
+// arithmetic can be built for js/wasm without the node dependencies.
+const (
+	txDataZeroGas    uint64 = 4
+	txDataNonZeroGas uint64 = 16
+)
+
+// Overhead represents the fixed cost of batch submission of a single
 // transaction in gas.
-const overhead uint64 = 2750
+const Overhead uint64 = 2750
 
 // feeScalar is used to scale the calculations in EncodeL2GasLimit
 // to prevent them from being too large
@@ -69,17 +76,29 @@ var BigTenThousand = new(big.Int).SetUint64(tenThousand)
 // function when in reality the RLP encoded transaction should be. The
 // additional cost is added to the overhead constant to prevent the need to RLP
 // encode transactions during calls to `eth_estimateGas`
+//
+// The intermediate values are computed in a pooled Calculator, so only the
+// result is allocated.
 func EncodeTxGasLimit(data []byte, l1GasPrice, l2GasLimit, l2GasPrice *big.Int) *big.Int {
-	l1GasLimit := calculateL1GasLimit(data, overhead)
-	roundedL2GasLimit := Ceilmod(l2GasLimit, BigTenThousand)
-	l1Fee := new(big.Int).Mul(l1GasPrice, l1GasLimit)
-	l2Fee := new(big.Int).Mul(l2GasPrice, roundedL2GasLimit)
-	sum := new(big.Int).Add(l1Fee, l2Fee)
-	scaled := new(big.Int).Div(sum, bigFeeScalar)
-	rounded := Ceilmod(scaled, BigTenThousand)
-	roundedScaledL2GasLimit := new(big.Int).Div(roundedL2GasLimit, BigTenThousand)
-	result := new(big.Int).Add(rounded, roundedScaledL2GasLimit)
-	return result
+	c := calculators.Get().(*Calculator)
+	defer calculators.Put(c)
+	return c.EncodeTxGasLimit(new(big.Int), data, l1GasPrice, l2GasLimit, l2GasPrice)
+}
+
+// EncodeTxGasLimitForL1Gas is EncodeTxGasLimit for a transaction whose L1
+// gas has already been computed, see CalculateL1GasUsed
+func EncodeTxGasLimitForL1Gas(l1GasUsed uint64, l1GasPrice, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	c := calculators.Get().(*Calculator)
+	defer calculators.Put(c)
+	return c.EncodeTxGasLimitForL1Gas(new(big.Int), l1GasUsed, l1GasPrice, l2GasLimit, l2GasPrice)
+}
+
+// EncodeTxGasLimitForL1Fee computes the `tx.gasLimit` from the L1 fee, see
+// EncodeTxGasLimit
+func EncodeTxGasLimitForL1Fee(l1Fee, l2GasLimit, l2GasPrice *big.Int) *big.Int {
+	c := calculators.Get().(*Calculator)
+	defer calculators.Put(c)
+	return c.encodeTxGasLimit(new(big.Int), l1Fee, l2GasLimit, l2GasPrice)
 }
 
 func Ceilmod(a, b *big.Int) *big.Int {
@@ -103,6 +122,38 @@ func DecodeL2GasLimitU64(gasLimit uint64) uint64 {
 	return scaled * tenThousand
 }
 
+// MaxChargedL1Fee returns the largest L1 fee that a transaction with the gas
+// limit can have been charged for at the L2 gas price. The gas limit encodes
+// the sum of the L1 and L2 fees rounded up to a multiple of ten thousand
+// after scaling, so the L1 fee is known up to that rounding. A negative
+// result means that the gas limit does not cover the L2 fee.
+func MaxChargedL1Fee(gasLimit uint64, l2GasPrice *big.Int) *big.Int {
+	// The scaled sum was rounded down by the division by the scalar, so the
+	// sum is less than one more than the scaled sum times the scalar
+	scaled := gasLimit - gasLimit%tenThousand
+	fee := new(big.Int).SetUint64(scaled + 1)
+	fee.Mul(fee, bigFeeScalar)
+	fee.Sub(fee, common.Big1)
+	l2Fee := new(big.Int).SetUint64(DecodeL2GasLimitU64(gasLimit))
+	l2Fee.Mul(l2Fee, l2GasPrice)
+	return fee.Sub(fee, l2Fee)
+}
+
+// MaxChargedL1GasPrice returns the largest L1 gas price that a transaction
+// with the gas limit and L1 gas used can have been charged at, see
+// MaxChargedL1Fee. It returns nil when the transaction uses no L1 gas or the
+// gas limit does not cover the L2 fee.
+func MaxChargedL1GasPrice(gasLimit, l1GasUsed uint64, l2GasPrice *big.Int) *big.Int {
+	if l1GasUsed == 0 {
+		return nil
+	}
+	fee := MaxChargedL1Fee(gasLimit, l2GasPrice)
+	if fee.Sign() < 0 {
+		return nil
+	}
+	return fee.Div(fee, new(big.Int).SetUint64(l1GasUsed))
+}
+
 // PaysEnoughOpts represent the options to PaysEnough
 type PaysEnoughOpts struct {
 	UserFee, ExpectedFee       *big.Int
@@ -150,16 +201,40 @@ func mulByFloat(num *big.Int, float *big.Float) *big.Int {
 	return new(big.Int).SetUint64(uint64(rounded))
 }
 
+// CalculateL1GasUsed returns the L1 gas that a transaction with the given
+// calldata is charged for, including the fixed batch submission overhead
+func CalculateL1GasUsed(data []byte) *big.Int {
+	return calculateL1GasLimit(data, Overhead)
+}
+
+// CalculateL1GasUsedU64 is CalculateL1GasUsed without allocating the result
+func CalculateL1GasUsedU64(data []byte) uint64 {
+	return calculateL1GasLimitU64(data, Overhead)
+}
+
 // calculateL1GasLimit computes the L1 gasLimit based on the calldata and
 // constant sized overhead. The overhead can be decreased as the cost of the
 // batch submission goes down via contract optimizations. This will not overflow
 // under standard network conditions.
 func calculateL1GasLimit(data []byte, overhead uint64) *big.Int {
+	return new(big.Int).SetUint64(calculateL1GasLimitU64(data, overhead))
+}
+
+func calculateL1GasLimitU64(data []byte, overhead uint64) uint64 {
 	zeroes, ones := zeroesAndOnes(data)
-	zeroesCost := zeroes * params.TxDataZeroGas
-	onesCost := ones * params.TxDataNonZeroGasEIP2028
-	gasLimit := zeroesCost + onesCost + overhead
-	return new(big.Int).SetUint64(gasLimit)
+	return l1GasLimitOfCounts(zeroes, ones, overhead)
+}
+
+// CalldataL1GasUsed is CalculateL1GasUsed for calldata with the given number
+// of zero and non-zero bytes, for callers that have already counted them
+func CalldataL1GasUsed(zeroes, nonZeroes uint64) uint64 {
+	return l1GasLimitOfCounts(zeroes, nonZeroes, Overhead)
+}
+
+func l1GasLimitOfCounts(zeroes, ones, overhead uint64) uint64 {
+	zeroesCost := zeroes * DefaultCalldataGas.Zero
+	onesCost := ones * DefaultCalldataGas.NonZero
+	return zeroesCost + onesCost + overhead
 }
 
 func zeroesAndOnes(data []byte) (uint64, uint64) {
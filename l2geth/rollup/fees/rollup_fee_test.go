@@ -31,6 +31,21 @@ func TestL1GasLimit(t *testing.T) {
 	}
 }
 
+func TestCalldataGas(t *testing.T) {
+	if txDataZeroGas != params.TxDataZeroGas || txDataNonZeroGas != params.TxDataNonZeroGasEIP2028 {
+		t.Fatal("calldata gas costs do not match the protocol params")
+	}
+}
+
+func TestCalculateL1GasUsed(t *testing.T) {
+	data := []byte{0x00, 0x00, 0x01, 0x02}
+	got := CalculateL1GasUsed(data)
+	expect := new(big.Int).SetUint64(2*params.TxDataZeroGas + 2*params.TxDataNonZeroGasEIP2028 + Overhead)
+	if got.Cmp(expect) != 0 {
+		t.Fatalf("mismatched L1 gas used: got %d, expect %d", got, expect)
+	}
+}
+
 var feeTests = map[string]struct {
 	dataLen    int
 	l1GasPrice uint64
@@ -202,3 +217,114 @@ func TestPaysEnough(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculatorAllocs(t *testing.T) {
+	data := make([]byte, 512)
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(params.GWei)
+	l2GasLimit := big.NewInt(1_234_567)
+	expect := EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)
+
+	var c Calculator
+	z := new(big.Int)
+	allocs := testing.AllocsPerRun(100, func() {
+		c.EncodeTxGasLimit(z, data, l1GasPrice, l2GasLimit, l2GasPrice)
+	})
+	if allocs != 0 {
+		t.Fatalf("calculator allocated %v times per call", allocs)
+	}
+	if z.Cmp(expect) != 0 {
+		t.Fatalf("mismatched gas limit: got %d, expect %d", z, expect)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { CalculateL1GasUsedU64(data) }); allocs != 0 {
+		t.Fatalf("L1 gas used allocated %v times per call", allocs)
+	}
+}
+
+func TestMaxChargedL1Fee(t *testing.T) {
+	for _, l1GasPrice := range []int64{0, 1, 1_000_000_000, 300_000_000_000} {
+		for _, l2GasPrice := range []int64{1, 15_000_000, 1_000_000_000} {
+			for _, size := range []int{0, 100, 10_000} {
+				data := make([]byte, size)
+				l2GasLimit := big.NewInt(8_000_000)
+				gasLimit := EncodeTxGasLimit(data, big.NewInt(l1GasPrice), l2GasLimit, big.NewInt(l2GasPrice))
+				l1Fee := new(big.Int).Mul(CalculateL1GasUsed(data), big.NewInt(l1GasPrice))
+				charged := MaxChargedL1Fee(gasLimit.Uint64(), big.NewInt(l2GasPrice))
+				// The charged L1 fee is an upper bound within the rounding of
+				// the encoding
+				if charged.Cmp(l1Fee) < 0 {
+					t.Fatalf("l1 %d l2 %d size %d: charged L1 fee %d below L1 fee %d", l1GasPrice, l2GasPrice, size, charged, l1Fee)
+				}
+				slack := new(big.Int).Mul(BigTenThousand, bigFeeScalar)
+				slack.Add(slack, bigFeeScalar)
+				if new(big.Int).Sub(charged, l1Fee).Cmp(slack) > 0 {
+					t.Fatalf("l1 %d l2 %d size %d: charged L1 fee %d too far above L1 fee %d", l1GasPrice, l2GasPrice, size, charged, l1Fee)
+				}
+			}
+		}
+	}
+}
+
+func TestMaxChargedL1GasPrice(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	l1GasUsed := CalculateL1GasUsedU64(data)
+	l2GasLimit, l2GasPrice := big.NewInt(100_000), big.NewInt(15_000_000)
+	for _, l1GasPrice := range []int64{1_000_000_000, 30_000_000_000, 300_000_000_000} {
+		gasLimit := EncodeTxGasLimit(data, big.NewInt(l1GasPrice), l2GasLimit, l2GasPrice)
+		charged := MaxChargedL1GasPrice(gasLimit.Uint64(), l1GasUsed, l2GasPrice)
+		if charged == nil || charged.Int64() < l1GasPrice {
+			t.Fatalf("l1 %d: charged L1 gas price %v below L1 gas price", l1GasPrice, charged)
+		}
+		// Within the rounding of the encoding
+		if slack := charged.Int64() - l1GasPrice; slack*100 > l1GasPrice {
+			t.Fatalf("l1 %d: charged L1 gas price %d too far above L1 gas price", l1GasPrice, charged)
+		}
+	}
+	if charged := MaxChargedL1GasPrice(21000, 0, l2GasPrice); charged != nil {
+		t.Fatalf("charged L1 gas price without L1 gas: %d", charged)
+	}
+}
+
+func TestCalldataGasSchedule(t *testing.T) {
+	data := []byte{0, 0, 1, 2, 3}
+	if got, expect := DefaultCalldataGas.CalculateL1GasUsed(data), CalculateL1GasUsed(data); got.Cmp(expect) != 0 {
+		t.Fatalf("mismatched default l1 gas used: got %d, expect %d", got, expect)
+	}
+	repriced := CalldataGas{Zero: 1, NonZero: 2}
+	if got, expect := repriced.CalculateL1GasUsed(data).Uint64(), 2*1+3*2+Overhead; got != expect {
+		t.Fatalf("mismatched repriced l1 gas used: got %d, expect %d", got, expect)
+	}
+	l1GasPrice, l2GasLimit, l2GasPrice := big.NewInt(1_000_000_000), big.NewInt(100_000), big.NewInt(1)
+	if got, expect := DefaultCalldataGas.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice), EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice); got.Cmp(expect) != 0 {
+		t.Fatalf("mismatched default gas limit: got %d, expect %d", got, expect)
+	}
+	if repriced.EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice).Cmp(EncodeTxGasLimit(data, l1GasPrice, l2GasLimit, l2GasPrice)) >= 0 {
+		t.Fatal("expected cheaper calldata to lower the gas limit")
+	}
+}
+
+func TestCalldataGasMinSize(t *testing.T) {
+	g := CalldataGas{Zero: 4, NonZero: 16, MinSize: 100}
+	tests := map[string]struct {
+		zeroes, nonZeroes uint64
+		expect            uint64
+	}{
+		"empty":        {0, 0, 100*16 + Overhead},
+		"zeroes-kept":  {10, 0, 10*4 + 90*16 + Overhead},
+		"at-floor":     {50, 50, 50*4 + 50*16 + Overhead},
+		"above-floor":  {0, 200, 200*16 + Overhead},
+		"padded-mixed": {20, 30, 20*4 + 80*16 + Overhead},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := g.L1GasUsed(tt.zeroes, tt.nonZeroes); got != tt.expect {
+				t.Fatalf("mismatched l1 gas used: got %d, expect %d", got, tt.expect)
+			}
+		})
+	}
+	if got, expect := (CalldataGas{Zero: 4, NonZero: 16}).L1GasUsed(0, 0), Overhead; got != expect {
+		t.Fatalf("mismatched l1 gas used without floor: got %d, expect %d", got, expect)
+	}
+}
@@ -0,0 +1,32 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+)
+
+// MaxL1FeeScalarDecimals is the largest number of decimals of the scalar of
+// the L1 fee in the gas price oracle
+const MaxL1FeeScalarDecimals = 18
+
+// ErrL1FeeScalarDecimals represents the error case of a scalar of the L1 fee
+// with more than MaxL1FeeScalarDecimals decimals
+var ErrL1FeeScalarDecimals = errors.New("too many decimals of the L1 fee scalar")
+
+var bigTen = big.NewInt(10)
+
+// ScaledL1Fee returns the L1 gas used and the L1 fee of calldata with the L1
+// gas of its data, at the overhead, the L1 gas price and the scalar with its
+// decimals that the gas price oracle stores. The L1 fee precompile and the
+// fee assertion of verifiers compute the L1 fee from the state with it.
+func ScaledL1Fee(dataGas uint64, overhead, l1GasPrice, scalar, decimals *big.Int) (*big.Int, *big.Int, error) {
+	if !decimals.IsUint64() || decimals.Uint64() > MaxL1FeeScalarDecimals {
+		return nil, nil, ErrL1FeeScalarDecimals
+	}
+	l1GasUsed := new(big.Int).SetUint64(dataGas)
+	l1GasUsed.Add(l1GasUsed, overhead)
+	l1Fee := new(big.Int).Mul(l1GasUsed, l1GasPrice)
+	l1Fee.Mul(l1Fee, scalar)
+	l1Fee.Quo(l1Fee, new(big.Int).Exp(bigTen, decimals, nil))
+	return l1GasUsed, l1Fee, nil
+}
@@ -0,0 +1,84 @@
+package fees
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// reportedPercentiles are the percentiles of the fee distributions that are
+// reported
+var reportedPercentiles = []int{10, 25, 50, 75, 90, 99}
+
+// FeeParams are hypothetical gas price oracle parameters
+type FeeParams struct {
+	L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	// Scalar multiplies the L1 fee, 1 when not set
+	Scalar *float64 `json:"scalar,omitempty"`
+	// Overhead is the fixed L1 gas charged per transaction, Overhead when
+	// not set
+	Overhead *hexutil.Uint64 `json:"overhead,omitempty"`
+}
+
+// SimulationTx is the part of a transaction that its fee depends on
+type SimulationTx struct {
+	Data       []byte
+	L2GasLimit uint64
+}
+
+// FeeDistribution describes the fees paid by a set of transactions
+type FeeDistribution struct {
+	Count       int                     `json:"count"`
+	Total       *hexutil.Big            `json:"total"`
+	Mean        *hexutil.Big            `json:"mean"`
+	Min         *hexutil.Big            `json:"min"`
+	Max         *hexutil.Big            `json:"max"`
+	Percentiles map[string]*hexutil.Big `json:"percentiles"`
+	Fees        []*hexutil.Big          `json:"fees"`
+}
+
+// Fee returns the fee that a transaction pays under the parameters. The fee
+// is rounded the same way as the fee encoded in `tx.gasLimit`, so with the
+// default scalar and overhead it equals the fee expected by the sequencer.
+func (p *FeeParams) Fee(tx SimulationTx) *big.Int {
+	overhead := Overhead
+	if p.Overhead != nil {
+		overhead = uint64(*p.Overhead)
+	}
+	l1Fee := new(big.Int).Mul(p.L1GasPrice.ToInt(), calculateL1GasLimit(tx.Data, overhead))
+	if p.Scalar != nil {
+		l1Fee = mulByFloatCeil(l1Fee, big.NewFloat(*p.Scalar))
+	}
+	l2GasLimit := new(big.Int).SetUint64(tx.L2GasLimit)
+	gasLimit := EncodeTxGasLimitForL1Fee(l1Fee, l2GasLimit, p.L2GasPrice.ToInt())
+	return gasLimit.Mul(gasLimit, BigTxGasPrice)
+}
+
+// Simulate computes the distribution of the fees that the transactions pay
+// under the parameters, so that changes to the parameters can be evaluated
+// before they are made. The fees are returned in the order of txs.
+func Simulate(params *FeeParams, txs []SimulationTx) *FeeDistribution {
+	dist := &FeeDistribution{
+		Count:       len(txs),
+		Percentiles: make(map[string]*hexutil.Big),
+		Fees:        make([]*hexutil.Big, len(txs)),
+	}
+	if len(txs) == 0 {
+		return dist
+	}
+	total := new(big.Int)
+	sorted := make([]*big.Int, len(txs))
+	for i, tx := range txs {
+		fee := params.Fee(tx)
+		dist.Fees[i] = (*hexutil.Big)(fee)
+		sorted[i] = fee
+		total.Add(total, fee)
+	}
+	dist.Total = (*hexutil.Big)(total)
+	dist.Mean = (*hexutil.Big)(new(big.Int).Div(total, big.NewInt(int64(len(txs)))))
+	dist.Percentiles = percentiles(sorted)
+	dist.Min = (*hexutil.Big)(sorted[0])
+	dist.Max = (*hexutil.Big)(sorted[len(sorted)-1])
+	return dist
+}
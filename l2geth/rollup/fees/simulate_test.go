@@ -0,0 +1,72 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestFeeParamsFee(t *testing.T) {
+	l1GasPrice := big.NewInt(100_000_000_000)
+	l2GasPrice := big.NewInt(15_000_000)
+	tx := SimulationTx{Data: make([]byte, 100), L2GasLimit: 21000}
+	l2GasLimit := new(big.Int).SetUint64(tx.L2GasLimit)
+	expect := new(big.Int).Mul(EncodeTxGasLimit(tx.Data, l1GasPrice, l2GasLimit, l2GasPrice), BigTxGasPrice)
+
+	params := &FeeParams{
+		L1GasPrice: (*hexutil.Big)(l1GasPrice),
+		L2GasPrice: (*hexutil.Big)(l2GasPrice),
+	}
+	if fee := params.Fee(tx); fee.Cmp(expect) != 0 {
+		t.Fatalf("mismatched default fee: got %d, expect %d", fee, expect)
+	}
+	scalar := 2.0
+	params.Scalar = &scalar
+	if fee := params.Fee(tx); fee.Cmp(expect) != 1 {
+		t.Fatalf("scaled fee %d not greater than %d", fee, expect)
+	}
+	overhead := hexutil.Uint64(0)
+	params.Scalar = nil
+	params.Overhead = &overhead
+	if fee := params.Fee(tx); fee.Cmp(expect) != -1 {
+		t.Fatalf("fee without overhead %d not less than %d", fee, expect)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	params := &FeeParams{
+		L1GasPrice: (*hexutil.Big)(big.NewInt(0)),
+		L2GasPrice: (*hexutil.Big)(big.NewInt(1_000_000_000)),
+	}
+	// Without an L1 fee the fee only depends on the L2 gas limit, one
+	// hundred transactions with increasing gas limits
+	txs := make([]SimulationTx, 100)
+	for i := range txs {
+		txs[i] = SimulationTx{L2GasLimit: uint64(100-i) * 10000}
+	}
+	dist := Simulate(params, txs)
+	if dist.Count != 100 || len(dist.Fees) != 100 {
+		t.Fatalf("unexpected count: %d", dist.Count)
+	}
+	fee := func(i int) *big.Int { return params.Fee(SimulationTx{L2GasLimit: uint64(i) * 10000}) }
+	if dist.Min.ToInt().Cmp(fee(1)) != 0 {
+		t.Fatalf("mismatched min: got %d, expect %d", dist.Min.ToInt(), fee(1))
+	}
+	if dist.Max.ToInt().Cmp(fee(100)) != 0 {
+		t.Fatalf("mismatched max: got %d, expect %d", dist.Max.ToInt(), fee(100))
+	}
+	for name, rank := range map[string]int{"p10": 10, "p50": 50, "p99": 99} {
+		if dist.Percentiles[name].ToInt().Cmp(fee(rank)) != 0 {
+			t.Fatalf("mismatched %s: got %d, expect %d", name, dist.Percentiles[name].ToInt(), fee(rank))
+		}
+	}
+	// The fees are returned in the order of the transactions
+	if dist.Fees[0].ToInt().Cmp(fee(100)) != 0 {
+		t.Fatalf("fees not in transaction order")
+	}
+
+	if empty := Simulate(params, nil); empty.Count != 0 || empty.Total != nil {
+		t.Fatal("unexpected distribution of no transactions")
+	}
+}
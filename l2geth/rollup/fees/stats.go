@@ -0,0 +1,267 @@
+package fees
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BlockFees are the fee components of the transactions in a block. They are
+// recorded when the block is imported so that fee statistics can be computed
+// without scanning the chain.
+type BlockFees struct {
+	Number       uint64
+	Timestamp    uint64
+	Transactions uint64
+	// Fee is the sum of the gas used times the gas price of the transactions
+	Fee          *big.Int
+	L1GasUsed    uint64
+	L2GasUsed    uint64
+	CalldataSize uint64
+	// Contracts are the fee components of the transactions to each
+	// contract, in order of their first transaction. Blocks recorded before
+	// they were tracked have none.
+	Contracts []*ContractFees `rlp:"tail"`
+}
+
+// ContractFees are the fee components of the transactions to a contract in
+// a block
+type ContractFees struct {
+	Address      common.Address
+	Transactions uint64
+	Fee          *big.Int
+	L1GasUsed    uint64
+	CalldataSize uint64
+}
+
+// NewBlockFees creates an empty BlockFees for a block
+func NewBlockFees(number, timestamp uint64) *BlockFees {
+	return &BlockFees{
+		Number:    number,
+		Timestamp: timestamp,
+		Fee:       new(big.Int),
+	}
+}
+
+// Add records the fee components of a transaction. Transactions that do not
+// pay a fee, such as deposits from L1, are not recorded.
+func (b *BlockFees) Add(data []byte, gasUsed uint64, gasPrice *big.Int) {
+	b.AddL1Gas(CalculateL1GasUsedU64(data), uint64(len(data)), gasUsed, gasPrice)
+}
+
+// AddL1Gas is Add for a transaction whose L1 gas has already been computed
+func (b *BlockFees) AddL1Gas(l1GasUsed, calldataSize, gasUsed uint64, gasPrice *big.Int) {
+	if gasPrice.Sign() == 0 {
+		return
+	}
+	b.Transactions++
+	c := calculators.Get().(*Calculator)
+	c.sum.SetUint64(gasUsed)
+	b.Fee.Add(b.Fee, c.sum.Mul(&c.sum, gasPrice))
+	calculators.Put(c)
+	b.L1GasUsed += l1GasUsed
+	b.L2GasUsed += gasUsed
+	b.CalldataSize += calldataSize
+}
+
+// AddL1GasTo is AddL1Gas for a transaction to a contract, which is recorded
+// for the contract as well
+func (b *BlockFees) AddL1GasTo(to common.Address, l1GasUsed, calldataSize, gasUsed uint64, gasPrice *big.Int) {
+	if gasPrice.Sign() == 0 {
+		return
+	}
+	b.AddL1Gas(l1GasUsed, calldataSize, gasUsed, gasPrice)
+	var contract *ContractFees
+	for _, c := range b.Contracts {
+		if c.Address == to {
+			contract = c
+			break
+		}
+	}
+	if contract == nil {
+		contract = &ContractFees{Address: to, Fee: new(big.Int)}
+		b.Contracts = append(b.Contracts, contract)
+	}
+	contract.Transactions++
+	contract.Fee.Add(contract.Fee, new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice))
+	contract.L1GasUsed += l1GasUsed
+	contract.CalldataSize += calldataSize
+}
+
+// BlockFeesResult is the JSON encoding of BlockFees
+type BlockFeesResult struct {
+	Number       hexutil.Uint64 `json:"number"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	Transactions hexutil.Uint64 `json:"transactions"`
+	Fee          *hexutil.Big   `json:"fee"`
+	L1GasUsed    hexutil.Uint64 `json:"l1GasUsed"`
+	L2GasUsed    hexutil.Uint64 `json:"l2GasUsed"`
+	CalldataSize hexutil.Uint64 `json:"calldataSize"`
+	// Contracts are the fee components of the transactions to each contract
+	Contracts []*ContractFeesResult `json:"contracts,omitempty"`
+}
+
+// ContractFeesResult is the JSON encoding of the fee components of the
+// transactions to a contract
+type ContractFeesResult struct {
+	Address      common.Address `json:"address"`
+	Transactions hexutil.Uint64 `json:"transactions"`
+	Fee          *hexutil.Big   `json:"fee"`
+	L1GasUsed    hexutil.Uint64 `json:"l1GasUsed"`
+	CalldataSize hexutil.Uint64 `json:"calldataSize"`
+}
+
+// Result returns the JSON encoding of the fee components
+func (b *BlockFees) Result() *BlockFeesResult {
+	result := &BlockFeesResult{
+		Number:       hexutil.Uint64(b.Number),
+		Timestamp:    hexutil.Uint64(b.Timestamp),
+		Transactions: hexutil.Uint64(b.Transactions),
+		Fee:          (*hexutil.Big)(b.Fee),
+		L1GasUsed:    hexutil.Uint64(b.L1GasUsed),
+		L2GasUsed:    hexutil.Uint64(b.L2GasUsed),
+		CalldataSize: hexutil.Uint64(b.CalldataSize),
+	}
+	for _, c := range b.Contracts {
+		result.Contracts = append(result.Contracts, &ContractFeesResult{
+			Address:      c.Address,
+			Transactions: hexutil.Uint64(c.Transactions),
+			Fee:          (*hexutil.Big)(c.Fee),
+			L1GasUsed:    hexutil.Uint64(c.L1GasUsed),
+			CalldataSize: hexutil.Uint64(c.CalldataSize),
+		})
+	}
+	return result
+}
+
+// FeeStats aggregates the fee components of a range of blocks
+type FeeStats struct {
+	FromBlock      hexutil.Uint64          `json:"fromBlock"`
+	ToBlock        hexutil.Uint64          `json:"toBlock"`
+	FromTimestamp  hexutil.Uint64          `json:"fromTimestamp"`
+	ToTimestamp    hexutil.Uint64          `json:"toTimestamp"`
+	Blocks         hexutil.Uint64          `json:"blocks"`
+	Transactions   hexutil.Uint64          `json:"transactions"`
+	TotalFee       *hexutil.Big            `json:"totalFee"`
+	L1GasUsed      hexutil.Uint64          `json:"l1GasUsed"`
+	L2GasUsed      hexutil.Uint64          `json:"l2GasUsed"`
+	CalldataSize   hexutil.Uint64          `json:"calldataSize"`
+	FeePercentiles map[string]*hexutil.Big `json:"feePercentiles"`
+}
+
+// AggregateFees computes the statistics of the fees of the blocks. The fee
+// percentiles are over the blocks with transactions that pay a fee.
+func AggregateFees(blocks []*BlockFees) *FeeStats {
+	stats := &FeeStats{
+		TotalFee:       (*hexutil.Big)(new(big.Int)),
+		FeePercentiles: make(map[string]*hexutil.Big),
+	}
+	var fees []*big.Int
+	for i, b := range blocks {
+		if i == 0 {
+			stats.FromBlock = hexutil.Uint64(b.Number)
+			stats.FromTimestamp = hexutil.Uint64(b.Timestamp)
+		}
+		stats.ToBlock = hexutil.Uint64(b.Number)
+		stats.ToTimestamp = hexutil.Uint64(b.Timestamp)
+		stats.Blocks++
+		stats.Transactions += hexutil.Uint64(b.Transactions)
+		stats.TotalFee.ToInt().Add(stats.TotalFee.ToInt(), b.Fee)
+		stats.L1GasUsed += hexutil.Uint64(b.L1GasUsed)
+		stats.L2GasUsed += hexutil.Uint64(b.L2GasUsed)
+		stats.CalldataSize += hexutil.Uint64(b.CalldataSize)
+		if b.Transactions > 0 {
+			fees = append(fees, b.Fee)
+		}
+	}
+	stats.FeePercentiles = percentiles(fees)
+	return stats
+}
+
+// percentiles returns the nearest rank percentiles of the values, which are
+// sorted in place
+func percentiles(values []*big.Int) map[string]*hexutil.Big {
+	result := make(map[string]*hexutil.Big)
+	if len(values) == 0 {
+		return result
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	for _, p := range reportedPercentiles {
+		rank := (p*len(values) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		result["p"+strconv.Itoa(p)] = (*hexutil.Big)(values[rank-1])
+	}
+	return result
+}
+
+// AggregateFeesByInterval computes the statistics of the fees of the blocks
+// in buckets of interval seconds, aligned to multiples of the interval. All
+// of the blocks are aggregated together when the interval is zero. The
+// blocks must be ordered by number.
+func AggregateFeesByInterval(blocks []*BlockFees, interval uint64) []*FeeStats {
+	if interval == 0 {
+		return []*FeeStats{AggregateFees(blocks)}
+	}
+	var (
+		stats  []*FeeStats
+		start  int
+		bucket uint64
+	)
+	for i, b := range blocks {
+		if i == 0 {
+			bucket = b.Timestamp / interval
+			continue
+		}
+		if b.Timestamp/interval != bucket {
+			stats = append(stats, AggregateFees(blocks[start:i]))
+			start = i
+			bucket = b.Timestamp / interval
+		}
+	}
+	if start < len(blocks) {
+		stats = append(stats, AggregateFees(blocks[start:]))
+	}
+	return stats
+}
+
+// WriteFeeStatsCSV writes the statistics with one row per entry
+func WriteFeeStatsCSV(w io.Writer, stats []*FeeStats) error {
+	out := csv.NewWriter(w)
+	header := []string{"fromBlock", "toBlock", "fromTimestamp", "toTimestamp", "blocks", "transactions",
+		"totalFee", "l1GasUsed", "l2GasUsed", "calldataSize"}
+	for _, p := range reportedPercentiles {
+		header = append(header, "feeP"+strconv.Itoa(p))
+	}
+	out.Write(header)
+	for _, s := range stats {
+		row := []string{
+			strconv.FormatUint(uint64(s.FromBlock), 10),
+			strconv.FormatUint(uint64(s.ToBlock), 10),
+			strconv.FormatUint(uint64(s.FromTimestamp), 10),
+			strconv.FormatUint(uint64(s.ToTimestamp), 10),
+			strconv.FormatUint(uint64(s.Blocks), 10),
+			strconv.FormatUint(uint64(s.Transactions), 10),
+			s.TotalFee.ToInt().String(),
+			strconv.FormatUint(uint64(s.L1GasUsed), 10),
+			strconv.FormatUint(uint64(s.L2GasUsed), 10),
+			strconv.FormatUint(uint64(s.CalldataSize), 10),
+		}
+		for _, p := range reportedPercentiles {
+			value := ""
+			if fee, ok := s.FeePercentiles["p"+strconv.Itoa(p)]; ok {
+				value = fee.ToInt().String()
+			}
+			row = append(row, value)
+		}
+		out.Write(row)
+	}
+	out.Flush()
+	return out.Error()
+}
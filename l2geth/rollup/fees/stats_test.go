@@ -0,0 +1,149 @@
+package fees
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func newTestBlockFees(number, timestamp uint64, fees ...int64) *BlockFees {
+	b := NewBlockFees(number, timestamp)
+	for _, fee := range fees {
+		b.Add([]byte{0x00, 0x01}, 1, big.NewInt(fee))
+	}
+	return b
+}
+
+func TestAggregateFees(t *testing.T) {
+	blocks := []*BlockFees{
+		newTestBlockFees(1, 10, 100),
+		newTestBlockFees(2, 11, 0),
+		newTestBlockFees(3, 12, 200, 300),
+		newTestBlockFees(4, 13, 50),
+	}
+	stats := AggregateFees(blocks)
+	if stats.FromBlock != 1 || stats.ToBlock != 4 || stats.FromTimestamp != 10 || stats.ToTimestamp != 13 {
+		t.Fatalf("mismatched range: %d-%d", stats.FromBlock, stats.ToBlock)
+	}
+	if stats.Blocks != 4 || stats.Transactions != 4 {
+		t.Fatalf("mismatched counts: %d blocks, %d transactions", stats.Blocks, stats.Transactions)
+	}
+	if stats.TotalFee.ToInt().Int64() != 650 {
+		t.Fatalf("mismatched total fee: %s", stats.TotalFee.ToInt())
+	}
+	expect := CalculateL1GasUsed([]byte{0x00, 0x01}).Uint64() * 4
+	if uint64(stats.L1GasUsed) != expect {
+		t.Fatalf("mismatched L1 gas used: got %d, expect %d", stats.L1GasUsed, expect)
+	}
+	if stats.L2GasUsed != 4 || stats.CalldataSize != 8 {
+		t.Fatalf("mismatched L2 gas used %d or calldata size %d", stats.L2GasUsed, stats.CalldataSize)
+	}
+	// The block without fee paying transactions is not part of the percentiles
+	tests := map[string]int64{"p10": 50, "p50": 100, "p90": 500, "p99": 500}
+	for p, fee := range tests {
+		if got := stats.FeePercentiles[p]; got == nil || got.ToInt().Int64() != fee {
+			t.Fatalf("mismatched %s: got %v, expect %d", p, got, fee)
+		}
+	}
+}
+
+func TestAggregateFeesByInterval(t *testing.T) {
+	blocks := []*BlockFees{
+		newTestBlockFees(1, 120, 1),
+		newTestBlockFees(2, 179, 2),
+		newTestBlockFees(3, 180, 3),
+		newTestBlockFees(4, 300, 4),
+	}
+	tests := map[string]struct {
+		interval uint64
+		buckets  []uint64
+	}{
+		"none":   {0, []uint64{4}},
+		"minute": {60, []uint64{2, 1, 1}},
+		"hour":   {3600, []uint64{4}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stats := AggregateFeesByInterval(blocks, tt.interval)
+			if len(stats) != len(tt.buckets) {
+				t.Fatalf("mismatched number of buckets: got %d, expect %d", len(stats), len(tt.buckets))
+			}
+			for i, s := range stats {
+				if uint64(s.Blocks) != tt.buckets[i] {
+					t.Fatalf("mismatched blocks in bucket %d: got %d, expect %d", i, s.Blocks, tt.buckets[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFeeStatsCSV(t *testing.T) {
+	stats := AggregateFeesByInterval([]*BlockFees{
+		newTestBlockFees(1, 100, 10),
+		newTestBlockFees(2, 200),
+	}, 100)
+
+	var out bytes.Buffer
+	if err := WriteFeeStatsCSV(&out, stats); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("mismatched number of lines: %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], "1,1,100,100,1,1,10,") || !strings.HasSuffix(lines[1], ",10") {
+		t.Fatalf("mismatched row: %s", lines[1])
+	}
+	// A bucket without fee paying transactions has no percentiles
+	if !strings.HasSuffix(lines[2], ",,") {
+		t.Fatalf("mismatched empty row: %s", lines[2])
+	}
+}
+
+func TestBlockFeesContracts(t *testing.T) {
+	dex, bridge := common.Address{0x01}, common.Address{0x02}
+	b := NewBlockFees(1, 10)
+	b.AddL1GasTo(dex, 1000, 100, 21000, big.NewInt(2))
+	b.AddL1GasTo(bridge, 500, 50, 30000, big.NewInt(1))
+	b.AddL1GasTo(dex, 2000, 200, 21000, big.NewInt(1))
+	b.AddL1GasTo(bridge, 500, 50, 30000, new(big.Int))
+	b.AddL1Gas(100, 10, 53000, big.NewInt(1))
+
+	if b.Transactions != 4 || b.Fee.Int64() != 146000 {
+		t.Fatalf("mismatched block: %d transactions, fee %d", b.Transactions, b.Fee)
+	}
+	if len(b.Contracts) != 2 || b.Contracts[0].Address != dex || b.Contracts[1].Address != bridge {
+		t.Fatalf("mismatched contracts: %v", b.Contracts)
+	}
+	c := b.Contracts[0]
+	if c.Transactions != 2 || c.Fee.Int64() != 63000 || c.L1GasUsed != 3000 || c.CalldataSize != 300 {
+		t.Fatalf("mismatched contract: %d transactions, fee %d, L1 gas used %d, calldata size %d",
+			c.Transactions, c.Fee, c.L1GasUsed, c.CalldataSize)
+	}
+	if result := b.Result(); len(result.Contracts) != 2 || result.Contracts[1].Fee.ToInt().Int64() != 30000 {
+		t.Fatalf("mismatched result contracts: %v", result.Contracts)
+	}
+
+	// Block fees that were recorded before contracts were tracked decode
+	// without contracts
+	legacy := struct {
+		Number, Timestamp, Transactions    uint64
+		Fee                                *big.Int
+		L1GasUsed, L2GasUsed, CalldataSize uint64
+	}{1, 10, 1, big.NewInt(42000), 1000, 21000, 100}
+	enc, err := rlp.EncodeToBytes(&legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded BlockFees
+	if err := rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Fee.Int64() != 42000 || len(decoded.Contracts) != 0 {
+		t.Fatalf("mismatched legacy block fees: fee %d, %d contracts", decoded.Fee, len(decoded.Contracts))
+	}
+}
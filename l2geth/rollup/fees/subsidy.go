@@ -0,0 +1,30 @@
+package fees
+
+import "math/big"
+
+const (
+	// FeeSubsidyRateDenominator is the denominator of subsidy rates, which
+	// are expressed in basis points
+	FeeSubsidyRateDenominator = 10000
+	// MaxFeeSubsidyRate is the largest fraction of the L1 fee that is
+	// subsidized, so that users always pay a part of the L1 fee.
+	MaxFeeSubsidyRate = 9000
+)
+
+var bigFeeSubsidyRateDenominator = big.NewInt(FeeSubsidyRateDenominator)
+
+// SubsidizeL1Fee returns the L1 fee that is charged to the user after the
+// subsidy at the rate, and the subsidy. A rate above MaxFeeSubsidyRate is
+// reduced to it. The fee is only subsidized when the balance covers the
+// whole subsidy, otherwise the user is charged the full fee.
+func SubsidizeL1Fee(l1Fee *big.Int, rate uint64, balance *big.Int) (*big.Int, *big.Int) {
+	if rate > MaxFeeSubsidyRate {
+		rate = MaxFeeSubsidyRate
+	}
+	subsidy := new(big.Int).Mul(l1Fee, new(big.Int).SetUint64(rate))
+	subsidy.Quo(subsidy, bigFeeSubsidyRateDenominator)
+	if subsidy.Sign() <= 0 || balance == nil || balance.Cmp(subsidy) < 0 {
+		return new(big.Int).Set(l1Fee), new(big.Int)
+	}
+	return new(big.Int).Sub(l1Fee, subsidy), subsidy
+}
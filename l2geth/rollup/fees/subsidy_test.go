@@ -0,0 +1,33 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSubsidizeL1Fee(t *testing.T) {
+	tests := map[string]struct {
+		l1Fee   int64
+		rate    uint64
+		balance int64
+		charged int64
+		subsidy int64
+	}{
+		"no-rate":          {1000, 0, 1000, 1000, 0},
+		"half":             {1000, 5000, 1000, 500, 500},
+		"capped-rate":      {1000, 10000, 1000, 100, 900},
+		"balance-too-low":  {1000, 5000, 499, 1000, 0},
+		"balance-is-exact": {1000, 5000, 500, 500, 500},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			charged, subsidy := SubsidizeL1Fee(big.NewInt(tt.l1Fee), tt.rate, big.NewInt(tt.balance))
+			if charged.Int64() != tt.charged {
+				t.Fatalf("mismatched charged fee: got %d, expect %d", charged, tt.charged)
+			}
+			if subsidy.Int64() != tt.subsidy {
+				t.Fatalf("mismatched subsidy: got %d, expect %d", subsidy, tt.subsidy)
+			}
+		})
+	}
+}
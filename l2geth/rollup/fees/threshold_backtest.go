@@ -0,0 +1,83 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// FeeCheckRecord is the input of a fee check as recorded by the sequencer.
+// It holds no hash, sender or recipient of the transaction so that recorded
+// traffic can be shared to tune the fee thresholds.
+type FeeCheckRecord struct {
+	Time        uint64       `json:"time"`
+	UserFee     *hexutil.Big `json:"userFee"`
+	ExpectedFee *hexutil.Big `json:"expectedFee"`
+	// The thresholds of the fee policy of the transaction, set only when
+	// the policy overrides the configured thresholds
+	ThresholdUp   *float64 `json:"thresholdUp,omitempty"`
+	ThresholdDown *float64 `json:"thresholdDown,omitempty"`
+}
+
+// Thresholds are candidate values of the fee thresholds. Without a threshold
+// up overpaying is not checked, without a threshold down the full expected fee
+// must be paid, the same as when they are not configured in the sequencer.
+type Thresholds struct {
+	Up   *float64 `json:"thresholdUp"`
+	Down *float64 `json:"thresholdDown"`
+}
+
+// ThresholdBacktest is the number of recorded fee checks that would have
+// been rejected under a setting of the fee thresholds
+type ThresholdBacktest struct {
+	Thresholds
+	Checks   int `json:"checks"`
+	TooLow   int `json:"tooLow"`
+	TooHigh  int `json:"tooHigh"`
+	Rejected int `json:"rejected"`
+}
+
+// BacktestThresholds replays the recorded fee checks against each candidate
+// setting of the fee thresholds. The thresholds of a fee policy that were
+// recorded with a check take precedence over the candidate, the same way as
+// they do in the sequencer.
+func BacktestThresholds(records []*FeeCheckRecord, candidates []Thresholds) []*ThresholdBacktest {
+	results := make([]*ThresholdBacktest, 0, len(candidates))
+	for _, candidate := range candidates {
+		result := &ThresholdBacktest{Thresholds: candidate}
+		for _, record := range records {
+			if record.UserFee == nil || record.ExpectedFee == nil {
+				continue
+			}
+			up, down := candidate.Up, candidate.Down
+			if record.ThresholdUp != nil {
+				up = record.ThresholdUp
+			}
+			if record.ThresholdDown != nil {
+				down = record.ThresholdDown
+			}
+			opts := PaysEnoughOpts{
+				UserFee:     record.UserFee.ToInt(),
+				ExpectedFee: record.ExpectedFee.ToInt(),
+			}
+			if up != nil {
+				opts.ThresholdUp = big.NewFloat(*up)
+			}
+			if down != nil {
+				opts.ThresholdDown = big.NewFloat(*down)
+			}
+			result.Checks++
+			switch err := PaysEnough(&opts); {
+			case errors.Is(err, ErrFeeTooLow):
+				result.TooLow++
+				result.Rejected++
+			case errors.Is(err, ErrFeeTooHigh):
+				result.TooHigh++
+				result.Rejected++
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,64 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestBacktestThresholds(t *testing.T) {
+	float := func(f float64) *float64 { return &f }
+	record := func(userFee, expectedFee int64) *FeeCheckRecord {
+		return &FeeCheckRecord{
+			UserFee:     (*hexutil.Big)(big.NewInt(userFee)),
+			ExpectedFee: (*hexutil.Big)(big.NewInt(expectedFee)),
+		}
+	}
+	policy := record(1000, 500)
+	policy.ThresholdUp = float(1)
+	records := []*FeeCheckRecord{
+		record(800, 1000),
+		record(950, 1000),
+		record(1000, 1000),
+		record(2500, 1000),
+		record(4500, 1000),
+		policy,
+		// Records without fees did not reach the fee check
+		{Time: 1},
+	}
+
+	tests := map[string]struct {
+		thresholds Thresholds
+		tooLow     int
+		tooHigh    int
+	}{
+		"unset":      {Thresholds{}, 2, 0},
+		"exact":      {Thresholds{Up: float(0), Down: float(1)}, 2, 2},
+		"down":       {Thresholds{Down: float(0.9)}, 1, 0},
+		"up":         {Thresholds{Up: float(3)}, 2, 1},
+		"up-policy":  {Thresholds{Up: float(0.5)}, 2, 2},
+		"boundaries": {Thresholds{Up: float(1.5), Down: float(0.8)}, 0, 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			results := BacktestThresholds(records, []Thresholds{tt.thresholds})
+			if len(results) != 1 {
+				t.Fatalf("mismatched results: got %d, expect 1", len(results))
+			}
+			result := results[0]
+			if result.Checks != 6 {
+				t.Fatalf("mismatched checks: got %d, expect 6", result.Checks)
+			}
+			if result.TooLow != tt.tooLow {
+				t.Fatalf("mismatched too low: got %d, expect %d", result.TooLow, tt.tooLow)
+			}
+			if result.TooHigh != tt.tooHigh {
+				t.Fatalf("mismatched too high: got %d, expect %d", result.TooHigh, tt.tooHigh)
+			}
+			if result.Rejected != tt.tooLow+tt.tooHigh {
+				t.Fatalf("mismatched rejected: got %d, expect %d", result.Rejected, tt.tooLow+tt.tooHigh)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package fees
+
+// ThresholdDecision is an adjustment of the fee threshold down by the
+// controller that keeps the false rejection rate under its target. A false
+// rejection is a transaction that was rejected for paying too little although
+// its fee covered the L1 gas price that was observed after it was rejected.
+type ThresholdDecision struct {
+	Time            uint64  `json:"time"`
+	Checks          uint64  `json:"checks"`
+	FalseRejections uint64  `json:"falseRejections"`
+	Rate            float64 `json:"rate"`
+	From            float64 `json:"from"`
+	To              float64 `json:"to"`
+	// Action is widen when the threshold was lowered, narrow when it was
+	// raised and hold when it was kept, including when a guardrail stopped
+	// it from moving
+	Action string `json:"action"`
+}
+
+// ThresholdControl is the state of the controller of the fee threshold down
+type ThresholdControl struct {
+	Threshold float64 `json:"threshold"`
+	Target    float64 `json:"target"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	// The settled fee checks and false rejections of the current window, and
+	// the rejections that are not yet settled
+	Checks          uint64 `json:"checks"`
+	FalseRejections uint64 `json:"falseRejections"`
+	Pending         int    `json:"pending"`
+	// The most recent decisions, oldest first
+	Decisions []ThresholdDecision `json:"decisions"`
+}
@@ -0,0 +1,129 @@
+package fees
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// feeTierWindow is the number of seconds between suggesting a fee and
+	// the sequencer checking it that the tiers are meant to cover
+	feeTierWindow = 120
+	// standardTierQuantile and fastTierQuantile are the shares of recent
+	// windows whose rise of the L1 gas price the standard and fast tiers
+	// cover
+	standardTierQuantile = 0.75
+	fastTierQuantile     = 0.95
+)
+
+// FeeTier is an L1 gas price to encode the fee of a transaction with, along
+// with the share of recent windows in which a fee at that price would have
+// covered the L1 gas price when it was checked
+type FeeTier struct {
+	L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+	L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	// Confidence is between 0 and 1, 0 when there is not enough history to
+	// estimate it
+	Confidence float64 `json:"confidence"`
+}
+
+// FeeTiers are the slow, standard and fast fee suggestions
+type FeeTiers struct {
+	Slow     *FeeTier `json:"slow"`
+	Standard *FeeTier `json:"standard"`
+	Fast     *FeeTier `json:"fast"`
+	// Window is the number of seconds after the suggestion that the
+	// confidence applies to
+	Window hexutil.Uint64 `json:"window"`
+	// Samples is the number of windows of the history that the confidence
+	// is estimated from
+	Samples int `json:"samples"`
+}
+
+// SuggestFeeTiers returns three fee tiers from the history of the L1 gas
+// price, which must be ordered by time. The slow tier is the latest L1 gas
+// price. The standard and fast tiers cover the rise of the L1 gas price in
+// most recent windows, and at least the trend of the L1 gas price over a
+// window.
+func SuggestFeeTiers(samples []L1GasPriceSample, l2GasPrice *big.Int) (*FeeTiers, error) {
+	if len(samples) == 0 {
+		return nil, errNoL1GasPriceSamples
+	}
+	latest := samples[len(samples)-1].Price
+	rises := l1GasPriceRises(samples, feeTierWindow)
+	projected, err := ProjectL1GasPrice(samples, feeTierWindow)
+	if err != nil {
+		return nil, err
+	}
+	tier := func(quantile float64) *FeeTier {
+		price := new(big.Int).Set(latest)
+		if quantile > 0 && len(rises) > 0 {
+			rise := rises[int(math.Ceil(quantile*float64(len(rises))))-1]
+			// Round the price up so that it covers the rise
+			scaled := new(big.Int).Mul(latest, rise.Num())
+			scaled.Add(scaled, new(big.Int).Sub(rise.Denom(), common.Big1))
+			scaled.Div(scaled, rise.Denom())
+			if scaled.Cmp(price) > 0 {
+				price = scaled
+			}
+		}
+		if quantile > 0 && projected.Cmp(price) > 0 {
+			price = new(big.Int).Set(projected)
+		}
+		return &FeeTier{
+			L1GasPrice: (*hexutil.Big)(price),
+			L2GasPrice: (*hexutil.Big)(l2GasPrice),
+			Confidence: coveredShare(rises, latest, price),
+		}
+	}
+	return &FeeTiers{
+		Slow:     tier(0),
+		Standard: tier(standardTierQuantile),
+		Fast:     tier(fastTierQuantile),
+		Window:   feeTierWindow,
+		Samples:  len(rises),
+	}, nil
+}
+
+// l1GasPriceRises returns the largest factor that the L1 gas price rose by
+// within the window after each sample whose window is complete, in
+// increasing order
+func l1GasPriceRises(samples []L1GasPriceSample, window uint64) []*big.Rat {
+	last := samples[len(samples)-1].Time
+	var rises []*big.Rat
+	for i, sample := range samples {
+		if sample.Time+window > last {
+			break
+		}
+		if sample.Price.Sign() == 0 {
+			continue
+		}
+		highest := sample.Price
+		for _, later := range samples[i+1:] {
+			if later.Time > sample.Time+window {
+				break
+			}
+			if later.Price.Cmp(highest) > 0 {
+				highest = later.Price
+			}
+		}
+		rises = append(rises, new(big.Rat).SetFrac(highest, sample.Price))
+	}
+	sort.Slice(rises, func(i, j int) bool { return rises[i].Cmp(rises[j]) < 0 })
+	return rises
+}
+
+// coveredShare returns the share of the rises that a price covers when it is
+// suggested at the base price
+func coveredShare(rises []*big.Rat, base, price *big.Int) float64 {
+	if len(rises) == 0 || base.Sign() == 0 {
+		return 0
+	}
+	multiple := new(big.Rat).SetFrac(price, base)
+	covered := sort.Search(len(rises), func(i int) bool { return rises[i].Cmp(multiple) > 0 })
+	return float64(covered) / float64(len(rises))
+}
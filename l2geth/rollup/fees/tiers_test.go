@@ -0,0 +1,105 @@
+package fees
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSuggestFeeTiers(t *testing.T) {
+	l2GasPrice := big.NewInt(1)
+
+	tests := map[string]struct {
+		samples  []L1GasPriceSample
+		slow     int64
+		standard int64
+		fast     int64
+		windows  int
+		err      error
+	}{
+		"no-samples": {
+			err: errNoL1GasPriceSamples,
+		},
+		// Without history every tier is the latest price
+		"single-sample": {
+			samples:  l1GasPriceSamples(100),
+			slow:     100,
+			standard: 100,
+			fast:     100,
+		},
+		"flat": {
+			samples:  l1GasPriceSamples(100, 100, 100, 100, 100),
+			slow:     100,
+			standard: 100,
+			fast:     100,
+			windows:  3,
+		},
+		// The price spikes by half in one window and by a tenth in two, the
+		// standard tier covers the smaller rise and the fast tier the spike
+		"spiky": {
+			samples:  l1GasPriceSamples(100, 150, 100, 100, 100, 110, 100, 100, 100, 100),
+			slow:     100,
+			standard: 110,
+			fast:     150,
+			windows:  8,
+		},
+		// The price rose by a fifth within a window, which is more than the
+		// trend of the price
+		"rising": {
+			samples:  l1GasPriceSamples(100, 110, 120, 130),
+			slow:     130,
+			standard: 156,
+			fast:     156,
+			windows:  2,
+		},
+		// The price rose after the last complete window, the standard and
+		// fast tiers follow the trend
+		"trend": {
+			samples: []L1GasPriceSample{
+				{Time: 1000, Price: big.NewInt(100)},
+				{Time: 1010, Price: big.NewInt(100)},
+				{Time: 1020, Price: big.NewInt(100)},
+				{Time: 1030, Price: big.NewInt(100)},
+				{Time: 1200, Price: big.NewInt(110)},
+			},
+			slow:     110,
+			standard: 117,
+			fast:     117,
+			windows:  4,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tiers, err := SuggestFeeTiers(tt.samples, l2GasPrice)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error mismatch: have %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			for _, tier := range []struct {
+				name     string
+				tier     *FeeTier
+				expected int64
+			}{
+				{"slow", tiers.Slow, tt.slow},
+				{"standard", tiers.Standard, tt.standard},
+				{"fast", tiers.Fast, tt.fast},
+			} {
+				if tier.tier.L1GasPrice.ToInt().Int64() != tier.expected {
+					t.Fatalf("%s tier mismatch: have %d, want %d", tier.name, tier.tier.L1GasPrice.ToInt(), tier.expected)
+				}
+			}
+			if tiers.Samples != tt.windows {
+				t.Fatalf("windows mismatch: have %d, want %d", tiers.Samples, tt.windows)
+			}
+			if tiers.Slow.Confidence > tiers.Standard.Confidence || tiers.Standard.Confidence > tiers.Fast.Confidence {
+				t.Fatalf("confidence not increasing: %f, %f, %f", tiers.Slow.Confidence, tiers.Standard.Confidence, tiers.Fast.Confidence)
+			}
+			if tt.windows > 0 && tiers.Fast.Confidence != 1 {
+				t.Fatalf("fast tier does not cover the history: %f", tiers.Fast.Confidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Package userop prices ERC-4337 user operations on the rollup. A bundler
+// submits user operations in a handleOps transaction to the EntryPoint, and
+// the L1 fee of that transaction depends on its calldata. The L1 fee of the
+// bundle is shared between its operations through their preVerificationGas,
+// which must cover the part of the calldata that each operation adds, its
+// share of the fixed calldata and batch submission overhead of the bundle,
+// and the L2 gas that the EntryPoint spends outside of the operation.
+package userop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+var (
+	// ErrPreVerificationGasTooLow represents the error case of a user
+	// operation whose preVerificationGas does not cover its L1 fee and
+	// overhead
+	ErrPreVerificationGasTooLow = errors.New("preVerificationGas too low")
+	// errMissingGasPrice represents the error case of an estimate without a
+	// gas price to convert the L1 fee into gas with
+	errMissingGasPrice = errors.New("missing gas price")
+	// errMissingL1GasPrice represents the error case of an estimate without
+	// an L1 gas price
+	errMissingL1GasPrice = errors.New("missing L1 gas price")
+)
+
+const (
+	// PerUserOpGas is the L2 gas that the EntryPoint spends on each
+	// operation outside of its verification and execution
+	PerUserOpGas uint64 = 18300
+	// BundleGas is the intrinsic L2 gas of the handleOps transaction, which
+	// is shared between the operations of the bundle
+	BundleGas = params.TxGas
+	// DummySignatureSize is the size of the signature that an operation is
+	// priced with before it is signed
+	DummySignatureSize = 65
+)
+
+// maxPreVerificationGas is the largest preVerificationGas that is priced,
+// four non-zero bytes
+var maxPreVerificationGas = big.NewInt(0xffffffff)
+
+// dummyBeneficiary is the beneficiary that the fixed calldata of a bundle is
+// priced with
+var dummyBeneficiary = common.BytesToAddress(bytes.Repeat([]byte{0xff}, common.AddressLength))
+
+// handleOpsSelector is the selector of handleOps of the EntryPoint
+var handleOpsSelector = crypto.Keccak256([]byte("handleOps((address,uint256,bytes,bytes,uint256,uint256,uint256,uint256,uint256,bytes,bytes)[],address)"))[:4]
+
+// UserOperation is an ERC-4337 user operation, in the JSON encoding of
+// eth_sendUserOperation
+type UserOperation struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// Pack returns the ABI encoding of the operation as it appears in the
+// calldata of handleOps
+func (op *UserOperation) Pack() []byte {
+	dynamic := [][]byte{op.InitCode, op.CallData, op.PaymasterAndData, op.Signature}
+	// The head holds the 11 fields, the dynamic fields hold the offset of
+	// their tail
+	offset := uint64(11 * 32)
+	offsets := make([][]byte, len(dynamic))
+	tail := make([]byte, 0)
+	for i, data := range dynamic {
+		offsets[i] = word(new(big.Int).SetUint64(offset))
+		encoded := packBytes(data)
+		tail = append(tail, encoded...)
+		offset += uint64(len(encoded))
+	}
+	head := make([]byte, 0, 11*32+len(tail))
+	head = append(head, common.LeftPadBytes(op.Sender.Bytes(), 32)...)
+	head = append(head, word(op.Nonce.ToInt())...)
+	head = append(head, offsets[0]...)
+	head = append(head, offsets[1]...)
+	head = append(head, word(op.CallGasLimit.ToInt())...)
+	head = append(head, word(op.VerificationGasLimit.ToInt())...)
+	head = append(head, word(op.PreVerificationGas.ToInt())...)
+	head = append(head, word(op.MaxFeePerGas.ToInt())...)
+	head = append(head, word(op.MaxPriorityFeePerGas.ToInt())...)
+	head = append(head, offsets[2]...)
+	head = append(head, offsets[3]...)
+	return append(head, tail...)
+}
+
+// HandleOpsCalldata returns the calldata of the handleOps transaction that
+// submits the operations
+func HandleOpsCalldata(ops []*UserOperation, beneficiary common.Address) []byte {
+	packed := make([][]byte, len(ops))
+	for i, op := range ops {
+		packed[i] = op.Pack()
+	}
+	data := bundleHeader(beneficiary, uint64(len(ops)))
+	// The array of dynamic tuples holds the offsets of the operations
+	// relative to the start of its elements
+	offset := uint64(32 * len(ops))
+	for _, p := range packed {
+		data = append(data, word(new(big.Int).SetUint64(offset))...)
+		offset += uint64(len(p))
+	}
+	for _, p := range packed {
+		data = append(data, p...)
+	}
+	return data
+}
+
+// bundleHeader returns the calldata of a handleOps transaction of a bundle
+// of the size that precedes the offsets of its operations
+func bundleHeader(beneficiary common.Address, size uint64) []byte {
+	data := append([]byte{}, handleOpsSelector...)
+	data = append(data, word(big.NewInt(64))...)
+	data = append(data, common.LeftPadBytes(beneficiary.Bytes(), 32)...)
+	return append(data, word(new(big.Int).SetUint64(size))...)
+}
+
+// Options are the parameters that a user operation is priced with
+type Options struct {
+	// BundleSize is the number of operations that the bundle is expected to
+	// contain, the fixed costs of the bundle are shared between them. A
+	// bundle of a single operation is assumed when it is not set.
+	BundleSize uint64
+	// L1GasPrice is the L1 gas price of the rollup
+	L1GasPrice *big.Int
+	// GasPrice is the gas price that the operation pays to the bundler in
+	// the EntryPoint, which converts the L1 fee into gas. The maxFeePerGas
+	// of the operation is used when it is not set.
+	GasPrice *big.Int
+	// CalldataGas is the L1 calldata gas schedule, the default schedule is
+	// used when it is not set
+	CalldataGas *fees.CalldataGas
+}
+
+// Fee is the price of a user operation
+type Fee struct {
+	// L1GasUsed is the L1 gas of the calldata of the operation and its share
+	// of the fixed L1 gas of the bundle
+	L1GasUsed hexutil.Uint64 `json:"l1GasUsed"`
+	// L1Fee is the L1 fee of the L1 gas used
+	L1Fee *hexutil.Big `json:"l1Fee"`
+	// PreVerificationGas covers the L1 fee at the gas price and the L2 gas
+	// that the EntryPoint spends outside of the operation
+	PreVerificationGas hexutil.Uint64 `json:"preVerificationGas"`
+	// MaxFee is the most that the operation pays at the gas price, with the
+	// estimated preVerificationGas and its gas limits
+	MaxFee *hexutil.Big `json:"maxFee"`
+}
+
+// EstimateFee prices the operation. An operation that is not signed yet is
+// priced with a dummy signature of non-zero bytes, so that it is not
+// underpriced once it is signed.
+func EstimateFee(op *UserOperation, opts *Options) (*Fee, error) {
+	if opts.L1GasPrice == nil {
+		return nil, errMissingL1GasPrice
+	}
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice = op.MaxFeePerGas.ToInt()
+	}
+	if gasPrice == nil || gasPrice.Sign() <= 0 {
+		return nil, errMissingGasPrice
+	}
+	size := opts.BundleSize
+	if size == 0 {
+		size = 1
+	}
+	schedule := fees.DefaultCalldataGas
+	if opts.CalldataGas != nil {
+		schedule = *opts.CalldataGas
+	}
+	// The preVerificationGas is part of the calldata that it pays for, so
+	// the operation is priced with the largest value that it can be set to
+	priced := *op
+	priced.PreVerificationGas = (*hexutil.Big)(maxPreVerificationGas)
+	if len(priced.Signature) == 0 {
+		priced.Signature = dummySignature()
+	}
+	// The operation adds its encoding and its offset in the array of
+	// operations to the calldata of the bundle
+	data := append(word(new(big.Int).SetUint64(uint64(32*size))), priced.Pack()...)
+	fixed := bundleHeader(dummyBeneficiary, size)
+
+	l1GasUsed := calldataGas(data, schedule) + ceilDiv(calldataGas(fixed, schedule)+fees.Overhead, size)
+	l1Fee := new(big.Int).Mul(new(big.Int).SetUint64(l1GasUsed), opts.L1GasPrice)
+
+	l1FeeGas := new(big.Int).Add(l1Fee, new(big.Int).Sub(gasPrice, common.Big1))
+	l1FeeGas.Quo(l1FeeGas, gasPrice)
+	// The operation shares the intrinsic gas of the bundle transaction and
+	// adds the intrinsic gas of its own calldata, as the node charges it
+	intrinsicGas, err := fees.ComputeIntrinsicGas(data, false, true, true)
+	if err != nil {
+		return nil, err
+	}
+	l2Gas := PerUserOpGas + ceilDiv(BundleGas, size) + intrinsicGas - params.TxGas
+	pvg := new(big.Int).Add(l1FeeGas, new(big.Int).SetUint64(l2Gas))
+	if pvg.Cmp(maxPreVerificationGas) > 0 {
+		return nil, fmt.Errorf("preVerificationGas overflows: %d", pvg)
+	}
+
+	// The verification gas limit is charged up to three times when the
+	// operation has a paymaster, for the validation and the two post ops
+	verification := toInt(op.VerificationGasLimit)
+	if len(op.PaymasterAndData) > 0 {
+		verification.Mul(verification, big.NewInt(3))
+	}
+	maxGas := new(big.Int).Add(pvg, verification)
+	maxGas.Add(maxGas, toInt(op.CallGasLimit))
+
+	return &Fee{
+		L1GasUsed:          hexutil.Uint64(l1GasUsed),
+		L1Fee:              (*hexutil.Big)(l1Fee),
+		PreVerificationGas: hexutil.Uint64(pvg.Uint64()),
+		MaxFee:             (*hexutil.Big)(maxGas.Mul(maxGas, gasPrice)),
+	}, nil
+}
+
+// ValidatePreVerificationGas returns an error if the preVerificationGas of
+// the operation does not cover its L1 fee and overhead
+func ValidatePreVerificationGas(op *UserOperation, opts *Options) error {
+	fee, err := EstimateFee(op, opts)
+	if err != nil {
+		return err
+	}
+	have := toInt(op.PreVerificationGas)
+	if have.Cmp(new(big.Int).SetUint64(uint64(fee.PreVerificationGas))) < 0 {
+		return fmt.Errorf("%w: have %d, want %d", ErrPreVerificationGasTooLow, have, fee.PreVerificationGas)
+	}
+	return nil
+}
+
+// calldataGas returns the gas of the calldata at the schedule, without the
+// batch submission overhead
+func calldataGas(data []byte, schedule fees.CalldataGas) uint64 {
+	var gas uint64
+	for _, b := range data {
+		if b == 0 {
+			gas += schedule.Zero
+		} else {
+			gas += schedule.NonZero
+		}
+	}
+	return gas
+}
+
+func ceilDiv(a, b uint64) uint64 {
+	return (a + b - 1) / b
+}
+
+func dummySignature() []byte {
+	return bytes.Repeat([]byte{0xff}, DummySignatureSize)
+}
+
+// toInt returns the value of a quantity, nil is zero
+func toInt(b *hexutil.Big) *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(b.ToInt())
+}
+
+// word returns the 32 byte ABI encoding of an unsigned integer, nil is zero
+func word(n *big.Int) []byte {
+	if n == nil {
+		return make([]byte, 32)
+	}
+	return common.LeftPadBytes(n.Bytes(), 32)
+}
+
+// packBytes returns the ABI encoding of dynamic bytes: the length followed
+// by the bytes padded to a multiple of 32
+func packBytes(data []byte) []byte {
+	encoded := word(big.NewInt(int64(len(data))))
+	padded := make([]byte, (len(data)+31)/32*32)
+	copy(padded, data)
+	return append(encoded, padded...)
+}
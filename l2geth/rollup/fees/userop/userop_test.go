@@ -0,0 +1,167 @@
+package userop
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+const entryPointABI = `[{"type":"function","name":"handleOps","inputs":[{"name":"ops","type":"tuple[]","components":[
+	{"name":"sender","type":"address"},
+	{"name":"nonce","type":"uint256"},
+	{"name":"initCode","type":"bytes"},
+	{"name":"callData","type":"bytes"},
+	{"name":"callGasLimit","type":"uint256"},
+	{"name":"verificationGasLimit","type":"uint256"},
+	{"name":"preVerificationGas","type":"uint256"},
+	{"name":"maxFeePerGas","type":"uint256"},
+	{"name":"maxPriorityFeePerGas","type":"uint256"},
+	{"name":"paymasterAndData","type":"bytes"},
+	{"name":"signature","type":"bytes"}]},
+	{"name":"beneficiary","type":"address"}],"outputs":[]}]`
+
+type abiUserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+func testUserOperation(callData []byte) *UserOperation {
+	return &UserOperation{
+		Sender:               common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Nonce:                (*hexutil.Big)(big.NewInt(7)),
+		CallData:             callData,
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(100_000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(150_000)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(0)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(1_000_000_000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1_000_000_000)),
+		Signature:            bytes.Repeat([]byte{0xab}, DummySignatureSize),
+	}
+}
+
+func TestHandleOpsCalldata(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(entryPointABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := []*UserOperation{
+		testUserOperation(common.FromHex("0xb61d27f6")),
+		testUserOperation(bytes.Repeat([]byte{1}, 100)),
+	}
+	ops[1].InitCode = bytes.Repeat([]byte{2}, 40)
+	ops[1].PaymasterAndData = bytes.Repeat([]byte{3}, 20)
+
+	args := make([]abiUserOperation, len(ops))
+	for i, op := range ops {
+		args[i] = abiUserOperation{
+			Sender:               op.Sender,
+			Nonce:                op.Nonce.ToInt(),
+			InitCode:             op.InitCode,
+			CallData:             op.CallData,
+			CallGasLimit:         op.CallGasLimit.ToInt(),
+			VerificationGasLimit: op.VerificationGasLimit.ToInt(),
+			PreVerificationGas:   op.PreVerificationGas.ToInt(),
+			MaxFeePerGas:         op.MaxFeePerGas.ToInt(),
+			MaxPriorityFeePerGas: op.MaxPriorityFeePerGas.ToInt(),
+			PaymasterAndData:     op.PaymasterAndData,
+			Signature:            op.Signature,
+		}
+	}
+	beneficiary := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	expected, err := parsed.Pack("handleOps", args, beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := HandleOpsCalldata(ops, beneficiary); !bytes.Equal(data, expected) {
+		t.Fatalf("mismatched calldata:\ngot    %x\nexpect %x", data, expected)
+	}
+}
+
+func TestEstimateFee(t *testing.T) {
+	l1GasPrice := big.NewInt(100_000_000_000)
+	op := testUserOperation(bytes.Repeat([]byte{1}, 200))
+
+	single, err := EstimateFee(op, &Options{L1GasPrice: l1GasPrice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The operation pays for the whole bundle when it is alone in it
+	priced := *op
+	priced.PreVerificationGas = (*hexutil.Big)(maxPreVerificationGas)
+	data := HandleOpsCalldata([]*UserOperation{&priced}, dummyBeneficiary)
+	expected := fees.CalculateL1GasUsed(data).Uint64()
+	if uint64(single.L1GasUsed) != expected {
+		t.Fatalf("mismatched L1 gas used: got %d, expect %d", single.L1GasUsed, expected)
+	}
+	// The preVerificationGas covers the L1 fee at the gas price
+	l1FeeGas := new(big.Int).Quo(single.L1Fee.ToInt(), op.MaxFeePerGas.ToInt()).Uint64()
+	if uint64(single.PreVerificationGas) < l1FeeGas+PerUserOpGas+BundleGas {
+		t.Fatalf("preVerificationGas %d does not cover the L1 fee gas %d", single.PreVerificationGas, l1FeeGas)
+	}
+
+	// The fixed costs are shared in a larger bundle
+	shared, err := EstimateFee(op, &Options{L1GasPrice: l1GasPrice, BundleSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.PreVerificationGas >= single.PreVerificationGas {
+		t.Fatalf("preVerificationGas not shared: got %d, single %d", shared.PreVerificationGas, single.PreVerificationGas)
+	}
+
+	// An unsigned operation is priced with a dummy signature
+	unsigned := *op
+	unsigned.Signature = nil
+	fee, err := EstimateFee(&unsigned, &Options{L1GasPrice: l1GasPrice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.PreVerificationGas != single.PreVerificationGas {
+		t.Fatalf("mismatched unsigned preVerificationGas: got %d, expect %d", fee.PreVerificationGas, single.PreVerificationGas)
+	}
+
+	if _, err := EstimateFee(op, &Options{}); !errors.Is(err, errMissingL1GasPrice) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, errMissingL1GasPrice)
+	}
+}
+
+func TestValidatePreVerificationGas(t *testing.T) {
+	opts := &Options{L1GasPrice: big.NewInt(100_000_000_000)}
+	op := testUserOperation(bytes.Repeat([]byte{1}, 200))
+	fee, err := EstimateFee(op, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		pvg uint64
+		err error
+	}{
+		"exact":   {uint64(fee.PreVerificationGas), nil},
+		"more":    {uint64(fee.PreVerificationGas) + 1, nil},
+		"too-low": {uint64(fee.PreVerificationGas) - 1, ErrPreVerificationGasTooLow},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			priced := *op
+			priced.PreVerificationGas = (*hexutil.Big)(new(big.Int).SetUint64(tt.pvg))
+			if err := ValidatePreVerificationGas(&priced, opts); !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+		})
+	}
+}
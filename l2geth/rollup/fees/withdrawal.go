@@ -0,0 +1,89 @@
+package fees
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The L1 gas of relaying a withdrawal with the L1CrossDomainMessenger. The
+// message is proven against a state root that was proposed to the
+// StateCommitmentChain and it is finalized once the fraud proof window of
+// the state root is over.
+const (
+	// relayMessageGas is the L1 gas of relayMessage besides the proof and
+	// the execution of the message: checking that the state root is final
+	// and recording that the message was relayed
+	relayMessageGas uint64 = 80_000
+	// proofNodeGas is the L1 gas of verifying a node of a trie witness
+	proofNodeGas uint64 = 10_000
+	// batchSiblingGas is the L1 gas of verifying a sibling of the inclusion
+	// proof of the state root in its batch
+	batchSiblingGas uint64 = 1_000
+	// StateBatchProofDepth is the number of siblings of the inclusion proof
+	// of a state root, for batches of up to 1024 state roots
+	StateBatchProofDepth uint64 = 10
+	// relayArgsSize is the size of the ABI encoded arguments of relayMessage
+	// without the message and the witnesses: the selector, the target, the
+	// sender, the nonce, the offsets and lengths, the state root, the batch
+	// header and the index of the state root. They are charged as non-zero
+	// bytes.
+	relayArgsSize uint64 = 4 + 20*32
+)
+
+// WithdrawalProof is the size of the proof that a message was sent on L2:
+// the witnesses of the account of the OVM_L2ToL1MessagePasser in the state
+// trie and of the message in its storage trie
+type WithdrawalProof struct {
+	Nodes uint64
+	Size  uint64
+}
+
+// WithdrawalEstimate is the L1 cost of relaying a withdrawal. Proving is the
+// verification of the proof of the message, finalizing is the execution of
+// the message. They are charged for in the same L1 transaction.
+type WithdrawalEstimate struct {
+	ProveGas    hexutil.Uint64 `json:"proveGas"`
+	FinalizeGas hexutil.Uint64 `json:"finalizeGas"`
+	L1GasUsed   hexutil.Uint64 `json:"l1GasUsed"`
+	L1GasPrice  *hexutil.Big   `json:"l1GasPrice"`
+	ProveFee    *hexutil.Big   `json:"proveFee"`
+	FinalizeFee *hexutil.Big   `json:"finalizeFee"`
+	L1Fee       *hexutil.Big   `json:"l1Fee"`
+}
+
+// WithdrawalProveGas returns the L1 gas of submitting and verifying the proof
+// of a withdrawal
+func WithdrawalProveGas(proof WithdrawalProof) uint64 {
+	siblings := StateBatchProofDepth * 32
+	calldata := (siblings + proof.Size) * txDataNonZeroGas
+	return calldata + proof.Nodes*proofNodeGas + StateBatchProofDepth*batchSiblingGas
+}
+
+// WithdrawalFinalizeGas returns the L1 gas of relaying a withdrawal besides
+// its proof, messageGas is the L1 gas of the execution of the message
+func WithdrawalFinalizeGas(message []byte, messageGas uint64) uint64 {
+	// The message is padded with zero bytes to a multiple of 32 bytes
+	zeroes, nonZeroes := zeroesAndOnes(message)
+	zeroes += (uint64(len(message))+31)/32*32 - uint64(len(message))
+	calldata := (relayArgsSize+nonZeroes)*txDataNonZeroGas + zeroes*txDataZeroGas
+	return txGas + calldata + relayMessageGas + messageGas
+}
+
+// NewWithdrawalEstimate returns the L1 cost of relaying a withdrawal of the
+// message with the proof at the L1 gas price
+func NewWithdrawalEstimate(message []byte, proof WithdrawalProof, messageGas uint64, l1GasPrice *big.Int) *WithdrawalEstimate {
+	prove := WithdrawalProveGas(proof)
+	finalize := WithdrawalFinalizeGas(message, messageGas)
+	proveFee := new(big.Int).Mul(new(big.Int).SetUint64(prove), l1GasPrice)
+	finalizeFee := new(big.Int).Mul(new(big.Int).SetUint64(finalize), l1GasPrice)
+	return &WithdrawalEstimate{
+		ProveGas:    hexutil.Uint64(prove),
+		FinalizeGas: hexutil.Uint64(finalize),
+		L1GasUsed:   hexutil.Uint64(prove + finalize),
+		L1GasPrice:  (*hexutil.Big)(new(big.Int).Set(l1GasPrice)),
+		ProveFee:    (*hexutil.Big)(proveFee),
+		FinalizeFee: (*hexutil.Big)(finalizeFee),
+		L1Fee:       (*hexutil.Big)(new(big.Int).Add(proveFee, finalizeFee)),
+	}
+}
@@ -0,0 +1,35 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWithdrawalEstimate(t *testing.T) {
+	var (
+		proof      = WithdrawalProof{Nodes: 12, Size: 3000}
+		message    = []byte{0x01, 0x00, 0x02}
+		l1GasPrice = big.NewInt(50_000_000_000)
+	)
+	prove := (StateBatchProofDepth*32+3000)*txDataNonZeroGas + 12*proofNodeGas + StateBatchProofDepth*batchSiblingGas
+	if gas := WithdrawalProveGas(proof); gas != prove {
+		t.Fatalf("mismatched prove gas: got %d, expect %d", gas, prove)
+	}
+	// The message is padded to a word of 30 zero bytes and 2 non-zero bytes
+	finalize := txGas + (relayArgsSize+2)*txDataNonZeroGas + 30*txDataZeroGas + relayMessageGas + 50_000
+	if gas := WithdrawalFinalizeGas(message, 50_000); gas != finalize {
+		t.Fatalf("mismatched finalize gas: got %d, expect %d", gas, finalize)
+	}
+
+	estimate := NewWithdrawalEstimate(message, proof, 50_000, l1GasPrice)
+	if uint64(estimate.L1GasUsed) != prove+finalize {
+		t.Fatalf("mismatched L1 gas: got %d, expect %d", estimate.L1GasUsed, prove+finalize)
+	}
+	expected := new(big.Int).Mul(new(big.Int).SetUint64(prove+finalize), l1GasPrice)
+	if estimate.L1Fee.ToInt().Cmp(expected) != 0 {
+		t.Fatalf("mismatched L1 fee: got %d, expect %d", estimate.L1Fee.ToInt(), expected)
+	}
+	if sum := new(big.Int).Add(estimate.ProveFee.ToInt(), estimate.FinalizeFee.ToInt()); sum.Cmp(expected) != 0 {
+		t.Fatalf("mismatched prove and finalize fees: got %d, expect %d", sum, expected)
+	}
+}
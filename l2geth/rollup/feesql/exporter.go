@@ -0,0 +1,273 @@
+// Package feesql exports the fee economics of the rollup to Postgres. The fee
+// components of every block and the L1 cost of every transaction batch are
+// written to tables that operators can query, so that revenue and cost can
+// be analyzed without a custom indexer.
+package feesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// DefaultInterval is the default interval between exports
+	DefaultInterval = time.Minute
+	// blockFeesPage is the number of blocks that are read and written at once
+	blockFeesPage = 1000
+)
+
+// Source is the node that the fee economics are read from
+type Source interface {
+	// BlockFees returns the fee components of up to count blocks from a
+	// block, stopping at the head
+	BlockFees(ctx context.Context, from, count uint64) ([]*fees.BlockFeesResult, error)
+	// Reconciliations returns the most recently reconciled batches
+	Reconciliations(ctx context.Context) ([]*fees.Reconciliation, error)
+}
+
+type rpcSource struct {
+	client *rpc.Client
+}
+
+// NewRPCSource returns a Source of the node that the client is connected to,
+// which must expose the rollup_personal namespace
+func NewRPCSource(client *rpc.Client) Source {
+	return &rpcSource{client: client}
+}
+
+func (s *rpcSource) BlockFees(ctx context.Context, from, count uint64) ([]*fees.BlockFeesResult, error) {
+	var result []*fees.BlockFeesResult
+	err := s.client.CallContext(ctx, &result, "rollup_personal_getBlockFees", hexUint64(from), hexUint64(count))
+	return result, err
+}
+
+func (s *rpcSource) Reconciliations(ctx context.Context) ([]*fees.Reconciliation, error) {
+	var result []*fees.Reconciliation
+	err := s.client.CallContext(ctx, &result, "rollup_personal_getFeeReconciliation")
+	return result, err
+}
+
+func hexUint64(n uint64) string {
+	return fmt.Sprintf("0x%x", n)
+}
+
+// Exporter writes fee economics to a Postgres database
+type Exporter struct {
+	db *sql.DB
+}
+
+// New creates an Exporter of the database
+func New(db *sql.DB) *Exporter {
+	return &Exporter{db: db}
+}
+
+// Migrate applies the migrations that have not been applied to the database
+func (e *Exporter) Migrate(ctx context.Context) error {
+	if _, err := e.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`); err != nil {
+		return fmt.Errorf("cannot create migrations table: %w", err)
+	}
+	applied := make(map[int]bool)
+	rows, err := e.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("cannot read migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("cannot read migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("cannot read migrations: %w", err)
+	}
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		err := e.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("cannot apply migration %d: %w", m.version, err)
+		}
+		log.Info("Applied fee exporter migration", "version", m.version, "name", m.name)
+	}
+	return nil
+}
+
+// LastBlock returns the number of the last exported block, 0 when no block
+// has been exported
+func (e *Exporter) LastBlock(ctx context.Context) (uint64, error) {
+	var number int64
+	if err := e.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(number), 0) FROM block_fees`).Scan(&number); err != nil {
+		return 0, fmt.Errorf("cannot read last exported block: %w", err)
+	}
+	return uint64(number), nil
+}
+
+// WriteBlockFees writes the fee components of the blocks, replacing blocks
+// that were already exported
+func (e *Exporter) WriteBlockFees(ctx context.Context, blocks []*fees.BlockFeesResult) error {
+	return e.inTx(ctx, func(tx *sql.Tx) error {
+		for _, b := range blocks {
+			_, err := tx.ExecContext(ctx, `INSERT INTO block_fees
+	(number, timestamp, transactions, fee, l1_gas_used, l2_gas_used, calldata_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (number) DO UPDATE SET
+	timestamp = EXCLUDED.timestamp,
+	transactions = EXCLUDED.transactions,
+	fee = EXCLUDED.fee,
+	l1_gas_used = EXCLUDED.l1_gas_used,
+	l2_gas_used = EXCLUDED.l2_gas_used,
+	calldata_size = EXCLUDED.calldata_size`,
+				int64(b.Number), time.Unix(int64(b.Timestamp), 0).UTC(), int64(b.Transactions),
+				b.Fee.ToInt().String(), int64(b.L1GasUsed), int64(b.L2GasUsed), int64(b.CalldataSize))
+			if err != nil {
+				return fmt.Errorf("cannot write fees of block %d: %w", b.Number, err)
+			}
+			for _, c := range b.Contracts {
+				_, err := tx.ExecContext(ctx, `INSERT INTO contract_fees
+	(number, address, transactions, fee, l1_gas_used, calldata_size)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (number, address) DO UPDATE SET
+	transactions = EXCLUDED.transactions,
+	fee = EXCLUDED.fee,
+	l1_gas_used = EXCLUDED.l1_gas_used,
+	calldata_size = EXCLUDED.calldata_size`,
+					int64(b.Number), c.Address.Bytes(), int64(c.Transactions),
+					c.Fee.ToInt().String(), int64(c.L1GasUsed), int64(c.CalldataSize))
+				if err != nil {
+					return fmt.Errorf("cannot write fees of contract %s in block %d: %w", c.Address.Hex(), b.Number, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// WriteDappLabels writes the labels of the applications that contracts
+// belong to, replacing the labels of contracts that were already labeled
+func (e *Exporter) WriteDappLabels(ctx context.Context, labels map[common.Address]string) error {
+	return e.inTx(ctx, func(tx *sql.Tx) error {
+		for address, label := range labels {
+			_, err := tx.ExecContext(ctx, `INSERT INTO dapp_labels (address, label)
+VALUES ($1, $2)
+ON CONFLICT (address) DO UPDATE SET label = EXCLUDED.label`,
+				address.Bytes(), label)
+			if err != nil {
+				return fmt.Errorf("cannot write label of %s: %w", address.Hex(), err)
+			}
+		}
+		return nil
+	})
+}
+
+// WriteBatchCosts writes the L1 costs of the batches, replacing batches that
+// were already exported
+func (e *Exporter) WriteBatchCosts(ctx context.Context, reports []*fees.Reconciliation) error {
+	return e.inTx(ctx, func(tx *sql.Tx) error {
+		for _, r := range reports {
+			_, err := tx.ExecContext(ctx, `INSERT INTO batch_costs
+	(batch_index, l1_tx_hash, l1_gas_used, l1_gas_price, start_block, end_block, transactions, revenue, cost, margin)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (batch_index) DO UPDATE SET
+	l1_tx_hash = EXCLUDED.l1_tx_hash,
+	l1_gas_used = EXCLUDED.l1_gas_used,
+	l1_gas_price = EXCLUDED.l1_gas_price,
+	start_block = EXCLUDED.start_block,
+	end_block = EXCLUDED.end_block,
+	transactions = EXCLUDED.transactions,
+	revenue = EXCLUDED.revenue,
+	cost = EXCLUDED.cost,
+	margin = EXCLUDED.margin`,
+				int64(r.BatchIndex), r.L1TxHash.Bytes(), int64(r.L1GasUsed), r.L1GasPrice.ToInt().String(),
+				int64(r.StartBlock), int64(r.EndBlock), int64(r.Transactions),
+				r.Revenue.ToInt().String(), r.Cost.ToInt().String(), r.Margin.ToInt().String())
+			if err != nil {
+				return fmt.Errorf("cannot write cost of batch %d: %w", r.BatchIndex, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Sync exports the blocks after the last exported block up to the head of
+// the source and the batches that the source has reconciled
+func (e *Exporter) Sync(ctx context.Context, src Source) error {
+	last, err := e.LastBlock(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		blocks, err := src.BlockFees(ctx, last+1, blockFeesPage)
+		if err != nil {
+			return fmt.Errorf("cannot read block fees: %w", err)
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		if err := e.WriteBlockFees(ctx, blocks); err != nil {
+			return err
+		}
+		last = uint64(blocks[len(blocks)-1].Number)
+		log.Info("Exported block fees", "blocks", len(blocks), "last", last)
+	}
+	reports, err := src.Reconciliations(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read batch costs: %w", err)
+	}
+	if len(reports) > 0 {
+		if err := e.WriteBatchCosts(ctx, reports); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Loop exports from the source every interval until the context is done
+func (e *Exporter) Loop(ctx context.Context, src Source, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := e.Sync(ctx, src); err != nil {
+			log.Error("Cannot export fees", "msg", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// inTx runs the function in a transaction that is committed when it
+// succeeds
+func (e *Exporter) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
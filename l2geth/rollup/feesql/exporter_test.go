@@ -0,0 +1,299 @@
+package feesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// fakeDB is an in-memory database that records the statements that it
+// executes and answers the queries of the exporter
+type fakeDB struct {
+	lock      sync.Mutex
+	execs     []fakeExec
+	versions  []int64
+	blocks    map[int64][]driver.Value
+	batches   map[int64][]driver.Value
+	contracts map[string][]driver.Value
+	labels    map[string]string
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+var (
+	fakeDBs   = make(map[string]*fakeDB)
+	fakeDBsMu sync.Mutex
+)
+
+func init() {
+	sql.Register("feesqlfake", fakeDriver{})
+}
+
+func openFake(t *testing.T) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{
+		blocks:    make(map[int64][]driver.Value),
+		batches:   make(map[int64][]driver.Value),
+		contracts: make(map[string][]driver.Value),
+		labels:    make(map[string]string),
+	}
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = fake
+	fakeDBsMu.Unlock()
+	db, err := sql.Open("feesqlfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	db := fakeDBs[name]
+	if db == nil {
+		return nil, errors.New("unknown database")
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	s.db.execs = append(s.db.execs, fakeExec{query: s.query, args: args})
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO schema_migrations"):
+		s.db.versions = append(s.db.versions, args[0].(int64))
+	case strings.HasPrefix(s.query, "INSERT INTO block_fees"):
+		s.db.blocks[args[0].(int64)] = args
+	case strings.HasPrefix(s.query, "INSERT INTO batch_costs"):
+		s.db.batches[args[0].(int64)] = args
+	case strings.HasPrefix(s.query, "INSERT INTO contract_fees"):
+		s.db.contracts[fmt.Sprintf("%d-%x", args[0].(int64), args[1].([]byte))] = args
+	case strings.HasPrefix(s.query, "INSERT INTO dapp_labels"):
+		s.db.labels[fmt.Sprintf("%x", args[0].([]byte))] = args[1].(string)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	var values []driver.Value
+	switch {
+	case strings.HasPrefix(s.query, "SELECT version FROM schema_migrations"):
+		for _, version := range s.db.versions {
+			values = append(values, version)
+		}
+	case strings.Contains(s.query, "FROM block_fees"):
+		var max int64
+		for number := range s.db.blocks {
+			if number > max {
+				max = number
+			}
+		}
+		values = append(values, max)
+	default:
+		return nil, errors.New("unexpected query")
+	}
+	return &fakeRows{values: values}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+// fakeSource serves the fee economics of a chain of blocks
+type fakeSource struct {
+	head    uint64
+	reports []*fees.Reconciliation
+}
+
+func (s *fakeSource) BlockFees(ctx context.Context, from, count uint64) ([]*fees.BlockFeesResult, error) {
+	var blocks []*fees.BlockFeesResult
+	for n := from; n <= s.head && n < from+count; n++ {
+		blocks = append(blocks, &fees.BlockFeesResult{
+			Number:       hexutil.Uint64(n),
+			Timestamp:    hexutil.Uint64(1600000000 + n),
+			Transactions: 1,
+			Fee:          (*hexutil.Big)(new(big.Int).Mul(big.NewInt(int64(n)), big.NewInt(1e18))),
+			L1GasUsed:    hexutil.Uint64(1000 * n),
+			L2GasUsed:    21000,
+			CalldataSize: hexutil.Uint64(100 * n),
+		})
+	}
+	return blocks, nil
+}
+
+func (s *fakeSource) Reconciliations(ctx context.Context) ([]*fees.Reconciliation, error) {
+	return s.reports, nil
+}
+
+func TestMigrate(t *testing.T) {
+	db, fake := openFake(t)
+	exporter := New(db)
+	for i := 0; i < 2; i++ {
+		if err := exporter.Migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fake.versions) != len(migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(fake.versions), len(migrations))
+	}
+	applied := 0
+	for _, exec := range fake.execs {
+		for _, m := range migrations {
+			if exec.query == m.sql {
+				applied++
+			}
+		}
+	}
+	if applied != len(migrations) {
+		t.Fatalf("executed %d migrations, want %d", applied, len(migrations))
+	}
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Fatalf("migration %d has version %d", i, m.version)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	db, fake := openFake(t)
+	exporter := New(db)
+	report := fees.NewReconciliation(7, common.HexToHash("0x01"), 50000, big.NewInt(30e9), 1, 2500, 2500, new(big.Int).Mul(big.NewInt(5), big.NewInt(1e18)))
+	source := &fakeSource{head: 2500, reports: []*fees.Reconciliation{report}}
+
+	if err := exporter.Sync(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.blocks) != 2500 {
+		t.Fatalf("exported %d blocks, want 2500", len(fake.blocks))
+	}
+	block := fake.blocks[2500]
+	if fee := block[3].(string); fee != "2500000000000000000000" {
+		t.Fatalf("fee mismatch: have %s", fee)
+	}
+	if l1GasUsed := block[4].(int64); l1GasUsed != 2500000 {
+		t.Fatalf("l1 gas used mismatch: have %d", l1GasUsed)
+	}
+	batch := fake.batches[7]
+	if batch == nil {
+		t.Fatal("batch cost not exported")
+	}
+	if cost := batch[8].(string); cost != "1500000000000000" {
+		t.Fatalf("cost mismatch: have %s", cost)
+	}
+	if margin := batch[9].(string); margin != "4998500000000000000" {
+		t.Fatalf("margin mismatch: have %s", margin)
+	}
+
+	// Only the new blocks are read on the next sync
+	source.head = 2600
+	writes := len(fake.execs)
+	if err := exporter.Sync(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.blocks) != 2600 {
+		t.Fatalf("exported %d blocks, want 2600", len(fake.blocks))
+	}
+	if have := len(fake.execs) - writes; have != 101 {
+		t.Fatalf("executed %d statements, want 101", have)
+	}
+}
+
+func TestWriteDappFees(t *testing.T) {
+	db, fake := openFake(t)
+	exporter := New(db)
+	dex, bridge := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	blocks := []*fees.BlockFeesResult{
+		{
+			Number: 1, Timestamp: 1600000000, Transactions: 3,
+			Fee: (*hexutil.Big)(big.NewInt(600)), L1GasUsed: 3000, L2GasUsed: 63000, CalldataSize: 300,
+			Contracts: []*fees.ContractFeesResult{
+				{Address: dex, Transactions: 2, Fee: (*hexutil.Big)(big.NewInt(400)), L1GasUsed: 2000, CalldataSize: 200},
+				{Address: bridge, Transactions: 1, Fee: (*hexutil.Big)(big.NewInt(200)), L1GasUsed: 1000, CalldataSize: 100},
+			},
+		},
+		{
+			Number: 2, Timestamp: 1600000001, Transactions: 1,
+			Fee: (*hexutil.Big)(big.NewInt(100)), L1GasUsed: 1000, L2GasUsed: 21000, CalldataSize: 100,
+		},
+	}
+	if err := exporter.WriteBlockFees(context.Background(), blocks); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.blocks) != 2 || len(fake.contracts) != 2 {
+		t.Fatalf("exported %d blocks and %d contracts, want 2 and 2", len(fake.blocks), len(fake.contracts))
+	}
+	contract := fake.contracts[fmt.Sprintf("1-%x", dex.Bytes())]
+	if contract == nil {
+		t.Fatal("contract fees not exported")
+	}
+	if fee := contract[3].(string); fee != "400" {
+		t.Fatalf("fee mismatch: have %s", fee)
+	}
+	if calldata := contract[5].(int64); calldata != 200 {
+		t.Fatalf("calldata size mismatch: have %d", calldata)
+	}
+
+	labels := map[common.Address]string{dex: "dex", bridge: "bridge"}
+	if err := exporter.WriteDappLabels(context.Background(), labels); err != nil {
+		t.Fatal(err)
+	}
+	if label := fake.labels[fmt.Sprintf("%x", dex.Bytes())]; label != "dex" {
+		t.Fatalf("label mismatch: have %q", label)
+	}
+}
@@ -0,0 +1,102 @@
+package feesql
+
+// migration is a change of the schema. Migrations are applied in order of
+// their version and each one is applied once, so a released migration must
+// not be changed: changes to the schema are made with a new migration.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// migrations are the migrations of the schema of the exporter. Amounts of
+// wei are NUMERIC so that they are not rounded.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create block fees and batch costs",
+		sql: `
+CREATE TABLE block_fees (
+	number        BIGINT PRIMARY KEY,
+	timestamp     TIMESTAMPTZ NOT NULL,
+	transactions  BIGINT NOT NULL,
+	fee           NUMERIC(78, 0) NOT NULL,
+	l1_gas_used   BIGINT NOT NULL,
+	l2_gas_used   BIGINT NOT NULL,
+	calldata_size BIGINT NOT NULL
+);
+CREATE INDEX block_fees_timestamp ON block_fees (timestamp);
+
+CREATE TABLE batch_costs (
+	batch_index  BIGINT PRIMARY KEY,
+	l1_tx_hash   BYTEA NOT NULL,
+	l1_gas_used  BIGINT NOT NULL,
+	l1_gas_price NUMERIC(78, 0) NOT NULL,
+	start_block  BIGINT NOT NULL,
+	end_block    BIGINT NOT NULL,
+	transactions BIGINT NOT NULL,
+	revenue      NUMERIC(78, 0) NOT NULL,
+	cost         NUMERIC(78, 0) NOT NULL,
+	margin       NUMERIC(78, 0) NOT NULL
+);
+CREATE INDEX batch_costs_blocks ON batch_costs (start_block, end_block);
+`,
+	},
+	{
+		version: 2,
+		name:    "create daily economics view",
+		sql: `
+CREATE VIEW daily_economics AS
+SELECT
+	blocks.day,
+	blocks.fee,
+	blocks.l1_gas_used,
+	COALESCE(batches.cost, 0) AS l1_cost,
+	blocks.fee - COALESCE(batches.cost, 0) AS margin
+FROM (
+	SELECT date_trunc('day', timestamp) AS day, SUM(fee) AS fee, SUM(l1_gas_used) AS l1_gas_used
+	FROM block_fees GROUP BY 1
+) blocks
+LEFT JOIN (
+	-- A batch is attributed to the day of its last block
+	SELECT date_trunc('day', b.timestamp) AS day, SUM(c.cost) AS cost
+	FROM batch_costs c JOIN block_fees b ON b.number = c.end_block GROUP BY 1
+') batches ON batches.day = blocks.day;
+`,
+	},
+	{
+		version: 3,
+		name:    "create contract fees and dapp labels",
+		sql: `
+CREATE TABLE contract_fees (
+	number        BIGINT NOT NULL,
+	address       BYTEA NOT NULL,
+	transactions  BIGINT NOT NULL,
+	fee           NUMERIC(78, 0) NOT NULL,
+	l1_gas_used   BIGINT NOT NULL,
+	calldata_size BIGINT NOT NULL,
+	PRIMARY KEY (number, address)
+);
+CREATE INDEX contract_fees_address ON contract_fees (address);
+
+CREATE TABLE dapp_labels (
+	address BYTEA PRIMARY KEY,
+	label   TEXT NOT NULL
+);
+
+-- Contracts without a label are aggregated as unlabeled
+CREATE VIEW daily_dapp_fees AS
+SELECT
+	date_trunc('day', b.timestamp) AS day,
+	COALESCE(l.label, 'unlabeled') AS label,
+	SUM(c.transactions) AS transactions,
+	SUM(c.fee) AS fee,
+	SUM(c.l1_gas_used) AS l1_gas_used,
+	SUM(c.calldata_size) AS calldata_size
+FROM contract_fees c
+JOIN block_fees b ON b.number = c.number
+LEFT JOIN dapp_labels l ON l.address = c.address
+GROUP BY 1, 2;
+`,
+	},
+}
@@ -0,0 +1,179 @@
+// Package fixture captures the state of a network that determines the fees
+// of transactions, so that fee behavior observed in production can be
+// reproduced deterministically in tests.
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Fixture is the fee relevant state of a network at a block
+type Fixture struct {
+	ChainID *hexutil.Big   `json:"chainId"`
+	Block   hexutil.Uint64 `json:"block"`
+	// The L1 gas price known by the node, it is only captured at the latest
+	// block because the node does not keep its history
+	L1GasPrice *hexutil.Big                `json:"l1GasPrice,omitempty"`
+	Accounts   map[common.Address]*Account `json:"accounts"`
+}
+
+// Account is the captured state of a single account, storage slots that are
+// empty are left out
+type Account struct {
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Balance *hexutil.Big                `json:"balance"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// Capture reads the state of the OVM_GasPriceOracle from a node at a block,
+// nil is the latest block
+func Capture(ctx context.Context, client *rpc.Client, block *big.Int) (*Fixture, error) {
+	ec := ethclient.NewClient(client)
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot fetch chain id: %w", err)
+	}
+	fixture := &Fixture{
+		ChainID:  (*hexutil.Big)(chainID),
+		Accounts: make(map[common.Address]*Account),
+	}
+	// Pin the latest block so that all of the state is read at the same
+	// block, and read the L1 gas price that belongs to it
+	if block == nil {
+		var number hexutil.Uint64
+		if err := client.CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+			return nil, fmt.Errorf("Cannot fetch block number: %w", err)
+		}
+		block = new(big.Int).SetUint64(uint64(number))
+		var prices struct {
+			L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+		}
+		if err := client.CallContext(ctx, &prices, "rollup_gasPrices"); err != nil {
+			return nil, fmt.Errorf("Cannot fetch gas prices: %w", err)
+		}
+		fixture.L1GasPrice = prices.L1GasPrice
+	}
+	fixture.Block = hexutil.Uint64(block.Uint64())
+
+	addr := rcfg.L2GasPriceOracleAddress
+	code, err := ec.CodeAt(ctx, addr, block)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot fetch code: %w", err)
+	}
+	balance, err := ec.BalanceAt(ctx, addr, block)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot fetch balance: %w", err)
+	}
+	account := &Account{
+		Code:    code,
+		Balance: (*hexutil.Big)(balance),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+	read := func(key common.Hash) (common.Hash, error) {
+		value, err := ec.StorageAt(ctx, addr, key, block)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("Cannot fetch slot %s: %w", key.Hex(), err)
+		}
+		if hash := common.BytesToHash(value); hash != (common.Hash{}) {
+			account.Storage[key] = hash
+		}
+		return common.BytesToHash(value), nil
+	}
+	keys := make([]common.Hash, 0, len(rcfg.GasPriceOracleSlots))
+	for _, slot := range rcfg.GasPriceOracleSlots {
+		keys = append(keys, slot.Key)
+	}
+	version, err := read(rcfg.L2GasPriceOracleVersionSlot)
+	if err != nil {
+		return nil, err
+	}
+	// Capture the slots of the layout that the contract reports as well,
+	// they are known when this node supports the layout
+	if layout, ok := rcfg.Layouts[version.Big().Uint64()]; ok {
+		keys = append(keys, layout.Owner, layout.GasPrice)
+	}
+	for _, key := range keys {
+		if _, err := read(key); err != nil {
+			return nil, err
+		}
+	}
+	fixture.Accounts[addr] = account
+	return fixture, nil
+}
+
+// Load reads a fixture from a JSON file
+func Load(path string) (*Fixture, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		return nil, fmt.Errorf("Cannot decode fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes the fixture to a JSON file
+func (f *Fixture) Save(path string) error {
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(raw, '\n'), 0644)
+}
+
+// Apply writes the accounts of the fixture into the state
+func (f *Fixture) Apply(statedb *state.StateDB) {
+	for addr, account := range f.Accounts {
+		statedb.SetCode(addr, account.Code)
+		if account.Balance != nil {
+			statedb.SetBalance(addr, account.Balance.ToInt())
+		}
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+}
+
+// StateDB returns an in memory state that only holds the accounts of the
+// fixture
+func (f *Fixture) StateDB() (*state.StateDB, error) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	if err != nil {
+		return nil, err
+	}
+	f.Apply(statedb)
+	return statedb, nil
+}
+
+// GenesisAlloc returns the accounts of the fixture as a genesis allocation,
+// for tests that run a chain
+func (f *Fixture) GenesisAlloc() core.GenesisAlloc {
+	alloc := make(core.GenesisAlloc, len(f.Accounts))
+	for addr, account := range f.Accounts {
+		balance := new(big.Int)
+		if account.Balance != nil {
+			balance = account.Balance.ToInt()
+		}
+		alloc[addr] = core.GenesisAccount{
+			Code:    account.Code,
+			Balance: balance,
+			Storage: account.Storage,
+		}
+	}
+	return alloc
+}
@@ -0,0 +1,135 @@
+package fixture
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testEthAPI serves the state of the gas price oracle, the gas price is the
+// block number that it is read at
+type testEthAPI struct {
+	owner common.Address
+}
+
+func (api *testEthAPI) ChainId() hexutil.Uint64 { return 420 }
+
+func (api *testEthAPI) BlockNumber() hexutil.Uint64 { return 7 }
+
+func (api *testEthAPI) GetCode(ctx context.Context, address common.Address, block rpc.BlockNumber) hexutil.Bytes {
+	if address != rcfg.L2GasPriceOracleAddress {
+		return nil
+	}
+	return hexutil.Bytes{0x60, 0x00}
+}
+
+func (api *testEthAPI) GetBalance(ctx context.Context, address common.Address, block rpc.BlockNumber) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int))
+}
+
+func (api *testEthAPI) GetStorageAt(ctx context.Context, address common.Address, key common.Hash, block rpc.BlockNumber) hexutil.Bytes {
+	switch {
+	case address != rcfg.L2GasPriceOracleAddress:
+	case key == rcfg.L2GasPriceOracleOwnerSlot:
+		return common.BytesToHash(api.owner.Bytes()).Bytes()
+	case key == rcfg.L2GasPriceSlot:
+		return common.BigToHash(big.NewInt(block.Int64())).Bytes()
+	}
+	return common.Hash{}.Bytes()
+}
+
+type testRollupAPI struct{}
+
+func (api *testRollupAPI) GasPrices() map[string]*hexutil.Big {
+	return map[string]*hexutil.Big{"l1GasPrice": (*hexutil.Big)(big.NewInt(100))}
+}
+
+func TestFixture(t *testing.T) {
+	owner := common.HexToAddress("0x1234")
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &testEthAPI{owner: owner}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("rollup", &testRollupAPI{}); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	dir, err := ioutil.TempDir("", "fixture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		block      *big.Int
+		number     uint64
+		l1GasPrice *big.Int
+	}{
+		"latest": {
+			number:     7,
+			l1GasPrice: big.NewInt(100),
+		},
+		"historical": {
+			block:  big.NewInt(3),
+			number: 3,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			captured, err := Capture(context.Background(), client, tt.block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, name+".json")
+			if err := captured.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			fixture, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uint64(fixture.Block) != tt.number {
+				t.Fatalf("mismatched block: got %d, expect %d", fixture.Block, tt.number)
+			}
+			if fixture.ChainID.ToInt().Uint64() != 420 {
+				t.Fatalf("mismatched chain id: got %d, expect 420", fixture.ChainID.ToInt())
+			}
+			if (fixture.L1GasPrice == nil) != (tt.l1GasPrice == nil) ||
+				(tt.l1GasPrice != nil && fixture.L1GasPrice.ToInt().Cmp(tt.l1GasPrice) != 0) {
+				t.Fatalf("mismatched l1 gas price: got %v, expect %v", fixture.L1GasPrice, tt.l1GasPrice)
+			}
+
+			statedb, err := fixture.StateDB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			slots, err := rcfg.ReadGPOStorageSlots(statedb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if slots.Owner != owner {
+				t.Fatalf("mismatched owner: got %s, expect %s", slots.Owner.Hex(), owner.Hex())
+			}
+			if slots.GasPrice.Uint64() != tt.number {
+				t.Fatalf("mismatched gas price: got %d, expect %d", slots.GasPrice, tt.number)
+			}
+			if code := statedb.GetCode(rcfg.L2GasPriceOracleAddress); len(code) != 2 {
+				t.Fatalf("mismatched code: %x", code)
+			}
+			if _, ok := fixture.GenesisAlloc()[rcfg.L2GasPriceOracleAddress]; !ok {
+				t.Fatal("missing gas price oracle in genesis alloc")
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package rollup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// gpoArtifact is the path of the EVM build of the OVM_GasPriceOracle, which
+// is created with `yarn build:contracts` in packages/contracts. It can be
+// overridden with the GPO_ARTIFACT environment variable.
+var gpoArtifact = filepath.Join("..", "..", "packages", "contracts", "artifacts", "contracts",
+	"optimistic-ethereum", "OVM", "predeploys", "OVM_GasPriceOracle.sol", "OVM_GasPriceOracle.json")
+
+// gpoContract executes the compiled OVM_GasPriceOracle in an in-process EVM
+type gpoContract struct {
+	abi   abi.ABI
+	state *state.StateDB
+	cfg   *runtime.Config
+}
+
+func newGPOContract(t *testing.T) *gpoContract {
+	path := gpoArtifact
+	if env := os.Getenv("GPO_ARTIFACT"); env != "" {
+		path = env
+	}
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Skipf("OVM_GasPriceOracle artifact not found at %s, build the contracts with yarn build:contracts", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var artifact struct {
+		ABI              json.RawMessage `json:"abi"`
+		DeployedBytecode hexutil.Bytes   `json:"deployedBytecode"`
+	}
+	if err := json.Unmarshal(raw, &artifact); err != nil {
+		t.Fatalf("cannot decode artifact: %v", err)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(artifact.ABI))
+	if err != nil {
+		t.Fatalf("cannot decode abi: %v", err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.SetCode(rcfg.L2GasPriceOracleAddress, artifact.DeployedBytecode)
+	return &gpoContract{
+		abi:   parsed,
+		state: statedb,
+		// The contracts are compiled for Istanbul
+		cfg: &runtime.Config{ChainConfig: params.AllEthashProtocolChanges, State: statedb},
+	}
+}
+
+// call executes a method of the contract from the sender
+func (c *gpoContract) call(t *testing.T, from common.Address, method string, args ...interface{}) ([]byte, error) {
+	input, err := c.abi.Pack(method, args...)
+	if err != nil {
+		t.Fatalf("cannot pack %s: %v", method, err)
+	}
+	c.cfg.Origin = from
+	ret, _, err := runtime.Call(rcfg.L2GasPriceOracleAddress, input, c.cfg)
+	return ret, err
+}
+
+// TestGasPriceOracleContract executes the OVM_GasPriceOracle with randomized
+// inputs and checks that the values that it returns and stores match the
+// values that the sync service reads from its storage slots.
+func TestGasPriceOracleContract(t *testing.T) {
+	contract := newGPOContract(t)
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	randomWord := func() common.Hash {
+		var word common.Hash
+		// Vary the length of the values so that small and large values are
+		// both covered
+		r.Read(word[32-r.Intn(33):])
+		return word
+	}
+
+	for i := 0; i < 256; i++ {
+		owner := common.BytesToAddress(randomWord().Bytes())
+		contract.state.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceOracleOwnerSlot, owner.Hash())
+
+		// Values written by the owner through the contract are read by
+		// the sync service
+		gasPrice := randomWord().Big()
+		if _, err := contract.call(t, owner, "setGasPrice", gasPrice); err != nil {
+			t.Fatalf("setGasPrice %d failed: %v", gasPrice, err)
+		}
+		if err := service.updateL2GasPrice(contract.state); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.cacheGasPriceOracleOwner(contract.state); err != nil {
+			t.Fatal(err)
+		}
+		read, _ := service.RollupGpo.SuggestL2GasPrice(context.Background())
+		if read.Cmp(gasPrice) != 0 {
+			t.Fatalf("mismatched gas price: contract set %d, sync service read %d", gasPrice, read)
+		}
+		if got := service.GasPriceOracleOwnerAddress(); *got != owner {
+			t.Fatalf("mismatched owner: contract has %s, sync service read %s", owner.Hex(), got.Hex())
+		}
+
+		// Values written to the storage slots are returned by the contract
+		// byte for byte
+		slot := randomWord()
+		contract.state.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceSlot, slot)
+		ret, err := contract.call(t, owner, "gasPrice")
+		if err != nil {
+			t.Fatalf("gasPrice failed: %v", err)
+		}
+		if !bytes.Equal(ret, slot.Bytes()) {
+			t.Fatalf("mismatched gasPrice: slot holds %x, contract returned %x", slot, ret)
+		}
+		ret, err = contract.call(t, owner, "owner")
+		if err != nil {
+			t.Fatalf("owner failed: %v", err)
+		}
+		if !bytes.Equal(ret, owner.Hash().Bytes()) {
+			t.Fatalf("mismatched owner: slot holds %s, contract returned %x", owner.Hex(), ret)
+		}
+
+		// Only the owner can set the gas price
+		if _, err := contract.call(t, common.Address{0x01}, "setGasPrice", big.NewInt(1)); err == nil {
+			t.Fatal("setGasPrice succeeded for an account that is not the owner")
+		}
+	}
+}
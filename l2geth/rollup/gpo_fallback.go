@@ -0,0 +1,87 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+const (
+	// defaultGPOUpstreamTTL is how long the values read from the upstream
+	// node are used before they are read again
+	defaultGPOUpstreamTTL = 15 * time.Second
+	// defaultGPOUpstreamMaxStaleness is how long the values read from the
+	// upstream node are used while it cannot be reached
+	defaultGPOUpstreamMaxStaleness = 5 * time.Minute
+)
+
+// errGPOUpstream is the error for when the gas price oracle cannot be read
+// from the upstream node and the values that were read before are too old
+var errGPOUpstream = errors.New("cannot read gas price oracle from upstream")
+
+var (
+	gpoUpstreamReadMeter  = metrics.NewRegisteredMeter("rollup/gpo/upstream/read", nil)
+	gpoUpstreamStaleMeter = metrics.NewRegisteredMeter("rollup/gpo/upstream/stale", nil)
+	gpoUpstreamErrorMeter = metrics.NewRegisteredMeter("rollup/gpo/upstream/error", nil)
+)
+
+// gpoFallback reads the OVM_GasPriceOracle from an upstream node with
+// eth_call, for nodes that do not have the state to read it locally. The
+// values are cached for the ttl, and are used for up to the max staleness
+// while the upstream node cannot be reached.
+type gpoFallback struct {
+	reader       *gaspriceoracle.GPOReader
+	ttl          time.Duration
+	maxStaleness time.Duration
+	now          func() time.Time
+
+	lock    sync.Mutex
+	slots   *rcfg.GPOStorageSlots
+	updated time.Time
+}
+
+func newGPOFallback(reader *gaspriceoracle.GPOReader, ttl, maxStaleness time.Duration) *gpoFallback {
+	if ttl == 0 {
+		ttl = defaultGPOUpstreamTTL
+	}
+	if maxStaleness == 0 {
+		maxStaleness = defaultGPOUpstreamMaxStaleness
+	}
+	return &gpoFallback{
+		reader:       reader,
+		ttl:          ttl,
+		maxStaleness: maxStaleness,
+		now:          time.Now,
+	}
+}
+
+// read returns the values of the OVM_GasPriceOracle of the upstream node
+func (f *gpoFallback) read(ctx context.Context) (*rcfg.GPOStorageSlots, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := f.now()
+	if f.slots != nil && now.Sub(f.updated) < f.ttl {
+		return f.slots, nil
+	}
+	slots, err := f.reader.Read(ctx)
+	if err == nil {
+		gpoUpstreamReadMeter.Mark(1)
+		f.slots, f.updated = slots, now
+		return slots, nil
+	}
+	gpoUpstreamErrorMeter.Mark(1)
+	if f.slots != nil && now.Sub(f.updated) < f.maxStaleness {
+		gpoUpstreamStaleMeter.Mark(1)
+		log.Warn("Cannot read gas price oracle from upstream, using stale values", "age", now.Sub(f.updated), "msg", err)
+		return f.slots, nil
+	}
+	return nil, fmt.Errorf("%w: %v", errGPOUpstream, err)
+}
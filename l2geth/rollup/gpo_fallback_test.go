@@ -0,0 +1,110 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle/contract"
+)
+
+// testGPOUpstream answers the calls of the getters of the gas price oracle
+type testGPOUpstream struct {
+	abi      abi.ABI
+	owner    common.Address
+	gasPrice *big.Int
+	err      error
+	calls    int
+}
+
+func (u *testGPOUpstream) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (u *testGPOUpstream) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	u.calls++
+	if u.err != nil {
+		return nil, u.err
+	}
+	method, err := u.abi.MethodById(call.Data)
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "owner" {
+		return method.Outputs.Pack(u.owner)
+	}
+	return method.Outputs.Pack(u.gasPrice)
+}
+
+func (u *testGPOUpstream) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	return common.Hash{}.Bytes(), nil
+}
+
+func TestGPOFallback(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(contract.GasPriceOracleABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := &testGPOUpstream{abi: parsed, owner: common.HexToAddress("0x1234"), gasPrice: big.NewInt(1)}
+	reader, err := gaspriceoracle.NewCallGPOReader(upstream, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	fallback := newGPOFallback(reader, time.Minute, 5*time.Minute)
+	fallback.now = func() time.Time { return now }
+
+	steps := []struct {
+		elapsed  time.Duration
+		gasPrice int64
+		err      error
+		expect   int64
+		calls    int
+		fails    bool
+	}{
+		// Read from the upstream node
+		{elapsed: 0, gasPrice: 1, expect: 1, calls: 2},
+		// Cached for the ttl
+		{elapsed: 30 * time.Second, gasPrice: 2, expect: 1, calls: 2},
+		// Read again after the ttl
+		{elapsed: 31 * time.Second, gasPrice: 2, expect: 2, calls: 4},
+		// The stale values are used while the upstream node fails
+		{elapsed: 2 * time.Minute, err: errors.New("unreachable"), expect: 2, calls: 5},
+		// Until they are older than the max staleness
+		{elapsed: 3 * time.Minute, err: errors.New("unreachable"), calls: 6, fails: true},
+	}
+	for i, step := range steps {
+		now = now.Add(step.elapsed)
+		upstream.gasPrice = big.NewInt(step.gasPrice)
+		upstream.err = step.err
+		slots, err := fallback.read(context.Background())
+		if step.fails {
+			if !errors.Is(err, errGPOUpstream) {
+				t.Fatalf("step %d: mismatched error: got %v, expect %v", i, err, errGPOUpstream)
+			}
+		} else {
+			if err != nil {
+				t.Fatalf("step %d: %v", i, err)
+			}
+			if slots.GasPrice.Int64() != step.expect {
+				t.Fatalf("step %d: mismatched gas price: got %d, expect %d", i, slots.GasPrice, step.expect)
+			}
+			if slots.Owner != upstream.owner {
+				t.Fatalf("step %d: mismatched owner: got %s", i, slots.Owner.Hex())
+			}
+		}
+		if upstream.calls != step.calls {
+			t.Fatalf("step %d: mismatched calls: got %d, expect %d", i, upstream.calls, step.calls)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// defaultGPOReplicaMaxLag is the number of blocks that the replica can be
+// behind the local chain when it is not configured
+const defaultGPOReplicaMaxLag = 4
+
+var (
+	// errGPOReplicaBehind is the error for when the replica is further behind
+	// the local chain than allowed
+	errGPOReplicaBehind = errors.New("gas price oracle replica is behind")
+	// errGPOReplicaMismatch is the error for when the block that the replica
+	// was read at is not part of the local chain
+	errGPOReplicaMismatch = errors.New("gas price oracle replica is not on the local chain")
+)
+
+var (
+	gpoReplicaReadMeter     = metrics.NewRegisteredMeter("rollup/gpo/replica/read", nil)
+	gpoReplicaMismatchMeter = metrics.NewRegisteredMeter("rollup/gpo/replica/mismatch", nil)
+	gpoReplicaErrorMeter    = metrics.NewRegisteredMeter("rollup/gpo/replica/error", nil)
+	gpoReplicaLagGauge      = metrics.NewRegisteredGauge("rollup/gpo/replica/lag", nil)
+)
+
+// GPOReplicaBackend is the part of a read replica that the OVM_GasPriceOracle
+// is read from, an ethclient.Client satisfies it
+type GPOReplicaBackend interface {
+	gaspriceoracle.Backend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// gpoReplica reads the OVM_GasPriceOracle from a read replica of the chain,
+// so that the fee path does not read the local state while transactions are
+// applied to it. Every read is pinned to a block of the local chain: the
+// replica is read at a block whose hash must match the local canonical hash
+// of its number both before and after the read, so the values are those of
+// the local chain at that block even when the replica reorganizes.
+type gpoReplica struct {
+	backend GPOReplicaBackend
+	maxLag  uint64
+	// canonicalHash returns the hash of the local canonical block of a
+	// number
+	canonicalHash func(number uint64) common.Hash
+}
+
+func newGPOReplica(backend GPOReplicaBackend, maxLag uint64, canonicalHash func(uint64) common.Hash) *gpoReplica {
+	if maxLag == 0 {
+		maxLag = defaultGPOReplicaMaxLag
+	}
+	return &gpoReplica{backend: backend, maxLag: maxLag, canonicalHash: canonicalHash}
+}
+
+// read returns the values of the OVM_GasPriceOracle of the replica at the
+// local head, or at the latest block of the replica when it is behind by no
+// more than the max lag, along with the number of the block that they were
+// read at
+func (r *gpoReplica) read(ctx context.Context, head uint64) (*rcfg.GPOStorageSlots, uint64, error) {
+	slots, number, err := r.readPinned(ctx, head)
+	switch {
+	case errors.Is(err, errGPOReplicaMismatch):
+		gpoReplicaMismatchMeter.Mark(1)
+	case err != nil:
+		gpoReplicaErrorMeter.Mark(1)
+	default:
+		gpoReplicaReadMeter.Mark(1)
+		gpoReplicaLagGauge.Update(int64(head - number))
+	}
+	return slots, number, err
+}
+
+func (r *gpoReplica) readPinned(ctx context.Context, head uint64) (*rcfg.GPOStorageSlots, uint64, error) {
+	latest, err := r.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Cannot read latest block of replica: %w", err)
+	}
+	number := head
+	if replicaHead := latest.Number.Uint64(); replicaHead < head {
+		if head-replicaHead > r.maxLag {
+			return nil, 0, fmt.Errorf("%w: at block %d, local head is %d", errGPOReplicaBehind, replicaHead, head)
+		}
+		number = replicaHead
+	}
+	pin := r.canonicalHash(number)
+	if err := r.verifyPin(ctx, number, pin); err != nil {
+		return nil, 0, err
+	}
+	reader, err := gaspriceoracle.NewCallGPOReader(r.backend, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, 0, err
+	}
+	slots, err := reader.Read(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The block may have been reorganized away while it was read
+	if err := r.verifyPin(ctx, number, pin); err != nil {
+		return nil, 0, err
+	}
+	return slots, number, nil
+}
+
+// verifyPin verifies that the block of the replica at a number has the hash
+// of the local canonical block
+func (r *gpoReplica) verifyPin(ctx context.Context, number uint64, pin common.Hash) error {
+	header, err := r.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return fmt.Errorf("Cannot read block %d of replica: %w", number, err)
+	}
+	if hash := header.Hash(); hash != pin {
+		return fmt.Errorf("%w: block %d is %s, expect %s", errGPOReplicaMismatch, number, hash.Hex(), pin.Hex())
+	}
+	return nil
+}
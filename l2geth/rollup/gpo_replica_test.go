@@ -0,0 +1,111 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testGPOReplica is a replica whose chain is a list of headers, its gas
+// price oracle is answered by the upstream
+type testGPOReplica struct {
+	testGPOUpstream
+	headers []*types.Header
+	// reorg replaces the headers once the gas price oracle has been called
+	reorg []*types.Header
+}
+
+func (r *testGPOReplica) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return r.headers[len(r.headers)-1], nil
+	}
+	if n := number.Uint64(); n < uint64(len(r.headers)) {
+		return r.headers[n], nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (r *testGPOReplica) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if r.reorg != nil {
+		r.headers = r.reorg
+	}
+	return r.testGPOUpstream.CallContract(ctx, call, blockNumber)
+}
+
+func testGPOReplicaChain(n int, extra byte) []*types.Header {
+	headers := make([]*types.Header, n)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), Extra: []byte{extra}}
+	}
+	return headers
+}
+
+func TestGPOReplica(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(contract.GasPriceOracleABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := testGPOReplicaChain(11, 0)
+	canonicalHash := func(number uint64) common.Hash {
+		return local[number].Hash()
+	}
+	tests := map[string]struct {
+		headers []*types.Header
+		reorg   []*types.Header
+		number  uint64
+		err     error
+	}{
+		"synced": {
+			headers: testGPOReplicaChain(11, 0),
+			number:  10,
+		},
+		"lagging": {
+			headers: testGPOReplicaChain(8, 0),
+			number:  7,
+		},
+		"behind": {
+			headers: testGPOReplicaChain(5, 0),
+			err:     errGPOReplicaBehind,
+		},
+		"other-chain": {
+			headers: testGPOReplicaChain(11, 1),
+			err:     errGPOReplicaMismatch,
+		},
+		"reorg-while-read": {
+			headers: testGPOReplicaChain(11, 0),
+			reorg:   testGPOReplicaChain(11, 1),
+			err:     errGPOReplicaMismatch,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := &testGPOReplica{
+				testGPOUpstream: testGPOUpstream{abi: parsed, owner: common.HexToAddress("0x1234"), gasPrice: big.NewInt(7)},
+				headers:         tt.headers,
+				reorg:           tt.reorg,
+			}
+			replica := newGPOReplica(backend, 4, canonicalHash)
+			slots, number, err := replica.read(context.Background(), 10)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if number != tt.number {
+				t.Fatalf("mismatched number: got %d, expect %d", number, tt.number)
+			}
+			if slots.GasPrice.Int64() != 7 || slots.Owner != backend.owner {
+				t.Fatalf("mismatched slots: gas price %d, owner %s", slots.GasPrice, slots.Owner.Hex())
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// HistoricalL1Fee recomputes the L1 fee of a transaction with the L2 gas
+// price of the gas price oracle in the state of the parent of its block,
+// which the fee was checked against, instead of the current state. The gas
+// price oracle is taken from the fee params recorded with the block, reading
+// the state of old blocks without them requires an archive node.
+func (s *SyncService) HistoricalL1Fee(ctx context.Context, hash common.Hash) (*fees.HistoricalL1Fee, error) {
+	tx, blockHash, number, _ := rawdb.ReadTransaction(s.db, hash)
+	if tx == nil {
+		return nil, fmt.Errorf("Cannot find transaction %s: %w", hash.Hex(), errElementNotFound)
+	}
+	if number == 0 {
+		return nil, errors.New("Cannot recompute the fees of the genesis block")
+	}
+	// Transactions from L1 pay for their gas on L1
+	if tx.QueueOrigin() == types.QueueOriginL1ToL2 {
+		return fees.NewHistoricalL1Fee(hash, number, tx.Gas(), common.Big0, 0, common.Big0), nil
+	}
+	header := s.bc.GetHeaderByHash(blockHash)
+	if header == nil {
+		return nil, fmt.Errorf("Cannot get block %d", number)
+	}
+	l1GasUsed := tx.L1GasUsedWith(core.BlockL1CalldataGas(s.bc.Config(), header.Number, tx.L1BlockNumber()))
+	// Blocks that were imported with their fee params do not need the
+	// historical state
+	if params := rawdb.ReadBlockFeeParams(s.db, blockHash, number); params != nil {
+		return fees.NewHistoricalL1Fee(hash, number, tx.Gas(), tx.GasPrice(), l1GasUsed, params.L2GasPrice), nil
+	}
+	parent := s.bc.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil, fmt.Errorf("Cannot get parent of block %d", number)
+	}
+	statedb, err := s.bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", number-1, errReplayStateUnavailable)
+	}
+	slots, err := s.readGPOStorageSlots(statedb)
+	if err != nil {
+		return nil, err
+	}
+	return fees.NewHistoricalL1Fee(hash, number, tx.Gas(), tx.GasPrice(), l1GasUsed, slots.GasPrice), nil
+}
@@ -0,0 +1,252 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+const (
+	// maxInclusionDeadline is the longest deadline in seconds that inclusion
+	// hints are given for
+	maxInclusionDeadline = 24 * 60 * 60
+	// inclusionHintLimit is the number of hints whose accuracy is tracked
+	// until their deadline, further hints are not tracked
+	inclusionHintLimit = 4096
+	// inclusionRatioLimit is the number of settled hints that the correction
+	// of the L1 gas price is computed from
+	inclusionRatioLimit = 1024
+	// inclusionMinSettled is the number of settled hints below which the L1
+	// gas price is not corrected
+	inclusionMinSettled = 20
+	// inclusionQuantile is the share of past hints that the correction of
+	// the L1 gas price covers
+	inclusionQuantile = 0.95
+)
+
+var (
+	inclusionHintMeter    = metrics.NewRegisteredMeter("rollup/inclusion/hints", nil)
+	inclusionCoveredMeter = metrics.NewRegisteredMeter("rollup/inclusion/covered", nil)
+	inclusionMissedMeter  = metrics.NewRegisteredMeter("rollup/inclusion/missed", nil)
+)
+
+// inclusionHint is a hint whose L1 gas price is compared against the L1 gas
+// prices observed until its deadline
+type inclusionHint struct {
+	issued    uint64
+	deadline  uint64
+	projected *big.Int
+	price     *big.Int
+}
+
+// inclusionTracker tracks the accuracy of the L1 gas price of inclusion
+// hints and corrects the L1 gas price of new hints by the amount that past
+// hints underestimated it
+type inclusionTracker struct {
+	lock     sync.Mutex
+	pending  []inclusionHint
+	ratios   []float64
+	next     int
+	hints    uint64
+	settled  uint64
+	covered  uint64
+	ratioSum float64
+}
+
+// track adds a hint whose accuracy is settled once its deadline has passed
+func (t *inclusionTracker) track(hint inclusionHint) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.hints++
+	if len(t.pending) < inclusionHintLimit {
+		t.pending = append(t.pending, hint)
+	}
+}
+
+// settle compares the hints whose deadline has passed against the highest L1
+// gas price observed until their deadline. The samples must be ordered by
+// time.
+func (t *inclusionTracker) settle(samples []fees.L1GasPriceSample) {
+	if len(samples) == 0 {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	latest := samples[len(samples)-1].Time
+	pending := t.pending[:0]
+	for _, hint := range t.pending {
+		if hint.deadline > latest {
+			pending = append(pending, hint)
+			continue
+		}
+		var observed *big.Int
+		for _, sample := range samples {
+			if sample.Time > hint.deadline {
+				break
+			}
+			// The price in effect when the hint was issued counts too
+			if sample.Time <= hint.issued {
+				observed = sample.Price
+				continue
+			}
+			if observed == nil || sample.Price.Cmp(observed) > 0 {
+				observed = sample.Price
+			}
+		}
+		if observed == nil || hint.projected.Sign() == 0 {
+			continue
+		}
+		ratio, _ := new(big.Rat).SetFrac(observed, hint.projected).Float64()
+		t.settled++
+		t.ratioSum += ratio
+		if observed.Cmp(hint.price) <= 0 {
+			t.covered++
+			inclusionCoveredMeter.Mark(1)
+		} else {
+			inclusionMissedMeter.Mark(1)
+		}
+		if len(t.ratios) < inclusionRatioLimit {
+			t.ratios = append(t.ratios, ratio)
+		} else {
+			t.ratios[t.next] = ratio
+			t.next = (t.next + 1) % inclusionRatioLimit
+		}
+	}
+	t.pending = pending
+}
+
+// correction returns the factor to scale the projected L1 gas price of new
+// hints by
+func (t *inclusionTracker) correction() float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.ratios) < inclusionMinSettled {
+		return 1
+	}
+	return fees.InclusionCorrection(t.ratios, inclusionQuantile)
+}
+
+// accuracy returns the accuracy of the settled hints
+func (t *inclusionTracker) accuracy() *fees.InclusionAccuracy {
+	correction := t.correction()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	accuracy := &fees.InclusionAccuracy{
+		Hints:      t.hints,
+		Settled:    t.settled,
+		Covered:    t.covered,
+		Correction: correction,
+	}
+	if t.settled != 0 {
+		accuracy.MeanRatio = t.ratioSum / float64(t.settled)
+	}
+	return accuracy
+}
+
+// InclusionHint returns the fee guidance for a transaction that must be
+// applied within a deadline of either blocks or seconds. The wait is
+// estimated from the transactions that wait to be applied and the time that
+// it takes to apply a transaction, and the L1 gas price from the trend of the
+// L1 gas price until the deadline.
+func (s *SyncService) InclusionHint(ctx context.Context, blocks, seconds uint64) (*fees.InclusionHint, error) {
+	if s.verifier {
+		return nil, errors.New("Verifier does not apply transactions")
+	}
+	if (blocks == 0) == (seconds == 0) {
+		return nil, errors.New("deadline must be set in either blocks or seconds")
+	}
+	waiting, held, holdTime := s.txLanes.stats()
+	// Every transaction is applied in its own block, so the number of
+	// transactions ahead is the number of blocks to wait for
+	ahead := func(lane int) uint64 {
+		n := uint64(0)
+		if held {
+			n++
+		}
+		for l := 0; l <= lane; l++ {
+			n += uint64(waiting[l])
+		}
+		return n
+	}
+	wait := func(n uint64) uint64 {
+		return uint64(math.Ceil(float64(n) * holdTime.Seconds()))
+	}
+	meets := func(n uint64) bool {
+		if blocks != 0 {
+			return n < blocks
+		}
+		return wait(n) <= seconds
+	}
+	deadline := seconds
+	if blocks != 0 {
+		deadline = uint64(math.Ceil(float64(blocks) * holdTime.Seconds()))
+	}
+	if deadline > maxInclusionDeadline {
+		return nil, fmt.Errorf("deadline too long: %d seconds, at most %d are allowed", deadline, maxInclusionDeadline)
+	}
+
+	depth := ahead(laneDefault)
+	hint := &fees.InclusionHint{
+		Deadline:      hexutil.Uint64(deadline),
+		QueueDepth:    hexutil.Uint64(depth),
+		ExpectedWait:  hexutil.Uint64(wait(depth)),
+		FeeMultiplier: 1,
+		Feasible:      meets(depth),
+	}
+	// Transactions that pay the premium wait behind the L1 to L2
+	// transactions and the express lane only, as long as it has room
+	if !hint.Feasible && s.txLanes.multiplier != nil && waiting[laneExpress] < s.txLanes.capacity {
+		if express := ahead(laneExpress); meets(express) {
+			multiplier, _ := s.txLanes.multiplier.Float64()
+			hint.QueueDepth = hexutil.Uint64(express)
+			hint.ExpectedWait = hexutil.Uint64(wait(express))
+			hint.Express, hint.FeeMultiplier, hint.Feasible = true, multiplier, true
+		}
+	}
+
+	samples := s.RollupGpo.L1GasPriceHistory()
+	s.inclusionHints.settle(samples)
+	correction := s.inclusionHints.correction()
+	projected, l1GasPrice, confidence, err := fees.InclusionL1GasPrice(samples, deadline, correction)
+	if err != nil {
+		return nil, err
+	}
+	l2GasPrice, err := s.RollupGpo.SuggestL2GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hint.L1GasPrice = (*hexutil.Big)(l1GasPrice)
+	hint.L2GasPrice = (*hexutil.Big)(l2GasPrice)
+	hint.Confidence = confidence
+	hint.Correction = correction
+
+	now := uint64(time.Now().Unix())
+	s.inclusionHints.track(inclusionHint{
+		issued:    now,
+		deadline:  now + deadline,
+		projected: projected,
+		price:     l1GasPrice,
+	})
+	inclusionHintMeter.Mark(1)
+	return hint, nil
+}
+
+// InclusionHintAccuracy returns the accuracy of the L1 gas price of the
+// inclusion hints whose deadline has passed
+func (s *SyncService) InclusionHintAccuracy() *fees.InclusionAccuracy {
+	s.inclusionHints.settle(s.RollupGpo.L1GasPriceHistory())
+	return s.inclusionHints.accuracy()
+}
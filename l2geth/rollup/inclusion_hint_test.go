@@ -0,0 +1,46 @@
+package rollup
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestInclusionTracker(t *testing.T) {
+	var tracker inclusionTracker
+	tracker.track(inclusionHint{issued: 100, deadline: 160, projected: big.NewInt(100), price: big.NewInt(110)})
+	tracker.track(inclusionHint{issued: 100, deadline: 220, projected: big.NewInt(100), price: big.NewInt(110)})
+	tracker.track(inclusionHint{issued: 200, deadline: 500, projected: big.NewInt(100), price: big.NewInt(110)})
+
+	samples := []fees.L1GasPriceSample{
+		{Time: 90, Price: big.NewInt(100)},
+		{Time: 150, Price: big.NewInt(105)},
+		{Time: 210, Price: big.NewInt(120)},
+		{Time: 300, Price: big.NewInt(100)},
+	}
+	tracker.settle(samples)
+
+	accuracy := tracker.accuracy()
+	if accuracy.Hints != 3 {
+		t.Fatalf("mismatched hints: got %d, expect 3", accuracy.Hints)
+	}
+	// The third hint is settled once its deadline has passed
+	if accuracy.Settled != 2 {
+		t.Fatalf("mismatched settled hints: got %d, expect 2", accuracy.Settled)
+	}
+	// The second hint missed the rise to 120
+	if accuracy.Covered != 1 {
+		t.Fatalf("mismatched covered hints: got %d, expect 1", accuracy.Covered)
+	}
+	if accuracy.MeanRatio != 1.125 {
+		t.Fatalf("mismatched mean ratio: got %f, expect 1.125", accuracy.MeanRatio)
+	}
+	// Too few hints have been settled to correct the L1 gas price
+	if accuracy.Correction != 1 {
+		t.Fatalf("mismatched correction: got %f, expect 1", accuracy.Correction)
+	}
+	if len(tracker.pending) != 1 {
+		t.Fatalf("mismatched pending hints: got %d, expect 1", len(tracker.pending))
+	}
+}
@@ -0,0 +1,101 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// The L1 gas prices are reported in wei. The delta is the L1 gas price that
+// the sequencer charged above the observed L1 gas price in percent of the
+// observed L1 gas price, negative when the sequencer charged less.
+var (
+	l1ObservedGasPriceGauge = metrics.NewRegisteredGauge("rollup/verifier/l1gasprice/observed", nil)
+	l1ChargedGasPriceGauge  = metrics.NewRegisteredGauge("rollup/verifier/l1gasprice/charged", nil)
+	l1GasPriceDeltaGauge    = metrics.NewRegisteredGauge("rollup/verifier/l1gasprice/delta", nil)
+	l1GasPriceDeltaHist     = metrics.NewRegisteredHistogram("rollup/verifier/l1gasprice/deltas", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// l1Observer reads the L1 gas price from the base fee of the latest block of
+// an L1 node, so that a verifier does not have to trust the L1 gas price that
+// the data transport layer reports for the sequencer
+type l1Observer struct {
+	l1 l1RPC
+}
+
+func newL1Observer(l1 l1RPC) *l1Observer {
+	return &l1Observer{l1: l1}
+}
+
+// l1Block is the part of an L1 block that the L1 gas price is read from
+type l1Block struct {
+	BaseFee *hexutil.Big `json:"baseFeePerGas"`
+}
+
+// gasPrice returns the base fee of the latest L1 block, or the gas price that
+// the L1 node suggests when its blocks have no base fee
+func (o *l1Observer) gasPrice(ctx context.Context) (*big.Int, error) {
+	var block *l1Block
+	if err := o.l1.CallContext(ctx, &block, "eth_getBlockByNumber", "latest", false); err != nil {
+		return nil, fmt.Errorf("Cannot read latest L1 block: %w", err)
+	}
+	if block == nil {
+		return nil, errors.New("Cannot read latest L1 block: not found")
+	}
+	if block.BaseFee != nil {
+		return block.BaseFee.ToInt(), nil
+	}
+	var gasPrice hexutil.Big
+	if err := o.l1.CallContext(ctx, &gasPrice, "eth_gasPrice"); err != nil {
+		return nil, fmt.Errorf("Cannot read L1 gas price: %w", err)
+	}
+	return gasPrice.ToInt(), nil
+}
+
+// BlobBaseFee returns the blob base fee of the next L1 block, it satisfies
+// fees.BlobBaseFeeSource
+func (o *l1Observer) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	var fee hexutil.Big
+	if err := o.l1.CallContext(ctx, &fee, "eth_blobBaseFee"); err != nil {
+		return nil, fmt.Errorf("Cannot read L1 blob base fee: %w", err)
+	}
+	return fee.ToInt(), nil
+}
+
+// observeChargedL1GasPrice compares the L1 gas price that the sequencer
+// charged a transaction that the verifier applies against the L1 gas price
+// that the verifier observed. The charged L1 gas price is recovered from the
+// fee encoded in the gas limit at the L2 gas price of the tip, up to the
+// rounding of the encoding.
+func (s *SyncService) observeChargedL1GasPrice(tx *types.Transaction) {
+	if tx.QueueOrigin() != types.QueueOriginSequencer || tx.GasPrice().Sign() == 0 {
+		return
+	}
+	ctx := context.Background()
+	observed, err := s.RollupGpo.SuggestL1GasPrice(ctx)
+	if err != nil || observed.Sign() == 0 {
+		return
+	}
+	l2GasPrice, err := s.RollupGpo.SuggestL2GasPrice(ctx)
+	if err != nil {
+		return
+	}
+	l1GasUsed := tx.L1GasUsedWith(core.L1CalldataGas(s.bc.Config(), tx.L1BlockNumber()))
+	charged := fees.MaxChargedL1GasPrice(tx.Gas(), l1GasUsed, l2GasPrice)
+	if charged == nil {
+		return
+	}
+	delta := new(big.Int).Sub(charged, observed)
+	delta.Mul(delta, big.NewInt(100))
+	delta.Quo(delta, observed)
+	l1ChargedGasPriceGauge.Update(charged.Int64())
+	l1GasPriceDeltaGauge.Update(delta.Int64())
+	l1GasPriceDeltaHist.Update(delta.Int64())
+}
@@ -0,0 +1,53 @@
+package rollup
+
+import (
+	"context"
+	"testing"
+)
+
+func TestL1ObserverGasPrice(t *testing.T) {
+	tests := map[string]struct {
+		responses map[string]string
+		gasPrice  int64
+		fails     bool
+	}{
+		"base-fee": {
+			responses: map[string]string{
+				"eth_getBlockByNumber": `{"baseFeePerGas":"0x3b9aca00"}`,
+				"eth_gasPrice":         `"0x1"`,
+			},
+			gasPrice: 1_000_000_000,
+		},
+		"legacy": {
+			responses: map[string]string{
+				"eth_getBlockByNumber": `{}`,
+				"eth_gasPrice":         `"0x77359400"`,
+			},
+			gasPrice: 2_000_000_000,
+		},
+		"no-block": {
+			responses: map[string]string{
+				"eth_getBlockByNumber": `null`,
+			},
+			fails: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			observer := newL1Observer(&mockL1RPC{responses: tt.responses})
+			gasPrice, err := observer.gasPrice(context.Background())
+			if tt.fails {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gasPrice.Int64() != tt.gasPrice {
+				t.Fatalf("mismatched gas price: got %d, expect %d", gasPrice, tt.gasPrice)
+			}
+		})
+	}
+}
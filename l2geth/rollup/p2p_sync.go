@@ -0,0 +1,157 @@
+package rollup
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// p2pSyncMaxDistance is the number of transactions that the chain synced over
+// the p2p network may be behind the remote tip before switching over to
+// deriving the rest of the chain with the sync service
+const p2pSyncMaxDistance uint64 = 64
+
+// errPeerSyncMismatch is the error for when the chain that was synced over the
+// p2p network does not match the chain derived by the data transport layer
+var errPeerSyncMismatch = errors.New("peer synced chain mismatch")
+
+// syncFromPeers waits for the execution layer to sync the chain over the p2p
+// network instead of deriving every transaction from genesis. The metadata of
+// every block that is imported from peers is restored by restoreTxMeta. Once
+// the local chain is close enough to the remote tip, the local head is
+// verified against the transaction derived by the data transport layer and
+// the sync service takes over. Blocks synced over the p2p network are checked
+// against L1 as the verifier processes historical transaction batches, a
+// mismatch stops the sync.
+func (s *SyncService) syncFromPeers() error {
+	if index := s.GetLatestIndex(); index != nil {
+		log.Info("Skipping p2p sync, chain already initialized", "index", *index)
+		return nil
+	}
+	log.Info("Waiting for chain to sync from peers", "max-distance", p2pSyncMaxDistance)
+	s.setSyncStatus(true)
+	defer s.setSyncStatus(false)
+
+	t := time.NewTicker(s.pollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-t.C:
+		}
+		head := s.bc.CurrentBlock().NumberU64()
+		if head == 0 {
+			log.Info("Waiting for blocks from peers")
+			continue
+		}
+		remote, err := s.client.GetLatestTransactionIndex(s.backend)
+		if err != nil {
+			log.Error("Cannot fetch remote tip during p2p sync", "msg", err)
+			continue
+		}
+		if remote == nil {
+			continue
+		}
+		// Handle the off by one
+		local := head - 1
+		if local+p2pSyncMaxDistance < *remote {
+			log.Info("Syncing from peers", "index", local, "tip", *remote)
+			continue
+		}
+		return s.verifyPeerSyncedHead()
+	}
+}
+
+// restoreTxMeta restores the metadata of the transaction of a block that is
+// imported from peers, which is not part of the blocks sent over the p2p
+// network, from the transaction at the same index that was derived by the
+// data transport layer. The transaction is executed with the metadata, so
+// the block is rejected when the transactions do not match.
+func (s *SyncService) restoreTxMeta(block *types.Block) error {
+	txs := block.Transactions()
+	if len(txs) != 1 {
+		return fmt.Errorf("Unexpected number of transactions in block %d: %d", block.NumberU64(), len(txs))
+	}
+	// Handle the off by one
+	index := block.NumberU64() - 1
+	remote, err := s.client.GetTransaction(index, s.backend)
+	if err != nil {
+		return fmt.Errorf("Cannot fetch transaction %d: %w", index, err)
+	}
+	// The metadata is not included in the transaction hash so the hashes
+	// match even though the synced transaction has no metadata
+	if txs[0].Hash() != remote.Hash() {
+		return fmt.Errorf("%w: index %d, local %s, remote %s", errPeerSyncMismatch, index,
+			txs[0].Hash().Hex(), remote.Hash().Hex())
+	}
+	if remote.L1BlockNumber() == nil {
+		return fmt.Errorf("%w: index %d, remote transaction has no L1 block number", errPeerSyncMismatch, index)
+	}
+	txs[0].SetTransactionMeta(remote.GetMeta())
+	return nil
+}
+
+// isTxMetaEqual returns whether two transaction metadata record the same L1
+// context and position of a transaction
+func isTxMetaEqual(a, b *types.TransactionMeta) bool {
+	if a.L1Timestamp != b.L1Timestamp || a.QueueOrigin != b.QueueOrigin {
+		return false
+	}
+	if (a.L1BlockNumber == nil) != (b.L1BlockNumber == nil) || (a.L1BlockNumber != nil && a.L1BlockNumber.Cmp(b.L1BlockNumber) != 0) {
+		return false
+	}
+	if (a.L1MessageSender == nil) != (b.L1MessageSender == nil) || (a.L1MessageSender != nil && *a.L1MessageSender != *b.L1MessageSender) {
+		return false
+	}
+	if (a.QueueIndex == nil) != (b.QueueIndex == nil) || (a.QueueIndex != nil && *a.QueueIndex != *b.QueueIndex) {
+		return false
+	}
+	return true
+}
+
+// verifyPeerSyncedHead compares the head of the chain that was synced over the
+// p2p network and its restored metadata to the transaction at the same index
+// that was derived by the data transport layer. When they match, the indices
+// are updated so that derivation continues from the head.
+func (s *SyncService) verifyPeerSyncedHead() error {
+	block := s.bc.CurrentBlock()
+	head := block.NumberU64()
+	if head == 0 {
+		return fmt.Errorf("%w: no blocks synced", errPeerSyncMismatch)
+	}
+	txs := block.Transactions()
+	if len(txs) != 1 {
+		return fmt.Errorf("Unexpected number of transactions in block %d: %d", head, len(txs))
+	}
+	// Handle the off by one
+	index := head - 1
+	remote, err := s.client.GetTransaction(index, s.backend)
+	if err != nil {
+		return fmt.Errorf("Cannot fetch transaction %d: %w", index, err)
+	}
+	if txs[0].Hash() != remote.Hash() {
+		return fmt.Errorf("%w: index %d, local %s, remote %s", errPeerSyncMismatch, index,
+			txs[0].Hash().Hex(), remote.Hash().Hex())
+	}
+	// The metadata is not included in the transaction hash, so the metadata
+	// that was restored when the head was imported is compared on its own
+	meta := remote.GetMeta()
+	if local := rawdb.ReadTransactionMeta(s.db, head); local == nil || !isTxMetaEqual(local, meta) {
+		return fmt.Errorf("%w: index %d, metadata of the synced transaction does not match", errPeerSyncMismatch, index)
+	}
+	s.SetLatestIndex(&index)
+	if meta.QueueIndex != nil {
+		s.SetLatestEnqueueIndex(meta.QueueIndex)
+	}
+	s.SetLatestL1Timestamp(remote.L1Timestamp())
+	if bn := remote.L1BlockNumber(); bn != nil {
+		s.SetLatestL1BlockNumber(bn.Uint64())
+	}
+	log.Info("Switching from p2p sync to derivation", "index", index, "hash", remote.Hash().Hex())
+	return nil
+}
@@ -0,0 +1,142 @@
+package rollup
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSyncServiceVerifyPeerSyncedHead(t *testing.T) {
+	service, _, _, err := newTestSyncService(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := mockTx()
+	tx.SetL1BlockNumber(10)
+	tx.SetL1Timestamp(100)
+	setupMockClient(service, map[string]interface{}{
+		"GetTransaction": []*types.Transaction{tx},
+	})
+
+	// The metadata of the head was restored when it was imported
+	local := types.NewTransaction(tx.Nonce(), *tx.To(), tx.Value(), tx.Gas(), tx.GasPrice(), tx.Data())
+	header := types.Header{Number: big.NewInt(1)}
+	block := types.NewBlock(&header, []*types.Transaction{local}, nil, nil)
+	service.bc.SetCurrentBlock(block)
+	rawdb.WriteTransactionMeta(service.db, 1, tx.GetMeta())
+
+	if err := service.verifyPeerSyncedHead(); err != nil {
+		t.Fatal(err)
+	}
+	index := service.GetLatestIndex()
+	if index == nil || *index != 0 {
+		t.Fatalf("Unexpected latest index: %v", index)
+	}
+	if ts := service.GetLatestL1Timestamp(); ts != 100 {
+		t.Fatalf("Unexpected L1 timestamp: got %d, expected %d", ts, 100)
+	}
+	if bn := service.GetLatestL1BlockNumber(); bn != 10 {
+		t.Fatalf("Unexpected L1 block number: got %d, expected %d", bn, 10)
+	}
+}
+
+func TestSyncServiceVerifyPeerSyncedHeadMetaMismatch(t *testing.T) {
+	service, _, _, err := newTestSyncService(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := mockTx()
+	tx.SetL1BlockNumber(10)
+	setupMockClient(service, map[string]interface{}{
+		"GetTransaction": []*types.Transaction{tx},
+	})
+	header := types.Header{Number: big.NewInt(1)}
+	block := types.NewBlock(&header, []*types.Transaction{tx}, nil, nil)
+	service.bc.SetCurrentBlock(block)
+	meta := *tx.GetMeta()
+	meta.L1BlockNumber = big.NewInt(11)
+	rawdb.WriteTransactionMeta(service.db, 1, &meta)
+
+	err = service.verifyPeerSyncedHead()
+	if !errors.Is(err, errPeerSyncMismatch) {
+		t.Fatalf("Unexpected error: got %v, expected %v", err, errPeerSyncMismatch)
+	}
+	if index := service.GetLatestIndex(); index != nil {
+		t.Fatalf("Latest index set on mismatch: %d", *index)
+	}
+}
+
+func TestSyncServiceRestoreTxMeta(t *testing.T) {
+	remote := mockTx()
+	remote.SetL1BlockNumber(10)
+	remote.SetL1Timestamp(100)
+	noL1Block := mockTx()
+	noL1Block.GetMeta().L1BlockNumber = nil
+
+	tests := map[string]struct {
+		remote *types.Transaction
+		local  *types.Transaction
+		err    error
+	}{
+		"match":       {remote, remote, nil},
+		"mismatch":    {remote, mockTx(), errPeerSyncMismatch},
+		"no-l1-block": {noL1Block, noL1Block, errPeerSyncMismatch},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, _, _, err := newTestSyncService(true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			setupMockClient(service, map[string]interface{}{
+				"GetTransaction": []*types.Transaction{tt.remote},
+			})
+			// Blocks synced over the p2p network do not include the metadata
+			local := types.NewTransaction(tt.local.Nonce(), *tt.local.To(), tt.local.Value(), tt.local.Gas(), tt.local.GasPrice(), tt.local.Data())
+			header := types.Header{Number: big.NewInt(1)}
+			block := types.NewBlock(&header, []*types.Transaction{local}, nil, nil)
+
+			err = service.restoreTxMeta(block)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Unexpected error: got %v, expected %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			restored := block.Transactions()[0]
+			if bn := restored.L1BlockNumber(); bn == nil || bn.Uint64() != 10 {
+				t.Fatalf("Unexpected L1 block number: got %v, expected %d", bn, 10)
+			}
+			if ts := restored.L1Timestamp(); ts != 100 {
+				t.Fatalf("Unexpected L1 timestamp: got %d, expected %d", ts, 100)
+			}
+		})
+	}
+}
+
+func TestSyncServiceVerifyPeerSyncedHeadMismatch(t *testing.T) {
+	service, _, _, err := newTestSyncService(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setupMockClient(service, map[string]interface{}{
+		"GetTransaction": []*types.Transaction{mockTx()},
+	})
+	header := types.Header{Number: big.NewInt(1)}
+	block := types.NewBlock(&header, []*types.Transaction{mockTx()}, nil, nil)
+	service.bc.SetCurrentBlock(block)
+
+	err = service.verifyPeerSyncedHead()
+	if !errors.Is(err, errPeerSyncMismatch) {
+		t.Fatalf("Unexpected error: got %v, expected %v", err, errPeerSyncMismatch)
+	}
+	if index := service.GetLatestIndex(); index != nil {
+		t.Fatalf("Latest index set on mismatch: %d", *index)
+	}
+}
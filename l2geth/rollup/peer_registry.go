@@ -0,0 +1,138 @@
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// peerRegistryABI is the interface of the contract that holds the list of
+// enode URLs that nodes should connect to
+const peerRegistryABI = `[{"inputs":[],"name":"getPeers","outputs":[{"internalType":"string[]","name":"","type":"string[]"}],"stateMutability":"view","type":"function"}]`
+
+var peerRegistryPeersGauge = metrics.NewRegisteredGauge("rollup/peerregistry/peers", nil)
+
+// PeerServer is the subset of the p2p server used by the PeerRegistry
+type PeerServer interface {
+	AddPeer(node *enode.Node)
+	RemovePeer(node *enode.Node)
+}
+
+// PeerRegistry keeps the static peers of the node in sync with the list of
+// peers held by a registry contract. Peers that are added to the registry
+// are dialed and peers that are removed from the registry are dropped, so
+// that peering changes can be rolled out to a fleet of nodes with a single
+// transaction instead of a configuration change.
+type PeerRegistry struct {
+	caller   ethereum.ContractCaller
+	address  common.Address
+	interval time.Duration
+	server   PeerServer
+	abi      abi.ABI
+
+	lock  sync.Mutex
+	peers map[enode.ID]*enode.Node
+}
+
+// NewPeerRegistry creates a PeerRegistry that reads the registry contract at
+// address using caller. The contract may live on L1 or be an L2 predeploy,
+// depending on the caller.
+func NewPeerRegistry(caller ethereum.ContractCaller, address common.Address, interval time.Duration, server PeerServer) (*PeerRegistry, error) {
+	parsed, err := abi.JSON(strings.NewReader(peerRegistryABI))
+	if err != nil {
+		return nil, err
+	}
+	if interval == 0 {
+		log.Info("Sanitizing peer registry interval to 5 minutes")
+		interval = 5 * time.Minute
+	}
+	return &PeerRegistry{
+		caller:   caller,
+		address:  address,
+		interval: interval,
+		server:   server,
+		abi:      parsed,
+		peers:    make(map[enode.ID]*enode.Node),
+	}, nil
+}
+
+// Loop refreshes the peers from the registry until the context is done
+func (r *PeerRegistry) Loop(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		if err := r.refresh(ctx); err != nil {
+			log.Error("Cannot refresh peers from registry", "address", r.address.Hex(), "msg", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh reads the list of peers from the registry contract and updates
+// the peers of the p2p server to match it
+func (r *PeerRegistry) refresh(ctx context.Context) error {
+	urls, err := r.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	peers := make(map[enode.ID]*enode.Node, len(urls))
+	for _, url := range urls {
+		node, err := enode.ParseV4(url)
+		if err != nil {
+			log.Warn("Skipping invalid enode in peer registry", "url", url, "msg", err)
+			continue
+		}
+		peers[node.ID()] = node
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for id, node := range r.peers {
+		if _, ok := peers[id]; !ok {
+			log.Info("Removing peer dropped from registry", "id", id)
+			r.server.RemovePeer(node)
+		}
+	}
+	for id, node := range peers {
+		if _, ok := r.peers[id]; !ok {
+			log.Info("Adding peer from registry", "id", id)
+			r.server.AddPeer(node)
+		}
+	}
+	r.peers = peers
+	peerRegistryPeersGauge.Update(int64(len(peers)))
+	return nil
+}
+
+// fetch calls the registry contract for the list of enode URLs
+func (r *PeerRegistry) fetch(ctx context.Context) ([]string, error) {
+	input, err := r.abi.Pack("getPeers")
+	if err != nil {
+		return nil, err
+	}
+	ret, err := r.caller.CallContract(ctx, ethereum.CallMsg{
+		To:   &r.address,
+		Data: input,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot call peer registry: %w", err)
+	}
+	var urls []string
+	if err := r.abi.Unpack(&urls, "getPeers", ret); err != nil {
+		return nil, fmt.Errorf("Cannot decode peer registry response: %w", err)
+	}
+	return urls, nil
+}
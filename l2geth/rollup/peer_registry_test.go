@@ -0,0 +1,61 @@
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	testEnode1 = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"
+	testEnode2 = "enode://3f1d12044546b76342d59d4a05532c14b85aa669704bfe1f864fe079415aa2c02d743e03218e57a33fb94523adb54032871a6c51b2cc5514cb7c7e35b3ed0a99@13.93.211.84:30303"
+)
+
+type mockPeerRegistryCaller struct {
+	registry *PeerRegistry
+	urls     []string
+}
+
+func (m *mockPeerRegistryCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return m.registry.abi.Methods["getPeers"].Outputs.Pack(m.urls)
+}
+
+type mockPeerServer struct {
+	peers map[enode.ID]bool
+}
+
+func (m *mockPeerServer) AddPeer(node *enode.Node)    { m.peers[node.ID()] = true }
+func (m *mockPeerServer) RemovePeer(node *enode.Node) { delete(m.peers, node.ID()) }
+
+func TestPeerRegistryRefresh(t *testing.T) {
+	caller := &mockPeerRegistryCaller{}
+	server := &mockPeerServer{peers: make(map[enode.ID]bool)}
+	registry, err := NewPeerRegistry(caller, common.Address{}, 0, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller.registry = registry
+
+	caller.urls = []string{testEnode1, testEnode2, "invalid"}
+	if err := registry.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.peers) != 2 {
+		t.Fatalf("Unexpected number of peers: got %d, expected %d", len(server.peers), 2)
+	}
+
+	caller.urls = []string{testEnode2}
+	if err := registry.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.peers) != 1 {
+		t.Fatalf("Unexpected number of peers: got %d, expected %d", len(server.peers), 1)
+	}
+	if !server.peers[enode.MustParseV4(testEnode2).ID()] {
+		t.Fatal("Peer in registry not added")
+	}
+}
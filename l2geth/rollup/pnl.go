@@ -0,0 +1,181 @@
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// maxPnLL1Blocks is the most L1 blocks whose proposer spend is read at once
+const maxPnLL1Blocks = 1000
+
+// stateBatchAppendedTopic is the topic of the event emitted by the state
+// commitment chain for every appended state batch
+var stateBatchAppendedTopic = crypto.Keccak256Hash([]byte("StateBatchAppended(uint256,bytes32,uint256,uint256,bytes)"))
+
+type l1Header struct {
+	Time hexutil.Uint64 `json:"timestamp"`
+}
+
+// pnlTracker keeps a profit and loss ledger of the sequencer in the database.
+// The revenue and batch submission spend of every reconciled batch is added
+// by the reconciler, along with the L2 fees paid by the oracle updater for
+// its updates of the gas price oracle in the batch. The proposer spend is
+// read from the state batches appended on L1.
+type pnlTracker struct {
+	db     ethdb.Database
+	bc     *core.BlockChain
+	l1     l1RPC
+	signer types.Signer
+	// owner returns the owner of the gas price oracle, which is the account
+	// of the oracle updater, nil when unknown
+	owner func() *common.Address
+	// stateCommitmentChain is the L1 contract that the proposer appends
+	// state batches to, the proposer spend is not tracked when it is zero
+	stateCommitmentChain common.Address
+}
+
+// recordBatch adds the revenue and spend of a reconciled batch to the ledger
+// at the time of its last block
+func (p *pnlTracker) recordBatch(report *fees.Reconciliation) error {
+	last := p.bc.GetHeaderByNumber(uint64(report.EndBlock))
+	if last == nil {
+		return fmt.Errorf("Cannot get block %d", report.EndBlock)
+	}
+	batch := p.db.NewBatch()
+	rawdb.WritePnLEntry(batch, &fees.PnLEntry{
+		Timestamp: last.Time,
+		Category:  fees.PnLRevenue,
+		Amount:    report.Revenue.ToInt(),
+		Reference: report.L1TxHash,
+	})
+	rawdb.WritePnLEntry(batch, &fees.PnLEntry{
+		Timestamp: last.Time,
+		Category:  fees.PnLBatchSubmission,
+		Amount:    report.Cost.ToInt(),
+		Reference: report.L1TxHash,
+	})
+	updater := p.owner()
+	for number := uint64(report.StartBlock); number <= uint64(report.EndBlock); number++ {
+		block := p.bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("Cannot get block %d", number)
+		}
+		receipts := p.bc.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(block.Transactions()) {
+			return fmt.Errorf("Cannot get receipts for block %d", number)
+		}
+		for i, tx := range block.Transactions() {
+			if updater == nil || tx.To() == nil || *tx.To() != rcfg.L2GasPriceOracleAddress || tx.GasPrice().Sign() == 0 {
+				continue
+			}
+			if from, err := types.Sender(p.signer, tx); err != nil || from != *updater {
+				continue
+			}
+			fee := new(big.Int).SetUint64(receipts[i].GasUsed)
+			rawdb.WritePnLEntry(batch, &fees.PnLEntry{
+				Timestamp: block.Time(),
+				Category:  fees.PnLOracleUpdater,
+				Amount:    fee.Mul(fee, tx.GasPrice()),
+				Reference: tx.Hash(),
+			})
+		}
+	}
+	return batch.Write()
+}
+
+// Loop adds the proposer spend to the ledger until the context is done
+func (p *pnlTracker) Loop(ctx context.Context, interval time.Duration) {
+	if p.stateCommitmentChain == (common.Address{}) {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		if err := p.recordProposerSpend(ctx); err != nil {
+			log.Error("Cannot add proposer spend to profit and loss ledger", "msg", err)
+		}
+	}
+}
+
+// recordProposerSpend adds the cost of the state batches appended since the
+// last call to the ledger, starting at the L1 head the first time
+func (p *pnlTracker) recordProposerSpend(ctx context.Context) error {
+	var head hexutil.Uint64
+	if err := p.l1.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return fmt.Errorf("Cannot get L1 head: %w", err)
+	}
+	from := uint64(head)
+	if next := rawdb.ReadPnLL1Block(p.db); next != nil {
+		from = *next
+	}
+	if from > uint64(head) {
+		return nil
+	}
+	to := uint64(head)
+	if to-from >= maxPnLL1Blocks {
+		to = from + maxPnLL1Blocks - 1
+	}
+	var logs []l1Log
+	filter := map[string]interface{}{
+		"fromBlock": hexutil.Uint64(from),
+		"toBlock":   hexutil.Uint64(to),
+		"address":   p.stateCommitmentChain,
+		"topics":    []interface{}{stateBatchAppendedTopic},
+	}
+	if err := p.l1.CallContext(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return fmt.Errorf("Cannot get logs: %w", err)
+	}
+	batch := p.db.NewBatch()
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		gasUsed, gasPrice, err := l1TxCost(ctx, p.l1, l.TxHash)
+		if err != nil {
+			return err
+		}
+		var header *l1Header
+		if err := p.l1.CallContext(ctx, &header, "eth_getBlockByNumber", l.BlockNumber, false); err != nil {
+			return fmt.Errorf("Cannot get L1 block %d: %w", l.BlockNumber, err)
+		}
+		if header == nil {
+			return fmt.Errorf("Cannot get L1 block %d", l.BlockNumber)
+		}
+		rawdb.WritePnLEntry(batch, &fees.PnLEntry{
+			Timestamp: uint64(header.Time),
+			Category:  fees.PnLProposer,
+			Amount:    new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice),
+			Reference: l.TxHash,
+		})
+	}
+	rawdb.WritePnLL1Block(batch, to+1)
+	return batch.Write()
+}
+
+// PnL returns the summary and the entries of the profit and loss ledger
+// between the from and to timestamps, nil when the ledger is disabled
+func (s *SyncService) PnL(from, to uint64) (*fees.PnLSummary, []*fees.PnLEntry) {
+	if s.pnl == nil {
+		return nil, nil
+	}
+	entries := rawdb.ReadPnLEntries(s.db, from, to)
+	return fees.SummarizePnL(from, to, entries), entries
+}
@@ -0,0 +1,47 @@
+package rollup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestPnLProposerSpend(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	db := rawdb.NewMemoryDatabase()
+	p := &pnlTracker{
+		db: db,
+		l1: &mockL1RPC{responses: map[string]string{
+			"eth_blockNumber":           `"0x64"`,
+			"eth_getLogs":               `[{"transactionHash":"` + txHash.Hex() + `","blockNumber":"0x64","removed":false}]`,
+			"eth_getTransactionReceipt": `{"gasUsed":"0x64","effectiveGasPrice":"0xa"}`,
+			"eth_getBlockByNumber":      `{"timestamp":"0x3e8"}`,
+		}},
+		stateCommitmentChain: common.Address{0x01},
+	}
+	if err := p.recordProposerSpend(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if next := rawdb.ReadPnLL1Block(db); next == nil || *next != 101 {
+		t.Fatalf("mismatched next L1 block: got %v, expect 101", next)
+	}
+	entries := rawdb.ReadPnLEntries(db, 0, 1000)
+	if len(entries) != 1 {
+		t.Fatalf("mismatched entries: got %d, expect 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Category != fees.PnLProposer || entry.Timestamp != 1000 || entry.Amount.Int64() != 1000 || entry.Reference != txHash {
+		t.Fatalf("mismatched entry: %s at %d of %d for %s", entry.Category, entry.Timestamp, entry.Amount, entry.Reference.Hex())
+	}
+
+	// The L1 head did not move, so nothing is read again
+	if err := p.recordProposerSpend(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if entries := rawdb.ReadPnLEntries(db, 0, 1000); len(entries) != 1 {
+		t.Fatalf("mismatched entries: got %d, expect 1", len(entries))
+	}
+}
@@ -0,0 +1,238 @@
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+	"github.com/ethereum/go-ethereum/tracing"
+)
+
+// txPrice is the fee that the sequencer expects for a transaction
+type txPrice struct {
+	// The L1 fee after the subsidy of the contract that the transaction is
+	// sent to, and the subsidized part when it is not zero
+	l1Fee     *big.Int
+	l1Subsidy *big.Int
+	// The transaction gas limit that pays the expected fee at
+	// fees.BigTxGasPrice, after the subsidy of the fee policy
+	expectedTxGasLimit *big.Int
+	policy             *FeePolicy
+	policyName         string
+}
+
+// priceTx computes the fee that the sequencer expects for a transaction from
+// the L1 fee parameters and the L2 gas price, applying the fee subsidies and
+// the fee policies at the snapshot
+func (s *SyncService) priceTx(tx *types.Transaction, snapshot *feeSnapshot, l1FeeParams *l1FeeParams, l2GasLimit, l2GasPrice *big.Int) (*txPrice, error) {
+	// Only count the calldata here as the overhead of the fully encoded
+	// RLP transaction is handled inside of EncodeL2GasLimit
+	price := &txPrice{l1Fee: s.l1FeeCache.l1Fee(tx, l1FeeParams)}
+	// Projects can subsidize the L1 fees of the transactions sent to their
+	// contracts through the OVM_FeeSubsidyRegistry
+	if to := tx.To(); to != nil {
+		if subsidy := snapshot.subsidies.read(*to); subsidy != nil && subsidy.Rate != 0 {
+			var subsidized *big.Int
+			price.l1Fee, subsidized = fees.SubsidizeL1Fee(price.l1Fee, subsidy.Rate, subsidy.Balance)
+			if subsidized.Sign() > 0 {
+				price.l1Subsidy = subsidized
+			}
+		}
+	}
+	price.expectedTxGasLimit = fees.EncodeTxGasLimitForL1Fee(price.l1Fee, l2GasLimit, l2GasPrice)
+
+	// This should only happen if the unscaled transaction fee is greater than 18.44 ETH
+	if !price.expectedTxGasLimit.IsUint64() {
+		return nil, fmt.Errorf("fee overflow: %s", price.expectedTxGasLimit.String())
+	}
+
+	// Fee policies can subsidize the fee of specific contracts and methods
+	price.policy, price.policyName = s.feePolicies.lookup(tx)
+	if price.policy != nil {
+		price.expectedTxGasLimit = price.policy.subsidize(price.expectedTxGasLimit)
+	}
+	return price, nil
+}
+
+// feeCheck is the check of the fee of a transaction before the fee hooks
+type feeCheck struct {
+	price *txPrice
+	// The gas limit that pays the expected fee at fees.BigTxGasPrice, and
+	// the options of PaysEnough that the fee is checked with
+	expectedTxGasLimit *big.Int
+	opts               fees.PaysEnoughOpts
+}
+
+// checkFee prices a transaction at the gas prices of the snapshot, or of the
+// fee quote when it is not nil, and returns the check of its fee. The values
+// are recorded in the fee decision and the span as they are computed. There
+// is no check when the transaction does not pay a fee, the decision records
+// why. decideFee and PoolTxFee share it, so that the pool explains the
+// decisions of the sequencer.
+func (s *SyncService) checkFee(ctx context.Context, tx *types.Transaction, snapshot *feeSnapshot, quote *feesig.FeeQuote, decision *feeDecision, span *tracing.Span) (*feeCheck, error) {
+	if s.noFees {
+		decision.decision = feeDecisionNoFees
+		return nil, nil
+	}
+	// Without an initialized oracle every fee computes to zero
+	if snapshot.uninitialized != nil {
+		return nil, snapshot.uninitialized
+	}
+
+	if tx.GasPrice().Cmp(common.Big0) == 0 {
+		// Transactions with a transfer authorization pay their fee in a
+		// fee token instead
+		if auth := feesig.TransferAuthorizationFromContext(ctx); auth != nil {
+			return nil, s.verifyFeeAuthorization(tx, auth, snapshot, decision)
+		}
+		// Allow 0 gas price transactions only if it is the owner of the gas
+		// price oracle
+		gpoOwner := snapshot.gpoOwner
+		if gpoOwner != nil {
+			from, err := types.Sender(s.signer, tx)
+			if err != nil {
+				return nil, fmt.Errorf("invalid transaction: %w", core.ErrInvalidSender)
+			}
+			if from == *gpoOwner {
+				decision.decision = feeDecisionOwner
+				return nil, nil
+			}
+		}
+		// Exit early if fees are enforced and the gasPrice is set to 0
+		if s.enforceFees {
+			return nil, errZeroGasPriceTx
+		}
+		// If fees are not enforced and the gas price is 0, return early
+		decision.decision = feeDecisionUnenforced
+		return nil, nil
+	}
+	// When the gas price is non zero, it must be equal to the constant
+	if tx.GasPrice().Cmp(fees.BigTxGasPrice) != 0 {
+		return nil, fmt.Errorf("tx.gasPrice must be %d", fees.TxGasPrice)
+	}
+	if snapshot.err != nil {
+		return nil, snapshot.err
+	}
+	l1GasPrice, l2GasPrice := snapshot.l1GasPrice, snapshot.l2GasPrice
+	l1FeeParams := snapshot.l1FeeParams
+	// Transactions submitted with a valid fee quote pay the quoted gas
+	// prices instead of the current ones
+	if quote != nil {
+		if err := s.verifyFeeQuote(quote, tx); err != nil {
+			markFeeQuote(err)
+			return nil, err
+		}
+		l1GasPrice, l2GasPrice = quote.L1GasPrice.ToInt(), quote.L2GasPrice.ToInt()
+		var err error
+		l1FeeParams, err = s.l1FeeParamsAt(ctx, l1GasPrice, l1FeeParams.calldataGas)
+		if err != nil {
+			return nil, err
+		}
+		span.SetAttribute("feeQuote", true)
+	}
+	// Calculate the fee based on decoded L2 gas limit
+	gas := new(big.Int).SetUint64(tx.Gas())
+	l2GasLimit := fees.DecodeL2GasLimit(gas)
+	decision.l1GasPrice = l1GasPrice
+	decision.l2GasPrice = l2GasPrice
+	decision.l2GasLimit = l2GasLimit
+
+	// When the L2 gas limit is smaller than the min L2 gas limit,
+	// reject the transaction
+	if l2GasLimit.Cmp(s.minL2GasLimit) == -1 {
+		return nil, fmt.Errorf("%w: %d, use at least %d", fees.ErrL2GasLimitTooLow, l2GasLimit, s.minL2GasLimit)
+	}
+
+	price, err := s.priceTx(tx, snapshot, l1FeeParams, l2GasLimit, l2GasPrice)
+	if err != nil {
+		return nil, err
+	}
+	decision.l1Fee = price.l1Fee
+	if price.l1Subsidy != nil {
+		decision.l1Subsidy = price.l1Subsidy
+		span.SetAttribute("l1Subsidy", price.l1Subsidy)
+	}
+	expectedTxGasLimit, policy := price.expectedTxGasLimit, price.policy
+	if policy != nil {
+		decision.policy = price.policyName
+		decision.policyThresholdUp, decision.policyThresholdDown = policy.ThresholdUp, policy.ThresholdDown
+		span.SetAttribute("feePolicy", price.policyName)
+	}
+
+	userFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+	expectedFee := new(big.Int).Mul(expectedTxGasLimit, fees.BigTxGasPrice)
+	span.SetAttribute("userFee", userFee)
+	span.SetAttribute("expectedFee", expectedFee)
+	decision.userFee = userFee
+	decision.expectedFee = expectedFee
+	return &feeCheck{
+		price:              price,
+		expectedTxGasLimit: expectedTxGasLimit,
+		opts: fees.PaysEnoughOpts{
+			UserFee:       userFee,
+			ExpectedFee:   expectedFee,
+			ThresholdUp:   policy.thresholdUp(s.feeThresholdUp),
+			ThresholdDown: policy.thresholdDown(snapshot.thresholdDown),
+		},
+	}, nil
+}
+
+// PoolTxFee checks the fee of a transaction of the transaction pool the same
+// way as verifyFee at the latest values of the gas price oracle, without
+// logging or recording the decision. It explains why transactions stay in
+// the pool.
+func (s *SyncService) PoolTxFee(tx *types.Transaction) *fees.PoolTxFee {
+	ctx := context.Background()
+	decision := &feeDecision{tx: tx, signer: s.signer}
+	check, err := s.checkFee(ctx, tx, s.snapshotFees(ctx), nil, decision, nil)
+	if check != nil && err == nil && len(s.feeHooks) != 0 {
+		_, err = s.checkFeeHooks(decision, &check.opts)
+	}
+	result := new(fees.PoolTxFee)
+	if check != nil {
+		result.L1Fee = (*hexutil.Big)(check.price.l1Fee)
+		result.UserFee = (*hexutil.Big)(check.opts.UserFee)
+		result.ExpectedFee = (*hexutil.Big)(check.opts.ExpectedFee)
+		if err == nil {
+			err = fees.PaysEnough(&check.opts)
+		}
+	}
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	result.PaysEnough = true
+	return result
+}
+
+// EstimateTxFee prices a transaction at the gas prices of the snapshot the
+// same way as verifyFee does, with the estimator of the data availability
+// layer, the fee subsidies at the tip and the fee policies, so that the
+// estimates pay the fee that is expected
+func (s *SyncService) EstimateTxFee(ctx context.Context, tx *types.Transaction, l2GasLimit *big.Int, oracle *fees.OracleSnapshot) (*fees.TxFeeEstimate, error) {
+	l1FeeParams, err := s.l1FeeParamsAt(ctx, oracle.L1GasPrice, oracle.CalldataGas)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &feeSnapshot{subsidies: s.feeSubsidies()}
+	price, err := s.priceTx(tx, snapshot, l1FeeParams, l2GasLimit, oracle.L2GasPrice)
+	if err != nil {
+		return nil, err
+	}
+	fee := new(big.Int).Mul(l2GasLimit, oracle.L2GasPrice)
+	fee.Add(fee, price.l1Fee)
+	// The fee policy subsidizes the fee without changing the L2 gas limit
+	// that the gas limit encodes
+	gasLimit := fees.EncodeTxGasLimitForL1Fee(price.l1Fee, l2GasLimit, oracle.L2GasPrice)
+	return &fees.TxFeeEstimate{
+		L1Fee:    price.l1Fee,
+		Fee:      price.policy.subsidize(fee),
+		GasLimit: price.policy.subsidizeGasLimit(gasLimit),
+	}, nil
+}
@@ -0,0 +1,63 @@
+package rollup
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestPoolTxFeeMatchesVerifyFee(t *testing.T) {
+	service, _, _, err := newTestSyncService(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.enforceFees = true
+	l1GasPrice, l2GasPrice := big.NewInt(100*params.GWei), big.NewInt(1*params.GWei)
+	service.RollupGpo.SetL1GasPrice(l1GasPrice)
+	service.RollupGpo.SetL2GasPrice(l2GasPrice)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	data := []byte{0, 1, 2, 3}
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, big.NewInt(100_000), l2GasPrice).Uint64()
+	tests := map[string]struct {
+		gasLimit uint64
+		gasPrice *big.Int
+		l1Fee    bool
+	}{
+		"exact":           {gasLimit, fees.BigTxGasPrice, true},
+		"too-low":         {gasLimit / 2, fees.BigTxGasPrice, true},
+		"too-high":        {gasLimit * 10, fees.BigTxGasPrice, true},
+		"zero-gas-price":  {gasLimit, new(big.Int), false},
+		"wrong-gas-price": {gasLimit, big.NewInt(2), false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := types.NewTransaction(0, common.Address{}, new(big.Int), tt.gasLimit, tt.gasPrice, data)
+			tx, err := types.SignTx(tx, signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := service.verifyFee(context.Background(), tx)
+			fee := service.PoolTxFee(tx)
+			if fee.PaysEnough != (expect == nil) {
+				t.Fatalf("mismatched check: got %t, expect %v", fee.PaysEnough, expect)
+			}
+			if !fee.PaysEnough && fee.Reason == "" {
+				t.Fatal("no reason for a rejected fee")
+			}
+			if (fee.L1Fee != nil) != tt.l1Fee {
+				t.Fatalf("mismatched L1 fee: %v", fee.L1Fee)
+			}
+			if tt.l1Fee && fee.L1Fee.ToInt().Cmp(new(big.Int).Mul(fees.CalculateL1GasUsed(data), l1GasPrice)) != 0 {
+				t.Fatalf("mismatched L1 fee: got %d", fee.L1Fee.ToInt())
+			}
+		})
+	}
+}
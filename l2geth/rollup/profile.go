@@ -0,0 +1,101 @@
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// NetworkProfiles are the fee parameters of several networks, keyed by their
+// decimal L2 chain ID. The same file is shared by the sequencer, the gas
+// oracle and the batch submitter so that a single deployment configuration
+// can serve several rollups, each service reads its own section of the
+// profile of the network that it runs on.
+type NetworkProfiles map[string]*NetworkProfile
+
+// NetworkProfile is the fee configuration of a single network
+type NetworkProfile struct {
+	// Address of the OVM_GasPriceOracle, read by the gas oracle. The
+	// sequencer always reads the gas prices from the predeploy.
+	GasPriceOracleAddress *common.Address `json:"gasPriceOracleAddress,omitempty"`
+	// Parameters of the sequencer
+	Sequencer *SequencerProfile `json:"sequencer,omitempty"`
+	// The sections of the other services are kept so that the file can be
+	// validated as a whole, they are not interpreted by l2geth
+	GasOracle      json.RawMessage `json:"gasOracle,omitempty"`
+	BatchSubmitter json.RawMessage `json:"batchSubmitter,omitempty"`
+}
+
+// SequencerProfile are the fee parameters of the sequencer. Values that are
+// not set are left to the command line flags.
+type SequencerProfile struct {
+	FeeThresholdUp             *float64        `json:"feeThresholdUp,omitempty"`
+	FeeThresholdDown           *float64        `json:"feeThresholdDown,omitempty"`
+	MinL2GasLimit              *hexutil.Uint64 `json:"minL2GasLimit,omitempty"`
+	GasPriceOracleOwnerAddress *common.Address `json:"gasPriceOracleOwnerAddress,omitempty"`
+}
+
+// LoadNetworkProfiles reads the network profiles from a JSON file
+func LoadNetworkProfiles(path string) (NetworkProfiles, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read network profiles: %w", err)
+	}
+	var profiles NetworkProfiles
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, fmt.Errorf("Cannot decode network profiles: %w", err)
+	}
+	for key := range profiles {
+		if _, ok := new(big.Int).SetString(key, 10); !ok {
+			return nil, fmt.Errorf("%w: network profile key %q is not a decimal chain id", errBadConfig, key)
+		}
+	}
+	return profiles, nil
+}
+
+// Profile returns the profile of the chain, nil when there is none
+func (p NetworkProfiles) Profile(chainID *big.Int) *NetworkProfile {
+	if p == nil || chainID == nil {
+		return nil
+	}
+	return p[chainID.String()]
+}
+
+// apply sets the parameters of the sequencer profile that are not already
+// set in the config, so that command line flags take precedence
+func (p *SequencerProfile) apply(cfg *Config) {
+	if p == nil {
+		return
+	}
+	if p.FeeThresholdUp != nil && cfg.FeeThresholdUp == nil {
+		cfg.FeeThresholdUp = new(big.Float).SetFloat64(*p.FeeThresholdUp)
+	}
+	if p.FeeThresholdDown != nil && cfg.FeeThresholdDown == nil {
+		cfg.FeeThresholdDown = new(big.Float).SetFloat64(*p.FeeThresholdDown)
+	}
+	if p.MinL2GasLimit != nil && cfg.MinL2GasLimit == nil {
+		cfg.MinL2GasLimit = new(big.Int).SetUint64(uint64(*p.MinL2GasLimit))
+	}
+	if p.GasPriceOracleOwnerAddress != nil && cfg.GasPriceOracleOwnerAddress == (common.Address{}) {
+		cfg.GasPriceOracleOwnerAddress = *p.GasPriceOracleOwnerAddress
+	}
+	log.Info("Applied sequencer network profile", "threshold-up", cfg.FeeThresholdUp,
+		"threshold-down", cfg.FeeThresholdDown, "min-l2-gas-limit", cfg.MinL2GasLimit,
+		"gpo-owner", cfg.GasPriceOracleOwnerAddress.Hex())
+}
+
+// ApplyNetworkProfile fills in the parameters that are not set with the
+// profile of the chain. It returns false when profiles are configured but
+// there is none for the chain.
+func (c *Config) ApplyNetworkProfile(chainID *big.Int) bool {
+	if profile := c.NetworkProfiles.Profile(chainID); profile != nil {
+		profile.Sequencer.apply(c)
+		return true
+	}
+	return c.NetworkProfiles == nil
+}
@@ -0,0 +1,105 @@
+package rollup
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNetworkProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := write("valid.json", `{
+		"10": {
+			"gasPriceOracleAddress": "0x420000000000000000000000000000000000000f",
+			"sequencer": {"feeThresholdUp": 3, "feeThresholdDown": 0.5, "minL2GasLimit": "0x1"},
+			"gasOracle": {"floorPrice": 1}
+		},
+		"69": {"sequencer": {"gasPriceOracleOwnerAddress": "0x0000000000000000000000000000000000000001"}}
+	}`)
+	badKey := write("bad-key.json", `{"optimism": {}}`)
+
+	if _, err := LoadNetworkProfiles(badKey); !errors.Is(err, errBadConfig) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, errBadConfig)
+	}
+	if _, err := LoadNetworkProfiles(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+	profiles, err := LoadNetworkProfiles(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		chainID *big.Int
+		cfg     Config
+		expect  Config
+	}{
+		"unknown-chain": {
+			chainID: big.NewInt(420),
+		},
+		"fills-unset": {
+			chainID: big.NewInt(10),
+			expect: Config{
+				FeeThresholdUp:   big.NewFloat(3),
+				FeeThresholdDown: big.NewFloat(0.5),
+				MinL2GasLimit:    big.NewInt(1),
+			},
+		},
+		"flags-take-precedence": {
+			chainID: big.NewInt(10),
+			cfg: Config{
+				FeeThresholdUp: big.NewFloat(2),
+			},
+			expect: Config{
+				FeeThresholdUp:   big.NewFloat(2),
+				FeeThresholdDown: big.NewFloat(0.5),
+				MinL2GasLimit:    big.NewInt(1),
+			},
+		},
+		"owner": {
+			chainID: big.NewInt(69),
+			expect: Config{
+				GasPriceOracleOwnerAddress: common.HexToAddress("0x01"),
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := tt.cfg
+			if profile := profiles.Profile(tt.chainID); profile != nil {
+				profile.Sequencer.apply(&cfg)
+			}
+			if !equalFloat(cfg.FeeThresholdUp, tt.expect.FeeThresholdUp) {
+				t.Fatalf("mismatched threshold up: got %v, expect %v", cfg.FeeThresholdUp, tt.expect.FeeThresholdUp)
+			}
+			if !equalFloat(cfg.FeeThresholdDown, tt.expect.FeeThresholdDown) {
+				t.Fatalf("mismatched threshold down: got %v, expect %v", cfg.FeeThresholdDown, tt.expect.FeeThresholdDown)
+			}
+			if (cfg.MinL2GasLimit == nil) != (tt.expect.MinL2GasLimit == nil) ||
+				(cfg.MinL2GasLimit != nil && cfg.MinL2GasLimit.Cmp(tt.expect.MinL2GasLimit) != 0) {
+				t.Fatalf("mismatched min l2 gas limit: got %v, expect %v", cfg.MinL2GasLimit, tt.expect.MinL2GasLimit)
+			}
+			if cfg.GasPriceOracleOwnerAddress != tt.expect.GasPriceOracleOwnerAddress {
+				t.Fatalf("mismatched owner: got %s, expect %s", cfg.GasPriceOracleOwnerAddress.Hex(), tt.expect.GasPriceOracleOwnerAddress.Hex())
+			}
+		})
+	}
+}
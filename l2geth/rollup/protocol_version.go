@@ -0,0 +1,279 @@
+package rollup
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// SupportedProtocolVersion is the latest protocol version that this binary
+// is able to follow. It must be updated with every release that includes a
+// consensus change.
+var SupportedProtocolVersion = NewProtocolVersion(0, 5, 0, 0)
+
+// protocolVersionInterval is how often the protocol versions contract is
+// polled for changes
+const protocolVersionInterval = time.Minute
+
+var (
+	// errUnsupportedProtocolVersion is the error for when the network has
+	// activated a protocol version that this binary does not support
+	errUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+	// errBadProtocolVersion is the error for when the protocol versions
+	// contract returns a malformed value
+	errBadProtocolVersion = errors.New("bad protocol version")
+)
+
+var (
+	protocolVersionRequiredGauge    = metrics.NewRegisteredGauge("rollup/protocol/required", nil)
+	protocolVersionRecommendedGauge = metrics.NewRegisteredGauge("rollup/protocol/recommended", nil)
+	protocolVersionSupportedGauge   = metrics.NewRegisteredGauge("rollup/protocol/supported", nil)
+)
+
+var (
+	requiredProtocolVersionSelector    = crypto.Keccak256([]byte("required()"))[:4]
+	recommendedProtocolVersionSelector = crypto.Keccak256([]byte("recommended()"))[:4]
+)
+
+// ProtocolVersion is a 32 byte value that encodes a protocol version. The
+// first byte is the version type, which must be zero. Bytes 8 to 16 are an
+// opaque build identifier, followed by the major, minor, patch and
+// prerelease components as big endian 32 bit integers.
+type ProtocolVersion [32]byte
+
+// NewProtocolVersion creates a ProtocolVersion from its components
+func NewProtocolVersion(major, minor, patch, prerelease uint32) ProtocolVersion {
+	var v ProtocolVersion
+	binary.BigEndian.PutUint32(v[16:20], major)
+	binary.BigEndian.PutUint32(v[20:24], minor)
+	binary.BigEndian.PutUint32(v[24:28], patch)
+	binary.BigEndian.PutUint32(v[28:32], prerelease)
+	return v
+}
+
+// Major returns the major component of the protocol version
+func (v ProtocolVersion) Major() uint32 { return binary.BigEndian.Uint32(v[16:20]) }
+
+// Minor returns the minor component of the protocol version
+func (v ProtocolVersion) Minor() uint32 { return binary.BigEndian.Uint32(v[20:24]) }
+
+// Patch returns the patch component of the protocol version
+func (v ProtocolVersion) Patch() uint32 { return binary.BigEndian.Uint32(v[24:28]) }
+
+// Prerelease returns the prerelease component of the protocol version, zero
+// for a full release
+func (v ProtocolVersion) Prerelease() uint32 { return binary.BigEndian.Uint32(v[28:32]) }
+
+// IsZero returns true when no protocol version has been signaled
+func (v ProtocolVersion) IsZero() bool {
+	return v == ProtocolVersion{}
+}
+
+// String implements the fmt.Stringer interface
+func (v ProtocolVersion) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	if pre := v.Prerelease(); pre != 0 {
+		s += fmt.Sprintf("-%d", pre)
+	}
+	return s
+}
+
+// Compare returns -1 if v is older than other, 1 if it is newer and 0 if
+// they are equal. The comparison stops at the given level of precision, so
+// comparing at ProtocolVersionMinor ignores the patch and prerelease
+// components. A prerelease is older than the full release it precedes.
+func (v ProtocolVersion) Compare(other ProtocolVersion, precision ProtocolVersionPrecision) int {
+	cmp := func(a, b uint32) int {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	if c := cmp(v.Major(), other.Major()); c != 0 || precision == ProtocolVersionMajor {
+		return c
+	}
+	if c := cmp(v.Minor(), other.Minor()); c != 0 || precision == ProtocolVersionMinor {
+		return c
+	}
+	if c := cmp(v.Patch(), other.Patch()); c != 0 || precision == ProtocolVersionPatch {
+		return c
+	}
+	a, b := v.Prerelease(), other.Prerelease()
+	switch {
+	case a == b:
+		return 0
+	case a == 0:
+		return 1
+	case b == 0:
+		return -1
+	}
+	return cmp(a, b)
+}
+
+// ProtocolVersionPrecision is the version component that a signaled protocol
+// version must differ in for the node to halt
+type ProtocolVersionPrecision uint8
+
+const (
+	// ProtocolVersionNone never halts the node
+	ProtocolVersionNone ProtocolVersionPrecision = iota
+	ProtocolVersionMajor
+	ProtocolVersionMinor
+	ProtocolVersionPatch
+)
+
+// String implements the fmt.Stringer interface
+func (p ProtocolVersionPrecision) String() string {
+	switch p {
+	case ProtocolVersionMajor:
+		return "major"
+	case ProtocolVersionMinor:
+		return "minor"
+	case ProtocolVersionPatch:
+		return "patch"
+	}
+	return "none"
+}
+
+// ParseProtocolVersionPrecision parses the halt setting from its
+// configuration string
+func ParseProtocolVersionPrecision(s string) (ProtocolVersionPrecision, error) {
+	switch s {
+	case "", "none":
+		return ProtocolVersionNone, nil
+	case "major":
+		return ProtocolVersionMajor, nil
+	case "minor":
+		return ProtocolVersionMinor, nil
+	case "patch":
+		return ProtocolVersionPatch, nil
+	}
+	return ProtocolVersionNone, fmt.Errorf("unknown protocol version precision: %s", s)
+}
+
+// ProtocolVersionsReader reads the protocol versions signaled on L1
+type ProtocolVersionsReader interface {
+	RequiredProtocolVersion(context.Context) (ProtocolVersion, error)
+	RecommendedProtocolVersion(context.Context) (ProtocolVersion, error)
+}
+
+// ProtocolVersionsClient reads the protocol versions from the L1 contract
+// that signals them
+type ProtocolVersionsClient struct {
+	client  *ethclient.Client
+	address common.Address
+}
+
+// NewProtocolVersionsClient connects to an L1 node and returns a
+// ProtocolVersionsClient for the contract at the given address
+func NewProtocolVersionsClient(url string, address common.Address) (*ProtocolVersionsClient, error) {
+	client, err := ethclient.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+	}
+	return &ProtocolVersionsClient{
+		client:  client,
+		address: address,
+	}, nil
+}
+
+// RequiredProtocolVersion returns the protocol version that nodes must
+// support to follow the network
+func (c *ProtocolVersionsClient) RequiredProtocolVersion(ctx context.Context) (ProtocolVersion, error) {
+	return c.call(ctx, requiredProtocolVersionSelector)
+}
+
+// RecommendedProtocolVersion returns the protocol version that nodes should
+// upgrade to
+func (c *ProtocolVersionsClient) RecommendedProtocolVersion(ctx context.Context) (ProtocolVersion, error) {
+	return c.call(ctx, recommendedProtocolVersionSelector)
+}
+
+func (c *ProtocolVersionsClient) call(ctx context.Context, selector []byte) (ProtocolVersion, error) {
+	var version ProtocolVersion
+	ret, err := c.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &c.address,
+		Data: selector,
+	}, nil)
+	if err != nil {
+		return version, err
+	}
+	if len(ret) != 32 {
+		return version, fmt.Errorf("%w: unexpected length %d", errBadProtocolVersion, len(ret))
+	}
+	copy(version[:], ret)
+	if version[0] != 0 {
+		return version, fmt.Errorf("%w: unknown version type %d", errBadProtocolVersion, version[0])
+	}
+	return version, nil
+}
+
+// checkProtocolVersion compares the protocol versions signaled on L1 to the
+// version supported by this binary. A warning is logged when the binary is
+// older than the recommended version. An error is returned when the binary
+// is older than the required version at the configured precision.
+func (s *SyncService) checkProtocolVersion() error {
+	ctx, cancel := context.WithTimeout(s.ctx, protocolVersionInterval)
+	defer cancel()
+
+	required, err := s.protocolVersions.RequiredProtocolVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("Cannot fetch required protocol version: %w", err)
+	}
+	recommended, err := s.protocolVersions.RecommendedProtocolVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("Cannot fetch recommended protocol version: %w", err)
+	}
+	protocolVersionRequiredGauge.Update(int64(required.Major()))
+	protocolVersionRecommendedGauge.Update(int64(recommended.Major()))
+	protocolVersionSupportedGauge.Update(int64(SupportedProtocolVersion.Major()))
+
+	if !recommended.IsZero() && SupportedProtocolVersion.Compare(recommended, ProtocolVersionPatch) < 0 {
+		log.Warn("Recommended protocol version not supported, upgrade soon",
+			"supported", SupportedProtocolVersion, "recommended", recommended)
+	}
+	if required.IsZero() || SupportedProtocolVersion.Compare(required, ProtocolVersionPatch) >= 0 {
+		return nil
+	}
+	if s.protocolVersionHalt != ProtocolVersionNone &&
+		SupportedProtocolVersion.Compare(required, s.protocolVersionHalt) < 0 {
+		return fmt.Errorf("%w: supported %s, required %s", errUnsupportedProtocolVersion,
+			SupportedProtocolVersion, required)
+	}
+	log.Warn("Required protocol version not supported, upgrade now",
+		"supported", SupportedProtocolVersion, "required", required)
+	return nil
+}
+
+// ProtocolVersionLoop periodically checks the protocol versions signaled on
+// L1 and halts the node when it can no longer follow the network
+func (s *SyncService) ProtocolVersionLoop() {
+	t := time.NewTicker(protocolVersionInterval)
+	defer t.Stop()
+	for {
+		err := s.checkProtocolVersion()
+		if errors.Is(err, errUnsupportedProtocolVersion) {
+			log.Crit("Halting on incompatible protocol version", "msg", err)
+		}
+		if err != nil {
+			log.Error("Cannot check protocol version", "msg", err)
+		}
+		select {
+		case <-t.C:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockProtocolVersions struct {
+	required    ProtocolVersion
+	recommended ProtocolVersion
+}
+
+func (m *mockProtocolVersions) RequiredProtocolVersion(context.Context) (ProtocolVersion, error) {
+	return m.required, nil
+}
+
+func (m *mockProtocolVersions) RecommendedProtocolVersion(context.Context) (ProtocolVersion, error) {
+	return m.recommended, nil
+}
+
+func TestProtocolVersionCompare(t *testing.T) {
+	tests := map[string]struct {
+		a, b      ProtocolVersion
+		precision ProtocolVersionPrecision
+		expect    int
+	}{
+		"equal":           {NewProtocolVersion(1, 2, 3, 0), NewProtocolVersion(1, 2, 3, 0), ProtocolVersionPatch, 0},
+		"major-older":     {NewProtocolVersion(1, 9, 9, 0), NewProtocolVersion(2, 0, 0, 0), ProtocolVersionMajor, -1},
+		"minor-ignored":   {NewProtocolVersion(1, 1, 0, 0), NewProtocolVersion(1, 2, 0, 0), ProtocolVersionMajor, 0},
+		"minor-newer":     {NewProtocolVersion(1, 3, 0, 0), NewProtocolVersion(1, 2, 0, 0), ProtocolVersionMinor, 1},
+		"patch-ignored":   {NewProtocolVersion(1, 2, 0, 0), NewProtocolVersion(1, 2, 1, 0), ProtocolVersionMinor, 0},
+		"patch-older":     {NewProtocolVersion(1, 2, 0, 0), NewProtocolVersion(1, 2, 1, 0), ProtocolVersionPatch, -1},
+		"prerelease":      {NewProtocolVersion(1, 2, 0, 1), NewProtocolVersion(1, 2, 0, 0), ProtocolVersionNone, -1},
+		"prerelease-more": {NewProtocolVersion(1, 2, 0, 2), NewProtocolVersion(1, 2, 0, 1), ProtocolVersionNone, 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.a.Compare(tt.b, tt.precision); got != tt.expect {
+				t.Fatalf("Unexpected comparison of %s and %s: got %d, expected %d", tt.a, tt.b, got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestSyncServiceCheckProtocolVersion(t *testing.T) {
+	supported := SupportedProtocolVersion
+	next := NewProtocolVersion(supported.Major()+1, 0, 0, 0)
+	tests := map[string]struct {
+		required ProtocolVersion
+		halt     ProtocolVersionPrecision
+		err      error
+	}{
+		"none-signaled": {ProtocolVersion{}, ProtocolVersionMajor, nil},
+		"supported":     {supported, ProtocolVersionPatch, nil},
+		"warn-only":     {next, ProtocolVersionNone, nil},
+		"halt":          {next, ProtocolVersionMajor, errUnsupportedProtocolVersion},
+		"below-precision": {
+			NewProtocolVersion(supported.Major(), supported.Minor(), supported.Patch()+1, 0),
+			ProtocolVersionMinor,
+			nil,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, _, _, err := newTestSyncService(true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			service.protocolVersions = &mockProtocolVersions{required: tt.required, recommended: tt.required}
+			service.protocolVersionHalt = tt.halt
+			if err := service.checkProtocolVersion(); !errors.Is(err, tt.err) {
+				t.Fatalf("Unexpected error: got %v, expected %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package rcfg
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// L2GasPriceOracleVersionSlot refers to the storage slot that the version of
+// the storage layout of the OVM_GasPriceOracle is stored in. It is derived
+// from a hash so that it cannot collide with the sequential slots of any
+// layout, the original layout does not set it and reads as version 0.
+var L2GasPriceOracleVersionSlot = common.BigToHash(new(big.Int).Sub(
+	crypto.Keccak256Hash([]byte("optimism.gaspriceoracle.layoutversion")).Big(),
+	big.NewInt(1),
+))
+
+// ErrUnknownLayout represents the error case of an OVM_GasPriceOracle whose
+// storage layout version is not known to this node
+var ErrUnknownLayout = errors.New("unknown gas price oracle storage layout")
+
+// ErrGPOUninitialized represents the error case of an OVM_GasPriceOracle
+// whose storage has not been set, in which case every fee computes to zero
+var ErrGPOUninitialized = errors.New("gas price oracle is not initialized")
+
+// StateReader is the part of the state that the storage slots of the
+// OVM_GasPriceOracle are read from
+type StateReader interface {
+	GetState(addr common.Address, key common.Hash) common.Hash
+}
+
+// Layout is the position of the values of the OVM_GasPriceOracle in its
+// storage at a layout version
+type Layout struct {
+	Owner    common.Hash
+	GasPrice common.Hash
+	// L1Fee is the position of the values that price the L1 fee on chain,
+	// nil when the layout does not store them
+	L1Fee *L1FeeLayout
+}
+
+// L1FeeLayout is the position of the values of the OVM_GasPriceOracle that
+// price the L1 fee on chain
+type L1FeeLayout struct {
+	L1GasPrice common.Hash
+	Overhead   common.Hash
+	Scalar     common.Hash
+	Decimals   common.Hash
+}
+
+// Layouts are the storage layouts of the OVM_GasPriceOracle by version. A new
+// version is added here before the contract that uses it is deployed.
+var Layouts = map[uint64]Layout{
+	0: {Owner: L2GasPriceOracleOwnerSlot, GasPrice: L2GasPriceSlot},
+	// Version 1 stores the L1 gas price, the overhead and the scalar of the
+	// L1 fee after the values of version 0
+	1: {
+		Owner:    common.BigToHash(big.NewInt(0)),
+		GasPrice: common.BigToHash(big.NewInt(1)),
+		L1Fee: &L1FeeLayout{
+			L1GasPrice: common.BigToHash(big.NewInt(2)),
+			Overhead:   common.BigToHash(big.NewInt(3)),
+			Scalar:     common.BigToHash(big.NewInt(4)),
+			Decimals:   common.BigToHash(big.NewInt(5)),
+		},
+	},
+}
+
+// GPOStorageSlots are the values of the OVM_GasPriceOracle that configure
+// fees
+type GPOStorageSlots struct {
+	Version  uint64
+	Owner    common.Address
+	GasPrice *big.Int
+	// L1Fee is nil when the layout does not store the values of the L1 fee
+	L1Fee *L1FeeParams
+}
+
+// L1FeeParams are the values of the OVM_GasPriceOracle that price the L1 fee
+// on chain. The L1 fee is the L1 gas used, including the overhead, at the
+// L1 gas price times the scalar, which has the given number of decimals.
+type L1FeeParams struct {
+	L1GasPrice *big.Int
+	Overhead   *big.Int
+	Scalar     *big.Int
+	Decimals   *big.Int
+}
+
+// Initialized returns true when the owner and the gas price of the
+// OVM_GasPriceOracle have been set
+func (s *GPOStorageSlots) Initialized() bool {
+	return s.Owner != (common.Address{}) && s.GasPrice != nil && s.GasPrice.Sign() != 0
+}
+
+// ReadLayoutVersion returns the storage layout version of the
+// OVM_GasPriceOracle
+func ReadLayoutVersion(db StateReader) uint64 {
+	return db.GetState(L2GasPriceOracleAddress, L2GasPriceOracleVersionSlot).Big().Uint64()
+}
+
+// ReadGPOStorageSlots reads the values of the OVM_GasPriceOracle with the
+// storage layout of the version that the contract reports
+func ReadGPOStorageSlots(db StateReader) (*GPOStorageSlots, error) {
+	version := ReadLayoutVersion(db)
+	layout, ok := Layouts[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrUnknownLayout, version)
+	}
+	return readLayout(db, version, layout), nil
+}
+
+func readLayout(db StateReader, version uint64, layout Layout) *GPOStorageSlots {
+	slots := &GPOStorageSlots{
+		Version:  version,
+		Owner:    common.BytesToAddress(db.GetState(L2GasPriceOracleAddress, layout.Owner).Bytes()),
+		GasPrice: db.GetState(L2GasPriceOracleAddress, layout.GasPrice).Big(),
+	}
+	if layout.L1Fee != nil {
+		slots.L1Fee = readL1FeeParams(db, layout.L1Fee)
+	}
+	return slots
+}
+
+// ReadL1FeeParams reads the values of the OVM_GasPriceOracle that price the
+// L1 fee on chain, without the values that only the node prices fees with
+func ReadL1FeeParams(db StateReader) (*L1FeeParams, error) {
+	version := ReadLayoutVersion(db)
+	layout, ok := Layouts[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrUnknownLayout, version)
+	}
+	if layout.L1Fee == nil {
+		return nil, fmt.Errorf("%w: version %d does not store the L1 fee", ErrUnknownLayout, version)
+	}
+	return readL1FeeParams(db, layout.L1Fee), nil
+}
+
+func readL1FeeParams(db StateReader, layout *L1FeeLayout) *L1FeeParams {
+	return &L1FeeParams{
+		L1GasPrice: db.GetState(L2GasPriceOracleAddress, layout.L1GasPrice).Big(),
+		Overhead:   db.GetState(L2GasPriceOracleAddress, layout.Overhead).Big(),
+		Scalar:     db.GetState(L2GasPriceOracleAddress, layout.Scalar).Big(),
+		Decimals:   db.GetState(L2GasPriceOracleAddress, layout.Decimals).Big(),
+	}
+}
+
+// LayoutMigration reads the OVM_GasPriceOracle while its storage moves from
+// one layout version to another. During the transition window the contract
+// may report either version, and the slots of the new layout may not all be
+// populated yet, so values that are unset in the new layout are read from
+// the old one. This lets nodes be upgraded ahead of the contract instead of
+// in lockstep with it.
+type LayoutMigration struct {
+	From uint64
+	To   uint64
+}
+
+// ParseLayoutMigration parses a migration in the form "from:to"
+func ParseLayoutMigration(s string) (*LayoutMigration, error) {
+	var m LayoutMigration
+	if _, err := fmt.Sscanf(s, "%d:%d", &m.From, &m.To); err != nil {
+		return nil, fmt.Errorf("invalid layout migration %q, expected from:to: %w", s, err)
+	}
+	for _, version := range []uint64{m.From, m.To} {
+		if _, ok := Layouts[version]; !ok {
+			return nil, fmt.Errorf("%w: version %d", ErrUnknownLayout, version)
+		}
+	}
+	return &m, nil
+}
+
+// Read reads the values of the OVM_GasPriceOracle during the migration
+func (m *LayoutMigration) Read(db StateReader) (*GPOStorageSlots, error) {
+	version := ReadLayoutVersion(db)
+	if version != m.From && version != m.To {
+		return nil, fmt.Errorf("%w: version %d during migration from %d to %d", ErrUnknownLayout, version, m.From, m.To)
+	}
+	from, ok := Layouts[m.From]
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrUnknownLayout, m.From)
+	}
+	to, ok := Layouts[m.To]
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrUnknownLayout, m.To)
+	}
+	if version == m.From {
+		return readLayout(db, version, from), nil
+	}
+	slots := readLayout(db, version, to)
+	old := readLayout(db, version, from)
+	if slots.Owner == (common.Address{}) {
+		slots.Owner = old.Owner
+	}
+	if slots.GasPrice.Sign() == 0 {
+		slots.GasPrice = old.GasPrice
+	}
+	return slots, nil
+}
@@ -0,0 +1,179 @@
+package rcfg
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type testState map[common.Hash]common.Hash
+
+func (s testState) GetState(addr common.Address, key common.Hash) common.Hash {
+	if addr != L2GasPriceOracleAddress {
+		return common.Hash{}
+	}
+	return s[key]
+}
+
+func TestReadGPOStorageSlots(t *testing.T) {
+	owner := common.HexToAddress("0x1234")
+	newOwner := common.HexToAddress("0x5678")
+
+	// Layout 9 moves the values of layout 0 up by ten slots
+	Layouts[9] = Layout{
+		Owner:    common.BigToHash(big.NewInt(10)),
+		GasPrice: common.BigToHash(big.NewInt(11)),
+	}
+	defer delete(Layouts, 9)
+
+	legacy := testState{
+		L2GasPriceOracleOwnerSlot: common.BytesToHash(owner.Bytes()),
+		L2GasPriceSlot:            common.BigToHash(big.NewInt(1)),
+	}
+	// The contract reports the new layout but has only moved the owner
+	partial := testState{
+		L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(9)),
+		L2GasPriceOracleOwnerSlot:   common.BytesToHash(owner.Bytes()),
+		L2GasPriceSlot:              common.BigToHash(big.NewInt(1)),
+		Layouts[9].Owner:            common.BytesToHash(newOwner.Bytes()),
+	}
+	unknown := testState{
+		L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(10)),
+	}
+
+	tests := map[string]struct {
+		state     testState
+		migration *LayoutMigration
+		owner     common.Address
+		gasPrice  int64
+		err       error
+	}{
+		"legacy": {
+			state:    legacy,
+			owner:    owner,
+			gasPrice: 1,
+		},
+		"partial": {
+			state:    partial,
+			owner:    newOwner,
+			gasPrice: 0,
+		},
+		"unknown": {
+			state: unknown,
+			err:   ErrUnknownLayout,
+		},
+		"migration-legacy": {
+			state:     legacy,
+			migration: &LayoutMigration{From: 0, To: 9},
+			owner:     owner,
+			gasPrice:  1,
+		},
+		"migration-partial": {
+			state:     partial,
+			migration: &LayoutMigration{From: 0, To: 9},
+			owner:     newOwner,
+			gasPrice:  1,
+		},
+		"migration-unknown": {
+			state:     unknown,
+			migration: &LayoutMigration{From: 0, To: 9},
+			err:       ErrUnknownLayout,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var slots *GPOStorageSlots
+			var err error
+			if tt.migration != nil {
+				slots, err = tt.migration.Read(tt.state)
+			} else {
+				slots, err = ReadGPOStorageSlots(tt.state)
+			}
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if slots.Owner != tt.owner {
+				t.Fatalf("mismatched owner: got %s, expect %s", slots.Owner.Hex(), tt.owner.Hex())
+			}
+			if slots.GasPrice.Int64() != tt.gasPrice {
+				t.Fatalf("mismatched gas price: got %d, expect %d", slots.GasPrice, tt.gasPrice)
+			}
+		})
+	}
+}
+
+func TestParseLayoutMigration(t *testing.T) {
+	if _, err := ParseLayoutMigration("0:0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseLayoutMigration("0:99"); !errors.Is(err, ErrUnknownLayout) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrUnknownLayout)
+	}
+	if _, err := ParseLayoutMigration("0"); err == nil {
+		t.Fatal("expected error for malformed migration")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	predeploy := L2GasPriceOracleAddress
+	address := common.HexToAddress("0x1234")
+	gasPriceSlot := common.BigToHash(big.NewInt(5))
+	owner := common.HexToAddress("0x5678")
+
+	Configure(&params.ChainConfig{
+		GasPriceOracle: &params.GasPriceOracleConfig{Address: &address, GasPriceSlot: &gasPriceSlot},
+	})
+	defer Configure(nil)
+
+	state := testState{
+		common.BigToHash(big.NewInt(0)): common.BytesToHash(owner.Bytes()),
+		gasPriceSlot:                    common.BigToHash(big.NewInt(7)),
+	}
+	slots, err := ReadGPOStorageSlots(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slots.Owner != owner {
+		t.Fatalf("mismatched owner: got %s, expect %s", slots.Owner.Hex(), owner.Hex())
+	}
+	if slots.GasPrice.Int64() != 7 {
+		t.Fatalf("mismatched gas price: got %d, expect %d", slots.GasPrice, 7)
+	}
+	if GasPriceOracleSlots[1].Key != gasPriceSlot {
+		t.Fatalf("mismatched gas price slot: got %s, expect %s", GasPriceOracleSlots[1].Key.Hex(), gasPriceSlot.Hex())
+	}
+
+	// Chains without a gas price oracle config read the predeploy
+	Configure(nil)
+	if L2GasPriceOracleAddress != predeploy || L2GasPriceSlot != common.BigToHash(big.NewInt(1)) {
+		t.Fatalf("mismatched predeploy: got %s at %s", L2GasPriceOracleAddress.Hex(), L2GasPriceSlot.Hex())
+	}
+}
+
+func TestReadL1FeeParams(t *testing.T) {
+	if _, err := ReadL1FeeParams(testState{}); !errors.Is(err, ErrUnknownLayout) {
+		t.Fatalf("mismatched error of version 0: got %v, expect %v", err, ErrUnknownLayout)
+	}
+	layout := Layouts[1].L1Fee
+	state := testState{
+		L2GasPriceOracleVersionSlot: common.BigToHash(big.NewInt(1)),
+		layout.L1GasPrice:           common.BigToHash(big.NewInt(20)),
+		layout.Overhead:             common.BigToHash(big.NewInt(2100)),
+		layout.Scalar:               common.BigToHash(big.NewInt(1_500_000)),
+		layout.Decimals:             common.BigToHash(big.NewInt(6)),
+	}
+	params, err := ReadL1FeeParams(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.L1GasPrice.Int64() != 20 || params.Overhead.Int64() != 2100 || params.Scalar.Int64() != 1_500_000 || params.Decimals.Int64() != 6 {
+		t.Fatalf("mismatched L1 fee params: got %+v", params)
+	}
+}
@@ -0,0 +1,88 @@
+// Package rcfg holds the rollup configuration that is read from the state of
+// the predeployed system contracts
+package rcfg
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// L2GasPriceOracleAddress is the address of the OVM_GasPriceOracle
+	// predeploy
+	L2GasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+	// L2GasPriceOracleOwnerSlot refers to the storage slot that the owner of
+	// the OVM_GasPriceOracle is stored in
+	L2GasPriceOracleOwnerSlot = common.BigToHash(big.NewInt(0))
+	// L2GasPriceSlot refers to the storage slot that the L2 gas price is stored
+	// in in the OVM_GasPriceOracle predeploy
+	L2GasPriceSlot = common.BigToHash(big.NewInt(1))
+	// L2ToL1MessagePasserAddress is the address of the
+	// OVM_L2ToL1MessagePasser predeploy, withdrawals are proven against its
+	// storage
+	L2ToL1MessagePasserAddress = common.HexToAddress("0x4200000000000000000000000000000000000000")
+	// SentMessagesSlot refers to the storage slot of the mapping of the sent
+	// messages in the OVM_L2ToL1MessagePasser predeploy
+	SentMessagesSlot = common.BigToHash(big.NewInt(0))
+)
+
+// Slot is a named storage slot of the OVM_GasPriceOracle
+type Slot struct {
+	Name string
+	Key  common.Hash
+}
+
+// GasPriceOracleSlots are the storage slots of the OVM_GasPriceOracle that
+// configure fees
+var GasPriceOracleSlots = []Slot{
+	{Name: "owner", Key: L2GasPriceOracleOwnerSlot},
+	{Name: "gasPrice", Key: L2GasPriceSlot},
+	{Name: "version", Key: L2GasPriceOracleVersionSlot},
+}
+
+// PredeployGasPriceOracleAddress is the address of the OVM_GasPriceOracle
+// predeploy, L2GasPriceOracleAddress unless the chain config moves it
+var PredeployGasPriceOracleAddress = L2GasPriceOracleAddress
+
+// The slots of the OVM_GasPriceOracle predeploy, restored by Configure for
+// chains that do not override them
+var (
+	predeployOwnerSlot    = L2GasPriceOracleOwnerSlot
+	predeployGasPriceSlot = L2GasPriceSlot
+	predeployVersionSlot  = L2GasPriceOracleVersionSlot
+)
+
+// Configure sets the location of the OVM_GasPriceOracle and of its values in
+// its storage to those of the chain config, so that deployments whose gas
+// price oracle is not the predeploy run with the same binary. It must be
+// called when the chain config is loaded, before the state is read.
+func Configure(config *params.ChainConfig) {
+	address, owner, gasPrice, version := PredeployGasPriceOracleAddress, predeployOwnerSlot, predeployGasPriceSlot, predeployVersionSlot
+	if config != nil && config.GasPriceOracle != nil {
+		gpo := config.GasPriceOracle
+		if gpo.Address != nil {
+			address = *gpo.Address
+		}
+		if gpo.OwnerSlot != nil {
+			owner = *gpo.OwnerSlot
+		}
+		if gpo.GasPriceSlot != nil {
+			gasPrice = *gpo.GasPriceSlot
+		}
+		if gpo.VersionSlot != nil {
+			version = *gpo.VersionSlot
+		}
+	}
+	L2GasPriceOracleAddress = address
+	L2GasPriceOracleOwnerSlot = owner
+	L2GasPriceSlot = gasPrice
+	L2GasPriceOracleVersionSlot = version
+	Layouts[0] = Layout{Owner: owner, GasPrice: gasPrice}
+	GasPriceOracleSlots = []Slot{
+		{Name: "owner", Key: owner},
+		{Name: "gasPrice", Key: gasPrice},
+		{Name: "version", Key: version},
+	}
+}
@@ -0,0 +1,43 @@
+package rcfg
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// L2FeeRebateVaultAddress is the address of the OVM_FeeRebateVault
+	// predeploy, where the rebates of the senders that were overcharged for
+	// the L1 cost of their transactions are recorded and claimed
+	L2FeeRebateVaultAddress = common.HexToAddress("0x4200000000000000000000000000000000000021")
+	// feeRebatesSlot is the slot of the mapping from sender to the claimable
+	// rebate
+	feeRebatesSlot = common.BigToHash(big.NewInt(0))
+	// FeeRebateNextBatchSlot is the slot of the index of the transaction
+	// batch after the last batch whose rebates were recorded
+	FeeRebateNextBatchSlot = common.BigToHash(big.NewInt(1))
+)
+
+// FeeRebateSlot returns the storage slot of the claimable rebate of the
+// sender
+func FeeRebateSlot(sender common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.BytesToHash(sender.Bytes()).Bytes(), feeRebatesSlot.Bytes())
+}
+
+// ReadFeeRebate reads the claimable rebate of the sender from the
+// OVM_FeeRebateVault
+func ReadFeeRebate(db StateReader, sender common.Address) *big.Int {
+	return db.GetState(L2FeeRebateVaultAddress, FeeRebateSlot(sender)).Big()
+}
+
+// ReadFeeRebateNextBatch reads the index of the first transaction batch whose
+// rebates were not recorded in the OVM_FeeRebateVault
+func ReadFeeRebateNextBatch(db StateReader) uint64 {
+	next := db.GetState(L2FeeRebateVaultAddress, FeeRebateNextBatchSlot).Big()
+	if !next.IsUint64() {
+		return ^uint64(0)
+	}
+	return next.Uint64()
+}
@@ -0,0 +1,70 @@
+package rcfg
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// L2FeeSubsidyRegistryAddress is the address of the OVM_FeeSubsidyRegistry
+	// predeploy, where projects deposit funds to subsidize the L1 fees of the
+	// transactions sent to their contracts
+	L2FeeSubsidyRegistryAddress = common.HexToAddress("0x4200000000000000000000000000000000000020")
+	// feeSubsidyBalancesSlot is the slot of the mapping from contract to the
+	// remaining subsidy balance
+	feeSubsidyBalancesSlot = common.BigToHash(big.NewInt(0))
+	// feeSubsidyRatesSlot is the slot of the mapping from contract to the
+	// subsidized fraction of the L1 fee in basis points
+	feeSubsidyRatesSlot = common.BigToHash(big.NewInt(1))
+)
+
+// StateWriter is the part of the state that the storage slots of the
+// predeploys are written to
+type StateWriter interface {
+	StateReader
+	SetState(addr common.Address, key, value common.Hash)
+}
+
+// FeeSubsidy is the subsidy of the L1 fees of the transactions sent to a
+// contract
+type FeeSubsidy struct {
+	// Rate is the subsidized fraction of the L1 fee in basis points
+	Rate uint64
+	// Balance is the amount that is left to subsidize fees with
+	Balance *big.Int
+}
+
+// FeeSubsidyBalanceSlot returns the storage slot of the subsidy balance of
+// the contract
+func FeeSubsidyBalanceSlot(contract common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.BytesToHash(contract.Bytes()).Bytes(), feeSubsidyBalancesSlot.Bytes())
+}
+
+// FeeSubsidyRateSlot returns the storage slot of the subsidy rate of the
+// contract
+func FeeSubsidyRateSlot(contract common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.BytesToHash(contract.Bytes()).Bytes(), feeSubsidyRatesSlot.Bytes())
+}
+
+// ReadFeeSubsidy reads the subsidy of the contract from the
+// OVM_FeeSubsidyRegistry. A contract without a subsidy has a zero rate.
+func ReadFeeSubsidy(db StateReader, contract common.Address) *FeeSubsidy {
+	rate := db.GetState(L2FeeSubsidyRegistryAddress, FeeSubsidyRateSlot(contract)).Big()
+	if !rate.IsUint64() {
+		rate.SetUint64(^uint64(0))
+	}
+	return &FeeSubsidy{
+		Rate:    rate.Uint64(),
+		Balance: db.GetState(L2FeeSubsidyRegistryAddress, FeeSubsidyBalanceSlot(contract)).Big(),
+	}
+}
+
+// DebitFeeSubsidy subtracts the amount from the subsidy balance of the
+// contract, which must cover it
+func DebitFeeSubsidy(db StateWriter, contract common.Address, amount *big.Int) {
+	slot := FeeSubsidyBalanceSlot(contract)
+	balance := db.GetState(L2FeeSubsidyRegistryAddress, slot).Big()
+	db.SetState(L2FeeSubsidyRegistryAddress, slot, common.BigToHash(balance.Sub(balance, amount)))
+}
@@ -0,0 +1,100 @@
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// receiptHydrationBatch is the number of blocks that are hydrated at every
+// interval, so that hydration does not hold up the sync of new blocks
+const receiptHydrationBatch = 100
+
+var receiptHydrationGauge = metrics.NewRegisteredGauge("rollup/receipts/hydration", nil)
+
+// receiptHydrator records the L1 fee in the receipts of the blocks that were
+// processed before the L1 fee was recorded in receipts, so that explorers
+// see the same receipt fields across the whole history of the chain. The fee
+// is recovered from the fee params recorded with each block, or else from the
+// state of its parent, which requires an archive node for the blocks whose
+// state was pruned.
+type receiptHydrator struct {
+	bc *core.BlockChain
+	db ethdb.Database
+}
+
+func newReceiptHydrator(bc *core.BlockChain, db ethdb.Database) *receiptHydrator {
+	return &receiptHydrator{bc: bc, db: db}
+}
+
+// Loop hydrates the receipts of the chain from the persisted progress up to
+// the head until the context is done. It stops when the state of a block is
+// not available.
+func (h *receiptHydrator) Loop(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		hydrated, err := h.hydrate(receiptHydrationBatch)
+		if err != nil {
+			log.Error("Cannot hydrate receipts", "msg", err)
+			return
+		}
+		if hydrated != 0 {
+			log.Info("Hydrated receipts with L1 fees", "blocks", hydrated)
+		}
+	}
+}
+
+// hydrate records the L1 fee in the receipts of up to count blocks that do
+// not have it and returns the number of blocks that it hydrated
+func (h *receiptHydrator) hydrate(count int) (int, error) {
+	next := uint64(1)
+	if progress := rawdb.ReadReceiptHydrationProgress(h.db); progress != nil {
+		next = *progress
+	}
+	head := h.bc.CurrentBlock().NumberU64()
+	hydrated := 0
+	defer func() {
+		rawdb.WriteReceiptHydrationProgress(h.db, next)
+		receiptHydrationGauge.Update(int64(next))
+	}()
+	for ; next <= head && hydrated < count; next++ {
+		block := h.bc.GetBlockByNumber(next)
+		if block == nil {
+			return hydrated, fmt.Errorf("Cannot get block %d", next)
+		}
+		receipts := h.bc.GetReceiptsByHash(block.Hash())
+		if len(receipts) == 0 || receipts[0].L1Fee != nil {
+			continue
+		}
+		if params := rawdb.ReadBlockFeeParams(h.db, block.Hash(), next); params != nil {
+			core.HydrateL1FeeReceiptsAt(h.bc.Config(), block, receipts, params.L2GasPrice)
+			rawdb.WriteL1FeeReceipts(h.db, block.Hash(), next, receipts)
+			hydrated++
+			continue
+		}
+		parent := h.bc.GetBlock(block.ParentHash(), next-1)
+		if parent == nil {
+			return hydrated, fmt.Errorf("Cannot get parent of block %d", next)
+		}
+		statedb, err := h.bc.StateAt(parent.Root())
+		if err != nil {
+			return hydrated, fmt.Errorf("Cannot get state of block %d, hydrating receipts requires an archive node: %w", next-1, err)
+		}
+		core.HydrateL1FeeReceipts(h.bc.Config(), block, receipts, statedb)
+		rawdb.WriteL1FeeReceipts(h.db, block.Hash(), next, receipts)
+		hydrated++
+	}
+	return hydrated, nil
+}
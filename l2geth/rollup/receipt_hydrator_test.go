@@ -0,0 +1,100 @@
+package rollup
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+func TestReceiptHydrator(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		from       = crypto.PubkeyToAddress(key.PublicKey)
+		chainID    = big.NewInt(420)
+		signer     = types.NewEIP155Signer(chainID)
+		l1GasPrice = big.NewInt(params.GWei)
+		l2GasPrice = big.NewInt(params.GWei)
+	)
+	// The receipts of the chain are generated without the L1 fee
+	chainCfg := *params.AllEthashProtocolChanges
+	chainCfg.ChainID = chainID
+	chainCfg.RollupForks = []params.RollupFork{{
+		Name:     "fees",
+		L1Block:  new(big.Int),
+		Features: []params.RollupFeature{params.RollupFeatureL1CalldataGas, params.RollupFeatureMinTxSize},
+	}}
+	genesis := &core.Genesis{
+		Config:   &chainCfg,
+		GasLimit: 10_000_000_000,
+		Alloc: core.GenesisAlloc{
+			from: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))},
+			rcfg.L2GasPriceOracleAddress: {
+				Balance: new(big.Int),
+				Storage: map[common.Hash]common.Hash{
+					rcfg.L2GasPriceSlot: common.BigToHash(l2GasPrice),
+				},
+			},
+		},
+	}
+	engine := ethash.NewFaker()
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, &chainCfg, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	data := []byte{0x00, 0x01}
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, big.NewInt(30_000), l2GasPrice)
+	blocks, _ := core.GenerateChain(&chainCfg, chain.CurrentBlock(), engine, db, 3, func(i int, b *core.BlockGen) {
+		tx := types.NewTransaction(uint64(i), common.Address{}, new(big.Int), gasLimit.Uint64(), fees.BigTxGasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(signed)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if receipts := rawdb.ReadReceipts(db, blocks[0].Hash(), 1, &chainCfg); receipts[0].L1Fee != nil {
+		t.Fatal("L1 fee recorded before hydration")
+	}
+
+	hydrator := newReceiptHydrator(chain, db)
+	hydrated, err := hydrator.hydrate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hydrated != 2 {
+		t.Fatalf("mismatched hydrated blocks: got %d, expect 2", hydrated)
+	}
+	if progress := rawdb.ReadReceiptHydrationProgress(db); progress == nil || *progress != 3 {
+		t.Fatalf("mismatched hydration progress: got %v, expect 3", progress)
+	}
+	if hydrated, _ := hydrator.hydrate(receiptHydrationBatch); hydrated != 1 {
+		t.Fatalf("mismatched hydrated blocks: got %d, expect 1", hydrated)
+	}
+
+	expect := fees.MaxChargedL1Fee(gasLimit.Uint64(), l2GasPrice)
+	for _, block := range blocks {
+		receipts := rawdb.ReadReceipts(db, block.Hash(), block.NumberU64(), &chainCfg)
+		if receipts[0].L1Fee == nil || receipts[0].L1Fee.Cmp(expect) != 0 {
+			t.Fatalf("block %d: mismatched L1 fee: got %v, expect %v", block.NumberU64(), receipts[0].L1Fee, expect)
+		}
+	}
+	if hydrated, _ := hydrator.hydrate(receiptHydrationBatch); hydrated != 0 {
+		t.Fatalf("blocks hydrated twice: %d", hydrated)
+	}
+}
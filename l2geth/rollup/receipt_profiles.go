@@ -0,0 +1,69 @@
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// receiptNamespaces are the RPC namespaces that serve receipts
+var receiptNamespaces = map[string]bool{
+	"eth":    true,
+	"rollup": true,
+}
+
+// ReceiptProfiles are the receipt profiles of the RPC namespaces that serve
+// receipts, keyed by namespace. The namespaces without a profile serve the
+// rollup fields of receipts under their own names.
+type ReceiptProfiles map[string]*fees.ReceiptProfile
+
+// LoadReceiptProfiles reads the receipt profiles of RPC namespaces from a
+// JSON file. A profile is either the name of a built in profile or an
+// object with the names of the rollup fields, for example
+//
+//	{"eth": "strip", "rollup": {"names": {"l1Fee": "l1_fee"}}}
+func LoadReceiptProfiles(path string) (ReceiptProfiles, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read receipt profiles: %w", err)
+	}
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("Cannot decode receipt profiles: %w", err)
+	}
+	profiles := make(ReceiptProfiles, len(entries))
+	for namespace, entry := range entries {
+		if !receiptNamespaces[namespace] {
+			return nil, fmt.Errorf("%w: namespace %q does not serve receipts", errBadConfig, namespace)
+		}
+		var name string
+		if err := json.Unmarshal(entry, &name); err == nil {
+			profile, ok := fees.ReceiptProfiles[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown receipt profile %q of namespace %q", errBadConfig, name, namespace)
+			}
+			profiles[namespace] = profile
+			continue
+		}
+		profile := new(fees.ReceiptProfile)
+		if err := json.Unmarshal(entry, profile); err != nil {
+			return nil, fmt.Errorf("Cannot decode receipt profile of namespace %q: %w", namespace, err)
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("namespace %q: %w", namespace, err)
+		}
+		profiles[namespace] = profile
+	}
+	return profiles, nil
+}
+
+// Profile returns the receipt profile of the namespace, the default one when
+// it has none
+func (p ReceiptProfiles) Profile(namespace string) *fees.ReceiptProfile {
+	if profile, ok := p[namespace]; ok {
+		return profile
+	}
+	return fees.DefaultReceiptProfile
+}
@@ -0,0 +1,75 @@
+package rollup
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestLoadReceiptProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "receipt-profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		json   string
+		eth    *fees.ReceiptProfile
+		rollup *fees.ReceiptProfile
+		err    error
+	}{
+		"built-in": {
+			json:   `{"eth": "strip"}`,
+			eth:    fees.StripReceiptProfile,
+			rollup: fees.DefaultReceiptProfile,
+		},
+		"custom": {
+			json:   `{"eth": "inject", "rollup": {"names": {"l1Fee": "l1_fee"}, "alwaysL1Fee": true}}`,
+			eth:    fees.InjectReceiptProfile,
+			rollup: &fees.ReceiptProfile{Names: map[string]string{fees.ReceiptFieldL1Fee: "l1_fee"}, AlwaysL1Fee: true},
+		},
+		"unknown-profile": {
+			json: `{"eth": "bedrock"}`,
+			err:  errBadConfig,
+		},
+		"unknown-namespace": {
+			json: `{"debug": "strip"}`,
+			err:  errBadConfig,
+		},
+		"invalid-profile": {
+			json: `{"rollup": {"names": {"l1Fee": "status"}}}`,
+			err:  fees.ErrInvalidReceiptProfile,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".json")
+			if err := ioutil.WriteFile(path, []byte(tt.json), 0600); err != nil {
+				t.Fatal(err)
+			}
+			profiles, err := LoadReceiptProfiles(path)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			for namespace, expect := range map[string]*fees.ReceiptProfile{"eth": tt.eth, "rollup": tt.rollup} {
+				got := profiles.Profile(namespace)
+				if got.AlwaysL1Fee != expect.AlwaysL1Fee || len(got.Names) != len(expect.Names) {
+					t.Fatalf("mismatched %s profile: got %+v, expect %+v", namespace, got, expect)
+				}
+				for field, name := range expect.Names {
+					if got.Names[field] != name {
+						t.Fatalf("mismatched %s profile: got %+v, expect %+v", namespace, got, expect)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// maxReconciliations is the number of batch reconciliations kept in memory
+const maxReconciliations = 1024
+
+var (
+	// errReconcileNotSynced is the error for when a batch cannot be reconciled
+	// yet because the local chain does not include all of its transactions
+	errReconcileNotSynced = errors.New("batch not yet synced")
+	// errBatchTxNotFound is the error for when the L1 transaction that
+	// appended a batch cannot be found
+	errBatchTxNotFound = errors.New("batch submission not found")
+)
+
+// transactionBatchAppendedTopic is the topic of the event emitted by the
+// canonical transaction chain for every appended transaction batch
+var transactionBatchAppendedTopic = crypto.Keccak256Hash([]byte("TransactionBatchAppended(uint256,bytes32,uint256,uint256,bytes)"))
+
+var (
+	reconcileRevenueCounter = metrics.NewRegisteredCounter("rollup/reconcile/revenue", nil)
+	reconcileCostCounter    = metrics.NewRegisteredCounter("rollup/reconcile/cost", nil)
+	reconcileMarginGauge    = metrics.NewRegisteredGauge("rollup/reconcile/margin", nil)
+	reconcileBatchGauge     = metrics.NewRegisteredGauge("rollup/reconcile/batch", nil)
+)
+
+// l1RPC is the subset of the RPC client used to read batch submissions
+type l1RPC interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// The L1 responses are decoded with minimal types instead of the types in
+// core/types so that transaction types introduced on L1 after this client
+// was written can still be reconciled
+type l1Log struct {
+	TxHash      common.Hash    `json:"transactionHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Removed     bool           `json:"removed"`
+}
+
+type l1Receipt struct {
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+}
+
+type l1Transaction struct {
+	GasPrice *hexutil.Big `json:"gasPrice"`
+}
+
+// reconciler joins the fees collected on L2 for the transactions in each
+// transaction batch with the L1 gas spent by the batch submitter to append
+// the batch, exporting the net margin as metrics and keeping the most recent
+// results in memory so that they can be queried over RPC
+type reconciler struct {
+	l1     l1RPC
+	client RollupClient
+	bc     *core.BlockChain
+	// rebate is called with every reconciled batch before it is recorded,
+	// the batch is reconciled again when it fails
+	rebate func(context.Context, *fees.Reconciliation) error
+	// pnl adds every reconciled batch to the profit and loss ledger
+	pnl *pnlTracker
+
+	lock    sync.RWMutex
+	reports []*fees.Reconciliation
+	margin  *big.Int
+}
+
+func newReconciler(l1 l1RPC, client RollupClient, bc *core.BlockChain) *reconciler {
+	return &reconciler{
+		l1:     l1,
+		client: client,
+		bc:     bc,
+		margin: new(big.Int),
+	}
+}
+
+// Loop reconciles every batch appended after startup until the context is
+// done
+func (r *reconciler) Loop(ctx context.Context, interval time.Duration) {
+	var next *uint64
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		latest, err := r.client.GetLatestTransactionBatchIndex()
+		if err != nil {
+			log.Error("Cannot get latest batch index for reconciliation", "msg", err)
+			continue
+		}
+		if latest == nil {
+			continue
+		}
+		if next == nil {
+			start := *latest
+			next = &start
+		}
+		for *next <= *latest {
+			report, err := r.reconcile(ctx, *next)
+			if errors.Is(err, errReconcileNotSynced) {
+				break
+			}
+			if err != nil {
+				log.Error("Cannot reconcile batch", "index", *next, "msg", err)
+				break
+			}
+			if r.rebate != nil {
+				if err := r.rebate(ctx, report); err != nil {
+					log.Error("Cannot record fee rebates", "index", *next, "msg", err)
+					break
+				}
+			}
+			r.record(report)
+			if r.pnl != nil {
+				if err := r.pnl.recordBatch(report); err != nil {
+					log.Error("Cannot add batch to profit and loss ledger", "index", *next, "msg", err)
+				}
+			}
+			*next++
+		}
+	}
+}
+
+// reconcile computes the revenue and cost of a single transaction batch
+func (r *reconciler) reconcile(ctx context.Context, index uint64) (*fees.Reconciliation, error) {
+	batch, _, err := r.client.GetTransactionBatch(index)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get batch %d: %w", index, err)
+	}
+	if batch == nil {
+		return nil, fmt.Errorf("Cannot get batch %d: %w", index, errElementNotFound)
+	}
+	// Handle the off by one, the first transaction in the batch is in the
+	// block after the previous total elements
+	start := uint64(batch.PrevTotalElements) + 1
+	end := uint64(batch.PrevTotalElements) + uint64(batch.Size)
+	if r.bc.CurrentBlock().NumberU64() < end {
+		return nil, errReconcileNotSynced
+	}
+
+	revenue := new(big.Int)
+	txs := uint64(0)
+	for number := start; number <= end; number++ {
+		block := r.bc.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("Cannot get block %d", number)
+		}
+		receipts := r.bc.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(block.Transactions()) {
+			return nil, fmt.Errorf("Cannot get receipts for block %d", number)
+		}
+		for i, tx := range block.Transactions() {
+			fee := new(big.Int).SetUint64(receipts[i].GasUsed)
+			revenue.Add(revenue, fee.Mul(fee, tx.GasPrice()))
+			txs++
+		}
+	}
+
+	txHash, gasUsed, gasPrice, err := r.batchSubmission(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	return fees.NewReconciliation(index, txHash, gasUsed, gasPrice, start, end, txs, revenue), nil
+}
+
+// batchSubmission finds the L1 transaction that appended the batch and
+// returns its hash, the gas it used and the price paid for that gas
+func (r *reconciler) batchSubmission(ctx context.Context, batch *Batch) (common.Hash, uint64, *big.Int, error) {
+	var logs []l1Log
+	filter := map[string]interface{}{
+		"fromBlock": hexutil.Uint64(batch.BlockNumber),
+		"toBlock":   hexutil.Uint64(batch.BlockNumber),
+		"topics": []interface{}{
+			transactionBatchAppendedTopic,
+			common.BigToHash(new(big.Int).SetUint64(batch.Index)),
+		},
+	}
+	if err := r.l1.CallContext(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return common.Hash{}, 0, nil, fmt.Errorf("Cannot get logs: %w", err)
+	}
+	var txHash *common.Hash
+	for _, l := range logs {
+		if !l.Removed {
+			txHash = &l.TxHash
+			break
+		}
+	}
+	if txHash == nil {
+		return common.Hash{}, 0, nil, fmt.Errorf("%w: batch %d at L1 block %d", errBatchTxNotFound,
+			batch.Index, batch.BlockNumber)
+	}
+	gasUsed, gasPrice, err := l1TxCost(ctx, r.l1, *txHash)
+	if err != nil {
+		return common.Hash{}, 0, nil, err
+	}
+	return *txHash, gasUsed, gasPrice, nil
+}
+
+// l1TxCost returns the gas used by an L1 transaction and the price paid for
+// that gas
+func l1TxCost(ctx context.Context, l1 l1RPC, txHash common.Hash) (uint64, *big.Int, error) {
+	var receipt *l1Receipt
+	if err := l1.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return 0, nil, fmt.Errorf("Cannot get receipt %s: %w", txHash.Hex(), err)
+	}
+	if receipt == nil {
+		return 0, nil, fmt.Errorf("%w: no receipt for %s", errBatchTxNotFound, txHash.Hex())
+	}
+	// Nodes that predate EIP-1559 do not include the effective gas price in
+	// the receipt, in which case the gas price of the transaction is used
+	if receipt.EffectiveGasPrice != nil {
+		return uint64(receipt.GasUsed), receipt.EffectiveGasPrice.ToInt(), nil
+	}
+	var tx *l1Transaction
+	if err := l1.CallContext(ctx, &tx, "eth_getTransactionByHash", txHash); err != nil {
+		return 0, nil, fmt.Errorf("Cannot get transaction %s: %w", txHash.Hex(), err)
+	}
+	if tx == nil || tx.GasPrice == nil {
+		return 0, nil, fmt.Errorf("%w: no gas price for %s", errBatchTxNotFound, txHash.Hex())
+	}
+	return uint64(receipt.GasUsed), tx.GasPrice.ToInt(), nil
+}
+
+// record exports the metrics for a reconciled batch and keeps it in memory
+func (r *reconciler) record(report *fees.Reconciliation) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reports = append(r.reports, report)
+	if len(r.reports) > maxReconciliations {
+		r.reports = r.reports[len(r.reports)-maxReconciliations:]
+	}
+	r.margin.Add(r.margin, report.Margin.ToInt())
+
+	gwei := big.NewInt(params.GWei)
+	reconcileRevenueCounter.Inc(new(big.Int).Div(report.Revenue.ToInt(), gwei).Int64())
+	reconcileCostCounter.Inc(new(big.Int).Div(report.Cost.ToInt(), gwei).Int64())
+	reconcileMarginGauge.Update(new(big.Int).Div(r.margin, gwei).Int64())
+	reconcileBatchGauge.Update(int64(report.BatchIndex))
+
+	log.Info("Reconciled batch", "index", uint64(report.BatchIndex), "txs", uint64(report.Transactions),
+		"revenue", report.Revenue.ToInt(), "cost", report.Cost.ToInt(), "margin", report.Margin.ToInt())
+}
+
+// Reports returns up to count of the most recently reconciled batches, the
+// most recent last
+func (r *reconciler) Reports(count int) []*fees.Reconciliation {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if count <= 0 || count > len(r.reports) {
+		count = len(r.reports)
+	}
+	reports := make([]*fees.Reconciliation, count)
+	copy(reports, r.reports[len(r.reports)-count:])
+	return reports
+}
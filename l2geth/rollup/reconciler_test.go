@@ -0,0 +1,107 @@
+package rollup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// mockL1RPC responds to RPC calls with canned JSON responses by method
+type mockL1RPC struct {
+	responses map[string]string
+}
+
+func (m *mockL1RPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	res, ok := m.responses[method]
+	if !ok {
+		return errors.New("method not found")
+	}
+	return json.Unmarshal([]byte(res), result)
+}
+
+func TestReconcilerBatchSubmission(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	tests := map[string]struct {
+		responses map[string]string
+		gasPrice  *big.Int
+		err       error
+	}{
+		"effective-gas-price": {
+			responses: map[string]string{
+				"eth_getLogs":               `[{"transactionHash":"` + txHash.Hex() + `","removed":false}]`,
+				"eth_getTransactionReceipt": `{"gasUsed":"0x64","effectiveGasPrice":"0xa"}`,
+			},
+			gasPrice: big.NewInt(10),
+		},
+		"legacy-gas-price": {
+			responses: map[string]string{
+				"eth_getLogs":               `[{"transactionHash":"` + txHash.Hex() + `","removed":false}]`,
+				"eth_getTransactionReceipt": `{"gasUsed":"0x64"}`,
+				"eth_getTransactionByHash":  `{"gasPrice":"0x14"}`,
+			},
+			gasPrice: big.NewInt(20),
+		},
+		"removed": {
+			responses: map[string]string{
+				"eth_getLogs": `[{"transactionHash":"` + txHash.Hex() + `","removed":true}]`,
+			},
+			err: errBatchTxNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newReconciler(&mockL1RPC{responses: tt.responses}, nil, nil)
+			hash, gasUsed, gasPrice, err := r.batchSubmission(context.Background(), &Batch{Index: 1, BlockNumber: 10})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Unexpected error: got %v, expected %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if hash != txHash || gasUsed != 100 || gasPrice.Cmp(tt.gasPrice) != 0 {
+				t.Fatalf("Unexpected submission: %s %d %d", hash.Hex(), gasUsed, gasPrice)
+			}
+		})
+	}
+}
+
+func TestReconcilerNotSynced(t *testing.T) {
+	service, _, _, err := newTestSyncService(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupMockClient(service, map[string]interface{}{
+		"GetTransactionBatch": []*Batch{{Index: 0, Size: 5}},
+	})
+
+	r := newReconciler(&mockL1RPC{}, service.client, service.bc)
+	if _, err := r.reconcile(context.Background(), 0); !errors.Is(err, errReconcileNotSynced) {
+		t.Fatalf("Unexpected error: got %v, expected %v", err, errReconcileNotSynced)
+	}
+}
+
+func TestReconcilerReports(t *testing.T) {
+	r := newReconciler(&mockL1RPC{}, nil, nil)
+	for i := uint64(0); i < maxReconciliations+10; i++ {
+		r.record(fees.NewReconciliation(i, common.Hash{}, 100, big.NewInt(1), i+1, i+1, 1, big.NewInt(150)))
+	}
+	reports := r.Reports(0)
+	if len(reports) != maxReconciliations {
+		t.Fatalf("Unexpected number of reports: got %d, expected %d", len(reports), maxReconciliations)
+	}
+	latest := r.Reports(1)
+	if uint64(latest[0].BatchIndex) != maxReconciliations+9 {
+		t.Fatalf("Unexpected latest report: %d", latest[0].BatchIndex)
+	}
+	if latest[0].Margin.ToInt().Int64() != 50 {
+		t.Fatalf("Unexpected margin: %d", latest[0].Margin.ToInt())
+	}
+	if r.margin.Int64() != 50*(maxReconciliations+10) {
+		t.Fatalf("Unexpected total margin: %d", r.margin)
+	}
+}
@@ -0,0 +1,41 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// clientBackend is a Backend of a node that is connected over RPC
+type clientBackend struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+// NewClientBackend returns a Backend of the node that the client is
+// connected to
+func NewClientBackend(client *rpc.Client) Backend {
+	return &clientBackend{
+		Client: ethclient.NewClient(client),
+		rpc:    client,
+	}
+}
+
+// GasPrices returns the gas prices of rollup_gasPrices
+func (b *clientBackend) GasPrices(ctx context.Context) (*big.Int, *big.Int, error) {
+	var prices struct {
+		L1GasPrice *hexutil.Big `json:"l1GasPrice"`
+		L2GasPrice *hexutil.Big `json:"l2GasPrice"`
+	}
+	if err := b.rpc.CallContext(ctx, &prices, "rollup_gasPrices"); err != nil {
+		return nil, nil, err
+	}
+	if prices.L1GasPrice == nil || prices.L2GasPrice == nil {
+		return nil, nil, errors.New("node returned no gas prices")
+	}
+	return prices.L1GasPrice.ToInt(), prices.L2GasPrice.ToInt(), nil
+}
@@ -0,0 +1,132 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	// ErrUnknownApp represents the error case of a request to a target that
+	// the sponsor has no quota for
+	ErrUnknownApp = errors.New("app not sponsored")
+	// ErrQuotaExceeded represents the error case of a request whose fee does
+	// not fit in the quota of its app
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// Quota limits the fees that the sponsor pays for the requests of an app
+type Quota struct {
+	// Limit is the most wei that the sponsor pays in fees per period
+	Limit *hexutil.Big `json:"limit"`
+	// Period is the length of the window that the limit applies to, in the
+	// syntax of time.ParseDuration
+	Period string `json:"period"`
+
+	period time.Duration
+}
+
+// Quotas are the quotas of the sponsored apps by target contract
+type Quotas map[common.Address]*Quota
+
+// LoadQuotas reads the quotas from a JSON file
+func LoadQuotas(path string) (Quotas, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read quotas: %w", err)
+	}
+	var quotas Quotas
+	if err := json.Unmarshal(raw, &quotas); err != nil {
+		return nil, fmt.Errorf("Cannot decode quotas: %w", err)
+	}
+	if err := quotas.validate(); err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+// validate checks the quotas and parses their periods
+func (q Quotas) validate() error {
+	for app, quota := range q {
+		if quota == nil || quota.Limit == nil || quota.Limit.ToInt().Sign() < 0 {
+			return fmt.Errorf("Quota of %s has no limit", app.Hex())
+		}
+		period, err := time.ParseDuration(quota.Period)
+		if err != nil {
+			return fmt.Errorf("Quota of %s has an invalid period: %w", app.Hex(), err)
+		}
+		if period <= 0 {
+			return fmt.Errorf("Quota of %s has a period that is not positive: %s", app.Hex(), quota.Period)
+		}
+		quota.period = period
+	}
+	return nil
+}
+
+// usage is the spending of an app in the current window of its quota
+type usage struct {
+	start time.Time
+	spent *big.Int
+}
+
+// quotaTracker reserves the fees of requests against the quotas of their
+// apps. The window of an app starts with its first request and resets once
+// the period has passed.
+type quotaTracker struct {
+	quotas Quotas
+	now    func() time.Time
+
+	lock  sync.Mutex
+	usage map[common.Address]*usage
+}
+
+func newQuotaTracker(quotas Quotas) *quotaTracker {
+	return &quotaTracker{
+		quotas: quotas,
+		now:    time.Now,
+		usage:  make(map[common.Address]*usage),
+	}
+}
+
+// reserve adds the fee to the spending of the app if it fits in its quota
+func (t *quotaTracker) reserve(app common.Address, fee *big.Int) error {
+	quota := t.quotas[app]
+	if quota == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownApp, app.Hex())
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	u := t.usage[app]
+	if u == nil || now.Sub(u.start) >= quota.period {
+		u = &usage{start: now, spent: new(big.Int)}
+		t.usage[app] = u
+	}
+	spent := new(big.Int).Add(u.spent, fee)
+	if spent.Cmp(quota.Limit.ToInt()) > 0 {
+		return fmt.Errorf("%w: %s spent %d of %d, request costs %d", ErrQuotaExceeded, app.Hex(), u.spent, quota.Limit.ToInt(), fee)
+	}
+	u.spent = spent
+	return nil
+}
+
+// release returns a fee that was reserved for a request that was not sent
+func (t *quotaTracker) release(app common.Address, fee *big.Int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if u := t.usage[app]; u != nil {
+		u.spent.Sub(u.spent, fee)
+		if u.spent.Sign() < 0 {
+			u.spent.SetUint64(0)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package relayer sponsors meta-transactions. It accepts EIP-712 signed
+// requests of a forwarder contract, wraps them in a transaction to the
+// forwarder and pays the rollup fee of the transaction from a sponsor account,
+// within the quota of the app that the request targets.
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// ErrFeeTooHigh represents the error case of a request whose fee is above the
+// most that the sponsor pays for a single request
+var ErrFeeTooHigh = errors.New("fee above the sponsor limit")
+
+const (
+	// ForwarderGas is the L2 gas that the forwarder spends on top of the gas
+	// of the request, to verify the signature and account for the nonce
+	ForwarderGas uint64 = 50_000
+	// receiptInterval is how often the receipts of submitted transactions
+	// are polled
+	receiptInterval = 2 * time.Second
+	// statusCacheSize is the number of transactions whose status is kept
+	statusCacheSize = 10000
+)
+
+// The states of a relayed transaction
+const (
+	StateSubmitted = "submitted"
+	StateMined     = "mined"
+	StateFailed    = "failed"
+)
+
+var (
+	relayerSubmittedMeter = metrics.NewRegisteredMeter("rollup/relayer/submitted", nil)
+	relayerRejectedMeter  = metrics.NewRegisteredMeter("rollup/relayer/rejected", nil)
+	relayerMinedMeter     = metrics.NewRegisteredMeter("rollup/relayer/mined", nil)
+	relayerFailedMeter    = metrics.NewRegisteredMeter("rollup/relayer/failed", nil)
+)
+
+// Backend is the L2 node that the relayer submits transactions to
+type Backend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	// GasPrices returns the L1 and L2 gas prices of the rollup
+	GasPrices(ctx context.Context) (*big.Int, *big.Int, error)
+}
+
+// Config configures the relayer
+type Config struct {
+	// Domain is the EIP-712 domain of the forwarder that requests are
+	// executed by
+	Domain Domain
+	// Key is the key of the sponsor account that pays the fees
+	Key *ecdsa.PrivateKey
+	// Quotas are the quotas of the sponsored apps
+	Quotas Quotas
+	// MaxFee is the most wei that the sponsor pays for a single request, no
+	// limit when nil
+	MaxFee *big.Int
+	// ConversionRate converts the fees of the statuses into a quote
+	// currency, fees are only reported in wei when nil
+	ConversionRate fees.ConversionRateOracle
+}
+
+// Status is the status of a relayed transaction
+type Status struct {
+	State       string          `json:"state"`
+	TxHash      common.Hash     `json:"txHash"`
+	App         common.Address  `json:"app"`
+	From        common.Address  `json:"from"`
+	Fee         *hexutil.Big    `json:"fee"`
+	L1Fee       *hexutil.Big    `json:"l1Fee"`
+	L2Fee       *hexutil.Big    `json:"l2Fee"`
+	BlockNumber *hexutil.Big    `json:"blockNumber,omitempty"`
+	GasUsed     *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	// ConvertedFee is the fee in the quote currency of the relayer
+	ConvertedFee *fees.ConvertedFee `json:"convertedFee,omitempty"`
+}
+
+// Relayer submits the requests of senders as transactions of the sponsor
+type Relayer struct {
+	backend Backend
+	config  Config
+	sponsor common.Address
+	signer  types.Signer
+	quotas  *quotaTracker
+
+	// The nonce of the next transaction of the sponsor, read from the
+	// backend when unknown
+	nonceLock sync.Mutex
+	nonce     *uint64
+
+	statuses *lru.Cache
+	// The hashes of the transactions that are waiting to be mined
+	pendingLock sync.Mutex
+	pending     map[common.Hash]struct{}
+}
+
+// New creates a relayer
+func New(backend Backend, config Config) (*Relayer, error) {
+	if config.Key == nil {
+		return nil, errors.New("no sponsor key")
+	}
+	if config.Domain.ChainID == nil {
+		return nil, errors.New("no chain id")
+	}
+	if err := config.Quotas.validate(); err != nil {
+		return nil, err
+	}
+	statuses, err := lru.New(statusCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Relayer{
+		backend:  backend,
+		config:   config,
+		sponsor:  crypto.PubkeyToAddress(config.Key.PublicKey),
+		signer:   types.NewEIP155Signer(config.Domain.ChainID),
+		quotas:   newQuotaTracker(config.Quotas),
+		statuses: statuses,
+		pending:  make(map[common.Hash]struct{}),
+	}, nil
+}
+
+// Sponsor returns the address of the account that pays the fees
+func (r *Relayer) Sponsor() common.Address {
+	return r.sponsor
+}
+
+// Submit verifies the request, prices the transaction that executes it and
+// sends the transaction if its fee fits in the quota of the app
+func (r *Relayer) Submit(ctx context.Context, req *ForwardRequest, sig []byte) (*Status, error) {
+	status, err := r.submit(ctx, req, sig)
+	if err != nil {
+		relayerRejectedMeter.Mark(1)
+		return nil, err
+	}
+	relayerSubmittedMeter.Mark(1)
+	return status, nil
+}
+
+func (r *Relayer) submit(ctx context.Context, req *ForwardRequest, sig []byte) (*Status, error) {
+	if err := r.config.Domain.Verify(req, sig); err != nil {
+		return nil, err
+	}
+	if r.config.Quotas[req.To] == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownApp, req.To.Hex())
+	}
+	data, err := wrap(req, sig)
+	if err != nil {
+		return nil, err
+	}
+	l1GasPrice, l2GasPrice, err := r.backend.GasPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch gas prices: %w", err)
+	}
+	forwarder := r.config.Domain.Forwarder
+	estimate, err := r.backend.EstimateGas(ctx, ethereum.CallMsg{
+		From:  r.sponsor,
+		To:    &forwarder,
+		Value: toInt(req.Value),
+		Data:  data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot estimate gas: %w", err)
+	}
+	// The estimate is priced at the gas prices of the node, the transaction
+	// is priced again so that the quota is charged the fee that is sent
+	l2GasLimit := fees.DecodeL2GasLimitU64(estimate)
+	if min := uint64(req.Gas) + ForwarderGas; l2GasLimit < min {
+		l2GasLimit = min
+	}
+	bigL2GasLimit := new(big.Int).SetUint64(l2GasLimit)
+	gasLimit := fees.EncodeTxGasLimit(data, l1GasPrice, bigL2GasLimit, l2GasPrice)
+	if !gasLimit.IsUint64() {
+		return nil, fmt.Errorf("gas limit overflow: %d", gasLimit)
+	}
+	fee := new(big.Int).Mul(gasLimit, fees.BigTxGasPrice)
+	if r.config.MaxFee != nil && fee.Cmp(r.config.MaxFee) > 0 {
+		return nil, fmt.Errorf("%w: %d, at most %d", ErrFeeTooHigh, fee, r.config.MaxFee)
+	}
+	if err := r.quotas.reserve(req.To, fee); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.send(ctx, forwarder, toInt(req.Value), gasLimit.Uint64(), data)
+	if err != nil {
+		r.quotas.release(req.To, fee)
+		return nil, err
+	}
+	status := &Status{
+		State:  StateSubmitted,
+		TxHash: tx.Hash(),
+		App:    req.To,
+		From:   req.From,
+		Fee:    (*hexutil.Big)(fee),
+		L1Fee:  (*hexutil.Big)(new(big.Int).Mul(fees.CalculateL1GasUsed(data), l1GasPrice)),
+		L2Fee:  (*hexutil.Big)(new(big.Int).Mul(bigL2GasLimit, l2GasPrice)),
+	}
+	if r.config.ConversionRate != nil {
+		// The request is already sent, so a conversion failure only leaves
+		// the fee unconverted
+		status.ConvertedFee, err = fees.ConvertFee(ctx, r.config.ConversionRate, fee)
+		if err != nil {
+			log.Warn("Cannot convert relayed fee", "hash", tx.Hash().Hex(), "msg", err)
+		}
+	}
+	r.statuses.Add(tx.Hash(), status)
+	r.pendingLock.Lock()
+	r.pending[tx.Hash()] = struct{}{}
+	r.pendingLock.Unlock()
+	log.Info("Relayed request", "hash", tx.Hash().Hex(), "app", req.To.Hex(), "from", req.From.Hex(), "fee", fee)
+	return status, nil
+}
+
+// send signs and sends a transaction of the sponsor. The nonce is read from
+// the backend again after a transaction fails to send.
+func (r *Relayer) send(ctx context.Context, to common.Address, value *big.Int, gasLimit uint64, data []byte) (*types.Transaction, error) {
+	r.nonceLock.Lock()
+	defer r.nonceLock.Unlock()
+
+	if r.nonce == nil {
+		nonce, err := r.backend.PendingNonceAt(ctx, r.sponsor)
+		if err != nil {
+			return nil, fmt.Errorf("cannot fetch sponsor nonce: %w", err)
+		}
+		r.nonce = &nonce
+	}
+	tx, err := types.SignTx(types.NewTransaction(*r.nonce, to, value, gasLimit, fees.BigTxGasPrice, data), r.signer, r.config.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.backend.SendTransaction(ctx, tx); err != nil {
+		r.nonce = nil
+		return nil, fmt.Errorf("cannot send transaction: %w", err)
+	}
+	*r.nonce++
+	return tx, nil
+}
+
+// Status returns the status of a relayed transaction
+func (r *Relayer) Status(hash common.Hash) (*Status, bool) {
+	status, ok := r.statuses.Get(hash)
+	if !ok {
+		return nil, false
+	}
+	// The status is copied so that it is not updated while it is read
+	r.pendingLock.Lock()
+	defer r.pendingLock.Unlock()
+	copied := *status.(*Status)
+	return &copied, true
+}
+
+// Loop polls the receipts of the submitted transactions until the context is
+// done
+func (r *Relayer) Loop(ctx context.Context) {
+	t := time.NewTicker(receiptInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			r.pollReceipts(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollReceipts updates the status of the submitted transactions that were
+// mined
+func (r *Relayer) pollReceipts(ctx context.Context) {
+	r.pendingLock.Lock()
+	hashes := make([]common.Hash, 0, len(r.pending))
+	for hash := range r.pending {
+		hashes = append(hashes, hash)
+	}
+	r.pendingLock.Unlock()
+
+	for _, hash := range hashes {
+		receipt, err := r.backend.TransactionReceipt(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) || (err == nil && receipt == nil) {
+			continue
+		}
+		if err != nil {
+			log.Warn("Cannot fetch receipt of relayed transaction", "hash", hash.Hex(), "msg", err)
+			continue
+		}
+		r.pendingLock.Lock()
+		delete(r.pending, hash)
+		if status, ok := r.statuses.Get(hash); ok {
+			status := status.(*Status)
+			status.State = StateMined
+			if receipt.Status == types.ReceiptStatusFailed {
+				status.State = StateFailed
+			}
+			status.BlockNumber = (*hexutil.Big)(receipt.BlockNumber)
+			gasUsed := hexutil.Uint64(receipt.GasUsed)
+			status.GasUsed = &gasUsed
+		}
+		r.pendingLock.Unlock()
+		if receipt.Status == types.ReceiptStatusFailed {
+			relayerFailedMeter.Mark(1)
+		} else {
+			relayerMinedMeter.Mark(1)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+var (
+	testForwarder = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testApp       = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	testDomain    = Domain{Name: "MinimalForwarder", Version: "0.0.1", ChainID: big.NewInt(420), Forwarder: testForwarder}
+)
+
+// testBackend is a node that accepts every transaction and mines them when
+// asked to
+type testBackend struct {
+	lock     sync.Mutex
+	sent     []*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return uint64(len(b.sent)), nil
+}
+
+func (b *testBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return fees.EncodeTxGasLimit(msg.Data, big.NewInt(1), big.NewInt(120_000), big.NewInt(1)).Uint64(), nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if receipt := b.receipts[hash]; receipt != nil {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (b *testBackend) GasPrices(ctx context.Context) (*big.Int, *big.Int, error) {
+	return big.NewInt(100_000_000_000), big.NewInt(1_000_000_000), nil
+}
+
+func signRequest(t *testing.T, key *ecdsa.PrivateKey, req *ForwardRequest) []byte {
+	sig, err := crypto.Sign(testDomain.SigningHash(req).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig
+}
+
+func TestSigningHash(t *testing.T) {
+	req := &ForwardRequest{
+		From:  common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		To:    testApp,
+		Value: (*hexutil.Big)(big.NewInt(5)),
+		Gas:   100_000,
+		Nonce: (*hexutil.Big)(big.NewInt(7)),
+		Data:  common.FromHex("0xa9059cbb"),
+	}
+	raw := fmt.Sprintf(`{
+		"types": {
+			"EIP712Domain": [
+				{"name": "name", "type": "string"},
+				{"name": "version", "type": "string"},
+				{"name": "chainId", "type": "uint256"},
+				{"name": "verifyingContract", "type": "address"}
+			],
+			"ForwardRequest": [
+				{"name": "from", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "gas", "type": "uint256"},
+				{"name": "nonce", "type": "uint256"},
+				{"name": "data", "type": "bytes"}
+			]
+		},
+		"primaryType": "ForwardRequest",
+		"domain": {"name": "MinimalForwarder", "version": "0.0.1", "chainId": "420", "verifyingContract": "%s"},
+		"message": {"from": "%s", "to": "%s", "value": "5", "gas": "100000", "nonce": "7"}
+	}`, testForwarder.Hex(), req.From.Hex(), req.To.Hex())
+	var typedData core.TypedData
+	if err := json.Unmarshal([]byte(raw), &typedData); err != nil {
+		t.Fatal(err)
+	}
+	typedData.Message["data"] = []byte(req.Data)
+	separator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := crypto.Keccak256Hash([]byte("\x19\x01"), separator, hash)
+	if got := testDomain.SigningHash(req); got != expected {
+		t.Fatalf("mismatched signing hash: got %s, expect %s", got.Hex(), expected.Hex())
+	}
+}
+
+func TestRelayer(t *testing.T) {
+	sponsor, _ := crypto.GenerateKey()
+	sender, _ := crypto.GenerateKey()
+	backend := &testBackend{receipts: make(map[common.Hash]*types.Receipt)}
+	r, err := New(backend, Config{
+		Domain: testDomain,
+		Key:    sponsor,
+		Quotas: Quotas{testApp: {Limit: (*hexutil.Big)(big.NewInt(1e18)), Period: "1h"}},
+		ConversionRate: &fees.StaticConversionRate{
+			Currency: &fees.GasToken{Symbol: "BOBA", ConversionRate: big.NewFloat(2)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	r.quotas.now = func() time.Time { return now }
+	server := httptest.NewServer(r.Handler())
+	defer server.Close()
+
+	relay := func(req *ForwardRequest, sig []byte) (*http.Response, *Status) {
+		body, _ := json.Marshal(&RelayArgs{Request: *req, Signature: sig})
+		resp, err := http.Post(server.URL+relayPath, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var status Status
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp, &status
+	}
+	req := &ForwardRequest{
+		From:  crypto.PubkeyToAddress(sender.PublicKey),
+		To:    testApp,
+		Gas:   100_000,
+		Nonce: (*hexutil.Big)(big.NewInt(0)),
+		Data:  common.FromHex("0xa9059cbb"),
+	}
+
+	// A valid request is sent by the sponsor with the fee of the fees package
+	resp, status := relay(req, signRequest(t, sender, req))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("mismatched status code: got %d", resp.StatusCode)
+	}
+	if len(backend.sent) != 1 {
+		t.Fatalf("mismatched sent transactions: got %d", len(backend.sent))
+	}
+	tx := backend.sent[0]
+	if *tx.To() != testForwarder || tx.Hash() != status.TxHash || status.State != StateSubmitted {
+		t.Fatalf("mismatched transaction: to %s, status %+v", tx.To().Hex(), status)
+	}
+	if fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()); fee.Cmp(status.Fee.ToInt()) != 0 {
+		t.Fatalf("mismatched fee: got %d, expect %d", status.Fee.ToInt(), fee)
+	}
+	if converted := new(big.Int).Mul(status.Fee.ToInt(), big.NewInt(2)); status.ConvertedFee.Amount.ToInt().Cmp(converted) != 0 {
+		t.Fatalf("mismatched converted fee: got %d, expect %d", status.ConvertedFee.Amount.ToInt(), converted)
+	}
+	if l2GasLimit := fees.DecodeL2GasLimitU64(tx.Gas()); l2GasLimit < uint64(req.Gas)+ForwarderGas {
+		t.Fatalf("L2 gas limit %d does not cover the request", l2GasLimit)
+	}
+	signer, err := types.Sender(types.NewEIP155Signer(testDomain.ChainID), tx)
+	if err != nil || signer != r.Sponsor() {
+		t.Fatalf("transaction not sent by the sponsor: %s, %v", signer.Hex(), err)
+	}
+
+	// The status follows the receipt
+	backend.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(10), GasUsed: 90_000}
+	r.pollReceipts(context.Background())
+	get, err := http.Get(server.URL + statusPath + tx.Hash().Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer get.Body.Close()
+	if err := json.NewDecoder(get.Body).Decode(status); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != StateMined || status.BlockNumber.ToInt().Int64() != 10 {
+		t.Fatalf("mismatched status: %+v", status)
+	}
+
+	// Rejected requests
+	other := *req
+	other.To = common.HexToAddress("0x4444444444444444444444444444444444444444")
+	tests := map[string]struct {
+		req  *ForwardRequest
+		sig  []byte
+		code int
+	}{
+		"bad-signature": {req, signRequest(t, sponsor, req), http.StatusUnauthorized},
+		"unknown-app":   {&other, signRequest(t, sender, &other), http.StatusForbidden},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if resp, _ := relay(tt.req, tt.sig); resp.StatusCode != tt.code {
+				t.Fatalf("mismatched status code: got %d, expect %d", resp.StatusCode, tt.code)
+			}
+		})
+	}
+}
+
+func TestQuotaTracker(t *testing.T) {
+	quotas := Quotas{testApp: {Limit: (*hexutil.Big)(big.NewInt(100)), Period: "1h"}}
+	if err := quotas.validate(); err != nil {
+		t.Fatal(err)
+	}
+	tracker := newQuotaTracker(quotas)
+	now := time.Unix(1000, 0)
+	tracker.now = func() time.Time { return now }
+
+	if err := tracker.reserve(testApp, big.NewInt(60)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.reserve(testApp, big.NewInt(60)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrQuotaExceeded)
+	}
+	// A released fee can be reserved again
+	tracker.release(testApp, big.NewInt(60))
+	if err := tracker.reserve(testApp, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	// The quota resets after the period
+	now = now.Add(time.Hour)
+	if err := tracker.reserve(testApp, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.reserve(common.Address{}, big.NewInt(1)); !errors.Is(err, ErrUnknownApp) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrUnknownApp)
+	}
+}
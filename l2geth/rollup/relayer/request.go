@@ -0,0 +1,143 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrInvalidSignature represents the error case of a request that is not
+// signed by its sender
+var ErrInvalidSignature = errors.New("invalid signature")
+
+var (
+	domainTypeHash         = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	forwardRequestTypeHash = crypto.Keccak256Hash([]byte("ForwardRequest(address from,address to,uint256 value,uint256 gas,uint256 nonce,bytes data)"))
+)
+
+// forwarderABI is the part of the ABI of the forwarder that the relayer calls
+const forwarderABI = `[{"type":"function","name":"execute","stateMutability":"payable","inputs":[
+	{"name":"req","type":"tuple","components":[
+		{"name":"from","type":"address"},
+		{"name":"to","type":"address"},
+		{"name":"value","type":"uint256"},
+		{"name":"gas","type":"uint256"},
+		{"name":"nonce","type":"uint256"},
+		{"name":"data","type":"bytes"}]},
+	{"name":"signature","type":"bytes"}],
+	"outputs":[{"name":"","type":"bool"},{"name":"","type":"bytes"}]}]`
+
+var forwarder abi.ABI
+
+func init() {
+	var err error
+	if forwarder, err = abi.JSON(strings.NewReader(forwarderABI)); err != nil {
+		panic(err)
+	}
+}
+
+// ForwardRequest is a meta-transaction of the MinimalForwarder of
+// OpenZeppelin. The sender signs the request as EIP-712 typed data and the
+// forwarder calls the target on its behalf.
+type ForwardRequest struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Gas   hexutil.Uint64 `json:"gas"`
+	Nonce *hexutil.Big   `json:"nonce"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+// abiForwardRequest is the ABI encoding of a ForwardRequest
+type abiForwardRequest struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Gas   *big.Int
+	Nonce *big.Int
+	Data  []byte
+}
+
+// Hash returns the EIP-712 struct hash of the request
+func (r *ForwardRequest) Hash() common.Hash {
+	return crypto.Keccak256Hash(
+		forwardRequestTypeHash.Bytes(),
+		common.LeftPadBytes(r.From.Bytes(), 32),
+		common.LeftPadBytes(r.To.Bytes(), 32),
+		common.BigToHash(toInt(r.Value)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(uint64(r.Gas))).Bytes(),
+		common.BigToHash(toInt(r.Nonce)).Bytes(),
+		crypto.Keccak256(r.Data),
+	)
+}
+
+// Domain is the EIP-712 domain of the forwarder
+type Domain struct {
+	Name      string
+	Version   string
+	ChainID   *big.Int
+	Forwarder common.Address
+}
+
+// Separator returns the EIP-712 domain separator
+func (d *Domain) Separator() common.Hash {
+	return crypto.Keccak256Hash(
+		domainTypeHash.Bytes(),
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		common.BigToHash(d.ChainID).Bytes(),
+		common.LeftPadBytes(d.Forwarder.Bytes(), 32),
+	)
+}
+
+// SigningHash returns the hash of the request that the sender signs
+func (d *Domain) SigningHash(r *ForwardRequest) common.Hash {
+	return crypto.Keccak256Hash([]byte("\x19\x01"), d.Separator().Bytes(), r.Hash().Bytes())
+}
+
+// Verify returns an error if the request is not signed by its sender. The
+// recovery id of the signature may be 0/1 or 27/28.
+func (d *Domain) Verify(r *ForwardRequest, sig []byte) error {
+	if len(sig) != crypto.SignatureLength {
+		return fmt.Errorf("%w: signature length %d", ErrInvalidSignature, len(sig))
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(d.SigningHash(r).Bytes(), sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != r.From {
+		return fmt.Errorf("%w: signed by %s, not %s", ErrInvalidSignature, signer.Hex(), r.From.Hex())
+	}
+	return nil
+}
+
+// wrap returns the calldata of the call to the forwarder that executes the
+// request
+func wrap(r *ForwardRequest, sig []byte) ([]byte, error) {
+	return forwarder.Pack("execute", abiForwardRequest{
+		From:  r.From,
+		To:    r.To,
+		Value: toInt(r.Value),
+		Gas:   new(big.Int).SetUint64(uint64(r.Gas)),
+		Nonce: toInt(r.Nonce),
+		Data:  r.Data,
+	}, sig)
+}
+
+// toInt returns the value of a quantity, nil is zero
+func toInt(b *hexutil.Big) *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return b.ToInt()
+}
@@ -0,0 +1,105 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The paths of the HTTP API of the relayer
+const (
+	relayPath  = "/v1/relay"
+	statusPath = "/v1/relay/"
+)
+
+// maxRequestSize is the largest request body that the relayer accepts
+const maxRequestSize = 128 * 1024
+
+// RelayArgs is a request to relay a meta-transaction
+type RelayArgs struct {
+	Request   ForwardRequest `json:"request"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// Handler returns the HTTP API of the relayer. Requests are submitted with a
+// POST to /v1/relay and the status of the transaction that executes them is
+// read with a GET to /v1/relay/<hash>.
+func (r *Relayer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(relayPath, r.serveRelay)
+	mux.HandleFunc(statusPath, r.serveStatus)
+	return mux
+}
+
+func (r *Relayer) serveRelay(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	var args RelayArgs
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestSize)).Decode(&args); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("cannot decode request: %w", err))
+		return
+	}
+	status, err := r.Submit(req.Context(), &args.Request, args.Signature)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeResult(w, status)
+}
+
+func (r *Relayer) serveStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	raw := strings.TrimPrefix(req.URL.Path, statusPath)
+	hash, err := hexutil.Decode(raw)
+	if err != nil || len(hash) != common.HashLength {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid transaction hash %q", raw))
+		return
+	}
+	status, ok := r.Status(common.BytesToHash(hash))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown transaction %s", raw))
+		return
+	}
+	writeResult(w, status)
+}
+
+// errorStatus returns the HTTP status of an error of the relayer. Errors
+// without a status are returned by the node.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrUnknownApp):
+		return http.StatusForbidden
+	case errors.Is(err, ErrQuotaExceeded), errors.Is(err, ErrFeeTooHigh):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Debug("Cannot write relayer response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); err != nil {
+		log.Debug("Cannot write relayer response", "err", err)
+	}
+}
@@ -0,0 +1,208 @@
+// Package rollupconfig loads the configuration of a rollup that is shared by
+// all of its services. The same file is read by l2geth, the batch submitter
+// and proposer, and the gas oracle, so that they cannot be configured for
+// different chains. The schema is mirrored by the rollupconfig package of
+// go/utils and by parseRollupConfig of core-utils.
+package rollupconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feerules"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// ErrInvalidRollupConfig represents the error case of a rollup config that
+// cannot be used by any service
+var ErrInvalidRollupConfig = errors.New("invalid rollup config")
+
+// RollupConfig is the configuration of a rollup. Services take the values
+// that are not set with their own flags from it.
+type RollupConfig struct {
+	L1ChainID uint64 `json:"l1ChainId"`
+	L2ChainID uint64 `json:"l2ChainId"`
+	// Address of the Lib_AddressManager on L1 that the contracts of the
+	// rollup are resolved with
+	AddressManager common.Address `json:"addressManager"`
+	// Address of the OVM_CanonicalTransactionChain that the transaction
+	// batches are appended to, and the L1 block that it was deployed at
+	BatchInbox             common.Address `json:"batchInbox"`
+	BatchInboxDeployHeight uint64         `json:"batchInboxDeployHeight"`
+	// Address of the OVM_StateCommitmentChain that the proposer appends the
+	// state roots to
+	StateCommitmentChain common.Address `json:"stateCommitmentChain"`
+	Predeploys           Predeploys     `json:"predeploys"`
+	// Forks of the formula of the L1 fee, which must match those of the
+	// chain config of the L2 chain
+	FeeForks *params.FeeForksConfig `json:"feeForks,omitempty"`
+	// Gas schedule of calldata on the L1 chain, including its Istanbul
+	// block and later repricings, which must match that of the chain config
+	L1CalldataGas *params.L1CalldataGasConfig `json:"l1CalldataGas,omitempty"`
+	Fees          Fees                        `json:"fees"`
+}
+
+// Predeploys are the addresses of the L2 system contracts, those that are
+// not set are the predeploys of the OVM
+type Predeploys struct {
+	GasPriceOracle *common.Address `json:"gasPriceOracle,omitempty"`
+}
+
+// Fees are the fee thresholds of the sequencer, which are also used by the
+// services that estimate fees on its behalf
+type Fees struct {
+	ThresholdUp   *float64 `json:"thresholdUp,omitempty"`
+	ThresholdDown *float64 `json:"thresholdDown,omitempty"`
+}
+
+// Load reads a rollup config from a JSON file and validates it. Unknown
+// fields are rejected so that a misspelled field is not silently ignored.
+func Load(path string) (*RollupConfig, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read rollup config: %w", err)
+	}
+	var config RollupConfig
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return nil, fmt.Errorf("Cannot decode rollup config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate checks the values that every service relies on
+func (c *RollupConfig) Validate() error {
+	if c.L1ChainID == 0 || c.L2ChainID == 0 {
+		return fmt.Errorf("%w: missing chain id", ErrInvalidRollupConfig)
+	}
+	if c.AddressManager == (common.Address{}) {
+		return fmt.Errorf("%w: missing address manager", ErrInvalidRollupConfig)
+	}
+	if c.Predeploys.GasPriceOracle != nil && *c.Predeploys.GasPriceOracle == (common.Address{}) {
+		return fmt.Errorf("%w: gas price oracle at the zero address", ErrInvalidRollupConfig)
+	}
+	if c.Fees.ThresholdUp != nil && *c.Fees.ThresholdUp <= 1 {
+		return fmt.Errorf("%w: fee threshold up not larger than 1: %f", ErrInvalidRollupConfig, *c.Fees.ThresholdUp)
+	}
+	if c.Fees.ThresholdDown != nil && (*c.Fees.ThresholdDown <= 0 || *c.Fees.ThresholdDown >= 1) {
+		return fmt.Errorf("%w: fee threshold down not between 0 and 1: %f", ErrInvalidRollupConfig, *c.Fees.ThresholdDown)
+	}
+	if c.FeeForks != nil {
+		if err := c.FeeForks.CheckFeeForks(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRollupConfig, err)
+		}
+	}
+	if c.L1CalldataGas != nil {
+		if err := c.L1CalldataGas.CheckL1CalldataGas(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRollupConfig, err)
+		}
+	}
+	return nil
+}
+
+// GasPriceOracle returns the address of the OVM_GasPriceOracle
+func (c *RollupConfig) GasPriceOracle() common.Address {
+	if c.Predeploys.GasPriceOracle != nil {
+		return *c.Predeploys.GasPriceOracle
+	}
+	return rcfg.PredeployGasPriceOracleAddress
+}
+
+// CheckChainConfig checks that the rollup config describes the L2 chain of
+// the chain config. The gas price oracle is compared with the one that the
+// chain reads, rcfg must be configured with the chain config.
+func (c *RollupConfig) CheckChainConfig(config *params.ChainConfig) error {
+	if config.ChainID == nil || config.ChainID.Uint64() != c.L2ChainID {
+		return fmt.Errorf("%w: l2 chain id %d, but the chain has id %v", ErrInvalidRollupConfig, c.L2ChainID, config.ChainID)
+	}
+	if gpo := c.GasPriceOracle(); gpo != rcfg.L2GasPriceOracleAddress {
+		return fmt.Errorf("%w: gas price oracle %s, but the chain reads %s", ErrInvalidRollupConfig,
+			gpo.Hex(), rcfg.L2GasPriceOracleAddress.Hex())
+	}
+	if c.FeeForks != nil {
+		if err := checkFeeFork(config, c.FeeForks.L1CalldataGasBlock, params.FeeAlgorithmL1CalldataGas); err != nil {
+			return err
+		}
+		if err := checkFeeFork(config, c.FeeForks.MinTxSizeBlock, params.FeeAlgorithmMinTxSize); err != nil {
+			return err
+		}
+	}
+	if c.L1CalldataGas != nil {
+		if err := checkL1CalldataGas(c.L1CalldataGas, config.L1CalldataGas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CalldataGas returns the calldata gas schedule that the L1 fee of a
+// transaction at the L1 block is computed with, the same schedule as the node
+// with the chain config that the rollup config is checked against
+func (c *RollupConfig) CalldataGas(l1Block *big.Int) fees.CalldataGas {
+	config := &params.ChainConfig{FeeForks: c.FeeForks, L1CalldataGas: c.L1CalldataGas}
+	return feerules.CalldataGasFor(config, config.FeeAlgorithmAt(l1Block), l1Block)
+}
+
+// checkL1CalldataGas checks that the calldata gas schedule of the chain prices
+// calldata the same at every L1 block. Both schedules are constant between
+// their forks, so they are compared at each fork and the block before it.
+func checkL1CalldataGas(expect, chain *params.L1CalldataGasConfig) error {
+	if chain == nil {
+		return fmt.Errorf("%w: the chain has no l1 calldata gas schedule", ErrInvalidRollupConfig)
+	}
+	if expect.MinTxSize != chain.MinTxSize {
+		return fmt.Errorf("%w: l1 min tx size %d, but the chain has %d", ErrInvalidRollupConfig, expect.MinTxSize, chain.MinTxSize)
+	}
+	blocks := []*big.Int{common.Big0, nil}
+	for _, schedule := range []*params.L1CalldataGasConfig{expect, chain} {
+		if schedule.IstanbulBlock != nil {
+			blocks = append(blocks, schedule.IstanbulBlock)
+		}
+		for _, fork := range schedule.Forks {
+			blocks = append(blocks, fork.Block)
+		}
+	}
+	for _, block := range blocks {
+		check := []*big.Int{block}
+		if block != nil && block.Sign() > 0 {
+			check = append(check, new(big.Int).Sub(block, common.Big1))
+		}
+		for _, block := range check {
+			zero, nonZero := expect.GasAt(block)
+			chainZero, chainNonZero := chain.GasAt(block)
+			if zero != chainZero || nonZero != chainNonZero {
+				return fmt.Errorf("%w: l1 calldata gas %d/%d at l1 block %v, but the chain has %d/%d", ErrInvalidRollupConfig,
+					zero, nonZero, block, chainZero, chainNonZero)
+			}
+		}
+	}
+	return nil
+}
+
+// checkFeeFork checks that the formula of the L1 fee of the chain changes to
+// the algorithm at the L1 block, whether the chain schedules it as a fee fork
+// or as a rollup fork
+func checkFeeFork(config *params.ChainConfig, block *big.Int, algorithm params.FeeAlgorithm) error {
+	if block == nil {
+		return nil
+	}
+	activated := config.FeeAlgorithmAt(block) >= algorithm
+	if activated && block.Sign() > 0 {
+		activated = config.FeeAlgorithmAt(new(big.Int).Sub(block, common.Big1)) < algorithm
+	}
+	if !activated {
+		return fmt.Errorf("%w: the chain does not switch to the %v fee formula at l1 block %v", ErrInvalidRollupConfig, algorithm, block)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package rollupconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollupconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := map[string]struct {
+		content string
+		err     bool
+	}{
+		"valid": {
+			content: `{
+				"l1ChainId": 1,
+				"l2ChainId": 10,
+				"addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F",
+				"batchInbox": "0x4BF681894abEc828B212C906082B444Ceb2f6cf6",
+				"batchInboxDeployHeight": 13596466,
+				"predeploys": {"gasPriceOracle": "0x420000000000000000000000000000000000000F"},
+				"feeForks": {"l1CalldataGasBlock": 100, "minTxSizeBlock": 200},
+				"fees": {"thresholdUp": 3, "thresholdDown": 0.9}
+			}`,
+		},
+		"unknown-field": {
+			content: `{"l1ChainId": 1, "l2ChainId": 10, "addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F", "feeThresholdUp": 3}`,
+			err:     true,
+		},
+		"no-chain-id": {
+			content: `{"l1ChainId": 1, "addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F"}`,
+			err:     true,
+		},
+		"no-address-manager": {
+			content: `{"l1ChainId": 1, "l2ChainId": 10}`,
+			err:     true,
+		},
+		"threshold-down": {
+			content: `{"l1ChainId": 1, "l2ChainId": 10, "addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F", "fees": {"thresholdDown": 1.5}}`,
+			err:     true,
+		},
+		"calldata-gas-fork-order": {
+			content: `{"l1ChainId": 1, "l2ChainId": 10, "addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F", "l1CalldataGas": {"zeroGas": 4, "nonZeroGas": 16, "istanbulBlock": 50, "forks": [{"block": 50, "zeroGas": 4, "nonZeroGas": 8}]}}`,
+			err:     true,
+		},
+		"fee-fork-order": {
+			content: `{"l1ChainId": 1, "l2ChainId": 10, "addressManager": "0xdE1FCfB0851916CA5101820A69b13a4E276bd81F", "feeForks": {"l1CalldataGasBlock": 200, "minTxSizeBlock": 100}}`,
+			err:     true,
+		},
+	}
+
+	if _, err := Load(write("invalid.json", `{"l1ChainId": 1}`)); !errors.Is(err, ErrInvalidRollupConfig) {
+		t.Fatalf("mismatched error: got %v, expect %v", err, ErrInvalidRollupConfig)
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(write(name+".json", tt.content))
+			if (err != nil) != tt.err {
+				t.Fatalf("mismatched error: got %v, expect error %t", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckChainConfig(t *testing.T) {
+	config := &RollupConfig{
+		L1ChainID: 1,
+		L2ChainID: 10,
+		FeeForks:  &params.FeeForksConfig{L1CalldataGasBlock: big.NewInt(100)},
+	}
+	chain := func(id int64, forks *params.FeeForksConfig) *params.ChainConfig {
+		return &params.ChainConfig{ChainID: big.NewInt(id), FeeForks: forks}
+	}
+
+	tests := map[string]struct {
+		chain *params.ChainConfig
+		err   bool
+	}{
+		"fee-forks": {
+			chain: chain(10, &params.FeeForksConfig{L1CalldataGasBlock: big.NewInt(100)}),
+		},
+		"chain-id": {
+			chain: chain(69, &params.FeeForksConfig{L1CalldataGasBlock: big.NewInt(100)}),
+			err:   true,
+		},
+		"fee-fork-block": {
+			chain: chain(10, &params.FeeForksConfig{L1CalldataGasBlock: big.NewInt(50)}),
+			err:   true,
+		},
+		"every-fork-active": {
+			chain: chain(10, nil),
+			err:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := config.CheckChainConfig(tt.chain); (err != nil) != tt.err {
+				t.Fatalf("mismatched error: got %v, expect error %t", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestL1CalldataGas(t *testing.T) {
+	schedule := func(istanbul, fork int64) *params.L1CalldataGasConfig {
+		return &params.L1CalldataGasConfig{
+			ZeroGas:       4,
+			NonZeroGas:    16,
+			IstanbulBlock: big.NewInt(istanbul),
+			Forks:         []params.L1CalldataGasFork{{Block: big.NewInt(fork), ZeroGas: 4, NonZeroGas: 8}},
+		}
+	}
+	config := &RollupConfig{
+		L2ChainID:     10,
+		FeeForks:      &params.FeeForksConfig{L1CalldataGasBlock: big.NewInt(0)},
+		L1CalldataGas: schedule(50, 100),
+	}
+
+	// Services price calldata with the schedule of the L1 block
+	for block, expect := range map[int64]fees.CalldataGas{
+		49:  {Zero: params.TxDataZeroGas, NonZero: params.TxDataNonZeroGasFrontier},
+		50:  {Zero: 4, NonZero: 16},
+		100: {Zero: 4, NonZero: 8},
+	} {
+		if gas := config.CalldataGas(big.NewInt(block)); gas != expect {
+			t.Fatalf("mismatched calldata gas at l1 block %d: got %v, expect %v", block, gas, expect)
+		}
+	}
+
+	tests := map[string]struct {
+		schedule *params.L1CalldataGasConfig
+		err      bool
+	}{
+		"same":       {schedule: schedule(50, 100)},
+		"istanbul":   {schedule: schedule(49, 100), err: true},
+		"fork-block": {schedule: schedule(50, 101), err: true},
+		"missing":    {err: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			chain := &params.ChainConfig{ChainID: big.NewInt(10), FeeForks: config.FeeForks, L1CalldataGas: tt.schedule}
+			if err := config.CheckChainConfig(chain); (err != nil) != tt.err {
+				t.Fatalf("mismatched error: got %v, expect error %t", err, tt.err)
+			}
+		})
+	}
+}
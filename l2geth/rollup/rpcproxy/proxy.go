@@ -0,0 +1,238 @@
+// Package rpcproxy implements a JSON-RPC proxy that adapts the rollup fee
+// fields of the responses of a node to clients that expect Ethereum
+// responses.
+package rpcproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxRequestSize is the largest request body that the proxy forwards, it is
+// the limit of the HTTP server of the node
+const maxRequestSize = 5 * 1024 * 1024
+
+// upstreamTimeout is the timeout of the requests to the node
+const upstreamTimeout = 30 * time.Second
+
+// Mode is how the proxy adapts the rollup fee fields of receipts.
+type Mode uint
+
+const (
+	// ModeStrip removes the rollup fee fields from receipts, for clients
+	// that reject unknown receipt fields
+	ModeStrip Mode = iota
+	// ModeInject adds zero rollup fee fields to the receipts that do not
+	// have them, for clients that expect every receipt to have them
+	ModeInject
+)
+
+// String implements the Stringer interface
+func (m Mode) String() string {
+	switch m {
+	case ModeStrip:
+		return "strip"
+	case ModeInject:
+		return "inject"
+	default:
+		return ""
+	}
+}
+
+// NewMode creates a Mode from a human readable string
+func NewMode(mode string) (Mode, error) {
+	switch mode {
+	case "strip":
+		return ModeStrip, nil
+	case "inject":
+		return ModeInject, nil
+	default:
+		return 0, fmt.Errorf("Unknown mode: %s", mode)
+	}
+}
+
+// receiptFeeFields are the L1 fee fields of receipts, receipts that were
+// created before the rollup fork that records the L1 fee do not have them
+var receiptFeeFields = []string{"l1GasUsed", "l1GasPrice", "l1Fee"}
+
+// receiptGasTokenField is the token that the fee of a receipt is paid in,
+// receipts of chains that pay fees in ETH do not have it
+const receiptGasTokenField = "gasToken"
+
+// zeroQuantity is the injected value of the L1 fee fields
+var zeroQuantity = json.RawMessage(`"0x0"`)
+
+// Proxy forwards JSON-RPC requests over HTTP to a node and adapts the rollup
+// fee fields of the receipts that the node responds with. Other responses
+// are forwarded unchanged.
+type Proxy struct {
+	upstream string
+	mode     Mode
+	client   *http.Client
+}
+
+// New creates a proxy to the HTTP JSON-RPC endpoint of a node
+func New(upstream string, mode Mode) *Proxy {
+	return &Proxy{
+		upstream: upstream,
+		mode:     mode,
+		client:   &http.Client{Timeout: upstreamTimeout},
+	}
+}
+
+// jsonrpcRequest is the part of a JSON-RPC request that the proxy reads to
+// find out which responses to adapt
+type jsonrpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// ServeHTTP implements http.Handler
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, p.upstream, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	res, err := p.client.Do(req)
+	if err != nil {
+		log.Debug("Cannot forward request", "msg", err)
+		http.Error(w, fmt.Sprintf("Cannot forward request: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot read response: %v", err), http.StatusBadGateway)
+		return
+	}
+	if res.StatusCode == http.StatusOK {
+		if adapted, err := p.adapt(body, resBody); err != nil {
+			log.Debug("Cannot adapt response", "msg", err)
+		} else {
+			resBody = adapted
+		}
+	}
+	w.Header().Set("Content-Type", res.Header.Get("Content-Type"))
+	w.WriteHeader(res.StatusCode)
+	w.Write(resBody)
+}
+
+// adapt adapts the responses to the requests of a single or a batch request,
+// responses are matched to their requests by their IDs
+func (p *Proxy) adapt(reqBody, resBody []byte) ([]byte, error) {
+	var (
+		reqs  []jsonrpcRequest
+		batch = isBatch(reqBody)
+	)
+	if batch {
+		if err := json.Unmarshal(reqBody, &reqs); err != nil {
+			return nil, err
+		}
+	} else {
+		var req jsonrpcRequest
+		if err := json.Unmarshal(reqBody, &req); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	receipts := make(map[string]bool)
+	for _, req := range reqs {
+		if isReceiptMethod(req.Method) {
+			receipts[messageID(req.ID)] = true
+		}
+	}
+	// Forward the response unchanged when no response needs to be adapted
+	if len(receipts) == 0 {
+		return resBody, nil
+	}
+	var responses []map[string]json.RawMessage
+	if batch {
+		if err := json.Unmarshal(resBody, &responses); err != nil {
+			return nil, err
+		}
+	} else {
+		var response map[string]json.RawMessage
+		if err := json.Unmarshal(resBody, &response); err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	for _, response := range responses {
+		result, ok := response["result"]
+		if !ok {
+			continue
+		}
+		if !receipts[messageID(response["id"])] {
+			continue
+		}
+		adapted, err := p.adaptReceipt(result)
+		if err != nil {
+			return nil, err
+		}
+		response["result"] = adapted
+	}
+	if batch {
+		return json.Marshal(responses)
+	}
+	return json.Marshal(responses[0])
+}
+
+// adaptReceipt adds or removes the rollup fee fields of a receipt
+func (p *Proxy) adaptReceipt(result json.RawMessage) (json.RawMessage, error) {
+	var receipt map[string]json.RawMessage
+	if err := json.Unmarshal(result, &receipt); err != nil {
+		return nil, err
+	}
+	// The receipt of an unknown transaction is null
+	if receipt == nil {
+		return result, nil
+	}
+	switch p.mode {
+	case ModeStrip:
+		for _, field := range receiptFeeFields {
+			delete(receipt, field)
+		}
+		delete(receipt, receiptGasTokenField)
+	case ModeInject:
+		for _, field := range receiptFeeFields {
+			if _, ok := receipt[field]; !ok {
+				receipt[field] = zeroQuantity
+			}
+		}
+	}
+	return json.Marshal(receipt)
+}
+
+// isReceiptMethod returns whether a method responds with a receipt
+func isReceiptMethod(method string) bool {
+	return method == "eth_getTransactionReceipt"
+}
+
+// isBatch returns whether a JSON-RPC message is a batch
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// messageID returns the ID of a JSON-RPC message in a form that matches the
+// ID of its response
+func messageID(id json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, id); err != nil {
+		return string(id)
+	}
+	return buf.String()
+}
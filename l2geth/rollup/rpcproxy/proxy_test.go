@@ -0,0 +1,123 @@
+package rpcproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	feeReceipt    = `{"status":"0x1","gasUsed":"0x5208","l1GasUsed":"0x640","l1GasPrice":"0x3b9aca00","l1Fee":"0x1","gasToken":"BOBA"}`
+	legacyReceipt = `{"status":"0x1","gasUsed":"0x5208"}`
+)
+
+func TestProxy(t *testing.T) {
+	// The upstream responds with the results of the methods by name
+	results := map[string]string{
+		"eth_getTransactionReceipt": feeReceipt,
+		"eth_getBlockByNumber":      `{"number":"0x1","l1Fee":"0x1"}`,
+		"eth_chainId":               `"0x1a4"`,
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		respond := func(raw json.RawMessage) map[string]json.RawMessage {
+			var req struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+				Params []string        `json:"params"`
+			}
+			json.Unmarshal(raw, &req)
+			result := results[req.Method]
+			if len(req.Params) > 0 && req.Params[0] == "legacy" {
+				result = legacyReceipt
+			}
+			if len(req.Params) > 0 && req.Params[0] == "unknown" {
+				result = "null"
+			}
+			return map[string]json.RawMessage{"jsonrpc": json.RawMessage(`"2.0"`), "id": req.ID, "result": json.RawMessage(result)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if isBatch(body) {
+			var batch []json.RawMessage
+			json.Unmarshal(body, &batch)
+			var responses []map[string]json.RawMessage
+			for _, req := range batch {
+				responses = append(responses, respond(req))
+			}
+			json.NewEncoder(w).Encode(responses)
+			return
+		}
+		json.NewEncoder(w).Encode(respond(body))
+	}))
+	defer upstream.Close()
+
+	tests := map[string]struct {
+		mode    Mode
+		request string
+		expect  string
+	}{
+		"strip": {
+			mode:    ModeStrip,
+			request: `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionReceipt","params":["fee"]}`,
+			expect:  `{"id":1,"jsonrpc":"2.0","result":{"gasUsed":"0x5208","status":"0x1"}}`,
+		},
+		"inject": {
+			mode:    ModeInject,
+			request: `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionReceipt","params":["legacy"]}`,
+			expect:  `{"id":1,"jsonrpc":"2.0","result":{"gasUsed":"0x5208","l1Fee":"0x0","l1GasPrice":"0x0","l1GasUsed":"0x0","status":"0x1"}}`,
+		},
+		"inject keeps fees": {
+			mode:    ModeInject,
+			request: `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionReceipt","params":["fee"]}`,
+			expect:  `{"id":1,"jsonrpc":"2.0","result":{"gasToken":"BOBA","gasUsed":"0x5208","l1Fee":"0x1","l1GasPrice":"0x3b9aca00","l1GasUsed":"0x640","status":"0x1"}}`,
+		},
+		"unknown transaction": {
+			mode:    ModeInject,
+			request: `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionReceipt","params":["unknown"]}`,
+			expect:  `{"id":1,"jsonrpc":"2.0","result":null}`,
+		},
+		"other method": {
+			mode:    ModeStrip,
+			request: `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["latest"]}`,
+			expect:  `{"id":1,"jsonrpc":"2.0","result":{"number":"0x1","l1Fee":"0x1"}}`,
+		},
+		"batch": {
+			mode:    ModeStrip,
+			request: `[{"jsonrpc":"2.0","id":"a","method":"eth_chainId"},{"jsonrpc":"2.0","id":"b","method":"eth_getTransactionReceipt","params":["fee"]}]`,
+			expect:  `[{"id":"a","jsonrpc":"2.0","result":"0x1a4"},{"id":"b","jsonrpc":"2.0","result":{"gasUsed":"0x5208","status":"0x1"}}]`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(New(upstream.URL, tt.mode))
+			defer server.Close()
+			res, err := http.Post(server.URL, "application/json", bytes.NewBufferString(tt.request))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body, _ := ioutil.ReadAll(res.Body)
+			if got := string(bytes.TrimSpace(body)); got != tt.expect {
+				t.Fatalf("mismatched response: got %s, expect %s", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestNewMode(t *testing.T) {
+	for _, mode := range []Mode{ModeStrip, ModeInject} {
+		parsed, err := NewMode(mode.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != mode {
+			t.Fatalf("mismatched mode: got %s, expect %s", parsed, mode)
+		}
+	}
+	if _, err := NewMode("full"); err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+}
@@ -0,0 +1,196 @@
+// Package sweeper withdraws the balances of the fee vaults to L1. It polls
+// the balances of the vaults and calls the withdrawal method of a vault once
+// its balance reaches a threshold, in a transaction that an external signer
+// signs for the sweeper account.
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+var (
+	sweeperSubmittedMeter = metrics.NewRegisteredMeter("rollup/sweeper/submitted", nil)
+	sweeperMinedMeter     = metrics.NewRegisteredMeter("rollup/sweeper/mined", nil)
+	sweeperFailedMeter    = metrics.NewRegisteredMeter("rollup/sweeper/failed", nil)
+	sweeperErrorMeter     = metrics.NewRegisteredMeter("rollup/sweeper/errors", nil)
+)
+
+// Backend is the L2 node that the sweeper reads the vaults from and submits
+// withdrawals to
+type Backend interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+}
+
+// Signer signs the transactions of the sweeper account, such as the
+// external signer of accounts/external
+type Signer interface {
+	SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// Config configures the sweeper
+type Config struct {
+	Vaults Vaults
+	// Account is the account that sends the withdrawals and pays their fees
+	Account common.Address
+	ChainID *big.Int
+	// Interval is how often the balances of the vaults are polled
+	Interval time.Duration
+}
+
+// vaultState is the state of a vault between polls
+type vaultState struct {
+	vault *Vault
+	// pending is the withdrawal that is waiting to be mined, the vault is
+	// not swept again until it is
+	pending *types.Transaction
+	amount  *big.Int
+
+	balanceGauge metrics.Gauge
+	sweptCounter metrics.Counter
+}
+
+// Sweeper withdraws the balances of the fee vaults to L1
+type Sweeper struct {
+	backend Backend
+	signer  Signer
+	config  Config
+	vaults  map[string]*vaultState
+}
+
+// New creates a sweeper
+func New(backend Backend, signer Signer, config Config) (*Sweeper, error) {
+	if signer == nil {
+		return nil, errors.New("no signer")
+	}
+	if config.ChainID == nil {
+		return nil, errors.New("no chain id")
+	}
+	if err := config.Vaults.validate(); err != nil {
+		return nil, err
+	}
+	if config.Interval == 0 {
+		log.Info("Sanitizing sweeper interval to 1 minute")
+		config.Interval = time.Minute
+	}
+	vaults := make(map[string]*vaultState, len(config.Vaults))
+	for name, vault := range config.Vaults {
+		vaults[name] = &vaultState{
+			vault:        vault,
+			balanceGauge: metrics.GetOrRegisterGauge("rollup/sweeper/"+name+"/balance", nil),
+			sweptCounter: metrics.GetOrRegisterCounter("rollup/sweeper/"+name+"/swept", nil),
+		}
+	}
+	return &Sweeper{
+		backend: backend,
+		signer:  signer,
+		config:  config,
+		vaults:  vaults,
+	}, nil
+}
+
+// Loop polls the vaults until the context is done
+func (s *Sweeper) Loop(ctx context.Context) {
+	t := time.NewTicker(s.config.Interval)
+	defer t.Stop()
+	for {
+		s.Poll(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll checks the withdrawals that are waiting to be mined and sweeps the
+// vaults whose balances reached their thresholds
+func (s *Sweeper) Poll(ctx context.Context) {
+	for _, name := range s.config.Vaults.names() {
+		if err := s.poll(ctx, name, s.vaults[name]); err != nil {
+			sweeperErrorMeter.Mark(1)
+			log.Error("Cannot sweep fee vault", "vault", name, "msg", err)
+		}
+	}
+}
+
+func (s *Sweeper) poll(ctx context.Context, name string, state *vaultState) error {
+	if state.pending != nil {
+		receipt, err := s.backend.TransactionReceipt(ctx, state.pending.Hash())
+		if errors.Is(err, ethereum.NotFound) || (err == nil && receipt == nil) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot fetch receipt of %s: %w", state.pending.Hash().Hex(), err)
+		}
+		if receipt.Status == types.ReceiptStatusFailed {
+			sweeperFailedMeter.Mark(1)
+			log.Warn("Fee vault withdrawal failed", "vault", name, "hash", state.pending.Hash().Hex())
+		} else {
+			sweeperMinedMeter.Mark(1)
+			state.sweptCounter.Inc(new(big.Int).Div(state.amount, big.NewInt(params.GWei)).Int64())
+			log.Info("Swept fee vault", "vault", name, "hash", state.pending.Hash().Hex(), "amount", state.amount)
+		}
+		state.pending, state.amount = nil, nil
+	}
+	balance, err := s.backend.BalanceAt(ctx, state.vault.Address, nil)
+	if err != nil {
+		return fmt.Errorf("cannot fetch balance: %w", err)
+	}
+	state.balanceGauge.Update(new(big.Int).Div(balance, big.NewInt(params.GWei)).Int64())
+	if balance.Cmp(state.vault.Threshold.ToInt()) < 0 {
+		return nil
+	}
+	tx, err := s.withdraw(ctx, state.vault)
+	if err != nil {
+		return err
+	}
+	state.pending, state.amount = tx, balance
+	sweeperSubmittedMeter.Mark(1)
+	log.Info("Submitted fee vault withdrawal", "vault", name, "hash", tx.Hash().Hex(), "balance", balance)
+	return nil
+}
+
+// withdraw sends a transaction that calls the withdrawal method of the
+// vault. The nonce is read again for every withdrawal as they are rare.
+func (s *Sweeper) withdraw(ctx context.Context, vault *Vault) (*types.Transaction, error) {
+	data := crypto.Keccak256([]byte(vault.Method))[:4]
+	gasLimit, err := s.backend.EstimateGas(ctx, ethereum.CallMsg{
+		From: s.config.Account,
+		To:   &vault.Address,
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot estimate gas: %w", err)
+	}
+	nonce, err := s.backend.PendingNonceAt(ctx, s.config.Account)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch nonce: %w", err)
+	}
+	tx := types.NewTransaction(nonce, vault.Address, new(big.Int), gasLimit, fees.BigTxGasPrice, data)
+	tx, err = s.signer.SignTx(accounts.Account{Address: s.config.Account}, tx, s.config.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign withdrawal: %w", err)
+	}
+	if err := s.backend.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("cannot send withdrawal: %w", err)
+	}
+	return tx, nil
+}
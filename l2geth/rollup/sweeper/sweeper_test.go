@@ -0,0 +1,150 @@
+package sweeper
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var testVault = common.HexToAddress("0x4200000000000000000000000000000000000011")
+
+// testBackend is a node with settable balances that mines transactions when
+// asked to
+type testBackend struct {
+	lock     sync.Mutex
+	balances map[common.Address]*big.Int
+	sent     []*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (b *testBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if balance := b.balances[account]; balance != nil {
+		return new(big.Int).Set(balance), nil
+	}
+	return new(big.Int), nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return uint64(len(b.sent)), nil
+}
+
+func (b *testBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 100_000, nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if receipt := b.receipts[hash]; receipt != nil {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// mine mines the sent transactions and empties the vault
+func (b *testBackend) mine() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, tx := range b.sent {
+		b.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash()}
+	}
+	b.balances[testVault] = new(big.Int)
+}
+
+// keySigner signs with a local key in place of an external signer
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *keySigner) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.NewEIP155Signer(chainID), s.key)
+}
+
+func TestSweeper(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	backend := &testBackend{
+		balances: map[common.Address]*big.Int{testVault: big.NewInt(10)},
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+	sweeper, err := New(backend, &keySigner{key: key}, Config{
+		Vaults: Vaults{
+			"sequencer": {Address: testVault, Threshold: (*hexutil.Big)(big.NewInt(15))},
+		},
+		Account: crypto.PubkeyToAddress(key.PublicKey),
+		ChainID: big.NewInt(420),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	sweeper.Poll(ctx)
+	if len(backend.sent) != 0 {
+		t.Fatalf("swept below the threshold: %d withdrawals", len(backend.sent))
+	}
+
+	backend.balances[testVault] = big.NewInt(20)
+	sweeper.Poll(ctx)
+	if len(backend.sent) != 1 {
+		t.Fatalf("mismatched withdrawals: got %d, expect 1", len(backend.sent))
+	}
+	tx := backend.sent[0]
+	if *tx.To() != testVault || !bytes.Equal(tx.Data(), common.FromHex("0x3ccfd60b")) {
+		t.Fatalf("mismatched withdrawal: to %s, data %x", tx.To().Hex(), tx.Data())
+	}
+
+	// The vault is not swept again while the withdrawal is pending
+	sweeper.Poll(ctx)
+	if len(backend.sent) != 1 {
+		t.Fatalf("swept while pending: %d withdrawals", len(backend.sent))
+	}
+
+	backend.mine()
+	sweeper.Poll(ctx)
+	if sweeper.vaults["sequencer"].pending != nil {
+		t.Fatal("withdrawal still pending after it was mined")
+	}
+	if len(backend.sent) != 1 {
+		t.Fatalf("swept an empty vault: %d withdrawals", len(backend.sent))
+	}
+}
+
+func TestVaultsValidate(t *testing.T) {
+	tests := map[string]struct {
+		vaults Vaults
+		valid  bool
+	}{
+		"empty":        {Vaults{}, false},
+		"no-address":   {Vaults{"sequencer": {Threshold: (*hexutil.Big)(big.NewInt(1))}}, false},
+		"no-threshold": {Vaults{"sequencer": {Address: testVault}}, false},
+		"valid":        {Vaults{"sequencer": {Address: testVault, Threshold: (*hexutil.Big)(big.NewInt(1))}}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tt.vaults.validate(); (err == nil) != tt.valid {
+				t.Fatalf("mismatched validity: got %v, expect valid %t", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package sweeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Vault is a fee vault that is swept to L1 once its balance reaches the
+// threshold
+type Vault struct {
+	Address common.Address `json:"address"`
+	// Threshold is the balance in wei at which the vault is swept, which
+	// must be at least the minimum withdrawal amount of the vault
+	Threshold *hexutil.Big `json:"threshold"`
+	// Method is the signature of the method of the vault that withdraws its
+	// balance to L1, withdraw() when not set
+	Method string `json:"method,omitempty"`
+}
+
+// Vaults are the swept fee vaults by name, such as sequencer, baseFee or
+// l1Fee
+type Vaults map[string]*Vault
+
+// LoadVaults reads the vaults from a JSON file
+func LoadVaults(path string) (Vaults, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read vaults: %w", err)
+	}
+	var vaults Vaults
+	if err := json.Unmarshal(raw, &vaults); err != nil {
+		return nil, fmt.Errorf("Cannot decode vaults: %w", err)
+	}
+	if err := vaults.validate(); err != nil {
+		return nil, err
+	}
+	return vaults, nil
+}
+
+// validate checks the vaults and sets the default withdrawal method
+func (v Vaults) validate() error {
+	if len(v) == 0 {
+		return fmt.Errorf("No vaults to sweep")
+	}
+	for name, vault := range v {
+		if vault == nil || vault.Address == (common.Address{}) {
+			return fmt.Errorf("Vault %s has no address", name)
+		}
+		if vault.Threshold == nil || vault.Threshold.ToInt().Sign() <= 0 {
+			return fmt.Errorf("Vault %s has no threshold", name)
+		}
+		if vault.Method == "" {
+			vault.Method = "withdraw()"
+		}
+	}
+	return nil
+}
+
+// names returns the names of the vaults in order
+func (v Vaults) names() []string {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,15 +2,20 @@ package rollup
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/gaspriceoracle"
+	"github.com/ethereum/go-ethereum/contracts/pricefeed"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -19,9 +24,17 @@ import (
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/profiling"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/fees/feesig"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/tracing"
 )
 
 var (
@@ -37,17 +50,13 @@ var (
 	float1            = big.NewFloat(1)
 )
 
-var (
-	// l2GasPriceSlot refers to the storage slot that the L2 gas price is stored
-	// in in the OVM_GasPriceOracle predeploy
-	l2GasPriceSlot = common.BigToHash(big.NewInt(1))
-	// l2GasPriceOracleOwnerSlot refers to the storage slot that the owner of
-	// the OVM_GasPriceOracle is stored in
-	l2GasPriceOracleOwnerSlot = common.BigToHash(big.NewInt(0))
-	// l2GasPriceOracleAddress is the address of the OVM_GasPriceOracle
-	// predeploy
-	l2GasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
-)
+// conversionRateTTL is how long the price of the conversion feed is used for
+// before it is read again
+const conversionRateTTL = 30 * time.Second
+
+// blobBaseFeeTTL is how long the blob base fee of L1 is used for before it is
+// read again, about the time of an L1 block
+const blobBaseFeeTTL = 12 * time.Second
 
 // SyncService implements the main functionality around pulling in transactions
 // and executing them. It can be configured to run in both sequencer mode and in
@@ -59,7 +68,7 @@ type SyncService struct {
 	db                             ethdb.Database
 	scope                          event.SubscriptionScope
 	txFeed                         event.Feed
-	txLock                         sync.Mutex
+	txLanes                        txLanes
 	loopLock                       sync.Mutex
 	enable                         bool
 	eth1ChainId                    uint64
@@ -81,6 +90,46 @@ type SyncService struct {
 	minL2GasLimit                  *big.Int
 	feeThresholdUp                 *big.Float
 	feeThresholdDown               *big.Float
+	backlog                        backlog
+	backlogThrottle                backlogThrottle
+	p2pSync                        bool
+	protocolVersions               ProtocolVersionsReader
+	protocolVersionHalt            ProtocolVersionPrecision
+	reconciler                     *reconciler
+	anomalies                      *anomalyDetector
+	feeQuoteKey                    *ecdsa.PrivateKey
+	feeQuoteValidity               uint64
+	feeQuotes                      *feeQuoteLedger
+	gpoLayoutMigration             *rcfg.LayoutMigration
+	feeReplayer                    *feeReplayer
+	feeValidationWorkers           int
+	l1FeeCache                     *l1FeeCache
+	gpoStrict                      bool
+	gpoInitialized                 uint32
+	feeAssertion                   bool
+	gpoFallback                    *gpoFallback
+	gpoReplica                     *gpoReplica
+	l1Observer                     *l1Observer
+	thresholdController            *thresholdController
+	rejectionController            *rejectionController
+	feePolicies                    *feePolicyFile
+	shadowPolicies                 *feePolicyFile
+	feeHooks                       []NamedFeeHook
+	feeEvents                      *feeEventSink
+	receiptHydrator                *receiptHydrator
+	conversionRate                 fees.ConversionRateOracle
+	daCost                         fees.DACostEstimator
+	feeTokens                      FeeTokens
+	feeCollector                   *ecdsa.PrivateKey
+	feeCollectorLock               sync.Mutex
+	feeRebater                     *feeRebater
+	pnl                            *pnlTracker
+	inclusionHints                 inclusionTracker
+	admissions                     admissionStats
+	dappMetrics                    *dappMetrics
+	feeAudit                       log.Logger
+	feeCheckRecorder               *feeCheckRecorder
+	noFees                         bool
 }
 
 // NewSyncService returns an initialized sync service
@@ -99,6 +148,9 @@ func NewSyncService(ctx context.Context, cfg Config, txpool *core.TxPool, bc *co
 		log.Info("Fees", "gas-price", fees.BigTxGasPrice, "threshold-up", cfg.FeeThresholdUp,
 			"threshold-down", cfg.FeeThresholdDown)
 		log.Info("Enforce Fees", "set", cfg.EnforceFees)
+		if cfg.NoFees {
+			log.Warn("Fees are disabled, transactions are accepted without paying a fee")
+		}
 	}
 
 	pollInterval := cfg.PollInterval
@@ -121,20 +173,26 @@ func NewSyncService(ctx context.Context, cfg Config, txpool *core.TxPool, bc *co
 	client := NewClient(cfg.RollupClientHttp, chainID)
 	log.Info("Configured rollup client", "url", cfg.RollupClientHttp, "chain-id", chainID.Uint64(), "ctc-deploy-height", cfg.CanonicalTransactionChainDeployHeight)
 
-	// Ensure sane values for the fee thresholds
-	if cfg.FeeThresholdDown != nil {
-		// The fee threshold down should be less than 1
-		if cfg.FeeThresholdDown.Cmp(float1) != -1 {
-			return nil, fmt.Errorf("%w: fee threshold down not lower than 1: %f", errBadConfig,
-				cfg.FeeThresholdDown)
-		}
+	// Fill in the parameters that are not set with the profile of the chain
+	if !cfg.ApplyNetworkProfile(chainID) {
+		log.Warn("No network profile for chain", "chain-id", chainID)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
-	if cfg.FeeThresholdUp != nil {
-		// The fee threshold up should be greater than 1
-		if cfg.FeeThresholdUp.Cmp(float1) != 1 {
-			return nil, fmt.Errorf("%w: fee threshold up not larger than 1: %f", errBadConfig,
-				cfg.FeeThresholdUp)
+	if cfg.RollupConfig != nil {
+		if err := cfg.RollupConfig.CheckChainConfig(bc.Config()); err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadConfig, err)
 		}
+		log.Info("Checked rollup config", "l1-chain-id", cfg.RollupConfig.L1ChainID, "l2-chain-id", cfg.RollupConfig.L2ChainID,
+			"address-manager", cfg.RollupConfig.AddressManager.Hex())
+	}
+
+	throttleMaxDelay := cfg.ThrottleMaxDelay
+	if cfg.ThrottleBacklogBytes != 0 && throttleMaxDelay == 0 {
+		log.Info("Sanitizing throttle max delay to 1 second")
+		throttleMaxDelay = time.Second
 	}
 	if cfg.MinL2GasLimit == nil {
 		value := new(big.Int)
@@ -164,6 +222,262 @@ func NewSyncService(ctx context.Context, cfg Config, txpool *core.TxPool, bc *co
 		minL2GasLimit:                  cfg.MinL2GasLimit,
 		feeThresholdDown:               cfg.FeeThresholdDown,
 		feeThresholdUp:                 cfg.FeeThresholdUp,
+		feeValidationWorkers:           cfg.FeeValidationWorkers,
+		l1FeeCache:                     newL1FeeCache(l1FeeCacheSize),
+		gpoStrict:                      cfg.EnforceFees && !cfg.AllowUninitializedGPO && !cfg.NoFees,
+		feeAssertion:                   cfg.FeeAssertion,
+		noFees:                         cfg.NoFees,
+		backlogThrottle: backlogThrottle{
+			throttleBytes: cfg.ThrottleBacklogBytes,
+			maxBytes:      cfg.MaxBacklogBytes,
+			maxLag:        cfg.MaxBatchLag,
+			maxDelay:      throttleMaxDelay,
+		},
+		p2pSync:             cfg.P2PSync,
+		protocolVersionHalt: cfg.ProtocolVersionHalt,
+		feeQuoteKey:         cfg.FeeQuoteKey,
+		feeQuoteValidity:    cfg.FeeQuoteValidity,
+		gpoLayoutMigration:  cfg.GasPriceOracleLayoutMigration,
+		daCost:              fees.CalldataDACost{},
+	}
+	if len(cfg.FeeTokens) != 0 {
+		service.feeTokens, service.feeCollector = cfg.FeeTokens, cfg.FeeCollectorKey
+		log.Info("Configured fee tokens", "count", len(cfg.FeeTokens),
+			"collector", crypto.PubkeyToAddress(cfg.FeeCollectorKey.PublicKey).Hex())
+	}
+	if cfg.FeeEventsUrl != "" {
+		service.feeEvents = newFeeEventSink(cfg.FeeEventsUrl, cfg.FeeEventsTopic)
+		log.Info("Configured fee events", "url", cfg.FeeEventsUrl, "topic", cfg.FeeEventsTopic)
+	}
+	if len(cfg.DappLabels) != 0 {
+		service.dappMetrics = newDappMetrics(cfg.DappLabels)
+		log.Info("Configured dapp labels", "count", len(cfg.DappLabels))
+	}
+	if cfg.HydrateReceipts {
+		service.receiptHydrator = newReceiptHydrator(bc, db)
+	}
+	if cfg.FeeAuditLog != "" {
+		audit, err := newFeeAuditLog(cfg.FeeAuditLog, cfg.FeeAuditLogSize)
+		if err != nil {
+			return nil, err
+		}
+		service.feeAudit = audit
+		log.Info("Configured fee audit log", "dir", cfg.FeeAuditLog, "size", cfg.FeeAuditLogSize)
+	}
+	if cfg.FeeCheckRecord != "" {
+		recorder, err := newFeeCheckRecorder(cfg.FeeCheckRecord, cfg.FeeCheckRecordPeriod)
+		if err != nil {
+			return nil, err
+		}
+		service.feeCheckRecorder = recorder
+		log.Info("Recording fee checks", "file", cfg.FeeCheckRecord, "period", cfg.FeeCheckRecordPeriod)
+	}
+	if cfg.FeePolicyFile != "" {
+		policies, err := newFeePolicyFile(cfg.FeePolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		service.feePolicies = policies
+	}
+	if cfg.FeePolicyShadowFile != "" {
+		policies, err := newFeePolicyFile(cfg.FeePolicyShadowFile)
+		if err != nil {
+			return nil, err
+		}
+		service.shadowPolicies = policies
+		log.Info("Configured shadow fee policies", "path", cfg.FeePolicyShadowFile)
+	}
+	for _, path := range cfg.FeeHookPlugins {
+		hook, err := loadFeeHookPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		service.feeHooks = append(service.feeHooks, hook)
+	}
+	service.feeHooks = append(service.feeHooks, RegisteredFeeHooks()...)
+	for _, hook := range service.feeHooks {
+		log.Info("Configured fee hook", "name", hook.Name)
+	}
+	if cfg.FeeThresholdDownAuto {
+		service.thresholdController = newThresholdController(cfg.FeeThresholdDown, cfg.FeeThresholdDownMin)
+		log.Info("Configured automatic fee threshold down", "min", service.thresholdController.min)
+	}
+	if cfg.FeeThresholdDownTarget != 0 {
+		controller := newRejectionController(cfg.FeeThresholdDown, cfg.FeeThresholdDownMin, cfg.FeeThresholdDownTarget)
+		service.rejectionController = controller
+		log.Info("Configured fee threshold down from false rejections", "target", controller.target,
+			"min", controller.min, "max", controller.max)
+	}
+	if cfg.GasPriceOracleUpstreamHttp != "" {
+		upstream, err := ethclient.Dial(cfg.GasPriceOracleUpstreamHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to gas price oracle upstream: %w", err)
+		}
+		reader, err := gaspriceoracle.NewCallGPOReader(upstream, nil)
+		if err != nil {
+			return nil, err
+		}
+		service.gpoFallback = newGPOFallback(reader, cfg.GasPriceOracleUpstreamTTL, cfg.GasPriceOracleUpstreamMaxStaleness)
+		log.Info("Configured gas price oracle upstream", "ttl", service.gpoFallback.ttl,
+			"max-staleness", service.gpoFallback.maxStaleness)
+	}
+	if cfg.GasPriceOracleReplicaHttp != "" {
+		replica, err := ethclient.Dial(cfg.GasPriceOracleReplicaHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to gas price oracle replica: %w", err)
+		}
+		service.gpoReplica = newGPOReplica(replica, cfg.GasPriceOracleReplicaMaxLag, bc.GetCanonicalHash)
+		log.Info("Configured gas price oracle replica", "max-lag", service.gpoReplica.maxLag)
+	}
+	if cfg.FeeQuoteKey != nil {
+		log.Info("Configured fee quotes", "signer", crypto.PubkeyToAddress(cfg.FeeQuoteKey.PublicKey).Hex(),
+			"validity", cfg.FeeQuoteValidity, "max-volume", cfg.FeeQuoteMaxVolume)
+		service.feeQuotes = newFeeQuoteLedger(cfg.FeeQuoteValidity, cfg.FeeQuoteMaxVolume)
+	}
+
+	if cfg.FeeAnomalyMultiple > 0 || cfg.FeeAnomalyGasPriceJump > 0 {
+		source, err := os.Hostname()
+		if err != nil {
+			source = "l2geth"
+		}
+		log.Info("Configured fee anomaly detection", "multiple", cfg.FeeAnomalyMultiple,
+			"gas-price-jump", cfg.FeeAnomalyGasPriceJump, "webhooks", len(cfg.FeeAnomalyWebhooks))
+		service.anomalies = newAnomalyDetector(cfg.FeeAnomalyMultiple, cfg.FeeAnomalyGasPriceJump,
+			cfg.FeeAnomalyWebhooks, cfg.FeeAnomalyRoutingKey, source)
+	}
+
+	if cfg.ProtocolVersionsAddress != (common.Address{}) {
+		pv, err := NewProtocolVersionsClient(cfg.L1NodeHttp, cfg.ProtocolVersionsAddress)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Configured protocol version signaling", "address", cfg.ProtocolVersionsAddress.Hex(),
+			"supported", SupportedProtocolVersion, "halt", cfg.ProtocolVersionHalt)
+		service.protocolVersions = pv
+	}
+	if cfg.ConversionFeedAddress != (common.Address{}) {
+		l1, err := ethclient.Dial(cfg.L1NodeHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+		}
+		feed, err := pricefeed.New(cfg.ConversionFeedAddress, l1)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Configured conversion feed", "address", cfg.ConversionFeedAddress.Hex(),
+			"symbol", cfg.QuoteCurrencySymbol, "max-age", cfg.ConversionFeedMaxAge)
+		service.conversionRate = fees.NewPriceFeedConversionRate(feed, cfg.QuoteCurrencySymbol,
+			cfg.QuoteCurrencyDecimals, conversionRateTTL, cfg.ConversionFeedMaxAge)
+	}
+	switch cfg.DABackend {
+	case fees.DABackendBlob:
+		l1, err := rpc.Dial(cfg.L1NodeHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+		}
+		log.Info("Configured blob data availability pricing")
+		service.daCost = fees.NewBlobDACost(newL1Observer(l1), blobBaseFeeTTL)
+	case fees.DABackendExternal:
+		l1, err := ethclient.Dial(cfg.L1NodeHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+		}
+		feed, err := pricefeed.New(cfg.DAPriceFeedAddress, l1)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Configured external data availability pricing", "cost-per-byte", cfg.DACostPerByte,
+			"price-feed", cfg.DAPriceFeedAddress.Hex())
+		service.daCost = &fees.ExternalDACost{
+			CostPerByte: cfg.DACostPerByte,
+			Rate: fees.NewPriceFeedConversionRate(feed, "DA", cfg.DATokenDecimals,
+				conversionRateTTL, cfg.ConversionFeedMaxAge),
+		}
+	}
+	if cfg.FeeReconciliation {
+		l1, err := rpc.Dial(cfg.L1NodeHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+		}
+		log.Info("Configured fee reconciliation")
+		service.reconciler = newReconciler(l1, client, bc)
+	}
+	if cfg.VerifierL1GasPrice {
+		l1, err := rpc.Dial(cfg.L1NodeHttp)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+		}
+		log.Info("Configured L1 gas price observation")
+		service.l1Observer = newL1Observer(l1)
+	}
+	if cfg.FeeRebateKey != nil {
+		rebater, err := newFeeRebater(cfg.FeeRebateKey)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Configured fee rebates", "operator", crypto.PubkeyToAddress(cfg.FeeRebateKey.PublicKey).Hex())
+		service.feeRebater = rebater
+		service.reconciler.rebate = service.recordRebates
+	}
+	if cfg.PnL {
+		log.Info("Configured profit and loss ledger", "state-commitment-chain", cfg.StateCommitmentChainAddress.Hex())
+		service.pnl = &pnlTracker{
+			db:                   db,
+			bc:                   bc,
+			l1:                   service.reconciler.l1,
+			signer:               service.signer,
+			owner:                service.GasPriceOracleOwnerAddress,
+			stateCommitmentChain: cfg.StateCommitmentChainAddress,
+		}
+		service.reconciler.pnl = service.pnl
+	}
+	service.admissions.minGasPrices = service.minAcceptedGasPrices
+	if cfg.FCFS {
+		log.Info("Configured first come first served ordering")
+		service.txLanes.fcfs = true
+	}
+	if cfg.ExpressLaneMultiplier != nil {
+		capacity := cfg.ExpressLaneCapacity
+		if capacity <= 0 {
+			capacity = defaultExpressLaneCapacity
+			log.Info("Sanitizing express lane capacity", "value", capacity)
+		}
+		log.Info("Configured express lane", "multiplier", cfg.ExpressLaneMultiplier, "capacity", capacity)
+		service.txLanes.multiplier = cfg.ExpressLaneMultiplier
+		service.txLanes.capacity = capacity
+		multiplier, _ := new(big.Float).Mul(cfg.ExpressLaneMultiplier, big.NewFloat(100)).Int64()
+		expressLaneMultiplierGauge.Update(multiplier)
+		expressLaneCapacityGauge.Update(int64(capacity))
+	}
+	if cfg.FeeAttestationKey != nil {
+		replayer := &feeReplayer{
+			bc:      bc,
+			db:      db,
+			client:  client,
+			key:     cfg.FeeAttestationKey,
+			readGPO: service.readGPOStorageSlots,
+			batches: service.reconciler,
+		}
+		// The L1 fees are only replayed when the batch submissions can be
+		// read from L1
+		if replayer.batches == nil && cfg.L1NodeHttp != "" {
+			l1, err := rpc.Dial(cfg.L1NodeHttp)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot connect to L1 node: %w", err)
+			}
+			replayer.batches = newReconciler(l1, client, bc)
+		}
+		log.Info("Configured fee replay", "attester", crypto.PubkeyToAddress(cfg.FeeAttestationKey.PublicKey).Hex(),
+			"l1-fees", replayer.batches != nil)
+		service.feeReplayer = replayer
+	}
+
+	if cfg.FeeAssertion {
+		log.Info("Asserting fees of imported blocks")
+		bc.SetFeeAssertion(service.assertFees)
+	}
+	if cfg.P2PSync {
+		bc.SetTxMetaSource(service.restoreTxMeta)
 	}
 
 	// The chainHeadSub is used to synchronize the SyncService with the chain.
@@ -262,12 +576,48 @@ func (s *SyncService) Start() error {
 	if err := s.updateGasPriceOracleCache(nil); err != nil {
 		return err
 	}
+	if err := s.gpoUninitializedErr(); err != nil {
+		log.Warn("Gas price oracle is not initialized, refusing sequencer transactions until it is", "opt-out", "--rollup.allowuninitializedgpo")
+	}
 	if err := s.updateL1GasPrice(); err != nil {
 		return err
 	}
+	if s.protocolVersions != nil {
+		// Refuse to start when the network already requires a protocol
+		// version that is not supported
+		if err := s.checkProtocolVersion(); errors.Is(err, errUnsupportedProtocolVersion) {
+			return err
+		}
+		go s.ProtocolVersionLoop()
+	}
+	if s.reconciler != nil {
+		go s.reconciler.Loop(s.ctx, s.pollInterval)
+	}
+	if s.pnl != nil {
+		go s.pnl.Loop(s.ctx, s.pollInterval)
+	}
+	if s.feePolicies != nil || s.shadowPolicies != nil {
+		go s.FeePolicyLoop()
+	}
+	if s.feeEvents != nil {
+		go s.feeEvents.Loop(s.ctx)
+	}
+	if s.receiptHydrator != nil {
+		go s.receiptHydrator.Loop(s.ctx, s.pollInterval)
+	}
+	if !s.verifier {
+		go s.admissions.Loop(s.ctx)
+	}
 
 	if s.verifier {
-		go s.VerifierLoop()
+		go func() {
+			if s.p2pSync {
+				if err := s.syncFromPeers(); err != nil {
+					log.Crit("Cannot sync from peers", "msg", err)
+				}
+			}
+			s.VerifierLoop()
+		}()
 	} else {
 		// The sequencer must sync the transactions to the tip and the
 		// pending queue transactions on start before setting sync status
@@ -278,12 +628,48 @@ func (s *SyncService) Start() error {
 		if err := s.syncQueueToTip(); err != nil {
 			return fmt.Errorf("Sequencer cannot sync queue to tip: %w", err)
 		}
+		s.initializeBacklog()
 		s.setSyncStatus(false)
 		go s.SequencerLoop()
+		s.replayPersistedTxs()
 	}
 	return nil
 }
 
+// replayPersistedTxs sequences the transactions of the transaction pool that
+// was persisted on shutdown. The sequencer does not build blocks from the
+// pool, so they are applied the same way as the transactions sent over RPC,
+// which verifies their fees at the current values of the gas price oracle.
+// The transactions that are rejected, such as the ones that became
+// underpriced while the node was down, are dropped.
+func (s *SyncService) replayPersistedTxs() {
+	s.txpool.ReplayPersisted(func(txs []*types.Transaction) []error {
+		errs := make([]error, len(txs))
+		replay := make([]*types.Transaction, 0, len(txs))
+		indexes := make([]int, 0, len(txs))
+		for i, tx := range txs {
+			raw, err := rlp.EncodeToBytes(tx)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			// L1Timestamp and L1BlockNumber will be set right before execution
+			tx.SetTransactionMeta(types.NewTransactionMeta(nil, 0, nil, types.QueueOriginSequencer, nil, nil, raw))
+			replay = append(replay, tx)
+			indexes = append(indexes, i)
+		}
+		for i, err := range s.ValidateAndApplySequencerTransactions(s.ctx, replay) {
+			errs[indexes[i]] = err
+		}
+		for i, err := range errs {
+			if err != nil {
+				log.Debug("Dropped persisted transaction", "hash", txs[i].Hash().Hex(), "err", err)
+			}
+		}
+		return errs
+	})
+}
+
 // initializeLatestL1 sets the initial values of the `L1BlockNumber`
 // and `L1Timestamp` to the deploy height of the Canonical Transaction
 // chain if the chain is empty, otherwise set it from the last
@@ -421,11 +807,11 @@ func (s *SyncService) SequencerLoop() {
 		if err := s.updateL1GasPrice(); err != nil {
 			log.Error("Cannot update L1 gas price", "msg", err)
 		}
-		s.txLock.Lock()
+		s.txLanes.acquire(laneQueue)
 		if err := s.sequence(); err != nil {
 			log.Error("Could not sequence", "error", err)
 		}
-		s.txLock.Unlock()
+		s.txLanes.release()
 
 		if err := s.updateGasPriceOracleCache(nil); err != nil {
 			log.Error("Cannot update L2 gas price", "msg", err)
@@ -481,13 +867,31 @@ func (s *SyncService) syncTransactionsToTip() error {
 
 // updateL1GasPrice queries for the current L1 gas price and then stores it
 // in the L1 Gas Price Oracle. This must be called over time to properly
-// estimate the transaction fees that the sequencer should charge.
+// estimate the transaction fees that the sequencer should charge. Verifiers
+// that observe L1 read it from the L1 node rather than from the data
+// transport layer.
 func (s *SyncService) updateL1GasPrice() error {
-	l1GasPrice, err := s.client.GetL1GasPrice()
+	// The gas prices stay at zero without fees
+	if s.noFees {
+		return nil
+	}
+	var l1GasPrice *big.Int
+	var err error
+	if s.l1Observer != nil {
+		l1GasPrice, err = s.l1Observer.gasPrice(s.ctx)
+		if err == nil {
+			l1ObservedGasPriceGauge.Update(l1GasPrice.Int64())
+		}
+	} else {
+		l1GasPrice, err = s.client.GetL1GasPrice()
+	}
 	if err != nil {
 		return fmt.Errorf("cannot fetch L1 gas price: %w", err)
 	}
 	s.RollupGpo.SetL1GasPrice(l1GasPrice)
+	s.anomalies.observeGasPrice(anomalyL1GasPrice, l1GasPrice)
+	s.thresholdController.observe(l1GasPrice)
+	s.rejectionController.observe(l1GasPrice)
 	return nil
 }
 
@@ -502,8 +906,46 @@ func (s *SyncService) updateL2GasPrice(statedb *state.StateDB) error {
 			return err
 		}
 	}
-	result := statedb.GetState(l2GasPriceOracleAddress, l2GasPriceSlot)
-	s.RollupGpo.SetL2GasPrice(result.Big())
+	slots, err := s.readGPOStorageSlots(statedb)
+	if err != nil {
+		return err
+	}
+	s.setL2GasPrice(slots)
+	return nil
+}
+
+// setL2GasPrice sets the L2 gas price to the value of the gas price oracle
+func (s *SyncService) setL2GasPrice(slots *rcfg.GPOStorageSlots) {
+	if s.noFees {
+		return
+	}
+	s.RollupGpo.SetL2GasPrice(slots.GasPrice)
+	s.anomalies.observeGasPrice(anomalyL2GasPrice, slots.GasPrice)
+	s.setGPOInitialized(slots.Initialized())
+}
+
+// setGPOInitialized records whether the OVM_GasPriceOracle has been
+// initialized, sequencer transactions are refused in strict mode until it is
+func (s *SyncService) setGPOInitialized(initialized bool) {
+	var val uint32
+	if initialized {
+		val = 1
+	}
+	if atomic.SwapUint32(&s.gpoInitialized, val) != val && s.gpoStrict {
+		if initialized {
+			log.Info("Gas price oracle initialized, accepting sequencer transactions")
+		} else {
+			log.Warn("Gas price oracle is not initialized, refusing sequencer transactions")
+		}
+	}
+}
+
+// gpoUninitializedErr returns rcfg.ErrGPOUninitialized in strict mode when the
+// OVM_GasPriceOracle has not been initialized
+func (s *SyncService) gpoUninitializedErr() error {
+	if s.gpoStrict && atomic.LoadUint32(&s.gpoInitialized) == 0 {
+		return fmt.Errorf("%w, refusing to sequence transactions", rcfg.ErrGPOUninitialized)
+	}
 	return nil
 }
 
@@ -517,16 +959,45 @@ func (s *SyncService) cacheGasPriceOracleOwner(statedb *state.StateDB) error {
 			return err
 		}
 	}
+	slots, err := s.readGPOStorageSlots(statedb)
+	if err != nil {
+		return err
+	}
+	s.setGasPriceOracleOwner(slots.Owner)
+	return nil
+}
+
+// setGasPriceOracleOwner caches the gas price oracle owner address
+func (s *SyncService) setGasPriceOracleOwner(owner common.Address) {
 	s.gasPriceOracleOwnerAddressLock.Lock()
 	defer s.gasPriceOracleOwnerAddressLock.Unlock()
-	result := statedb.GetState(l2GasPriceOracleAddress, l2GasPriceOracleOwnerSlot)
-	s.gasPriceOracleOwnerAddress = common.BytesToAddress(result.Bytes())
-	return nil
+	s.gasPriceOracleOwnerAddress = owner
+}
+
+// readGPOStorageSlots reads the OVM_GasPriceOracle with the storage layout
+// that it reports, or with both layouts of a migration that is in progress
+func (s *SyncService) readGPOStorageSlots(statedb *state.StateDB) (*rcfg.GPOStorageSlots, error) {
+	return gaspriceoracle.NewStateGPOReader(statedb, s.gpoLayoutMigration).Read(context.Background())
 }
 
 // updateGasPriceOracleCache caches the owner as well as updating the
-// the L2 gas price from the OVM_GasPriceOracle
+// the L2 gas price from the OVM_GasPriceOracle. When the state is not
+// available locally, the OVM_GasPriceOracle is read from the upstream node
+// if one is configured. The tip is read from the read replica if one is
+// configured while transactions are applied to it.
 func (s *SyncService) updateGasPriceOracleCache(hash *common.Hash) error {
+	// Transactions are being applied to the tip, so its values are read from
+	// the replica rather than from the local state
+	if hash == nil && s.gpoReplica != nil && s.txLanes.busy() {
+		slots, number, err := s.gpoReplica.read(s.ctx, s.bc.CurrentBlock().NumberU64())
+		if err == nil {
+			log.Trace("Read gas price oracle from replica", "number", number)
+			s.setGasPriceOracleOwner(slots.Owner)
+			s.setL2GasPrice(slots)
+			return nil
+		}
+		log.Debug("Cannot read gas price oracle from replica, reading local state", "msg", err)
+	}
 	var statedb *state.StateDB
 	var err error
 	if hash != nil {
@@ -535,7 +1006,16 @@ func (s *SyncService) updateGasPriceOracleCache(hash *common.Hash) error {
 		statedb, err = s.bc.State()
 	}
 	if err != nil {
-		return err
+		if s.gpoFallback == nil {
+			return err
+		}
+		slots, ferr := s.gpoFallback.read(s.ctx)
+		if ferr != nil {
+			return fmt.Errorf("state not available: %v: %w", err, ferr)
+		}
+		s.setGasPriceOracleOwner(slots.Owner)
+		s.setL2GasPrice(slots)
+		return nil
 	}
 	if err := s.cacheGasPriceOracleOwner(statedb); err != nil {
 		return err
@@ -729,6 +1209,11 @@ func (s *SyncService) applyHistoricalTransaction(tx *types.Transaction) error {
 		return fmt.Errorf("More than one transaction found in block %d", *index+1)
 	}
 	if !isCtcTxEqual(tx, txs[0]) {
+		// The blocks that were synced over the p2p network are only
+		// trusted once they match L1, so the sync stops on a mismatch
+		if s.p2pSync {
+			return fmt.Errorf("%w: index %d, historical transaction %s", errPeerSyncMismatch, *index, tx.Hash().Hex())
+		}
 		log.Error("Mismatched transaction", "index", *index)
 	} else {
 		log.Debug("Historical transaction matches", "index", *index, "hash", tx.Hash().Hex())
@@ -741,6 +1226,7 @@ func (s *SyncService) applyHistoricalTransaction(tx *types.Transaction) error {
 // the chain. It is assumed that validation around the index has already
 // happened.
 func (s *SyncService) applyTransactionToTip(tx *types.Transaction) error {
+	defer profiling.Default.Observe("block", time.Now())
 	if tx == nil {
 		return errors.New("nil transaction passed to applyTransactionToTip")
 	}
@@ -752,6 +1238,16 @@ func (s *SyncService) applyTransactionToTip(tx *types.Transaction) error {
 			return fmt.Errorf("Queue origin L1 to L2 transaction without a timestamp: %s", tx.Hash().Hex())
 		}
 	}
+	// The verifier recomputes the fee that the sequencer charged before
+	// executing the transaction
+	if s.verifier && s.feeAssertion {
+		if err := s.assertTipFee(tx); err != nil {
+			return err
+		}
+	}
+	if s.verifier && s.l1Observer != nil {
+		s.observeChargedL1GasPrice(tx)
+	}
 	// If there is no OVM timestamp assigned to the transaction, then assign a
 	// timestamp and blocknumber to it. This should only be the case for queue
 	// origin sequencer transactions that come in via RPC. The L1 to L2
@@ -793,12 +1289,19 @@ func (s *SyncService) applyTransactionToTip(tx *types.Transaction) error {
 	}
 	// The index was set above so it is safe to dereference
 	log.Debug("Applying transaction to tip", "index", *tx.GetMeta().Index, "hash", tx.Hash().Hex())
+	if !s.verifier {
+		s.backlog.add(*tx.GetMeta().Index, uint64(tx.Size()), time.Now())
+		s.recordL1Fee(tx)
+	}
 
 	txs := types.Transactions{tx}
 	s.txFeed.Send(core.NewTxsEvent{Txs: txs})
 	// Block until the transaction has been added to the chain
 	log.Trace("Waiting for transaction to be added to chain", "hash", tx.Hash().Hex())
-	<-s.chainHeadCh
+	head := <-s.chainHeadCh
+	if s.dappMetrics != nil && head.Block != nil {
+		s.dappMetrics.record(rawdb.ReadBlockFees(s.db, head.Block.NumberU64()))
+	}
 
 	return nil
 }
@@ -821,110 +1324,338 @@ func (s *SyncService) applyBatchedTransaction(tx *types.Transaction) error {
 		return fmt.Errorf("Cannot apply batched transaction: %w", err)
 	}
 	s.SetLatestVerifiedIndex(index)
+	s.backlog.prune(*index)
 	return nil
 }
 
-// verifyFee will verify that a valid fee is being paid.
-func (s *SyncService) verifyFee(tx *types.Transaction) error {
-	if tx.GasPrice().Cmp(common.Big0) == 0 {
-		// Allow 0 gas price transactions only if it is the owner of the gas
-		// price oracle
-		gpoOwner := s.GasPriceOracleOwnerAddress()
-		if gpoOwner != nil {
-			from, err := types.Sender(s.signer, tx)
-			if err != nil {
-				return fmt.Errorf("invalid transaction: %w", core.ErrInvalidSender)
-			}
-			if from == *gpoOwner {
-				return nil
-			}
+// initializeBacklog rebuilds the in memory backlog of transactions that have
+// been sequenced but not yet batch submitted from the chain. This must be
+// called after syncing to the tip so that the verified index is up to date.
+func (s *SyncService) initializeBacklog() {
+	s.backlog.reset()
+	latest := s.GetLatestIndex()
+	if latest == nil {
+		return
+	}
+	start := s.GetNextVerifiedIndex()
+	for index := start; index <= *latest; index++ {
+		// Handle the off by one
+		block := s.bc.GetBlockByNumber(index + 1)
+		if block == nil {
+			log.Error("Cannot find block while initializing backlog", "index", index)
+			break
 		}
-		// Exit early if fees are enforced and the gasPrice is set to 0
-		if s.enforceFees {
-			return errZeroGasPriceTx
+		for _, tx := range block.Transactions() {
+			s.backlog.add(index, uint64(tx.Size()), time.Unix(int64(block.Time()), 0))
 		}
-		// If fees are not enforced and the gas price is 0, return early
-		return nil
 	}
-	// When the gas price is non zero, it must be equal to the constant
-	if tx.GasPrice().Cmp(fees.BigTxGasPrice) != 0 {
-		return fmt.Errorf("tx.gasPrice must be %d", fees.TxGasPrice)
+	bytes, count, lag := s.backlog.stats(time.Now())
+	log.Info("Initialized unbatched backlog", "bytes", bytes, "txs", count, "lag", lag)
+}
+
+// throttle blocks the caller when the backlog of transactions that have not
+// yet been batch submitted grows past the configured threshold and returns an
+// error when transactions should not be accepted at all. It is meant to be
+// called while holding the tip of the chain so that the delay applies to the sequencer
+// as a whole rather than to individual RPC requests.
+func (s *SyncService) throttle() error {
+	bytes, count, lag := s.backlog.stats(time.Now())
+	backlogBytesGauge.Update(int64(bytes))
+	backlogTxsGauge.Update(int64(count))
+	backlogLagGauge.Update(int64(lag))
+	if !s.backlogThrottle.enabled() {
+		return nil
 	}
-	l1GasPrice, err := s.RollupGpo.SuggestL1GasPrice(context.Background())
+	delay, err := s.backlogThrottle.delay(bytes, lag)
 	if err != nil {
-		return err
+		backlogRejectMeter.Mark(1)
+		log.Warn("Rejecting transaction due to unbatched backlog", "bytes", bytes, "txs", count, "lag", lag)
+		return fmt.Errorf("%w: %d bytes in %d transactions, oldest is %s", err, bytes, count, lag)
 	}
-	l2GasPrice, err := s.RollupGpo.SuggestL2GasPrice(context.Background())
-	if err != nil {
-		return err
+	if delay > 0 {
+		backlogThrottleMeter.Mark(1)
+		backlogDelayTimer.Update(delay)
+		log.Debug("Throttling transaction due to unbatched backlog", "bytes", bytes, "delay", delay)
+		time.Sleep(delay)
 	}
-	// Calculate the fee based on decoded L2 gas limit
-	gas := new(big.Int).SetUint64(tx.Gas())
-	l2GasLimit := fees.DecodeL2GasLimit(gas)
+	return nil
+}
 
-	// When the L2 gas limit is smaller than the min L2 gas limit,
-	// reject the transaction
-	if l2GasLimit.Cmp(s.minL2GasLimit) == -1 {
-		return fmt.Errorf("%w: %d, use at least %d", fees.ErrL2GasLimitTooLow, l2GasLimit, s.minL2GasLimit)
-	}
+// verifyFee will verify that a valid fee is being paid.
+func (s *SyncService) verifyFee(ctx context.Context, tx *types.Transaction) error {
+	return s.verifyFeeAt(ctx, tx, s.snapshotFees(ctx), feesig.FeeQuoteFromContext(ctx))
+}
 
-	// Only count the calldata here as the overhead of the fully encoded
-	// RLP transaction is handled inside of EncodeL2GasLimit
-	expectedTxGasLimit := fees.EncodeTxGasLimit(tx.Data(), l1GasPrice, l2GasLimit, l2GasPrice)
+// verifyFeeAt verifies the fee of a transaction against a snapshot of the
+// gas price oracle, with the gas prices of the fee quote when it is not nil
+func (s *SyncService) verifyFeeAt(ctx context.Context, tx *types.Transaction, snapshot *feeSnapshot, quote *feesig.FeeQuote) error {
+	decision := &feeDecision{tx: tx, signer: s.signer, decision: feeDecisionAccept}
+	return s.decideFee(ctx, tx, snapshot, quote, decision)
+}
 
-	// This should only happen if the unscaled transaction fee is greater than 18.44 ETH
-	if !expectedTxGasLimit.IsUint64() {
-		return fmt.Errorf("fee overflow: %s", expectedTxGasLimit.String())
-	}
+// decideFee is verifyFeeAt that records the outcome of the verification in
+// the fee decision of the caller
+func (s *SyncService) decideFee(ctx context.Context, tx *types.Transaction, snapshot *feeSnapshot, quote *feesig.FeeQuote, decision *feeDecision) (err error) {
+	defer profiling.Default.Observe("fee", time.Now())
+	ctx, span := tracing.StartSpan(ctx, "rollup.verifyFee")
+	defer func() {
+		decision.log(err)
+		s.admissions.observe(decision, err)
+		if s.feeAudit != nil {
+			decision.audit(s.feeAudit, err)
+		}
+		if s.feeCheckRecorder != nil {
+			s.feeCheckRecorder.record(decision)
+		}
+		s.rejectionController.observeCheck(decision, err)
+		span.Finish(err)
+	}()
 
-	userFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
-	expectedFee := new(big.Int).Mul(expectedTxGasLimit, fees.BigTxGasPrice)
-	opts := fees.PaysEnoughOpts{
-		UserFee:       userFee,
-		ExpectedFee:   expectedFee,
-		ThresholdUp:   s.feeThresholdUp,
-		ThresholdDown: s.feeThresholdDown,
+	check, err := s.checkFee(ctx, tx, snapshot, quote, decision, span)
+	if check == nil || err != nil {
+		return err
+	}
+	if decision.l1Subsidy != nil {
+		feeSubsidyMeter.Mark(1)
+	}
+	opts, expectedTxGasLimit := check.opts, check.expectedTxGasLimit
+	userFee, expectedFee := opts.UserFee, opts.ExpectedFee
+	s.anomalies.observeFee(expectedFee)
+	if len(s.feeHooks) != 0 {
+		adjusted, err := s.checkFeeHooks(decision, &opts)
+		if err != nil {
+			feeHookVetoMeter.Mark(1)
+			return err
+		}
+		if adjusted {
+			feeHookAdjustMeter.Mark(1)
+			decision.hookAdjusted = true
+			expectedFee = opts.ExpectedFee
+			decision.expectedFee = expectedFee
+			// The gas limit that pays the adjusted fee, rounded up
+			expectedTxGasLimit = new(big.Int).Div(fees.Ceilmod(expectedFee, fees.BigTxGasPrice), fees.BigTxGasPrice)
+		}
+	}
+	err = fees.PaysEnough(&opts)
+	if s.shadowPolicies != nil {
+		gasLimit := fees.EncodeTxGasLimitForL1Fee(check.price.l1Fee, decision.l2GasLimit, decision.l2GasPrice)
+		s.shadowFeePolicy(tx, decision, gasLimit, snapshot.thresholdDown, err)
 	}
 	// Check the error type and return the correct error message to the user
-	if err := fees.PaysEnough(&opts); err != nil {
+	if err != nil {
 		if errors.Is(err, fees.ErrFeeTooLow) {
-			return fmt.Errorf("%w: %d, use at least tx.gasLimit = %d and tx.gasPrice = %d",
+			err = fmt.Errorf("%w: %d, use at least tx.gasLimit = %d and tx.gasPrice = %d",
 				fees.ErrFeeTooLow, userFee, expectedTxGasLimit, fees.BigTxGasPrice)
+			return fees.NewFeeRejection(err, &opts, expectedTxGasLimit.Uint64())
 		}
 		if errors.Is(err, fees.ErrFeeTooHigh) {
-			return fmt.Errorf("%w: %d, use less than %d * %f", fees.ErrFeeTooHigh, userFee,
-				expectedFee, s.feeThresholdUp)
+			err = fmt.Errorf("%w: %d, use less than %d * %f", fees.ErrFeeTooHigh, userFee,
+				expectedFee, opts.ThresholdUp)
+			return fees.NewFeeRejection(err, &opts, expectedTxGasLimit.Uint64())
 		}
 		return err
 	}
+	if quote != nil {
+		err := s.feeQuotes.consume(quote, s.bc.CurrentBlock().NumberU64()+1, userFee)
+		markFeeQuote(err)
+		return err
+	}
 	return nil
 }
 
+// IssueFeeQuote returns a fee quote for the sender at the current gas prices,
+// signed with the fee quote key
+func (s *SyncService) IssueFeeQuote(ctx context.Context, sender common.Address) (*feesig.FeeQuote, error) {
+	if s.feeQuoteKey == nil {
+		return nil, errors.New("fee quotes are not enabled")
+	}
+	l1GasPrice, err := s.RollupGpo.SuggestL1GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l2GasPrice, err := s.RollupGpo.SuggestL2GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quote := &feesig.FeeQuote{
+		ChainID:    (*hexutil.Big)(s.bc.Config().ChainID),
+		Sender:     sender,
+		L1GasPrice: (*hexutil.Big)(l1GasPrice),
+		L2GasPrice: (*hexutil.Big)(l2GasPrice),
+		ValidUntil: hexutil.Uint64(s.bc.CurrentBlock().NumberU64() + s.feeQuoteValidity),
+	}
+	if err := quote.Sign(s.feeQuoteKey); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// FeeQuoteConsumption returns the transactions that were accepted with the
+// fee quote of the hash, nil when none were accepted
+func (s *SyncService) FeeQuoteConsumption(hash common.Hash) *feesig.FeeQuoteConsumption {
+	if s.feeQuotes == nil {
+		return nil
+	}
+	return s.feeQuotes.Consumption(hash)
+}
+
+// verifyFeeQuote verifies that the fee quote was issued by the sequencer to
+// the sender of the transaction and that the transaction can still be
+// included before the quote expires
+func (s *SyncService) verifyFeeQuote(quote *feesig.FeeQuote, tx *types.Transaction) error {
+	if s.feeQuoteKey == nil {
+		return fmt.Errorf("%w: fee quotes are not enabled", feesig.ErrInvalidFeeQuote)
+	}
+	signer, err := quote.Signer()
+	if err != nil {
+		return err
+	}
+	if signer != crypto.PubkeyToAddress(s.feeQuoteKey.PublicKey) {
+		return fmt.Errorf("%w: not signed by the sequencer", feesig.ErrInvalidFeeQuote)
+	}
+	from, err := types.Sender(s.signer, tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %w", core.ErrInvalidSender)
+	}
+	if from != quote.Sender {
+		return fmt.Errorf("%w: issued to %s, transaction sent by %s", feesig.ErrInvalidFeeQuote,
+			quote.Sender.Hex(), from.Hex())
+	}
+	// The transaction is included in the next block
+	if next := s.bc.CurrentBlock().NumberU64() + 1; next > uint64(quote.ValidUntil) {
+		return fmt.Errorf("%w: valid until block %d, next block is %d", feesig.ErrFeeQuoteExpired,
+			uint64(quote.ValidUntil), next)
+	}
+	return nil
+}
+
+// FeeReconciliation returns up to count of the most recently reconciled
+// transaction batches, nil when fee reconciliation is disabled
+func (s *SyncService) FeeReconciliation(count int) []*fees.Reconciliation {
+	if s.reconciler == nil {
+		return nil
+	}
+	return s.reconciler.Reports(count)
+}
+
+// ConversionRateOracle returns the oracle of the configured conversion feed,
+// nil when no conversion feed is configured
+func (s *SyncService) ConversionRateOracle() fees.ConversionRateOracle {
+	return s.conversionRate
+}
+
 // Higher level API for applying transactions. Should only be called for
 // queue origin sequencer transactions, as the contracts on L1 manage the same
 // validity checks that are done here.
-func (s *SyncService) ValidateAndApplySequencerTransaction(tx *types.Transaction) error {
+func (s *SyncService) ValidateAndApplySequencerTransaction(ctx context.Context, tx *types.Transaction) error {
 	if s.verifier {
 		return errors.New("Verifier does not accept transactions out of band")
 	}
 	if tx == nil {
 		return errors.New("nil transaction passed to ValidateAndApplySequencerTransaction")
 	}
-	if err := s.verifyFee(tx); err != nil {
+	// Transactions that are applied first come first served take their
+	// place in line before their fee is verified
+	arrival := s.txLanes.arrive()
+	defer s.txLanes.leave(arrival)
+
+	decision := &feeDecision{tx: tx, signer: s.signer, decision: feeDecisionAccept}
+	if err := s.decideFee(ctx, tx, s.snapshotFees(ctx), feesig.FeeQuoteFromContext(ctx), decision); err != nil {
 		return err
 	}
-	s.txLock.Lock()
-	defer s.txLock.Unlock()
+	w := arrival
+	if w == nil {
+		w = s.txLanes.join(s.txLanes.laneOf(decision))
+	}
+	s.waitTurn(ctx, w)
+	defer s.txLanes.release()
+
+	// The fee of a transaction with a transfer authorization is collected
+	// right before the transaction is applied, once the transaction is known
+	// to be valid so that the fee is not collected for a transaction that
+	// is then rejected
+	if auth := feesig.TransferAuthorizationFromContext(ctx); auth != nil && tx.GasPrice().Sign() == 0 && !s.noFees {
+		if err := s.validateAtTip(ctx, tx); err != nil {
+			return err
+		}
+		// The fee collection takes the L1 context of the transaction
+		// that it pays for
+		if tx.L1Timestamp() == 0 {
+			tx.SetL1Timestamp(s.GetLatestL1Timestamp())
+			tx.SetL1BlockNumber(s.GetLatestL1BlockNumber())
+		}
+		if err := s.collectFee(ctx, auth, tx); err != nil {
+			return err
+		}
+		return s.applyValidatedAtTip(ctx, tx, w)
+	}
+	return s.applyAtTip(ctx, tx, w)
+}
+
+// applySequencerTransaction applies a sequencer transaction whose fee has
+// been verified once its turn has come in the lane
+func (s *SyncService) applySequencerTransaction(ctx context.Context, tx *types.Transaction, lane int) error {
+	w := s.txLanes.join(lane)
+	s.waitTurn(ctx, w)
+	defer s.txLanes.release()
+	return s.applyAtTip(ctx, tx, w)
+}
+
+// waitTurn blocks until the tip of the chain is free for the waiter, which
+// must release it once it is done
+func (s *SyncService) waitTurn(ctx context.Context, w *laneWaiter) {
+	_, span := tracing.StartSpan(ctx, "rollup.acquireTxLock")
+	s.txLanes.wait(w)
+	span.SetAttribute("lane", laneNames[w.lane])
+	span.Finish(nil)
+}
+
+// applyAtTip applies a sequencer transaction to the tip of the chain, which
+// the caller must hold
+func (s *SyncService) applyAtTip(ctx context.Context, tx *types.Transaction, w *laneWaiter) error {
+	if err := s.validateAtTip(ctx, tx); err != nil {
+		return err
+	}
+	return s.applyValidatedAtTip(ctx, tx, w)
+}
+
+// validateAtTip checks that a sequencer transaction can be applied to the
+// tip of the chain, which the caller must hold
+func (s *SyncService) validateAtTip(ctx context.Context, tx *types.Transaction) error {
 	log.Trace("Sequencer transaction validation", "hash", tx.Hash().Hex())
 
+	_, span := tracing.StartSpan(ctx, "rollup.throttle")
+	err := s.throttle()
+	span.Finish(err)
+	if err != nil {
+		return err
+	}
+
 	qo := tx.QueueOrigin()
 	if qo != types.QueueOriginSequencer {
 		return fmt.Errorf("invalid transaction with queue origin %d", qo)
 	}
-	if err := s.txpool.ValidateTx(tx); err != nil {
+	_, span = tracing.StartSpan(ctx, "txpool.ValidateTx")
+	err = s.txpool.ValidateTx(tx)
+	span.Finish(err)
+	if err != nil {
 		return fmt.Errorf("invalid transaction: %w", err)
 	}
-	return s.applyTransaction(tx)
+	return nil
+}
+
+// applyValidatedAtTip applies a sequencer transaction that validateAtTip
+// accepted to the tip of the chain, which the caller must hold. The arrival
+// of the transaction is recorded when transactions are applied first come
+// first served.
+func (s *SyncService) applyValidatedAtTip(ctx context.Context, tx *types.Transaction, w *laneWaiter) error {
+	_, span := tracing.StartSpan(ctx, "rollup.applyTransaction")
+	err := s.applyTransaction(tx)
+	span.Finish(err)
+	if err == nil && s.txLanes.fcfs && w != nil {
+		rawdb.WriteTxArrival(s.db, tx.Hash(), uint64(w.joined.UnixNano()/int64(time.Millisecond)))
+	}
+	return err
 }
 
 // syncer represents a function that can sync remote items and then returns the
@@ -1040,6 +1771,7 @@ func (s *SyncService) syncTransactionBatchRange(start, end uint64) error {
 	log.Info("Syncing transaction batch range", "start", start, "end", end)
 	for i := start; i <= end; i++ {
 		log.Debug("Fetching transaction batch", "index", i)
+		batchStart := time.Now()
 		_, txs, err := s.client.GetTransactionBatch(i)
 		if err != nil {
 			return fmt.Errorf("Cannot get transaction batch: %w", err)
@@ -1049,6 +1781,8 @@ func (s *SyncService) syncTransactionBatchRange(start, end uint64) error {
 				return fmt.Errorf("cannot apply batched transaction: %w", err)
 			}
 		}
+		profiling.Default.Observe("batch", batchStart)
+		s.publishFeeEvents(i, txs)
 		s.SetLatestBatchIndex(&i)
 	}
 	return nil
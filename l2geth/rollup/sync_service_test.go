@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rollup/fees"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
 )
 
 func setupLatestEthContextTest() (*SyncService, *EthContext) {
@@ -534,7 +535,7 @@ func TestSyncServiceL2GasPrice(t *testing.T) {
 		t.Fatal("Cannot get state db")
 	}
 	l2GasPrice := big.NewInt(100000000000)
-	state.SetState(l2GasPriceOracleAddress, l2GasPriceSlot, common.BigToHash(l2GasPrice))
+	state.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceSlot, common.BigToHash(l2GasPrice))
 	_, _ = state.Commit(false)
 
 	service.updateL2GasPrice(state)
@@ -549,6 +550,50 @@ func TestSyncServiceL2GasPrice(t *testing.T) {
 	}
 }
 
+func TestSyncServiceStrictGPO(t *testing.T) {
+	tests := map[string]struct {
+		strict   bool
+		owner    common.Address
+		gasPrice *big.Int
+		expect   error
+	}{
+		"strict-uninitialized": {true, common.Address{}, new(big.Int), rcfg.ErrGPOUninitialized},
+		"strict-no-owner":      {true, common.Address{}, big.NewInt(1), rcfg.ErrGPOUninitialized},
+		"strict-zero-price":    {true, common.HexToAddress("0x01"), new(big.Int), rcfg.ErrGPOUninitialized},
+		"strict-initialized":   {true, common.HexToAddress("0x01"), big.NewInt(1), nil},
+		"opt-out":              {false, common.Address{}, new(big.Int), nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, _, _, err := newTestSyncService(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			service.gpoStrict = tt.strict
+			state, err := service.bc.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			state.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceOracleOwnerSlot, common.BytesToHash(tt.owner.Bytes()))
+			state.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceSlot, common.BigToHash(tt.gasPrice))
+			if err := service.updateL2GasPrice(state); err != nil {
+				t.Fatal(err)
+			}
+			// A zero gas price transaction is accepted when fees are not
+			// enforced, unless the oracle is uninitialized in strict mode
+			key, _ := crypto.GenerateKey()
+			tx, err := types.SignTx(mockTx(), types.NewEIP155Signer(big.NewInt(420)), key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = service.verifyFee(context.Background(), tx)
+			if !errors.Is(err, tt.expect) || (tt.expect == nil && err != nil) {
+				t.Fatalf("mismatched error: got %v, expect %v", err, tt.expect)
+			}
+		})
+	}
+}
+
 func TestSyncServiceMinL2GasPrice(t *testing.T) {
 	service, _, _, err := newTestSyncService(true)
 	if err != nil {
@@ -575,7 +620,7 @@ func TestSyncServiceMinL2GasPrice(t *testing.T) {
 		t.Fatal("L2 gas limit expected to be smaller than min accepted by sequencer")
 	}
 	// Verify the fee of the signed tx, ensure it does not error
-	err = service.verifyFee(signedTx)
+	err = service.verifyFee(context.Background(), signedTx)
 	if !errors.Is(err, fees.ErrL2GasLimitTooLow) {
 		t.Fatal(err)
 	}
@@ -600,7 +645,7 @@ func TestSyncServiceGasPriceOracleOwnerAddress(t *testing.T) {
 
 	// Update the owner in the state to a non zero address
 	updatedOwner := common.HexToAddress("0xEA674fdDe714fd979de3EdF0F56AA9716B898ec8")
-	state.SetState(l2GasPriceOracleAddress, l2GasPriceOracleOwnerSlot, updatedOwner.Hash())
+	state.SetState(rcfg.L2GasPriceOracleAddress, rcfg.L2GasPriceOracleOwnerSlot, updatedOwner.Hash())
 	hash, _ := state.Commit(false)
 
 	// Update the cache based on the latest state root
@@ -645,7 +690,7 @@ func TestFeeGasPriceOracleOwnerTransactions(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Verify the fee of the signed tx, ensure it does not error
-	if err := service.verifyFee(signedTx); err != nil {
+	if err := service.verifyFee(context.Background(), signedTx); err != nil {
 		t.Fatal(err)
 	}
 	// Generate a new random key that is not the owner
@@ -661,7 +706,7 @@ func TestFeeGasPriceOracleOwnerTransactions(t *testing.T) {
 	}
 	// Attempt to verify the fee of the bad tx
 	// It should error and be a errZeroGasPriceTx
-	if err := service.verifyFee(badSignedTx); err != nil {
+	if err := service.verifyFee(context.Background(), badSignedTx); err != nil {
 		if !errors.Is(errZeroGasPriceTx, err) {
 			t.Fatal(err)
 		}
@@ -798,6 +843,7 @@ func TestBadFeeThresholds(t *testing.T) {
 	tests := map[string]struct {
 		thresholdUp   *big.Float
 		thresholdDown *big.Float
+		margin        uint64
 		err           error
 	}{
 		"nil-values": {
@@ -820,12 +866,23 @@ func TestBadFeeThresholds(t *testing.T) {
 			thresholdDown: new(big.Float).SetFloat64(1.1),
 			err:           errBadConfig,
 		},
+		"good-margin": {
+			thresholdUp: new(big.Float).SetFloat64(2),
+			margin:      200,
+			err:         nil,
+		},
+		"bad-margin": {
+			thresholdUp: new(big.Float).SetFloat64(2),
+			margin:      201,
+			err:         errBadConfig,
+		},
 	}
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			cfg.FeeThresholdDown = tt.thresholdDown
 			cfg.FeeThresholdUp = tt.thresholdUp
+			cfg.FeeEstimateMargin = tt.margin
 
 			_, err := NewSyncService(context.Background(), cfg, txPool, chain, db)
 			if !errors.Is(err, tt.err) {
@@ -835,6 +892,48 @@ func TestBadFeeThresholds(t *testing.T) {
 	}
 }
 
+func TestNoFees(t *testing.T) {
+	cfg, txPool, chain, db, err := newTestSyncServiceDeps(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.EnforceFees = true
+	cfg.NoFees = true
+	service, err := NewSyncService(context.Background(), cfg, txPool, chain, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.RollupGpo = gasprice.NewRollupOracle()
+	// The gas prices of the oracle are not updated
+	setupMockClient(service, map[string]interface{}{})
+	if err := service.updateL1GasPrice(); err != nil {
+		t.Fatal(err)
+	}
+	service.setL2GasPrice(&rcfg.GPOStorageSlots{GasPrice: big.NewInt(1)})
+	if l1GasPrice, _ := service.RollupGpo.SuggestL1GasPrice(context.Background()); l1GasPrice.Sign() != 0 {
+		t.Fatalf("L1 gas price updated: %d", l1GasPrice)
+	}
+	if l2GasPrice, _ := service.RollupGpo.SuggestL2GasPrice(context.Background()); l2GasPrice.Sign() != 0 {
+		t.Fatalf("L2 gas price updated: %d", l2GasPrice)
+	}
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(420))
+	for _, gasPrice := range []*big.Int{new(big.Int), fees.BigTxGasPrice, big.NewInt(7)} {
+		tx := types.NewTransaction(0, common.Address{}, new(big.Int), 21_000, gasPrice, nil)
+		tx, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := service.verifyFee(context.Background(), tx); err != nil {
+			t.Fatalf("gas price %d: fee rejected: %v", gasPrice, err)
+		}
+		if fee := service.PoolTxFee(tx); !fee.PaysEnough {
+			t.Fatalf("gas price %d: pool fee rejected: %s", gasPrice, fee.Reason)
+		}
+	}
+}
+
 func newTestSyncServiceDeps(isVerifier bool) (Config, *core.TxPool, *core.BlockChain, ethdb.Database, error) {
 	chainCfg := params.AllEthashProtocolChanges
 	chainID := big.NewInt(420)
@@ -888,6 +987,7 @@ type mockClient struct {
 	getLatestEthContext            *EthContext
 	getLatestEnqueueIndex          []func() (*uint64, error)
 	getLatestEnqueueIndexCallCount int
+	getTransactionBatch            []*Batch
 }
 
 func setupMockClient(service *SyncService, responses map[string]interface{}) {
@@ -923,6 +1023,11 @@ func newMockClient(responses map[string]interface{}) *mockClient {
 	if ok {
 		getLatestEnqueueIndexResponses = getLatestEnqueueIdx.([]func() (*uint64, error))
 	}
+	getTransactionBatchResponses := []*Batch{}
+	getBatch, ok := responses["GetTransactionBatch"]
+	if ok {
+		getTransactionBatchResponses = getBatch.([]*Batch)
+	}
 
 	return &mockClient{
 		getEnqueue:            getEnqueueResponses,
@@ -930,6 +1035,7 @@ func newMockClient(responses map[string]interface{}) *mockClient {
 		getEthContext:         getEthContextResponses,
 		getLatestEthContext:   getLatestEthContextResponse,
 		getLatestEnqueueIndex: getLatestEnqueueIndexResponses,
+		getTransactionBatch:   getTransactionBatchResponses,
 	}
 }
 
@@ -987,6 +1093,9 @@ func (m *mockClient) GetLatestTransactionBatch() (*Batch, []*types.Transaction,
 }
 
 func (m *mockClient) GetTransactionBatch(index uint64) (*Batch, []*types.Transaction, error) {
+	if index < uint64(len(m.getTransactionBatch)) {
+		return m.getTransactionBatch[index], nil, nil
+	}
 	return nil, nil, nil
 }
 
@@ -0,0 +1,379 @@
+package systest
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup"
+)
+
+// L1Config configures the L1 chain
+type L1Config struct {
+	// GasPrice is the initial gas price of the chain
+	GasPrice *big.Int
+	// BlockTime is the number of seconds between blocks
+	BlockTime uint64
+}
+
+// L1 is an in-memory L1 chain as it is seen through the data transport
+// layer. It holds the transaction batches of the canonical transaction chain
+// and the state roots of the state commitment chain, and serves them to the
+// L2 nodes over the HTTP API of the data transport layer.
+type L1 struct {
+	cfg     L1Config
+	chainID *big.Int
+
+	mu       sync.Mutex
+	blocks   []*rollup.EthContext
+	gasPrice *big.Int
+	batches  []*rollup.Batch
+	// The elements of the canonical transaction chain and the index of the
+	// batch that appended each of them
+	elements     []*types.Transaction
+	batchIndices []uint64
+	stateRoots   []common.Hash
+	// The chain of the sequencer, the transactions that are not batch
+	// submitted yet are served from it to the nodes that sync from L2
+	l2 *core.BlockChain
+
+	server *httptest.Server
+}
+
+// newL1 starts the L1 chain and the data transport layer, chainID is the
+// chain id of the L2 chain
+func newL1(cfg L1Config, chainID *big.Int) *L1 {
+	l1 := &L1{
+		cfg:      cfg,
+		chainID:  chainID,
+		gasPrice: new(big.Int).Set(cfg.GasPrice),
+	}
+	l1.blocks = append(l1.blocks, newEthContext(0, uint64(time.Now().Unix())))
+	l1.server = httptest.NewServer(l1)
+	return l1
+}
+
+func newEthContext(number, timestamp uint64) *rollup.EthContext {
+	return &rollup.EthContext{
+		BlockNumber: number,
+		BlockHash:   crypto.Keccak256Hash(new(big.Int).SetUint64(number).Bytes()),
+		Timestamp:   timestamp,
+	}
+}
+
+// URL is the endpoint of the data transport layer
+func (l1 *L1) URL() string {
+	return l1.server.URL
+}
+
+// Mine adds a block to the chain and returns it
+func (l1 *L1) Mine() *rollup.EthContext {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return l1.mine()
+}
+
+func (l1 *L1) mine() *rollup.EthContext {
+	head := l1.blocks[len(l1.blocks)-1]
+	block := newEthContext(head.BlockNumber+1, head.Timestamp+l1.cfg.BlockTime)
+	l1.blocks = append(l1.blocks, block)
+	return block
+}
+
+// Head returns the latest block of the chain
+func (l1 *L1) Head() *rollup.EthContext {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return l1.blocks[len(l1.blocks)-1]
+}
+
+// SetGasPrice sets the gas price of the chain
+func (l1 *L1) SetGasPrice(price *big.Int) {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	l1.gasPrice = new(big.Int).Set(price)
+}
+
+// GasPrice returns the gas price of the chain
+func (l1 *L1) GasPrice() *big.Int {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return new(big.Int).Set(l1.gasPrice)
+}
+
+// TotalElements returns the number of transactions of the canonical
+// transaction chain
+func (l1 *L1) TotalElements() uint64 {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return uint64(len(l1.elements))
+}
+
+// Batches returns the transaction batches of the canonical transaction chain
+func (l1 *L1) Batches() []*rollup.Batch {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return append([]*rollup.Batch{}, l1.batches...)
+}
+
+// StateRoots returns the state roots of the state commitment chain, the state
+// root at an index is the state after the transaction at the same index of
+// the canonical transaction chain
+func (l1 *L1) StateRoots() []common.Hash {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return append([]common.Hash{}, l1.stateRoots...)
+}
+
+// setL2 sets the chain of the sequencer
+func (l1 *L1) setL2(chain *core.BlockChain) {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	l1.l2 = chain
+}
+
+// appendBatch appends a batch of transactions to the canonical transaction
+// chain in a new block
+func (l1 *L1) appendBatch(txs []*types.Transaction, submitter common.Address) *rollup.Batch {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+
+	block := l1.mine()
+	batch := &rollup.Batch{
+		Index:             uint64(len(l1.batches)),
+		Size:              uint32(len(txs)),
+		PrevTotalElements: uint32(len(l1.elements)),
+		BlockNumber:       block.BlockNumber,
+		Timestamp:         block.Timestamp,
+		Submitter:         submitter,
+	}
+	l1.batches = append(l1.batches, batch)
+	for _, tx := range txs {
+		l1.elements = append(l1.elements, tx)
+		l1.batchIndices = append(l1.batchIndices, batch.Index)
+	}
+	return batch
+}
+
+// appendStateBatch appends a batch of state roots to the state commitment
+// chain in a new block
+func (l1 *L1) appendStateBatch(roots []common.Hash) {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+
+	l1.mine()
+	l1.stateRoots = append(l1.stateRoots, roots...)
+}
+
+// transaction is a transaction of the canonical transaction chain in the
+// API of the data transport layer
+type transaction struct {
+	Index       uint64          `json:"index"`
+	BatchIndex  uint64          `json:"batchIndex"`
+	BlockNumber uint64          `json:"blockNumber"`
+	Timestamp   uint64          `json:"timestamp"`
+	Value       *hexutil.Big    `json:"value"`
+	GasLimit    uint64          `json:"gasLimit,string"`
+	Target      common.Address  `json:"target"`
+	Origin      *common.Address `json:"origin"`
+	Data        hexutil.Bytes   `json:"data"`
+	QueueOrigin string          `json:"queueOrigin"`
+	QueueIndex  *uint64         `json:"queueIndex"`
+	Decoded     *decoded        `json:"decoded"`
+}
+
+// decoded is a queue origin sequencer transaction decoded from its batch
+type decoded struct {
+	Signature struct {
+		R hexutil.Bytes `json:"r"`
+		S hexutil.Bytes `json:"s"`
+		V uint          `json:"v"`
+	} `json:"sig"`
+	Value    *hexutil.Big    `json:"value"`
+	GasLimit uint64          `json:"gasLimit,string"`
+	GasPrice uint64          `json:"gasPrice,string"`
+	Nonce    uint64          `json:"nonce,string"`
+	Target   *common.Address `json:"target"`
+	Data     hexutil.Bytes   `json:"data"`
+}
+
+type transactionResponse struct {
+	Transaction *transaction  `json:"transaction"`
+	Batch       *rollup.Batch `json:"batch"`
+}
+
+type transactionBatchResponse struct {
+	Batch        *rollup.Batch  `json:"batch"`
+	Transactions []*transaction `json:"transactions"`
+}
+
+// encodeTransaction encodes a queue origin sequencer transaction of the
+// canonical transaction chain
+func (l1 *L1) encodeTransaction(index, batchIndex uint64, tx *types.Transaction) *transaction {
+	raw := tx.GetMeta().RawTransaction
+	if len(raw) == 0 {
+		raw, _ = rlp.EncodeToBytes(tx)
+	}
+	res := &transaction{
+		Index:       index,
+		BatchIndex:  batchIndex,
+		BlockNumber: tx.L1BlockNumber().Uint64(),
+		Timestamp:   tx.L1Timestamp(),
+		Value:       (*hexutil.Big)(tx.Value()),
+		GasLimit:    tx.Gas(),
+		Data:        raw,
+		QueueOrigin: "sequencer",
+		Decoded: &decoded{
+			Value:    (*hexutil.Big)(tx.Value()),
+			GasLimit: tx.Gas(),
+			GasPrice: tx.GasPrice().Uint64(),
+			Nonce:    tx.Nonce(),
+			Target:   tx.To(),
+			Data:     tx.Data(),
+		},
+	}
+	// The signature is sent with the recovery id instead of the EIP155 v
+	v, r, s := tx.RawSignatureValues()
+	v = new(big.Int).Sub(v, new(big.Int).Add(new(big.Int).Mul(l1.chainID, big.NewInt(2)), big.NewInt(35)))
+	res.Decoded.Signature.V = uint(v.Uint64())
+	res.Decoded.Signature.R = r.Bytes()
+	res.Decoded.Signature.S = s.Bytes()
+	return res
+}
+
+// transaction returns the transaction at an index of the canonical
+// transaction chain, or of the chain of the sequencer when syncing from L2
+func (l1 *L1) transaction(index uint64, backend string) *transactionResponse {
+	if backend == rollup.BackendL2.String() {
+		if l1.l2 == nil {
+			return &transactionResponse{}
+		}
+		block := l1.l2.GetBlockByNumber(index + 1)
+		if block == nil || len(block.Transactions()) != 1 {
+			return &transactionResponse{}
+		}
+		return &transactionResponse{Transaction: l1.encodeTransaction(index, 0, block.Transactions()[0])}
+	}
+	if index >= uint64(len(l1.elements)) {
+		return &transactionResponse{}
+	}
+	batchIndex := l1.batchIndices[index]
+	return &transactionResponse{
+		Transaction: l1.encodeTransaction(index, batchIndex, l1.elements[index]),
+		Batch:       l1.batches[batchIndex],
+	}
+}
+
+// latestTransaction returns the latest transaction of the canonical
+// transaction chain, or of the chain of the sequencer when syncing from L2
+func (l1 *L1) latestTransaction(backend string) *transactionResponse {
+	var total uint64
+	if backend == rollup.BackendL2.String() {
+		if l1.l2 != nil {
+			total = l1.l2.CurrentBlock().NumberU64()
+		}
+	} else {
+		total = uint64(len(l1.elements))
+	}
+	if total == 0 {
+		return &transactionResponse{}
+	}
+	return l1.transaction(total-1, backend)
+}
+
+// transactionBatch returns a transaction batch with its transactions
+func (l1 *L1) transactionBatch(index uint64) *transactionBatchResponse {
+	if index >= uint64(len(l1.batches)) {
+		return &transactionBatchResponse{Transactions: []*transaction{}}
+	}
+	batch := l1.batches[index]
+	res := &transactionBatchResponse{Batch: batch}
+	for i := uint64(batch.PrevTotalElements); i < uint64(batch.PrevTotalElements+batch.Size); i++ {
+		res.Transactions = append(res.Transactions, l1.encodeTransaction(i, index, l1.elements[i]))
+	}
+	return res
+}
+
+// ServeHTTP serves the HTTP API of the data transport layer. There are no
+// deposits, so the queue is always empty.
+func (l1 *L1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+
+	path := r.URL.Path
+	backend := r.URL.Query().Get("backend")
+	var res interface{}
+	switch {
+	case path == "/eth/syncing":
+		total := uint64(len(l1.elements))
+		if backend == rollup.BackendL2.String() && l1.l2 != nil {
+			total = l1.l2.CurrentBlock().NumberU64()
+		}
+		res = &rollup.SyncStatus{HighestKnownTransactionIndex: total, CurrentTransactionIndex: total}
+	case path == "/eth/context/latest":
+		res = l1.blocks[len(l1.blocks)-1]
+	case strings.HasPrefix(path, "/eth/context/blocknumber/"):
+		number, ok := parseIndex(w, path)
+		if !ok {
+			return
+		}
+		if number < uint64(len(l1.blocks)) {
+			res = l1.blocks[number]
+		}
+	case path == "/eth/gasprice":
+		res = &rollup.L1GasPrice{GasPrice: l1.gasPrice.String()}
+	case path == "/enqueue/latest", strings.HasPrefix(path, "/enqueue/index/"):
+		res = nil
+	case path == "/transaction/latest":
+		res = l1.latestTransaction(backend)
+	case strings.HasPrefix(path, "/transaction/index/"):
+		index, ok := parseIndex(w, path)
+		if !ok {
+			return
+		}
+		res = l1.transaction(index, backend)
+	case path == "/batch/transaction/latest":
+		if len(l1.batches) == 0 {
+			res = &transactionBatchResponse{Transactions: []*transaction{}}
+		} else {
+			res = l1.transactionBatch(uint64(len(l1.batches) - 1))
+		}
+	case strings.HasPrefix(path, "/batch/transaction/index/"):
+		index, ok := parseIndex(w, path)
+		if !ok {
+			return
+		}
+		res = l1.transactionBatch(index)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// parseIndex parses the index at the end of the path of a request
+func parseIndex(w http.ResponseWriter, path string) (uint64, bool) {
+	index, err := strconv.ParseUint(path[strings.LastIndex(path, "/")+1:], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return index, true
+}
+
+// close stops serving the data transport layer
+func (l1 *L1) close() {
+	l1.server.Close()
+}
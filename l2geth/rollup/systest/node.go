@@ -0,0 +1,151 @@
+package systest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Node is an L2 node of the system, either the sequencer or a verifier. It
+// runs the same services as geth does when started with --mine and
+// --eth1.syncservice.
+type Node struct {
+	stack    *node.Node
+	ethereum *eth.Ethereum
+	rpc      *rpc.Client
+	client   *ethclient.Client
+}
+
+// newNode starts an L2 node, signer is the key that signs the blocks that
+// the node mines. The node runs in memory when dataDir is empty.
+func newNode(cfg *eth.Config, signer *ecdsa.PrivateKey, dataDir string) (*Node, error) {
+	stack, err := node.New(&node.Config{
+		DataDir:           dataDir,
+		P2P:               p2p.Config{NoDiscovery: true, MaxPeers: 0},
+		UseLightweightKDF: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cannot create node: %w", err)
+	}
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	// The keystore of a data directory already has the block signer when
+	// the node is restarted
+	account := accounts.Account{Address: crypto.PubkeyToAddress(signer.PublicKey)}
+	if !ks.HasAddress(account.Address) {
+		if account, err = ks.ImportECDSA(signer, ""); err != nil {
+			return nil, fmt.Errorf("Cannot import block signer: %w", err)
+		}
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		return nil, fmt.Errorf("Cannot unlock block signer: %w", err)
+	}
+	cfg.Miner.Etherbase = account.Address
+
+	n := &Node{stack: stack}
+	err = stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		ethereum, err := eth.New(ctx, cfg)
+		n.ethereum = ethereum
+		return ethereum, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cannot register service: %w", err)
+	}
+	if err := stack.Start(); err != nil {
+		return nil, fmt.Errorf("Cannot start node: %w", err)
+	}
+	// Start the miner before the sync service, the sync service blocks
+	// until the transactions that it applies are mined
+	if err := n.ethereum.StartMining(1); err != nil {
+		stack.Stop()
+		return nil, fmt.Errorf("Cannot start mining: %w", err)
+	}
+	for !n.ethereum.IsMining() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := n.ethereum.SyncService().Start(); err != nil {
+		stack.Stop()
+		return nil, fmt.Errorf("Cannot start sync service: %w", err)
+	}
+	n.rpc, err = stack.Attach()
+	if err != nil {
+		stack.Stop()
+		return nil, fmt.Errorf("Cannot attach to node: %w", err)
+	}
+	n.client = ethclient.NewClient(n.rpc)
+	return n, nil
+}
+
+// Ethereum returns the service of the node
+func (n *Node) Ethereum() *eth.Ethereum { return n.ethereum }
+
+// BlockChain returns the chain of the node
+func (n *Node) BlockChain() *core.BlockChain { return n.ethereum.BlockChain() }
+
+// SyncService returns the sync service of the node
+func (n *Node) SyncService() *rollup.SyncService { return n.ethereum.SyncService() }
+
+// RPC returns an in-process RPC client of the node
+func (n *Node) RPC() *rpc.Client { return n.rpc }
+
+// Client returns an in-process client of the node
+func (n *Node) Client() *ethclient.Client { return n.client }
+
+// SendTransaction submits a signed transaction to the sequencer the same way
+// as eth_sendRawTransaction does when running the OVM. The fee of the
+// transaction is checked, and it returns once the transaction is mined.
+func (n *Node) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+	tx = new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return err
+	}
+	// L1Timestamp and L1BlockNumber will be set right before execution
+	meta := types.NewTransactionMeta(nil, 0, nil, types.QueueOriginSequencer, nil, nil, raw)
+	tx.SetTransactionMeta(meta)
+	return n.SyncService().ValidateAndApplySequencerTransaction(ctx, tx)
+}
+
+// WaitForIndex blocks until the transaction at an index of the canonical
+// transaction chain is part of the chain of the node
+func (n *Node) WaitForIndex(ctx context.Context, index uint64) error {
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := n.BlockChain().SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	// The transaction at index i is the transaction of block i+1
+	for n.BlockChain().CurrentBlock().NumberU64() <= index {
+		select {
+		case <-heads:
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return fmt.Errorf("Cannot find index %d at block %d: %w", index,
+				n.BlockChain().CurrentBlock().NumberU64(), ctx.Err())
+		}
+	}
+	return nil
+}
+
+// close stops the node
+func (n *Node) close() error {
+	if n.rpc != nil {
+		n.rpc.Close()
+	}
+	return n.stack.Stop()
+}
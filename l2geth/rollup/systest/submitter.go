@@ -0,0 +1,151 @@
+package systest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// BatchSubmitterConfig configures the batch submitter
+type BatchSubmitterConfig struct {
+	// MaxBatchSize is the maximum number of transactions of a batch
+	MaxBatchSize int
+	// Interval at which the transactions of the sequencer are submitted,
+	// they are only submitted by calling Submit when it is zero
+	Interval time.Duration
+	// Address is the submitter of the batches
+	Address common.Address
+}
+
+// BatchSubmitter appends the transactions of the sequencer to the canonical
+// transaction chain
+type BatchSubmitter struct {
+	cfg BatchSubmitterConfig
+	l1  *L1
+	l2  *core.BlockChain
+	mu  sync.Mutex
+}
+
+// Submit appends the transactions of the sequencer that are not batch
+// submitted yet to the canonical transaction chain and returns the number
+// of batches that it submitted
+func (b *BatchSubmitter) Submit() (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	next := b.l1.TotalElements()
+	head := b.l2.CurrentBlock().NumberU64()
+	count := 0
+	for next < head {
+		end := next + uint64(b.cfg.MaxBatchSize)
+		if end > head {
+			end = head
+		}
+		txs := make([]*types.Transaction, 0, end-next)
+		for i := next; i < end; i++ {
+			// The transaction at index i is the transaction of block i+1
+			block := b.l2.GetBlockByNumber(i + 1)
+			if block == nil || len(block.Transactions()) != 1 {
+				return count, fmt.Errorf("Cannot find transaction %d", i)
+			}
+			txs = append(txs, block.Transactions()[0])
+		}
+		batch := b.l1.appendBatch(txs, b.cfg.Address)
+		log.Debug("Submitted transaction batch", "index", batch.Index, "size", batch.Size)
+		next = end
+		count++
+	}
+	return count, nil
+}
+
+// setL2 sets the chain of the sequencer
+func (b *BatchSubmitter) setL2(chain *core.BlockChain) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.l2 = chain
+}
+
+// ProposerConfig configures the proposer
+type ProposerConfig struct {
+	// MaxBatchSize is the maximum number of state roots of a batch
+	MaxBatchSize int
+	// Interval at which the state roots of the sequencer are proposed, they
+	// are only proposed by calling Propose when it is zero
+	Interval time.Duration
+}
+
+// Proposer appends the state roots of the sequencer to the state commitment
+// chain. It only proposes the state roots of transactions that are batch
+// submitted.
+type Proposer struct {
+	cfg ProposerConfig
+	l1  *L1
+	l2  *core.BlockChain
+	mu  sync.Mutex
+}
+
+// Propose appends the state roots of the transactions of the canonical
+// transaction chain that are not proposed yet to the state commitment chain
+// and returns the number of batches that it proposed
+func (p *Proposer) Propose() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next := uint64(len(p.l1.StateRoots()))
+	total := p.l1.TotalElements()
+	count := 0
+	for next < total {
+		end := next + uint64(p.cfg.MaxBatchSize)
+		if end > total {
+			end = total
+		}
+		roots := make([]common.Hash, 0, end-next)
+		for i := next; i < end; i++ {
+			block := p.l2.GetBlockByNumber(i + 1)
+			if block == nil {
+				return count, fmt.Errorf("Cannot find state root %d", i)
+			}
+			roots = append(roots, block.Root())
+		}
+		p.l1.appendStateBatch(roots)
+		log.Debug("Proposed state batch", "start", next, "size", len(roots))
+		next = end
+		count++
+	}
+	return count, nil
+}
+
+// setL2 sets the chain of the sequencer
+func (p *Proposer) setL2(chain *core.BlockChain) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.l2 = chain
+}
+
+// poll calls fn at an interval until quit is closed
+func poll(interval time.Duration, quit chan struct{}, wg *sync.WaitGroup, name string, fn func() (int, error)) {
+	if interval == 0 {
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if _, err := fn(); err != nil {
+					log.Error("Cannot "+name, "msg", err)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,279 @@
+// Package systest wires up an L1 chain, a sequencer, a batch submitter, a
+// proposer and verifiers inside of a single process, so that the fee,
+// batching and sync behavior of the system can be tested end to end without
+// docker.
+//
+// The sequencer and the verifiers are full L2 nodes with a miner and a sync
+// service. The L1 chain is modeled as it is seen through the data transport
+// layer, which is served to the L2 nodes over HTTP. The nodes execute with
+// the EVM instead of the OVM, because the OVM requires the system contracts
+// of a state dump, so there are no deposits.
+package systest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/rcfg"
+)
+
+// gasPriceOracleCode stands in for the OVM_GasPriceOracle. It stores the
+// first word of the calldata as the L2 gas price:
+// PUSH1 0 CALLDATALOAD PUSH1 1 SSTORE STOP
+var gasPriceOracleCode = common.FromHex("0x60003560015500")
+
+// Config configures the system. The hooks are called with the configuration
+// of each component before it is started.
+type Config struct {
+	// ChainID is the chain id of the L2 chain
+	ChainID *big.Int
+	// GasLimit is the block gas limit of the L2 chain, it must fit the
+	// gas limits that encode the fees of the transactions
+	GasLimit uint64
+	// Alloc are the accounts of the L2 genesis
+	Alloc core.GenesisAlloc
+	// L2GasPrice is the initial L2 gas price of the OVM_GasPriceOracle
+	L2GasPrice *big.Int
+	// PollInterval is the poll interval of the sync services
+	PollInterval time.Duration
+	// Verifiers is the number of verifiers
+	Verifiers int
+	// SequencerDataDir is the data directory of the sequencer, which keeps
+	// its chain across RestartSequencer. The sequencer runs in memory when
+	// it is empty.
+	SequencerDataDir string
+
+	// Hooks that adjust the configuration of each component
+	L1             func(*L1Config)
+	Sequencer      func(*eth.Config)
+	Verifier       func(index int, cfg *eth.Config)
+	BatchSubmitter func(*BatchSubmitterConfig)
+	Proposer       func(*ProposerConfig)
+}
+
+// System is a running system
+type System struct {
+	L1             *L1
+	Sequencer      *Node
+	Verifiers      []*Node
+	BatchSubmitter *BatchSubmitter
+	Proposer       *Proposer
+	// GasPriceOracleOwner is the owner of the OVM_GasPriceOracle
+	GasPriceOracleOwner *ecdsa.PrivateKey
+
+	chainID *big.Int
+	quit    chan struct{}
+	wg      sync.WaitGroup
+
+	// The configuration that the sequencer is restarted with
+	sequencerCfg     *eth.Config
+	sequencerDataDir string
+	signer           *ecdsa.PrivateKey
+}
+
+// New starts a system. It must be closed when it is not used anymore.
+func New(cfg Config) (*System, error) {
+	if cfg.ChainID == nil {
+		cfg.ChainID = big.NewInt(420)
+	}
+	if cfg.GasLimit == 0 {
+		cfg.GasLimit = 10_000_000_000
+	}
+	if cfg.L2GasPrice == nil {
+		cfg.L2GasPrice = big.NewInt(params.GWei)
+	}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 10 * time.Millisecond
+	}
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	signer, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	sys := &System{
+		GasPriceOracleOwner: owner,
+		chainID:             cfg.ChainID,
+		quit:                make(chan struct{}),
+		sequencerDataDir:    cfg.SequencerDataDir,
+		signer:              signer,
+	}
+
+	l1Cfg := L1Config{GasPrice: big.NewInt(params.GWei), BlockTime: 12}
+	if cfg.L1 != nil {
+		cfg.L1(&l1Cfg)
+	}
+	sys.L1 = newL1(l1Cfg, cfg.ChainID)
+
+	genesis := newGenesis(&cfg, crypto.PubkeyToAddress(owner.PublicKey), crypto.PubkeyToAddress(signer.PublicKey))
+	seqCfg := sys.ethConfig(&cfg, genesis, false)
+	if cfg.Sequencer != nil {
+		cfg.Sequencer(seqCfg)
+	}
+	sys.sequencerCfg = seqCfg
+	sys.Sequencer, err = newNode(seqCfg, signer, cfg.SequencerDataDir)
+	if err != nil {
+		sys.Close()
+		return nil, fmt.Errorf("Cannot start sequencer: %w", err)
+	}
+	sys.L1.setL2(sys.Sequencer.BlockChain())
+
+	for i := 0; i < cfg.Verifiers; i++ {
+		verifierCfg := sys.ethConfig(&cfg, genesis, true)
+		if cfg.Verifier != nil {
+			cfg.Verifier(i, verifierCfg)
+		}
+		verifier, err := newNode(verifierCfg, signer, "")
+		if err != nil {
+			sys.Close()
+			return nil, fmt.Errorf("Cannot start verifier %d: %w", i, err)
+		}
+		sys.Verifiers = append(sys.Verifiers, verifier)
+	}
+
+	submitterCfg := BatchSubmitterConfig{MaxBatchSize: 100}
+	if cfg.BatchSubmitter != nil {
+		cfg.BatchSubmitter(&submitterCfg)
+	}
+	sys.BatchSubmitter = &BatchSubmitter{cfg: submitterCfg, l1: sys.L1, l2: sys.Sequencer.BlockChain()}
+	poll(submitterCfg.Interval, sys.quit, &sys.wg, "submit batches", sys.BatchSubmitter.Submit)
+
+	proposerCfg := ProposerConfig{MaxBatchSize: 100}
+	if cfg.Proposer != nil {
+		cfg.Proposer(&proposerCfg)
+	}
+	sys.Proposer = &Proposer{cfg: proposerCfg, l1: sys.L1, l2: sys.Sequencer.BlockChain()}
+	poll(proposerCfg.Interval, sys.quit, &sys.wg, "propose state roots", sys.Proposer.Propose)
+	return sys, nil
+}
+
+// newGenesis returns the genesis of the L2 chain, its blocks are signed by
+// signer
+func newGenesis(cfg *Config, owner, signer common.Address) *core.Genesis {
+	chainCfg := *params.AllCliqueProtocolChanges
+	chainCfg.ChainID = cfg.ChainID
+	chainCfg.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000}
+	// The nodes record the L1 fee in the receipts from genesis
+	chainCfg.RollupForks = []params.RollupFork{{
+		Name:     "genesis",
+		L1Block:  new(big.Int),
+		Features: []params.RollupFeature{params.RollupFeatureL1FeeReceipts},
+	}}
+
+	alloc := core.GenesisAlloc{
+		rcfg.L2GasPriceOracleAddress: {
+			Code:    gasPriceOracleCode,
+			Balance: new(big.Int),
+			Storage: map[common.Hash]common.Hash{
+				rcfg.L2GasPriceOracleOwnerSlot: owner.Hash(),
+				rcfg.L2GasPriceSlot:            common.BigToHash(cfg.L2GasPrice),
+			},
+		},
+	}
+	for addr, account := range cfg.Alloc {
+		alloc[addr] = account
+	}
+	extra := make([]byte, 32+common.AddressLength+crypto.SignatureLength)
+	copy(extra[32:], signer.Bytes())
+	return &core.Genesis{
+		Config:    &chainCfg,
+		GasLimit:  cfg.GasLimit,
+		ExtraData: extra,
+		Alloc:     alloc,
+	}
+}
+
+// ethConfig returns the configuration of an L2 node
+func (s *System) ethConfig(cfg *Config, genesis *core.Genesis, verifier bool) *eth.Config {
+	ethCfg := eth.DefaultConfig
+	ethCfg.Genesis = genesis
+	ethCfg.NetworkId = cfg.ChainID.Uint64()
+	ethCfg.SyncMode = downloader.FullSync
+	ethCfg.Miner.GasFloor = cfg.GasLimit
+	ethCfg.Miner.GasCeil = cfg.GasLimit
+	ethCfg.Miner.GasPrice = new(big.Int)
+	ethCfg.Rollup = rollup.Config{
+		Eth1SyncServiceEnable:                 true,
+		IsVerifier:                            verifier,
+		RollupClientHttp:                      s.L1.URL(),
+		CanonicalTransactionChainDeployHeight: new(big.Int),
+		GasLimit:                              cfg.GasLimit,
+		MaxCallDataSize:                       eth.DefaultConfig.Rollup.MaxCallDataSize,
+		PollInterval:                          cfg.PollInterval,
+		TimestampRefreshThreshold:             time.Second,
+		Backend:                               rollup.BackendL1,
+	}
+	return &ethCfg
+}
+
+// SetL2GasPrice sets the L2 gas price of the OVM_GasPriceOracle with a
+// transaction of its owner
+func (s *System) SetL2GasPrice(ctx context.Context, price *big.Int) error {
+	owner := crypto.PubkeyToAddress(s.GasPriceOracleOwner.PublicKey)
+	nonce, err := s.Sequencer.Client().NonceAt(ctx, owner, nil)
+	if err != nil {
+		return err
+	}
+	tx := types.NewTransaction(nonce, rcfg.L2GasPriceOracleAddress, new(big.Int), 100_000, new(big.Int), common.BigToHash(price).Bytes())
+	tx, err = types.SignTx(tx, types.NewEIP155Signer(s.chainID), s.GasPriceOracleOwner)
+	if err != nil {
+		return err
+	}
+	return s.Sequencer.SendTransaction(ctx, tx)
+}
+
+// RestartSequencer stops the sequencer and starts it again with the same
+// configuration, the way that geth is restarted
+func (s *System) RestartSequencer() error {
+	if err := s.Sequencer.close(); err != nil {
+		return fmt.Errorf("Cannot stop sequencer: %w", err)
+	}
+	sequencer, err := newNode(s.sequencerCfg, s.signer, s.sequencerDataDir)
+	if err != nil {
+		s.Sequencer = nil
+		return fmt.Errorf("Cannot restart sequencer: %w", err)
+	}
+	s.Sequencer = sequencer
+	s.L1.setL2(sequencer.BlockChain())
+	s.BatchSubmitter.setL2(sequencer.BlockChain())
+	s.Proposer.setL2(sequencer.BlockChain())
+	return nil
+}
+
+// Close stops all of the components of the system
+func (s *System) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	// Stop serving the data transport layer first so that the sync services
+	// stop applying transactions
+	s.L1.close()
+	var errs []error
+	for _, verifier := range s.Verifiers {
+		if err := verifier.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.Sequencer != nil {
+		if err := s.Sequencer.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("Cannot close system: %v", errs)
+	}
+	return nil
+}
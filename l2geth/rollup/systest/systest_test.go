@@ -0,0 +1,242 @@
+package systest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rollup"
+	"github.com/ethereum/go-ethereum/rollup/fees"
+)
+
+// TestSystem sends transactions to the sequencer, batch submits them and
+// proposes their state roots, and checks that the verifiers derive the same
+// chain from L1 and from L2
+func TestSystem(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		from   = crypto.PubkeyToAddress(key.PublicKey)
+		count  = 5
+	)
+	sys, err := New(Config{
+		Alloc: core.GenesisAlloc{
+			from: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))},
+		},
+		Verifiers: 2,
+		Sequencer: func(cfg *eth.Config) {
+			cfg.Rollup.EnforceFees = true
+			cfg.Rollup.FeeThresholdUp = big.NewFloat(3)
+		},
+		Verifier: func(index int, cfg *eth.Config) {
+			// The second verifier follows the sequencer before the
+			// transactions are batch submitted
+			if index == 1 {
+				cfg.Rollup.Backend = rollup.BackendL2
+			}
+		},
+		BatchSubmitter: func(cfg *BatchSubmitterConfig) {
+			cfg.MaxBatchSize = 2
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sys.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	signer := types.NewEIP155Signer(sys.chainID)
+	data := []byte{0x00, 0x01, 0x02, 0x03}
+	l2GasUsed := params.TxGas + params.TxDataZeroGas + 3*params.TxDataNonZeroGasEIP2028
+	gasLimit := fees.EncodeTxGasLimit(data, sys.L1.GasPrice(), new(big.Int).SetUint64(l2GasUsed), big.NewInt(params.GWei)).Uint64()
+	newTx := func(nonce, gasLimit uint64) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{1}, big.NewInt(1), gasLimit, fees.BigTxGasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	for i := 0; i < count; i++ {
+		if err := sys.Sequencer.SendTransaction(ctx, newTx(uint64(i), gasLimit)); err != nil {
+			t.Fatalf("transaction %d rejected: %v", i, err)
+		}
+	}
+	err = sys.Sequencer.SendTransaction(ctx, newTx(uint64(count), gasLimit/2))
+	if !errors.Is(err, fees.ErrFeeTooLow) {
+		t.Fatalf("mismatched underpaying transaction error: got %v, expect %v", err, fees.ErrFeeTooLow)
+	}
+	last := uint64(count - 1)
+
+	if err := sys.Verifiers[1].WaitForIndex(ctx, last); err != nil {
+		t.Fatal(err)
+	}
+	if total := sys.L1.TotalElements(); total != 0 {
+		t.Fatalf("transactions batch submitted early: %d", total)
+	}
+
+	batches, err := sys.BatchSubmitter.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batches != 3 {
+		t.Fatalf("mismatched batches: got %d, expect 3", batches)
+	}
+	if _, err := sys.Proposer.Propose(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Verifiers[0].WaitForIndex(ctx, last); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := sys.L1.StateRoots()
+	if len(roots) != count {
+		t.Fatalf("mismatched state roots: got %d, expect %d", len(roots), count)
+	}
+	for i, verifier := range sys.Verifiers {
+		for index, root := range roots {
+			block := verifier.BlockChain().GetBlockByNumber(uint64(index) + 1)
+			if block.Root() != root {
+				t.Fatalf("verifier %d: mismatched state root %d: got %s, expect %s", i, index, block.Root().Hex(), root.Hex())
+			}
+			if expect := sys.Sequencer.BlockChain().GetBlockByNumber(uint64(index) + 1).Hash(); block.Hash() != expect {
+				t.Fatalf("verifier %d: mismatched block %d: got %s, expect %s", i, index+1, block.Hash().Hex(), expect.Hex())
+			}
+		}
+	}
+
+	// Every node records the same L1 fee in the receipts
+	hash := sys.Sequencer.BlockChain().GetBlockByNumber(1).Transactions()[0].Hash()
+	expect, err := sys.Sequencer.Client().TransactionReceipt(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect.L1Fee == nil || expect.L1Fee.Sign() == 0 {
+		t.Fatalf("missing L1 fee in receipt: %v", expect.L1Fee)
+	}
+	for i, verifier := range sys.Verifiers {
+		receipt, err := verifier.Client().TransactionReceipt(ctx, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.L1Fee == nil || receipt.L1Fee.Cmp(expect.L1Fee) != 0 {
+			t.Fatalf("verifier %d: mismatched L1 fee: got %v, expect %v", i, receipt.L1Fee, expect.L1Fee)
+		}
+	}
+}
+
+// TestSystemL2GasPrice checks that the sequencer charges the L2 gas price
+// that the owner of the OVM_GasPriceOracle sets
+func TestSystemL2GasPrice(t *testing.T) {
+	sys, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sys.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	price := big.NewInt(10 * params.GWei)
+	if err := sys.SetL2GasPrice(ctx, price); err != nil {
+		t.Fatal(err)
+	}
+	gpo := sys.Sequencer.SyncService().RollupGpo
+	for {
+		current, err := gpo.SuggestL2GasPrice(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.Cmp(price) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("mismatched L2 gas price: got %d, expect %d", current, price)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// TestSystemPersistedTxPool checks that the transactions of the pool of the
+// sequencer, which it does not build blocks from, are sequenced after a
+// restart, and that the ones that do not pay enough anymore are dropped
+func TestSystemPersistedTxPool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "systest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		key, _ = crypto.GenerateKey()
+		from   = crypto.PubkeyToAddress(key.PublicKey)
+	)
+	sys, err := New(Config{
+		Alloc: core.GenesisAlloc{
+			from: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))},
+		},
+		SequencerDataDir: dir,
+		Sequencer: func(cfg *eth.Config) {
+			cfg.Rollup.EnforceFees = true
+			cfg.TxPool.Persist = "txpool.rlp"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sys.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	signer := types.NewEIP155Signer(sys.chainID)
+	data := []byte{0x00, 0x01, 0x02, 0x03}
+	l2GasUsed := params.TxGas + params.TxDataZeroGas + 3*params.TxDataNonZeroGasEIP2028
+	gasLimit := fees.EncodeTxGasLimit(data, sys.L1.GasPrice(), new(big.Int).SetUint64(l2GasUsed), big.NewInt(params.GWei)).Uint64()
+	newTx := func(nonce, gasLimit uint64) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{1}, big.NewInt(1), gasLimit, fees.BigTxGasPrice, data)
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	// The nodes run the EVM, so the miner stops mining to not build blocks
+	// from the pool either
+	sys.Sequencer.Ethereum().StopMining()
+	paying, underpaying := newTx(0, gasLimit), newTx(1, gasLimit/2)
+	for _, err := range sys.Sequencer.Ethereum().TxPool().AddRemotesSync([]*types.Transaction{paying, underpaying}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if head := sys.Sequencer.BlockChain().CurrentBlock().NumberU64(); head != 0 {
+		t.Fatalf("pool transactions sequenced before restart: head %d", head)
+	}
+
+	if err := sys.RestartSequencer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Sequencer.WaitForIndex(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if receipt, err := sys.Sequencer.Client().TransactionReceipt(ctx, paying.Hash()); err != nil || receipt.BlockNumber.Uint64() != 1 {
+		t.Fatalf("persisted transaction not sequenced: %v", err)
+	}
+	if head := sys.Sequencer.BlockChain().CurrentBlock().NumberU64(); head != 1 {
+		t.Fatalf("underpaying persisted transaction sequenced: head %d", head)
+	}
+	if pending, queued := sys.Sequencer.Ethereum().TxPool().Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("persisted transactions admitted to the pool: %d/%d", pending, queued)
+	}
+}
@@ -0,0 +1,230 @@
+package rollup
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// The lanes that the work of the sequencer waits in to be applied to the tip
+// of the chain. When the tip is free, the longest waiting work of the first
+// lane that is not empty is applied next.
+const (
+	// laneQueue is the lane of the L1 to L2 transactions, which are always
+	// applied before the transactions that are sent to the sequencer
+	laneQueue = iota
+	// laneExpress is the lane of the transactions that pay a premium over
+	// the expected fee
+	laneExpress
+	// laneDefault is the lane of all other transactions
+	laneDefault
+	numLanes
+)
+
+// defaultExpressLaneCapacity is the number of transactions that can wait in
+// the express lane when it is not configured
+const defaultExpressLaneCapacity = 64
+
+var laneNames = [numLanes]string{"queue", "express", "default"}
+
+// The multiplier of the express lane is reported in percent to fit in the
+// int64 gauge
+var (
+	expressLaneMultiplierGauge = metrics.NewRegisteredGauge("rollup/lane/express/multiplier", nil)
+	expressLaneCapacityGauge   = metrics.NewRegisteredGauge("rollup/lane/express/capacity", nil)
+	expressLaneAdmitMeter      = metrics.NewRegisteredMeter("rollup/lane/express/admit", nil)
+	expressLaneDemoteMeter     = metrics.NewRegisteredMeter("rollup/lane/express/demote", nil)
+	laneWaitingGauges          [numLanes]metrics.Gauge
+	laneWaitTimers             [numLanes]metrics.Timer
+)
+
+func init() {
+	for lane, name := range laneNames {
+		laneWaitingGauges[lane] = metrics.NewRegisteredGauge("rollup/lane/"+name+"/waiting", nil)
+		laneWaitTimers[lane] = metrics.NewRegisteredTimer("rollup/lane/"+name+"/wait", nil)
+	}
+}
+
+// txLanes serializes the application of transactions to the tip of the chain
+// like a mutex, but hands the tip to the waiters in the order of their lanes
+// rather than at random. The express lane is disabled when the multiplier is
+// nil.
+type txLanes struct {
+	lock    sync.Mutex
+	held    bool
+	waiters [numLanes][]*laneWaiter
+	// granted is when the tip was last handed over, and holdTime is the
+	// moving average of the time that the tip is held for
+	granted  time.Time
+	holdTime time.Duration
+
+	// multiplier is the premium over the expected fee that a transaction
+	// must pay to wait in the express lane
+	multiplier *big.Float
+	// capacity is the number of transactions that can wait in the express
+	// lane, further transactions wait in the default lane
+	capacity int
+	// fcfs is set when transactions are applied strictly in the order in
+	// which they arrived, regardless of their fee
+	fcfs bool
+}
+
+// laneOf returns the lane of a sequencer transaction whose fee has been
+// verified
+func (l *txLanes) laneOf(decision *feeDecision) int {
+	if l.multiplier == nil || decision.decision != feeDecisionAccept {
+		return laneDefault
+	}
+	if decision.userFee == nil || decision.expectedFee == nil || decision.expectedFee.Sign() == 0 {
+		return laneDefault
+	}
+	premium := new(big.Float).SetInt(decision.expectedFee)
+	premium.Mul(premium, l.multiplier)
+	if new(big.Float).SetInt(decision.userFee).Cmp(premium) < 0 {
+		return laneDefault
+	}
+	return laneExpress
+}
+
+// laneWaiter is a place in line for the tip of the chain
+type laneWaiter struct {
+	lane    int
+	joined  time.Time
+	ready   bool
+	granted bool
+	turn    chan struct{}
+}
+
+// arrive returns the place in line of a transaction that has just been sent
+// to the sequencer when transactions are applied first come first served,
+// nil otherwise. The transaction must wait for its turn or leave the line.
+func (l *txLanes) arrive() *laneWaiter {
+	if !l.fcfs {
+		return nil
+	}
+	return l.join(laneDefault)
+}
+
+// join returns a place at the end of the lane. Express transactions beyond
+// the capacity of the express lane join the default lane.
+func (l *txLanes) join(lane int) *laneWaiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if lane == laneExpress {
+		if len(l.waiters[laneExpress]) >= l.capacity {
+			expressLaneDemoteMeter.Mark(1)
+			lane = laneDefault
+		} else {
+			expressLaneAdmitMeter.Mark(1)
+		}
+	}
+	w := &laneWaiter{lane: lane, joined: time.Now(), turn: make(chan struct{})}
+	l.waiters[lane] = append(l.waiters[lane], w)
+	laneWaitingGauges[lane].Update(int64(len(l.waiters[lane])))
+	return w
+}
+
+// wait blocks until the tip of the chain is free for the waiter and its turn
+// has come in the lane. The waiter must release the tip once it is done.
+func (l *txLanes) wait(w *laneWaiter) {
+	l.lock.Lock()
+	w.ready = true
+	l.dispatch()
+	l.lock.Unlock()
+
+	<-w.turn
+	laneWaitTimers[w.lane].UpdateSince(w.joined)
+}
+
+// leave gives up the place in line of a waiter that did not wait for its
+// turn, it is a noop for waiters that were given the tip
+func (l *txLanes) leave(w *laneWaiter) {
+	if w == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if w.granted {
+		return
+	}
+	for i, waiter := range l.waiters[w.lane] {
+		if waiter == w {
+			l.waiters[w.lane] = append(l.waiters[w.lane][:i], l.waiters[w.lane][i+1:]...)
+			laneWaitingGauges[w.lane].Update(int64(len(l.waiters[w.lane])))
+			break
+		}
+	}
+	l.dispatch()
+}
+
+// acquire blocks until the tip of the chain is free for the caller and its
+// turn has come in the lane
+func (l *txLanes) acquire(lane int) {
+	l.wait(l.join(lane))
+}
+
+// release hands the tip of the chain to the next waiter, or frees it when
+// nothing is waiting
+func (l *txLanes) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if hold := time.Since(l.granted); l.holdTime == 0 {
+		l.holdTime = hold
+	} else {
+		l.holdTime += (hold - l.holdTime) / 8
+	}
+	l.held = false
+	l.dispatch()
+}
+
+// stats returns the number of waiters in each lane, whether the tip of the
+// chain is held and the moving average of the time that it is held for
+func (l *txLanes) stats() ([numLanes]int, bool, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var waiting [numLanes]int
+	for lane := range l.waiters {
+		waiting[lane] = len(l.waiters[lane])
+	}
+	return waiting, l.held, l.holdTime
+}
+
+// busy returns whether the tip of the chain is held or waited for
+func (l *txLanes) busy() bool {
+	waiting, held, _ := l.stats()
+	for _, n := range waiting {
+		if n != 0 {
+			return true
+		}
+	}
+	return held
+}
+
+// dispatch hands the free tip of the chain to the first waiter of the first
+// lane that is not empty. A waiter that is not ready yet holds up the others
+// in its lane, so that transactions are applied in the order in which they
+// joined the lane. It must be called holding the lock.
+func (l *txLanes) dispatch() {
+	if l.held {
+		return
+	}
+	for lane := range l.waiters {
+		if len(l.waiters[lane]) == 0 || !l.waiters[lane][0].ready {
+			continue
+		}
+		w := l.waiters[lane][0]
+		l.waiters[lane][0] = nil
+		l.waiters[lane] = l.waiters[lane][1:]
+		laneWaitingGauges[lane].Update(int64(len(l.waiters[lane])))
+		l.held, w.granted = true, true
+		l.granted = time.Now()
+		close(w.turn)
+		return
+	}
+}
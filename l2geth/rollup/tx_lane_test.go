@@ -0,0 +1,135 @@
+package rollup
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTxLanesOrder(t *testing.T) {
+	lanes := &txLanes{multiplier: big.NewFloat(2), capacity: 2}
+	lanes.acquire(laneDefault)
+
+	// Wait in the lanes in the reverse of the order they are served
+	waiting := []int{laneDefault, laneDefault, laneExpress, laneExpress, laneExpress, laneQueue}
+	order := make(chan int, len(waiting))
+	for i, lane := range waiting {
+		go func(i, lane int) {
+			lanes.acquire(lane)
+			order <- i
+			lanes.release()
+		}(i, lane)
+		// Ensure that the waiter has joined its lane before the next one
+		for {
+			lanes.lock.Lock()
+			n := 0
+			for _, w := range lanes.waiters {
+				n += len(w)
+			}
+			lanes.lock.Unlock()
+			if n == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	lanes.release()
+
+	// The third express transaction is beyond the capacity of the express
+	// lane and waits behind the default transactions
+	expect := []int{5, 2, 3, 0, 1, 4}
+	for _, want := range expect {
+		if got := <-order; got != want {
+			t.Fatalf("mismatched order: got %d, expect %d", got, want)
+		}
+	}
+	// The tip is free once the last waiter has released it
+	lanes.acquire(laneDefault)
+	lanes.release()
+}
+
+func TestTxLanesFCFS(t *testing.T) {
+	lanes := &txLanes{fcfs: true}
+	first, second, third := lanes.arrive(), lanes.arrive(), lanes.arrive()
+
+	// The second transaction is ready first but waits for the first one
+	order := make(chan int, 2)
+	go func() {
+		lanes.wait(second)
+		order <- 2
+		lanes.release()
+	}()
+	for {
+		lanes.lock.Lock()
+		ready := second.ready
+		lanes.lock.Unlock()
+		if ready {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-order:
+		t.Fatal("transaction applied before an earlier arrival")
+	case <-time.After(10 * time.Millisecond):
+	}
+	// The third transaction is rejected and leaves the line
+	lanes.leave(third)
+	lanes.wait(first)
+	order <- 1
+	lanes.release()
+
+	for _, want := range []int{1, 2} {
+		if got := <-order; got != want {
+			t.Fatalf("mismatched order: got %d, expect %d", got, want)
+		}
+	}
+	lanes.acquire(laneDefault)
+	lanes.release()
+}
+
+func TestTxLanesLaneOf(t *testing.T) {
+	tests := map[string]struct {
+		multiplier  *big.Float
+		decision    string
+		userFee     *big.Int
+		expectedFee *big.Int
+		lane        int
+	}{
+		"disabled": {
+			multiplier:  nil,
+			decision:    feeDecisionAccept,
+			userFee:     big.NewInt(300),
+			expectedFee: big.NewInt(100),
+			lane:        laneDefault,
+		},
+		"premium": {
+			multiplier:  big.NewFloat(1.5),
+			decision:    feeDecisionAccept,
+			userFee:     big.NewInt(150),
+			expectedFee: big.NewInt(100),
+			lane:        laneExpress,
+		},
+		"below-premium": {
+			multiplier:  big.NewFloat(1.5),
+			decision:    feeDecisionAccept,
+			userFee:     big.NewInt(149),
+			expectedFee: big.NewInt(100),
+			lane:        laneDefault,
+		},
+		"gpo-owner": {
+			multiplier: big.NewFloat(1.5),
+			decision:   feeDecisionOwner,
+			lane:       laneDefault,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			lanes := &txLanes{multiplier: tt.multiplier}
+			decision := &feeDecision{decision: tt.decision, userFee: tt.userFee, expectedFee: tt.expectedFee}
+			if lane := lanes.laneOf(decision); lane != tt.lane {
+				t.Fatalf("mismatched lane: got %d, expect %d", lane, tt.lane)
+			}
+		})
+	}
+}
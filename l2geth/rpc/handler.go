@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/tracing"
 )
 
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
@@ -366,7 +367,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	ctx, span := tracing.StartSpan(ctx, "rpc."+msg.Method)
 	result, err := callb.call(ctx, msg.Method, args)
+	span.Finish(err)
 	if err != nil {
 		return msg.errorResponse(err)
 	}
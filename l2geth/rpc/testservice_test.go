@@ -63,6 +63,12 @@ type echoResult struct {
 	Args   *echoArgs
 }
 
+type testError struct{}
+
+func (testError) Error() string          { return "testError" }
+func (testError) ErrorCode() int         { return 444 }
+func (testError) ErrorData() interface{} { return "testError data" }
+
 func (s *testService) NoArgsRets() {}
 
 func (s *testService) Echo(str string, i int, args *echoArgs) echoResult {
@@ -82,6 +88,10 @@ func (s *testService) Rets() (string, error) {
 }
 
 //lint:ignore ST1008 returns error first on purpose.
+func (s *testService) ReturnError() error {
+	return testError{}
+}
+
 func (s *testService) InvalidRets1() (error, string) {
 	return nil, ""
 }
@@ -0,0 +1,252 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	otlpBatchSize     = 512
+	otlpQueueSize     = 4096
+	otlpFlushInterval = 5 * time.Second
+	otlpTimeout       = 10 * time.Second
+
+	otlpSpanKindInternal = 1
+	otlpStatusCodeError  = 2
+)
+
+var (
+	otlpExportedMeter = metrics.NewRegisteredMeter("tracing/otlp/exported", nil)
+	otlpDroppedMeter  = metrics.NewRegisteredMeter("tracing/otlp/dropped", nil)
+	otlpFailedMeter   = metrics.NewRegisteredMeter("tracing/otlp/failed", nil)
+)
+
+// OTLPExporter batches finished spans and sends them to an OpenTelemetry
+// collector using the OTLP/HTTP JSON encoding. Spans are dropped rather
+// than blocking the traced code path when the collector falls behind.
+type OTLPExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+	queue    chan *Span
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+// NewOTLPExporter creates an OTLPExporter that posts spans to the given
+// endpoint, usually http://localhost:4318/v1/traces, and starts its
+// background flush loop
+func NewOTLPExporter(endpoint, service string) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint: endpoint,
+		service:  service,
+		client:   &http.Client{Timeout: otlpTimeout},
+		queue:    make(chan *Span, otlpQueueSize),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+// Export implements the Exporter interface
+func (e *OTLPExporter) Export(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		otlpDroppedMeter.Mark(1)
+	}
+}
+
+// Stop flushes the queued spans and stops the exporter
+func (e *OTLPExporter) Stop() {
+	close(e.quit)
+	<-e.done
+}
+
+func (e *OTLPExporter) loop() {
+	defer close(e.done)
+	t := time.NewTicker(otlpFlushInterval)
+	defer t.Stop()
+
+	batch := make([]*Span, 0, otlpBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			log.Warn("Cannot export spans", "endpoint", e.endpoint, "count", len(batch), "err", err)
+			otlpFailedMeter.Mark(int64(len(batch)))
+		} else {
+			otlpExportedMeter.Mark(int64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == otlpBatchSize {
+				flush()
+			}
+		case <-t.C:
+			flush()
+		case <-e.quit:
+			for {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []*Span) error {
+	body, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// The types below are the subset of the OTLP JSON encoding that is required
+// to export spans
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+func newOTLPValue(v interface{}) otlpValue {
+	switch v := v.(type) {
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		return otlpValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpValue{IntValue: &s}
+	case uint64:
+		s := strconv.FormatUint(v, 10)
+		return otlpValue{IntValue: &s}
+	case float64:
+		return otlpValue{DoubleValue: &v}
+	case string:
+		return otlpValue{StringValue: &v}
+	case fmt.Stringer:
+		s := v.String()
+		return otlpValue{StringValue: &s}
+	}
+	s := fmt.Sprint(v)
+	return otlpValue{StringValue: &s}
+}
+
+func (e *OTLPExporter) encode(spans []*Span) *otlpRequest {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			Name:              span.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		}
+		if span.ParentID != (SpanID{}) {
+			s.ParentSpanID = hex.EncodeToString(span.ParentID[:])
+		}
+		attrs := span.Attributes()
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			s.Attributes = append(s.Attributes, otlpAttribute{Key: k, Value: newOTLPValue(attrs[k])})
+		}
+		if span.Err != nil {
+			s.Status = &otlpStatus{Code: otlpStatusCodeError, Message: span.Err.Error()}
+		}
+		encoded = append(encoded, s)
+	}
+	service := e.service
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: &service}}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/ethereum/go-ethereum/tracing"},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
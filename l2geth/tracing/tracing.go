@@ -0,0 +1,211 @@
+// Package tracing implements lightweight request tracing with W3C trace
+// context propagation. Spans are only recorded when an Exporter has been
+// registered, so instrumented code paths cost a single atomic load when
+// tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace across services
+type TraceID [16]byte
+
+// SpanID identifies a single span within a trace
+type SpanID [8]byte
+
+// Span represents a single timed operation within a trace
+type Span struct {
+	TraceID  TraceID
+	SpanID   SpanID
+	ParentID SpanID
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Err      error
+
+	lock       sync.Mutex
+	attributes map[string]interface{}
+	exporter   Exporter
+}
+
+// Exporter ships finished spans to a tracing backend
+type Exporter interface {
+	Export(span *Span)
+}
+
+// spanContext is the part of a span that is propagated to its children
+type spanContext struct {
+	traceID TraceID
+	spanID  SpanID
+	sampled bool
+}
+
+type spanContextKey struct{}
+
+var (
+	exporter    atomic.Value
+	sampleRatio uint64 = 1 << 63
+)
+
+// Register sets the exporter that finished spans are sent to and the ratio
+// of traces that are sampled. Tracing is disabled until an exporter is
+// registered.
+func Register(e Exporter, ratio float64) {
+	if ratio > 1 {
+		ratio = 1
+	}
+	if ratio < 0 {
+		ratio = 0
+	}
+	atomic.StoreUint64(&sampleRatio, uint64(ratio*float64(1<<63)))
+	exporter.Store(&e)
+}
+
+// Enabled returns true when an exporter has been registered
+func Enabled() bool {
+	return currentExporter() != nil
+}
+
+func currentExporter() Exporter {
+	e, _ := exporter.Load().(*Exporter)
+	if e == nil {
+		return nil
+	}
+	return *e
+}
+
+// StartSpan starts a span as a child of the span in the context, or as the
+// root of a new trace when there is none. The returned context carries the
+// new span. A nil span is returned when tracing is disabled or the trace is
+// not sampled, all of the methods on Span are safe to call on nil.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	e := currentExporter()
+	if e == nil {
+		return ctx, nil
+	}
+	parent, ok := ctx.Value(spanContextKey{}).(spanContext)
+	if ok && !parent.sampled {
+		return ctx, nil
+	}
+	span := &Span{
+		Name:     name,
+		Start:    time.Now(),
+		exporter: e,
+	}
+	randomBytes(span.SpanID[:])
+	if ok {
+		span.TraceID = parent.traceID
+		span.ParentID = parent.spanID
+	} else {
+		randomBytes(span.TraceID[:])
+		if !sampled(span.TraceID) {
+			ctx = context.WithValue(ctx, spanContextKey{}, spanContext{traceID: span.TraceID})
+			return ctx, nil
+		}
+	}
+	ctx = context.WithValue(ctx, spanContextKey{}, spanContext{
+		traceID: span.TraceID,
+		spanID:  span.SpanID,
+		sampled: true,
+	})
+	return ctx, span
+}
+
+// sampled decides if a new trace should be recorded based on its id so that
+// the decision is stable for a given trace
+func sampled(id TraceID) bool {
+	return binary.BigEndian.Uint64(id[8:])>>1 < atomic.LoadUint64(&sampleRatio)
+}
+
+// SetAttribute annotates the span with a key value pair
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// Attributes returns a copy of the attributes of the span
+func (s *Span) Attributes() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	attrs := make(map[string]interface{}, len(s.attributes))
+	for k, v := range s.attributes {
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// Finish ends the span, recording the error if there is one, and hands the
+// span to the exporter
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+	s.End = time.Now()
+	s.Err = err
+	s.exporter.Export(s)
+}
+
+// Extract returns a context that carries the remote parent described by a
+// W3C traceparent header. The context is returned unchanged when the header
+// is malformed.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return ctx
+	}
+	var sc spanContext
+	if n, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil || n != len(sc.traceID) {
+		return ctx
+	}
+	if n, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil || n != len(sc.spanID) {
+		return ctx
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return ctx
+	}
+	if sc.traceID == (TraceID{}) || sc.spanID == (SpanID{}) {
+		return ctx
+	}
+	sc.sampled = flags[0]&1 == 1
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// Inject returns the W3C traceparent header for the span in the context, or
+// an empty string if there is none
+func Inject(ctx context.Context) string {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	if !ok || sc.spanID == (SpanID{}) {
+		return ""
+	}
+	flags := 0
+	if sc.sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%x-%x-%02x", sc.traceID, sc.spanID, flags)
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("tracing: cannot read random bytes: %v", err))
+	}
+}
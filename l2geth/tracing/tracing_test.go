@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type testExporter struct {
+	lock  sync.Mutex
+	spans []*Span
+}
+
+func (e *testExporter) Export(span *Span) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// disable unregisters the exporter between tests
+func disable() {
+	var none Exporter
+	exporter.Store(&none)
+}
+
+func TestStartSpanDisabled(t *testing.T) {
+	disable()
+	ctx, span := StartSpan(context.Background(), "disabled")
+	if span != nil {
+		t.Fatal("Span created without an exporter")
+	}
+	// Methods on a nil span must not panic
+	span.SetAttribute("key", "value")
+	span.Finish(nil)
+	if Inject(ctx) != "" {
+		t.Fatal("Trace context propagated without a span")
+	}
+}
+
+func TestSpanPropagation(t *testing.T) {
+	e := &testExporter{}
+	Register(e, 1)
+	defer disable()
+
+	traceparent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	ctx := Extract(context.Background(), traceparent)
+
+	ctx, parent := StartSpan(ctx, "parent")
+	_, child := StartSpan(ctx, "child")
+	child.SetAttribute("fee", 1)
+	child.Finish(errors.New("fee too low"))
+	parent.Finish(nil)
+
+	if len(e.spans) != 2 {
+		t.Fatalf("Unexpected number of spans: got %d, expected %d", len(e.spans), 2)
+	}
+	if got := Inject(ctx); got[:36] != traceparent[:36] {
+		t.Fatalf("Trace id not propagated: %s", got)
+	}
+	if child.TraceID != parent.TraceID || child.ParentID != parent.SpanID {
+		t.Fatal("Child span not linked to parent")
+	}
+	if parent.ParentID != (SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31}) {
+		t.Fatal("Parent span not linked to remote parent")
+	}
+	if child.Err == nil || child.Attributes()["fee"] != 1 {
+		t.Fatal("Child span not annotated")
+	}
+}
+
+func TestExtractUnsampled(t *testing.T) {
+	Register(&testExporter{}, 1)
+	defer disable()
+	ctx := Extract(context.Background(), "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	if _, span := StartSpan(ctx, "unsampled"); span != nil {
+		t.Fatal("Span created for unsampled trace")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		lock sync.Mutex
+		req  otlpRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	e := NewOTLPExporter(server.URL, "test")
+	Register(e, 1)
+	defer disable()
+	_, span := StartSpan(context.Background(), "export")
+	span.SetAttribute("l1GasPrice", uint64(10))
+	span.Finish(nil)
+	e.Stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans[0].Spans) != 1 {
+		t.Fatalf("Unexpected export: %+v", req)
+	}
+	exported := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if exported.Name != "export" || *exported.Attributes[0].Value.IntValue != "10" {
+		t.Fatalf("Unexpected span: %+v", exported)
+	}
+}
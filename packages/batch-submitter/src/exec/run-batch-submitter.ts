@@ -1,5 +1,10 @@
 /* External Imports */
-import { injectL2Context, Bcfg } from '@eth-optimism/core-utils'
+import {
+  injectL2Context,
+  Bcfg,
+  parseRollupConfig,
+  RollupConfig,
+} from '@eth-optimism/core-utils'
 import * as Sentry from '@sentry/node'
 import { Logger, Metrics, createMetricsServer } from '@eth-optimism/common-ts'
 import { exit } from 'process'
@@ -9,6 +14,8 @@ import {
   TransactionReceipt,
 } from '@ethersproject/providers'
 import * as dotenv from 'dotenv'
+import * as path from 'path'
+import * as fs from 'fs'
 import Config from 'bcfg'
 
 /* Internal Imports */
@@ -23,6 +30,11 @@ import {
   TransactionSubmitter,
   YnatmTransactionSubmitter,
   ResubmissionConfig,
+  PersistentTransactionSubmitter,
+  SubmissionStateStore,
+  recoverSubmission,
+  loadNetworkProfile,
+  BatchSubmitterProfile,
 } from '../utils'
 
 interface RequiredEnvVars {
@@ -128,6 +140,28 @@ export const run = async () => {
     logger = new Logger({ name })
   }
 
+  // Rollup config shared with l2geth and the gas oracle
+  const ROLLUP_CONFIG = config.str('rollup-config', env.ROLLUP_CONFIG)
+  let rollupConfig: RollupConfig
+  if (ROLLUP_CONFIG) {
+    rollupConfig = parseRollupConfig(fs.readFileSync(ROLLUP_CONFIG, 'utf8'))
+    logger.info('Loaded rollup config', { rollupConfig })
+  }
+
+  // Per network defaults, read from the profile of the L2 chain
+  const PROFILES = config.str('profiles', env.PROFILES)
+  let profile: BatchSubmitterProfile = {}
+  if (PROFILES) {
+    const l2Network = await new StaticJsonRpcProvider(
+      config.str('l2-node-web3-url', env.L2_NODE_WEB3_URL)
+    ).getNetwork()
+    profile = loadNetworkProfile(PROFILES, l2Network.chainId)
+    logger.info('Loaded network profile', {
+      chainId: l2Network.chainId,
+      profile,
+    })
+  }
+
   const useHardhat = config.bool('use-hardhat', !!env.USE_HARDHAT)
   const DEBUG_IMPERSONATE_SEQUENCER_ADDRESS = config.str(
     'debug-impersonate-sequencer-address',
@@ -219,7 +253,9 @@ export const run = async () => {
   )
   const GAS_THRESHOLD_IN_GWEI = config.uint(
     'gas-threshold-in-gwei',
-    parseInt(env.GAS_THRESHOLD_IN_GWEI, 10) || 100
+    parseInt(env.GAS_THRESHOLD_IN_GWEI, 10) ||
+      profile.gasThresholdInGwei ||
+      100
   )
 
   // Private keys & mnemonics
@@ -250,6 +286,10 @@ export const run = async () => {
   )
 
   // Auto fix batch options -- TODO: Remove this very hacky config
+  // Directory to persist in flight submissions in so that a restart does
+  // not submit the same batch twice
+  const STATE_DIR = config.str('state-dir', env.STATE_DIR)
+
   const AUTO_FIX_BATCH_OPTIONS_CONF = config.str(
     'auto-fix-batch-conf',
     env.AUTO_FIX_BATCH_OPTIONS_CONF || ''
@@ -273,27 +313,29 @@ export const run = async () => {
     L2_NODE_WEB3_URL: config.str('l2-node-web3-url', env.L2_NODE_WEB3_URL),
     ADDRESS_MANAGER_ADDRESS: config.str(
       'address-manager-address',
-      env.ADDRESS_MANAGER_ADDRESS
+      env.ADDRESS_MANAGER_ADDRESS ||
+        rollupConfig?.addressManager ||
+        profile.addressManagerAddress
     ),
     MIN_L1_TX_SIZE: config.uint(
       'min-l1-tx-size',
-      parseInt(env.MIN_L1_TX_SIZE, 10)
+      parseInt(env.MIN_L1_TX_SIZE, 10) || profile.minL1TxSize
     ),
     MAX_L1_TX_SIZE: config.uint(
       'max-l1-tx-size',
-      parseInt(env.MAX_L1_TX_SIZE, 10)
+      parseInt(env.MAX_L1_TX_SIZE, 10) || profile.maxL1TxSize
     ),
     MAX_TX_BATCH_COUNT: config.uint(
       'max-tx-batch-count',
-      parseInt(env.MAX_TX_BATCH_COUNT, 10)
+      parseInt(env.MAX_TX_BATCH_COUNT, 10) || profile.maxTxBatchCount
     ),
     MAX_STATE_BATCH_COUNT: config.uint(
       'max-state-batch-count',
-      parseInt(env.MAX_STATE_BATCH_COUNT, 10)
+      parseInt(env.MAX_STATE_BATCH_COUNT, 10) || profile.maxStateBatchCount
     ),
     MAX_BATCH_SUBMISSION_TIME: config.uint(
       'max-batch-submisison-time',
-      parseInt(env.MAX_BATCH_SUBMISSION_TIME, 10)
+      parseInt(env.MAX_BATCH_SUBMISSION_TIME, 10) || profile.maxBatchSubmissionTime
     ),
     POLL_INTERVAL: config.uint(
       'poll-interval',
@@ -345,6 +387,22 @@ export const run = async () => {
     new StaticJsonRpcProvider(requiredEnvVars.L2_NODE_WEB3_URL)
   )
 
+  // Refuse to submit batches of a different rollup than the configured one
+  if (rollupConfig) {
+    const l1Network = await new StaticJsonRpcProvider(
+      requiredEnvVars.L1_NODE_WEB3_URL
+    ).getNetwork()
+    const l2Network = await l2Provider.getNetwork()
+    if (
+      l1Network.chainId !== rollupConfig.l1ChainId ||
+      l2Network.chainId !== rollupConfig.l2ChainId
+    ) {
+      throw new Error(
+        `Rollup config of chains ${rollupConfig.l1ChainId} and ${rollupConfig.l2ChainId}, but connected to chains ${l1Network.chainId} and ${l2Network.chainId}`
+      )
+    }
+  }
+
   const sequencerSigner: Signer = await getSequencerSigner()
   let proposerSigner: Signer = await getProposerSigner()
 
@@ -367,12 +425,23 @@ export const run = async () => {
     maxGasPriceInGwei: GAS_THRESHOLD_IN_GWEI,
     gasRetryIncrement: GAS_RETRY_INCREMENT,
   }
-  const txBatchTxSubmitter: TransactionSubmitter =
+  let txBatchTxSubmitter: TransactionSubmitter =
     new YnatmTransactionSubmitter(
       sequencerSigner,
       resubmissionConfig,
       requiredEnvVars.NUM_CONFIRMATIONS
     )
+  let txBatchStore: SubmissionStateStore
+  if (STATE_DIR) {
+    txBatchStore = new SubmissionStateStore(
+      path.join(STATE_DIR, 'tx-batch-submission.json')
+    )
+    txBatchTxSubmitter = new PersistentTransactionSubmitter(
+      sequencerSigner,
+      txBatchTxSubmitter,
+      txBatchStore
+    )
+  }
   const txBatchSubmitter = new TransactionBatchSubmitter(
     sequencerSigner,
     l2Provider,
@@ -393,12 +462,23 @@ export const run = async () => {
     autoFixBatchOptions
   )
 
-  const stateBatchTxSubmitter: TransactionSubmitter =
+  let stateBatchTxSubmitter: TransactionSubmitter =
     new YnatmTransactionSubmitter(
       proposerSigner,
       resubmissionConfig,
       requiredEnvVars.NUM_CONFIRMATIONS
     )
+  let stateBatchStore: SubmissionStateStore
+  if (STATE_DIR) {
+    stateBatchStore = new SubmissionStateStore(
+      path.join(STATE_DIR, 'state-batch-submission.json')
+    )
+    stateBatchTxSubmitter = new PersistentTransactionSubmitter(
+      proposerSigner,
+      stateBatchTxSubmitter,
+      stateBatchStore
+    )
+  }
   const stateBatchSubmitter = new StateBatchSubmitter(
     proposerSigner,
     l2Provider,
@@ -420,8 +500,29 @@ export const run = async () => {
 
   // Loops infinitely!
   const loop = async (
-    func: () => Promise<TransactionReceipt>
+    func: () => Promise<TransactionReceipt>,
+    signer: Signer,
+    store?: SubmissionStateStore
   ): Promise<void> => {
+    // Resolve any submission left in flight by a previous run
+    if (store) {
+      try {
+        await recoverSubmission(
+          store,
+          signer,
+          requiredEnvVars.NUM_CONFIRMATIONS,
+          logger
+        )
+      } catch (err) {
+        logger.error('Cannot recover in flight submission', {
+          message: err.toString(),
+          stack: err.stack,
+          code: err.code,
+        })
+        process.exit(1)
+      }
+    }
+
     // Clear all pending transactions
     if (clearPendingTxs) {
       try {
@@ -498,10 +599,18 @@ export const run = async () => {
 
   // Run batch submitters in two seperate infinite loops!
   if (requiredEnvVars.RUN_TX_BATCH_SUBMITTER) {
-    loop(() => txBatchSubmitter.submitNextBatch())
+    loop(
+      () => txBatchSubmitter.submitNextBatch(),
+      sequencerSigner,
+      txBatchStore
+    )
   }
   if (requiredEnvVars.RUN_STATE_BATCH_SUBMITTER) {
-    loop(() => stateBatchSubmitter.submitNextBatch())
+    loop(
+      () => stateBatchSubmitter.submitNextBatch(),
+      proposerSigner,
+      stateBatchStore
+    )
   }
 
   if (config.bool('run-metrics-server', env.RUN_METRICS_SERVER === 'true')) {
@@ -1 +1,3 @@
 export * from './tx-submission'
+export * from './submission-state'
+export * from './network-profile'
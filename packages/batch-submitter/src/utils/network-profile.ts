@@ -0,0 +1,36 @@
+/* External Imports */
+import * as fs from 'fs'
+
+/**
+ * The section of a network profile that is read by the batch submitter.
+ * Values that are not set are left to the environment.
+ */
+export interface BatchSubmitterProfile {
+  addressManagerAddress?: string
+  minL1TxSize?: number
+  maxL1TxSize?: number
+  maxTxBatchCount?: number
+  maxStateBatchCount?: number
+  maxBatchSubmissionTime?: number
+  gasThresholdInGwei?: number
+}
+
+/**
+ * Reads the batch submitter section of the profile of the chain from a
+ * network profiles file. The file is shared with l2geth and the gas oracle
+ * and is keyed by the decimal L2 chain id. Returns an empty profile when the
+ * file has no profile for the chain.
+ */
+export const loadNetworkProfile = (
+  file: string,
+  chainId: number
+): BatchSubmitterProfile => {
+  const profiles = JSON.parse(fs.readFileSync(file, 'utf8'))
+  for (const key of Object.keys(profiles)) {
+    if (!/^[0-9]+$/.test(key)) {
+      throw new Error(`Network profile key ${key} is not a decimal chain id`)
+    }
+  }
+  const profile = profiles[chainId.toString()]
+  return (profile && profile.batchSubmitter) || {}
+}
@@ -0,0 +1,166 @@
+/* External Imports */
+import * as fs from 'fs'
+import * as path from 'path'
+import { Signer, PopulatedTransaction } from 'ethers'
+import {
+  TransactionReceipt,
+  TransactionResponse,
+} from '@ethersproject/abstract-provider'
+import { Logger } from '@eth-optimism/common-ts'
+
+/* Internal Imports */
+import { TransactionSubmitter, TxSubmissionHooks } from './tx-submission'
+
+/**
+ * The stage that an in flight submission reached before the batch submitter
+ * stopped. A submission is `prepared` once its nonce has been assigned and
+ * `sent` once at least one transaction using that nonce has been broadcast.
+ */
+export type SubmissionStage = 'prepared' | 'sent'
+
+export interface SubmissionState {
+  stage: SubmissionStage
+  nonce: number
+  txHashes: string[]
+}
+
+/**
+ * Stores the in flight submission on disk. Writes go to a temporary file
+ * that is renamed over the previous state so that a crash mid write never
+ * leaves a partially written file behind.
+ */
+export class SubmissionStateStore {
+  constructor(readonly file: string) {}
+
+  public load(): SubmissionState | undefined {
+    if (!fs.existsSync(this.file)) {
+      return undefined
+    }
+    return JSON.parse(fs.readFileSync(this.file, 'utf8'))
+  }
+
+  public save(state: SubmissionState): void {
+    fs.mkdirSync(path.dirname(this.file), { recursive: true })
+    const tmp = `${this.file}.tmp`
+    fs.writeFileSync(tmp, JSON.stringify(state))
+    fs.renameSync(tmp, this.file)
+  }
+
+  public clear(): void {
+    if (fs.existsSync(this.file)) {
+      fs.unlinkSync(this.file)
+    }
+  }
+}
+
+/**
+ * Wraps a TransactionSubmitter and records every submission in a
+ * SubmissionStateStore. The nonce is fixed up front so that every gas price
+ * bump replaces the same transaction instead of queueing a second batch
+ * behind the first, and so that a restart can find the transactions that
+ * were broadcast before the batch submitter stopped.
+ */
+export class PersistentTransactionSubmitter implements TransactionSubmitter {
+  constructor(
+    readonly signer: Signer,
+    readonly submitter: TransactionSubmitter,
+    readonly store: SubmissionStateStore
+  ) {}
+
+  public async submitTransaction(
+    tx: PopulatedTransaction,
+    hooks?: TxSubmissionHooks
+  ): Promise<TransactionReceipt> {
+    const nonce = await this.signer.getTransactionCount('pending')
+    const state: SubmissionState = {
+      stage: 'prepared',
+      nonce,
+      txHashes: [],
+    }
+    this.store.save(state)
+
+    const receipt = await this.submitter.submitTransaction(
+      { ...tx, nonce },
+      {
+        beforeSendTransaction: (fullTx: PopulatedTransaction) => {
+          if (hooks) {
+            hooks.beforeSendTransaction(fullTx)
+          }
+        },
+        onTransactionResponse: (txResponse: TransactionResponse) => {
+          state.stage = 'sent'
+          state.txHashes.push(txResponse.hash)
+          this.store.save(state)
+          if (hooks) {
+            hooks.onTransactionResponse(txResponse)
+          }
+        },
+      }
+    )
+    this.store.clear()
+    return receipt
+  }
+}
+
+/**
+ * Resolves a submission left behind by a previous run before any new batch
+ * is built. Batches are always built from the tip of the chain contract, so
+ * once the in flight transaction is either confirmed or known to have been
+ * replaced, the batch submitter can continue without submitting it twice.
+ */
+export const recoverSubmission = async (
+  store: SubmissionStateStore,
+  signer: Signer,
+  numConfirmations: number,
+  logger: Logger
+): Promise<TransactionReceipt | undefined> => {
+  const state = store.load()
+  if (!state) {
+    return undefined
+  }
+  logger.info('Recovering in flight submission', {
+    stage: state.stage,
+    nonce: state.nonce,
+    txHashes: state.txHashes,
+  })
+
+  // Nothing was broadcast, the batch will be rebuilt from the chain contract
+  if (state.stage === 'prepared' || state.txHashes.length === 0) {
+    store.clear()
+    return undefined
+  }
+
+  for (const hash of state.txHashes) {
+    const receipt = await signer.provider.getTransactionReceipt(hash)
+    if (receipt) {
+      logger.info('Found receipt for in flight submission', { hash })
+      const confirmed = await signer.provider.waitForTransaction(
+        hash,
+        numConfirmations
+      )
+      store.clear()
+      return confirmed
+    }
+  }
+
+  const latest = await signer.getTransactionCount('latest')
+  if (latest > state.nonce) {
+    logger.warn('In flight submission replaced by another transaction', {
+      nonce: state.nonce,
+    })
+    store.clear()
+    return undefined
+  }
+
+  // Still in the mempool, wait for whichever gas price bump is included
+  logger.info('Waiting for in flight submission', {
+    nonce: state.nonce,
+  })
+  const receipt = await Promise.race(
+    state.txHashes.map((hash) =>
+      signer.provider.waitForTransaction(hash, numConfirmations)
+    )
+  )
+  store.clear()
+  return receipt
+}
@@ -0,0 +1,47 @@
+import { expect } from '../setup'
+import * as fs from 'fs'
+import * as os from 'os'
+import * as path from 'path'
+import { loadNetworkProfile } from '../../src'
+
+describe('loadNetworkProfile', () => {
+  let dir: string
+  beforeEach(() => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), 'profiles-'))
+  })
+  afterEach(() => {
+    fs.rmdirSync(dir, { recursive: true })
+  })
+
+  const write = (profiles: any): string => {
+    const file = path.join(dir, 'profiles.json')
+    fs.writeFileSync(file, JSON.stringify(profiles))
+    return file
+  }
+
+  it('should return the batch submitter section of the chain', () => {
+    const file = write({
+      10: {
+        gasPriceOracleAddress: '0x420000000000000000000000000000000000000F',
+        batchSubmitter: { maxL1TxSize: 90000, gasThresholdInGwei: 200 },
+      },
+      69: { batchSubmitter: { maxL1TxSize: 1 } },
+    })
+    expect(loadNetworkProfile(file, 10)).to.deep.equal({
+      maxL1TxSize: 90000,
+      gasThresholdInGwei: 200,
+    })
+  })
+
+  it('should return an empty profile for unknown chains', () => {
+    const file = write({ 10: { batchSubmitter: { maxL1TxSize: 1 } } })
+    expect(loadNetworkProfile(file, 420)).to.deep.equal({})
+  })
+
+  it('should reject keys that are not chain ids', () => {
+    const file = write({ optimism: {} })
+    expect(() => loadNetworkProfile(file, 10)).to.throw(
+      'not a decimal chain id'
+    )
+  })
+})
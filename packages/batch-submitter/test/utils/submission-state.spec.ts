@@ -0,0 +1,135 @@
+import { expect } from '../setup'
+import * as fs from 'fs'
+import * as os from 'os'
+import * as path from 'path'
+import { ethers, Signer } from 'ethers'
+import {
+  TransactionReceipt,
+  TransactionResponse,
+} from '@ethersproject/abstract-provider'
+import { Logger } from '@eth-optimism/common-ts'
+import {
+  PersistentTransactionSubmitter,
+  SubmissionStateStore,
+  TransactionSubmitter,
+  TxSubmissionHooks,
+  recoverSubmission,
+} from '../../src'
+
+const logger = new Logger({ name: 'submission-state-test' })
+
+const makeSigner = (opts: {
+  latest: number
+  pending: number
+  receipts?: { [hash: string]: TransactionReceipt }
+}): Signer => {
+  return {
+    getTransactionCount: async (blockTag: string) =>
+      blockTag === 'pending' ? opts.pending : opts.latest,
+    provider: {
+      getTransactionReceipt: async (hash: string) =>
+        (opts.receipts || {})[hash] || null,
+      waitForTransaction: async (hash: string) =>
+        ({ transactionHash: hash } as TransactionReceipt),
+    },
+  } as unknown as Signer
+}
+
+describe('SubmissionStateStore', () => {
+  let store: SubmissionStateStore
+  beforeEach(() => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'batch-submitter-'))
+    store = new SubmissionStateStore(path.join(dir, 'submission.json'))
+  })
+
+  it('round trips the submission state', () => {
+    expect(store.load()).to.be.undefined
+    store.save({ stage: 'sent', nonce: 3, txHashes: ['0x01'] })
+    expect(store.load()).to.deep.equal({
+      stage: 'sent',
+      nonce: 3,
+      txHashes: ['0x01'],
+    })
+    store.clear()
+    expect(store.load()).to.be.undefined
+  })
+
+  describe('PersistentTransactionSubmitter', () => {
+    it('fixes the nonce and records every broadcast transaction', async () => {
+      let calls = 0
+      const inner: TransactionSubmitter = {
+        submitTransaction: async (
+          tx: ethers.PopulatedTransaction,
+          hooks: TxSubmissionHooks
+        ) => {
+          expect(tx.nonce).to.equal(7)
+          expect(store.load().stage).to.equal('prepared')
+          hooks.beforeSendTransaction(tx)
+          hooks.onTransactionResponse({ hash: '0x01' } as TransactionResponse)
+          hooks.onTransactionResponse({ hash: '0x02' } as TransactionResponse)
+          expect(store.load()).to.deep.equal({
+            stage: 'sent',
+            nonce: 7,
+            txHashes: ['0x01', '0x02'],
+          })
+          calls++
+          return {} as TransactionReceipt
+        },
+      }
+      const submitter = new PersistentTransactionSubmitter(
+        makeSigner({ latest: 7, pending: 7 }),
+        inner,
+        store
+      )
+      await submitter.submitTransaction({ data: '0x' })
+      expect(calls).to.equal(1)
+      expect(store.load()).to.be.undefined
+    })
+  })
+
+  describe('recoverSubmission', () => {
+    it('does nothing without a stored submission', async () => {
+      const signer = makeSigner({ latest: 0, pending: 0 })
+      const receipt = await recoverSubmission(store, signer, 0, logger)
+      expect(receipt).to.be.undefined
+    })
+
+    it('discards a submission that crashed before being sent', async () => {
+      store.save({ stage: 'prepared', nonce: 1, txHashes: [] })
+      const signer = makeSigner({ latest: 1, pending: 1 })
+      const receipt = await recoverSubmission(store, signer, 0, logger)
+      expect(receipt).to.be.undefined
+      expect(store.load()).to.be.undefined
+    })
+
+    it('waits for a submission that crashed after being sent', async () => {
+      store.save({ stage: 'sent', nonce: 1, txHashes: ['0x01', '0x02'] })
+      const signer = makeSigner({ latest: 1, pending: 2 })
+      const receipt = await recoverSubmission(store, signer, 0, logger)
+      expect(receipt.transactionHash).to.equal('0x01')
+      expect(store.load()).to.be.undefined
+    })
+
+    it('uses the receipt of a submission that crashed after being included', async () => {
+      store.save({ stage: 'sent', nonce: 1, txHashes: ['0x01', '0x02'] })
+      const signer = makeSigner({
+        latest: 2,
+        pending: 2,
+        receipts: {
+          '0x02': { transactionHash: '0x02' } as TransactionReceipt,
+        },
+      })
+      const receipt = await recoverSubmission(store, signer, 0, logger)
+      expect(receipt.transactionHash).to.equal('0x02')
+      expect(store.load()).to.be.undefined
+    })
+
+    it('discards a submission that was replaced', async () => {
+      store.save({ stage: 'sent', nonce: 1, txHashes: ['0x01'] })
+      const signer = makeSigner({ latest: 2, pending: 2 })
+      const receipt = await recoverSubmission(store, signer, 0, logger)
+      expect(receipt).to.be.undefined
+      expect(store.load()).to.be.undefined
+    })
+  })
+})
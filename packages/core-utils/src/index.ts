@@ -6,3 +6,4 @@ export * from './events'
 export * from './batches'
 export * from './bcfg'
 export * from './fees'
+export * from './rollup-config'
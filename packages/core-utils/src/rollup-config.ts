@@ -0,0 +1,116 @@
+/**
+ * The configuration of a rollup that is shared by all of its services:
+ * l2geth, the batch submitter and proposer, and the gas oracle. It mirrors
+ * the rollupconfig packages of l2geth and go/utils.
+ */
+
+import { ethers } from 'ethers'
+
+export interface RollupConfig {
+  l1ChainId: number
+  l2ChainId: number
+  // Lib_AddressManager on L1 that the contracts of the rollup are resolved with
+  addressManager: string
+  // OVM_CanonicalTransactionChain that transaction batches are appended to
+  batchInbox?: string
+  batchInboxDeployHeight?: number
+  // OVM_StateCommitmentChain that the proposer appends state roots to
+  stateCommitmentChain?: string
+  predeploys?: {
+    gasPriceOracle?: string
+  }
+  feeForks?: {
+    l1CalldataGasBlock?: number
+    minTxSizeBlock?: number
+  }
+  // Gas schedule of calldata on the L1 chain, calldata of L1 blocks before
+  // istanbulBlock is priced at the Frontier costs
+  l1CalldataGas?: {
+    zeroGas: number
+    nonZeroGas: number
+    minTxSize: number
+    istanbulBlock?: number
+    forks?: Array<{ block: number; zeroGas: number; nonZeroGas: number }>
+  }
+  fees?: {
+    thresholdUp?: number
+    thresholdDown?: number
+  }
+}
+
+const fields = [
+  'l1ChainId',
+  'l2ChainId',
+  'addressManager',
+  'batchInbox',
+  'batchInboxDeployHeight',
+  'stateCommitmentChain',
+  'predeploys',
+  'feeForks',
+  'l1CalldataGas',
+  'fees',
+]
+
+const isAddress = (value: unknown): boolean =>
+  typeof value === 'string' && ethers.utils.isAddress(value)
+
+/**
+ * Parses and validates a rollup config. Unknown fields are rejected so that
+ * a misspelled field is not silently ignored.
+ *
+ * @param json The rollup config as JSON.
+ * @returns The rollup config.
+ */
+export const parseRollupConfig = (json: string): RollupConfig => {
+  const config = JSON.parse(json)
+  for (const key of Object.keys(config)) {
+    if (!fields.includes(key)) {
+      throw new Error(`Unknown rollup config field ${key}`)
+    }
+  }
+  if (!config.l1ChainId || !config.l2ChainId) {
+    throw new Error('Invalid rollup config: missing chain id')
+  }
+  if (!isAddress(config.addressManager)) {
+    throw new Error('Invalid rollup config: missing address manager')
+  }
+  for (const key of ['batchInbox', 'stateCommitmentChain']) {
+    if (config[key] !== undefined && !isAddress(config[key])) {
+      throw new Error(`Invalid rollup config: invalid ${key} address`)
+    }
+  }
+  const { thresholdUp, thresholdDown } = config.fees || {}
+  if (thresholdUp !== undefined && thresholdUp <= 1) {
+    throw new Error(
+      `Invalid rollup config: fee threshold up not larger than 1: ${thresholdUp}`
+    )
+  }
+  if (
+    thresholdDown !== undefined &&
+    (thresholdDown <= 0 || thresholdDown >= 1)
+  ) {
+    throw new Error(
+      `Invalid rollup config: fee threshold down not between 0 and 1: ${thresholdDown}`
+    )
+  }
+  const { l1CalldataGasBlock, minTxSizeBlock } = config.feeForks || {}
+  if (
+    minTxSizeBlock !== undefined &&
+    (l1CalldataGasBlock === undefined || l1CalldataGasBlock > minTxSizeBlock)
+  ) {
+    throw new Error(
+      'Invalid rollup config: min tx size fee fork before the l1 calldata gas fee fork'
+    )
+  }
+  let last = config.l1CalldataGas?.istanbulBlock
+  for (const fork of config.l1CalldataGas?.forks || []) {
+    if (
+      fork.block === undefined ||
+      (last !== undefined && fork.block <= last)
+    ) {
+      throw new Error('Invalid rollup config: l1 calldata gas fork out of order')
+    }
+    last = fork.block
+  }
+  return config
+}
@@ -0,0 +1,46 @@
+import { expect } from '../setup'
+import { parseRollupConfig } from '../../src'
+
+const valid = {
+  l1ChainId: 1,
+  l2ChainId: 10,
+  addressManager: '0xdE1FCfB0851916CA5101820A69b13a4E276bd81F',
+  batchInboxDeployHeight: 13596466,
+  feeForks: { l1CalldataGasBlock: 100, minTxSizeBlock: 200 },
+  fees: { thresholdUp: 3, thresholdDown: 0.9 },
+}
+
+describe('parseRollupConfig', () => {
+  it('should parse a valid rollup config', () => {
+    const config = parseRollupConfig(JSON.stringify(valid))
+    expect(config.l2ChainId).to.equal(10)
+    expect(config.fees.thresholdDown).to.equal(0.9)
+  })
+
+  const invalid = {
+    'unknown field': { ...valid, feeThresholdUp: 3 },
+    'missing chain id': { ...valid, l2ChainId: undefined },
+    'missing address manager': { ...valid, addressManager: '0x1234' },
+    'fee threshold up': { ...valid, fees: { thresholdUp: 0.5 } },
+    'fee threshold down': { ...valid, fees: { thresholdDown: 1.5 } },
+    'fee fork order': {
+      ...valid,
+      feeForks: { l1CalldataGasBlock: 200, minTxSizeBlock: 100 },
+    },
+    'l1 calldata gas fork order': {
+      ...valid,
+      l1CalldataGas: {
+        zeroGas: 4,
+        nonZeroGas: 16,
+        minTxSize: 0,
+        istanbulBlock: 100,
+        forks: [{ block: 100, zeroGas: 4, nonZeroGas: 8 }],
+      },
+    },
+  }
+  for (const [name, config] of Object.entries(invalid)) {
+    it(`should reject a rollup config with ${name}`, () => {
+      expect(() => parseRollupConfig(JSON.stringify(config))).to.throw()
+    })
+  }
+})